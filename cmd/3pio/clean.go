@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// runIDTimestampLayout is the timestamp every run ID starts with
+const runIDTimestampLayout = "20060102T150405"
+
+// runIDRegex matches run directory names, which start with the run's timestamp
+var runIDRegex = regexp.MustCompile(`^\d{8}T\d{6}-`)
+
+// newCleanCommand creates the "clean" subcommand, which removes old runs from the output directory
+func newCleanCommand() *cobra.Command {
+	var outputDir, olderThan string
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "clean [--older-than <duration>] [--all]",
+		Short: "Remove run directories, or the whole output directory with --all",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exitCode, _ := runCleanCore(outputDir, olderThan, all)
+			os.Exit(exitCode)
+			return nil // Never reached, but needed for signature
+		},
+	}
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Output directory to clean (default: THREEPIO_OUTPUT_DIR or .3pio)")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only remove runs started more than this long ago (e.g. 12h or 7d)")
+	cmd.Flags().BoolVar(&all, "all", false, "Remove the whole output directory, including debug.log")
+	return cmd
+}
+
+// runCleanCore removes the run directories in outputDir, only those older than olderThan
+// when it is set, or the whole output directory with all (testable). It prints each
+// directory it removes.
+func runCleanCore(outputDir, olderThan string, all bool) (int, error) {
+	if outputDir == "" {
+		outputDir = defaultOutputDir()
+	}
+	if all && olderThan != "" {
+		err := fmt.Errorf("--all removes every run; it can't be combined with --older-than")
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+	var maxAge time.Duration
+	if olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1, err
+		}
+		maxAge = age
+	}
+
+	if err := checkCleanableOutputDir(outputDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+	if _, err := os.Lstat(outputDir); os.IsNotExist(err) {
+		fmt.Printf("Nothing to clean: %s does not exist\n", outputDir)
+		return 0, nil
+	}
+
+	if all {
+		if err := os.RemoveAll(outputDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to remove %s: %v\n", outputDir, err)
+			return 1, err
+		}
+		fmt.Printf("Removed:     %s\n", outputDir)
+		return 0, nil
+	}
+
+	runsDir := filepath.Join(outputDir, "runs")
+	runIDs, err := runsToClean(runsDir, maxAge, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+	for _, runID := range runIDs {
+		runDir := filepath.Join(runsDir, runID)
+		if err := os.RemoveAll(runDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to remove %s: %v\n", runDir, err)
+			return 1, err
+		}
+		fmt.Printf("Removed:     %s\n", runDir)
+	}
+	removeStaleLatestLink(runsDir)
+
+	fmt.Printf("Cleaned:     %d runs\n", len(runIDs))
+	return 0, nil
+}
+
+// defaultOutputDir returns the output directory used when none is given: THREEPIO_OUTPUT_DIR or .3pio
+func defaultOutputDir() string {
+	if outputDir := os.Getenv("THREEPIO_OUTPUT_DIR"); outputDir != "" {
+		return outputDir
+	}
+	return ".3pio"
+}
+
+// checkCleanableOutputDir refuses output directories that clean must not delete from:
+// anything that is neither named .3pio nor holds a runs directory, and the working
+// directory or any directory above it
+func checkCleanableOutputDir(outputDir string) error {
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", outputDir, err)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(absOutputDir, wd); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to clean %s: it contains the working directory", outputDir)
+		}
+	}
+	if filepath.Base(absOutputDir) == ".3pio" {
+		return nil
+	}
+	if info, err := os.Stat(filepath.Join(absOutputDir, "runs")); err == nil && info.IsDir() {
+		return nil
+	}
+	if _, err := os.Lstat(absOutputDir); os.IsNotExist(err) {
+		return nil
+	}
+	return fmt.Errorf("refusing to clean %s: it is not a 3pio output directory (no runs directory)", outputDir)
+}
+
+// runsToClean returns the run directories in runsDir, oldest first, that were started
+// more than maxAge before now (all of them when maxAge is 0). Entries that aren't run
+// directories, such as the latest link, are never returned.
+func runsToClean(runsDir string, maxAge time.Duration, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(runsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", runsDir, err)
+	}
+
+	var runIDs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !runIDRegex.MatchString(entry.Name()) {
+			continue
+		}
+		if maxAge > 0 {
+			started, err := time.ParseInLocation(runIDTimestampLayout, entry.Name()[:len(runIDTimestampLayout)], time.Local)
+			if err != nil || now.Sub(started) <= maxAge {
+				continue
+			}
+		}
+		runIDs = append(runIDs, entry.Name())
+	}
+	sort.Strings(runIDs)
+	return runIDs, nil
+}
+
+// removeStaleLatestLink removes runsDir/latest (or latest.txt) once the run it points at is gone
+func removeStaleLatestLink(runsDir string) {
+	linkPath := filepath.Join(runsDir, "latest")
+	if target, err := os.Readlink(linkPath); err == nil {
+		if _, err := os.Stat(filepath.Join(runsDir, target)); os.IsNotExist(err) {
+			_ = os.Remove(linkPath)
+		}
+	}
+	fallbackPath := filepath.Join(runsDir, "latest.txt")
+	if data, err := os.ReadFile(fallbackPath); err == nil {
+		if _, err := os.Stat(filepath.Join(runsDir, strings.TrimSpace(string(data)))); os.IsNotExist(err) {
+			_ = os.Remove(fallbackPath)
+		}
+	}
+}
+
+// parseAge parses a duration for --older-than, which also accepts whole days such as 7d
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("--older-than requires a positive duration such as 12h or 7d, got %q", value)
+}
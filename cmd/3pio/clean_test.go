@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunsToClean(t *testing.T) {
+	runsDir := t.TempDir()
+	for _, name := range []string{"20250901T120000-yoda", "20250920T120000-spock", "20250924T110000-merged", "notes"} {
+		if err := os.Mkdir(filepath.Join(runsDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only directories are runs
+	if err := os.WriteFile(filepath.Join(runsDir, "20250101T000000-file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 9, 24, 12, 0, 0, 0, time.Local)
+
+	all, err := runsToClean(runsDir, 0, now)
+	if err != nil {
+		t.Fatalf("runsToClean failed: %v", err)
+	}
+	if got := strings.Join(all, ","); got != "20250901T120000-yoda,20250920T120000-spock,20250924T110000-merged" {
+		t.Errorf("Expected every run directory, oldest first, got %s", got)
+	}
+
+	old, err := runsToClean(runsDir, 7*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("runsToClean failed: %v", err)
+	}
+	if got := strings.Join(old, ","); got != "20250901T120000-yoda" {
+		t.Errorf("Expected only the run older than 7 days, got %s", got)
+	}
+
+	if missing, err := runsToClean(filepath.Join(runsDir, "missing"), 0, now); err != nil || len(missing) != 0 {
+		t.Errorf("Expected no runs and no error for a missing runs directory, got %v, %v", missing, err)
+	}
+}
+
+func TestRunCleanCore(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), ".3pio")
+	runsDir := filepath.Join(outputDir, "runs")
+	oldRun := filepath.Join(runsDir, time.Now().Add(-48*time.Hour).Format(runIDTimestampLayout)+"-old")
+	newRun := filepath.Join(runsDir, time.Now().Format(runIDTimestampLayout)+"-new")
+	for _, dir := range []string{oldRun, newRun} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(runsDir, "latest.txt"), []byte(filepath.Base(oldRun)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, err := runCleanCore(outputDir, "1d", false); code != 0 || err != nil {
+		t.Fatalf("Expected clean --older-than 1d to succeed, got %d, %v", code, err)
+	}
+	if _, err := os.Stat(oldRun); !os.IsNotExist(err) {
+		t.Errorf("Expected the 2 day old run to be removed, got %v", err)
+	}
+	if _, err := os.Stat(newRun); err != nil {
+		t.Errorf("Expected the new run to be kept, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(runsDir, "latest.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected latest.txt pointing at the removed run to be removed, got %v", err)
+	}
+
+	if code, err := runCleanCore(outputDir, "1d", true); code != 1 || err == nil {
+		t.Errorf("Expected --all with --older-than to fail, got %d, %v", code, err)
+	}
+	if code, err := runCleanCore(outputDir, "", true); code != 0 || err != nil {
+		t.Fatalf("Expected clean --all to succeed, got %d, %v", code, err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("Expected clean --all to remove the output directory, got %v", err)
+	}
+}
+
+func TestRunCleanCore_RefusesOutsideOutputDir(t *testing.T) {
+	// A directory that isn't a 3pio output directory
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, err := runCleanCore(dir, "", true); code != 1 || err == nil {
+		t.Errorf("Expected clean to refuse a directory without runs, got %d, %v", code, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("Expected the directory to be left alone, got %v", err)
+	}
+
+	// The working directory itself
+	if code, err := runCleanCore(".", "", true); code != 1 || err == nil {
+		t.Errorf("Expected clean to refuse the working directory, got %d, %v", code, err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for value, want := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "12h": 12 * time.Hour, "90m": 90 * time.Minute} {
+		if got, err := parseAge(value); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "0d", "-1h", "week", "1.5d"} {
+		if _, err := parseAge(value); err == nil {
+			t.Errorf("Expected parseAge(%q) to fail", value)
+		}
+	}
+}
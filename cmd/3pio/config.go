@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configFileNames are the config files looked for in the working directory, in order.
+// Only the first one found is read.
+var configFileNames = []string{".3pio.yml", ".3pio.yaml", ".3pio.toml"}
+
+// configKeys maps config file keys to the flags they stand for. --run-id is left out
+// since a fixed run name only makes sense for a single run, --watch since it needs
+// a command that starts the runner in watch mode, --list-runners and --dry-run since
+// they never run the tests, and --test-name-filter and --test-file since they pick the tests of
+// a single run.
+var configKeys = map[string]string{
+	"junit":               "junit",
+	"outputDir":           "output-dir",
+	"keepRuns":            "keep-runs",
+	"quiet":               "quiet",
+	"verbose":             "verbose",
+	"slowThreshold":       "slow-threshold",
+	"goList":              "go-list",
+	"ginkgo":              "ginkgo",
+	"failFast":            "fail-fast",
+	"failOnSkip":          "fail-on-skip",
+	"allowNoEvents":       "allow-no-events",
+	"failUnder":           "fail-under",
+	"failUnderAllowEmpty": "fail-under-allow-empty",
+	"timeout":             "timeout",
+	"color":               "color",
+	"onlyFailures":        "only-failures",
+	"redactPaths":         "redact-paths",
+	"keepAnsi":            "keep-ansi",
+	"maxGroupOutput":      "max-group-output",
+	"maxFailures":         "max-failures",
+	"preflight":           "preflight",
+	"ipcSocket":           "ipc-socket",
+	"sort":                "sort",
+	"runner":              "runner",
+	"envAllowlist":        "env-allowlist",
+	"eventStream":         "event-stream",
+	"noOutputLog":         "no-output-log",
+	"separateStreams":     "separate-streams",
+	"syncOutput":          "sync-output",
+	"compressOutput":      "compress-output",
+	"adapterLogLevel":     "adapter-log-level",
+	"reportDebounce":      "report-debounce",
+	"reportMaxWait":       "report-max-wait",
+	"reportDetail":        "report-detail",
+	"collapseDepth":       "collapse-depth",
+	"retryOnCrash":        "retry-on-crash",
+}
+
+// loadConfigFile reads 3pio options from the first config file found in dir. It returns
+// the path it read ("" when there is none) and a warning for each line it ignored.
+// Invalid values are errors, just like invalid flags.
+func loadConfigFile(dir string) (cliOptions, string, []string, error) {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return cliOptions{}, path, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		separator := ":"
+		if filepath.Ext(name) == ".toml" {
+			separator = "="
+		}
+		opts, warnings, err := parseConfig(string(data), name, separator)
+		return opts, path, warnings, err
+	}
+	return cliOptions{}, "", nil, nil
+}
+
+// parseConfig parses flat "key: value" (YAML) or "key = value" (TOML) lines. Nested
+// tables, lists and multi-line values are not supported and are skipped with a warning.
+func parseConfig(content, name, separator string) (cliOptions, []string, error) {
+	var opts cliOptions
+	var warnings []string
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, separator)
+		if !ok || line != strings.TrimLeft(line, " \t") {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: ignoring unsupported line %q", name, lineNo, trimmed))
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = configValue(value)
+
+		flag, known := configKeys[key]
+		if !known {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: ignoring unknown key %q", name, lineNo, key))
+			continue
+		}
+
+		if isBoolFlag(flag) {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, warnings, fmt.Errorf("%s:%d: %s must be true or false, got %q", name, lineNo, key, value)
+			}
+			setBoolFlag(&opts, flag, b)
+			continue
+		}
+		if value == "" {
+			return opts, warnings, fmt.Errorf("%s:%d: %s requires a value", name, lineNo, key)
+		}
+		if err := setValueFlag(&opts, flag, value); err != nil {
+			return opts, warnings, fmt.Errorf("%s:%d: %w", name, lineNo, err)
+		}
+	}
+
+	return opts, warnings, nil
+}
+
+// configValue strips a trailing comment and surrounding quotes from a config value
+func configValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end != -1 {
+			return value[1 : end+1]
+		}
+	}
+	if idx := strings.Index(value, " #"); idx != -1 {
+		value = value[:idx]
+	}
+	return strings.TrimSpace(value)
+}
+
+// withDefaults fills in options not given on the command line from defaults, such as
+// the ones read from the config file
+func (o cliOptions) withDefaults(defaults cliOptions) cliOptions {
+	if o.JUnitPath == "" {
+		o.JUnitPath = defaults.JUnitPath
+	}
+	if o.OutputDir == "" {
+		o.OutputDir = defaults.OutputDir
+	}
+	if o.KeepRuns == 0 {
+		o.KeepRuns = defaults.KeepRuns
+	}
+	if o.RunName == "" {
+		o.RunName = defaults.RunName
+	}
+	if o.SlowThreshold == 0 {
+		o.SlowThreshold = defaults.SlowThreshold
+	}
+	if o.Timeout == 0 {
+		o.Timeout = defaults.Timeout
+	}
+	if o.Color == "" {
+		o.Color = defaults.Color
+	}
+	if o.MaxGroupOutput == 0 {
+		o.MaxGroupOutput = defaults.MaxGroupOutput
+	}
+	if o.MaxFailures == 0 {
+		o.MaxFailures = defaults.MaxFailures
+	}
+	if o.Sort == "" {
+		o.Sort = defaults.Sort
+	}
+	if o.Runner == "" {
+		o.Runner = defaults.Runner
+	}
+	if o.EnvAllowlist == nil {
+		o.EnvAllowlist = defaults.EnvAllowlist
+	}
+	if o.EventStream == "" {
+		o.EventStream = defaults.EventStream
+	}
+	if o.AdapterLogLevel == "" {
+		o.AdapterLogLevel = defaults.AdapterLogLevel
+	}
+	if o.ReportDebounce == 0 {
+		o.ReportDebounce = defaults.ReportDebounce
+	}
+	if o.ReportMaxWait == 0 {
+		o.ReportMaxWait = defaults.ReportMaxWait
+	}
+	if o.ReportDetail == "" {
+		o.ReportDetail = defaults.ReportDetail
+	}
+	if o.CollapseDepth == 0 {
+		o.CollapseDepth = defaults.CollapseDepth
+	}
+	if o.RetryOnCrash == 0 {
+		o.RetryOnCrash = defaults.RetryOnCrash
+	}
+	if o.FailUnder == 0 {
+		o.FailUnder = defaults.FailUnder
+	}
+	// A bool flag given on the command line wins; otherwise either side turns it on
+	mergeBool := func(name string, value *bool, fileValue bool) {
+		if !o.explicit[name] {
+			*value = *value || fileValue
+		}
+	}
+	mergeBool("quiet", &o.Quiet, defaults.Quiet)
+	mergeBool("verbose", &o.Verbose, defaults.Verbose)
+	mergeBool("go-list", &o.GoList, defaults.GoList)
+	mergeBool("ginkgo", &o.Ginkgo, defaults.Ginkgo)
+	mergeBool("fail-fast", &o.FailFast, defaults.FailFast)
+	mergeBool("fail-on-skip", &o.FailOnSkip, defaults.FailOnSkip)
+	mergeBool("allow-no-events", &o.AllowNoEvents, defaults.AllowNoEvents)
+	mergeBool("fail-under-allow-empty", &o.FailUnderEmpty, defaults.FailUnderEmpty)
+	mergeBool("only-failures", &o.OnlyFailures, defaults.OnlyFailures)
+	mergeBool("redact-paths", &o.RedactPaths, defaults.RedactPaths)
+	mergeBool("keep-ansi", &o.KeepANSI, defaults.KeepANSI)
+	mergeBool("preflight", &o.Preflight, defaults.Preflight)
+	mergeBool("ipc-socket", &o.IPCSocket, defaults.IPCSocket)
+	mergeBool("no-output-log", &o.NoOutputLog, defaults.NoOutputLog)
+	mergeBool("separate-streams", &o.SeparateStreams, defaults.SeparateStreams)
+	mergeBool("sync-output", &o.SyncOutput, defaults.SyncOutput)
+	mergeBool("compress-output", &o.CompressOutput, defaults.CompressOutput)
+	return o
+}
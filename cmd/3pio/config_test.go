@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, ".3pio.yml", `# Shared settings
+outputDir: build/3pio
+keepRuns: 10
+color: never   # CI logs
+slowThreshold: "2s"
+failFast: true
+failOnSkip: true
+`)
+
+	fileOpts, path, warnings, err := loadConfigFile(dir)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if path != filepath.Join(dir, ".3pio.yml") || len(warnings) != 0 {
+		t.Fatalf("loadConfigFile() path = %q, warnings = %v", path, warnings)
+	}
+
+	cliOpts, command, err := parseCLIFlags([]string{"--color", "always", "--timeout", "5m", "go", "test"})
+	if err != nil {
+		t.Fatalf("parseCLIFlags() error = %v", err)
+	}
+
+	// Flags win over the file, the file wins over the defaults
+	got := cliOpts.withDefaults(fileOpts)
+	want := cliOptions{
+		OutputDir:     "build/3pio",
+		KeepRuns:      10,
+		Color:         "always",
+		SlowThreshold: 2 * time.Second,
+		FailFast:      true,
+		FailOnSkip:    true,
+		Timeout:       5 * time.Minute,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged options = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(command, []string{"go", "test"}) {
+		t.Errorf("command = %v", command)
+	}
+
+	// A bool flag set to false on the command line turns off the file's true
+	cliOpts, _, err = parseCLIFlags([]string{"--fail-fast=false", "--quiet", "go", "test"})
+	if err != nil {
+		t.Fatalf("parseCLIFlags() error = %v", err)
+	}
+	got = cliOpts.withDefaults(fileOpts)
+	if got.FailFast || !got.FailOnSkip || !got.Quiet {
+		t.Errorf("merged options = %+v, want failFast off, failOnSkip from the file and quiet from the flag", got)
+	}
+
+	// Without a config file everything keeps its default
+	if got := (cliOptions{}).withDefaults(cliOptions{}); !reflect.DeepEqual(got, cliOptions{}) {
+		t.Errorf("defaults changed without a config file: %+v", got)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("no config file", func(t *testing.T) {
+		opts, path, warnings, err := loadConfigFile(t.TempDir())
+		if err != nil || path != "" || len(warnings) != 0 || !reflect.DeepEqual(opts, cliOptions{}) {
+			t.Errorf("loadConfigFile() = %+v, %q, %v, %v", opts, path, warnings, err)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFile(t, dir, ".3pio.toml", "keepRuns = 0\nquiet = true\njunit = 'reports/junit.xml'\n")
+		opts, _, _, err := loadConfigFile(dir)
+		if err != nil {
+			t.Fatalf("loadConfigFile() error = %v", err)
+		}
+		// 0 keeps every run, as on the command line
+		want := cliOptions{KeepRuns: -1, Quiet: true, JUnitPath: "reports/junit.xml"}
+		if !reflect.DeepEqual(opts, want) {
+			t.Errorf("options = %+v, want %+v", opts, want)
+		}
+	})
+
+	t.Run("unknown keys warn", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFile(t, dir, ".3pio.yml", "reporter: dot\nrunId: nightly\nreporters:\n  - json\nquiet: true\n")
+		opts, _, warnings, err := loadConfigFile(dir)
+		if err != nil {
+			t.Fatalf("loadConfigFile() error = %v", err)
+		}
+		if !opts.Quiet {
+			t.Error("Expected known keys after an unknown one to be read")
+		}
+		if len(warnings) != 4 || !strings.Contains(warnings[0], `.3pio.yml:1: ignoring unknown key "reporter"`) {
+			t.Errorf("warnings = %q", warnings)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigFile(t, dir, ".3pio.yml", "timeout: soon\n")
+		if _, _, _, err := loadConfigFile(dir); err == nil || !strings.Contains(err.Error(), ".3pio.yml:1:") {
+			t.Errorf("Expected an error pointing at the line, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/zk/3pio/internal/logger"
+	"github.com/zk/3pio/internal/report"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// newDiffCommand creates the "diff" subcommand, which lists the tests that started failing,
+// were fixed or disappeared between two runs
+func newDiffCommand() *cobra.Command {
+	var outDir string
+	var matchMoved bool
+	cmd := &cobra.Command{
+		Use:   "diff <old-run> <new-run>",
+		Short: "Compare the test results of two runs",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exitCode, _ := runDiffCore(args[0], args[1], outDir, matchMoved)
+			os.Exit(exitCode)
+			return nil // Never reached, but needed for signature
+		},
+	}
+	cmd.Flags().StringVar(&outDir, "output", "", "Directory for diff.md and diff.json (default: the new run)")
+	cmd.Flags().BoolVar(&matchMoved, "match-moved", false, "Match tests whose file was moved or renamed by their stable key")
+	return cmd
+}
+
+// runDiffCore compares two runs, given as run directories or run IDs, prints the diff and
+// writes diff.md and diff.json to outDir (testable). Exits 1 if any test started failing.
+func runDiffCore(oldRun, newRun, outDir string, matchMoved bool) (int, error) {
+	var runDirs [2]string
+	for i, run := range []string{oldRun, newRun} {
+		runDir, err := resolveRunDir(run)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1, err
+		}
+		runDirs[i] = runDir
+	}
+	if outDir == "" {
+		outDir = runDirs[1]
+	}
+
+	// Run directories live in <output-dir>/runs, next to debug.log
+	fileLogger, err := logger.NewFileLoggerInDir(filepath.Dir(filepath.Dir(runDirs[1])))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create debug logger: %v\n", err)
+		return 1, err
+	}
+	defer func() {
+		if err := fileLogger.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close debug log: %v\n", err)
+		}
+	}()
+
+	diff, err := report.Diff(runDirs[0], runDirs[1], matchMoved, runner.NewManager(fileLogger), fileLogger)
+	if err == nil {
+		err = report.WriteDiff(outDir, diff)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	fmt.Print(diff.Markdown())
+	fmt.Printf("\nDiff:        %s\n", filepath.Join(outDir, "diff.md"))
+	if len(diff.NewlyFailing) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// resolveRunDir returns the directory of a run given as a path or as a run ID under
+// <output-dir>/runs
+func resolveRunDir(run string) (string, error) {
+	if info, err := os.Stat(run); err == nil && info.IsDir() {
+		return run, nil
+	}
+	runDir := filepath.Join(defaultOutputDir(), "runs", run)
+	if info, err := os.Stat(runDir); err == nil && info.IsDir() {
+		return runDir, nil
+	}
+	return "", fmt.Errorf("run directory not found: %s", run)
+}
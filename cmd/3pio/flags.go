@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zk/3pio/internal/orchestrator"
+	"github.com/zk/3pio/internal/report"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// cliOptions holds 3pio's own flags. These must appear before the test command
+// so they are never confused with flags meant for the test runner.
+type cliOptions struct {
+	JUnitPath       string        // Where to write the JUnit XML report (defaults to the run directory)
+	OutputDir       string        // Base directory for runs and debug.log (defaults to .3pio)
+	KeepRuns        int           // Number of runs to keep (0 uses the default, negative keeps all)
+	RunName         string        // Fixed run name used instead of the random suffix
+	Quiet           bool          // Only print the final summary and report path
+	SlowThreshold   time.Duration // Test cases slower than this are listed as slow (0 disables)
+	GoList          bool          // Run "go list" to group Go tests by source file
+	Ginkgo          bool          // Report Ginkgo suites as their Describe/Context/It hierarchy
+	FailFast        bool          // Stop the test process at the first failing group
+	Timeout         time.Duration // Kill the test process after this long (0 disables)
+	Color           string        // "auto", "always" or "never" ("" means auto)
+	OnlyFailures    bool          // List only failing groups in test-run.md
+	RedactPaths     bool          // Replace the project root and home directory in reports
+	KeepANSI        bool          // Keep ANSI escape sequences in the output shown in group reports
+	Verbose         bool          // Print each test case result as it arrives
+	MaxGroupOutput  int           // Bytes of stdout/stderr kept per group report (0 keeps everything)
+	MaxFailures     int           // Failed tests keeping error details in reports (0 keeps all)
+	Preflight       bool          // Count pytest tests with --collect-only before the run
+	Watch           bool          // Keep the runner in its watch mode and report every re-run
+	IPCSocket       bool          // Read IPC events from a Unix domain socket instead of ipc.jsonl
+	ListRunners     bool          // Print runner detection for the command instead of running it
+	DryRun          bool          // Print the command, run directory and environment 3pio would use, then exit
+	Sort            string        // Order of groups in reports: name, status, duration or discovery ("" means name)
+	Runner          string        // Runner to use instead of detecting one, e.g. "vitest" ("" detects)
+	EnvAllowlist    []string      // Only these environment variables reach the test command (nil passes all)
+	EventStream     string        // Mirror each test event as a JSON line to this file, or "-" for stdout
+	NoOutputLog     bool          // Don't write the command's full output to output.log
+	SeparateStreams bool          // Also write the command's stderr to stderr.log
+	AdapterLogLevel string        // THREEPIO_LOG_LEVEL for the adapters: DEBUG, INFO, WARN or ERROR ("" leaves it alone)
+	TestNameFilter  string        // Run only tests matching this pattern, through the runner's own selector
+	TestFiles       []string      // Run only these test files (--test-file may be repeated)
+	ReportDebounce  time.Duration // Quiet time before reports are rewritten during a run (0 uses the defaults)
+	ReportMaxWait   time.Duration // Longest a report can lag behind the events (0 uses the defaults)
+	ReportDetail    string        // How much of each test group reports show: minimal, standard or full ("" means standard)
+	CollapseDepth   int           // Level below which group reports list subgroups' tests inline (0 shows every level)
+	RetryOnCrash    int           // Times to re-run a command that crashes before reporting results (0 disables)
+	FailOnSkip      bool          // Exit non-zero when any test or group was skipped
+	AllowNoEvents   bool          // Keep exit code 0 when the command passed but reported no test results
+	FailUnder       float64       // Pass only when at least this percentage of test cases passed (0 disables)
+	FailUnderEmpty  bool          // A run without test cases meets --fail-under
+	SyncOutput      bool          // Flush output.log to disk every second during the run
+	CompressOutput  bool          // Gzip output.log into output.log.gz once the run is finalized
+	Ingest          string        // Build a run from results in this format read from stdin, e.g. "go-json" ("" runs a command)
+
+	// Bool flags given on the command line, by name. Their value wins over the config
+	// file's, so --fail-fast=false turns off failFast: true.
+	explicit map[string]bool
+}
+
+// adapterLogLevels are the values --adapter-log-level accepts, as the adapters spell them
+var adapterLogLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// parseCLIFlags consumes leading 3pio flags and returns the remaining test command.
+// Parsing stops at the first argument that isn't a 3pio flag, or after a "--" separator.
+func parseCLIFlags(args []string) (cliOptions, []string, error) {
+	var opts cliOptions
+
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+
+		// Explicit end of 3pio flags
+		if arg == "--" {
+			return opts, args[i+1:], nil
+		}
+
+		// Test commands never start with "--", so anything else ends flag parsing
+		if !strings.HasPrefix(arg, "--") {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if isBoolFlag(name) {
+			b := true
+			if hasValue {
+				var err error
+				if b, err = strconv.ParseBool(value); err != nil {
+					return opts, nil, fmt.Errorf("flag --%s takes true or false, got %q", name, value)
+				}
+			}
+			setBoolFlag(&opts, name, b)
+			if opts.explicit == nil {
+				opts.explicit = make(map[string]bool)
+			}
+			opts.explicit[name] = true
+			i++
+			continue
+		}
+		if !isValueFlag(name) {
+			return opts, nil, fmt.Errorf("unknown flag: --%s", name)
+		}
+		v, consumed, err := flagValue(args, i, name, value, hasValue)
+		if err != nil {
+			return opts, nil, err
+		}
+		if err := setValueFlag(&opts, name, v); err != nil {
+			return opts, nil, err
+		}
+		i += consumed
+	}
+
+	return opts, args[i:], nil
+}
+
+// isBoolFlag reports whether name is a flag that takes no value, or true or false after "="
+func isBoolFlag(name string) bool {
+	switch name {
+	case "quiet", "verbose", "go-list", "ginkgo", "fail-fast", "fail-on-skip", "allow-no-events", "fail-under-allow-empty", "only-failures", "redact-paths", "keep-ansi", "preflight", "watch", "ipc-socket", "list-runners", "dry-run", "no-output-log", "separate-streams", "sync-output", "compress-output":
+		return true
+	}
+	return false
+}
+
+// isValueFlag reports whether name is a flag that requires a value
+func isValueFlag(name string) bool {
+	switch name {
+	case "junit", "output-dir", "keep-runs", "run-id", "slow-threshold", "timeout", "color", "max-group-output", "max-failures", "sort", "runner", "env-allowlist", "event-stream", "adapter-log-level", "test-name-filter", "test-file", "report-debounce", "report-max-wait", "report-detail", "collapse-depth", "retry-on-crash", "fail-under", "ingest":
+		return true
+	}
+	return false
+}
+
+// setBoolFlag sets the option for a flag accepted by isBoolFlag
+func setBoolFlag(opts *cliOptions, name string, value bool) {
+	switch name {
+	case "quiet":
+		opts.Quiet = value
+	case "verbose":
+		opts.Verbose = value
+	case "go-list":
+		opts.GoList = value
+	case "ginkgo":
+		opts.Ginkgo = value
+	case "fail-fast":
+		opts.FailFast = value
+	case "fail-on-skip":
+		opts.FailOnSkip = value
+	case "allow-no-events":
+		opts.AllowNoEvents = value
+	case "fail-under-allow-empty":
+		opts.FailUnderEmpty = value
+	case "only-failures":
+		opts.OnlyFailures = value
+	case "redact-paths":
+		opts.RedactPaths = value
+	case "keep-ansi":
+		opts.KeepANSI = value
+	case "preflight":
+		opts.Preflight = value
+	case "watch":
+		opts.Watch = value
+	case "ipc-socket":
+		opts.IPCSocket = value
+	case "list-runners":
+		opts.ListRunners = value
+	case "dry-run":
+		opts.DryRun = value
+	case "no-output-log":
+		opts.NoOutputLog = value
+	case "separate-streams":
+		opts.SeparateStreams = value
+	case "sync-output":
+		opts.SyncOutput = value
+	case "compress-output":
+		opts.CompressOutput = value
+	}
+}
+
+// setValueFlag validates and sets the option for a flag accepted by isValueFlag
+func setValueFlag(opts *cliOptions, name, v string) error {
+	switch name {
+	case "junit":
+		opts.JUnitPath = v
+	case "output-dir":
+		opts.OutputDir = v
+	case "keep-runs":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("flag --%s requires a non-negative integer, got %q", name, v)
+		}
+		// On the command line 0 means keep everything
+		if n == 0 {
+			n = -1
+		}
+		opts.KeepRuns = n
+	case "run-id":
+		opts.RunName = v
+	case "slow-threshold":
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("flag --%s requires a positive duration such as 500ms or 2s, got %q", name, v)
+		}
+		opts.SlowThreshold = d
+	case "timeout":
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("flag --%s requires a positive duration such as 30s or 10m, got %q", name, v)
+		}
+		opts.Timeout = d
+	case "report-debounce", "report-max-wait":
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("flag --%s requires a positive duration such as 50ms or 2s, got %q", name, v)
+		}
+		if name == "report-debounce" {
+			opts.ReportDebounce = d
+		} else {
+			opts.ReportMaxWait = d
+		}
+	case "color":
+		if v != "auto" && v != "always" && v != "never" {
+			return fmt.Errorf("flag --%s must be auto, always or never, got %q", name, v)
+		}
+		opts.Color = v
+	case "max-group-output":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("flag --%s requires a positive number of bytes, got %q", name, v)
+		}
+		opts.MaxGroupOutput = n
+	case "max-failures":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("flag --%s requires a positive number of failures, got %q", name, v)
+		}
+		opts.MaxFailures = n
+	case "fail-under":
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return fmt.Errorf("flag --%s requires a percentage above 0 and at most 100, got %q", name, v)
+		}
+		opts.FailUnder = percent
+	case "retry-on-crash":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("flag --%s requires a positive number of retries, got %q", name, v)
+		}
+		opts.RetryOnCrash = n
+	case "sort":
+		if _, err := report.ParseGroupOrder(v); err != nil {
+			return fmt.Errorf("flag --%s must be name, status, duration or discovery, got %q", name, v)
+		}
+		opts.Sort = v
+	case "report-detail":
+		if _, err := report.ParseReportDetail(v); err != nil {
+			return fmt.Errorf("flag --%s must be minimal, standard or full, got %q", name, v)
+		}
+		opts.ReportDetail = v
+	case "collapse-depth":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("flag --%s requires a positive number of levels, got %q", name, v)
+		}
+		opts.CollapseDepth = n
+	case "runner":
+		valid := runner.BuiltinNames()
+		if !slices.Contains(valid, v) {
+			return fmt.Errorf("flag --%s must be one of %s, got %q", name, strings.Join(valid, ", "), v)
+		}
+		opts.Runner = v
+	case "env-allowlist":
+		var names []string
+		for _, variable := range strings.Split(v, ",") {
+			variable = strings.TrimSpace(variable)
+			if variable == "" {
+				continue
+			}
+			if strings.Contains(variable, "=") {
+				return fmt.Errorf("flag --%s takes variable names separated by commas, got %q", name, v)
+			}
+			names = append(names, variable)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("flag --%s requires at least one variable name", name)
+		}
+		opts.EnvAllowlist = names
+	case "event-stream":
+		opts.EventStream = v
+	case "adapter-log-level":
+		level := strings.ToUpper(v)
+		if !slices.Contains(adapterLogLevels, level) {
+			return fmt.Errorf("flag --%s must be debug, info, warn or error, got %q", name, v)
+		}
+		opts.AdapterLogLevel = level
+	case "test-name-filter":
+		opts.TestNameFilter = v
+	case "test-file":
+		opts.TestFiles = append(opts.TestFiles, v)
+	case "ingest":
+		formats := orchestrator.IngestFormats()
+		if !slices.Contains(formats, v) {
+			return fmt.Errorf("flag --%s must be one of %s, got %q", name, strings.Join(formats, ", "), v)
+		}
+		opts.Ingest = v
+	}
+	return nil
+}
+
+// flagValue returns the value for a flag given as either "--name=value" or "--name value",
+// along with the number of arguments consumed.
+func flagValue(args []string, i int, name, value string, hasValue bool) (string, int, error) {
+	if hasValue {
+		if value == "" {
+			return "", 0, fmt.Errorf("flag --%s requires a value", name)
+		}
+		return value, 1, nil
+	}
+	if i+1 >= len(args) || args[i+1] == "" {
+		return "", 0, fmt.Errorf("flag --%s requires a value", name)
+	}
+	return args[i+1], 2, nil
+}
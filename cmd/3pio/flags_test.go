@@ -0,0 +1,410 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCLIFlags(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		args        []string
+		wantOpts    cliOptions
+		wantCommand []string
+		wantErr     bool
+	}{
+		{
+			desc:        "no flags",
+			args:        []string{"npx", "jest"},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:        "junit with separate value",
+			args:        []string{"--junit", "out.xml", "go", "test", "./..."},
+			wantOpts:    cliOptions{JUnitPath: "out.xml"},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "junit with equals value",
+			args:        []string{"--junit=out.xml", "pytest"},
+			wantOpts:    cliOptions{JUnitPath: "out.xml"},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:        "runner flags after command are untouched",
+			args:        []string{"npx", "jest", "--junit", "x"},
+			wantCommand: []string{"npx", "jest", "--junit", "x"},
+		},
+		{
+			desc:        "double dash ends 3pio flags",
+			args:        []string{"--junit", "out.xml", "--", "npm", "test"},
+			wantOpts:    cliOptions{JUnitPath: "out.xml"},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:        "output dir",
+			args:        []string{"--output-dir", "../.3pio", "--junit=out.xml", "pytest"},
+			wantOpts:    cliOptions{JUnitPath: "out.xml", OutputDir: "../.3pio"},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:        "keep runs",
+			args:        []string{"--keep-runs", "10", "npm", "test"},
+			wantOpts:    cliOptions{KeepRuns: 10},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:        "keep runs zero keeps all",
+			args:        []string{"--keep-runs=0", "npm", "test"},
+			wantOpts:    cliOptions{KeepRuns: -1},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:    "keep runs not a number",
+			args:    []string{"--keep-runs", "many", "npm", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "run id",
+			args:        []string{"--run-id", "job-1234", "cargo", "test"},
+			wantOpts:    cliOptions{RunName: "job-1234"},
+			wantCommand: []string{"cargo", "test"},
+		},
+		{
+			desc:        "quiet",
+			args:        []string{"--quiet", "--run-id=ci", "go", "test", "./..."},
+			wantOpts:    cliOptions{RunName: "ci", Quiet: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:    "quiet does not take a value",
+			args:    []string{"--quiet=yes", "go", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "slow threshold",
+			args:        []string{"--slow-threshold", "1.5s", "pytest"},
+			wantOpts:    cliOptions{SlowThreshold: 1500 * time.Millisecond},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:    "slow threshold without unit",
+			args:    []string{"--slow-threshold=2", "pytest"},
+			wantErr: true,
+		},
+		{
+			desc:        "go list",
+			args:        []string{"--go-list", "go", "test", "./..."},
+			wantOpts:    cliOptions{GoList: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "keep ansi",
+			args:        []string{"--keep-ansi", "npx", "jest"},
+			wantOpts:    cliOptions{KeepANSI: true},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:        "ginkgo",
+			args:        []string{"--ginkgo", "go", "test", "./..."},
+			wantOpts:    cliOptions{Ginkgo: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "fail fast",
+			args:        []string{"--fail-fast", "--quiet", "pytest"},
+			wantOpts:    cliOptions{FailFast: true, Quiet: true},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:        "fail on skip",
+			args:        []string{"--fail-on-skip", "pytest", "-x"},
+			wantOpts:    cliOptions{FailOnSkip: true},
+			wantCommand: []string{"pytest", "-x"},
+		},
+		{
+			desc:        "allow no events",
+			args:        []string{"--allow-no-events", "npm", "test"},
+			wantOpts:    cliOptions{AllowNoEvents: true},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:        "timeout",
+			args:        []string{"--timeout=10m", "go", "test", "./..."},
+			wantOpts:    cliOptions{Timeout: 10 * time.Minute},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:    "negative timeout",
+			args:    []string{"--timeout", "-5s", "pytest"},
+			wantErr: true,
+		},
+		{
+			desc:        "report write intervals",
+			args:        []string{"--report-debounce", "1s", "--report-max-wait=10s", "npx", "jest"},
+			wantOpts:    cliOptions{ReportDebounce: time.Second, ReportMaxWait: 10 * time.Second},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:    "zero report debounce",
+			args:    []string{"--report-debounce", "0s", "npx", "jest"},
+			wantErr: true,
+		},
+		{
+			desc:        "report detail",
+			args:        []string{"--report-detail=minimal", "pytest"},
+			wantOpts:    cliOptions{ReportDetail: "minimal"},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:    "unknown report detail",
+			args:    []string{"--report-detail", "verbose", "pytest"},
+			wantErr: true,
+		},
+		{
+			desc:        "collapse depth",
+			args:        []string{"--collapse-depth", "2", "cargo", "test"},
+			wantOpts:    cliOptions{CollapseDepth: 2},
+			wantCommand: []string{"cargo", "test"},
+		},
+		{
+			desc:    "zero collapse depth",
+			args:    []string{"--collapse-depth=0", "cargo", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "color",
+			args:        []string{"--color", "never", "npm", "test"},
+			wantOpts:    cliOptions{Color: "never"},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:        "only failures",
+			args:        []string{"--only-failures", "npx", "jest"},
+			wantOpts:    cliOptions{OnlyFailures: true},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:        "redact paths",
+			args:        []string{"--redact-paths", "npx", "jest"},
+			wantOpts:    cliOptions{RedactPaths: true},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:        "verbose",
+			args:        []string{"--verbose", "pytest", "-v"},
+			wantOpts:    cliOptions{Verbose: true},
+			wantCommand: []string{"pytest", "-v"},
+		},
+		{
+			desc:        "preflight",
+			args:        []string{"--preflight", "pytest", "tests/"},
+			wantOpts:    cliOptions{Preflight: true},
+			wantCommand: []string{"pytest", "tests/"},
+		},
+		{
+			desc:        "ipc socket",
+			args:        []string{"--ipc-socket", "go", "test", "./..."},
+			wantOpts:    cliOptions{IPCSocket: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "list runners",
+			args:        []string{"--list-runners", "npm", "test"},
+			wantOpts:    cliOptions{ListRunners: true},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:        "dry run",
+			args:        []string{"--dry-run", "cargo", "test"},
+			wantOpts:    cliOptions{DryRun: true},
+			wantCommand: []string{"cargo", "test"},
+		},
+		{
+			desc:        "no output log",
+			args:        []string{"--no-output-log", "cargo", "test"},
+			wantOpts:    cliOptions{NoOutputLog: true},
+			wantCommand: []string{"cargo", "test"},
+		},
+		{
+			desc:        "separate streams",
+			args:        []string{"--separate-streams", "npx", "jest"},
+			wantOpts:    cliOptions{SeparateStreams: true},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:        "ingest",
+			args:        []string{"--ingest", "go-json"},
+			wantOpts:    cliOptions{Ingest: "go-json"},
+			wantCommand: []string{},
+		},
+		{
+			desc:    "unknown ingest format",
+			args:    []string{"--ingest=junit"},
+			wantErr: true,
+		},
+		{
+			desc:        "sync output",
+			args:        []string{"--sync-output", "go", "test", "./..."},
+			wantOpts:    cliOptions{SyncOutput: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "compress output",
+			args:        []string{"--compress-output", "go", "test", "./..."},
+			wantOpts:    cliOptions{CompressOutput: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:        "max group output",
+			args:        []string{"--max-group-output", "65536", "go", "test", "./..."},
+			wantOpts:    cliOptions{MaxGroupOutput: 65536},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:    "invalid max group output",
+			args:    []string{"--max-group-output=64KB", "go", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "max failures",
+			args:        []string{"--max-failures", "100", "go", "test", "./..."},
+			wantOpts:    cliOptions{MaxFailures: 100},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:    "invalid max failures",
+			args:    []string{"--max-failures=0", "go", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "fail under",
+			args:        []string{"--fail-under", "97.5%", "--fail-under-allow-empty", "go", "test", "./..."},
+			wantOpts:    cliOptions{FailUnder: 97.5, FailUnderEmpty: true},
+			wantCommand: []string{"go", "test", "./..."},
+		},
+		{
+			desc:    "invalid fail under",
+			args:    []string{"--fail-under=101", "go", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "retry on crash",
+			args:        []string{"--retry-on-crash=2", "npx", "jest"},
+			wantOpts:    cliOptions{RetryOnCrash: 2},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:    "invalid retry on crash",
+			args:    []string{"--retry-on-crash", "0", "npx", "jest"},
+			wantErr: true,
+		},
+		{
+			desc:        "sort",
+			args:        []string{"--sort=status", "npx", "jest"},
+			wantOpts:    cliOptions{Sort: "status"},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:    "invalid sort",
+			args:    []string{"--sort", "size", "npx", "jest"},
+			wantErr: true,
+		},
+		{
+			desc:        "runner",
+			args:        []string{"--runner", "vitest", "npm", "test"},
+			wantOpts:    cliOptions{Runner: "vitest"},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:    "unknown runner",
+			args:    []string{"--runner=karma", "npm", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "env allowlist",
+			args:        []string{"--env-allowlist", "HOME, CI,NODE_OPTIONS", "npm", "test"},
+			wantOpts:    cliOptions{EnvAllowlist: []string{"HOME", "CI", "NODE_OPTIONS"}},
+			wantCommand: []string{"npm", "test"},
+		},
+		{
+			desc:    "env allowlist with values",
+			args:    []string{"--env-allowlist=CI=true", "npm", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "event stream to stdout",
+			args:        []string{"--event-stream", "-", "--quiet", "pytest"},
+			wantOpts:    cliOptions{EventStream: "-", Quiet: true},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:        "adapter log level",
+			args:        []string{"--adapter-log-level", "debug", "npx", "jest"},
+			wantOpts:    cliOptions{AdapterLogLevel: "DEBUG"},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:    "invalid adapter log level",
+			args:    []string{"--adapter-log-level=trace", "npx", "jest"},
+			wantErr: true,
+		},
+		{
+			desc:        "test name filter and repeated test files",
+			args:        []string{"--test-name-filter", "adds items", "--test-file=a.test.js", "--test-file", "b.test.js", "npx", "jest"},
+			wantOpts:    cliOptions{TestNameFilter: "adds items", TestFiles: []string{"a.test.js", "b.test.js"}},
+			wantCommand: []string{"npx", "jest"},
+		},
+		{
+			desc:    "invalid color",
+			args:    []string{"--color=yes", "npm", "test"},
+			wantErr: true,
+		},
+		{
+			desc:        "fail fast with value",
+			args:        []string{"--fail-fast=true", "pytest"},
+			wantOpts:    cliOptions{FailFast: true},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:        "fail fast turned off",
+			args:        []string{"--fail-fast=false", "pytest"},
+			wantOpts:    cliOptions{},
+			wantCommand: []string{"pytest"},
+		},
+		{
+			desc:    "missing value",
+			args:    []string{"--junit"},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown flag",
+			args:    []string{"--bogus", "npm", "test"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts, command, err := parseCLIFlags(tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error for %v", tc.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// Which flags were given is covered by the config precedence tests
+			opts.explicit = nil
+			if !reflect.DeepEqual(opts, tc.wantOpts) {
+				t.Errorf("Expected options %+v, got %+v", tc.wantOpts, opts)
+			}
+			if !reflect.DeepEqual(command, tc.wantCommand) {
+				t.Errorf("Expected command %v, got %v", tc.wantCommand, command)
+			}
+		})
+	}
+}
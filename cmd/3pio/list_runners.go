@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zk/3pio/internal/orchestrator"
+)
+
+// printRunnerDetection prints which runner 3pio detects for args and the command it
+// would execute (--list-runners), then returns without running anything
+func printRunnerDetection(orch *orchestrator.Orchestrator, args []string) (int, error) {
+	detection, err := orch.DetectRunner()
+	if err != nil {
+		printUnsupportedRunner(args)
+		return 1, err
+	}
+
+	adapter := detection.AdapterFile
+	if adapter == "" {
+		adapter = "none (native runner)"
+	}
+	fmt.Printf("Runner:      %s\n", detection.Runner)
+	fmt.Printf("Definition:  %s\n", detection.Definition)
+	fmt.Printf("Adapter:     %s\n", adapter)
+	fmt.Printf("Command:     %s\n", strings.Join(detection.Command, " "))
+	if len(detection.AlsoMatched) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: the command also matches %s; detection may pick either\n", strings.Join(detection.AlsoMatched, ", "))
+	}
+	return 0, nil
+}
+
+// printDryRun prints the command 3pio would execute for args, the run directory it would
+// create and the environment variables it would add (--dry-run), then returns without
+// running anything
+func printDryRun(orch *orchestrator.Orchestrator, args []string) (int, error) {
+	dryRun, err := orch.DryRun()
+	if err != nil {
+		if strings.Contains(err.Error(), "no test runner detected") {
+			printUnsupportedRunner(args)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return 1, err
+	}
+
+	adapter := dryRun.AdapterPath
+	if adapter == "" {
+		adapter = "none (native runner)"
+	}
+	fmt.Printf("Runner:      %s\n", dryRun.Runner)
+	fmt.Printf("Run dir:     %s\n", dryRun.RunDir)
+	fmt.Printf("Adapter:     %s\n", adapter)
+	fmt.Printf("Command:     %s\n", strings.Join(dryRun.Command, " "))
+	for i, entry := range dryRun.Env {
+		label := ""
+		if i == 0 {
+			label = "Env:"
+		}
+		fmt.Printf("%-12s %s\n", label, entry)
+	}
+	return 0, nil
+}
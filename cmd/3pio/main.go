@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/zk/3pio/internal/logger"
 	"github.com/zk/3pio/internal/orchestrator"
+	"github.com/zk/3pio/internal/report"
+	"github.com/zk/3pio/internal/runner"
 )
 
 var (
@@ -18,15 +20,77 @@ var (
 
 func main() {
 	var rootCmd = &cobra.Command{
-		Use:   "3pio [your full test command] | [flags]",
+		Use:   "3pio [3pio flags] [your full test command] | [flags]",
 		Short: "Context-optimized test runner adapter",
 		Long: `3pio translates test runs into a format optimized for AI agents, providing
 context-optimized console output and file-based records.
 
-Structured reports are written to .3pio/runs/[timestamp]-[memorable-name]/:
+Structured reports are written to .3pio/runs/[timestamp]-[memorable-name]/
+(.3pio/runs/latest always points at the most recent run):
 • test-run.md  - Main report with test summary and individual test results
 • output.log   - Complete stdout/stderr output from the entire test run  
+• stderr.log   - The test command's stderr alone (with --separate-streams)
 • logs/*.log   - Per-file output with test case demarcation
+• test-run.xml - JUnit XML report for CI systems
+• run.json     - Machine-readable run summary (versioned schema)
+
+3pio flags (must come before the test command):
+  --junit <path>                   # Write the JUnit XML report to <path>
+  --output-dir <dir>               # Use <dir> instead of .3pio (or set THREEPIO_OUTPUT_DIR)
+  --keep-runs <n>                  # Keep the n most recent runs (default 50, 0 keeps all)
+  --run-id <name>                  # Name the run [timestamp]-<name> instead of a random name
+  --quiet                          # Only print the final results and report path
+  --verbose                        # Print each test result as it arrives (at most 20 passing per second)
+  --slow-threshold <duration>      # List tests slower than <duration> (e.g. 2s) in test-run.md
+  --max-group-output <bytes>       # Cap each group report's stdout/stderr at <bytes>, keeping the start and end
+  --max-failures <n>               # Keep error details for only the first <n> failed tests (the rest are still counted)
+  --go-list                        # Group go test results by test file (runs go list, ~200-500ms)
+  --ginkgo                         # Report Ginkgo suites as Describe/Context/It groups (detected when every tested package uses Ginkgo)
+  --preflight                      # Count pytest tests with --collect-only before running them
+  --watch                          # Report every re-run of Jest or Vitest watch mode (e.g. 3pio --watch npx jest --watch)
+  --runner <name>                  # Use this runner instead of detecting one (e.g. vitest when jest is also installed)
+  --list-runners                   # Print the detected runner and the command 3pio would run, without running it
+  --dry-run                        # Print the final command, run directory and added environment, without running it
+  --ingest go-json                 # Build a run from a saved go test -json stream on stdin instead of running tests (3pio --ingest go-json < results.jsonl)
+  --ipc-socket                     # Send test events over a Unix socket instead of a file (native runners and pytest)
+  --env-allowlist <VAR,...>        # Pass only these environment variables (plus PATH) to the test command
+  --event-stream <path|->          # Write each test event as a JSON line to <path> (- for stdout) while the run is going
+  --no-output-log                  # Don't save the full command output to output.log (saves disk on huge suites)
+  --separate-streams               # Also write the command's stderr to stderr.log
+  --sync-output                    # Flush output.log to disk every second, so a machine crash loses at most a second of output (slower on busy disks)
+  --compress-output                # Gzip output.log into output.log.gz when the run ends (for very large outputs)
+  --adapter-log-level <level>      # Set THREEPIO_LOG_LEVEL for the test adapters: debug, info, warn (default) or error (debug is slow and verbose)
+  --test-name-filter <pattern>     # Run only tests matching <pattern>, passed as the runner's own selector (jest -t, go test -run, pytest -k, ...)
+  --test-file <path>               # Run only this test file; repeat for more (JavaScript runners, pytest, playwright, rspec)
+  --fail-fast                      # Stop the test run at the first failing group
+  --fail-on-skip                   # Exit non-zero if any test or group was skipped, even when the runner passed
+  --allow-no-events                # Exit 0 when the runner passed without reporting any test results (only warn)
+  --fail-under <percent>           # Pass if at least <percent> of test cases passed and fail otherwise, whatever the runner's exit code
+  --fail-under-allow-empty         # Let a run without test cases meet --fail-under (by default it fails)
+  --timeout <duration>             # Kill the test run after <duration> (e.g. 10m), exit code 124
+  --retry-on-crash <n>             # Run the command again, up to <n> times, if it crashes before reporting any results
+  --color <auto|always|never>      # Color PASS/FAIL output (auto: only on a terminal without NO_COLOR)
+  --only-failures                  # List only failing groups in test-run.md (totals stay complete)
+  --redact-paths                   # Show the project root as <root> and the home directory as ~ in reports
+  --keep-ansi                      # Keep color codes in the output shown in group reports (stripped by default; output.log is always raw)
+  --report-debounce <duration>     # Wait for <duration> without events before rewriting reports (default 200ms, 100ms for group reports)
+  --report-max-wait <duration>     # Let reports lag behind the events by at most <duration> (default 1s for test-run.md, no limit for group reports)
+  --report-detail <level>          # Leave passing tests out of group reports (minimal), list every test (standard, default) or add every test's stdout/stderr (full)
+  --collapse-depth <n>             # Show at most <n> levels of groups in group reports; level <n> lists the tests of deeper groups as "describe > test"
+  --sort <order>                   # Order groups in reports by name (default), status, duration or discovery
+
+Several commands separated by --and run one after another, each reported as its own run,
+then combined into one report with a top-level group per command. The exit code is the
+highest of the commands' (--fail-fast skips the rest once one fails).
+
+Flags can also be set in .3pio.yml (or .3pio.toml) in the working directory, e.g.
+"keepRuns: 10" or "slowThreshold: 2s". Flags given on the command line win.
+
+Commands:
+  3pio report <run-dir>            # Regenerate a run's reports from its ipc.jsonl
+  3pio merge <run-dir|glob>...     # Merge runs such as jest --shard shards into a new run (--output <dir>)
+  3pio diff <old-run> <new-run>    # List tests newly failing, fixed or gone since an earlier run (diff.md, diff.json)
+  3pio clean [--older-than 7d]     # Remove runs from .3pio (all of them, or those older than 7d); --all removes .3pio
 
 Examples:
   3pio npm test                    # Run npm test script
@@ -34,11 +98,18 @@ Examples:
   3pio npx jest                    # Run Jest directly
   3pio npx vitest run              # Run Vitest
   3pio pytest                      # Run pytest
-  3pio cargo test                  # Run Rust tests`,
+  3pio cargo test                  # Run Rust tests
+  3pio mvn test                    # Run Maven (Surefire) tests
+  3pio --junit out.xml go test ./... # Write JUnit XML to out.xml
+  3pio go test ./... --and npm test  # Run both and combine their results`,
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
 	}
 
-	// No subcommands - 3pio works as a direct wrapper
+	// "report", "merge", "diff" and "clean" are the only subcommands; anything else is a test command to wrap
+	rootCmd.AddCommand(newReportCommand())
+	rootCmd.AddCommand(newMergeCommand())
+	rootCmd.AddCommand(newDiffCommand())
+	rootCmd.AddCommand(newCleanCommand())
 
 	// Allow running without "run" subcommand
 	rootCmd.DisableFlagParsing = true
@@ -85,14 +156,62 @@ Flags:
 
 // runTestsCore contains the core logic for running tests (testable)
 func runTestsCore(args []string) (int, error) {
-	// Check for unsupported modes
-	if err := checkUnsupportedModes(args); err != nil {
+	// Split 3pio's own flags from the test command
+	opts, args, err := parseCLIFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	// Flags take precedence over the config file, which takes precedence over the defaults
+	fileOpts, configPath, warnings, err := loadConfigFile(".")
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	// The flag takes precedence over the environment variable, which takes precedence over the config file
+	if opts.OutputDir == "" {
+		opts.OutputDir = os.Getenv("THREEPIO_OUTPUT_DIR")
+	}
+	// Likewise --adapter-log-level wins over THREEPIO_LOG_LEVEL, which wins over the config file
+	if os.Getenv("THREEPIO_LOG_LEVEL") != "" {
+		fileOpts.AdapterLogLevel = ""
+	}
+	opts = opts.withDefaults(fileOpts)
+
+	// --ingest reads results from stdin, so there is nothing to run
+	if opts.Ingest != "" && len(args) > 0 {
+		err := fmt.Errorf("--ingest reads results from stdin and takes no test command, got %q", strings.Join(args, " "))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1, err
 	}
 
+	// "--and" separates the commands of a combined run; watch mode never ends, so
+	// nothing could come after it
+	commands := orchestrator.SplitCommands(args)
+	if len(commands) == 1 {
+		args = commands[0]
+	}
+	if len(commands) > 1 && opts.Watch {
+		err := fmt.Errorf("--watch can't be combined with %s", orchestrator.CommandSeparator)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	// Check for unsupported modes (--watch lets the runner's own watch mode through)
+	for _, command := range commands {
+		if err := checkUnsupportedModes(command, opts.Watch); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1, err
+		}
+	}
+
 	// Create file logger
-	fileLogger, err := logger.NewFileLogger()
+	fileLogger, err := logger.NewFileLoggerInDir(opts.OutputDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create debug logger: %v\n", err)
 		return 1, err
@@ -102,11 +221,70 @@ func runTestsCore(args []string) (int, error) {
 			fmt.Fprintf(os.Stderr, "Warning: failed to close debug log: %v\n", err)
 		}
 	}()
+	if configPath != "" {
+		fileLogger.Debug("Read 3pio options from %s", configPath)
+	}
+
+	// Set after the file logger is created so only the adapters' log level changes
+	if opts.AdapterLogLevel != "" {
+		if err := os.Setenv("THREEPIO_LOG_LEVEL", opts.AdapterLogLevel); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to set adapter log level: %v\n", err)
+			return 1, err
+		}
+		fileLogger.Debug("Adapter log level: %s", opts.AdapterLogLevel)
+	}
 
 	// Create orchestrator configuration
 	config := orchestrator.Config{
-		Command: args,
-		Logger:  fileLogger,
+		Command:         args,
+		Logger:          fileLogger,
+		JUnitPath:       opts.JUnitPath,
+		OutputDir:       opts.OutputDir,
+		KeepRuns:        opts.KeepRuns,
+		RunName:         opts.RunName,
+		Quiet:           opts.Quiet,
+		SlowThreshold:   opts.SlowThreshold,
+		GoList:          opts.GoList,
+		Ginkgo:          opts.Ginkgo,
+		FailFast:        opts.FailFast,
+		Timeout:         opts.Timeout,
+		Color:           opts.Color,
+		OnlyFailures:    opts.OnlyFailures,
+		RedactPaths:     opts.RedactPaths,
+		KeepANSI:        opts.KeepANSI,
+		Verbose:         opts.Verbose,
+		MaxGroupOutput:  opts.MaxGroupOutput,
+		MaxFailures:     opts.MaxFailures,
+		Preflight:       opts.Preflight,
+		Watch:           opts.Watch,
+		IPCSocket:       opts.IPCSocket,
+		GroupOrder:      report.GroupOrder(opts.Sort),
+		Runner:          opts.Runner,
+		EnvAllowlist:    opts.EnvAllowlist,
+		EventStream:     opts.EventStream,
+		NoOutputLog:     opts.NoOutputLog,
+		SeparateStreams: opts.SeparateStreams,
+		TestNameFilter:  opts.TestNameFilter,
+		TestFiles:       opts.TestFiles,
+		ReportDebounce:  opts.ReportDebounce,
+		ReportMaxWait:   opts.ReportMaxWait,
+		ReportDetail:    report.ReportDetail(opts.ReportDetail),
+		CollapseDepth:   opts.CollapseDepth,
+		RetryOnCrash:    opts.RetryOnCrash,
+		FailOnSkip:      opts.FailOnSkip,
+		AllowNoEvents:   opts.AllowNoEvents,
+		FailUnder:       opts.FailUnder,
+		FailUnderEmpty:  opts.FailUnderEmpty,
+		SyncOutput:      opts.SyncOutput,
+		CompressOutput:  opts.CompressOutput,
+	}
+
+	// Each command of a combined run is run and reported on its own first
+	if len(commands) > 1 && !opts.ListRunners && !opts.DryRun {
+		return orchestrator.RunCommands(config, commands)
+	}
+	if len(commands) > 1 {
+		return forEachCommand(config, commands, opts.DryRun)
 	}
 
 	// Create and run orchestrator
@@ -116,51 +294,123 @@ func runTestsCore(args []string) (int, error) {
 		return 1, err
 	}
 
+	// --ingest builds the run from stdin instead of running a command
+	if opts.Ingest != "" {
+		if err := orch.Ingest(opts.Ingest, os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return orch.GetExitCode(), err
+		}
+		return orch.GetExitCode(), nil
+	}
+
+	// --list-runners stops after detection
+	if opts.ListRunners {
+		return printRunnerDetection(orch, args)
+	}
+
+	// --dry-run stops once the command is built
+	if opts.DryRun {
+		return printDryRun(orch, args)
+	}
+
 	// Run tests
 	if err := orch.Run(); err != nil {
 		// Check if it's a test runner not found error
 		if strings.Contains(err.Error(), "no test runner detected") {
-			fmt.Fprintf(os.Stderr, "\nError: Could not detect test runner from command: %s\n", strings.Join(args, " "))
-			fmt.Fprintf(os.Stderr, "\n3pio currently supports:\n")
-			fmt.Fprintf(os.Stderr, "\nTest Runners:\n")
-			fmt.Fprintf(os.Stderr, "  • Jest\n")
-			fmt.Fprintf(os.Stderr, "  • Vitest (requires v3.0+)\n")
-			fmt.Fprintf(os.Stderr, "  • pytest\n")
-			fmt.Fprintf(os.Stderr, "  • go test\n")
-			fmt.Fprintf(os.Stderr, "  • cargo test\n")
-			fmt.Fprintf(os.Stderr, "\nPackage Managers:\n")
-			fmt.Fprintf(os.Stderr, "  • npm\n")
-			fmt.Fprintf(os.Stderr, "  • yarn\n")
-			fmt.Fprintf(os.Stderr, "  • pnpm\n")
-			fmt.Fprintf(os.Stderr, "  • bun\n")
-			fmt.Fprintf(os.Stderr, "\nExample usage:\n")
-			fmt.Fprintf(os.Stderr, "  3pio npm test\n")
-			fmt.Fprintf(os.Stderr, "  3pio yarn test\n")
-			fmt.Fprintf(os.Stderr, "  3pio pnpm test\n")
-			fmt.Fprintf(os.Stderr, "  3pio npx jest\n")
-			fmt.Fprintf(os.Stderr, "  3pio npx vitest run\n")
-			fmt.Fprintf(os.Stderr, "  3pio pytest\n")
-			fmt.Fprintf(os.Stderr, "  3pio go test ./...\n")
-			fmt.Fprintf(os.Stderr, "  3pio cargo test\n")
+			printUnsupportedRunner(args)
 			return 1, err
 		}
 
 		fmt.Fprintf(os.Stderr, "Test execution failed: %v\n", err)
-		return orch.GetExitCode(), err
+		// Errors before the command ran, such as an invalid test selection, have no exit code of their own
+		return max(orch.GetExitCode(), 1), err
 	}
 
 	// Return the exit code
 	return orch.GetExitCode(), nil
 }
 
+// forEachCommand prints the runner detection, or the dry run, of each command of a
+// combined run, returning the highest exit code
+func forEachCommand(config orchestrator.Config, commands [][]string, dryRun bool) (int, error) {
+	exitCode := 0
+	var firstErr error
+	for i, command := range commands {
+		if i > 0 {
+			fmt.Println()
+		}
+		config.Command = command
+		orch, err := orchestrator.New(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create orchestrator: %v\n", err)
+			return 1, err
+		}
+		var code int
+		if dryRun {
+			code, err = printDryRun(orch, command)
+		} else {
+			code, err = printRunnerDetection(orch, command)
+		}
+		exitCode = max(exitCode, code)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return exitCode, firstErr
+}
+
 func runTests(args []string) error {
 	exitCode, _ := runTestsCore(args)
 	os.Exit(exitCode)
 	return nil // Never reached, but needed for signature
 }
 
-// checkUnsupportedModes checks for watch mode and coverage mode
-func checkUnsupportedModes(args []string) error {
+// checkUnsupportedModes checks for watch mode (unless allowWatch) and coverage mode
+func checkUnsupportedModes(args []string, allowWatch bool) error {
+	if !allowWatch {
+		if err := checkWatchMode(args); err != nil {
+			return fmt.Errorf("%w (use 3pio --watch to report each re-run of Jest or Vitest)", err)
+		}
+	}
+
+	// Join all args to check for flags
+	cmdStr := strings.Join(args, " ")
+
+	// Check for coverage mode
+	coveragePatterns := []string{
+		"--coverage",
+		"--collectCoverage",
+		"--cov",
+		"--cov-report",
+		"nyc",
+		"c8",
+		"tarpaulin",
+		"llvm-cov",
+	}
+
+	for _, pattern := range coveragePatterns {
+		if strings.Contains(cmdStr, pattern) {
+			return fmt.Errorf("coverage mode is not supported. Please run tests without coverage flags")
+		}
+	}
+
+	// go test -cover only adds a summary line per package, which is reported; profiles
+	// and coverage of other packages are not
+	for _, arg := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && (name == "coverprofile" || name == "coverpkg" || name == "test.coverprofile") {
+			return fmt.Errorf("go test -%s is not supported. Use -cover for a coverage summary per package", name)
+		}
+	}
+
+	return nil
+}
+
+// checkWatchMode reports an error if the command would start the runner in watch mode
+func checkWatchMode(args []string) error {
+	// Workspace flags such as npm's -w pick packages rather than watch
+	args = runner.WithoutWorkspaceFlags(args)
+
 	// Join all args to check for flags
 	cmdStr := strings.Join(args, " ")
 
@@ -207,23 +457,45 @@ func checkUnsupportedModes(args []string) error {
 		}
 	}
 
-	// Check for coverage mode
-	coveragePatterns := []string{
-		"--coverage",
-		"--collectCoverage",
-		"--cov",
-		"--cov-report",
-		"nyc",
-		"c8",
-		"tarpaulin",
-		"llvm-cov",
-	}
-
-	for _, pattern := range coveragePatterns {
-		if strings.Contains(cmdStr, pattern) {
-			return fmt.Errorf("coverage mode is not supported. Please run tests without coverage flags")
-		}
-	}
-
 	return nil
 }
+
+// printUnsupportedRunner explains on stderr that no supported test runner was detected
+func printUnsupportedRunner(args []string) {
+	fmt.Fprintf(os.Stderr, "\nError: Could not detect test runner from command: %s\n", strings.Join(args, " "))
+	fmt.Fprintf(os.Stderr, "\n3pio currently supports:\n")
+	fmt.Fprintf(os.Stderr, "\nTest Runners:\n")
+	fmt.Fprintf(os.Stderr, "  • Jest\n")
+	fmt.Fprintf(os.Stderr, "  • Vitest (requires v3.0+)\n")
+	fmt.Fprintf(os.Stderr, "  • pytest\n")
+	fmt.Fprintf(os.Stderr, "  • go test\n")
+	fmt.Fprintf(os.Stderr, "  • cargo test\n")
+	fmt.Fprintf(os.Stderr, "  • Maven (mvn test)\n")
+	fmt.Fprintf(os.Stderr, "  • Gradle (gradle test)\n")
+	fmt.Fprintf(os.Stderr, "  • dotnet test\n")
+	fmt.Fprintf(os.Stderr, "  • RSpec\n")
+	fmt.Fprintf(os.Stderr, "  • Playwright (playwright test)\n")
+	fmt.Fprintf(os.Stderr, "  • TAP (node --test, tape, Perl .t scripts)\n")
+	fmt.Fprintf(os.Stderr, "\nPackage Managers:\n")
+	fmt.Fprintf(os.Stderr, "  • npm\n")
+	fmt.Fprintf(os.Stderr, "  • yarn\n")
+	fmt.Fprintf(os.Stderr, "  • pnpm\n")
+	fmt.Fprintf(os.Stderr, "  • bun\n")
+	fmt.Fprintf(os.Stderr, "\nExample usage:\n")
+	fmt.Fprintf(os.Stderr, "  3pio npm test\n")
+	fmt.Fprintf(os.Stderr, "  3pio yarn test\n")
+	fmt.Fprintf(os.Stderr, "  3pio pnpm test\n")
+	fmt.Fprintf(os.Stderr, "  3pio npx jest\n")
+	fmt.Fprintf(os.Stderr, "  3pio npx vitest run\n")
+	fmt.Fprintf(os.Stderr, "  3pio pytest\n")
+	fmt.Fprintf(os.Stderr, "  3pio go test ./...\n")
+	fmt.Fprintf(os.Stderr, "  3pio cargo test\n")
+	fmt.Fprintf(os.Stderr, "  3pio mvn test\n")
+	fmt.Fprintf(os.Stderr, "  3pio ./gradlew test\n")
+	fmt.Fprintf(os.Stderr, "  3pio dotnet test\n")
+	fmt.Fprintf(os.Stderr, "  3pio bundle exec rspec\n")
+	fmt.Fprintf(os.Stderr, "  3pio npx playwright test\n")
+	fmt.Fprintf(os.Stderr, "  3pio node --test\n")
+	fmt.Fprintf(os.Stderr, "\nIf the command runs a supported runner in a way 3pio doesn't recognize, name it with --runner:\n")
+	fmt.Fprintf(os.Stderr, "  3pio --runner vitest npm run test:unit\n")
+}
@@ -47,6 +47,47 @@ func TestRunTestsCore_InvalidRunner(t *testing.T) {
 	}
 }
 
+func TestRunTestsCore_IngestWithCommand(t *testing.T) {
+	// --ingest reads stdin, so a test command is a mistake rather than something to run
+	exitCode, err := runTestsCore([]string{"--ingest", "go-json", "go", "test", "./..."})
+	if err == nil || !strings.Contains(err.Error(), "takes no test command") {
+		t.Errorf("Expected an error for a test command with --ingest, got %v", err)
+	}
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestCheckUnsupportedModes_GoCover(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"go", "test", "-cover", "./..."}, false},
+		{[]string{"go", "test", "-cover", "-covermode=atomic", "./..."}, false},
+		{[]string{"go", "test", "-coverprofile=cover.out", "./..."}, true},
+		{[]string{"go", "test", "-coverprofile", "cover.out", "./..."}, true},
+		{[]string{"go", "test", "-cover", "-coverpkg=./...", "./..."}, true},
+		{[]string{"cargo", "tarpaulin"}, true},
+	}
+	for _, tt := range tests {
+		err := checkUnsupportedModes(tt.args, false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkUnsupportedModes(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckUnsupportedModes_WorkspaceFlags(t *testing.T) {
+	// npm's -w picks a workspace package; after the script's "--" it is the runner's watch flag
+	if err := checkUnsupportedModes([]string{"npm", "test", "-w", "web"}, false); err != nil {
+		t.Errorf("Expected npm -w to be allowed, got %v", err)
+	}
+	if err := checkUnsupportedModes([]string{"npm", "test", "--", "-w"}, false); err == nil {
+		t.Error("Expected -w after -- to be rejected as watch mode")
+	}
+}
+
 func TestRunTestsCore_ValidCommands(t *testing.T) {
 	// Test that valid command patterns are recognized (without actually running)
 	testCases := []struct {
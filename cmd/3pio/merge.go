@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zk/3pio/internal/logger"
+	"github.com/zk/3pio/internal/report"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// newMergeCommand creates the "merge" subcommand, which combines runs of the same suite
+// (e.g. the shards of "jest --shard") into one report
+func newMergeCommand() *cobra.Command {
+	var outDir string
+	cmd := &cobra.Command{
+		Use:   "merge <run-dir|glob>...",
+		Short: "Merge the reports of several runs, such as test shards, into a new run",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exitCode, _ := runMergeCore(args, outDir)
+			os.Exit(exitCode)
+			return nil // Never reached, but needed for signature
+		},
+	}
+	cmd.Flags().StringVar(&outDir, "output", "", "Directory for the merged run (default: a new run next to the first run)")
+	return cmd
+}
+
+// runMergeCore merges the runs named by args, run directories or glob patterns, into
+// outDir and prints where the report is (testable). Without outDir the merged run is
+// created next to the first run.
+func runMergeCore(args []string, outDir string) (int, error) {
+	runDirs, warnings, err := expandRunDirs(args)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if err == nil && len(runDirs) < 2 {
+		err = fmt.Errorf("merge needs at least two runs, got %d", len(runDirs))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	for _, runDir := range runDirs {
+		info, err := os.Stat(runDir)
+		if err != nil || !info.IsDir() {
+			err = fmt.Errorf("run directory not found: %s", runDir)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1, err
+		}
+	}
+
+	runsDir := filepath.Dir(filepath.Clean(runDirs[0]))
+	if outDir == "" {
+		outDir = filepath.Join(runsDir, time.Now().Format("20060102T150405")+"-merged")
+	}
+	if _, err := os.Stat(outDir); err == nil {
+		err = fmt.Errorf("output directory already exists: %s", outDir)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	// Run directories live in <output-dir>/runs, next to debug.log
+	fileLogger, err := logger.NewFileLoggerInDir(filepath.Dir(runsDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create debug logger: %v\n", err)
+		return 1, err
+	}
+	defer func() {
+		if err := fileLogger.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close debug log: %v\n", err)
+		}
+	}()
+
+	summary, err := report.Merge(outDir, runDirs, runner.NewManager(fileLogger), fileLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	fmt.Printf("Merged:      %d runs\n", len(runDirs))
+	printSummaryCounts(summary)
+	fmt.Printf("Report:      %s\n", filepath.Join(outDir, "test-run.md"))
+	return 0, nil
+}
+
+// expandRunDirs returns the run directories named by args, in order and without
+// duplicates. Arguments with glob characters (quoted, so the shell leaves them alone) are
+// expanded; matches that are symlinks, such as runs/latest, are skipped, and so are
+// matches lacking a parseable run.json or an ipc.jsonl, with a warning. Other arguments
+// are taken as run directories.
+func expandRunDirs(args []string) ([]string, []string, error) {
+	var runDirs, warnings []string
+	seen := make(map[string]bool)
+	add := func(runDir string) {
+		if key := filepath.Clean(runDir); !seen[key] {
+			seen[key] = true
+			runDirs = append(runDirs, runDir)
+		}
+	}
+
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			add(arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, warnings, fmt.Errorf("no runs match %s", arg)
+		}
+		for _, match := range matches {
+			if info, err := os.Lstat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			if err := checkMergeableRun(match); err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipping %s: %v", match, err))
+				continue
+			}
+			add(match)
+		}
+	}
+	return runDirs, warnings, nil
+}
+
+// checkMergeableRun checks that a run directory has a run.json that parses, which only
+// finished runs have, and the ipc.jsonl its groups are replayed from
+func checkMergeableRun(runDir string) error {
+	data, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err != nil {
+		return fmt.Errorf("no run.json (the run may not have finished)")
+	}
+	var summary report.RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return fmt.Errorf("invalid run.json: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(runDir, "ipc.jsonl")); err != nil {
+		return fmt.Errorf("no ipc.jsonl to replay")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+// writeShardRun creates a finished run directory whose ipc.jsonl reports one passing and
+// one failing test in a test file of its own. Without a summary it has no run.json.
+func writeShardRun(t *testing.T, runDir, testFile string, summary bool) {
+	t.Helper()
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	testRun := "---\ndetected_runner: jest\n---\n\n# 3pio Test Run\n\n- Test command: `npx jest --shard`\n"
+	ipcLines := []string{
+		fmt.Sprintf(`{"eventType":"testCase","payload":{"testName":"works","parentNames":[%q],"status":"PASS"}}`, testFile),
+		fmt.Sprintf(`{"eventType":"testCase","payload":{"testName":"breaks","parentNames":[%q],"status":"FAIL","error":{"message":"broken in %s"}}}`, testFile, testFile),
+		fmt.Sprintf(`{"eventType":"testGroupResult","payload":{"groupName":%q,"parentNames":[],"status":"FAIL"}}`, testFile),
+	}
+	files := map[string]string{
+		"test-run.md": testRun,
+		"ipc.jsonl":   strings.Join(ipcLines, "\n") + "\n",
+	}
+	if summary {
+		files["run.json"] = `{"schemaVersion":1,"status":"COMPLETE","exitCode":1}`
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(runDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readMergedSummary reads the run.json of a merged run
+func readMergedSummary(t *testing.T, outDir string) report.RunSummary {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(outDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json in the merged run: %v", err)
+	}
+	var summary report.RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Invalid run.json: %v", err)
+	}
+	return summary
+}
+
+func TestExpandRunDirs(t *testing.T) {
+	runsDir := t.TempDir()
+	for _, name := range []string{"20250924T120000-shard-1", "20250924T120000-shard-2"} {
+		writeShardRun(t, filepath.Join(runsDir, name), name+".test.js", true)
+	}
+	writeShardRun(t, filepath.Join(runsDir, "20250924T120000-shard-3"), "crashed.test.js", false)
+	writeShardRun(t, filepath.Join(runsDir, "20250924T120000-shard-5"), "merged.test.js", true)
+	if err := os.Remove(filepath.Join(runsDir, "20250924T120000-shard-5", "ipc.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runsDir, "20250924T120000-shard-4"), []byte("not a run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Creating symlinks may need extra privileges on Windows
+	_ = os.Symlink("20250924T120000-shard-1", filepath.Join(runsDir, "20250924T120000-latest"))
+
+	shard1 := filepath.Join(runsDir, "20250924T120000-shard-1")
+	shard2 := filepath.Join(runsDir, "20250924T120000-shard-2")
+	runDirs, warnings, err := expandRunDirs([]string{shard1, filepath.Join(runsDir, "20250924T12*")})
+	if err != nil {
+		t.Fatalf("expandRunDirs failed: %v", err)
+	}
+	// The explicit run comes first and isn't repeated; the link and the file are left out
+	if !slices.Equal(runDirs, []string{shard1, shard2}) {
+		t.Errorf("Expected %v, got %v", []string{shard1, shard2}, runDirs)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "shard-3") || !strings.Contains(warnings[0], "no run.json") {
+		t.Errorf("Expected a warning about the run without run.json, got %v", warnings)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[1], "shard-5") || !strings.Contains(warnings[1], "no ipc.jsonl") {
+		t.Errorf("Expected a warning about the run without ipc.jsonl, got %v", warnings)
+	}
+
+	if _, _, err := expandRunDirs([]string{filepath.Join(runsDir, "2024*")}); err == nil || !strings.Contains(err.Error(), "no runs match") {
+		t.Errorf("Expected an error for a pattern without matches, got %v", err)
+	}
+}
+
+func TestRunMergeCore_Glob(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), ".3pio", "runs")
+	for i := 1; i <= 3; i++ {
+		writeShardRun(t, filepath.Join(runsDir, fmt.Sprintf("20250924T120000-shard-%d", i)), fmt.Sprintf("shard%d.test.js", i), true)
+	}
+
+	outDir := filepath.Join(runsDir, "20250924T130000-merged")
+	if code, err := runMergeCore([]string{filepath.Join(runsDir, "20250924T120000-shard-*")}, outDir); code != 0 || err != nil {
+		t.Fatalf("Expected the merge to succeed, got %d, %v", code, err)
+	}
+
+	summary := readMergedSummary(t, outDir)
+	wantCounts := report.RunSummaryCounts{Total: 6, Passed: 3, Failed: 3, Groups: 3}
+	if summary.Counts != wantCounts {
+		t.Errorf("Expected counts %+v, got %+v", wantCounts, summary.Counts)
+	}
+	var failed []string
+	for _, test := range summary.FailedTests {
+		failed = append(failed, test.ErrorMessage)
+	}
+	slices.Sort(failed)
+	want := []string{"broken in shard1.test.js", "broken in shard2.test.js", "broken in shard3.test.js"}
+	if !slices.Equal(failed, want) {
+		t.Errorf("Expected the failed tests of every shard %v, got %v", want, failed)
+	}
+
+	// A run without ipc.jsonl is left out of the merge rather than failing it
+	if err := os.Remove(filepath.Join(runsDir, "20250924T120000-shard-3", "ipc.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	outDir = filepath.Join(runsDir, "20250924T140000-merged")
+	if code, err := runMergeCore([]string{filepath.Join(runsDir, "20250924T120000-shard-*")}, outDir); code != 0 || err != nil {
+		t.Fatalf("Expected the merge to skip the run without ipc.jsonl, got %d, %v", code, err)
+	}
+	if summary := readMergedSummary(t, outDir); summary.Counts.Total != 4 {
+		t.Errorf("Expected the tests of the two remaining shards, got %+v", summary.Counts)
+	}
+
+	// A pattern matching a single run has nothing to merge it with
+	if code, err := runMergeCore([]string{filepath.Join(runsDir, "*-shard-1")}, filepath.Join(t.TempDir(), "out")); code != 1 || err == nil {
+		t.Errorf("Expected merging a single run to fail, got %d, %v", code, err)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zk/3pio/internal/logger"
+	"github.com/zk/3pio/internal/report"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// newReportCommand creates the "report" subcommand, which rebuilds a run's reports
+// from its IPC log without running the tests again
+func newReportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report <run-dir>",
+		Short: "Regenerate the reports of an existing run from its ipc.jsonl",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exitCode, _ := runReportCore(args[0])
+			os.Exit(exitCode)
+			return nil // Never reached, but needed for signature
+		},
+	}
+}
+
+// runReportCore regenerates the reports in runDir and prints where they are (testable)
+func runReportCore(runDir string) (int, error) {
+	info, err := os.Stat(runDir)
+	if err != nil || !info.IsDir() {
+		err = fmt.Errorf("run directory not found: %s", runDir)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	// Run directories live in <output-dir>/runs, next to debug.log
+	fileLogger, err := logger.NewFileLoggerInDir(filepath.Dir(filepath.Dir(runDir)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create debug logger: %v\n", err)
+		return 1, err
+	}
+	defer func() {
+		if err := fileLogger.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close debug log: %v\n", err)
+		}
+	}()
+
+	summary, err := report.Regenerate(runDir, runner.NewManager(fileLogger), fileLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1, err
+	}
+
+	printSummaryCounts(summary)
+	fmt.Printf("Report:      %s\n", filepath.Join(runDir, "test-run.md"))
+	return 0, nil
+}
+
+// printSummaryCounts prints a run summary's test counts as a "Results:" line
+func printSummaryCounts(summary report.RunSummary) {
+	parts := []string{fmt.Sprintf("%d passed", summary.Counts.Passed)}
+	if summary.Counts.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", summary.Counts.Failed))
+	}
+	if summary.Counts.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", summary.Counts.Skipped))
+	}
+	parts = append(parts, fmt.Sprintf("%d total", summary.Counts.Total))
+	fmt.Printf("Results:     %s\n", strings.Join(parts, ", "))
+}
@@ -34,42 +34,53 @@ func GetAdapterPath(name string, ipcPath string, runDir string, logLevel string)
 	return extractAdapter(name, ipcPath, runDir, logLevel)
 }
 
-// extractAdapter extracts an embedded adapter with IPC path and log level injected
-func extractAdapter(name string, ipcPath string, runDir string, logLevel string) (string, error) {
-	var content []byte
-	var filename string
-	var isESM bool
+// AdapterPath returns the absolute path an adapter is extracted to for a run directory,
+// without extracting it
+func AdapterPath(name string, runDir string) (string, error) {
+	_, filename, _, err := adapterFile(name)
+	if err != nil {
+		return "", err
+	}
+	adapterPath := filepath.Join(runDir, "adapters", filename)
+	if absPath, err := filepath.Abs(adapterPath); err == nil {
+		return absPath, nil
+	}
+	return adapterPath, nil
+}
 
+// adapterFile returns an embedded adapter's template, the file name it is extracted as
+// and whether it is an ES module
+func adapterFile(name string) ([]byte, string, bool, error) {
 	switch name {
 	case "jest.js":
-		content = jestAdapter
-		// Check if target project is ES module
+		// Use .cjs extension for ES module projects
 		if isProjectESM() {
-			filename = "jest.cjs" // Use .cjs extension for ES module projects
-		} else {
-			filename = "jest.js"
+			return jestAdapter, "jest.cjs", false, nil
 		}
-		isESM = false
+		return jestAdapter, "jest.js", false, nil
 	case "vitest.js":
-		content = vitestAdapter
-		filename = "vitest.js"
-		isESM = true // Vitest adapter is ESM
+		return vitestAdapter, "vitest.js", true, nil // Vitest adapter is ESM
 	case "pytest_adapter.py":
-		content = pytestAdapter
-		filename = "pytest_adapter.py"
-		isESM = false
+		return pytestAdapter, "pytest_adapter.py", false, nil
 	case "cypress.js":
-		content = cypressAdapter
 		// Cypress reporter is CommonJS
-		filename = "cypress.js"
-		isESM = false
+		return cypressAdapter, "cypress.js", false, nil
 	case "mocha.js":
-		content = mochaAdapter
-		// Mocha reporter is CommonJS
-		filename = "mocha.js"
-		isESM = false
+		// Mocha reporter is CommonJS, so it needs .cjs in ES module projects
+		if isProjectESM() {
+			return mochaAdapter, "mocha.cjs", false, nil
+		}
+		return mochaAdapter, "mocha.js", false, nil
 	default:
-		return "", fmt.Errorf("unknown adapter: %s", name)
+		return nil, "", false, fmt.Errorf("unknown adapter: %s", name)
+	}
+}
+
+// extractAdapter extracts an embedded adapter with IPC path and log level injected
+func extractAdapter(name string, ipcPath string, runDir string, logLevel string) (string, error) {
+	content, filename, isESM, err := adapterFile(name)
+	if err != nil {
+		return "", err
 	}
 
 	// Replace template markers with actual IPC path
@@ -39,6 +39,15 @@ function getGroupId(hierarchy) {
  * Send event to IPC file
  */
 function sendEvent(event) {
+  for (const nested of nestInWorkspace(event)) {
+    writeEvent(nested);
+  }
+}
+
+/**
+ * Write an event to the IPC file
+ */
+function writeEvent(event) {
   try {
     const dir = path.dirname(IPC_PATH);
     if (!fs.existsSync(dir)) {
@@ -52,22 +61,131 @@ function sendEvent(event) {
 }
 
 /**
- * Discover all groups in a hierarchy
+ * Name of the workspace package Jest runs in when 3pio runs a workspace command (pnpm
+ * --filter, npm --workspace), or null. The package manager starts Jest in the package's
+ * directory, so it is named after the package.json there.
+ */
+function workspaceGroupName() {
+  if (!process.env.THREEPIO_WORKSPACE_GROUPS) {
+    return null;
+  }
+  try {
+    const pkg = JSON.parse(fs.readFileSync(path.join(process.cwd(), 'package.json'), 'utf8'));
+    if (pkg.name) {
+      return pkg.name;
+    }
+  } catch (error) {
+    // Fall back to what the package manager tells us
+  }
+  return process.env.npm_package_name || path.basename(process.cwd());
+}
+
+// The workspace package's root group, which every group and test is nested under, and
+// the totals of its top-level groups, reported when the run completes
+const workspace = { name: workspaceGroupName(), startTime: null, totals: null, failed: false };
+
+/**
+ * The events to write for event: as is outside a workspace run, otherwise nested under
+ * the package's group, which is discovered with the first group and reported with
+ * runComplete
+ */
+function nestInWorkspace(event) {
+  const payload = event.payload || {};
+  if (!workspace.name) {
+    return [event];
+  }
+  if (event.eventType === 'runComplete') {
+    const events = [];
+    if (workspace.startTime !== null) {
+      const totals = workspace.totals;
+      let status = 'PASS';
+      if (totals.failed > 0 || workspace.failed) {
+        status = 'FAIL';
+      } else if (totals.passed === 0 && totals.skipped > 0) {
+        status = 'SKIP';
+      }
+      events.push({
+        eventType: 'testGroupResult',
+        payload: {
+          groupName: workspace.name,
+          parentNames: [],
+          status: status,
+          duration: Date.now() - workspace.startTime,
+          totals: totals
+        }
+      });
+      workspace.startTime = null;
+    }
+    events.push(event);
+    return events;
+  }
+  if (!payload.groupName && !payload.testName) {
+    return [event];
+  }
+
+  const events = [];
+  if (workspace.startTime === null) {
+    workspace.startTime = Date.now();
+    workspace.totals = { total: 0, passed: 0, failed: 0, skipped: 0 };
+    workspace.failed = false;
+    const group = { groupName: workspace.name, parentNames: [] };
+    events.push({ eventType: 'testGroupDiscovered', payload: group });
+    events.push({ eventType: 'testGroupStart', payload: group });
+  }
+  const parentNames = payload.parentNames || [];
+  if (parentNames.length === 0 && event.eventType === 'testGroupResult') {
+    const totals = payload.totals || {};
+    for (const key of ['total', 'passed', 'failed', 'skipped']) {
+      workspace.totals[key] += totals[key] || 0;
+    }
+    workspace.failed = workspace.failed || payload.status === 'FAIL';
+  }
+  events.push({ ...event, payload: { ...payload, parentNames: [workspace.name, ...parentNames] } });
+  return events;
+}
+
+/**
+ * Name of the project a test file belongs to when Jest runs several projects, or null.
+ * Projects are named by their displayName, or by their rootDir when they have none.
  */
-function discoverGroups(filePath, ancestorTitles) {
+function projectName(test, multiProject) {
+  const config = test.context && test.context.config;
+  if (!multiProject || !config) {
+    return null;
+  }
+  const displayName = typeof config.displayName === 'string' ? config.displayName : config.displayName?.name;
+  if (displayName) {
+    return displayName;
+  }
+  return path.relative(process.cwd(), config.rootDir || '') || path.basename(config.rootDir || '') || null;
+}
+
+/**
+ * Discover all groups in a hierarchy. roots are the groups above the file (its project).
+ */
+function discoverGroups(roots, filePath, ancestorTitles) {
   const groups = [];
-  
-  // First, the file itself is a group
+
+  // Project groups come first
+  for (let i = 0; i < roots.length; i++) {
+    groups.push({
+      hierarchy: roots.slice(0, i + 1),
+      name: roots[i],
+      parentNames: roots.slice(0, i)
+    });
+  }
+
+  // Then the file itself is a group
   groups.push({
-    hierarchy: [filePath],
+    hierarchy: [...roots, filePath],
     name: filePath,
-    parentNames: []
+    parentNames: roots
   });
   
   // Then each level of ancestorTitles creates a nested group
   if (ancestorTitles && ancestorTitles.length > 0) {
     for (let i = 0; i < ancestorTitles.length; i++) {
-      const parentNames = [filePath, ...ancestorTitles.slice(0, i)];
+      const parentNames = [...roots, filePath, ...ancestorTitles.slice(0, i)];
       const groupName = ancestorTitles[i];
       groups.push({
         hierarchy: [...parentNames, groupName],
@@ -83,8 +201,8 @@ function discoverGroups(filePath, ancestorTitles) {
 /**
  * Send GroupDiscovered events for new groups
  */
-function ensureGroupsDiscovered(filePath, ancestorTitles) {
-  const groups = discoverGroups(filePath, ancestorTitles);
+function ensureGroupsDiscovered(roots, filePath, ancestorTitles) {
+  const groups = discoverGroups(roots, filePath, ancestorTitles);
   
   for (const group of groups) {
     const groupId = getGroupId(group.hierarchy);
@@ -122,19 +240,54 @@ function ensureGroupStarted(hierarchy) {
   }
 }
 
+/**
+ * Classify a failed test for the report. Failed expect() calls carry a matcherResult;
+ * other kinds are left to 3pio to work out from the message.
+ */
+function failureKind(testCaseResult) {
+  const details = testCaseResult.failureDetails || [];
+  if (details.some(detail => detail && detail.matcherResult)) {
+    return 'assertion';
+  }
+  return undefined;
+}
+
 class ThreePioJestReporter {
   originalStdoutWrite;
   originalStderrWrite;
   currentTestFile = null;
+  currentRoots = [];
   captureEnabled = false;
   testSuiteStats = new Map(); // Track stats per test suite
+  projectTotals = new Map(); // Totals of each project, reported when the run completes
 
-  constructor() {
+  constructor(globalConfig) {
     this.originalStdoutWrite = process.stdout.write.bind(process.stdout);
     this.originalStderrWrite = process.stderr.write.bind(process.stderr);
+    // With the projects option every test file is nested under its project's group
+    this.multiProject = Array.isArray(globalConfig?.projects) && globalConfig.projects.length > 0;
+  }
+
+  /**
+   * Groups above a test file: its project when Jest runs several, otherwise none
+   */
+  rootNames(test) {
+    const project = projectName(test, this.multiProject);
+    return project ? [project] : [];
   }
 
   onRunStart() {
+    // In watch mode every re-run starts here, so groups are reported afresh
+    discoveredGroups.clear();
+    groupStarts.clear();
+    fileGroups.clear();
+    this.testSuiteStats.clear();
+    this.projectTotals.clear();
+    sendEvent({
+      eventType: 'runStart',
+      payload: {}
+    });
+
     // Collection phase for Jest (Jest doesn't have separate collection)
     sendEvent({
       eventType: 'collectionStart',
@@ -143,16 +296,21 @@ class ThreePioJestReporter {
   }
 
   onTestStart(test) {
+    const roots = this.rootNames(test);
     this.currentTestFile = test.path;
+    this.currentRoots = roots;
     
-    // Discover the file as a root group
-    ensureGroupsDiscovered(test.path, []);
+    // Discover the file as a root group, or under its project
+    ensureGroupsDiscovered(roots, test.path, []);
     
-    // Start the file group
-    ensureGroupStarted([test.path]);
+    // Start the project and file groups
+    for (let i = 1; i <= roots.length; i++) {
+      ensureGroupStarted(roots.slice(0, i));
+    }
+    ensureGroupStarted([...roots, test.path]);
     
     // Store file group info
-    fileGroups.set(test.path, {
+    fileGroups.set(getGroupId([...roots, test.path]), {
       startTime: Date.now(),
       tests: []
     });
@@ -163,13 +321,15 @@ class ThreePioJestReporter {
 
   onTestCaseStart(test, testCaseStartInfo) {
     if (testCaseStartInfo?.ancestorTitles && testCaseStartInfo?.title) {
+      const roots = this.rootNames(test);
+
       // Ensure all parent groups are discovered
-      ensureGroupsDiscovered(test.path, testCaseStartInfo.ancestorTitles);
+      ensureGroupsDiscovered(roots, test.path, testCaseStartInfo.ancestorTitles);
       
       // Start all parent groups
       if (testCaseStartInfo.ancestorTitles.length > 0) {
         for (let i = 0; i <= testCaseStartInfo.ancestorTitles.length; i++) {
-          const hierarchy = [test.path, ...testCaseStartInfo.ancestorTitles.slice(0, i)];
+          const hierarchy = [...roots, test.path, ...testCaseStartInfo.ancestorTitles.slice(0, i)];
           ensureGroupStarted(hierarchy);
         }
       }
@@ -178,7 +338,8 @@ class ThreePioJestReporter {
 
   onTestCaseResult(test, testCaseResult) {
     if (testCaseResult) {
-      const parentNames = [test.path, ...(testCaseResult.ancestorTitles || [])];
+      const roots = this.rootNames(test);
+      const parentNames = [...roots, test.path, ...(testCaseResult.ancestorTitles || [])];
       const testName = testCaseResult.title;
       
       let status = 'PASS';
@@ -201,7 +362,8 @@ class ThreePioJestReporter {
       // Only include error if it exists
       if (error) {
         payload.error = {
-          message: error
+          message: error,
+          failureKind: failureKind(testCaseResult)
         };
       }
 
@@ -211,7 +373,7 @@ class ThreePioJestReporter {
       });
       
       // Track test in file group
-      const fileGroup = fileGroups.get(test.path);
+      const fileGroup = fileGroups.get(getGroupId([...roots, test.path]));
       if (fileGroup) {
         fileGroup.tests.push({
           name: testName,
@@ -224,6 +386,7 @@ class ThreePioJestReporter {
 
   onTestResult(test, testResult, aggregatedResult) {
     this.stopCapture();
+    const roots = this.rootNames(test);
     
     // Send console output as group output
     if (testResult.console && testResult.console.length > 0) {
@@ -233,7 +396,7 @@ class ThreePioJestReporter {
           eventType: log.type === 'error' ? 'groupStderr' : 'groupStdout',
           payload: {
             groupName: test.path,
-            parentNames: [],
+            parentNames: roots,
             chunk: chunk
           }
         });
@@ -271,7 +434,7 @@ class ThreePioJestReporter {
 
         // Only send if we haven't already sent this test via onTestCaseResult
         if (!sentTests.has(testId) && (testCase.status === 'skipped' || testCase.status === 'pending')) {
-          const parentNames = [test.path, ...(testCase.ancestorTitles || [])];
+          const parentNames = [...roots, test.path, ...(testCase.ancestorTitles || [])];
           let status = 'SKIP';
 
           sendEvent({
@@ -319,14 +482,14 @@ class ThreePioJestReporter {
           };
           
           const groupName = groupInfo.ancestorTitles[groupInfo.ancestorTitles.length - 1];
-          const parentNames = [test.path, ...groupInfo.ancestorTitles.slice(0, -1)];
+          const parentNames = [...roots, test.path, ...groupInfo.ancestorTitles.slice(0, -1)];
           
           const groupId = getGroupId([...parentNames, groupName]);
           if (!processedGroups.has(groupId)) {
             processedGroups.add(groupId);
 
             // Ensure the group and its parents are discovered before sending results
-            ensureGroupsDiscovered(test.path, groupInfo.ancestorTitles);
+            ensureGroupsDiscovered(roots, test.path, groupInfo.ancestorTitles);
 
             let groupStatus = 'PASS';
             if (groupTotals.failed > 0) {
@@ -355,7 +518,7 @@ class ThreePioJestReporter {
     }
     
     // Send GroupResult for the file itself
-    const fileGroup = fileGroups.get(test.path);
+    const fileGroup = fileGroups.get(getGroupId([...roots, test.path]));
     const fileStatus = totals.failed > 0 ? 'FAIL' : (totals.passed > 0 ? 'PASS' : 'SKIP');
     const fileDuration = fileGroup?.startTime ? Date.now() - fileGroup.startTime : undefined;
     
@@ -363,18 +526,58 @@ class ThreePioJestReporter {
       eventType: 'testGroupResult',
       payload: {
         groupName: test.path,
-        parentNames: [],
+        parentNames: roots,
         status: fileStatus,
         duration: fileDuration,
         totals: totals
       }
     });
+
+    // Add the file to its project, which reports once every project has run
+    if (roots.length > 0) {
+      const projectId = getGroupId(roots);
+      const projectTotals = this.projectTotals.get(projectId) || { total: 0, passed: 0, failed: 0, skipped: 0 };
+      projectTotals.total += totals.total;
+      projectTotals.passed += totals.passed;
+      projectTotals.failed += totals.failed;
+      projectTotals.skipped += totals.skipped;
+      if (testResult.testExecError) {
+        projectTotals.fileFailed = true; // The file failed to run at all
+      }
+      this.projectTotals.set(projectId, projectTotals);
+    }
     
     this.currentTestFile = null;
+    this.currentRoots = [];
   }
 
   onRunComplete(testContexts, results) {
     this.stopCapture();
+
+    // Send results for project groups
+    for (const [projectId, totals] of this.projectTotals) {
+      const group = discoveredGroups.get(projectId);
+      if (!group) {
+        continue;
+      }
+      let status = 'PASS';
+      if (totals.failed > 0 || totals.fileFailed) {
+        status = 'FAIL';
+      } else if (totals.passed === 0 && totals.skipped > 0) {
+        status = 'SKIP';
+      }
+      const startTime = groupStarts.get(projectId);
+      sendEvent({
+        eventType: 'testGroupResult',
+        payload: {
+          groupName: group.name,
+          parentNames: group.parentNames,
+          status: status,
+          duration: startTime ? Date.now() - startTime : undefined,
+          totals: { total: totals.total, passed: totals.passed, failed: totals.failed, skipped: totals.skipped }
+        }
+      });
+    }
     
     // Send run complete event
     sendEvent({
@@ -394,7 +597,7 @@ class ThreePioJestReporter {
           eventType: 'groupStdout',
           payload: {
             groupName: this.currentTestFile,
-            parentNames: [],
+            parentNames: this.currentRoots,
             chunk: chunkStr
           }
         });
@@ -409,7 +612,7 @@ class ThreePioJestReporter {
           eventType: 'groupStderr',
           payload: {
             groupName: this.currentTestFile,
-            parentNames: [],
+            parentNames: this.currentRoots,
             chunk: chunkStr
           }
         });
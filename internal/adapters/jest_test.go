@@ -0,0 +1,156 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jestDriver feeds the reporter the calls Jest makes for one test file in each of two
+// projects, @app/web with a passing test and api (named by its rootDir) with a failing
+// one. The projects option is only set when the driver gets "projects" as argument.
+const jestDriver = `
+const Reporter = require(process.argv[2]);
+const globalConfig = process.argv[3] === 'projects' ? { projects: ['packages/web', 'packages/api'] } : {};
+const reporter = new Reporter(globalConfig);
+
+const web = { path: '/repo/packages/web/app.test.js', context: { config: { displayName: { name: '@app/web', color: 'blue' }, rootDir: '/repo/packages/web' } } };
+const api = { path: '/repo/packages/api/app.test.js', context: { config: { rootDir: process.cwd() + '/packages/api' } } };
+
+function run(test, title, status) {
+  const result = { title, ancestorTitles: ['App'], status, duration: 2, failureMessages: status === 'failed' ? ['expected 1 to be 2'] : [] };
+  reporter.onTestStart(test);
+  reporter.onTestCaseStart(test, { title, ancestorTitles: ['App'] });
+  reporter.onTestCaseResult(test, result);
+  reporter.onTestResult(test, { testResults: [result], console: [] });
+}
+
+reporter.onRunStart();
+run(web, 'renders', 'passed');
+run(api, 'responds', 'failed');
+reporter.onRunComplete();
+`
+
+// runJestDriver runs jestDriver against the Jest adapter and returns each test's parent
+// names and the group results, in order, as "path=status/total". In "workspace" mode Jest
+// runs in a workspace package named @app/shop.
+func runJestDriver(t *testing.T, node, mode string) (map[string][]string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	ipcPath := filepath.Join(dir, "ipc.jsonl")
+	adapterPath, err := GetAdapterPath("jest.js", ipcPath, dir, "WARN")
+	if err != nil {
+		t.Fatalf("GetAdapterPath() error = %v", err)
+	}
+	driverPath := filepath.Join(dir, "driver.js")
+	if err := os.WriteFile(driverPath, []byte(jestDriver), 0644); err != nil {
+		t.Fatalf("Failed to write driver: %v", err)
+	}
+	cmd := exec.Command(node, driverPath, adapterPath, mode)
+	cmd.Dir = dir
+	if mode == "workspace" {
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "@app/shop"}`), 0644); err != nil {
+			t.Fatalf("Failed to write package.json: %v", err)
+		}
+		cmd.Env = append(os.Environ(), "THREEPIO_WORKSPACE_GROUPS=1")
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("node failed: %v\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	parents := make(map[string][]string)
+	var results []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string `json:"eventType"`
+			Payload   struct {
+				TestName    string   `json:"testName"`
+				GroupName   string   `json:"groupName"`
+				ParentNames []string `json:"parentNames"`
+				Status      string   `json:"status"`
+				Totals      struct {
+					Total int `json:"total"`
+				} `json:"totals"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			parents[event.Payload.TestName] = event.Payload.ParentNames
+		case "testGroupResult":
+			name := strings.Join(append(event.Payload.ParentNames, event.Payload.GroupName), " > ")
+			results = append(results, fmt.Sprintf("%s=%s/%d", name, event.Payload.Status, event.Payload.Totals.Total))
+		}
+	}
+	return parents, results
+}
+
+func TestJestAdapter_GroupsByProject(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found in PATH")
+	}
+
+	parents, results := runJestDriver(t, node, "projects")
+	expectedParents := map[string][]string{
+		"renders":  {"@app/web", "/repo/packages/web/app.test.js", "App"},
+		"responds": {"packages/api", "/repo/packages/api/app.test.js", "App"},
+	}
+	if !reflect.DeepEqual(parents, expectedParents) {
+		t.Errorf("Expected test parents %v, got %v", expectedParents, parents)
+	}
+
+	// Projects report once every project has run
+	expectedResults := []string{
+		"@app/web > /repo/packages/web/app.test.js > App=PASS/1",
+		"@app/web > /repo/packages/web/app.test.js=PASS/1",
+		"packages/api > /repo/packages/api/app.test.js > App=FAIL/1",
+		"packages/api > /repo/packages/api/app.test.js=FAIL/1",
+		"@app/web=PASS/1",
+		"packages/api=FAIL/1",
+	}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Expected group results %v, got %v", expectedResults, results)
+	}
+
+	// Without the projects option test files stay root groups
+	parents, _ = runJestDriver(t, node, "")
+	if got := parents["renders"]; !reflect.DeepEqual(got, []string{"/repo/packages/web/app.test.js", "App"}) {
+		t.Errorf("Expected a single project's file to be a root group, got parents %v", got)
+	}
+}
+
+func TestJestAdapter_GroupsByWorkspacePackage(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found in PATH")
+	}
+
+	parents, results := runJestDriver(t, node, "workspace")
+	if got := parents["renders"]; !reflect.DeepEqual(got, []string{"@app/shop", "/repo/packages/web/app.test.js", "App"}) {
+		t.Errorf("Expected the test file nested under the package, got parents %v", got)
+	}
+
+	// The package reports the totals of its files once the run completes
+	expectedResults := []string{
+		"@app/shop > /repo/packages/web/app.test.js > App=PASS/1",
+		"@app/shop > /repo/packages/web/app.test.js=PASS/1",
+		"@app/shop > /repo/packages/api/app.test.js > App=FAIL/1",
+		"@app/shop > /repo/packages/api/app.test.js=FAIL/1",
+		"@app/shop=FAIL/2",
+	}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Expected group results %v, got %v", expectedResults, results)
+	}
+}
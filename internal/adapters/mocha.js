@@ -74,20 +74,18 @@ function ensureStarted(filePath, suiteChain) {
 }
 
 // Mocha reporter API
+//
+// Each test is attributed to the spec file of its suite, so a run over several files
+// reports one root group per file. Suites report their result on "suite end"; a file
+// reports once its last top-level suite has ended (or at the end of the run).
 function ThreePioMochaReporter(runner /*, options */) {
-  // Track per-spec statistics
-  let specFile = null;
-  let startedAt = 0;
-  let passed = 0, failed = 0, skipped = 0;
-
-  function resolveSpecFrom(obj) {
-    if (!obj) return null;
-    // Mocha commonly exposes file on test/suite
-    if (obj.file) return obj.file;
-    if (obj.parent && obj.parent.file) return obj.parent.file;
-    // Fallbacks
-    if (obj.parent && obj.parent.root && obj.parent.suites && obj.parent.suites[0] && obj.parent.suites[0].file) {
-      return obj.parent.suites[0].file;
+  const groupStats = new Map(); // group id -> { hierarchy, passed, failed, skipped, startedAt }
+  const openFiles = new Set(); // files whose result has not been sent yet
+
+  function fileOf(node) {
+    // Mocha sets file on suites and tests loaded from a spec file
+    for (let n = node; n; n = n.parent) {
+      if (n.file) return n.file;
     }
     return null;
   }
@@ -109,56 +107,114 @@ function ThreePioMochaReporter(runner /*, options */) {
     return 'PENDING';
   }
 
-  runner.on('start', () => {
-    startedAt = now();
-  });
+  function statsFor(hierarchy) {
+    const id = groupId(hierarchy);
+    let stats = groupStats.get(id);
+    if (!stats) {
+      stats = { hierarchy, passed: 0, failed: 0, skipped: 0, startedAt: now() };
+      groupStats.set(id, stats);
+    }
+    return stats;
+  }
+
+  function beginFile(file) {
+    if (!openFiles.has(file) && !groupStats.has(groupId([file]))) {
+      openFiles.add(file);
+    }
+    ensureDiscovered(file, []);
+    ensureStarted(file, []);
+    statsFor([file]);
+  }
+
+  function sendGroupResult(hierarchy) {
+    const stats = statsFor(hierarchy);
+    const total = stats.passed + stats.failed + stats.skipped;
+    let status = 'PASS';
+    if (stats.failed > 0) status = 'FAIL';
+    else if (stats.passed === 0 && stats.skipped > 0) status = 'SKIP';
+
+    sendEvent({
+      eventType: 'testGroupResult',
+      payload: {
+        groupName: hierarchy[hierarchy.length - 1],
+        parentNames: hierarchy.slice(0, -1),
+        status,
+        duration: now() - stats.startedAt,
+        totals: { passed: stats.passed, failed: stats.failed, skipped: stats.skipped, total },
+      },
+    });
+  }
+
+  function finishFile(file) {
+    if (!openFiles.has(file)) return;
+    openFiles.delete(file);
+    sendGroupResult([file]);
+  }
 
   runner.on('suite', (suite) => {
     // Ignore root suite (empty title)
-    if (!suite || !suite.title) return;
-    if (!specFile) specFile = resolveSpecFrom(suite) || specFile;
-    const chain = toChain(suite);
-    if (specFile) {
-      ensureDiscovered(specFile, chain.concat([suite.title]));
-      ensureStarted(specFile, chain.concat([suite.title]));
+    if (!suite || suite.root || !suite.title) return;
+    const file = fileOf(suite) || 'unknown.spec';
+    beginFile(file);
+    const chain = toChain(suite).concat([suite.title]);
+    ensureDiscovered(file, chain);
+    ensureStarted(file, chain);
+    statsFor([file, ...chain]);
+  });
+
+  runner.on('suite end', (suite) => {
+    if (!suite || suite.root || !suite.title) return;
+    const file = fileOf(suite) || 'unknown.spec';
+    sendGroupResult([file, ...toChain(suite), suite.title]);
+
+    // Top-level suites run in file order, so the file is done once none of the
+    // remaining top-level suites belong to it
+    const parent = suite.parent;
+    if (parent && parent.root) {
+      const siblings = parent.suites || [];
+      const rest = siblings.slice(siblings.indexOf(suite) + 1);
+      if (!rest.some((s) => fileOf(s) === file)) {
+        finishFile(file);
+      }
     }
   });
 
   runner.on('test', (test) => {
-    if (!specFile) specFile = resolveSpecFrom(test) || specFile;
-    const chain = toChain(test); // excludes the test title itself
-    if (specFile) {
-      ensureDiscovered(specFile, chain);
-      ensureStarted(specFile, []); // ensure file-level started for RUNNING
-    }
+    beginFile(fileOf(test) || 'unknown.spec');
   });
 
   runner.on('pass', (test) => {
-    passed++;
     emitTestCase(test, 'pass');
   });
 
   runner.on('fail', (test, err) => {
-    failed++;
     emitTestCase(test, 'fail', err);
   });
 
   runner.on('pending', (test) => {
-    skipped++;
     emitTestCase(test, 'pending');
   });
 
   function emitTestCase(test, kind, err) {
-    if (!specFile) specFile = resolveSpecFrom(test) || 'unknown.spec';
+    const file = fileOf(test) || 'unknown.spec';
     const chain = toChain(test);
     const duration = typeof test.duration === 'number' ? test.duration : 0;
 
     // Ensure discovery for all parent groups
-    ensureDiscovered(specFile, chain);
+    beginFile(file);
+    ensureDiscovered(file, chain);
+
+    // Count the result towards the file and every enclosing suite
+    for (let i = 0; i <= chain.length; i++) {
+      const stats = statsFor([file, ...chain.slice(0, i)]);
+      if (kind === 'pass') stats.passed++;
+      else if (kind === 'fail') stats.failed++;
+      else if (kind === 'pending') stats.skipped++;
+    }
 
     const payload = {
       testName: test.title || 'Unnamed test',
-      parentNames: [specFile, ...chain],
+      parentNames: [file, ...chain],
       status: statusFrom(kind),
       duration,
     };
@@ -174,26 +230,10 @@ function ThreePioMochaReporter(runner /*, options */) {
   }
 
   runner.once('end', () => {
-    const endedAt = now();
-    const total = passed + failed + skipped;
-    const status = failed > 0 ? 'FAIL' : (passed > 0 && failed === 0 ? 'PASS' : (skipped > 0 ? 'SKIP' : 'PASS'));
-    const duration = endedAt - startedAt;
-
-    const file = specFile || 'unknown.spec';
-    // Ensure file group at least
-    ensureDiscovered(file, []);
-    ensureStarted(file, []);
-
-    sendEvent({
-      eventType: 'testGroupResult',
-      payload: {
-        groupName: file,
-        parentNames: [],
-        status,
-        duration,
-        totals: { passed, failed, skipped, total },
-      },
-    });
+    // Files with only top-level tests (no describe blocks) have no suite end
+    for (const file of Array.from(openFiles)) {
+      finishFile(file);
+    }
 
     // End of run (optional, used by manager)
     sendEvent({ eventType: 'runComplete', payload: {} });
@@ -201,4 +241,3 @@ function ThreePioMochaReporter(runner /*, options */) {
 }
 
 module.exports = ThreePioMochaReporter;
-
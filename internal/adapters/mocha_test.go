@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mochaDriver feeds the reporter the events mocha emits for two spec files: cart.spec.js
+// with a nested suite and a failing test, and util.spec.js with a top-level test only
+const mochaDriver = `
+const EventEmitter = require('events');
+const Reporter = require(process.argv[2]);
+
+const runner = new EventEmitter();
+new Reporter(runner);
+
+const root = { root: true, title: '', suites: [] };
+function suite(title, file, parent) {
+  const s = { title, file, parent, suites: [] };
+  parent.suites.push(s);
+  return s;
+}
+function test(title, file, parent) {
+  return { title, file, parent, duration: 3 };
+}
+
+const cart = suite('Cart', '/src/cart.spec.js', root);
+const totals = suite('totals', '/src/cart.spec.js', cart);
+const utilTest = test('formats prices', '/src/util.spec.js', root);
+const add = test('adds items', '/src/cart.spec.js', cart);
+const sum = test('sums prices', '/src/cart.spec.js', totals);
+const tax = test('adds tax', '/src/cart.spec.js', totals);
+
+runner.emit('start');
+runner.emit('suite', root);
+runner.emit('test', utilTest);
+runner.emit('pass', utilTest);
+runner.emit('suite', cart);
+runner.emit('test', add);
+runner.emit('pass', add);
+runner.emit('suite', totals);
+runner.emit('test', sum);
+runner.emit('pass', sum);
+runner.emit('test', tax);
+runner.emit('fail', tax, new Error('expected 11 to equal 10'));
+runner.emit('suite end', totals);
+runner.emit('suite end', cart);
+runner.emit('suite end', root);
+runner.emit('end');
+`
+
+func TestMochaAdapter_GroupsByFile(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found in PATH")
+	}
+
+	dir := t.TempDir()
+	ipcPath := filepath.Join(dir, "ipc.jsonl")
+	adapterPath, err := GetAdapterPath("mocha.js", ipcPath, dir, "WARN")
+	if err != nil {
+		t.Fatalf("GetAdapterPath() error = %v", err)
+	}
+	driverPath := filepath.Join(dir, "driver.js")
+	if err := os.WriteFile(driverPath, []byte(mochaDriver), 0644); err != nil {
+		t.Fatalf("Failed to write driver: %v", err)
+	}
+	if out, err := exec.Command(node, driverPath, adapterPath).CombinedOutput(); err != nil {
+		t.Fatalf("node failed: %v\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	parents := make(map[string][]string)
+	var results []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string `json:"eventType"`
+			Payload   struct {
+				TestName    string   `json:"testName"`
+				GroupName   string   `json:"groupName"`
+				ParentNames []string `json:"parentNames"`
+				Status      string   `json:"status"`
+				Totals      struct {
+					Total int `json:"total"`
+				} `json:"totals"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			parents[event.Payload.TestName] = event.Payload.ParentNames
+		case "testGroupResult":
+			name := strings.Join(append(event.Payload.ParentNames, event.Payload.GroupName), " > ")
+			results = append(results, fmt.Sprintf("%s=%s/%d", name, event.Payload.Status, event.Payload.Totals.Total))
+		}
+	}
+
+	expectedParents := map[string][]string{
+		"formats prices": {"/src/util.spec.js"},
+		"adds items":     {"/src/cart.spec.js", "Cart"},
+		"sums prices":    {"/src/cart.spec.js", "Cart", "totals"},
+		"adds tax":       {"/src/cart.spec.js", "Cart", "totals"},
+	}
+	if !reflect.DeepEqual(parents, expectedParents) {
+		t.Errorf("Expected test parents %v, got %v", expectedParents, parents)
+	}
+
+	// Suites report as they end, cart.spec.js after its last suite and util.spec.js at the end
+	expectedResults := []string{
+		"/src/cart.spec.js > Cart > totals=FAIL/2",
+		"/src/cart.spec.js > Cart=FAIL/3",
+		"/src/cart.spec.js=FAIL/3",
+		"/src/util.spec.js=PASS/1",
+	}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Expected group results %v, got %v", expectedResults, results)
+	}
+}
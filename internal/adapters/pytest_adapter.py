@@ -3,18 +3,26 @@
 3pio pytest adapter - Reports test results via IPC for AI-optimized reporting.
 
 This plugin hooks into pytest execution and sends test events to a JSON Lines file
-specified by the THREEPIO_IPC_PATH environment variable.
+specified by the THREEPIO_IPC_PATH environment variable, or to a Unix domain socket
+when the path starts with "unix:" (3pio --ipc-socket).
 """
 
 import os
 import sys
 import json
 import time
+import socket
+import threading
 from pathlib import Path
 from typing import Optional, Dict, Any
 from io import StringIO, TextIOBase
 from datetime import datetime
 
+try:
+    import fcntl  # POSIX only; used to serialize appends from concurrent writers
+except ImportError:  # pragma: no cover - Windows
+    fcntl = None
+
 from _pytest.config import Config
 from _pytest.reports import TestReport, CollectReport
 from _pytest.nodes import Item
@@ -24,15 +32,62 @@ LOG_LEVEL = #__LOG_LEVEL__#"WARN"#__LOG_LEVEL__#
 from _pytest.terminal import TerminalReporter
 
 
+# IPC paths with this prefix name a Unix domain socket instead of a file
+SOCKET_SCHEME = "unix:"
+
+# Set by 3pio for tox runs: each tox environment's results go under a root group
+# named after the environment (TOX_ENV_NAME, which tox sets in its environments)
+TOX_ENV_GROUPS_VAR = "THREEPIO_TOX_ENV_GROUPS"
+
 # Global reporter instance
 _reporter: Optional['ThreepioReporter'] = None
 
 
+def _skip_reason(report: TestReport) -> Optional[str]:
+    """Return the reason a skipped test was skipped, from the skip marker or pytest.skip().
+
+    pytest stores it in longrepr as (path, lineno, "Skipped: <reason>").
+    """
+    longrepr = report.longrepr
+    if not isinstance(longrepr, tuple) or len(longrepr) != 3:
+        return None
+    reason = str(longrepr[2])
+    if reason.startswith("Skipped: "):
+        reason = reason[len("Skipped: "):]
+    return reason or None
+
+
+def _test_error(report: TestReport) -> Dict[str, Any]:
+    """Build the error payload for a failed test from its longrepr."""
+    longrepr = str(report.longrepr)
+    error: Dict[str, Any] = {"message": longrepr}
+    if "[XPASS(strict)]" in longrepr:
+        error["failureKind"] = "skip_as_fail"
+        return error
+
+    # reprcrash holds the final exception line, e.g. "ZeroDivisionError: division by zero".
+    # Rewritten asserts read "assert 1 == 2" without the exception name.
+    crash = getattr(report.longrepr, "reprcrash", None)
+    message = getattr(crash, "message", None)
+    if message:
+        error["message"] = message
+        error["stack"] = longrepr
+        if message.startswith("assert "):
+            error["errorType"] = "AssertionError"
+        else:
+            name = message.split(":", 1)[0]
+            if name.replace(".", "").replace("_", "").isalnum():
+                error["errorType"] = name
+    return error
+
+
 class ThreepioReporter:
     """pytest reporter that sends test events via IPC."""
 
     def __init__(self, ipc_path: str):
         self.ipc_path = ipc_path
+        self.ipc_socket = None  # Connection to the IPC socket, opened on the first event
+        self.ipc_socket_lock = threading.Lock()
         self.test_files = set()
         self.test_results = {}  # Track results per file
         self.current_test_file = None
@@ -46,11 +101,28 @@ class ThreepioReporter:
         self.group_starts = set()
         self.file_groups = {}
 
+        # Root group for this tox environment's results, if any
+        self.env_group = None
+        if os.environ.get(TOX_ENV_GROUPS_VAR) and os.environ.get("TOX_ENV_NAME"):
+            self.env_group = os.environ["TOX_ENV_NAME"]
+        self.env_group_start = time.time()
+
         self._ensure_debug_log_dir()
         self._log_startup()
-        
+
+        if self.env_group:
+            group = {'groupName': self.env_group, 'parentNames': []}
+            self._write_event('testGroupDiscovered', group)
+            self._write_event('testGroupStart', group)
+
     def send_event(self, event_type: str, payload: Dict[str, Any]) -> None:
-        """Send an event to the IPC file."""
+        """Send an event, placing its groups under the tox environment's group."""
+        if self.env_group and isinstance(payload.get('parentNames'), list):
+            payload = dict(payload, parentNames=[self.env_group] + payload['parentNames'])
+        self._write_event(event_type, payload)
+
+    def _write_event(self, event_type: str, payload: Dict[str, Any]) -> None:
+        """Write an event to the IPC file or socket."""
         event = {
             "eventType": event_type,
             "payload": payload,
@@ -58,14 +130,48 @@ class ThreepioReporter:
         }
         
         try:
-            # Write to IPC file (append mode, create if doesn't exist)
-            with open(self.ipc_path, 'a') as f:
-                f.write(json.dumps(event) + '\n')
-                f.flush()  # Ensure immediate write
+            # Write each event with a single append so lines from concurrent
+            # writers can't interleave, holding an exclusive lock where available
+            data = (json.dumps(event) + '\n').encode('utf-8')
+            if self.ipc_path.startswith(SOCKET_SCHEME):
+                self._send_socket(data)
+                return
+            fd = os.open(self.ipc_path, os.O_WRONLY | os.O_APPEND | os.O_CREAT, 0o644)
+            try:
+                if fcntl is not None:
+                    fcntl.flock(fd, fcntl.LOCK_EX)
+                os.write(fd, data)
+            finally:
+                if fcntl is not None:
+                    fcntl.flock(fd, fcntl.LOCK_UN)
+                os.close(fd)
         except Exception as e:
             # Log error to debug log but stay silent in console
             self._log_error(f"Failed to send IPC event: {e}")
     
+    def _send_socket(self, data: bytes) -> None:
+        """Write an event line to the IPC socket, connecting on first use."""
+        with self.ipc_socket_lock:
+            try:
+                if self.ipc_socket is None:
+                    sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+                    sock.connect(self.ipc_path[len(SOCKET_SCHEME):])
+                    self.ipc_socket = sock
+                self.ipc_socket.sendall(data)
+            except Exception:
+                # Reconnect on the next event
+                self.close_socket()
+                raise
+
+    def close_socket(self) -> None:
+        """Close the IPC socket connection, if one is open."""
+        if self.ipc_socket is not None:
+            try:
+                self.ipc_socket.close()
+            except Exception:
+                pass
+            self.ipc_socket = None
+
     def _ensure_debug_log_dir(self) -> None:
         """Ensure the debug log directory exists."""
         try:
@@ -199,6 +305,23 @@ class ThreepioReporter:
                     })
                     break
     
+    def ensure_file_tracked(self, file_path: str) -> None:
+        """Start tracking a test file as a root group the first time it is seen."""
+        if file_path in self.test_files:
+            return
+        self.test_files.add(file_path)
+        self.test_results[file_path] = {"passed": 0, "failed": 0, "skipped": 0, "xfailed": 0, "xpassed": 0, "failed_tests": []}
+
+        # Discover the file as a root group and start it
+        self.ensure_groups_discovered(file_path, [])
+        self.ensure_group_started([file_path])
+
+        # Store file group info
+        self.file_groups[file_path] = {
+            'start_time': time.time(),
+            'tests': []
+        }
+
     def get_file_path(self, item: Item) -> str:
         """Extract the test file path from a test item."""
         # Get the file path relative to the current directory
@@ -286,7 +409,13 @@ def pytest_configure(config: Config) -> None:
     global _reporter
     
     ipc_path = #__IPC_PATH__#"WILL_BE_REPLACED"#__IPC_PATH__#
-    
+
+    # pytest-xdist workers forward every report to the controller, which calls
+    # pytest_runtest_logreport for all tests. Only the controller reports, so there
+    # is a single IPC writer and crashed workers still get their tests reported.
+    if hasattr(config, 'workerinput'):
+        return
+
     if True:  # IPC path will always be present after injection
         # Create the reporter instance
         _reporter = ThreepioReporter(ipc_path)
@@ -361,19 +490,7 @@ def pytest_runtest_protocol(item: Item, nextitem: Optional[Item]) -> None:
 
         # testFileStart event removed - using group events instead
         if file_path not in _reporter.test_files:
-            _reporter.test_files.add(file_path)
-            # File discovery and group management handled by group events
-            _reporter.test_results[file_path] = {"passed": 0, "failed": 0, "skipped": 0, "failed_tests": []}
-
-            # Discover the file as a root group and start it
-            _reporter.ensure_groups_discovered(file_path, [])
-            _reporter.ensure_group_started([file_path])
-
-            # Store file group info
-            _reporter.file_groups[file_path] = {
-                'start_time': time.time(),
-                'tests': []
-            }
+            _reporter.ensure_file_tracked(file_path)
 
             # Update the file path for capturing (capture already started in pytest_configure)
             _reporter.current_test_file = file_path
@@ -389,16 +506,19 @@ def pytest_runtest_logreport(report: TestReport) -> None:
     if not _reporter:
         return
     
-    # Only process the 'call' phase (actual test execution)
-    if report.when != 'call':
+    # Only process the 'call' phase (actual test execution), and the 'setup' phase of
+    # tests skipped before they run (skip and skipif markers). pytest-xdist reports
+    # a test whose worker crashed with when='???', which must count as a failure.
+    skipped_at_setup = report.when == 'setup' and report.skipped
+    if report.when != 'call' and not skipped_at_setup and not (report.when == '???' and report.failed):
         return
     
     # Parse the test hierarchy from nodeid
     file_path, suite_chain, test_name = _reporter.parse_test_hierarchy(report.nodeid)
 
-    # Initialize results for file if needed
-    if file_path not in _reporter.test_results:
-        _reporter.test_results[file_path] = {"passed": 0, "failed": 0, "skipped": 0, "xfailed": 0, "xpassed": 0, "failed_tests": []}
+    # Under pytest-xdist the controller never runs pytest_runtest_protocol,
+    # so files are first seen here
+    _reporter.ensure_file_tracked(file_path)
 
     # Determine test status
     has_xfail = hasattr(report, 'wasxfail')
@@ -420,6 +540,10 @@ def pytest_runtest_logreport(report: TestReport) -> None:
     elif report.skipped:
         status = "SKIP"
         _reporter.test_results[file_path]["skipped"] += 1
+    elif report.outcome == "rerun":
+        # pytest-rerunfailures reports each failed attempt before retrying it.
+        # Only the final attempt counts towards the file's results.
+        status = "FAIL"
     else:
         status = "UNKNOWN"
 
@@ -445,11 +569,21 @@ def pytest_runtest_logreport(report: TestReport) -> None:
     # Add xfail reason if available
     if has_xfail:
         payload["xfailReason"] = str(report.wasxfail)
+    elif status == "SKIP":
+        reason = _skip_reason(report)
+        if reason:
+            payload["skipReason"] = reason
+
+    # pytest-rerunfailures numbers the attempts after the first
+    retry = getattr(report, 'rerun', 0)
+    if isinstance(retry, int) and retry > 0:
+        payload["metadata"] = {"retry": retry}
 
     # Add error information for failures
-    if report.failed:
+    if report.failed or report.outcome == "rerun":
         if hasattr(report, 'longrepr') and report.longrepr:
-            payload["error"] = str(report.longrepr)
+            payload["error"] = _test_error(report)
+    if report.failed:
         # Track failed test for file result
         _reporter.test_results[file_path]["failed_tests"].append({
             "name": test_name,
@@ -457,7 +591,7 @@ def pytest_runtest_logreport(report: TestReport) -> None:
         })
 
     # Track test in file group
-    if file_path in _reporter.file_groups:
+    if file_path in _reporter.file_groups and report.outcome != "rerun":
         _reporter.file_groups[file_path]['tests'].append({
             'name': test_name,
             'status': status,
@@ -524,6 +658,35 @@ def pytest_sessionfinish(session, exitstatus: int) -> None:
 
         # testFileResult event removed - using group events instead
 
+    if _reporter.env_group:
+        _send_env_group_result(_reporter)
+
+
+def _send_env_group_result(reporter: 'ThreepioReporter') -> None:
+    """Send the result of the tox environment's group, totalling its files."""
+    totals = {key: 0 for key in ('total', 'passed', 'failed', 'skipped', 'xfailed', 'xpassed')}
+    for results in reporter.test_results.values():
+        for key in ('passed', 'failed', 'skipped', 'xfailed', 'xpassed'):
+            totals[key] += results.get(key, 0)
+            totals['total'] += results.get(key, 0)
+
+    if totals['failed'] > 0:
+        status = "FAIL"
+    elif totals['passed'] > 0:
+        status = "PASS"
+    elif totals['skipped'] > 0:
+        status = "SKIP"
+    else:
+        status = "NO_TESTS"
+
+    reporter._write_event("testGroupResult", {
+        "groupName": reporter.env_group,
+        "parentNames": [],
+        "status": status,
+        "duration": (time.time() - reporter.env_group_start) * 1000,
+        "totals": totals
+    })
+
 
 def pytest_unconfigure(config: Config) -> None:
     """Clean up when pytest is done."""
@@ -532,5 +695,8 @@ def pytest_unconfigure(config: Config) -> None:
     # Note: We don't restore stdout/stderr since we manage the entire test run
     # This prevents any buffered output from appearing after the tests complete
     
-    # Clear the global reporter
+    # Close the IPC socket before clearing the global reporter
+    if _reporter is not None:
+        with _reporter.ipc_socket_lock:
+            _reporter.close_socket()
     _reporter = None
\ No newline at end of file
@@ -90,6 +90,88 @@ const require_package = __commonJS({
     };
   },
 });
+/**
+ * Name of the workspace package Vitest runs in when 3pio runs a workspace command (pnpm
+ * --filter, npm --workspace), or null. The package manager starts Vitest in the package's
+ * directory, so it is named after the package.json there.
+ */
+function workspaceGroupName() {
+  if (!process.env.THREEPIO_WORKSPACE_GROUPS) {
+    return null;
+  }
+  try {
+    const pkg = JSON.parse(fs.readFileSync(path.join(process.cwd(), 'package.json'), 'utf8'));
+    if (pkg.name) {
+      return pkg.name;
+    }
+  } catch {}
+  return process.env.npm_package_name || path.basename(process.cwd());
+}
+
+// The workspace package's root group, which every group and test is nested under, and
+// the totals of its top-level groups, reported when the run completes
+const workspace = { name: workspaceGroupName(), startTime: null, totals: null, failed: false };
+
+/**
+ * The events to write for event: as is outside a workspace run, otherwise nested under
+ * the package's group, which is discovered with the first group and reported with
+ * runComplete
+ */
+function nestInWorkspace(event) {
+  const payload = event.payload || {};
+  if (!workspace.name) {
+    return [event];
+  }
+  if (event.eventType === 'runComplete') {
+    const events = [];
+    if (workspace.startTime !== null) {
+      const { totals } = workspace;
+      let status = 'PASS';
+      if (totals.failed > 0 || workspace.failed) {
+        status = 'FAIL';
+      } else if (totals.passed === 0 && totals.skipped > 0) {
+        status = 'SKIP';
+      }
+      events.push({
+        eventType: 'testGroupResult',
+        payload: {
+          groupName: workspace.name,
+          parentNames: [],
+          status,
+          duration: Date.now() - workspace.startTime,
+          totals,
+        },
+      });
+      workspace.startTime = null;
+    }
+    events.push(event);
+    return events;
+  }
+  if (!payload.groupName && !payload.testName) {
+    return [event];
+  }
+
+  const events = [];
+  if (workspace.startTime === null) {
+    workspace.startTime = Date.now();
+    workspace.totals = { total: 0, passed: 0, failed: 0, skipped: 0 };
+    workspace.failed = false;
+    const group = { groupName: workspace.name, parentNames: [] };
+    events.push({ eventType: 'testGroupDiscovered', payload: group });
+    events.push({ eventType: 'testGroupStart', payload: group });
+  }
+  const parentNames = payload.parentNames || [];
+  if (parentNames.length === 0 && event.eventType === 'testGroupResult') {
+    const totals = payload.totals || {};
+    for (const key of ['total', 'passed', 'failed', 'skipped']) {
+      workspace.totals[key] += totals[key] || 0;
+    }
+    workspace.failed = workspace.failed || payload.status === 'FAIL';
+  }
+  events.push({ ...event, payload: { ...payload, parentNames: [workspace.name, ...parentNames] } });
+  return events;
+}
+
 const IPCSender = {
   /**
    * Send an event to the IPC file (used by adapters)
@@ -110,8 +192,9 @@ const IPCSender = {
       if (!fs.existsSync(dir)) {
         fs.mkdirSync(dir, { recursive: true });
       }
-      const line = `${JSON.stringify(event)}\n`;
-      fs.appendFileSync(ipcPath, line);
+      for (const nested of nestInWorkspace(event)) {
+        fs.appendFileSync(ipcPath, `${JSON.stringify(nested)}\n`);
+      }
     } catch {}
   },
 };
@@ -435,6 +518,18 @@ const ThreePioVitestReporter = class {
       count: specifications?.length || 0,
       specs: specifications?.map((s) => s.moduleId || s.filepath || s),
     });
+
+    // In watch mode every re-run starts here, so groups are reported afresh
+    this.filesStarted.clear();
+    this.discoveredGroups.clear();
+    this.groupStarts.clear();
+    this.fileGroups.clear();
+    IPCSender.sendEvent({
+      eventType: 'runStart',
+      payload: {},
+    }).catch((error) => {
+      this.logger.error('Failed to send runStart event', error);
+    });
   }
 
   onTestModuleCollected(testModule) {
@@ -619,6 +714,13 @@ const ThreePioVitestReporter = class {
       modules: testModules?.length || 0,
       errors: unhandledErrors?.length || 0,
     });
+
+    IPCSender.sendEvent({
+      eventType: 'runComplete',
+      payload: {},
+    }).catch((error) => {
+      this.logger.error('Failed to send runComplete event', error);
+    });
   }
 
   onHookStart(hook) {
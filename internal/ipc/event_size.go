@@ -0,0 +1,37 @@
+package ipc
+
+// DefaultMaxEventSize caps the size of a single IPC event line, in bytes. Events are read
+// with bufio.Reader.ReadBytes, which grows its buffer as needed and has no limit of its
+// own, so the cap only keeps a runaway line from exhausting memory. It is well above the
+// 10MB lines native runners scan from test output, so any event built from one fits.
+const DefaultMaxEventSize = 64 * 1024 * 1024
+
+// SetMaxEventSize changes the largest IPC event line the manager accepts. Longer lines are
+// dropped and logged. Call it before WatchEvents.
+func (m *Manager) SetMaxEventSize(size int) {
+	m.readerMu.Lock()
+	defer m.readerMu.Unlock()
+	m.maxEventSize = size
+}
+
+// eventTooLarge logs and reports whether an event line of size bytes is over the limit
+func (m *Manager) eventTooLarge(size int) bool {
+	if size <= m.maxEventSize {
+		return false
+	}
+	m.logger.Error("Dropping IPC event of %d bytes, over the %d byte limit", size, m.maxEventSize)
+	return true
+}
+
+// bufferPartialLine keeps the start of a line that has not been completely written yet.
+// A line that grows past the limit is discarded up to its newline. Caller must hold m.readerMu.
+func (m *Manager) bufferPartialLine(chunk []byte) {
+	if m.skippingLine {
+		return
+	}
+	m.partialBuffer = append(m.partialBuffer, chunk...)
+	if m.eventTooLarge(len(m.partialBuffer)) {
+		m.partialBuffer = nil
+		m.skippingLine = true
+	}
+}
@@ -0,0 +1,112 @@
+package ipc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_LargeEventRoundTrip(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	manager, err := NewManager(ipcPath, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer func() { _ = manager.Cleanup() }()
+	if err := manager.WatchEvents(); err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+
+	stdout := strings.Repeat("log line with some detail\n", 200*1024) // ~5MB
+	event := NewGroupTestCaseEvent("prints a lot", []string{"noisy.test.js"}, "PASS")
+	event.Payload.Stdout = stdout
+	if err := AppendEvent(ipcPath, event); err != nil {
+		t.Fatalf("Failed to write event: %v", err)
+	}
+
+	select {
+	case received := <-manager.Events:
+		testCase, ok := received.(GroupTestCaseEvent)
+		if !ok {
+			t.Fatalf("Expected a test case event, got %T", received)
+		}
+		if testCase.Payload.Stdout != stdout {
+			t.Errorf("Expected %d bytes of stdout, got %d", len(stdout), len(testCase.Payload.Stdout))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the large event")
+	}
+}
+
+func TestManager_DropsOversizedEvent(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	logger := &mockLogger{}
+	manager, err := NewManager(ipcPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetMaxEventSize(1024)
+
+	big := NewGroupTestCaseEvent("too big", []string{"a.test.js"}, "PASS")
+	big.Payload.Stdout = strings.Repeat("x", 4096)
+	for _, event := range []interface{}{big, NewGroupTestCaseEvent("small", []string{"a.test.js"}, "PASS")} {
+		if err := AppendEvent(ipcPath, event); err != nil {
+			t.Fatalf("Failed to write event: %v", err)
+		}
+	}
+
+	if err := manager.WatchEvents(); err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+	_ = manager.Cleanup()
+
+	var names []string
+	for event := range manager.Events {
+		names = append(names, event.(GroupTestCaseEvent).Payload.TestName)
+	}
+	if len(names) != 1 || names[0] != "small" {
+		t.Errorf("Expected only the small event, got %v", names)
+	}
+	if errs := logger.getErrorMessages(); len(errs) != 1 || !strings.Contains(errs[0], "over the 1024 byte limit") {
+		t.Errorf("Expected one error about the size limit, got %v", errs)
+	}
+}
+
+func TestManager_CleanupReadsTrailingEvents(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	manager, err := NewManager(ipcPath, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.WatchEvents(); err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+
+	// Written right before Cleanup, like the last events of a test process that just exited
+	const count = 50
+	for i := 0; i < count; i++ {
+		if err := AppendEvent(ipcPath, NewGroupTestCaseEvent("test", []string{"a.test.js"}, "PASS")); err != nil {
+			t.Fatalf("Failed to write event: %v", err)
+		}
+	}
+	// A writer killed mid-write can leave the last line without its newline
+	file, err := os.OpenFile(ipcPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open IPC file: %v", err)
+	}
+	_, _ = file.WriteString(`{"eventType":"testCase","payload":{"testName":"last","status":"PASS"}}`)
+	_ = file.Close()
+	_ = manager.Cleanup()
+
+	received := 0
+	last := ""
+	for event := range manager.Events {
+		received++
+		last = event.(GroupTestCaseEvent).Payload.TestName
+	}
+	if received != count+1 || last != "last" {
+		t.Errorf("Expected %d events ending with the unterminated one, got %d ending with %q", count+1, received, last)
+	}
+}
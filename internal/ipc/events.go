@@ -7,6 +7,7 @@ type EventType string
 
 const (
 	EventTypeTestCase         EventType = "testCase"
+	EventTypeRunStart         EventType = "runStart"
 	EventTypeRunComplete      EventType = "runComplete"
 	EventTypeCollectionStart  EventType = "collectionStart"
 	EventTypeCollectionError  EventType = "collectionError"
@@ -32,6 +33,15 @@ type Event interface {
 	Type() EventType
 }
 
+// RunStartEvent indicates that the test runner is starting a run. In watch mode it is
+// sent again for every re-run.
+type RunStartEvent struct {
+	EventType EventType `json:"eventType"`
+	Payload   struct{}  `json:"payload"`
+}
+
+func (e RunStartEvent) Type() EventType { return EventTypeRunStart }
+
 // RunCompleteEvent indicates that the test runner has completed
 type RunCompleteEvent struct {
 	EventType EventType `json:"eventType"`
@@ -44,7 +54,8 @@ func (e RunCompleteEvent) Type() EventType { return EventTypeRunComplete }
 type CollectionStartEvent struct {
 	EventType EventType `json:"eventType"`
 	Payload   struct {
-		Phase string `json:"phase"`
+		Phase     string `json:"phase"`
+		Collected int    `json:"collected,omitempty"` // Tests about to be collected, when known
 	} `json:"payload"`
 }
 
@@ -72,6 +83,25 @@ type CollectionFinishEvent struct {
 
 func (e CollectionFinishEvent) Type() EventType { return EventTypeCollectionFinish }
 
+// NewRunCompleteEvent creates a new run complete event
+func NewRunCompleteEvent() RunCompleteEvent {
+	return RunCompleteEvent{EventType: EventTypeRunComplete}
+}
+
+// NewCollectionStartEvent creates a new collection start event for collected tests
+func NewCollectionStartEvent(collected int) CollectionStartEvent {
+	event := CollectionStartEvent{EventType: EventTypeCollectionStart}
+	event.Payload.Collected = collected
+	return event
+}
+
+// NewCollectionFinishEvent creates a new collection finish event for collected tests
+func NewCollectionFinishEvent(collected int) CollectionFinishEvent {
+	event := CollectionFinishEvent{EventType: EventTypeCollectionFinish}
+	event.Payload.Collected = collected
+	return event
+}
+
 // TestCase represents a test case in the test run state
 type TestCase struct {
 	Name     string     `json:"name"`
@@ -104,4 +134,22 @@ type TestRunState struct {
 	FilesSkipped   int        `json:"filesSkipped"`
 	TestFiles      []TestFile `json:"testFiles"`
 	ErrorDetails   string     `json:"errorDetails,omitempty"` // Error details when status is ERROR
+	AbortReason    string     `json:"abortReason,omitempty"`  // Why the run was stopped before the test command finished
+	ExitReason     string     `json:"exitReason,omitempty"`   // Why the run exited non-zero, e.g. "tests_failed"
+	Signal         string     `json:"signal,omitempty"`       // Signal that killed the test command, e.g. "SIGKILL"
+
+	RunnerExitCode *int `json:"runnerExitCode,omitempty"` // The test command's own exit code, when 3pio exits with another
+	ExitCode       int  `json:"exitCode,omitempty"`       // 3pio's exit code, set along with RunnerExitCode
+
+	CrashedAttempts []CrashedAttempt `json:"crashedAttempts,omitempty"` // Earlier attempts re-run with --retry-on-crash
+	Warnings        []string         `json:"warnings,omitempty"`        // Problems that may make the results unreliable
+}
+
+// CrashedAttempt is an execution of the test command that crashed before reporting any
+// results and was run again (--retry-on-crash)
+type CrashedAttempt struct {
+	Attempt   int    `json:"attempt"`             // 1 for the first execution
+	ExitCode  int    `json:"exitCode"`            // Exit code, 128+n when killed by signal n
+	Signal    string `json:"signal,omitempty"`    // Signal that killed the command, e.g. "SIGSEGV"
+	OutputLog string `json:"outputLog,omitempty"` // The attempt's output, relative to the run directory
 }
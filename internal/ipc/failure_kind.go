@@ -0,0 +1,58 @@
+package ipc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FailureKind classifies why a test case failed, so a logic bug can be told apart from
+// a crash or a flaky timeout
+type FailureKind string
+
+const (
+	FailureKindAssertion  FailureKind = "assertion"    // An expectation in the test didn't hold
+	FailureKindException  FailureKind = "exception"    // The test threw, raised or panicked unexpectedly
+	FailureKindTimeout    FailureKind = "timeout"      // The test ran out of time
+	FailureKindDataRace   FailureKind = "data_race"    // The race detector found a data race while the test ran
+	FailureKindSkipAsFail FailureKind = "skip_as_fail" // An expected failure that passed under strict xfail
+	FailureKindUnknown    FailureKind = "unknown"      // Nothing in the error tells
+)
+
+// assertionWords matches wording of failed expectations, but not e.g. "Unexpected token"
+var assertionWords = regexp.MustCompile(`\b(assert|expect)`)
+
+// errorNamePrefix matches an error class name leading a message, e.g. "TypeError: ..."
+var errorNamePrefix = regexp.MustCompile(`^([A-Za-z_][\w.:]*(?:Error|Exception|Panic)):`)
+
+// ClassifyFailure returns the kind of a test error. A kind set by the runner's definition
+// or adapter wins; otherwise the error type and the first line of the message decide.
+func ClassifyFailure(testError *TestError) FailureKind {
+	if testError == nil {
+		return FailureKindUnknown
+	}
+	if testError.FailureKind != "" {
+		return testError.FailureKind
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(testError.Message), "\n")
+	errorType := testError.ErrorType
+	if errorType == "" {
+		if match := errorNamePrefix.FindStringSubmatch(firstLine); match != nil {
+			errorType = match[1]
+		}
+	}
+	text := strings.ToLower(errorType + " " + firstLine)
+
+	switch {
+	case strings.Contains(text, "xpass(strict)"):
+		return FailureKindSkipAsFail
+	case strings.Contains(text, "timeout") || strings.Contains(text, "timed out"):
+		return FailureKindTimeout
+	case assertionWords.MatchString(text):
+		return FailureKindAssertion
+	case errorType != "":
+		return FailureKindException
+	default:
+		return FailureKindUnknown
+	}
+}
@@ -0,0 +1,31 @@
+package ipc
+
+import "testing"
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		error *TestError
+		kind  FailureKind
+	}{
+		{"no error", nil, FailureKindUnknown},
+		{"set by the runner", &TestError{Message: "boom", FailureKind: FailureKindTimeout}, FailureKindTimeout},
+		{"jest expect", &TestError{Message: "Error: expect(received).toBe(expected)\n\nExpected: 2"}, FailureKindAssertion},
+		{"chai", &TestError{Message: "expected 1 to equal 2", ErrorType: "AssertionError"}, FailureKindAssertion},
+		{"rspec", &TestError{Message: "expected: 2\n     got: 1", ErrorType: "RSpec::Expectations::ExpectationNotMetError"}, FailureKindAssertion},
+		{"mocha timeout", &TestError{Message: "Timeout of 2000ms exceeded.", ErrorType: "Error"}, FailureKindTimeout},
+		{"playwright timeout", &TestError{Message: "Test timeout of 30000ms exceeded.", ErrorType: "TIMEOUT"}, FailureKindTimeout},
+		{"thrown error", &TestError{Message: "TypeError: cart.add is not a function"}, FailureKindException},
+		{"unexpected is not expect", &TestError{Message: "Unexpected token '<'", ErrorType: "SyntaxError"}, FailureKindException},
+		{"strict xpass", &TestError{Message: "[XPASS(strict)] flaky on CI"}, FailureKindSkipAsFail},
+		{"plain message", &TestError{Message: "something went wrong"}, FailureKindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := ClassifyFailure(tt.error); kind != tt.kind {
+				t.Errorf("Expected %s, got %s", tt.kind, kind)
+			}
+		})
+	}
+}
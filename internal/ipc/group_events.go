@@ -86,18 +86,30 @@ type TestCasePayload struct {
 	Stdout      string                 `json:"stdout,omitempty"`
 	Stderr      string                 `json:"stderr,omitempty"`
 	XFailReason string                 `json:"xfailReason,omitempty"` // Reason for expected failure (xfail marker)
+	SkipReason  string                 `json:"skipReason,omitempty"`  // Why a skipped test was skipped (skipif marker, t.Skip message)
+	Benchmark   *BenchmarkResult       `json:"benchmark,omitempty"`   // Benchmark measurements (go test -bench)
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	Timestamp   int64                  `json:"timestamp,omitempty"`
 }
 
 // TestError contains error information for failed tests
 type TestError struct {
-	Message   string `json:"message"`
-	Stack     string `json:"stack,omitempty"`
-	Expected  string `json:"expected,omitempty"`
-	Actual    string `json:"actual,omitempty"`
-	Location  string `json:"location,omitempty"`  // File:line
-	ErrorType string `json:"errorType,omitempty"` // e.g., "AssertionError"
+	Message     string      `json:"message"`
+	Stack       string      `json:"stack,omitempty"`
+	Expected    string      `json:"expected,omitempty"`
+	Actual      string      `json:"actual,omitempty"`
+	Location    string      `json:"location,omitempty"`    // File:line
+	ErrorType   string      `json:"errorType,omitempty"`   // e.g., "AssertionError"
+	FailureKind FailureKind `json:"failureKind,omitempty"` // Set by runners that can tell; see ClassifyFailure
+}
+
+// BenchmarkResult contains measurements for a benchmark test case
+type BenchmarkResult struct {
+	Iterations  int64   `json:"iterations"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	BytesPerOp  int64   `json:"bytesPerOp,omitempty"`
+	AllocsPerOp int64   `json:"allocsPerOp,omitempty"`
+	MemStats    bool    `json:"memStats,omitempty"` // True when B/op and allocs/op were reported (-benchmem)
 }
 
 // GroupStdoutChunkEvent represents stdout output from a test group
@@ -134,7 +146,7 @@ func (e GroupErrorEvent) Type() EventType { return EventTypeGroupError }
 type GroupErrorPayload struct {
 	GroupName   string                 `json:"groupName"`
 	ParentNames []string               `json:"parentNames,omitempty"`
-	ErrorType   string                 `json:"errorType"`          // "SETUP_FAILURE", "COMPILATION_FAILURE", etc.
+	ErrorType   string                 `json:"errorType"`          // "SETUP_FAILURE", "BUILD_FAILURE", etc.
 	Duration    float64                `json:"duration,omitempty"` // Duration in milliseconds
 	Error       *GroupError            `json:"error,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
@@ -145,6 +157,7 @@ type GroupErrorPayload struct {
 type GroupError struct {
 	Message string `json:"message"`
 	Phase   string `json:"phase,omitempty"` // "setup", "compilation", "collection", etc.
+	Stack   string `json:"stack,omitempty"` // Stack trace, e.g. the goroutine dump of a Go panic
 }
 
 // GenericEvent is used for parsing unknown event types
@@ -219,6 +232,30 @@ func NewGroupTestCaseEvent(testName string, parentNames []string, status string)
 	}
 }
 
+// NewGroupStdoutEvent creates a new stdout chunk event for a group
+func NewGroupStdoutEvent(groupName string, parentNames []string, chunk string) GroupStdoutChunkEvent {
+	return GroupStdoutChunkEvent{
+		EventType: string(EventTypeGroupStdout),
+		Payload: OutputChunkPayload{
+			GroupName:   groupName,
+			ParentNames: parentNames,
+			Chunk:       chunk,
+		},
+	}
+}
+
+// NewGroupStderrEvent creates a new stderr chunk event for a group
+func NewGroupStderrEvent(groupName string, parentNames []string, chunk string) GroupStderrChunkEvent {
+	return GroupStderrChunkEvent{
+		EventType: string(EventTypeGroupStderr),
+		Payload: OutputChunkPayload{
+			GroupName:   groupName,
+			ParentNames: parentNames,
+			Chunk:       chunk,
+		},
+	}
+}
+
 // IsGroupEvent returns true if the event type is a group-related event
 func IsGroupEvent(eventType string) bool {
 	switch EventType(eventType) {
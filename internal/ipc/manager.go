@@ -2,17 +2,22 @@ package ipc
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 
 	"github.com/fsnotify/fsnotify"
 )
 
-// Manager handles IPC communication via file-based JSONL
+// Manager handles IPC communication via file-based JSONL, or a Unix domain socket when
+// created with NewSocketManager
 type Manager struct {
 	IPCPath       string
 	watcher       *fsnotify.Watcher
@@ -26,6 +31,23 @@ type Manager struct {
 	reader        *bufio.Reader
 	readerMu      sync.Mutex // Protects concurrent access to reader
 	partialBuffer []byte
+	skippingLine  bool // The rest of an oversized line is being discarded
+	maxEventSize  int  // Longer event lines are dropped (DefaultMaxEventSize)
+
+	// Lines read and those that were not valid events (see LineCounts)
+	lines          atomic.Int64
+	malformedLines atomic.Int64
+	schemaVersion  atomic.Int64 // Highest version declared by a schema header (UnsupportedSchema)
+
+	// Socket transport (NewSocketManager)
+	listener   net.Listener
+	socketPath string
+	record     *os.File   // ipc.jsonl copy of the events read from the socket
+	recordMu   sync.Mutex // Serializes record writes from concurrent connections
+	conns      map[net.Conn]struct{}
+	connsMu    sync.Mutex
+	draining   bool // Set by Cleanup; connections get a read deadline
+	readers    sync.WaitGroup
 }
 
 // Logger interface for debug logging
@@ -53,13 +75,14 @@ func NewManager(ipcPath string, logger Logger) (*Manager, error) {
 	}
 
 	return &Manager{
-		IPCPath:  ipcPath,
-		Events:   make(chan Event, 10000), // Large buffer for handling burst of events
-		stopChan: make(chan struct{}),
-		stopped:  make(chan struct{}),
-		logger:   logger,
-		file:     file,
-		reader:   bufio.NewReader(file),
+		IPCPath:      ipcPath,
+		Events:       make(chan Event, 10000), // Large buffer for handling burst of events
+		stopChan:     make(chan struct{}),
+		stopped:      make(chan struct{}),
+		logger:       logger,
+		file:         file,
+		reader:       bufio.NewReader(file),
+		maxEventSize: DefaultMaxEventSize,
 	}, nil
 }
 
@@ -68,6 +91,10 @@ func (m *Manager) WatchEvents() error {
 	if m.watcher != nil {
 		return fmt.Errorf("watch already started")
 	}
+	if m.listener != nil {
+		go m.acceptLoop()
+		return nil
+	}
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("failed to create file watcher: %w", err)
@@ -98,7 +125,7 @@ func (m *Manager) readEvents() {
 		if err != nil {
 			if err == io.EOF {
 				if len(line) > 0 {
-					m.partialBuffer = append(m.partialBuffer, line...)
+					m.bufferPartialLine(line)
 				}
 				break
 			}
@@ -106,16 +133,39 @@ func (m *Manager) readEvents() {
 			break
 		}
 
+		if m.skippingLine {
+			// End of a line that was dropped for being too large
+			m.skippingLine = false
+			continue
+		}
 		if len(line) > 0 {
 			if len(m.partialBuffer) > 0 {
 				line = append(append([]byte(nil), m.partialBuffer...), line...)
 				m.partialBuffer = nil
 			}
+			if m.eventTooLarge(len(line)) {
+				continue
+			}
 			m.parseAndSendEvent(line)
 		}
 	}
 }
 
+// drainFile reads the events written since the last file change was handled. Cleanup
+// calls it once the watcher has stopped, since the last writes may not have been handled
+// yet. A final line without a newline is parsed as is.
+func (m *Manager) drainFile() {
+	m.readEvents()
+
+	m.readerMu.Lock()
+	defer m.readerMu.Unlock()
+	if len(m.partialBuffer) > 0 {
+		m.logger.Debug("Parsing final IPC line without a newline (%d bytes)", len(m.partialBuffer))
+		m.parseAndSendEvent(m.partialBuffer)
+		m.partialBuffer = nil
+	}
+}
+
 // watchLoop watches for file changes and triggers reads
 func (m *Manager) watchLoop() {
 	defer close(m.stopped)
@@ -147,119 +197,150 @@ func (m *Manager) watchLoop() {
 
 // parseAndSendEvent parses a JSON line and sends it as an event
 func (m *Manager) parseAndSendEvent(line []byte) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	m.lines.Add(1)
+	event, err := ParseEvent(line)
+	if err != nil {
+		var unknown *UnknownEventTypeError
+		switch {
+		case errors.As(err, &unknown):
+			// A newer adapter's event rather than a corrupt line
+			m.logger.Error("[3PIO ERROR] Unknown event type: %s", unknown.EventType)
+		case errors.Is(err, ErrMissingEventType):
+			m.malformedLines.Add(1)
+			m.logger.Error("Event missing eventType field")
+		default:
+			m.malformedLines.Add(1)
+			m.logger.Debug("Failed to parse event: %v", err)
+		}
+		return
+	}
+	if schema, ok := event.(SchemaEvent); ok {
+		m.handleSchema(schema)
+		return
+	}
+
+	// Send event to channel (blocking send for natural backpressure)
+	m.Events <- event
+	m.logger.Debug("Processing IPC event: %s", event.Type())
+}
+
+// LineCounts returns how many non-blank lines were read and how many of them were not
+// valid events, such as output of the code under test written to THREEPIO_IPC_PATH.
+// Events of an unknown type are counted as valid.
+func (m *Manager) LineCounts() (lines, malformed int) {
+	return int(m.lines.Load()), int(m.malformedLines.Load())
+}
+
+// ErrMissingEventType is returned by ParseEvent for a line without an eventType field
+var ErrMissingEventType = errors.New("event missing eventType field")
+
+// UnknownEventTypeError is returned by ParseEvent for an eventType it does not know
+type UnknownEventTypeError struct {
+	EventType string
+}
+
+func (e *UnknownEventTypeError) Error() string {
+	return fmt.Sprintf("unknown event type: %s", e.EventType)
+}
+
+// ParseEvent decodes one JSON line of an IPC file into its typed event
+func ParseEvent(line []byte) (Event, error) {
 	// First, decode to determine event type
 	var rawEvent map[string]interface{}
 	if err := json.Unmarshal(line, &rawEvent); err != nil {
-		m.logger.Debug("Failed to parse event: %v", err)
-		return
+		return nil, err
 	}
 
 	eventType, ok := rawEvent["eventType"].(string)
 	if !ok {
-		m.logger.Error("Event missing eventType field")
-		return
+		return nil, ErrMissingEventType
 	}
 
-	// Parse based on event type
 	var event Event
+	var err error
 	switch EventType(eventType) {
+	case EventTypeSchema:
+		event, err = decodeEvent[SchemaEvent](line)
 	case EventTypeTestCase:
 		// Only new group-based testCase events are supported
-		var e GroupTestCaseEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group test case event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupTestCaseEvent](line)
+	case EventTypeRunStart:
+		event, err = decodeEvent[RunStartEvent](line)
 	case EventTypeRunComplete:
-		var e RunCompleteEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse run complete event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[RunCompleteEvent](line)
 	case EventTypeCollectionStart:
-		var e CollectionStartEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse collection start event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[CollectionStartEvent](line)
 	case EventTypeCollectionError:
-		var e CollectionErrorEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse collection error event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[CollectionErrorEvent](line)
 	case EventTypeCollectionFinish:
-		var e CollectionFinishEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse collection finish event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[CollectionFinishEvent](line)
 	case EventTypeGroupDiscovered:
-		var e GroupDiscoveredEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group discovered event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupDiscoveredEvent](line)
 	case EventTypeGroupStart:
-		var e GroupStartEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group start event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupStartEvent](line)
 	case EventTypeGroupResult:
-		var e GroupResultEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group result event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupResultEvent](line)
 	case EventTypeGroupError:
-		var e GroupErrorEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group error event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupErrorEvent](line)
 	case EventTypeGroupStdout:
-		var e GroupStdoutChunkEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group stdout event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupStdoutChunkEvent](line)
 	case EventTypeGroupStderr:
-		var e GroupStderrChunkEvent
-		if err := json.Unmarshal(line, &e); err != nil {
-			m.logger.Debug("Failed to parse group stderr event: %v", err)
-			return
-		}
-		event = e
-
+		event, err = decodeEvent[GroupStderrChunkEvent](line)
 	default:
-		m.logger.Error("[3PIO ERROR] Unknown event type: %s", eventType)
-		return
+		return nil, &UnknownEventTypeError{EventType: eventType}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
 	}
+	return event, nil
+}
 
-	// Send event to channel (blocking send for natural backpressure)
-	m.Events <- event
-	m.logger.Debug("Processing IPC event: %s", eventType)
+// decodeEvent unmarshals a line into the event type E
+func decodeEvent[E Event](line []byte) (Event, error) {
+	var e E
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReplayFile parses every event of a completed IPC file in order and passes it to handle.
+// Lines that cannot be parsed are skipped and logged, like in a live run, and schema
+// headers are not passed on. It stops at the first error returned by handle.
+func ReplayFile(ipcPath string, logger Logger, handle func(Event) error) error {
+	if logger == nil {
+		logger = &noopLogger{}
+	}
+
+	file, err := os.Open(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open IPC file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			event, parseErr := ParseEvent(line)
+			if parseErr != nil {
+				logger.Debug("Skipping IPC line %d: %v", lineNumber, parseErr)
+			} else if schema, ok := event.(SchemaEvent); ok {
+				logger.Debug("IPC line %d declares schema version %d", lineNumber, schema.Payload.Version)
+			} else if handleErr := handle(event); handleErr != nil {
+				return handleErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read IPC file: %w", err)
+		}
+	}
 }
 
 // Cleanup stops watching and closes resources
@@ -272,9 +353,19 @@ func (m *Manager) Cleanup() error {
 		close(m.stopChan)
 	}
 
-	// Wait for watchLoop to finish before cleaning up resources
+	// Socket connections are drained by acceptLoop once the listener is closed
+	if m.listener != nil {
+		m.stopListening()
+	}
+
+	// Wait for watchLoop (or acceptLoop) to finish before cleaning up resources
 	<-m.stopped
 
+	// Events written just before Cleanup may not have been read by watchLoop yet
+	if m.listener == nil && m.file != nil {
+		m.drainFile()
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -288,6 +379,15 @@ func (m *Manager) Cleanup() error {
 		m.file = nil
 	}
 
+	if m.record != nil {
+		_ = m.record.Close()
+		m.record = nil
+	}
+	if m.socketPath != "" {
+		_ = os.RemoveAll(filepath.Dir(m.socketPath))
+		m.socketPath = ""
+	}
+
 	// Close channels only once using sync.Once
 	m.closeOnce.Do(func() {
 		if m.Events != nil {
@@ -298,12 +398,25 @@ func (m *Manager) Cleanup() error {
 	return nil
 }
 
-// SendEvent writes an event to the IPC file (for adapters)
+// SendEvent writes an event to the IPC file or socket (for adapters)
 func SendEvent(event interface{}) error {
 	ipcPath := os.Getenv("THREEPIO_IPC_PATH")
 	if ipcPath == "" {
 		return fmt.Errorf("THREEPIO_IPC_PATH not set")
 	}
+	return AppendEvent(ipcPath, event)
+}
+
+// AppendEvent writes an event as a JSON line to the IPC file at ipcPath, or to the socket
+// when ipcPath uses SocketScheme
+func AppendEvent(ipcPath string, event interface{}) error {
+	if socketPath, ok := SocketPath(ipcPath); ok {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		return sendSocketEvent(socketPath, data)
+	}
 
 	// Ensure directory exists
 	ipcDir := filepath.Dir(ipcPath)
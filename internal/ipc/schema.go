@@ -0,0 +1,55 @@
+package ipc
+
+// SchemaVersion is the version of the IPC event contract written by this build. It goes
+// up when an event changes in a way older readers would get wrong; new optional fields
+// don't need a new version.
+const SchemaVersion = 1
+
+// EventTypeSchema is the header line a writer sends before its events
+const EventTypeSchema EventType = "schema"
+
+// SchemaEvent declares the version of the events that follow it. Writers that don't send
+// one (the runner adapters) are read as the current version.
+type SchemaEvent struct {
+	EventType EventType     `json:"eventType"`
+	Payload   SchemaPayload `json:"payload"`
+}
+
+func (e SchemaEvent) Type() EventType { return EventTypeSchema }
+
+type SchemaPayload struct {
+	Version int `json:"version"`
+}
+
+// NewSchemaEvent creates the header line for events of the current SchemaVersion
+func NewSchemaEvent() SchemaEvent {
+	return SchemaEvent{
+		EventType: EventTypeSchema,
+		Payload:   SchemaPayload{Version: SchemaVersion},
+	}
+}
+
+// handleSchema records the version declared by a schema header. Events of a newer
+// version are still read as far as they are understood.
+func (m *Manager) handleSchema(event SchemaEvent) {
+	version := event.Payload.Version
+	m.logger.Debug("IPC events use schema version %d", version)
+	if version > SchemaVersion {
+		m.logger.Error("IPC events use schema version %d, newer than the supported version %d", version, SchemaVersion)
+	}
+	for {
+		current := m.schemaVersion.Load()
+		if int64(version) <= current || m.schemaVersion.CompareAndSwap(current, int64(version)) {
+			return
+		}
+	}
+}
+
+// UnsupportedSchema returns the schema version declared by the events read when it is
+// newer than SchemaVersion, meaning 3pio is older than what wrote them, and 0 otherwise
+func (m *Manager) UnsupportedSchema() int {
+	if version := int(m.schemaVersion.Load()); version > SchemaVersion {
+		return version
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package ipc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManager_SchemaHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		unsupported int
+	}{
+		{"current version", SchemaVersion, 0},
+		{"newer version", SchemaVersion + 1, SchemaVersion + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+			header := NewSchemaEvent()
+			header.Payload.Version = tt.version
+			if err := AppendEvent(ipcPath, header); err != nil {
+				t.Fatal(err)
+			}
+			if err := AppendEvent(ipcPath, NewRunCompleteEvent()); err != nil {
+				t.Fatal(err)
+			}
+
+			manager, err := NewManager(ipcPath, &mockLogger{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := manager.WatchEvents(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The header is read but not passed on; the events after it are
+			select {
+			case event := <-manager.Events:
+				if event.Type() != EventTypeRunComplete {
+					t.Errorf("Expected the runComplete event after the header, got %s", event.Type())
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timed out waiting for the runComplete event")
+			}
+			_ = manager.Cleanup()
+
+			if got := manager.UnsupportedSchema(); got != tt.unsupported {
+				t.Errorf("UnsupportedSchema() = %d, want %d", got, tt.unsupported)
+			}
+			if lines, malformed := manager.LineCounts(); lines != 2 || malformed != 0 {
+				t.Errorf("Expected 2 valid lines, got %d lines with %d malformed", lines, malformed)
+			}
+		})
+	}
+}
+
+func TestReplayFile_SkipsSchemaHeader(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	content := `{"eventType":"schema","payload":{"version":1}}
+{"eventType":"testGroupStart","payload":{"groupName":"pkg"}}
+`
+	if err := os.WriteFile(ipcPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []EventType
+	err := ReplayFile(ipcPath, nil, func(event Event) error {
+		types = append(types, event.Type())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReplayFile failed: %v", err)
+	}
+	if len(types) != 1 || types[0] != EventTypeGroupStart {
+		t.Errorf("Expected only the testGroupStart event, got %v", types)
+	}
+}
@@ -0,0 +1,181 @@
+package ipc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SocketScheme prefixes an IPC path that names a Unix domain socket instead of a JSONL file,
+// e.g. "unix:/tmp/3pio-ipc-123/ipc.sock"
+const SocketScheme = "unix:"
+
+// socketDrainTimeout is how long Cleanup waits for connections that are still open to
+// deliver their remaining events, e.g. from a worker process that outlived the command
+const socketDrainTimeout = 2 * time.Second
+
+// SocketSupported reports whether the socket transport can be used on this platform.
+// AF_UNIX support on Windows depends on the Windows build, so Windows always uses files.
+func SocketSupported() bool {
+	return runtime.GOOS != "windows"
+}
+
+// SocketPath returns the socket path of an IPC path using SocketScheme
+func SocketPath(ipcPath string) (string, bool) {
+	if !strings.HasPrefix(ipcPath, SocketScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(ipcPath, SocketScheme), true
+}
+
+// NewSocketManager creates an IPC manager that reads newline-delimited JSON events from
+// connections to a Unix domain socket instead of tailing a file. Every event read is also
+// appended to recordPath, so the run keeps a complete ipc.jsonl for "3pio report". The
+// socket lives in a temporary directory, since socket paths are limited to ~100 bytes.
+func NewSocketManager(recordPath string, logger Logger) (*Manager, error) {
+	if logger == nil {
+		logger = &noopLogger{}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create IPC directory: %w", err)
+	}
+	record, err := os.OpenFile(recordPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IPC file: %w", err)
+	}
+
+	socketDir, err := os.MkdirTemp("", "3pio-ipc-")
+	if err != nil {
+		_ = record.Close()
+		return nil, fmt.Errorf("failed to create IPC socket directory: %w", err)
+	}
+	socketPath := filepath.Join(socketDir, "ipc.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		_ = record.Close()
+		_ = os.RemoveAll(socketDir)
+		return nil, fmt.Errorf("failed to listen on IPC socket: %w", err)
+	}
+
+	return &Manager{
+		IPCPath:      recordPath,
+		Events:       make(chan Event, 10000),
+		stopChan:     make(chan struct{}),
+		stopped:      make(chan struct{}),
+		logger:       logger,
+		listener:     listener,
+		socketPath:   socketPath,
+		record:       record,
+		conns:        make(map[net.Conn]struct{}),
+		maxEventSize: DefaultMaxEventSize,
+	}, nil
+}
+
+// Address returns the IPC path writers should be given: the socket address with
+// SocketScheme for a socket manager, otherwise the IPC file path
+func (m *Manager) Address() string {
+	if m.listener != nil {
+		return SocketScheme + m.socketPath
+	}
+	return m.IPCPath
+}
+
+// acceptLoop accepts writer connections until the listener is closed, then waits for
+// the connections to be drained
+func (m *Manager) acceptLoop() {
+	defer close(m.stopped)
+
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				m.logger.Error("IPC socket accept failed: %v", err)
+			}
+			break
+		}
+
+		m.connsMu.Lock()
+		m.conns[conn] = struct{}{}
+		if m.draining {
+			_ = conn.SetReadDeadline(time.Now().Add(socketDrainTimeout))
+		}
+		m.connsMu.Unlock()
+
+		m.readers.Add(1)
+		go m.readConn(conn)
+	}
+
+	m.readers.Wait()
+}
+
+// readConn reads events from one writer connection until it is closed
+func (m *Manager) readConn(conn net.Conn) {
+	defer m.readers.Done()
+	defer func() {
+		m.connsMu.Lock()
+		delete(m.conns, conn)
+		m.connsMu.Unlock()
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if len(line) > 0 {
+				m.logger.Debug("Dropping incomplete IPC event from closed connection (%d bytes)", len(line))
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				m.logger.Debug("Closing IPC connection still open after %v", socketDrainTimeout)
+			}
+			return
+		}
+		if m.eventTooLarge(len(line)) {
+			continue
+		}
+
+		m.recordMu.Lock()
+		if _, err := m.record.Write(line); err != nil {
+			m.logger.Debug("Failed to record IPC event: %v", err)
+		}
+		m.recordMu.Unlock()
+
+		m.parseAndSendEvent(line)
+	}
+}
+
+// stopListening closes the socket listener and gives open connections socketDrainTimeout
+// to deliver what they have written
+func (m *Manager) stopListening() {
+	_ = m.listener.Close()
+
+	m.connsMu.Lock()
+	defer m.connsMu.Unlock()
+	m.draining = true
+	deadline := time.Now().Add(socketDrainTimeout)
+	for conn := range m.conns {
+		_ = conn.SetReadDeadline(deadline)
+	}
+}
+
+// sendSocketEvent writes one JSON line to the IPC socket at socketPath
+func sendSocketEvent(socketPath string, data []byte) error {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to IPC socket: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
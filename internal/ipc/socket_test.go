@@ -0,0 +1,86 @@
+package ipc
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSocketManager_ReadsAndRecordsEvents(t *testing.T) {
+	if !SocketSupported() {
+		t.Skip("IPC sockets are not used on this platform")
+	}
+
+	recordPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	manager, err := NewSocketManager(recordPath, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create socket manager: %v", err)
+	}
+	address := manager.Address()
+	socketPath, ok := SocketPath(address)
+	if !ok {
+		t.Fatalf("Expected a %s address, got %s", SocketScheme, address)
+	}
+	if err := manager.WatchEvents(); err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+
+	// One writer sends each event on its own connection, another keeps its connection
+	for _, group := range []string{"a.test.js", "b.test.js"} {
+		var event GroupDiscoveredEvent
+		event.EventType = string(EventTypeGroupDiscovered)
+		event.Payload.GroupName = group
+		if err := AppendEvent(address, event); err != nil {
+			t.Fatalf("AppendEvent failed: %v", err)
+		}
+	}
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	lines := `{"eventType":"testGroupStart","payload":{"groupName":"c.test.js","parentNames":[]}}` + "\n" +
+		`{"eventType":"testGroupResult","payload":{"groupName":"c.test.js","parentNames":[],"status":"PASS"}}` + "\n"
+	if _, err := conn.Write([]byte(lines)); err != nil {
+		t.Fatalf("Failed to write to socket: %v", err)
+	}
+	_ = conn.Close()
+
+	received := make(map[EventType]int)
+	for range 4 {
+		event := <-manager.Events
+		received[event.Type()]++
+	}
+	if received[EventTypeGroupDiscovered] != 2 || received[EventTypeGroupStart] != 1 || received[EventTypeGroupResult] != 1 {
+		t.Errorf("Unexpected events received: %v", received)
+	}
+
+	if err := manager.Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if _, ok := <-manager.Events; ok {
+		t.Error("Expected the events channel to be closed")
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed, got %v", err)
+	}
+
+	// The record keeps every event for replaying the run
+	data, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("Failed to read record: %v", err)
+	}
+	if count := strings.Count(string(data), "\n"); count != 4 {
+		t.Errorf("Expected 4 recorded events, got %d:\n%s", count, data)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	if path, ok := SocketPath("unix:/tmp/3pio-ipc-1/ipc.sock"); !ok || path != "/tmp/3pio-ipc-1/ipc.sock" {
+		t.Errorf("Expected the socket path, got %q (%v)", path, ok)
+	}
+	if _, ok := SocketPath("/project/.3pio/runs/x/ipc.jsonl"); ok {
+		t.Error("Expected a file path not to be a socket path")
+	}
+}
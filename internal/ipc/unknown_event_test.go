@@ -219,3 +219,64 @@ func TestManager_ShouldHandleRunCompleteGracefully(t *testing.T) {
 		}
 	}
 }
+
+func TestReplayFile(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	content := `{"eventType":"testGroupStart","payload":{"groupName":"a.test.js","parentNames":[]}}
+{"eventType":"unknownEvent","payload":{}}
+{"eventType":"testCase","payload":{"testName":"works","parentNames":["a.test.js"],"status":"PASS"}}
+{"eventType":"testGroupResult","payload":{"groupName":"a.test.js","parentNames":[],"status":"PASS"}}`
+	if err := os.WriteFile(ipcPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write IPC file: %v", err)
+	}
+
+	var types []EventType
+	err := ReplayFile(ipcPath, &mockLogger{}, func(event Event) error {
+		types = append(types, event.Type())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReplayFile failed: %v", err)
+	}
+
+	// The unknown event is skipped and the last line is read without a trailing newline
+	expected := []EventType{EventTypeGroupStart, EventTypeTestCase, EventTypeGroupResult}
+	if fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("Expected events %v, got %v", expected, types)
+	}
+}
+
+func TestManager_LineCounts(t *testing.T) {
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	lines := strings.Join([]string{
+		`{"eventType":"runComplete","payload":{}}`,
+		`{"eventType":"futureEvent","payload":{}}`,
+		`PASS src/app.test.js`,
+		``,
+		`{"level":"info","msg":"a test's own JSON log"}`,
+		`{"eventType":"runComplete","payload":{}}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(ipcPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write IPC file: %v", err)
+	}
+
+	manager, err := NewManager(ipcPath, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.WatchEvents(); err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+	go func() {
+		for range manager.Events {
+		}
+	}()
+	if err := manager.Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+
+	// Blank lines aren't counted and an unknown event type isn't malformed
+	if total, malformed := manager.LineCounts(); total != 5 || malformed != 2 {
+		t.Errorf("Expected 2 malformed of 5 lines, got %d of %d", malformed, total)
+	}
+}
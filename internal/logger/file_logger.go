@@ -60,13 +60,23 @@ type FileLogger struct {
 
 // NewFileLogger creates a new file-based logger
 func NewFileLogger() (*FileLogger, error) {
-	// Ensure .3pio directory exists
-	if err := os.MkdirAll(".3pio", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create .3pio directory: %w", err)
+	return NewFileLoggerInDir(".3pio")
+}
+
+// NewFileLoggerInDir creates a file-based logger writing to debug.log in dir.
+// An empty dir uses the default .3pio directory.
+func NewFileLoggerInDir(dir string) (*FileLogger, error) {
+	if dir == "" {
+		dir = ".3pio"
+	}
+
+	// Ensure the output directory exists
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s directory: %w", dir, err)
 	}
 
 	// Open debug log file in append mode
-	logPath := filepath.Join(".3pio", "debug.log")
+	logPath := filepath.Join(dir, "debug.log")
 	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open debug log: %w", err)
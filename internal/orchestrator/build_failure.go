@@ -0,0 +1,35 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// buildFailureErrorType is the testGroupError type runners send for code that failed to compile
+const buildFailureErrorType = "BUILD_FAILURE"
+
+// buildFailure is a group whose tests could not run because its build failed
+type buildFailure struct {
+	group   string
+	message string // Compiler output
+}
+
+// buildFailureDetails describes failed builds for the run's error message, starting with
+// "Build failed" and followed by each group's compiler output
+func buildFailureDetails(failures []buildFailure) string {
+	var sb strings.Builder
+	if len(failures) == 1 {
+		fmt.Fprintf(&sb, "Build failed: %s", failures[0].group)
+	} else {
+		fmt.Fprintf(&sb, "Build failed in %d packages", len(failures))
+	}
+	for _, failure := range failures {
+		if len(failures) > 1 {
+			fmt.Fprintf(&sb, "\n\n%s:", failure.group)
+		}
+		if failure.message != "" {
+			sb.WriteString("\n" + failure.message)
+		}
+	}
+	return sb.String()
+}
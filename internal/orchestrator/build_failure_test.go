@@ -0,0 +1,18 @@
+package orchestrator
+
+import "testing"
+
+func TestBuildFailureDetails(t *testing.T) {
+	single := buildFailureDetails([]buildFailure{{group: "example.com/shop", message: "cart_test.go:5:30: undefined: checkout"}})
+	if want := "Build failed: example.com/shop\ncart_test.go:5:30: undefined: checkout"; single != want {
+		t.Errorf("buildFailureDetails() = %q, want %q", single, want)
+	}
+
+	several := buildFailureDetails([]buildFailure{
+		{group: "example.com/shop", message: "cart_test.go:5:30: undefined: checkout"},
+		{group: "example.com/util"},
+	})
+	if want := "Build failed in 2 packages\n\nexample.com/shop:\ncart_test.go:5:30: undefined: checkout\n\nexample.com/util:"; several != want {
+		t.Errorf("buildFailureDetails() = %q, want %q", several, want)
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+)
+
+// ANSI escape sequences used for console status output
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
+
+// colorEnabled decides whether console output is colored for a --color mode.
+// "auto" (or "") colors only when out is a terminal, NO_COLOR is unset or empty and
+// TERM is not "dumb", so piped output and CI logs stay free of escape sequences.
+func colorEnabled(mode string, out *os.File) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps text in an ANSI color when console color is enabled
+func (o *Orchestrator) paint(text, color string) string {
+	if !o.color {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// passedCount formats the passed count of the Results line, green unless nothing passed
+func (o *Orchestrator) passedCount(passed int) string {
+	text := fmt.Sprintf("%d passed", passed)
+	if passed == 0 {
+		return text
+	}
+	return o.paint(text, ansiGreen)
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"os"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer func() {
+		_ = reader.Close()
+		_ = writer.Close()
+	}()
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	testCases := []struct {
+		mode    string
+		noColor string
+		want    bool
+	}{
+		{"always", "", true},
+		{"always", "1", true},
+		{"never", "", false},
+		// A pipe is not a terminal, so auto stays plain
+		{"auto", "", false},
+		{"", "", false},
+		{"auto", "1", false},
+	}
+	for _, tc := range testCases {
+		t.Setenv("NO_COLOR", tc.noColor)
+		if got := colorEnabled(tc.mode, writer); got != tc.want {
+			t.Errorf("colorEnabled(%q) with NO_COLOR=%q = %v, want %v", tc.mode, tc.noColor, got, tc.want)
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	plain := &Orchestrator{}
+	if got := plain.paint("FAIL(1)", ansiRed); got != "FAIL(1)" {
+		t.Errorf("Expected uncolored text, got %q", got)
+	}
+	if got := plain.passedCount(3); got != "3 passed" {
+		t.Errorf("Expected uncolored passed count, got %q", got)
+	}
+
+	colored := &Orchestrator{color: true}
+	if got := colored.paint("FAIL(1)", ansiRed); got != "\x1b[31mFAIL(1)\x1b[0m" {
+		t.Errorf("Expected red text, got %q", got)
+	}
+	if got := colored.passedCount(3); got != "\x1b[32m3 passed\x1b[0m" {
+		t.Errorf("Expected green passed count, got %q", got)
+	}
+	if got := colored.passedCount(0); got != "0 passed" {
+		t.Errorf("Expected no color when nothing passed, got %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// crashedBeforeResults reports whether an attempt died abnormally before reporting any
+// results: killed by a signal from outside 3pio or exiting with a signal's code above 128
+// (as shells and npm report a crashed child), without a single group or test result.
+// Commands 3pio stopped itself and watch mode are never retried.
+func (o *Orchestrator) crashedBeforeResults(result attemptResult) bool {
+	if result.killed || o.interrupted || o.watch != nil {
+		return false
+	}
+	if o.totalGroups > 0 || o.totalTests > 0 {
+		return false
+	}
+	return o.exitSignal != "" || o.exitCode > 128
+}
+
+// prepareRetry records a crashed attempt and resets the run for the next one: the
+// attempt's output.log (or output.log.gz), stderr.log and ipc.jsonl are kept under attempt-numbered names,
+// its group reports are removed, and the report and IPC managers start over
+func (o *Orchestrator) prepareRetry(attempt int, parser runner.OutputParser, runnerDef runner.Definition, modifiedCommand, args string) error {
+	crashed := ipc.CrashedAttempt{Attempt: attempt, ExitCode: o.exitCode, Signal: o.exitSignal}
+	how := fmt.Sprintf("exit code %d", o.exitCode)
+	if o.exitSignal != "" {
+		how = o.exitSignal
+	}
+	o.logger.Info("Test command crashed before reporting any results (%s), retrying (--retry-on-crash)", how)
+	if !o.quiet {
+		fmt.Printf("Test command crashed before reporting any results (%s), running it again (attempt %d of %d)\n\n", how, attempt+1, o.retryOnCrash+1)
+	}
+
+	// The crashed attempt's report is replaced; finalizing it stops its pending writes
+	if err := o.reportManager.Finalize(o.exitCode); err != nil {
+		o.logger.Debug("Failed to finalize report of crashed attempt %d: %v", attempt, err)
+	}
+	// With --compress-output, finalizing has already replaced output.log with output.log.gz
+	for _, name := range []string{"output.log", "output.log.gz", "stderr.log"} {
+		kept := strings.Replace(name, ".log", fmt.Sprintf(".attempt-%d.log", attempt), 1)
+		if err := os.Rename(filepath.Join(o.runDir, name), filepath.Join(o.runDir, kept)); err == nil && strings.HasPrefix(name, "output") {
+			crashed.OutputLog = kept
+		}
+	}
+	if err := os.Rename(o.ipcPath, filepath.Join(o.runDir, fmt.Sprintf("ipc.attempt-%d.jsonl", attempt))); err != nil {
+		o.logger.Debug("Failed to keep ipc.jsonl of crashed attempt %d: %v", attempt, err)
+	}
+	if err := os.RemoveAll(filepath.Join(o.runDir, "reports")); err != nil {
+		o.logger.Debug("Failed to remove group reports of crashed attempt %d: %v", attempt, err)
+	}
+	o.crashedAttempts = append(o.crashedAttempts, crashed)
+
+	o.resetRunState()
+	o.exitCode = 0
+	o.exitSignal = ""
+	o.stderrCapture.Reset()
+
+	manager, err := o.newReportManager(o.runDir, parser, runnerDef, modifiedCommand)
+	if err != nil {
+		return err
+	}
+	if err := manager.Initialize(args); err != nil {
+		return fmt.Errorf("failed to initialize report: %w", err)
+	}
+	manager.SetCrashedAttempts(o.crashedAttempts)
+	o.reportMu.Lock()
+	o.reportManager = manager
+	o.reportMu.Unlock()
+
+	o.ipcManager, err = o.newIPCManager(runnerDef)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC manager: %w", err)
+	}
+	if err := o.ipcManager.WatchEvents(); err != nil {
+		return fmt.Errorf("failed to start IPC watcher: %w", err)
+	}
+	return nil
+}
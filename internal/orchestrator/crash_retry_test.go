@@ -0,0 +1,162 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestOrchestrator_RetryOnCrash(t *testing.T) {
+	// The fake go binary segfaults the first time it runs the tests and passes the second
+	marker := filepath.Join(t.TempDir(), "crashed")
+	installFakeGo(t, fmt.Sprintf(`#!/bin/sh
+if [ "$1" = test ] && [ ! -e %[1]q ]; then
+  touch %[1]q
+  echo 'runtime: out of luck'
+  kill -SEGV $$
+fi
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+`, marker))
+
+	orch, err := New(Config{
+		Command:      []string{"go", "test", "./..."},
+		Logger:       logger.NewTestLogger(),
+		OutputDir:    filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:        true,
+		RetryOnCrash: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Expected the retried run to pass, got %v", err)
+	}
+	if orch.GetExitCode() != 0 {
+		t.Errorf("Expected exit code 0, got %d", orch.GetExitCode())
+	}
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary struct {
+		Signal          string `json:"signal"`
+		CrashedAttempts []struct {
+			Attempt   int    `json:"attempt"`
+			Signal    string `json:"signal"`
+			OutputLog string `json:"outputLog"`
+		} `json:"crashedAttempts"`
+		Counts struct {
+			Passed int `json:"passed"`
+		} `json:"counts"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v", err)
+	}
+	if len(summary.CrashedAttempts) != 1 || summary.CrashedAttempts[0].Signal != "SIGSEGV" || summary.CrashedAttempts[0].OutputLog != "output.attempt-1.log" {
+		t.Errorf("Expected the segfaulted first attempt to be recorded, got %+v", summary.CrashedAttempts)
+	}
+	if summary.Signal != "" || summary.Counts.Passed != 1 {
+		t.Errorf("Expected the results of the second attempt, got %+v", summary)
+	}
+
+	crashedOutput, err := os.ReadFile(filepath.Join(orch.runDir, "output.attempt-1.log"))
+	if err != nil || !strings.Contains(string(crashedOutput), "out of luck") {
+		t.Errorf("Expected the crashed attempt's output to be kept, got %q (%v)", crashedOutput, err)
+	}
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "- Attempt 1: killed by SIGSEGV") {
+		t.Errorf("Expected test-run.md to list the crashed attempt:\n%s", report)
+	}
+}
+
+func TestOrchestrator_RetryOnCrashGivesUp(t *testing.T) {
+	installFakeGo(t, "#!/bin/sh\nkill -SEGV $$\n")
+
+	orch, err := New(Config{
+		Command:      []string{"go", "test", "./..."},
+		Logger:       logger.NewTestLogger(),
+		OutputDir:    filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:        true,
+		RetryOnCrash: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+	if orch.GetExitCode() != 128+11 {
+		t.Errorf("Expected the last attempt's exit code %d, got %d", 128+11, orch.GetExitCode())
+	}
+	if len(orch.crashedAttempts) != 2 {
+		t.Errorf("Expected 2 crashed attempts before the last one, got %+v", orch.crashedAttempts)
+	}
+	for _, name := range []string{"output.attempt-1.log", "output.attempt-2.log", "ipc.attempt-2.jsonl", "output.log"} {
+		if _, err := os.Stat(filepath.Join(orch.runDir, name)); err != nil {
+			t.Errorf("Expected %s in the run directory: %v", name, err)
+		}
+	}
+}
+
+func TestOrchestrator_RetryOnCrashCompressed(t *testing.T) {
+	// Finalizing the crashed attempt gzips its output.log, which is kept under the attempt's name
+	marker := filepath.Join(t.TempDir(), "crashed")
+	installFakeGo(t, fmt.Sprintf(`#!/bin/sh
+if [ "$1" = test ] && [ ! -e %[1]q ]; then
+  touch %[1]q
+  echo 'runtime: out of luck'
+  kill -SEGV $$
+fi
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+`, marker))
+
+	orch, err := New(Config{
+		Command:        []string{"go", "test", "./..."},
+		Logger:         logger.NewTestLogger(),
+		OutputDir:      filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:          true,
+		RetryOnCrash:   1,
+		CompressOutput: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Expected the retried run to pass, got %v", err)
+	}
+	if len(orch.crashedAttempts) != 1 || orch.crashedAttempts[0].OutputLog != "output.attempt-1.log.gz" {
+		t.Errorf("Expected the crashed attempt's output at output.attempt-1.log.gz, got %+v", orch.crashedAttempts)
+	}
+
+	f, err := os.Open(filepath.Join(orch.runDir, "output.attempt-1.log.gz"))
+	if err != nil {
+		t.Fatalf("Expected output.attempt-1.log.gz: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("output.attempt-1.log.gz is not gzipped: %v", err)
+	}
+	crashedOutput, err := io.ReadAll(zr)
+	if err != nil || !strings.Contains(string(crashedOutput), "out of luck") {
+		t.Errorf("Expected the crashed attempt's output in output.attempt-1.log.gz, got %q (%v)", crashedOutput, err)
+	}
+	if _, err := os.Stat(filepath.Join(orch.runDir, "output.log.gz")); err != nil {
+		t.Errorf("Expected the last attempt's output.log.gz: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/logger"
 	"github.com/zk/3pio/internal/report"
 )
@@ -157,3 +158,73 @@ func TestDisplayGroupWithoutFailures(t *testing.T) {
 
 // TestFormatElapsedTime tests the elapsed time formatting
 // Removed elapsed time prefix from output; no longer testing formatElapsedTime
+
+// TestDisplayGroupQuiet tests that quiet mode suppresses per-group lines
+func TestDisplayGroupQuiet(t *testing.T) {
+	// Capture stdout
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	o := &Orchestrator{
+		runID:        "20250917T120000-test-run",
+		startTime:    time.Now(),
+		logger:       logger.NewTestLogger(),
+		noTestGroups: make(map[string]bool),
+		quiet:        true,
+	}
+
+	group := &report.TestGroup{
+		Name:        "math.test.js",
+		Stats:       report.TestGroupStats{TotalTests: 1, FailedTests: 1},
+		ParentNames: []string{},
+		TestCases: []report.TestCase{
+			{Name: "adds numbers", Status: report.TestStatusFail},
+		},
+	}
+
+	o.displayGroupHierarchy(group, 0, 100.0)
+
+	// Restore stdout and read captured output
+	_ = w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output in quiet mode, got: %s", buf.String())
+	}
+}
+
+// TestRetriedTestsCountOnce checks that the console counts a retried test by its last attempt
+func TestRetriedTestsCountOnce(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger()
+	o := &Orchestrator{
+		logger:           testLogger,
+		groupFailedTests: make(map[string][]string),
+		testOutcomes:     make(map[string]*testOutcome),
+	}
+
+	send := func(name, status string) {
+		o.handleConsoleOutput(ipc.GroupTestCaseEvent{
+			EventType: string(ipc.EventTypeTestCase),
+			Payload:   ipc.TestCasePayload{TestName: name, ParentNames: []string{"/work/login.spec.ts"}, Status: status},
+		})
+	}
+	send("flaky", "FAIL")
+	send("flaky", "PASS")
+	send("broken", "FAIL")
+	send("broken", "FAIL")
+	send("stable", "PASS")
+
+	if o.totalTests != 3 || o.passedTests != 2 || o.failedTests != 1 {
+		t.Errorf("Counts = %d total, %d passed, %d failed; want 3, 2, 1", o.totalTests, o.passedTests, o.failedTests)
+	}
+	if flaky := o.flakyTests(); flaky != 1 {
+		t.Errorf("flakyTests() = %d, want 1", flaky)
+	}
+	if failed := o.groupFailedTests["/work/login.spec.ts"]; len(failed) != 1 || failed[0] != "broken" {
+		t.Errorf("Failed tests = %v, want [broken]", failed)
+	}
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/zk/3pio/internal/adapters"
+)
+
+// DryRun describes the command a run would execute (--dry-run)
+type DryRun struct {
+	Runner      string   // Runner name as recorded in reports, e.g. "jest" or "go test"
+	RunDir      string   // Run directory the run would create
+	AdapterPath string   // Where the adapter would be extracted, "" for native runners
+	Command     []string // Command after BuildCommand, as it would be executed
+	Env         []string // Variables 3pio would set for the command, as NAME=value
+}
+
+// DryRun goes through Run's setup up to starting the test command: it detects the runner,
+// applies test selection, resolves the adapter path and builds the command. Nothing is
+// written: no run directory is created and no adapter is extracted.
+func (o *Orchestrator) DryRun() (DryRun, error) {
+	o.newRunDir()
+	runnerDef, err := o.detectRunnerDefinition()
+	if err != nil {
+		return DryRun{}, err
+	}
+	if err := o.selectTests(runnerDef); err != nil {
+		return DryRun{}, err
+	}
+	o.enableGinkgo(runnerDef)
+
+	dryRun := DryRun{Runner: runnerName(runnerDef), RunDir: o.runDir}
+	// Some definitions' BuildCommand set variables in 3pio's own environment (PYTHONPATH
+	// for pytest), so the additions are taken against the environment from before it
+	environ := os.Environ()
+	if adapterFile := runnerDef.GetAdapterFileName(); adapterFile != "" {
+		dryRun.AdapterPath, err = adapters.AdapterPath(adapterFile, o.runDir)
+		if err != nil {
+			return DryRun{}, fmt.Errorf("failed to resolve adapter path: %w", err)
+		}
+		o.setVitestJSONReport(runnerDef)
+	}
+	dryRun.Command = runnerDef.BuildCommand(o.command, dryRun.AdapterPath)
+	if err := o.verifyCommand(dryRun.Command); err != nil {
+		return DryRun{}, err
+	}
+
+	for _, entry := range o.commandEnv(runnerDef, nativeDefinition(runnerDef), o.ipcPath) {
+		if !slices.Contains(environ, entry) {
+			dryRun.Env = append(dryRun.Env, entry)
+		}
+	}
+	return dryRun, nil
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestDryRun_NativeRunner(t *testing.T) {
+	outputDir := t.TempDir()
+	orch, err := New(Config{Command: []string{"cargo", "test"}, OutputDir: outputDir, Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	dryRun, err := orch.DryRun()
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if dryRun.Runner != "cargo test" || dryRun.AdapterPath != "" {
+		t.Errorf("Expected cargo test without an adapter, got %s with %q", dryRun.Runner, dryRun.AdapterPath)
+	}
+	if command := strings.Join(dryRun.Command, " "); !strings.Contains(command, "--format json") {
+		t.Errorf("Expected the built command, got %s", command)
+	}
+	wantEnv := []string{"THREEPIO_IPC_PATH=" + filepath.Join(dryRun.RunDir, "ipc.jsonl"), "RUSTC_BOOTSTRAP=1"}
+	if !slices.Equal(dryRun.Env, wantEnv) {
+		t.Errorf("Expected env additions %v, got %v", wantEnv, dryRun.Env)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "runs")); !os.IsNotExist(err) {
+		t.Errorf("Expected no run directory to be created, got %v", err)
+	}
+}
+
+func TestDryRun_AdapterRunner(t *testing.T) {
+	orch, err := New(Config{Command: []string{"npx", "jest", "src"}, OutputDir: t.TempDir(), Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	dryRun, err := orch.DryRun()
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if !filepath.IsAbs(dryRun.AdapterPath) || !strings.Contains(dryRun.AdapterPath, filepath.Join(dryRun.RunDir, "adapters")) {
+		t.Errorf("Expected the adapter path under %s, got %s", dryRun.RunDir, dryRun.AdapterPath)
+	}
+	if !slices.Contains(dryRun.Command, dryRun.AdapterPath) {
+		t.Errorf("Expected the adapter path in the command, got %v", dryRun.Command)
+	}
+	if _, err := os.Stat(dryRun.AdapterPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the adapter not to be extracted, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner"
+)
+
+// alwaysKeptEnv lists the variables passed to the test command even when they are not in
+// --env-allowlist, since without PATH the runner's own tools (node, python, go) can't be found
+var alwaysKeptEnv = []string{"PATH"}
+
+// baseEnv returns the environment the test command starts from, before 3pio adds its own
+// variables. With --env-allowlist only the allowlisted variables, alwaysKeptEnv and the
+// ones the runner's adapter needs are passed on; otherwise 3pio's whole environment is.
+func (o *Orchestrator) baseEnv(runnerDef runner.Definition) []string {
+	environ := os.Environ()
+	if o.envAllowlist == nil {
+		return environ
+	}
+
+	keep := append(append([]string{}, o.envAllowlist...), alwaysKeptEnv...)
+	// BuildCommand puts the pytest adapter's directory on PYTHONPATH, and under tox loads
+	// the adapter through PYTEST_ADDOPTS
+	if runnerDef.GetAdapterFileName() == "pytest_adapter.py" {
+		keep = append(keep, "PYTHONPATH", "PYTEST_ADDOPTS", runner.ToxEnvGroupsVar)
+	}
+	// BuildCommand has the Jest and Vitest adapters group a workspace run by package
+	if adapter := runnerDef.GetAdapterFileName(); adapter == "jest.js" || adapter == "vitest.js" {
+		keep = append(keep, runner.WorkspaceGroupsVar)
+	}
+	env := filterEnv(environ, keep)
+	o.logger.Debug("Passing %d of %d environment variables to the test command (--env-allowlist)", len(env), len(environ))
+	return env
+}
+
+// filterEnv returns the "NAME=value" entries of environ whose name is in names. Names
+// are case-insensitive on Windows, like the environment itself.
+func filterEnv(environ []string, names []string) []string {
+	var env []string
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		for _, keep := range names {
+			if name == keep || (runtime.GOOS == "windows" && strings.EqualFold(name, keep)) {
+				env = append(env, entry)
+				break
+			}
+		}
+	}
+	return env
+}
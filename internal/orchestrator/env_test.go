@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/logger"
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestFilterEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "HOME=/home/dev", "CI=true", "AWS_SECRET=shh", "CIRCLECI=true"}
+	got := filterEnv(environ, []string{"CI", "PATH"})
+	want := []string{"PATH=/usr/bin", "CI=true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestBaseEnv_Allowlist(t *testing.T) {
+	t.Setenv("THREEPIO_TEST_KEPT", "yes")
+	t.Setenv("THREEPIO_TEST_DROPPED", "no")
+
+	orch, err := New(Config{
+		Command:      []string{"go", "test", "./..."},
+		Logger:       logger.NewTestLogger(),
+		EnvAllowlist: []string{"THREEPIO_TEST_KEPT"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	runnerDef, err := orch.detectRunnerDefinition()
+	if err != nil {
+		t.Fatalf("Failed to detect runner: %v", err)
+	}
+	env := orch.baseEnv(runnerDef)
+
+	want := []string{"THREEPIO_TEST_KEPT=yes"}
+	if path, ok := os.LookupEnv("PATH"); ok {
+		want = append(want, "PATH="+path)
+	}
+	if len(env) != len(want) {
+		t.Fatalf("Expected only %v, got %v", want, env)
+	}
+	for _, entry := range want {
+		found := false
+		for _, e := range env {
+			found = found || e == entry
+		}
+		if !found {
+			t.Errorf("Expected %s in %v", entry, env)
+		}
+	}
+
+	// The pytest adapter is found through PYTHONPATH, so it is kept for pytest
+	t.Setenv("PYTHONPATH", "/adapters")
+	if env := orch.baseEnv(runner.NewPytestDefinition()); len(filterEnv(env, []string{"PYTHONPATH"})) != 1 {
+		t.Errorf("Expected PYTHONPATH to be kept for pytest, got %v", env)
+	}
+
+	// A workspace run tells the Jest adapter to group results by package
+	t.Setenv(runner.WorkspaceGroupsVar, "1")
+	if env := orch.baseEnv(runner.NewJestDefinition()); len(filterEnv(env, []string{runner.WorkspaceGroupsVar})) != 1 {
+		t.Errorf("Expected %s to be kept for Jest, got %v", runner.WorkspaceGroupsVar, env)
+	}
+}
+
+func TestRunnerIPCAddress(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The runner may start in a workspace package's directory, so a relative file is resolved here
+	if got, want := runnerIPCAddress(".3pio/runs/x/ipc.jsonl"), filepath.Join(cwd, ".3pio/runs/x/ipc.jsonl"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got := runnerIPCAddress(ipc.SocketScheme + "/tmp/ipc.sock"); got != ipc.SocketScheme+"/tmp/ipc.sock" {
+		t.Errorf("Expected a socket address to be kept, got %s", got)
+	}
+}
+
+func TestBaseEnv_NoAllowlist(t *testing.T) {
+	orch, err := New(Config{Command: []string{"go", "test"}, Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	if env := orch.baseEnv(runner.NewPytestDefinition()); len(env) != len(os.Environ()) {
+		t.Errorf("Expected the whole environment without an allowlist, got %d of %d variables", len(env), len(os.Environ()))
+	}
+}
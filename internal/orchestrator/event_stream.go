@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/report"
+)
+
+// eventStream mirrors IPC events as JSON lines for tools that follow a run live
+// (--event-stream). Unlike ipc.jsonl, each line has the group's resolved ID, full path
+// and status as the report sees them after the event.
+type eventStream struct {
+	out    io.Writer
+	closer io.Closer // nil when writing to stdout
+}
+
+// openEventStream opens the --event-stream sink: "-" for stdout, otherwise a file path,
+// which is truncated unless appendTo is set
+func openEventStream(path string, appendTo bool) (*eventStream, error) {
+	if path == "-" {
+		return &eventStream{out: os.Stdout}, nil
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event stream: %w", err)
+	}
+	return &eventStream{out: file, closer: file}, nil
+}
+
+// Close closes the sink unless it is stdout
+func (s *eventStream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// streamEvent writes event to the event stream, if there is one. It must be called after
+// the report manager has handled the event so the group status is up to date.
+func (o *Orchestrator) streamEvent(event ipc.Event) {
+	if o.eventStream == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		o.logger.Debug("Failed to encode event for the event stream: %v", err)
+		return
+	}
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(data, &record); err != nil {
+		o.logger.Debug("Failed to encode event for the event stream: %v", err)
+		return
+	}
+	record["eventType"], _ = json.Marshal(event.Type())
+	record["time"], _ = json.Marshal(time.Now().UTC().Format(time.RFC3339Nano))
+
+	if path := eventGroupPath(event); len(path) > 0 {
+		for i, name := range path {
+			path[i] = o.normalizePathForReportManager(name)
+		}
+		groupID := report.GenerateGroupID(path[len(path)-1], path[:len(path)-1])
+		record["groupId"], _ = json.Marshal(groupID)
+		record["groupPath"], _ = json.Marshal(path)
+		if group, ok := o.reportManager.GetGroup(groupID); ok && group.Status != "" {
+			record["groupStatus"], _ = json.Marshal(group.Status)
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		o.logger.Debug("Failed to encode event for the event stream: %v", err)
+		return
+	}
+	if _, err := o.eventStream.out.Write(append(line, '\n')); err != nil {
+		o.logger.Error("Failed to write to the event stream, no longer writing it: %v", err)
+		o.eventStream = nil
+	}
+}
+
+// eventGroupPath returns the full path of the group an event belongs to, from the root
+// group down, or nil for events without a group. A test case belongs to its parent group.
+func eventGroupPath(event ipc.Event) []string {
+	var name string
+	var parents []string
+	switch e := event.(type) {
+	case ipc.GroupDiscoveredEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupStartEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupResultEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupErrorEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupStdoutChunkEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupStderrChunkEvent:
+		name, parents = e.Payload.GroupName, e.Payload.ParentNames
+	case ipc.GroupTestCaseEvent:
+		return append([]string{}, e.Payload.ParentNames...)
+	}
+	if name == "" {
+		return nil
+	}
+	return append(append([]string{}, parents...), name)
+}
@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/report"
+)
+
+func TestStreamEvent(t *testing.T) {
+	manager, err := report.NewManager(t.TempDir(), nil, discardLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create report manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	o := &Orchestrator{logger: discardLogger{}, reportManager: manager, eventStream: &eventStream{out: &out}}
+
+	events := []ipc.Event{
+		ipc.NewGroupTestCaseEvent("adds", []string{"math.test.js", "Calculator"}, "FAIL"),
+		ipc.RunCompleteEvent{EventType: ipc.EventTypeRunComplete},
+	}
+	for _, event := range events {
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+		o.streamEvent(event)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(events) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(events), len(lines), out.String())
+	}
+
+	var testCase struct {
+		EventType   string   `json:"eventType"`
+		Time        string   `json:"time"`
+		GroupID     string   `json:"groupId"`
+		GroupPath   []string `json:"groupPath"`
+		GroupStatus string   `json:"groupStatus"`
+		Payload     struct {
+			TestName string `json:"testName"`
+			Status   string `json:"status"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &testCase); err != nil {
+		t.Fatalf("Invalid JSON line %q: %v", lines[0], err)
+	}
+	if testCase.EventType != "testCase" || testCase.Time == "" || testCase.Payload.TestName != "adds" {
+		t.Errorf("Expected the test case event with a timestamp, got %s", lines[0])
+	}
+	if want := report.GenerateGroupID("Calculator", []string{"math.test.js"}); testCase.GroupID != want {
+		t.Errorf("Expected group ID %s, got %s", want, testCase.GroupID)
+	}
+	if len(testCase.GroupPath) != 2 || testCase.GroupPath[1] != "Calculator" {
+		t.Errorf("Expected the parent group's path, got %v", testCase.GroupPath)
+	}
+	if testCase.GroupStatus != "FAIL" {
+		t.Errorf("Expected the group status after the event, got %q", testCase.GroupStatus)
+	}
+
+	if strings.Contains(lines[1], "groupId") || !strings.Contains(lines[1], `"eventType":"runComplete"`) {
+		t.Errorf("Expected a run complete event without a group, got %s", lines[1])
+	}
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestOrchestrator_ExitCodePassthrough(t *testing.T) {
+	// pytest gives 2 to 5 their own meanings (interrupted, internal error, usage error, no
+	// tests); for go test they are just codes. Either way 3pio exits with the runner's code.
+	commands := [][]string{{"go", "test", "./..."}, {"pytest"}}
+	for _, command := range commands {
+		for code := 2; code <= 5; code++ {
+			t.Run(fmt.Sprintf("%s exit %d", command[0], code), func(t *testing.T) {
+				installFakeBinary(t, command[0], fmt.Sprintf("#!/bin/sh\necho 'something went wrong'\nexit %d\n", code))
+				orch, err := New(Config{
+					Command:   command,
+					Logger:    logger.NewTestLogger(),
+					OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+					Quiet:     true,
+				})
+				if err != nil {
+					t.Fatalf("Failed to create orchestrator: %v", err)
+				}
+				_ = orch.Run()
+				if orch.GetExitCode() != code {
+					t.Errorf("Expected exit code %d, got %d", code, orch.GetExitCode())
+				}
+
+				summary := readRunSummary(t, orch.runDir)
+				if summary.ExitCode != code || summary.RunnerExitCode != nil {
+					t.Errorf("Expected run.json to record exit code %d alone, got %d and runner %v", code, summary.ExitCode, summary.RunnerExitCode)
+				}
+			})
+		}
+	}
+}
+
+func TestOrchestrator_ExitCodeOverride(t *testing.T) {
+	// The command passes without reporting results, which 3pio fails on its own account
+	installFakeGo(t, "#!/bin/sh\nexit 0\n")
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+	if orch.GetExitCode() != 1 {
+		t.Fatalf("Expected 3pio to exit 1 without test results, got %d", orch.GetExitCode())
+	}
+
+	summary := readRunSummary(t, orch.runDir)
+	if summary.RunnerExitCode == nil || *summary.RunnerExitCode != 0 || summary.ExitCode != 1 {
+		t.Errorf("Expected run.json to record the runner's 0 next to 3pio's 1, got %d and runner %v", summary.ExitCode, summary.RunnerExitCode)
+	}
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	if !strings.Contains(string(content), "runner_exit_code: 0\n3pio_exit_code: 1\n") {
+		t.Errorf("Expected both exit codes in the frontmatter:\n%s", content)
+	}
+}
@@ -0,0 +1,232 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner"
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// Exit reasons recorded in test-run.md and run.json when the run exits non-zero
+const (
+	ExitReasonTestsFailed  = "tests_failed"
+	ExitReasonSetupError   = "setup_error"
+	ExitReasonBuildFailure = "build_failure"
+	ExitReasonNoTestsRan   = "no_tests_ran"
+	ExitReasonInterrupted  = "interrupted"
+	ExitReasonTimeout      = "timeout"
+	ExitReasonSignal       = "killed_by_signal"
+
+	ExitReasonSkippedNotAllowed = "skipped_not_allowed"
+	ExitReasonNoEventsReceived  = "no_events_received"
+	ExitReasonBelowThreshold    = "below_pass_threshold"
+)
+
+// runOutcome is what the orchestrator knows about a finished test command
+type runOutcome struct {
+	exitCode      int
+	exitMeaning   string // The runner's reading of exitCode (see runner.Definition.InterpretExitCode)
+	interrupted   bool   // Stopped by SIGINT or SIGTERM
+	timedOut      bool   // Stopped by --timeout
+	signal        string // Signal that killed the test command from outside 3pio, e.g. SIGKILL from the OOM killer
+	totalGroups   int
+	passedGroups  int
+	failedGroups  int
+	totalTests    int
+	failedTests   int
+	buildFailures int    // Groups that failed to compile
+	failedOnSkip  bool   // The command exited 0 but skipped tests or groups under --fail-on-skip
+	noEvents      bool   // The command exited 0 but no test results were received
+	belowPassRate bool   // Fewer test cases passed than --fail-under requires
+	passRate      string // How the run measured up to --fail-under ("" when it doesn't apply)
+}
+
+// runOutcome collects the console counts used to explain the exit code
+func (o *Orchestrator) runOutcome(timedOut bool) runOutcome {
+	return runOutcome{
+		exitCode:      o.exitCode,
+		exitMeaning:   o.interpretExitCode(o.exitCode),
+		interrupted:   o.interrupted,
+		timedOut:      timedOut,
+		signal:        o.exitSignal,
+		totalGroups:   o.totalGroups,
+		passedGroups:  o.passedGroups,
+		failedGroups:  o.failedGroups,
+		totalTests:    o.totalTests,
+		failedTests:   o.failedTests,
+		buildFailures: len(o.buildFailures),
+	}
+}
+
+// failOnSkip fails a run that exited 0 but skipped tests or groups when --fail-on-skip
+// is set. Runs that already failed keep their exit code and reason.
+func (o *Orchestrator) failOnSkip(outcome *runOutcome) {
+	if !o.failOnSkipped || outcome.exitCode != 0 || (o.skippedTests == 0 && o.skippedGroups == 0) {
+		return
+	}
+	outcome.exitCode = 1
+	outcome.exitMeaning = o.interpretExitCode(1)
+	outcome.failedOnSkip = true
+	o.logger.Info("%s", o.skipFailureMessage())
+}
+
+// printSkipFailure explains an exit code set by --fail-on-skip, ahead of the results
+func (o *Orchestrator) printSkipFailure(outcome runOutcome) {
+	if outcome.failedOnSkip && !o.quiet {
+		fmt.Printf("Error: %s\n\n", o.skipFailureMessage())
+	}
+}
+
+func (o *Orchestrator) skipFailureMessage() string {
+	return fmt.Sprintf("%d tests and %d groups were skipped, which --fail-on-skip does not allow", o.skippedTests, o.skippedGroups)
+}
+
+// checkNoEvents flags a run whose command exited 0 without a single group being
+// reported, which happens when the adapter never loaded, the wrong runner was detected or
+// the runner never invoked the reporter. Such a run fails unless --allow-no-events is set,
+// and is warned about in the report either way.
+func (o *Orchestrator) checkNoEvents(outcome *runOutcome) {
+	if outcome.exitCode != 0 || len(o.reportManager.GetRootGroups()) > 0 {
+		return
+	}
+	outcome.noEvents = true
+	o.logger.Info("%s", o.noEventsMessage())
+	o.reportManager.AddWarning(o.noEventsMessage())
+	if o.allowNoEvents {
+		return
+	}
+	outcome.exitCode = 1
+	outcome.exitMeaning = o.interpretExitCode(1)
+}
+
+// printNoEvents explains a run flagged by checkNoEvents on stderr, ahead of the results.
+// It is printed even with --quiet, since the results that follow say nothing useful.
+func (o *Orchestrator) printNoEvents(outcome runOutcome) {
+	if !outcome.noEvents {
+		return
+	}
+	label := "Error"
+	if outcome.exitCode == 0 {
+		label = "Warning"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n\n", label, o.noEventsMessage())
+}
+
+func (o *Orchestrator) noEventsMessage() string {
+	return fmt.Sprintf("the test command exited 0 but 3pio received no test results from %s. The adapter may not have loaded, the wrong runner may have been detected, or the runner never invoked the reporter; check what 3pio detects with: 3pio --list-runners %s",
+		o.detectedRunner, definitions.ShellJoin(o.command))
+}
+
+// checkPassRate applies --fail-under: the run passes when at least that percentage of
+// its test cases passed and fails otherwise, whether or not the runner exited non-zero
+// for the failed tests. A run without test cases fails unless --fail-under-allow-empty is
+// set. Runs that stopped for another reason, such as a build failure or a timeout, keep
+// their exit code and reason.
+func (o *Orchestrator) checkPassRate(outcome *runOutcome) {
+	if o.failUnder <= 0 {
+		return
+	}
+	switch outcome.exitReason() {
+	case "", ExitReasonTestsFailed, ExitReasonNoTestsRan:
+	default:
+		return
+	}
+
+	counts := o.reportManager.RunCounts()
+	var met bool
+	if counts.Total == 0 {
+		met = o.failUnderEmpty
+		outcome.passRate = fmt.Sprintf("no test cases ran, which --fail-under %s%% counts as a failure without --fail-under-allow-empty", formatPercent(o.failUnder))
+		if met {
+			outcome.passRate = fmt.Sprintf("no test cases ran, which meets --fail-under %s%% with --fail-under-allow-empty", formatPercent(o.failUnder))
+		}
+	} else {
+		rate := float64(counts.Passed) * 100 / float64(counts.Total)
+		met = rate >= o.failUnder
+		comparison := "is below"
+		if met {
+			comparison = "meets"
+		}
+		outcome.passRate = fmt.Sprintf("%d of %d test cases passed (%s%%), which %s --fail-under %s%%",
+			counts.Passed, counts.Total, formatPercent(rate), comparison, formatPercent(o.failUnder))
+	}
+
+	outcome.exitCode = 0
+	if !met {
+		outcome.exitCode = 1
+	}
+	outcome.exitMeaning = o.interpretExitCode(outcome.exitCode)
+	outcome.belowPassRate = !met
+	o.logger.Info("Pass rate: %s", outcome.passRate)
+}
+
+// printPassRate shows how the run measured up to --fail-under, ahead of the results
+func (o *Orchestrator) printPassRate(outcome runOutcome) {
+	if outcome.passRate == "" || o.quiet {
+		return
+	}
+	label := "Pass rate"
+	if outcome.belowPassRate {
+		label = "Error"
+	}
+	fmt.Printf("%s: %s\n\n", label, outcome.passRate)
+}
+
+// formatPercent formats a percentage with up to two decimals, e.g. 95, 97.5 or 66.67
+func formatPercent(percent float64) string {
+	return strings.TrimSuffix(strings.TrimRight(strconv.FormatFloat(percent, 'f', 2, 64), "0"), ".")
+}
+
+// interpretExitCode asks the detected runner what code means, or returns "" before a
+// runner was detected
+func (o *Orchestrator) interpretExitCode(code int) string {
+	if o.runnerDef == nil {
+		return ""
+	}
+	return o.runnerDef.InterpretExitCode(code)
+}
+
+// isConfigError reports whether the command failed before running tests, judging by
+// how few groups it reported and its exit code
+func (r runOutcome) isConfigError() bool {
+	return r.totalGroups == 0 ||
+		(r.exitCode != 0 && r.exitCode != 1 && r.totalGroups < 2) ||
+		(r.passedGroups == 0 && r.failedGroups == 0 && r.exitCode != 0)
+}
+
+// onlyBuildFailed reports whether nothing ran because every failed group failed to build
+func (r runOutcome) onlyBuildFailed() bool {
+	return r.buildFailures > 0 && r.passedGroups == 0 && r.buildFailures >= r.failedGroups
+}
+
+// exitReason explains a non-zero exit code, or returns "" when the run exited cleanly
+func (r runOutcome) exitReason() string {
+	switch {
+	case r.exitCode == 0:
+		return ""
+	case r.failedOnSkip:
+		return ExitReasonSkippedNotAllowed
+	case r.noEvents:
+		return ExitReasonNoEventsReceived
+	case r.belowPassRate:
+		return ExitReasonBelowThreshold
+	case r.interrupted:
+		return ExitReasonInterrupted
+	case r.timedOut:
+		return ExitReasonTimeout
+	case r.signal != "":
+		return ExitReasonSignal
+	case r.onlyBuildFailed():
+		return ExitReasonBuildFailure
+	case r.failedTests > 0 || (r.failedGroups > 0 && !r.isConfigError()):
+		return ExitReasonTestsFailed
+	case r.exitMeaning == runner.ExitCodeNoTests,
+		r.totalGroups > 0 && r.totalTests == 0 && r.failedGroups == 0:
+		return ExitReasonNoTestsRan
+	default:
+		return ExitReasonSetupError
+	}
+}
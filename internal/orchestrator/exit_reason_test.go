@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestRunOutcomeExitReason(t *testing.T) {
+	tests := []struct {
+		desc    string
+		outcome runOutcome
+		want    string
+	}{
+		{
+			desc:    "clean exit",
+			outcome: runOutcome{exitCode: 0, totalGroups: 3, passedGroups: 3, totalTests: 10},
+			want:    "",
+		},
+		{
+			desc:    "skipped tests under --fail-on-skip",
+			outcome: runOutcome{exitCode: 1, totalGroups: 1, passedGroups: 1, totalTests: 2, failedOnSkip: true},
+			want:    ExitReasonSkippedNotAllowed,
+		},
+		{
+			desc:    "passed without reporting any results",
+			outcome: runOutcome{exitCode: 1, noEvents: true},
+			want:    ExitReasonNoEventsReceived,
+		},
+		{
+			desc:    "pass rate below --fail-under",
+			outcome: runOutcome{exitCode: 1, totalGroups: 3, passedGroups: 2, failedGroups: 1, totalTests: 10, failedTests: 1, belowPassRate: true},
+			want:    ExitReasonBelowThreshold,
+		},
+		{
+			desc:    "failing tests",
+			outcome: runOutcome{exitCode: 1, totalGroups: 3, passedGroups: 2, failedGroups: 1, totalTests: 10, failedTests: 1},
+			want:    ExitReasonTestsFailed,
+		},
+		{
+			desc:    "failing group without test cases",
+			outcome: runOutcome{exitCode: 1, totalGroups: 2, passedGroups: 1, failedGroups: 1, totalTests: 4},
+			want:    ExitReasonTestsFailed,
+		},
+		{
+			desc:    "interrupted",
+			outcome: runOutcome{exitCode: 130, interrupted: true, totalGroups: 2, passedGroups: 1},
+			want:    ExitReasonInterrupted,
+		},
+		{
+			desc:    "timeout",
+			outcome: runOutcome{exitCode: TimeoutExitCode, timedOut: true, totalGroups: 2, passedGroups: 1, failedTests: 1},
+			want:    ExitReasonTimeout,
+		},
+		{
+			desc:    "killed by the OOM killer while tests were failing",
+			outcome: runOutcome{exitCode: 137, signal: "SIGKILL", totalGroups: 2, passedGroups: 1, failedTests: 1},
+			want:    ExitReasonSignal,
+		},
+		{
+			desc:    "every failure is a build failure",
+			outcome: runOutcome{exitCode: 1, totalGroups: 2, failedGroups: 2, buildFailures: 2},
+			want:    ExitReasonBuildFailure,
+		},
+		{
+			desc:    "build failure next to passing packages",
+			outcome: runOutcome{exitCode: 1, totalGroups: 3, passedGroups: 2, failedGroups: 1, buildFailures: 1, totalTests: 5},
+			want:    ExitReasonTestsFailed,
+		},
+		{
+			desc:    "pytest collected nothing",
+			outcome: runOutcome{exitCode: 5, exitMeaning: runner.ExitCodeNoTests},
+			want:    ExitReasonNoTestsRan,
+		},
+		{
+			desc:    "exit code 5 from a runner without that convention",
+			outcome: runOutcome{exitCode: 5, exitMeaning: "failure"},
+			want:    ExitReasonSetupError,
+		},
+		{
+			desc:    "groups without test cases",
+			outcome: runOutcome{exitCode: 1, totalGroups: 2, passedGroups: 2},
+			want:    ExitReasonNoTestsRan,
+		},
+		{
+			desc:    "command failed before reporting anything",
+			outcome: runOutcome{exitCode: 1},
+			want:    ExitReasonSetupError,
+		},
+		{
+			desc:    "single group with an unusual exit code",
+			outcome: runOutcome{exitCode: 2, totalGroups: 1, failedGroups: 1},
+			want:    ExitReasonSetupError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.outcome.exitReason(); got != tt.want {
+				t.Errorf("exitReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// exitSignal returns the name and number of the signal that terminated the process,
+// such as SIGKILL from the OOM killer, or "" and 0 when it exited normally
+func exitSignal(state *os.ProcessState) (string, int) {
+	if state == nil {
+		return "", 0
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return "", 0
+	}
+	sig := status.Signal()
+	name := unix.SignalName(sig)
+	if name == "" {
+		name = sig.String()
+	}
+	return name, int(sig)
+}
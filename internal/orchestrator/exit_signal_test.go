@@ -0,0 +1,28 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestExitSignal(t *testing.T) {
+	err := exec.Command("sh", "-c", "kill -KILL $$").Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected the shell to be killed, got %v", err)
+	}
+	if name, number := exitSignal(exitErr.ProcessState); name != "SIGKILL" || number != 9 {
+		t.Errorf("Expected SIGKILL (9), got %q (%d)", name, number)
+	}
+
+	err = exec.Command("sh", "-c", "exit 3").Run()
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected exit code 3, got %v", err)
+	}
+	if name, _ := exitSignal(exitErr.ProcessState); name != "" {
+		t.Errorf("Expected no signal for a normal exit, got %q", name)
+	}
+}
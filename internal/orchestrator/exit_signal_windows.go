@@ -0,0 +1,8 @@
+package orchestrator
+
+import "os"
+
+// exitSignal always returns "" and 0: Windows processes are not terminated by signals
+func exitSignal(state *os.ProcessState) (string, int) {
+	return "", 0
+}
@@ -121,3 +121,37 @@ func TestAdapterExtraction_FileLocations(t *testing.T) {
 		})
 	}
 }
+
+func TestOrchestrator_OutputDir(t *testing.T) {
+	tempDir := t.TempDir()
+	oldCwd, _ := os.Getwd()
+	_ = os.Chdir(tempDir)
+	defer func() { _ = os.Chdir(oldCwd) }()
+
+	outputDir := filepath.Join("artifacts", "3pio")
+	config := Config{
+		Command:   []string{"echo", "test"},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: outputDir,
+	}
+
+	orch, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() {
+		_ = orch.Close()
+	}()
+
+	// Runner detection fails for echo, but paths are initialized first
+	_ = orch.Run()
+
+	expectedRunDir := filepath.Join(outputDir, "runs", orch.runID)
+	if orch.runDir != expectedRunDir {
+		t.Errorf("Run dir should be %s, got: %s", expectedRunDir, orch.runDir)
+	}
+	expectedIPCPath := filepath.Join(expectedRunDir, "ipc.jsonl")
+	if orch.ipcPath != expectedIPCPath {
+		t.Errorf("IPC path should be %s, got: %s", expectedIPCPath, orch.ipcPath)
+	}
+}
@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ingestCommands maps the formats --ingest reads to the command that produces them. The
+// command is never run: it picks the runner whose parser reads the format and is shown
+// in the report.
+var ingestCommands = map[string][]string{
+	"go-json": {"go", "test", "-json"},
+}
+
+// IngestFormats returns the formats --ingest reads, sorted
+func IngestFormats() []string {
+	formats := make([]string, 0, len(ingestCommands))
+	for format := range ingestCommands {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// Ingest builds a run from test results read from input instead of running a command
+// (--ingest), e.g. a go test -json stream saved by CI. The results go through the same
+// parser as a live run, and the input is kept as the run's output.log. The exit code
+// reflects the results: 1 when a test or group failed, 0 otherwise.
+func (o *Orchestrator) Ingest(format string, input io.Reader) error {
+	defer func() {
+		_ = o.Close()
+	}()
+
+	command, ok := ingestCommands[format]
+	if !ok {
+		o.exitCode = 1
+		return fmt.Errorf("unsupported ingest format %q, expected one of %s", format, strings.Join(IngestFormats(), ", "))
+	}
+	o.command = command
+	o.newRunDir()
+	testCommand := fmt.Sprintf("%s (ingested from stdin)", strings.Join(command, " "))
+	o.printRunHeader(testCommand, fmt.Sprintf("Reading %s results, no output until test results.", format))
+
+	runnerDef, err := o.detectRunnerDefinition()
+	if err != nil {
+		o.exitCode = 1
+		return err
+	}
+	o.runnerDef = runnerDef
+	o.detectedRunner = runnerName(runnerDef)
+	nativeDef, ok := nativeDefinition(runnerDef).(interface {
+		ProcessOutput(io.Reader, string) error
+	})
+	if !ok {
+		o.exitCode = 1
+		return fmt.Errorf("the %s runner cannot read results from stdin", o.detectedRunner)
+	}
+
+	o.ipcManager, err = o.newIPCManager(runnerDef)
+	if err != nil {
+		o.exitCode = 1
+		return fmt.Errorf("failed to create IPC manager: %w", err)
+	}
+	if err := updateLatestLink(filepath.Dir(o.runDir), o.runID); err != nil {
+		o.logger.Debug("Failed to update latest run link: %v", err)
+	}
+	if err := o.ipcManager.WatchEvents(); err != nil {
+		o.exitCode = 1
+		return fmt.Errorf("failed to start IPC watcher: %w", err)
+	}
+
+	parser := o.runnerManager.GetParser(runnerDef.GetAdapterFileName())
+	o.reportManager, err = o.newReportManager(o.runDir, parser, runnerDef, testCommand)
+	if err != nil {
+		o.exitCode = 1
+		return err
+	}
+	defer func() {
+		if o.reportManager != nil {
+			_ = o.reportManager.Finalize(o.exitCode, "")
+		}
+	}()
+	if err := o.reportManager.Initialize(testCommand); err != nil {
+		o.exitCode = 1
+		return fmt.Errorf("failed to initialize report: %w", err)
+	}
+	if err := pruneOldRuns(filepath.Dir(o.runDir), o.runID, o.keepRuns, o.logger); err != nil {
+		o.logger.Debug("Failed to prune old runs: %v", err)
+	}
+
+	// The input is saved as output.log while the parser reads it
+	if !o.noOutputLog {
+		outputFile, err := os.Create(filepath.Join(o.runDir, "output.log"))
+		if err != nil {
+			o.exitCode = 1
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = outputFile.Close() }()
+		input = io.TeeReader(input, outputFile)
+	}
+
+	o.startTime = time.Now()
+	eventsDone := make(chan struct{})
+	go func() {
+		defer close(eventsDone)
+		o.processEvents()
+	}()
+
+	o.logger.Debug("Reading %s results from stdin", format)
+	processErr := nativeDef.ProcessOutput(input, o.ipcManager.Address())
+	_ = o.ipcManager.Cleanup()
+	o.checkIPCLines()
+	o.checkIPCSchema()
+	<-eventsDone
+	if processErr != nil {
+		o.exitCode = 1
+		return fmt.Errorf("failed to read %s results: %w", format, processErr)
+	}
+
+	if o.totalGroups == 0 {
+		o.computeStatsFromReportManager()
+		o.displayFinalResults()
+	}
+
+	outcome := o.runOutcome(false)
+	if o.failedGroups > 0 || o.failedTests > 0 {
+		outcome.exitCode = 1
+	}
+	outcome.exitMeaning = o.interpretExitCode(outcome.exitCode)
+	o.failOnSkip(&outcome)
+	o.checkNoEvents(&outcome)
+	o.checkPassRate(&outcome)
+	o.exitCode = outcome.exitCode
+	o.reportManager.SetExitReason(outcome.exitReason())
+	if err := o.reportManager.Finalize(o.exitCode); err != nil {
+		o.logger.Error("Failed to finalize report: %v", err)
+	}
+
+	if o.stream != nil {
+		o.stream.flush()
+	}
+	if !o.quiet {
+		fmt.Println()
+	}
+	o.printSkipFailure(outcome)
+	o.printNoEvents(outcome)
+	o.printPassRate(outcome)
+	o.printResults()
+	o.printFailedTests()
+	o.printResultLine(o.exitCode)
+	return nil
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// ingestedGoJSON is a go test -json stream with a passing and a failing test
+const ingestedGoJSON = `{"Action":"start","Package":"example.com/shop"}
+{"Action":"run","Package":"example.com/shop","Test":"TestCart"}
+{"Action":"pass","Package":"example.com/shop","Test":"TestCart","Elapsed":0.01}
+{"Action":"run","Package":"example.com/shop","Test":"TestCheckout"}
+{"Action":"output","Package":"example.com/shop","Test":"TestCheckout","Output":"    checkout_test.go:12: total = 9, want 10\n"}
+{"Action":"fail","Package":"example.com/shop","Test":"TestCheckout","Elapsed":0.02}
+{"Action":"fail","Package":"example.com/shop","Elapsed":0.05}
+`
+
+func TestIngest_GoJSON(t *testing.T) {
+	orch, err := New(Config{OutputDir: filepath.Join(t.TempDir(), ".3pio"), Logger: logger.NewTestLogger(), Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Ingest("go-json", strings.NewReader(ingestedGoJSON)); err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if orch.GetExitCode() != 1 {
+		t.Errorf("Expected exit code 1 for the failing test, got %d", orch.GetExitCode())
+	}
+
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	for _, want := range []string{"detected_runner: go test\n", "exit_reason: tests_failed\n", "- Test cases passed: 1\n", "- Test cases failed: 1\n"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+
+	// The input is kept as the run's output
+	output, err := os.ReadFile(filepath.Join(orch.runDir, "output.log"))
+	if err != nil || string(output) != ingestedGoJSON {
+		t.Errorf("Expected output.log to hold the ingested stream, got %q (%v)", output, err)
+	}
+}
+
+func TestIngest_UnknownFormat(t *testing.T) {
+	orch, err := New(Config{OutputDir: t.TempDir(), Logger: logger.NewTestLogger(), Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Ingest("junit", strings.NewReader("")); err == nil || !strings.Contains(err.Error(), "go-json") {
+		t.Errorf("Expected an error listing the supported formats, got %v", err)
+	}
+}
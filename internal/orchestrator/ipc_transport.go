@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// newIPCManager creates the IPC manager for the run. With --ipc-socket, events arrive
+// over a Unix domain socket when the platform and the runner's writer support it;
+// everything else tails ipc.jsonl. Either way the run directory gets a complete ipc.jsonl.
+func (o *Orchestrator) newIPCManager(runnerDef runner.Definition) (*ipc.Manager, error) {
+	if o.ipcSocket {
+		switch {
+		case !ipc.SocketSupported():
+			o.logger.Info("IPC socket not supported on this platform, using %s", o.ipcPath)
+		case !socketTransportSupported(runnerDef):
+			o.logger.Info("The %s adapter writes IPC events to a file, using %s", runnerDef.GetAdapterFileName(), o.ipcPath)
+		default:
+			manager, err := ipc.NewSocketManager(o.ipcPath, o.logger)
+			if err == nil {
+				o.logger.Debug("Reading IPC events from socket %s", manager.Address())
+				return manager, nil
+			}
+			o.logger.Error("Failed to create IPC socket, using %s: %v", o.ipcPath, err)
+		}
+	}
+	return ipc.NewManager(o.ipcPath, o.logger)
+}
+
+// malformedIPCWarnPercent is the share of malformed IPC lines, in percent, from which a
+// run is reported as possibly contaminated. Adapters only write valid events, so any
+// real share means something else wrote to the IPC file.
+const malformedIPCWarnPercent = 1
+
+// checkIPCLines warns on the console and in the report when the lines read from the IPC
+// file were not all valid events. The code under test inherits THREEPIO_IPC_PATH, and
+// whatever it writes there can push out or split the adapter's events.
+func (o *Orchestrator) checkIPCLines() {
+	lines, malformed := o.ipcManager.LineCounts()
+	if malformed == 0 || malformed*100 < lines*malformedIPCWarnPercent {
+		return
+	}
+	warning := fmt.Sprintf("%d of %d lines in the IPC file were not valid 3pio events; something other than the adapter, such as the code under test, may have written to THREEPIO_IPC_PATH, so results may be incomplete", malformed, lines)
+	o.logger.Info("IPC may be contaminated: %s", warning)
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	if rm := o.activeReport(); rm != nil {
+		rm.AddWarning(warning)
+	}
+}
+
+// checkIPCSchema warns on the console and in the report when the IPC events declared a
+// newer schema version than this build reads, since fields it doesn't know are dropped
+func (o *Orchestrator) checkIPCSchema() {
+	version := o.ipcManager.UnsupportedSchema()
+	if version == 0 {
+		return
+	}
+	warning := fmt.Sprintf("the test events use IPC schema version %d, but this 3pio reads version %d; upgrade 3pio, as results may be incomplete", version, ipc.SchemaVersion)
+	o.logger.Info("Unsupported IPC schema: %s", warning)
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	if rm := o.activeReport(); rm != nil {
+		rm.AddWarning(warning)
+	}
+}
+
+// socketTransportSupported reports whether a runner's events can be written to an IPC
+// socket: native runners write them from 3pio itself, and the pytest adapter writes
+// synchronously. The JavaScript adapters keep file appends, which are synchronous in Node
+// and so survive the runner exiting right after its last event.
+func socketTransportSupported(runnerDef runner.Definition) bool {
+	switch runnerDef.GetAdapterFileName() {
+	case "", "pytest_adapter.py":
+		return true
+	}
+	return false
+}
+
+// runnerIPCAddress returns the IPC address handed to the test command and its adapter. A
+// file path is made absolute, since the runner may start in another directory than 3pio,
+// as a workspace run (pnpm --filter, npm --workspace) starts it in the package's.
+func runnerIPCAddress(address string) string {
+	if strings.HasPrefix(address, ipc.SocketScheme) {
+		return address
+	}
+	if abs, err := filepath.Abs(address); err == nil {
+		return abs
+	}
+	return address
+}
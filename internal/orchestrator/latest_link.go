@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// latestLinkName is the symlink in the runs directory that points at the most recent run
+const latestLinkName = "latest"
+
+// latestFallbackName is written instead of the symlink where symlinks aren't available (e.g. Windows)
+const latestFallbackName = "latest.txt"
+
+// updateLatestLink points runsDir/latest at the given run directory name.
+// If a symlink can't be created, the run name is written to runsDir/latest.txt instead.
+func updateLatestLink(runsDir, runID string) error {
+	linkPath := filepath.Join(runsDir, latestLinkName)
+
+	// Create the new link under a temporary name and rename it over the old one,
+	// so readers never see a missing link
+	tmpLink := filepath.Join(runsDir, fmt.Sprintf(".%s-%d", latestLinkName, os.Getpid()))
+	_ = os.Remove(tmpLink)
+
+	// Relative target keeps the link valid if the output directory is moved
+	symlinkErr := os.Symlink(runID, tmpLink)
+	if symlinkErr == nil {
+		symlinkErr = os.Rename(tmpLink, linkPath)
+		if symlinkErr == nil {
+			// A stale fallback file would disagree with the link
+			_ = os.Remove(filepath.Join(runsDir, latestFallbackName))
+			return nil
+		}
+		_ = os.Remove(tmpLink)
+	}
+
+	fallbackPath := filepath.Join(runsDir, latestFallbackName)
+	if err := os.WriteFile(fallbackPath, []byte(runID+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to create latest symlink (%v) and fallback file: %w", symlinkErr, err)
+	}
+	return nil
+}
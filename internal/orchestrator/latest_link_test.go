@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateLatestLink(t *testing.T) {
+	runsDir := t.TempDir()
+
+	first := "20250101T100000-grumpy-yoda"
+	second := "20250102T100000-wonky-picard"
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(filepath.Join(runsDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	if err := updateLatestLink(runsDir, first); err != nil {
+		t.Fatalf("updateLatestLink failed: %v", err)
+	}
+	// Replacing an existing link must succeed
+	if err := updateLatestLink(runsDir, second); err != nil {
+		t.Fatalf("updateLatestLink failed on existing link: %v", err)
+	}
+
+	linkPath := filepath.Join(runsDir, latestLinkName)
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		// Symlinks may be unavailable (e.g. Windows without developer mode)
+		content, readErr := os.ReadFile(filepath.Join(runsDir, latestFallbackName))
+		if readErr != nil {
+			t.Fatalf("Expected latest symlink or %s fallback: %v / %v", latestFallbackName, err, readErr)
+		}
+		if string(content) != second+"\n" {
+			t.Errorf("Expected fallback to name %s, got %q", second, content)
+		}
+		return
+	}
+
+	if target != second {
+		t.Errorf("Expected latest to point at %s, got %s", second, target)
+	}
+	if info, err := os.Stat(linkPath); err != nil || !info.IsDir() {
+		t.Errorf("Expected latest to resolve to the run directory: %v", err)
+	}
+}
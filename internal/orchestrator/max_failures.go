@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// recordFailedTest adds a failed test to its group's list. Past --max-failures the test
+// is only counted, so a run with thousands of failures doesn't hold every name.
+func (o *Orchestrator) recordFailedTest(group, testName string) {
+	if o.maxFailures > 0 && o.listedFailures() >= o.maxFailures {
+		o.omittedFailures[group+"\x00"+testName] = true
+		return
+	}
+	o.groupFailedTests[group] = append(o.groupFailedTests[group], testName)
+}
+
+// forgetFailedTest removes a test that passed on a retry from the failed tests
+func (o *Orchestrator) forgetFailedTest(group, testName string) {
+	o.groupFailedTests[group] = removeString(o.groupFailedTests[group], testName)
+	delete(o.omittedFailures, group+"\x00"+testName)
+}
+
+// listedFailures returns how many failed tests are listed in groupFailedTests
+func (o *Orchestrator) listedFailures() int {
+	listed := 0
+	for _, tests := range o.groupFailedTests {
+		listed += len(tests)
+	}
+	return listed
+}
+
+// printOmittedFailures says how many failures went past --max-failures without details
+func (o *Orchestrator) printOmittedFailures() {
+	if o.quiet || len(o.omittedFailures) == 0 {
+		return
+	}
+	fmt.Printf("... and %s more failures (details kept for the first %d, --max-failures)\n", formatThousands(len(o.omittedFailures)), o.maxFailures)
+}
+
+// formatThousands formats n with comma thousands separators, e.g. 1,482
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
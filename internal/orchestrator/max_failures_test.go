@@ -0,0 +1,45 @@
+package orchestrator
+
+import "testing"
+
+func TestRecordFailedTest_MaxFailures(t *testing.T) {
+	o := &Orchestrator{
+		maxFailures:      2,
+		groupFailedTests: make(map[string][]string),
+		omittedFailures:  make(map[string]bool),
+	}
+	o.recordFailedTest("a.test.js", "one")
+	o.recordFailedTest("b.test.js", "two")
+	o.recordFailedTest("b.test.js", "three")
+	o.recordFailedTest("c.test.js", "four")
+
+	if got := o.listedFailures(); got != 2 {
+		t.Errorf("Expected 2 listed failures, got %d", got)
+	}
+	if len(o.omittedFailures) != 2 {
+		t.Errorf("Expected 2 omitted failures, got %v", o.omittedFailures)
+	}
+
+	// Passing on a retry clears a failure whether or not it was listed
+	o.forgetFailedTest("c.test.js", "four")
+	o.forgetFailedTest("a.test.js", "one")
+	if got := o.listedFailures(); got != 1 || len(o.omittedFailures) != 1 {
+		t.Errorf("Expected 1 listed and 1 omitted failure, got %d and %v", got, o.omittedFailures)
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := map[int]string{
+		0:        "0",
+		999:      "999",
+		1000:     "1,000",
+		1482:     "1,482",
+		12345678: "12,345,678",
+		-4200:    "-4,200",
+	}
+	for n, want := range tests {
+		if got := formatThousands(n); got != want {
+			t.Errorf("formatThousands(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -8,6 +9,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"syscall"
@@ -21,6 +23,12 @@ import (
 	"github.com/zk/3pio/internal/runner/definitions"
 )
 
+// failFastAbortReason is recorded in the report when --fail-fast stops the run
+const failFastAbortReason = "Run stopped after the first failing group (--fail-fast); remaining tests did not run"
+
+// TimeoutExitCode is the exit code used when --timeout kills the run, matching timeout(1)
+const TimeoutExitCode = 124
+
 // Orchestrator manages the test execution lifecycle
 type Orchestrator struct {
 	runnerManager *runner.Manager
@@ -28,14 +36,64 @@ type Orchestrator struct {
 	ipcManager    *ipc.Manager
 	logger        Logger
 
-	runID          string
-	runDir         string
-	ipcPath        string
-	command        []string
-	exitCode       int
-	detectedRunner string // Track which test runner was detected
+	runID            string
+	runDir           string
+	ipcPath          string
+	command          []string
+	exitCode         int
+	exitSignal       string                    // Signal that terminated the test command, e.g. SIGKILL
+	detectedRunner   string                    // Track which test runner was detected
+	runnerVersion    string                    // Version of the detected runner, "" until known (guarded by reportMu)
+	junitPath        string                    // Optional override for the JUnit XML report location
+	outputDir        string                    // Base directory for run artifacts (defaults to .3pio)
+	keepRuns         int                       // Number of most recent runs to keep (negative keeps all)
+	runName          string                    // Optional fixed run name used instead of the random suffix
+	quiet            bool                      // Suppress the header and per-group lines, keeping only the final summary
+	slowThreshold    time.Duration             // Test cases slower than this are listed as slow (0 disables)
+	goList           bool                      // Map go test results to their test files using go list
+	ginkgo           bool                      // Report Ginkgo suites as their spec hierarchy
+	failFast         bool                      // Kill the test process when the first group fails
+	timeout          time.Duration             // Kill the test process after this long (0 disables)
+	color            bool                      // Color PASS and FAIL statuses on the console
+	onlyFailures     bool                      // List only failing groups in test-run.md
+	redactPaths      bool                      // Show the project root as <root> and home as ~ in reports
+	keepANSI         bool                      // Keep ANSI escape sequences in group report output
+	maxFailures      int                       // Failed tests keeping error details (0 keeps all)
+	stream           *testStream               // Prints each test case as it arrives (--verbose), nil otherwise
+	interrupted      bool                      // The test command was stopped by SIGINT or SIGTERM
+	timedOut         bool                      // The test command was stopped by --timeout
+	maxGroupOutput   int                       // Bytes of stdout/stderr kept in each group report (0 keeps everything)
+	reportDebounce   time.Duration             // Quiet time before reports are rewritten (0 keeps the defaults)
+	reportMaxWait    time.Duration             // Longest reports can lag behind the events (0 keeps the defaults)
+	groupOrder       report.GroupOrder         // Order of groups in reports
+	reportDetail     report.ReportDetail       // How much of each test case group reports show
+	collapseDepth    int                       // Level whose group reports list their subgroups' tests (0 collapses nothing)
+	retryOnCrash     int                       // Times to re-run a command that crashed before reporting results
+	failOnSkipped    bool                      // Fail a run that exited 0 but skipped tests or groups
+	allowNoEvents    bool                      // Keep exit code 0 for a run that passed without reporting any results
+	failUnder        float64                   // Percentage of test cases that must pass, deciding the exit code (0 disables)
+	failUnderEmpty   bool                      // A run without test cases meets failUnder
+	syncOutput       bool                      // Flush output.log to disk periodically during the run
+	compressOutput   bool                      // Gzip output.log into output.log.gz when the run is finalized
+	crashedAttempts  []ipc.CrashedAttempt      // Attempts that crashed before reporting results, oldest first
+	forcedRunner     string                    // Runner selected with --runner instead of detected ("" detects)
+	selection        definitions.TestSelection // Tests picked with --test-name-filter and --test-file
+	runnerDef        runner.Definition         // Runner of the current run, nil until detected
+	envAllowlist     []string                  // Environment variables passed to the test command (nil passes all)
+	eventStreamPath  string                    // Where --event-stream writes ("" disables)
+	streamAppend     bool                      // Append to the --event-stream file (later commands of RunCommands)
+	eventStream      *eventStream              // Open event stream during a run, nil otherwise
+	noOutputLog      bool                      // Don't write the command's output to output.log
+	separateStreams  bool                      // Also write the command's stderr to stderr.log
+	preflight        bool                      // Count the tests with a collect-only run first (pytest only)
+	ipcSocket        bool                      // Read IPC events from a Unix domain socket where the runner supports it
+	vitestJSONReport string                    // Where Vitest's json reporter writes, read if the 3pio reporter sends nothing
+	watch            *watchSession             // Tracks re-runs with --watch, nil otherwise
+	reportMu         sync.Mutex                // Guards swapping reportManager between watch runs
 
 	// Console output state
+	consoleMu        sync.Mutex    // Guards console output and counters shared with the progress line
+	progress         *progressLine // Periodic progress line, nil with --quiet or --watch
 	startTime        time.Time
 	passedGroups     int
 	failedGroups     int
@@ -43,24 +101,31 @@ type Orchestrator struct {
 	xfailedGroups    int // Track groups with xfailed tests
 	xpassedGroups    int // Track groups with xpassed tests
 	totalGroups      int
-	passedTests      int                  // Track actual test cases
-	failedTests      int                  // Track actual test cases
-	skippedTests     int                  // Track actual test cases
-	xfailedTests     int                  // Track expected failures (xfail)
-	xpassedTests     int                  // Track unexpected passes (xpass)
-	totalTests       int                  // Track actual test cases
-	displayedGroups  map[string]bool      // Track which groups we've already displayed
-	lastCollected    int                  // Track last collection count to avoid duplicates
-	groupStartTimes  map[string]time.Time // Track start time for each group
-	groupFailedTests map[string][]string  // Track failed test names by group
-	completedGroups  map[string]bool      // Track which groups have shown their final PASS/FAIL status
-	noTestGroups     map[string]bool      // Track packages with no test files (Go specific)
+	passedTests      int                     // Track actual test cases
+	failedTests      int                     // Track actual test cases
+	skippedTests     int                     // Track actual test cases
+	xfailedTests     int                     // Track expected failures (xfail)
+	xpassedTests     int                     // Track unexpected passes (xpass)
+	totalTests       int                     // Track actual test cases
+	testOutcomes     map[string]*testOutcome // Latest status of each test case, so retries count once
+	buildFailures    []buildFailure          // Groups that failed to compile, in the order reported
+	displayedGroups  map[string]bool         // Track which groups we've already displayed
+	lastCollected    int                     // Track last collection count to avoid duplicates
+	groupStartTimes  map[string]time.Time    // Track start time for each group
+	groupFailedTests map[string][]string     // Track failed test names by group
+	omittedFailures  map[string]bool         // Failed tests past --max-failures, by group and test name
+	completedGroups  map[string]bool         // Track which groups have shown their final PASS/FAIL status
+	noTestGroups     map[string]bool         // Track packages with no test files (Go specific)
 
 	// Error capture
 	stderrCapture strings.Builder
 
 	// Cargo test support
 	cargoProcessExited chan<- struct{}
+
+	// Fail-fast support: failFastTriggered is closed once, on the first failing group
+	failFastTriggered chan struct{}
+	failFastOnce      sync.Once
 }
 
 // TailReader implements io.Reader that tails a file until signaled to stop
@@ -102,8 +167,47 @@ type Logger interface {
 
 // Config holds orchestrator configuration
 type Config struct {
-	Command []string
-	Logger  Logger
+	Command         []string
+	Logger          Logger
+	JUnitPath       string              // Optional JUnit XML output path (defaults to the run directory)
+	OutputDir       string              // Optional base directory used instead of .3pio
+	KeepRuns        int                 // Number of most recent runs to keep (0 uses DefaultKeepRuns, negative keeps all)
+	RunName         string              // Optional run name used instead of the random suffix (still timestamp-prefixed)
+	Quiet           bool                // Only print the final summary and report path to the console
+	SlowThreshold   time.Duration       // Test cases slower than this are listed in test-run.md (0 disables)
+	GoList          bool                // Group go test results by test file using go list (adds ~200-500ms of background work)
+	Ginkgo          bool                // Report Ginkgo suites as Describe/Context/It groups, even alongside packages without Ginkgo
+	FailFast        bool                // Kill the test command as soon as a group fails
+	Timeout         time.Duration       // Kill the test command once the run has taken this long (0 disables)
+	Color           string              // "auto", "always" or "never" console colors ("" means auto)
+	OnlyFailures    bool                // List only failing groups in test-run.md's group table
+	RedactPaths     bool                // Replace the project root with <root> and the home directory with ~ in reports
+	KeepANSI        bool                // Keep ANSI escape sequences in the output shown in group reports
+	MaxFailures     int                 // Keep error details for only this many failed tests (0 keeps all)
+	Verbose         bool                // Print every test case result as it arrives (ignored with Quiet)
+	MaxGroupOutput  int                 // Bytes of stdout/stderr kept in each group report (0 keeps everything)
+	Preflight       bool                // Count pytest tests with --collect-only before the run
+	Watch           bool                // Report every re-run of a Jest or Vitest command in watch mode
+	IPCSocket       bool                // Use a Unix domain socket for IPC instead of tailing ipc.jsonl, where supported
+	GroupOrder      report.GroupOrder   // Order of groups in test-run.md and subgroup tables ("" means by name)
+	Runner          string              // Name of the runner to use instead of detecting one ("" detects)
+	EnvAllowlist    []string            // Environment variables passed to the test command besides PATH (nil passes all)
+	EventStream     string              // Path to mirror events to as JSON lines, "-" for stdout ("" disables)
+	NoOutputLog     bool                // Don't write the command's output to output.log
+	SeparateStreams bool                // Also write the command's stderr to stderr.log
+	TestNameFilter  string              // Run only tests matching this pattern, via the runner's own selector ("" runs all)
+	TestFiles       []string            // Run only these test files, via the runner's own selector (nil runs all)
+	ReportDebounce  time.Duration       // Quiet time before reports are rewritten during the run (0 keeps the defaults)
+	ReportMaxWait   time.Duration       // Longest reports can lag behind the events (0 keeps the defaults)
+	ReportDetail    report.ReportDetail // How much of each test case group reports show ("" means standard)
+	CollapseDepth   int                 // Show this many levels of groups in group reports, collapsing deeper ones (0 shows all)
+	RetryOnCrash    int                 // Times to re-run a command that crashed before reporting results (0 disables)
+	FailOnSkip      bool                // Exit non-zero when any test or group was skipped
+	AllowNoEvents   bool                // Keep exit code 0 when the command passed but reported no test results
+	FailUnder       float64             // Exit 0 only when at least this percentage of test cases passed (0 disables)
+	FailUnderEmpty  bool                // A run without test cases meets FailUnder instead of failing it
+	SyncOutput      bool                // Flush output.log to disk every second while the command runs
+	CompressOutput  bool                // Gzip output.log into output.log.gz once the run is finalized
 }
 
 // New creates a new orchestrator
@@ -127,15 +231,82 @@ func New(config Config) (*Orchestrator, error) {
 		return nil, fmt.Errorf("logger must be a *logger.FileLogger or *logger.TestLogger")
 	}
 
+	outputDir := config.OutputDir
+	if outputDir == "" {
+		outputDir = ".3pio"
+	}
+
+	keepRuns := config.KeepRuns
+	if keepRuns == 0 {
+		keepRuns = DefaultKeepRuns
+	}
+
+	var stream *testStream
+	if config.Verbose && !config.Quiet {
+		stream = newTestStream(os.Stdout)
+	}
+
+	var watch *watchSession
+	if config.Watch {
+		watch = &watchSession{}
+	}
+
+	var progress *progressLine
+	if !config.Quiet && watch == nil {
+		progress = &progressLine{out: os.Stdout, tty: isTerminal(os.Stdout)}
+	}
+
 	return &Orchestrator{
-		runnerManager:    runnerMgr,
-		logger:           config.Logger,
-		command:          config.Command,
-		displayedGroups:  make(map[string]bool),
-		groupStartTimes:  make(map[string]time.Time),
-		groupFailedTests: make(map[string][]string),
-		completedGroups:  make(map[string]bool),
-		noTestGroups:     make(map[string]bool),
+		runnerManager:     runnerMgr,
+		logger:            config.Logger,
+		command:           config.Command,
+		junitPath:         config.JUnitPath,
+		outputDir:         filepath.Clean(outputDir),
+		keepRuns:          keepRuns,
+		runName:           config.RunName,
+		quiet:             config.Quiet,
+		slowThreshold:     config.SlowThreshold,
+		goList:            config.GoList,
+		ginkgo:            config.Ginkgo,
+		failFast:          config.FailFast,
+		timeout:           config.Timeout,
+		color:             colorEnabled(config.Color, os.Stdout),
+		onlyFailures:      config.OnlyFailures,
+		redactPaths:       config.RedactPaths,
+		keepANSI:          config.KeepANSI,
+		maxFailures:       config.MaxFailures,
+		stream:            stream,
+		maxGroupOutput:    config.MaxGroupOutput,
+		reportDebounce:    config.ReportDebounce,
+		reportMaxWait:     config.ReportMaxWait,
+		groupOrder:        config.GroupOrder,
+		reportDetail:      config.ReportDetail,
+		collapseDepth:     config.CollapseDepth,
+		retryOnCrash:      config.RetryOnCrash,
+		failOnSkipped:     config.FailOnSkip,
+		allowNoEvents:     config.AllowNoEvents,
+		failUnder:         config.FailUnder,
+		failUnderEmpty:    config.FailUnderEmpty,
+		syncOutput:        config.SyncOutput,
+		compressOutput:    config.CompressOutput,
+		forcedRunner:      config.Runner,
+		selection:         definitions.TestSelection{NameFilter: config.TestNameFilter, Files: config.TestFiles},
+		envAllowlist:      config.EnvAllowlist,
+		eventStreamPath:   config.EventStream,
+		noOutputLog:       config.NoOutputLog,
+		separateStreams:   config.SeparateStreams,
+		preflight:         config.Preflight,
+		ipcSocket:         config.IPCSocket,
+		watch:             watch,
+		progress:          progress,
+		failFastTriggered: make(chan struct{}),
+		displayedGroups:   make(map[string]bool),
+		groupStartTimes:   make(map[string]time.Time),
+		groupFailedTests:  make(map[string][]string),
+		omittedFailures:   make(map[string]bool),
+		testOutcomes:      make(map[string]*testOutcome),
+		completedGroups:   make(map[string]bool),
+		noTestGroups:      make(map[string]bool),
 	}, nil
 }
 
@@ -154,49 +325,41 @@ func (o *Orchestrator) Run() error {
 		_ = o.Close()
 	}()
 
-	// Generate run ID
-	o.runID = generateRunID()
-	o.runDir = filepath.Join(".3pio", "runs", o.runID)
-
-	// Setup IPC in the run directory (do this early so it's available even if runner detection fails)
-	o.ipcPath = filepath.Join(o.runDir, "ipc.jsonl")
+	// Generate run ID and set up IPC in the run directory (do this early so it's
+	// available even if runner detection fails)
+	o.newRunDir()
 
 	// Print test run header with metadata
-	testCommand := strings.Join(o.command, " ")
-	currentTime := time.Now().Format(time.RFC3339)
-	trunDir := o.runDir
-	fullReport := "$trun_dir/test-run.md"
-
-	// Get current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = "unknown"
+	if o.stream != nil {
+		o.printRunHeader(strings.Join(o.command, " "), "Test execution starting, printing test results as they arrive.")
+	} else {
+		o.printRunHeader(strings.Join(o.command, " "), "Test execution starting, no output until test results.")
 	}
 
-	fmt.Println("---")
-	fmt.Printf("current_time: %s\n", currentTime)
-	fmt.Printf("cwd: %s\n", cwd)
-	fmt.Printf("test_command: `%s`\n", testCommand)
-	fmt.Printf("trun_dir: %s\n", trunDir)
-	fmt.Printf("full_report: %s\n", fullReport)
-	fmt.Println("---")
-	fmt.Println()
-	fmt.Println("Test execution starting, no output until test results.")
-	fmt.Println()
-
 	// Detect test runner
-	runnerDef, err := o.runnerManager.Detect(o.command)
+	runnerDef, err := o.detectRunnerDefinition()
 	if err != nil {
-		return fmt.Errorf("failed to detect test runner: %w", err)
+		return err
 	}
+	o.runnerDef = runnerDef
+	if err := o.selectTests(runnerDef); err != nil {
+		return err
+	}
+	o.enableGinkgo(runnerDef)
 
 	// Create IPC manager
-	o.ipcManager, err = ipc.NewManager(o.ipcPath, o.logger)
+	o.ipcManager, err = o.newIPCManager(runnerDef)
 	if err != nil {
 		return fmt.Errorf("failed to create IPC manager: %w", err)
 	}
 	// Cleanup will be called explicitly later, not deferred
 
+	// Point runs/latest at this run as soon as its directory exists,
+	// so even a crashed run leaves a useful latest link
+	if err := updateLatestLink(filepath.Dir(o.runDir), o.runID); err != nil {
+		o.logger.Debug("Failed to update latest run link: %v", err)
+	}
+
 	// Start watching for events
 	if err := o.ipcManager.WatchEvents(); err != nil {
 		return fmt.Errorf("failed to start IPC watcher: %w", err)
@@ -207,48 +370,17 @@ func (o *Orchestrator) Run() error {
 
 	// Determine runner name from adapter file
 	adapterFile := runnerDef.GetAdapterFileName()
-	var detectedRunner string
-	switch adapterFile {
-	case "jest.js":
-		detectedRunner = "jest"
-	case "vitest.js":
-		detectedRunner = "vitest"
-	case "pytest_adapter.py":
-		detectedRunner = "pytest"
-	case "cypress.js":
-		detectedRunner = "cypress"
-	case "mocha.js":
-		detectedRunner = "mocha"
-	case "":
-		// Native runner - determine which one based on the underlying definition
-		if nativeRunner, ok := runnerDef.(runner.NativeRunner); ok {
-			nativeDef := nativeRunner.GetNativeDefinition()
-			o.logger.Debug("Native definition type: %T", nativeDef)
-			switch nativeDef.(type) {
-			case *definitions.GoTestDefinition:
-				detectedRunner = "go test"
-				o.logger.Debug("Detected as go test")
-			case *definitions.CargoTestDefinition:
-				detectedRunner = "cargo test"
-				o.logger.Debug("Detected as cargo test")
-			case *definitions.NextestDefinition:
-				detectedRunner = "cargo nextest"
-				o.logger.Debug("Detected as cargo nextest")
-			default:
-				detectedRunner = fmt.Sprintf("unknown native (%T)", nativeDef)
-				o.logger.Debug("Unknown native type: %T", nativeDef)
-			}
-		} else {
-			detectedRunner = "unknown native"
-			o.logger.Debug("Not a native runner")
-		}
-	default:
-		detectedRunner = "unknown"
-	}
+	detectedRunner := runnerName(runnerDef)
+	o.logger.Debug("Detected runner: %s (%T)", detectedRunner, runnerDef)
 
 	// Store the detected runner
 	o.detectedRunner = detectedRunner
 
+	// Watch mode relies on the adapter marking where each re-run starts and ends
+	if o.watch != nil && detectedRunner != "jest" && detectedRunner != "vitest" {
+		return fmt.Errorf("--watch is only supported for Jest and Vitest, detected %s", detectedRunner)
+	}
+
 	// Build modified command for logging
 	var modifiedCommand string
 	if adapterFile == "" {
@@ -261,9 +393,9 @@ func (o *Orchestrator) Run() error {
 	}
 
 	// Create report manager
-	o.reportManager, err = report.NewManager(o.runDir, parser, o.logger, detectedRunner, modifiedCommand)
+	o.reportManager, err = o.newReportManager(o.runDir, parser, runnerDef, modifiedCommand)
 	if err != nil {
-		return fmt.Errorf("failed to create report manager: %w", err)
+		return err
 	}
 	// Ensure report manager is finalized even on early return
 	defer func() {
@@ -272,39 +404,42 @@ func (o *Orchestrator) Run() error {
 		}
 	}()
 
+	o.recordRunnerVersion(detectedRunner)
+
 	// Initialize report
 	args := strings.Join(o.command, " ")
 	if err := o.reportManager.Initialize(args); err != nil {
 		return fmt.Errorf("failed to initialize report: %w", err)
 	}
 
+	// Remove old runs now that the current run directory exists
+	if err := pruneOldRuns(filepath.Dir(o.runDir), o.runID, o.keepRuns, o.logger); err != nil {
+		o.logger.Debug("Failed to prune old runs: %v", err)
+	}
+
 	// Check if this is a native runner (like Go test)
 	var testCommandSlice []string
-	var isNativeRunner bool
 	var nativeDef interface{}
 
 	// Check if adapter is needed (empty adapter name means native runner)
 	adapterFileName := runnerDef.GetAdapterFileName()
 	if adapterFileName == "" {
 		// Native runner - no adapter needed (e.g., Go test, cargo test, nextest)
-		isNativeRunner = true
-		// Try to get the native definition using type assertion
-		switch wrapper := runnerDef.(type) {
-		case *definitions.GoTestWrapper:
-			nativeDef = wrapper.GoTestDefinition
-		case *definitions.CargoTestWrapper:
-			nativeDef = wrapper.CargoTestDefinition
-		case *definitions.NextestWrapper:
-			nativeDef = wrapper.NextestDefinition
-		}
+		nativeDef = nativeDefinition(runnerDef)
 		testCommandSlice = runnerDef.BuildCommand(o.command, "")
 		o.logger.Debug("Using native runner for: %v", testCommandSlice)
+
+		// The go list lookup runs alongside the tests; results map to files once it finishes
+		if goDef, ok := nativeDef.(*definitions.GoTestDefinition); ok && o.goList {
+			goDef.EnableFileMapping(o.command)
+		}
 	} else {
 		// Traditional adapter-based runner
 		adapterPath, err := o.extractAdapter(adapterFileName)
 		if err != nil {
 			return fmt.Errorf("failed to extract adapter: %w", err)
 		}
+		o.setVitestJSONReport(runnerDef)
 		testCommandSlice = runnerDef.BuildCommand(o.command, adapterPath)
 		o.logger.Debug("Adapter path: %s", adapterPath)
 
@@ -312,14 +447,228 @@ func (o *Orchestrator) Run() error {
 		modifiedCommand = strings.Join(testCommandSlice, " ")
 		o.reportManager.UpdateModifiedCommand(modifiedCommand)
 	}
+	if err := o.verifyCommand(testCommandSlice); err != nil {
+		return err
+	}
+
+	// Events written now are read by the watcher started above, ahead of the real run's
+	if o.preflight {
+		o.runPreflight(runnerDef)
+	}
+
+	// Each re-run in watch mode gets a report manager of its own, set up like this one
+	if o.watch != nil {
+		rerunCommand := strings.Join(testCommandSlice, " ")
+		o.watch.dir = o.runDir
+		o.watch.active = true
+		o.watch.newReport = func(runDir string) (*report.Manager, error) {
+			manager, err := o.newReportManager(runDir, parser, runnerDef, rerunCommand)
+			if err != nil {
+				return nil, err
+			}
+			return manager, manager.Initialize(args)
+		}
+	}
 
 	o.logger.Debug("Executing command: %v", testCommandSlice)
-	o.logger.Debug("IPC path: %s", o.ipcPath)
+	o.logger.Debug("IPC path: %s", o.ipcManager.Address())
 
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// Open the event stream before the command can produce events
+	if o.eventStreamPath != "" {
+		stream, err := openEventStream(o.eventStreamPath, o.streamAppend)
+		if err != nil {
+			return err
+		}
+		o.eventStream = stream
+		defer func() {
+			o.eventStream = nil
+			if err := stream.Close(); err != nil {
+				o.logger.Error("Failed to close event stream: %v", err)
+			}
+		}()
+	}
+
+	// With --timeout, runAttempt stops the command at the deadline, which covers every
+	// attempt of --retry-on-crash
+	timedOut := make(chan struct{})
+	if o.timeout > 0 {
+		timer := time.AfterFunc(o.timeout, func() { close(timedOut) })
+		defer timer.Stop()
+	}
+
+	// With --retry-on-crash, a command that crashed before reporting any results is run again
+	var result attemptResult
+	for attempt := 1; ; attempt++ {
+		result, err = o.runAttempt(testCommandSlice, runnerDef, nativeDef, sigChan, timedOut)
+		if err != nil {
+			return err
+		}
+		if attempt > o.retryOnCrash || !o.crashedBeforeResults(result) {
+			break
+		}
+		if err := o.prepareRetry(attempt, parser, runnerDef, strings.Join(testCommandSlice, " "), args); err != nil {
+			return err
+		}
+	}
+	commandErr, timeoutHit := result.commandErr, result.timedOut
+	outputPath := filepath.Join(o.runDir, "output.log")
+
+	// All goroutines should be finished at this point
+	// (they were waited for via outputDone)
+
+	// In watch mode each completed run was reported as it finished
+	if o.watch != nil && !o.watch.active {
+		if !o.quiet {
+			fmt.Println("Stopped watching.")
+		}
+		if commandErr != nil {
+			return fmt.Errorf("test command failed: %w", commandErr)
+		}
+		return nil
+	}
+
+	// Finalize report
+	outcome := o.runOutcome(timeoutHit)
+	o.failOnSkip(&outcome)
+	o.checkNoEvents(&outcome)
+	o.checkPassRate(&outcome)
+	// A command that exited on its own keeps its exit code unless 3pio has a reason of its own
+	if !result.killed {
+		o.reportManager.SetRunnerExitCode(o.exitCode, outcome.exitCode)
+	}
+	o.exitCode = outcome.exitCode
+	var errorDetails string
+	var shouldShowError bool
+	if commandErr != nil {
+		// Check if this is a configuration/startup error vs test failures
+		// Configuration errors happen when we have very few or no test groups
+		// or when the exit code suggests a setup problem
+		isConfigError := outcome.isConfigError()
+
+		if outcome.exitMeaning == runner.ExitCodeNoTests {
+			errorDetails = fmt.Sprintf("No tests were collected (%s exited with code %d)", o.detectedRunner, o.exitCode)
+			shouldShowError = true
+		} else if outcome.onlyBuildFailed() {
+			errorDetails = buildFailureDetails(o.buildFailures)
+			shouldShowError = true
+		} else if isConfigError {
+			errorDetails = commandErr.Error()
+
+			// Include stderr content if available for command errors
+			stderrContent := strings.TrimSpace(o.stderrCapture.String())
+			if stderrContent != "" {
+				errorDetails = stderrContent
+			}
+
+			// For config/setup errors (non-zero exit with no tests run),
+			// show the actual output instead of generic "exit status N"
+			if (errorDetails == "exit status 1" || errorDetails == "exit status 2") && o.totalGroups == 0 {
+				// Read first part of output.log to show actual error
+				if outputContent, err := os.ReadFile(outputPath); err == nil {
+					lines := strings.Split(string(outputContent), "\n")
+					// Show first non-empty lines (up to 10 lines)
+					var errorLines []string
+					for i := 0; i < len(lines) && len(errorLines) < 10; i++ {
+						if trimmed := strings.TrimSpace(lines[i]); trimmed != "" {
+							errorLines = append(errorLines, lines[i])
+						}
+					}
+					if len(errorLines) > 0 {
+						errorDetails = strings.Join(errorLines, "\n")
+						shouldShowError = true
+					}
+				}
+			} else {
+				shouldShowError = true
+			}
+		}
+	}
+	o.reportManager.SetExitReason(outcome.exitReason())
+	if err := o.reportManager.Finalize(o.exitCode, errorDetails); err != nil {
+		o.logger.Error("Failed to finalize report: %v", err)
+	}
+
+	// If we didn't get GroupResult events, compute stats and display results from the report manager
+	if o.totalGroups == 0 {
+		o.computeStatsFromReportManager()
+		o.displayFinalResults()
+	}
+
+	// Summarize streamed test cases that were not printed
+	if o.stream != nil {
+		o.stream.flush()
+	}
+
+	// Print completion message with TypeScript-style summary
+	if !o.quiet {
+		fmt.Println()
+	}
+
+	// Print error details if command failed and we have error details
+	if (commandErr != nil && errorDetails != "" && shouldShowError) ||
+		(commandErr != nil && o.totalGroups == 0 && errorDetails != "") {
+		fmt.Printf("Error: %s\n", errorDetails)
+		fmt.Println()
+	}
+	o.printSkipFailure(outcome)
+	o.printNoEvents(outcome)
+	o.printPassRate(outcome)
+
+	o.printResults()
+	o.printFailedTests()
+	o.printResultLine(o.exitCode)
+
+	// Return command error if there was one, unless --fail-under passed the run anyway
+	if commandErr != nil && o.exitCode != 0 {
+		return fmt.Errorf("test command failed: %w", commandErr)
+	}
+
+	return nil
+}
+
+// printRunHeader prints the run's metadata and what happens next. In quiet mode the
+// report path is printed with the final summary instead.
+func (o *Orchestrator) printRunHeader(testCommand, status string) {
+	if o.quiet {
+		return
+	}
+
+	// Get current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "unknown"
+	}
+
+	fmt.Println("---")
+	fmt.Printf("current_time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Printf("cwd: %s\n", cwd)
+	fmt.Printf("test_command: `%s`\n", testCommand)
+	fmt.Printf("trun_dir: %s\n", o.runDir)
+	fmt.Printf("full_report: %s\n", "$trun_dir/test-run.md")
+	fmt.Println("---")
+	fmt.Println()
+	fmt.Println(status)
+	fmt.Println()
+}
+
+// attemptResult is how one execution of the test command ended
+type attemptResult struct {
+	commandErr error // Error from waiting for the command, nil when it exited 0
+	timedOut   bool  // Stopped by --timeout
+	killed     bool  // Stopped by a signal, --fail-fast or --timeout rather than exiting
+}
+
+// runAttempt executes the test command once and waits until its output and IPC events
+// have been processed. The IPC manager stops watching when it returns.
+func (o *Orchestrator) runAttempt(testCommandSlice []string, runnerDef runner.Definition, nativeDef interface{}, sigChan <-chan os.Signal, timedOut <-chan struct{}) (attemptResult, error) {
+	var result attemptResult
+	var err error
+	isNativeRunner := runnerDef.GetAdapterFileName() == ""
+
 	// Create command
 	cmd := exec.Command(testCommandSlice[0], testCommandSlice[1:]...)
 
@@ -332,33 +681,41 @@ func (o *Orchestrator) Run() error {
 	}
 
 	// Set environment
-	cmd.Env = append(os.Environ(), fmt.Sprintf("THREEPIO_IPC_PATH=%s", o.ipcPath))
-
-	// Add RUSTC_BOOTSTRAP=1 for cargo test to enable JSON output
-	if len(o.command) >= 2 && o.command[0] == "cargo" && o.command[1] == "test" {
-		cmd.Env = append(cmd.Env, "RUSTC_BOOTSTRAP=1")
-		o.logger.Debug("Added RUSTC_BOOTSTRAP=1 for cargo test JSON output")
-	}
-
-	// Add NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1 for cargo nextest to enable JSON output
-	if len(o.command) >= 2 && o.command[0] == "cargo" && o.command[1] == "nextest" {
-		cmd.Env = append(cmd.Env, "NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1")
-		o.logger.Debug("Added NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1 for cargo nextest JSON output")
-	}
+	cmd.Env = o.commandEnv(runnerDef, nativeDef, o.ipcManager.Address())
 
 	// Connect stdin to allow interactive prompts
 	cmd.Stdin = os.Stdin
 
-	// Create output.log for capturing all command output
+	// A timed out command is killed with everything it started. Only then, since a
+	// command in its own process group can't read the terminal.
+	if o.timeout > 0 {
+		startProcessGroup(cmd)
+	}
+
+	// Create output.log for capturing all command output. With --no-output-log nothing is
+	// written to disk: native runners still parse the output, read from a pipe instead,
+	// and adapter runners' output is discarded since their results arrive over IPC.
 	outputPath := filepath.Join(o.runDir, "output.log")
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+	var outputFile, outputPipe *os.File
+	if o.noOutputLog {
+		o.logger.Debug("Not writing output.log (--no-output-log)")
+		if isNativeRunner {
+			outputPipe, outputFile, err = os.Pipe()
+			if err != nil {
+				return result, fmt.Errorf("failed to create output pipe: %w", err)
+			}
+			defer func() { _ = outputPipe.Close() }()
+		}
+	} else {
+		outputFile, err = os.Create(outputPath)
+		if err != nil {
+			return result, fmt.Errorf("failed to create output file: %w", err)
+		}
 	}
 	// Ensure outputFile is closed on all exit paths, but track if we closed it explicitly
 	outputFileClosed := false
 	defer func() {
-		if !outputFileClosed {
+		if outputFile != nil && !outputFileClosed {
 			_ = outputFile.Sync() // Ensure file is flushed on Windows
 			_ = outputFile.Close()
 		}
@@ -380,18 +737,45 @@ func (o *Orchestrator) Run() error {
 		o.logger.Debug("Combining stdout and stderr into output.log")
 	}
 
-	// Configure command output redirection directly to output.log
-	cmd.Stdout = outputFile
+	// Configure command output redirection directly to output.log. Left unset, the
+	// command's output goes to the null device.
+	if outputFile != nil {
+		cmd.Stdout = outputFile
+	}
+
+	// With --separate-streams stderr is also written to stderr.log
+	var stderrFile *os.File
+	if o.separateStreams {
+		stderrFile, err = os.Create(filepath.Join(o.runDir, "stderr.log"))
+		if err != nil {
+			return result, fmt.Errorf("failed to create stderr.log: %w", err)
+		}
+		defer func() { _ = stderrFile.Close() }()
+	}
 
 	var stderrPipe io.ReadCloser
+	var stderrCopyReader, stderrCopyWriter *os.File
 	if keepStderrSeparate {
 		// Keep stderr separate (for Go test only)
 		stderrPipe, err = cmd.StderrPipe()
 		if err != nil {
-			return fmt.Errorf("failed to create stderr pipe: %w", err)
+			return result, fmt.Errorf("failed to create stderr pipe: %w", err)
 		}
 		o.logger.Debug("Keeping stderr separate for Go test")
-	} else {
+	} else if stderrFile != nil && isNativeRunner {
+		// Native parsers still need stderr in output.log (cargo matches its "Running"
+		// lines to the JSON that follows), so a second reader copies it to both logs
+		stderrCopyReader, stderrCopyWriter, err = os.Pipe()
+		if err != nil {
+			return result, fmt.Errorf("failed to create stderr pipe: %w", err)
+		}
+		defer func() { _ = stderrCopyReader.Close() }()
+		cmd.Stderr = stderrCopyWriter
+		o.logger.Debug("Copying stderr to stderr.log and output.log (--separate-streams)")
+	} else if stderrFile != nil {
+		// Adapter runners report results over IPC, so only the raw logs are split
+		cmd.Stderr = stderrFile
+	} else if outputFile != nil {
 		// Redirect both stdout and stderr to output.log
 		cmd.Stderr = outputFile
 	}
@@ -404,7 +788,20 @@ func (o *Orchestrator) Run() error {
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		o.exitCode = 1 // Set error exit code
-		return fmt.Errorf("failed to start test command: %w", err)
+		return result, fmt.Errorf("failed to start test command: %w", err)
+	}
+
+	// The command has its own copy of the pipes' write ends; closing ours lets the
+	// readers see EOF once the command exits. While stderr is copied into the output
+	// pipe, the copy closes the pipe when it is done.
+	if stderrCopyWriter != nil {
+		_ = stderrCopyWriter.Close()
+	}
+	if outputPipe != nil {
+		outputFileClosed = true
+		if stderrCopyReader == nil {
+			_ = outputFile.Close()
+		}
 	}
 
 	// Record start time for duration calculation
@@ -412,10 +809,10 @@ func (o *Orchestrator) Run() error {
 
 	// Open output.log for reading (tail -f style) only for native runners
 	var tailReader *os.File
-	if isNativeRunner {
+	if isNativeRunner && outputPipe == nil {
 		tailReader, err = os.Open(outputPath)
 		if err != nil {
-			return fmt.Errorf("failed to open output.log for reading: %w", err)
+			return result, fmt.Errorf("failed to open output.log for reading: %w", err)
 		}
 		o.logger.Debug("Opened output.log for tailing: %s", outputPath)
 	}
@@ -430,27 +827,62 @@ func (o *Orchestrator) Run() error {
 		defer close(eventsDone)
 		o.processEvents()
 	}()
+	stopProgress := o.startProgress()
+
+	// With --sync-output, output.log is flushed to disk until the command's output is all in
+	stopOutputSync := func() {}
+	if outputFile != nil && outputPipe == nil {
+		stopOutputSync = o.startOutputSync(outputFile)
+	}
 
 	// Universal output handling for ALL runners
 	// Create a channel to signal when the process exits
 	processExited := make(chan struct{})
 	o.cargoProcessExited = processExited // Used by TailReader
 
-	// Process output from output.log for native runners
-	if isNativeRunner && tailReader != nil {
+	// Copy stderr into stderr.log and output.log (--separate-streams with native runners).
+	// The tail reader waits for the copy so it doesn't stop before the last stderr lines.
+	tailExited := processExited
+	if stderrCopyReader != nil {
+		stderrCopied := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(stderrCopied)
+			_, _ = io.Copy(io.MultiWriter(stderrFile, outputFile), stderrCopyReader)
+			if outputPipe != nil {
+				_ = outputFile.Close()
+			}
+		}()
+
+		bothExited := make(chan struct{})
+		go func() {
+			<-processExited
+			<-stderrCopied
+			close(bothExited)
+		}()
+		tailExited = bothExited
+	}
+
+	// Process output from output.log (or the output pipe) for native runners
+	if isNativeRunner && (tailReader != nil || outputPipe != nil) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			defer func() {
-				_ = tailReader.Close()
-				o.logger.Debug("Closed tail reader for native runner")
-			}()
-
-			// Create a custom reader that polls the file until process exits
-			fileReader := &TailReader{
-				file:          tailReader,
-				processExited: processExited,
-				logger:        o.logger,
+
+			var outputReader io.Reader = outputPipe
+			if tailReader != nil {
+				defer func() {
+					_ = tailReader.Close()
+					o.logger.Debug("Closed tail reader for native runner")
+				}()
+
+				// Create a custom reader that polls the file until process exits
+				outputReader = &TailReader{
+					file:          tailReader,
+					processExited: tailExited,
+					logger:        o.logger,
+				}
 			}
 
 			// Process output through native definition (no TeeReader needed)
@@ -458,8 +890,12 @@ func (o *Orchestrator) Run() error {
 				ProcessOutput(io.Reader, string) error
 			}); ok {
 				o.logger.Debug("Processing output for native runner")
-				if err := nd.ProcessOutput(fileReader, o.ipcPath); err != nil {
-					o.logger.Error("Failed to process native output: %v", err)
+				if err := nd.ProcessOutput(outputReader, o.ipcManager.Address()); err != nil {
+					if errors.Is(err, os.ErrClosed) {
+						o.logger.Debug("Stopped reading output of the stopped command: %v", err)
+					} else {
+						o.logger.Error("Failed to process native output: %v", err)
+					}
 				}
 			}
 		}()
@@ -470,8 +906,12 @@ func (o *Orchestrator) Run() error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			// Capture stderr to the error buffer
-			_, _ = io.Copy(&o.stderrCapture, stderrPipe)
+			// Capture stderr to the error buffer, and stderr.log with --separate-streams
+			var stderrOut io.Writer = &o.stderrCapture
+			if stderrFile != nil {
+				stderrOut = io.MultiWriter(&o.stderrCapture, stderrFile)
+			}
+			_, _ = io.Copy(stderrOut, stderrPipe)
 		}()
 	}
 
@@ -481,17 +921,29 @@ func (o *Orchestrator) Run() error {
 		done <- cmd.Wait()
 	}()
 
-	var commandErr error
 	select {
 	case err := <-done:
-		commandErr = err
+		result.commandErr = err
 		if err != nil {
 			if exitErr, ok := err.(*exec.ExitError); ok {
 				o.exitCode = exitErr.ExitCode()
 				o.logger.Debug("Command completed with exit code: %d", exitErr.ExitCode())
+				if name, number := exitSignal(exitErr.ProcessState); name != "" {
+					// ExitCode is -1 for a signaled process; exit like a shell would
+					o.exitSignal = name
+					o.exitCode = 128 + number
+					o.logger.Info("Test command was killed by %s", name)
+					if rm := o.activeReport(); rm != nil {
+						rm.SetExitSignal(name)
+					}
+				}
 			} else {
+				// Wait can fail after the command exited, e.g. copying its output; keep its code
 				o.exitCode = 1
-				o.logger.Debug("Command completed with error: %v", err)
+				if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() >= 0 {
+					o.exitCode = cmd.ProcessState.ExitCode()
+				}
+				o.logger.Debug("Command completed with exit code %d and error: %v", o.exitCode, err)
 			}
 		} else {
 			o.logger.Debug("Command completed successfully")
@@ -505,135 +957,197 @@ func (o *Orchestrator) Run() error {
 		o.logger.Debug("Command completed, waiting for readers to finish...")
 	case sig := <-sigChan:
 		o.logger.Info("Received signal: %v", sig)
-		_ = cmd.Process.Kill()
+		_ = killCommand(cmd)
+		result.killed = true
 		o.exitCode = 130 // Standard exit code for SIGINT
+		if rm := o.activeReport(); rm != nil {
+			rm.SetInterrupted(sig.String())
+		}
+		o.interrupted = true
 		// Signal cargo reader if it exists (same as normal completion)
 		if o.cargoProcessExited != nil {
 			close(o.cargoProcessExited)
 			o.logger.Debug("Signaled cargo reader that process was interrupted")
 		}
+	case <-o.failFastTriggered:
+		o.logger.Info("Stopping test command after the first failing group (--fail-fast)")
+		_ = killCommand(cmd)
+		<-done
+		result.killed = true
+		o.exitCode = 1
+		if rm := o.activeReport(); rm != nil {
+			rm.SetAbortReason(failFastAbortReason)
+		}
+		if o.cargoProcessExited != nil {
+			close(o.cargoProcessExited)
+			o.logger.Debug("Signaled cargo reader that process was stopped by fail-fast")
+		}
+	case <-timedOut:
+		o.logger.Info("Test command exceeded the %v timeout, stopping it", o.timeout)
+		_ = killCommand(cmd)
+		<-done
+		result.killed = true
+		o.exitCode = TimeoutExitCode
+		result.timedOut = true
+		o.timedOut = true
+		if rm := o.activeReport(); rm != nil {
+			rm.SetAbortReason(fmt.Sprintf("Run exceeded the %v timeout (--timeout) and was stopped; results are partial", o.timeout))
+		}
+		if o.cargoProcessExited != nil {
+			close(o.cargoProcessExited)
+			o.logger.Debug("Signaled cargo reader that process timed out")
+		}
+	}
+
+	// A killed command's children can keep the output pipes open, so stop reading them
+	// rather than wait for an EOF that may never come
+	if result.killed {
+		if outputPipe != nil {
+			_ = outputPipe.Close()
+		}
+		if stderrCopyReader != nil {
+			_ = stderrCopyReader.Close()
+		}
 	}
 
 	// Wait for output capture to complete
 	wg.Wait()
 	o.logger.Debug("Output capture completed")
 
+	// Events appended now are still read by Cleanup below
+	o.recoverVitestResults()
+
 	// Stop watching for events (this closes the Events channel and allows processEvents to exit)
 	_ = o.ipcManager.Cleanup()
+	o.checkIPCLines()
+	o.checkIPCSchema()
 
 	// Wait for event processing to complete (channel is closed, range will exit)
 	<-eventsDone
+	stopProgress()
 	o.logger.Debug("Event processing completed")
 
-	o.logger.Debug("Output capture completed")
-
 	// NOW it's safe to close the output file after all goroutines are done
 	// On Windows, we need to ensure the file is fully flushed before closing
-	outputFileClosed = true
-	if err := outputFile.Sync(); err != nil {
-		o.logger.Debug("Failed to sync output file: %v", err)
-	}
-	if err := outputFile.Close(); err != nil {
-		o.logger.Error("Failed to close output file: %v", err)
+	stopOutputSync()
+	if !outputFileClosed && outputFile != nil {
+		outputFileClosed = true
+		if err := outputFile.Sync(); err != nil {
+			o.logger.Debug("Failed to sync output file: %v", err)
+		}
+		if err := outputFile.Close(); err != nil {
+			o.logger.Error("Failed to close output file: %v", err)
+		}
 	}
 
-	// All goroutines should be finished at this point
-	// (they were waited for via outputDone)
-
-	// Finalize report
-	var errorDetails string
-	var shouldShowError bool
-	if commandErr != nil {
-		// Check if this is a configuration/startup error vs test failures
-		// Configuration errors happen when we have very few or no test groups
-		// or when the exit code suggests a setup problem
-		isConfigError := o.totalGroups == 0 ||
-			(o.exitCode != 0 && o.exitCode != 1 && o.totalGroups < 2) ||
-			(o.passedGroups == 0 && o.failedGroups == 0 && o.exitCode != 0)
-
-		if isConfigError {
-			errorDetails = commandErr.Error()
-
-			// Include stderr content if available for command errors
-			stderrContent := strings.TrimSpace(o.stderrCapture.String())
-			if stderrContent != "" {
-				errorDetails = stderrContent
-			}
+	return result, nil
+}
 
-			// For config/setup errors (non-zero exit with no tests run),
-			// show the actual output instead of generic "exit status N"
-			if (errorDetails == "exit status 1" || errorDetails == "exit status 2") && o.totalGroups == 0 {
-				// Read first part of output.log to show actual error
-				if outputContent, err := os.ReadFile(outputPath); err == nil {
-					lines := strings.Split(string(outputContent), "\n")
-					// Show first non-empty lines (up to 10 lines)
-					var errorLines []string
-					for i := 0; i < len(lines) && len(errorLines) < 10; i++ {
-						if trimmed := strings.TrimSpace(lines[i]); trimmed != "" {
-							errorLines = append(errorLines, lines[i])
-						}
-					}
-					if len(errorLines) > 0 {
-						errorDetails = strings.Join(errorLines, "\n")
-						shouldShowError = true
-					}
-				}
-			} else {
-				shouldShowError = true
-			}
-		}
+// newReportManager creates the report manager for a run directory with the configured
+// report options
+func (o *Orchestrator) newReportManager(runDir string, parser runner.OutputParser, runnerDef runner.Definition, modifiedCommand string) (*report.Manager, error) {
+	manager, err := report.NewManager(runDir, parser, o.logger, o.detectedRunner, modifiedCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report manager: %w", err)
 	}
-	if err := o.reportManager.Finalize(o.exitCode, errorDetails); err != nil {
-		o.logger.Error("Failed to finalize report: %v", err)
+	if o.junitPath != "" {
+		manager.SetJUnitPath(o.junitPath)
 	}
-
-	// If we didn't get GroupResult events, compute stats and display results from the report manager
-	if o.totalGroups == 0 {
-		o.computeStatsFromReportManager()
-		o.displayFinalResults()
+	if o.slowThreshold > 0 {
+		manager.SetSlowThreshold(o.slowThreshold)
+	}
+	manager.SetOnlyFailures(o.onlyFailures)
+	if o.redactPaths {
+		manager.SetRedactPaths(true)
+	}
+	if o.keepANSI {
+		manager.SetKeepANSI(true)
+	}
+	if o.groupOrder != "" {
+		manager.SetGroupOrder(o.groupOrder)
+	}
+	if o.maxGroupOutput > 0 {
+		manager.SetMaxGroupOutput(o.maxGroupOutput)
+	}
+	if o.maxFailures > 0 {
+		manager.SetMaxFailures(o.maxFailures)
+	}
+	if o.reportDetail != "" {
+		manager.SetReportDetail(o.reportDetail)
+	}
+	if o.collapseDepth > 0 {
+		manager.SetCollapseDepth(o.collapseDepth)
+	}
+	if o.reportDebounce > 0 || o.reportMaxWait > 0 {
+		manager.SetReportIntervals(o.reportDebounce, o.reportMaxWait)
 	}
+	if o.compressOutput {
+		manager.CompressOutputLog()
+	}
+	if o.noOutputLog {
+		if err := manager.DisableOutputLog(); err != nil {
+			return nil, err
+		}
+	}
+	manager.SetRunnerDefinition(runnerDef)
+	o.reportMu.Lock()
+	if o.runnerVersion != "" {
+		manager.SetRunnerVersion(o.runnerVersion)
+	}
+	o.reportMu.Unlock()
+	return manager, nil
+}
 
-	// Print completion message with TypeScript-style summary
-	fmt.Println()
+// printResults prints the closing lines of a run: an exclamation, the abort reason,
+// the results counts and the total time
+func (o *Orchestrator) printResults() {
+	o.printOmittedFailures()
 
-	// Print error details if command failed and we have error details
-	if (commandErr != nil && errorDetails != "" && shouldShowError) ||
-		(commandErr != nil && o.totalGroups == 0 && errorDetails != "") {
-		fmt.Printf("Error: %s\n", errorDetails)
-		fmt.Println()
+	// Add random failure exclamation if tests failed (quiet mode keeps only the results lines)
+	if !o.quiet {
+		if o.failedGroups > 0 {
+			exclamations := []string{
+				"This is madness!",
+				"We're doomed!",
+				"Are you sure this thing is safe?",
+			}
+			randomExclamation := exclamations[time.Now().UnixNano()%int64(len(exclamations))]
+			fmt.Printf("Test failures! %s\n", randomExclamation)
+			// Test details are shown inline with each failing group
+		} else if o.interrupted {
+			fmt.Println("Test run interrupted!")
+		} else if o.timedOut {
+			fmt.Println("Test run timed out!")
+		} else if o.passedGroups > 0 && o.skippedGroups == 0 {
+			// All tests that ran passed (no skips)
+			fmt.Println("Splendid! All tests passed successfully")
+		} else if o.passedGroups > 0 && o.skippedGroups > 0 {
+			// Some tests passed, some were skipped
+			fmt.Println("Tests completed with some skipped")
+		} else if o.skippedGroups > 0 && o.passedGroups == 0 {
+			// Only skipped tests
+			fmt.Println("All tests were skipped")
+		}
 	}
 
-	// Add random failure exclamation if tests failed
-	if o.failedGroups > 0 {
-		exclamations := []string{
-			"This is madness!",
-			"We're doomed!",
-			"Are you sure this thing is safe?",
-		}
-		randomExclamation := exclamations[time.Now().UnixNano()%int64(len(exclamations))]
-		fmt.Printf("Test failures! %s\n", randomExclamation)
-		// Test details are shown inline with each failing group
-	} else if o.passedGroups > 0 && o.skippedGroups == 0 {
-		// All tests that ran passed (no skips)
-		fmt.Println("Splendid! All tests passed successfully")
-	} else if o.passedGroups > 0 && o.skippedGroups > 0 {
-		// Some tests passed, some were skipped
-		fmt.Println("Tests completed with some skipped")
-	} else if o.skippedGroups > 0 && o.passedGroups == 0 {
-		// Only skipped tests
-		fmt.Println("All tests were skipped")
+	if o.reportManager.AbortReason() != "" {
+		fmt.Printf("Aborted:     %s\n", o.reportManager.AbortReason())
 	}
 
 	// Format results summary
 	// Show test case counts when we have actual test counts with skipped tests
 	// Otherwise show group counts (for compatibility with runners that don't report individual tests)
-	if o.totalTests > 0 && (o.skippedTests > 0 || o.xfailedTests > 0 || o.xpassedTests > 0 || strings.HasPrefix(o.detectedRunner, "cargo")) {
+	flakyTests := o.flakyTests()
+	if o.totalTests > 0 && (o.skippedTests > 0 || o.xfailedTests > 0 || o.xpassedTests > 0 || flakyTests > 0 || strings.HasPrefix(o.detectedRunner, "cargo")) {
 		// Show test case counts
 		// Build the results string dynamically to only include non-zero counts
 		var parts []string
-		parts = append(parts, fmt.Sprintf("%d passed", o.passedTests))
+		parts = append(parts, o.passedCount(o.passedTests))
+		if flakyTests > 0 {
+			parts = append(parts, fmt.Sprintf("%d flaky", flakyTests))
+		}
 		if o.failedTests > 0 {
-			parts = append(parts, fmt.Sprintf("%d failed", o.failedTests))
+			parts = append(parts, o.paint(fmt.Sprintf("%d failed", o.failedTests), ansiRed))
 		}
 		if o.skippedTests > 0 {
 			parts = append(parts, fmt.Sprintf("%d skipped", o.skippedTests))
@@ -649,9 +1163,9 @@ func (o *Orchestrator) Run() error {
 	} else {
 		// Show group counts for other runners or when no test-level detail available
 		var parts []string
-		parts = append(parts, fmt.Sprintf("%d passed", o.passedGroups))
+		parts = append(parts, o.passedCount(o.passedGroups))
 		if o.failedGroups > 0 {
-			parts = append(parts, fmt.Sprintf("%d failed", o.failedGroups))
+			parts = append(parts, o.paint(fmt.Sprintf("%d failed", o.failedGroups), ansiRed))
 		}
 		if o.skippedGroups > 0 {
 			parts = append(parts, fmt.Sprintf("%d skipped", o.skippedGroups))
@@ -669,25 +1183,52 @@ func (o *Orchestrator) Run() error {
 	// Calculate and display elapsed time
 	elapsed := time.Since(o.startTime).Seconds()
 	fmt.Printf("Total time:  %.3fs\n", elapsed)
-
-	// Return command error if there was one
-	if commandErr != nil {
-		return fmt.Errorf("test command failed: %w", commandErr)
+	if o.quiet {
+		fmt.Printf("Report:      %s\n", filepath.Join(o.runDir, "test-run.md"))
 	}
-
-	return nil
 }
 
 // processEvents processes IPC events and displays console output
 func (o *Orchestrator) processEvents() {
 	for event := range o.ipcManager.Events {
+		// A re-run in watch mode reports into a fresh run directory
+		if _, ok := event.(ipc.RunStartEvent); ok && o.watch != nil {
+			o.startWatchRun()
+		}
+
 		// Pass event to report manager FIRST to update state
 		if err := o.reportManager.HandleEvent(event); err != nil {
 			o.logger.Error("Failed to handle event: %v", err)
 		}
 
-		// Then handle console output for different event types
+		// Then handle console output for different event types, keeping the
+		// progress line below it
+		o.consoleMu.Lock()
+		redraw := o.progress != nil && o.progress.clear()
+		if _, ok := event.(ipc.GroupResultEvent); ok && o.progress != nil {
+			o.progress.results = true
+		}
+		o.streamEvent(event)
 		o.handleConsoleOutput(event)
+		if text := o.statusText(); redraw && text != "" {
+			o.progress.draw(text)
+		}
+		o.consoleMu.Unlock()
+
+		// With --fail-fast, the first failing group stops the run
+		if o.failFast {
+			if e, ok := event.(ipc.GroupResultEvent); ok && e.Payload.Status == "FAIL" {
+				o.failFastOnce.Do(func() {
+					o.logger.Debug("Group %s failed, triggering fail-fast", e.Payload.GroupName)
+					close(o.failFastTriggered)
+				})
+			}
+		}
+
+		// In watch mode the command keeps running, so each run is reported as it completes
+		if _, ok := event.(ipc.RunCompleteEvent); ok && o.watch != nil {
+			o.finishWatchRun()
+		}
 	}
 }
 
@@ -708,6 +1249,10 @@ func (o *Orchestrator) normalizePathForReportManager(name string) string {
 	if !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "./") && !strings.Contains(name, "/") {
 		return name
 	}
+	// Scoped package names such as Jest project names (@app/web) aren't paths either
+	if strings.HasPrefix(name, "@") {
+		return name
+	}
 
 	// Convert to absolute path
 	absPath, err := filepath.Abs(name)
@@ -806,24 +1351,37 @@ func (o *Orchestrator) handleConsoleOutput(event ipc.Event) {
 			}
 		}
 
+	case ipc.GroupErrorEvent:
+		if e.Payload.ErrorType == buildFailureErrorType {
+			failure := buildFailure{group: e.Payload.GroupName}
+			if e.Payload.Error != nil {
+				failure.message = e.Payload.Error.Message
+			}
+			o.buildFailures = append(o.buildFailures, failure)
+		}
+
 	case ipc.GroupTestCaseEvent:
-		// Track test case counts
-		o.totalTests++
-		switch e.Payload.Status {
-		case "PASS":
-			o.passedTests++
-		case "FAIL":
-			o.failedTests++
-		case "SKIP":
-			o.skippedTests++
-		case "XFAIL":
-			o.xfailedTests++
-		case "XPASS":
-			o.xpassedTests++
+		// Track test case counts. A test reported again (a retry) replaces its earlier status.
+		key := strings.Join(append(append([]string{}, e.Payload.ParentNames...), e.Payload.TestName), "\x00")
+		outcome, seen := o.testOutcomes[key]
+		if seen {
+			o.countTestStatus(outcome.status, -1)
+			outcome.failedBefore = outcome.failedBefore || outcome.status == "FAIL"
+		} else {
+			outcome = &testOutcome{}
+			o.testOutcomes[key] = outcome
+			o.totalTests++
+		}
+		previousStatus := outcome.status
+		outcome.status = e.Payload.Status
+		o.countTestStatus(outcome.status, 1)
+
+		if o.stream != nil {
+			o.stream.add(e.Payload.Status, o.formatTestCaseLine(e.Payload))
 		}
 
 		// Track failed tests for hierarchical display
-		if e.Payload.Status == "FAIL" {
+		if (e.Payload.Status == "FAIL") != (previousStatus == "FAIL") {
 			// Use the first parent name as file path (should be the file)
 			if len(e.Payload.ParentNames) > 0 {
 				normalizedPath := o.normalizePath(e.Payload.ParentNames[0])
@@ -833,13 +1391,61 @@ func (o *Orchestrator) handleConsoleOutput(event ipc.Event) {
 					suiteNames := e.Payload.ParentNames[1:]
 					testName = strings.Join(suiteNames, " > ") + " > " + testName
 				}
-				o.groupFailedTests[normalizedPath] = append(o.groupFailedTests[normalizedPath], testName)
+				if e.Payload.Status == "FAIL" {
+					o.recordFailedTest(normalizedPath, testName)
+				} else {
+					// Passed on a retry
+					o.forgetFailedTest(normalizedPath, testName)
+				}
 			}
 		}
 
 	}
 }
 
+// testOutcome is the console's record of one test case across its attempts
+type testOutcome struct {
+	status       string
+	failedBefore bool // An earlier attempt failed
+}
+
+// countTestStatus adjusts the console's test case count for a status
+func (o *Orchestrator) countTestStatus(status string, delta int) {
+	switch status {
+	case "PASS":
+		o.passedTests += delta
+	case "FAIL":
+		o.failedTests += delta
+	case "SKIP":
+		o.skippedTests += delta
+	case "XFAIL":
+		o.xfailedTests += delta
+	case "XPASS":
+		o.xpassedTests += delta
+	}
+}
+
+// flakyTests counts test cases that passed after failing on an earlier attempt
+func (o *Orchestrator) flakyTests() int {
+	count := 0
+	for _, outcome := range o.testOutcomes {
+		if outcome.status == "PASS" && outcome.failedBefore {
+			count++
+		}
+	}
+	return count
+}
+
+// removeString returns values without the first occurrence of value
+func removeString(values []string, value string) []string {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
 // extractAdapter extracts the adapter file to a temporary directory
 func (o *Orchestrator) extractAdapter(adapterName string) (string, error) {
 	// Read log level from environment variable for adapter injection
@@ -850,7 +1456,7 @@ func (o *Orchestrator) extractAdapter(adapterName string) (string, error) {
 
 	// Always use embedded adapters in production
 	// Pass IPC path, run directory, and log level for injection
-	embeddedPath, err := adapters.GetAdapterPath(adapterName, o.ipcPath, o.runDir, logLevel)
+	embeddedPath, err := adapters.GetAdapterPath(adapterName, runnerIPCAddress(o.ipcManager.Address()), o.runDir, logLevel)
 	if err != nil {
 		return "", fmt.Errorf("failed to extract embedded adapter %s: %w", adapterName, err)
 	}
@@ -859,6 +1465,88 @@ func (o *Orchestrator) extractAdapter(adapterName string) (string, error) {
 	return embeddedPath, nil
 }
 
+// nativeDefinition returns the definition a native runner's wrapper holds, or nil for
+// adapter-based runners
+func nativeDefinition(runnerDef runner.Definition) interface{} {
+	switch wrapper := runnerDef.(type) {
+	case *definitions.GoTestWrapper:
+		return wrapper.GoTestDefinition
+	case *definitions.CargoTestWrapper:
+		return wrapper.CargoTestDefinition
+	case *definitions.NextestWrapper:
+		return wrapper.NextestDefinition
+	case *definitions.MavenWrapper:
+		return wrapper.MavenDefinition
+	case *definitions.GradleWrapper:
+		return wrapper.GradleDefinition
+	case *definitions.DotnetTestWrapper:
+		return wrapper.DotnetTestDefinition
+	case *definitions.RSpecWrapper:
+		return wrapper.RSpecDefinition
+	case *definitions.PlaywrightWrapper:
+		return wrapper.PlaywrightDefinition
+	case *definitions.TAPWrapper:
+		return wrapper.TAPDefinition
+	}
+	return nil
+}
+
+// newRunDir picks the run ID and sets the run directory and IPC path from it
+func (o *Orchestrator) newRunDir() {
+	if o.runName != "" {
+		o.runID = generateNamedRunID(o.runName)
+	} else {
+		o.runID = generateRunID()
+	}
+	o.runDir = filepath.Join(o.outputDir, "runs", o.runID)
+	o.ipcPath = filepath.Join(o.runDir, "ipc.jsonl")
+}
+
+// enableGinkgo turns on Ginkgo mode for go test under --ginkgo. It adds a report flag to
+// the command, so it comes before the command is built.
+func (o *Orchestrator) enableGinkgo(runnerDef runner.Definition) {
+	if goDef, ok := nativeDefinition(runnerDef).(*definitions.GoTestDefinition); ok && o.ginkgo {
+		goDef.EnableGinkgo()
+	}
+}
+
+// setVitestJSONReport has Vitest's json reporter run alongside ours, in case ours fails
+// to load. Other runners and watch mode are left alone.
+func (o *Orchestrator) setVitestJSONReport(runnerDef runner.Definition) {
+	vitestDef, ok := runnerDef.(*runner.VitestDefinition)
+	if !ok || o.watch != nil {
+		return
+	}
+	if absRunDir, err := filepath.Abs(o.runDir); err == nil {
+		o.vitestJSONReport = filepath.Join(absRunDir, definitions.VitestJSONReportFile)
+		vitestDef.SetJSONReportPath(o.vitestJSONReport)
+	}
+}
+
+// commandEnv returns the environment the test command runs with: the base environment,
+// the IPC address and the variables the runner needs to produce machine-readable output
+func (o *Orchestrator) commandEnv(runnerDef runner.Definition, nativeDef interface{}, ipcAddress string) []string {
+	env := append(o.baseEnv(runnerDef), fmt.Sprintf("THREEPIO_IPC_PATH=%s", runnerIPCAddress(ipcAddress)))
+
+	// Add RUSTC_BOOTSTRAP=1 for cargo test to enable JSON output
+	if len(o.command) >= 2 && o.command[0] == "cargo" && o.command[1] == "test" {
+		env = append(env, "RUSTC_BOOTSTRAP=1")
+		o.logger.Debug("Added RUSTC_BOOTSTRAP=1 for cargo test JSON output")
+	}
+
+	// Add NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1 for cargo nextest to enable JSON output
+	if len(o.command) >= 2 && o.command[0] == "cargo" && o.command[1] == "nextest" {
+		env = append(env, "NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1")
+		o.logger.Debug("Added NEXTEST_EXPERIMENTAL_LIBTEST_JSON=1 for cargo nextest JSON output")
+	}
+
+	// Playwright's JSON reporter takes its output file from the environment
+	if pw, ok := nativeDef.(*definitions.PlaywrightDefinition); ok {
+		env = append(env, pw.ReporterEnv(o.ipcPath)...)
+	}
+	return env
+}
+
 // GetExitCode returns the exit code from the test run
 func (o *Orchestrator) GetExitCode() int {
 	return o.exitCode
@@ -921,6 +1609,10 @@ func (o *Orchestrator) displayGroupResult(groupName string, parentNames []string
 
 // displayGroupHierarchy displays a group and its children with hierarchical indentation
 func (o *Orchestrator) displayGroupHierarchy(group *report.TestGroup, indent int, eventDuration float64) {
+	if o.quiet {
+		return
+	}
+
 	// Only display top-level groups (files) in main output
 	// Subgroups will only be shown if they have failures
 	if len(group.ParentNames) > 0 {
@@ -944,7 +1636,7 @@ func (o *Orchestrator) displayGroupHierarchy(group *report.TestGroup, indent int
 			// Build status string
 			var statusParts []string
 			if isFailed {
-				statusParts = append(statusParts, "FAIL")
+				statusParts = append(statusParts, o.paint("FAIL", ansiRed))
 			}
 			if isNoTests {
 				statusParts = append(statusParts, "NO_TESTS")
@@ -1010,12 +1702,12 @@ func (o *Orchestrator) displayGroupHierarchy(group *report.TestGroup, indent int
 		} else {
 			// Add FAIL count if there are failures
 			if group.Stats.FailedTestsRecursive > 0 {
-				statusParts = append(statusParts, fmt.Sprintf("FAIL(%d)", group.Stats.FailedTestsRecursive))
+				statusParts = append(statusParts, o.paint(fmt.Sprintf("FAIL(%d)", group.Stats.FailedTestsRecursive), ansiRed))
 			}
 
 			// Add PASS count only if > 0
 			if group.Stats.PassedTestsRecursive > 0 {
-				statusParts = append(statusParts, fmt.Sprintf("PASS(%d)", group.Stats.PassedTestsRecursive))
+				statusParts = append(statusParts, o.paint(fmt.Sprintf("PASS(%d)", group.Stats.PassedTestsRecursive), ansiGreen))
 			}
 
 			// Add SKIP count only if > 0
@@ -1083,7 +1775,6 @@ func (o *Orchestrator) collectFailedTests(group *report.TestGroup) []string {
 }
 
 // formatElapsedTime formats the elapsed time from start in a progressive display
-// nolint:unused // elapsed time no longer printed; keep for future use
 func (o *Orchestrator) formatElapsedTime() string {
 	elapsed := time.Since(o.startTime)
 	totalSeconds := int(elapsed.Seconds())
@@ -1244,3 +1935,16 @@ func generateRunID() string {
 
 	return fmt.Sprintf("%s-%s-%s", timestamp, adjectives[adjIdx], characters[charIdx])
 }
+
+// runNameUnsafeChars matches characters that aren't safe in a run directory name
+var runNameUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// generateNamedRunID generates a run identifier using a caller-supplied name instead of the random suffix.
+// Falls back to generateRunID if the name is empty after sanitizing.
+func generateNamedRunID(name string) string {
+	sanitized := strings.Trim(runNameUnsafeChars.ReplaceAllString(name, "-"), "-.")
+	if sanitized == "" {
+		return generateRunID()
+	}
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102T150405"), sanitized)
+}
@@ -1,15 +1,18 @@
 package orchestrator
 
 import (
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/zk/3pio/internal/logger"
+	"github.com/zk/3pio/internal/report"
 )
 
 func TestOrchestrator_New(t *testing.T) {
@@ -477,3 +480,595 @@ func TestOutputFileRaceCondition(t *testing.T) {
 	// but we don't want to fail the test if the race doesn't occur
 	t.Log("Race condition did not occur in this test run (timing dependent)")
 }
+
+func TestGenerateNamedRunID(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantSuffix string
+	}{
+		{name: "plain name", input: "job-1234", wantSuffix: "-job-1234"},
+		{name: "path separators", input: "feature/ci run", wantSuffix: "-feature-ci-run"},
+		{name: "parent directory", input: "../../etc", wantSuffix: "-etc"},
+		{name: "dots and underscores kept", input: "v1.2_rc", wantSuffix: "-v1.2_rc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runID := generateNamedRunID(tt.input)
+			if !strings.HasSuffix(runID, tt.wantSuffix) {
+				t.Errorf("Expected run ID ending in %q, got %q", tt.wantSuffix, runID)
+			}
+			if !runDirNameRegex.MatchString(runID) {
+				t.Errorf("Expected run ID to keep the timestamp prefix, got %q", runID)
+			}
+			if strings.ContainsAny(runID, "/\\ ") {
+				t.Errorf("Run ID contains path-unsafe characters: %q", runID)
+			}
+		})
+	}
+
+	// Names that sanitize to nothing fall back to a random name
+	if runID := generateNamedRunID("///"); strings.Count(runID, "-") < 2 {
+		t.Errorf("Expected fallback to random run ID, got %q", runID)
+	}
+}
+
+// installFakeGo puts a shell script named go first on PATH
+func installFakeGo(t *testing.T, script string) {
+	t.Helper()
+	installFakeBinary(t, "go", script)
+}
+
+// installFakeBinary puts a shell script with the given name first on PATH
+func installFakeBinary(t *testing.T, name, script string) {
+	t.Helper()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake %s binary: %v", name, err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestOrchestrator_FailFast(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// A fake go binary reports a failing package, then hangs as if more packages were still running
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/slow","Test":"TestBroken"}'
+echo '{"Action":"fail","Package":"example.com/slow","Test":"TestBroken","Elapsed":0.01}'
+echo '{"Action":"fail","Package":"example.com/slow","Elapsed":0.02}'
+exec sleep 30
+`)
+
+	outputDir := filepath.Join(t.TempDir(), ".3pio")
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: outputDir,
+		Quiet:     true,
+		FailFast:  true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	start := time.Now()
+	_ = orch.Run()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Expected fail-fast to stop the run early, took %v", elapsed)
+	}
+	if orch.GetExitCode() == 0 {
+		t.Error("Expected a non-zero exit code after fail-fast")
+	}
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary struct {
+		Status      string `json:"status"`
+		AbortReason string `json:"abortReason"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v", err)
+	}
+	if summary.Status != "COMPLETE" || summary.AbortReason != failFastAbortReason {
+		t.Errorf("Expected a completed run with the fail-fast note, got %+v", summary)
+	}
+
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "## Aborted") {
+		t.Errorf("Expected test-run.md to note the aborted run:\n%s", report)
+	}
+}
+
+func TestOrchestrator_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// One package passes, then the fake go binary hangs
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+exec sleep 30
+`)
+
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+		Timeout:   500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	start := time.Now()
+	_ = orch.Run()
+	elapsed := time.Since(start)
+	if elapsed < 500*time.Millisecond || elapsed > 10*time.Second {
+		t.Fatalf("Expected the run to be killed at the 500ms deadline, took %v", elapsed)
+	}
+	if orch.GetExitCode() != TimeoutExitCode {
+		t.Errorf("Expected exit code %d, got %d", TimeoutExitCode, orch.GetExitCode())
+	}
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary struct {
+		ExitCode    int    `json:"exitCode"`
+		AbortReason string `json:"abortReason"`
+		Counts      struct {
+			Passed int `json:"passed"`
+		} `json:"counts"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v", err)
+	}
+	if summary.ExitCode != TimeoutExitCode || !strings.Contains(summary.AbortReason, "timeout") {
+		t.Errorf("Expected the timeout to be recorded, got %+v", summary)
+	}
+	// Results reported before the deadline are kept
+	if summary.Counts.Passed != 1 {
+		t.Errorf("Expected the passing test to be kept, got %+v", summary)
+	}
+}
+
+func TestOrchestrator_NoOutputLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// The hanging child keeps the output pipe open after the fake go binary is killed
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+sleep 30 &
+wait
+`)
+
+	orch, err := New(Config{
+		Command:     []string{"go", "test", "./..."},
+		Logger:      logger.NewTestLogger(),
+		OutputDir:   filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:       true,
+		Timeout:     500 * time.Millisecond,
+		NoOutputLog: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	start := time.Now()
+	_ = orch.Run()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Expected the run to stop at the deadline, took %v", elapsed)
+	}
+
+	if _, err := os.Stat(filepath.Join(orch.runDir, "output.log")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output.log with NoOutputLog, got stat error %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary struct {
+		Counts struct {
+			Passed int `json:"passed"`
+		} `json:"counts"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v", err)
+	}
+	// The go test output was still parsed from the pipe
+	if summary.Counts.Passed != 1 {
+		t.Errorf("Expected the passing test to be reported, got %+v", summary)
+	}
+}
+
+func TestOrchestrator_SeparateStreams(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cargo binary is a shell script")
+	}
+
+	// cargo prints which test binary runs on stderr, and the binary's JSON on stdout
+	installFakeBinary(t, "cargo", `#!/bin/sh
+[ "$1" = "metadata" ] && exit 1
+echo '     Running unittests src/lib.rs (target/debug/deps/mycrate-0123abcd)' >&2
+sleep 0.2
+echo '{"type":"suite","event":"started","test_count":1}'
+echo '{"type":"test","event":"started","name":"tests::works"}'
+echo '{"type":"test","name":"tests::works","event":"ok","exec_time":0.001}'
+echo '{"type":"suite","event":"ok","passed":1,"failed":0,"ignored":0,"measured":0,"filtered_out":0,"exec_time":0.001}'
+echo 'warning: done' >&2
+`)
+
+	orch, err := New(Config{
+		Command:         []string{"cargo", "test"},
+		Logger:          logger.NewTestLogger(),
+		OutputDir:       filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:           true,
+		SeparateStreams: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+
+	stderrLog, err := os.ReadFile(filepath.Join(orch.runDir, "stderr.log"))
+	if err != nil {
+		t.Fatalf("Expected stderr.log to be written: %v", err)
+	}
+	if !strings.Contains(string(stderrLog), "Running unittests") || !strings.Contains(string(stderrLog), "warning: done") {
+		t.Errorf("Expected both stderr lines in stderr.log, got:\n%s", stderrLog)
+	}
+	if strings.Contains(string(stderrLog), `"type":"suite"`) {
+		t.Errorf("Expected no stdout in stderr.log, got:\n%s", stderrLog)
+	}
+
+	// The parser still saw the stderr line naming the crate
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "| PASS | mycrate |") {
+		t.Errorf("Expected the test to be grouped under its crate:\n%s", report)
+	}
+}
+
+func TestOrchestrator_VitestJSONReportFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake vitest binary is a shell script")
+	}
+
+	// The 3pio reporter never loads, so only Vitest's json reporter writes results
+	installFakeBinary(t, "vitest", `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --outputFile.json=*) out="${arg#--outputFile.json=}" ;;
+  esac
+done
+cat > "$out" <<'JSON'
+{"testResults":[{"name":"/src/math.test.ts","status":"failed","startTime":1000,"endTime":1250,"assertionResults":[
+{"ancestorTitles":["math"],"title":"adds","status":"passed","duration":3},
+{"ancestorTitles":["math"],"title":"divides","status":"failed","duration":5,"failureMessages":["expected 2 to be 3"]}]}]}
+JSON
+exit 1
+`)
+
+	orch, err := New(Config{
+		Command:   []string{"vitest", "run"},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary report.RunSummary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("Failed to parse run.json: %v", err)
+	}
+	if summary.Counts.Passed != 1 || summary.Counts.Failed != 1 {
+		t.Errorf("Expected the JSON report's 1 passed and 1 failed test, got %+v", summary.Counts)
+	}
+	if len(summary.FailedTests) != 1 || summary.FailedTests[0].ErrorMessage != "expected 2 to be 3" {
+		t.Errorf("Expected divides to fail with its message, got %+v", summary.FailedTests)
+	}
+
+	// The recovered events are in ipc.jsonl, so the run can be regenerated
+	ipcLog, err := os.ReadFile(filepath.Join(orch.runDir, "ipc.jsonl"))
+	if err != nil || !strings.Contains(string(ipcLog), `"testName":"divides"`) {
+		t.Errorf("Expected the recovered events in ipc.jsonl, got %q (%v)", ipcLog, err)
+	}
+}
+
+func TestOrchestrator_PytestNoTestsCollected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake pytest binary is a shell script")
+	}
+
+	installFakeBinary(t, "pytest", `#!/bin/sh
+echo 'no tests ran in 0.01s'
+exit 5
+`)
+
+	orch, err := New(Config{
+		Command:   []string{"pytest", "tests/"},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var summary struct {
+		ExitCode   int    `json:"exitCode"`
+		ExitReason string `json:"exitReason"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v", err)
+	}
+	if summary.ExitCode != 5 || summary.ExitReason != ExitReasonNoTestsRan {
+		t.Errorf("Expected exit code 5 with reason %s, got %+v", ExitReasonNoTestsRan, summary)
+	}
+
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "No tests were collected (pytest exited with code 5)") {
+		t.Errorf("Expected test-run.md to explain the exit code:\n%s", report)
+	}
+}
+
+func TestOrchestrator_ContaminatedIPC(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// The code under test appends its own lines to the IPC file it inherited
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo 'debug: connecting to db' >> "$THREEPIO_IPC_PATH"
+echo 'debug: connected' >> "$THREEPIO_IPC_PATH"
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+`)
+
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "## Warnings\n\n- 2 of ") || !strings.Contains(string(report), "THREEPIO_IPC_PATH") {
+		t.Errorf("Expected a warning about the contaminated IPC file:\n%s", report)
+	}
+}
+
+func TestOrchestrator_NewerIPCSchema(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// Events written by a newer 3pio declare a schema version this build doesn't know
+	installFakeGo(t, `#!/bin/sh
+echo '{"eventType":"schema","payload":{"version":99}}' >> "$THREEPIO_IPC_PATH"
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+`)
+
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Expected test-run.md to be written: %v", err)
+	}
+	if !strings.Contains(string(report), "IPC schema version 99") || !strings.Contains(string(report), "Test cases passed: 1") {
+		t.Errorf("Expected the results with a warning about the newer schema:\n%s", report)
+	}
+	if orch.GetExitCode() != 0 {
+		t.Errorf("Expected a newer schema to keep exit code 0, got %d", orch.GetExitCode())
+	}
+}
+
+func TestOrchestrator_NoEventsReceived(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	// A runner that passes without reporting anything, as when the adapter never loads
+	installFakeGo(t, "#!/bin/sh\necho 'all good'\n")
+
+	for _, allowNoEvents := range []bool{false, true} {
+		orch, err := New(Config{
+			Command:       []string{"go", "test", "./..."},
+			Logger:        logger.NewTestLogger(),
+			OutputDir:     filepath.Join(t.TempDir(), ".3pio"),
+			Quiet:         true,
+			AllowNoEvents: allowNoEvents,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create orchestrator: %v", err)
+		}
+		if err := orch.Run(); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+
+		report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+		if err != nil {
+			t.Fatalf("Expected test-run.md to be written: %v", err)
+		}
+		if !strings.Contains(string(report), "3pio --list-runners go test ./...") {
+			t.Errorf("Expected a warning suggesting --list-runners:\n%s", report)
+		}
+		hasReason := strings.Contains(string(report), "exit_reason: "+ExitReasonNoEventsReceived)
+		if !allowNoEvents && (orch.GetExitCode() != 1 || !hasReason) {
+			t.Errorf("Expected a run without events to fail with %s, got exit code %d:\n%s", ExitReasonNoEventsReceived, orch.GetExitCode(), report)
+		}
+		if allowNoEvents && (orch.GetExitCode() != 0 || hasReason) {
+			t.Errorf("Expected --allow-no-events to keep exit code 0, got %d:\n%s", orch.GetExitCode(), report)
+		}
+	}
+}
+
+func TestOrchestrator_FailOnSkip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/db","Test":"TestQuery"}'
+echo '{"Action":"pass","Package":"example.com/db","Test":"TestQuery","Elapsed":0.01}'
+echo '{"Action":"run","Package":"example.com/db","Test":"TestMigrate"}'
+echo '{"Action":"skip","Package":"example.com/db","Test":"TestMigrate","Elapsed":0}'
+echo '{"Action":"pass","Package":"example.com/db","Elapsed":0.02}'
+`)
+
+	for _, failOnSkip := range []bool{false, true} {
+		orch, err := New(Config{
+			Command:    []string{"go", "test", "./..."},
+			Logger:     logger.NewTestLogger(),
+			OutputDir:  filepath.Join(t.TempDir(), ".3pio"),
+			Quiet:      true,
+			FailOnSkip: failOnSkip,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create orchestrator: %v", err)
+		}
+		if err := orch.Run(); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+
+		report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+		if err != nil {
+			t.Fatalf("Expected test-run.md to be written: %v", err)
+		}
+		hasReason := strings.Contains(string(report), "exit_reason: "+ExitReasonSkippedNotAllowed)
+		if !failOnSkip && (orch.GetExitCode() != 0 || hasReason) {
+			t.Errorf("Expected a skipped test to pass without --fail-on-skip, got exit code %d:\n%s", orch.GetExitCode(), report)
+		}
+		if failOnSkip && (orch.GetExitCode() != 1 || !hasReason) {
+			t.Errorf("Expected --fail-on-skip to fail with %s, got exit code %d:\n%s", ExitReasonSkippedNotAllowed, orch.GetExitCode(), report)
+		}
+	}
+}
+
+func TestOrchestrator_FailUnder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go binary is a shell script")
+	}
+
+	tests := []struct {
+		desc       string
+		script     string
+		failUnder  float64
+		allowEmpty bool
+		wantExit   int
+		wantReason string
+	}{
+		{"3 of 4 passed meets 75%", threeOfFourScript, 75, false, 0, ""},
+		{"3 of 4 passed is below 80%", threeOfFourScript, 80, false, 1, ExitReasonBelowThreshold},
+		{"no test cases", noTestFilesScript, 50, false, 1, ExitReasonBelowThreshold},
+		{"no test cases with --fail-under-allow-empty", noTestFilesScript, 50, true, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			installFakeGo(t, tt.script)
+			orch, err := New(Config{
+				Command:        []string{"go", "test", "./..."},
+				Logger:         logger.NewTestLogger(),
+				OutputDir:      filepath.Join(t.TempDir(), ".3pio"),
+				Quiet:          true,
+				FailUnder:      tt.failUnder,
+				FailUnderEmpty: tt.allowEmpty,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create orchestrator: %v", err)
+			}
+			// The command's own failure is not an error once --fail-under passed the run
+			if err := orch.Run(); err != nil && tt.wantExit == 0 {
+				t.Errorf("Expected no error for a run --fail-under passed, got %v", err)
+			}
+
+			report, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+			if err != nil {
+				t.Fatalf("Expected test-run.md to be written: %v", err)
+			}
+			if orch.GetExitCode() != tt.wantExit {
+				t.Errorf("Expected exit code %d, got %d:\n%s", tt.wantExit, orch.GetExitCode(), report)
+			}
+			hasReason := strings.Contains(string(report), "exit_reason: "+ExitReasonBelowThreshold)
+			if hasReason != (tt.wantReason != "") {
+				t.Errorf("Expected exit reason %q:\n%s", tt.wantReason, report)
+			}
+		})
+	}
+}
+
+// threeOfFourScript is a go test run where one of four tests fails
+const threeOfFourScript = `#!/bin/sh
+for name in TestA TestB TestC; do
+  echo "{\"Action\":\"run\",\"Package\":\"example.com/shop\",\"Test\":\"$name\"}"
+  echo "{\"Action\":\"pass\",\"Package\":\"example.com/shop\",\"Test\":\"$name\",\"Elapsed\":0.01}"
+done
+echo '{"Action":"run","Package":"example.com/shop","Test":"TestD"}'
+echo '{"Action":"fail","Package":"example.com/shop","Test":"TestD","Elapsed":0.01}'
+echo '{"Action":"fail","Package":"example.com/shop","Elapsed":0.02}'
+exit 1
+`
+
+// noTestFilesScript is a go test run of a package without tests
+const noTestFilesScript = `#!/bin/sh
+echo '{"Action":"start","Package":"example.com/docs"}'
+echo '{"Action":"output","Package":"example.com/docs","Output":"?   \texample.com/docs\t[no test files]\n"}'
+echo '{"Action":"skip","Package":"example.com/docs","Elapsed":0}'
+`
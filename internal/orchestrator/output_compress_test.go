@@ -0,0 +1,69 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestOrchestrator_CompressOutput(t *testing.T) {
+	// go test results are tailed from output.log while the command runs, so they must
+	// all be read before it is compressed
+	installFakeGo(t, `#!/bin/sh
+echo '{"Action":"run","Package":"example.com/calc","Test":"TestAdd"}'
+echo '{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"adding\n"}'
+echo '{"Action":"pass","Package":"example.com/calc","Test":"TestAdd","Elapsed":0.01}'
+echo '{"Action":"run","Package":"example.com/calc","Test":"TestDiv"}'
+echo '{"Action":"fail","Package":"example.com/calc","Test":"TestDiv","Elapsed":0.01}'
+echo '{"Action":"fail","Package":"example.com/calc","Elapsed":0.02}'
+exit 1
+`)
+	orch, err := New(Config{
+		Command:        []string{"go", "test", "./..."},
+		Logger:         logger.NewTestLogger(),
+		OutputDir:      filepath.Join(t.TempDir(), ".3pio"),
+		Quiet:          true,
+		CompressOutput: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+
+	summary := readRunSummary(t, orch.runDir)
+	if summary.Counts.Total != 2 || summary.Counts.Failed != 1 {
+		t.Errorf("Expected both tests from the tailed output, got %+v", summary.Counts)
+	}
+
+	if _, err := os.Stat(filepath.Join(orch.runDir, "output.log")); !os.IsNotExist(err) {
+		t.Errorf("Expected output.log to be replaced by output.log.gz, got %v", err)
+	}
+	f, err := os.Open(filepath.Join(orch.runDir, "output.log.gz"))
+	if err != nil {
+		t.Fatalf("Expected output.log.gz: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("output.log.gz is not gzipped: %v", err)
+	}
+	output, err := io.ReadAll(gz)
+	if err != nil || !strings.Contains(string(output), `"Test":"TestDiv"`) {
+		t.Errorf("Expected the command's output in output.log.gz, got %q (%v)", output, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(orch.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	if !strings.Contains(string(content), "- Run stdout/stderr: `./output.log.gz`") {
+		t.Errorf("Expected test-run.md to point at output.log.gz:\n%s", content)
+	}
+}
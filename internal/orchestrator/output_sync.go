@@ -0,0 +1,49 @@
+package orchestrator
+
+import "time"
+
+// outputSyncInterval is how often --sync-output flushes output.log to disk
+const outputSyncInterval = time.Second
+
+// syncer is a file that can be flushed to disk
+type syncer interface {
+	Sync() error
+}
+
+// startOutputSync flushes output.log to disk every outputSyncInterval with --sync-output,
+// until the returned function is called. The command writes to the file directly, so its
+// output is never held in 3pio's memory; the flushes keep it on disk through a machine
+// crash or power loss, at the cost of a disk flush every interval.
+func (o *Orchestrator) startOutputSync(file syncer) func() {
+	if !o.syncOutput {
+		return func() {}
+	}
+	o.logger.Debug("Flushing output.log to disk every %v (--sync-output)", outputSyncInterval)
+	return syncPeriodically(file, outputSyncInterval, o.logger)
+}
+
+// syncPeriodically calls file.Sync every interval until the returned function is called
+func syncPeriodically(file syncer, interval time.Duration, log Logger) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := file.Sync(); err != nil {
+					log.Debug("Failed to sync output file: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
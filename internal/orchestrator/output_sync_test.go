@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// countingSyncer counts the calls to Sync
+type countingSyncer struct {
+	syncs atomic.Int32
+}
+
+func (s *countingSyncer) Sync() error {
+	s.syncs.Add(1)
+	return nil
+}
+
+func TestSyncPeriodically(t *testing.T) {
+	file := &countingSyncer{}
+	stop := syncPeriodically(file, time.Millisecond, logger.NewTestLogger())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for file.syncs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	if file.syncs.Load() < 3 {
+		t.Fatalf("Expected output to be synced repeatedly, got %d syncs", file.syncs.Load())
+	}
+
+	// No syncs after stop returns, when the caller closes the file
+	stopped := file.syncs.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := file.syncs.Load(); got != stopped {
+		t.Errorf("Expected no syncs after stop, got %d more", got-stopped)
+	}
+}
+
+func TestStartOutputSync_Disabled(t *testing.T) {
+	o := &Orchestrator{logger: logger.NewTestLogger()}
+	file := &countingSyncer{}
+	stop := o.startOutputSync(file)
+	stop()
+	if file.syncs.Load() != 0 {
+		t.Errorf("Expected no syncs without --sync-output, got %d", file.syncs.Load())
+	}
+}
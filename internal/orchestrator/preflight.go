@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// runPreflight counts the tests the command will run before starting it (--preflight) and
+// sends the total as a collectionFinish event, so progress is known from the first result.
+// Only pytest supports it. Any failure is logged and the run goes ahead without a total.
+func (o *Orchestrator) runPreflight(runnerDef runner.Definition) {
+	pytest, ok := runnerDef.(*runner.PytestDefinition)
+	if !ok {
+		o.logger.Info("Skipping preflight: only supported for pytest, detected %s", o.detectedRunner)
+		return
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		o.logger.Error("Skipping preflight: failed to get working directory: %v", err)
+		return
+	}
+
+	start := time.Now()
+	count, err := pytest.CollectTestCount(dir, o.baseEnv(runnerDef), o.command)
+	if err != nil {
+		// Collection errors are reported again by the real run, with their details
+		o.logger.Info("Preflight collection: %v", err)
+	}
+	if count == 0 {
+		return
+	}
+	o.logger.Info("Preflight collected %d tests in %v", count, time.Since(start))
+
+	var event ipc.CollectionFinishEvent
+	event.EventType = ipc.EventTypeCollectionFinish
+	event.Payload.Collected = count
+	if err := ipc.AppendEvent(o.ipcManager.Address(), event); err != nil {
+		o.logger.Error("Failed to send preflight collection count: %v", err)
+		return
+	}
+
+	if !o.quiet {
+		fmt.Printf("Collected %d tests.\n\n", count)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup makes the command the leader of a new process group, so that
+// killCommand also reaches the processes it starts, such as the test binaries of go test.
+// The group is not the terminal's foreground group: Ctrl+C only reaches 3pio.
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killCommand kills a started command, along with its process group when it leads one
+func killCommand(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err == nil {
+			return nil
+		}
+	}
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,89 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// processGone reports whether pid has exited; a zombie nobody reaped counts as exited
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return true
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	return err == nil && strings.Contains(string(stat), ") Z ")
+}
+
+func TestOrchestrator_TimeoutKillsProcessGroup(t *testing.T) {
+	// Like go test, the fake go binary runs the tests in a child process
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	installFakeGo(t, `#!/bin/sh
+[ "$1" = version ] && exit 0
+echo '{"Action":"run","Package":"example.com/fast","Test":"TestQuick"}'
+echo '{"Action":"pass","Package":"example.com/fast","Test":"TestQuick","Elapsed":0.01}'
+echo '{"Action":"pass","Package":"example.com/fast","Elapsed":0.02}'
+sleep 30 &
+echo $! > `+pidFile+`
+wait
+`)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	captured := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		captured <- buf.String()
+	}()
+
+	orch, err := New(Config{
+		Command:   []string{"go", "test", "./..."},
+		Logger:    logger.NewTestLogger(),
+		OutputDir: filepath.Join(t.TempDir(), ".3pio"),
+		Timeout:   500 * time.Millisecond,
+	})
+	if err != nil {
+		os.Stdout = old
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	_ = orch.Run()
+	_ = w.Close()
+	os.Stdout = old
+	output := <-captured
+
+	if orch.GetExitCode() != TimeoutExitCode {
+		t.Errorf("Expected exit code %d, got %d", TimeoutExitCode, orch.GetExitCode())
+	}
+	if strings.Contains(output, "Splendid") || !strings.Contains(output, "Test run timed out!") {
+		t.Errorf("Expected a timed out run not to report success:\n%s", output)
+	}
+
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("Expected the child to write its pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		t.Fatalf("Invalid pid %q: %v", content, err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !processGone(pid) {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("Expected the timeout to kill the command's child process %d", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
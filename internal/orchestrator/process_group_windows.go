@@ -0,0 +1,11 @@
+package orchestrator
+
+import "os/exec"
+
+// startProcessGroup does nothing: Windows has no process groups to kill at once
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// killCommand kills the command's process; processes it started keep running
+func killCommand(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// progressInterval is how often the progress line is redrawn on a terminal
+	progressInterval = time.Second
+	// progressLogInterval is how often a progress line is printed when stdout is not a terminal
+	progressLogInterval = 10 * time.Second
+	// heartbeatLogInterval is how often the heartbeat is printed when stdout is not a terminal
+	heartbeatLogInterval = 30 * time.Second
+)
+
+// spinnerFrames are drawn in turn, one per second, by the heartbeat on a terminal
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progressLine shows how many of the collected tests have finished during a run. On a
+// terminal the line is redrawn in place with \r; otherwise each update is a new line.
+type progressLine struct {
+	out   io.Writer
+	tty   bool
+	shown bool // The line is on screen without a trailing newline (terminal only)
+
+	frame   int  // Spinner frame of the heartbeat
+	results bool // A group result has arrived, which ends the heartbeat
+}
+
+// draw prints text as the current progress
+func (p *progressLine) draw(text string) {
+	if !p.tty {
+		_, _ = fmt.Fprintln(p.out, text)
+		return
+	}
+	_, _ = fmt.Fprintf(p.out, "\r\033[K%s", text)
+	p.shown = true
+}
+
+// clear erases the line on a terminal so other output can be printed in its place.
+// It returns whether there was a line to erase.
+func (p *progressLine) clear() bool {
+	if !p.shown {
+		return false
+	}
+	_, _ = fmt.Fprint(p.out, "\r\033[K")
+	p.shown = false
+	return true
+}
+
+// progressText returns the progress line, e.g. "[T+ 12s] 145/340 tests (3 failed)", or ""
+// until the runner or --preflight has reported how many tests were collected.
+// Caller must hold o.consoleMu.
+func (o *Orchestrator) progressText() string {
+	if o.lastCollected == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %d/%d tests (%d failed)", o.formatElapsedTime(), o.totalTests, o.lastCollected, o.failedTests)
+}
+
+// heartbeatText returns the line shown until the first group result arrives, so a
+// suite that is still building or starting up doesn't look hung, e.g.
+// "/ [T+ 12s] Waiting for test results...". It returns "" after the first group result.
+// Caller must hold o.consoleMu.
+func (o *Orchestrator) heartbeatText() string {
+	if o.progress == nil || o.progress.results {
+		return ""
+	}
+	if !o.progress.tty {
+		return fmt.Sprintf("%s Still waiting for test results...", o.formatElapsedTime())
+	}
+	return fmt.Sprintf("%s %s Waiting for test results...", spinnerFrames[o.progress.frame%len(spinnerFrames)], o.formatElapsedTime())
+}
+
+// statusText returns the progress line, or the heartbeat until the test count is known.
+// Caller must hold o.consoleMu.
+func (o *Orchestrator) statusText() string {
+	if text := o.progressText(); text != "" {
+		return text
+	}
+	return o.heartbeatText()
+}
+
+// startProgress prints the progress line periodically until the returned function is
+// called, which also erases the line from the terminal. Until the first group result, a
+// heartbeat is printed instead: every second on a terminal, every 30 seconds otherwise.
+func (o *Orchestrator) startProgress() func() {
+	if o.progress == nil {
+		return func() {}
+	}
+	interval := progressLogInterval
+	if o.progress.tty {
+		interval = progressInterval
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for ticks := 1; ; ticks++ {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				o.consoleMu.Lock()
+				o.progress.frame++
+				text := o.progressText()
+				if text == "" && (o.progress.tty || ticks%int(heartbeatLogInterval/interval) == 0) {
+					text = o.heartbeatText()
+				}
+				if text != "" {
+					o.progress.draw(text)
+				}
+				o.consoleMu.Unlock()
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		o.consoleMu.Lock()
+		o.progress.clear()
+		o.consoleMu.Unlock()
+	}
+}
@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProgressText(t *testing.T) {
+	o := &Orchestrator{startTime: time.Now().Add(-12 * time.Second)}
+	if text := o.progressText(); text != "" {
+		t.Errorf("Expected no progress before a test count is known, got %q", text)
+	}
+
+	o.lastCollected = 340
+	o.totalTests = 145
+	o.failedTests = 3
+	if text, want := o.progressText(), "[T+ 12s] 145/340 tests (3 failed)"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+}
+
+func TestHeartbeatText(t *testing.T) {
+	o := &Orchestrator{startTime: time.Now().Add(-5 * time.Second)}
+	if text := o.heartbeatText(); text != "" {
+		t.Errorf("Expected no heartbeat without a progress line, got %q", text)
+	}
+
+	o.progress = &progressLine{tty: true, frame: 1}
+	if text, want := o.statusText(), "/ [T+ 5s] Waiting for test results..."; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+	o.progress.tty = false
+	if text, want := o.statusText(), "[T+ 5s] Still waiting for test results..."; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+
+	// The test count replaces the heartbeat, and the first group result ends it
+	o.lastCollected = 10
+	if text, want := o.statusText(), "[T+ 5s] 0/10 tests (0 failed)"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+	o.lastCollected = 0
+	o.progress.results = true
+	if text := o.statusText(); text != "" {
+		t.Errorf("Expected no heartbeat after the first group result, got %q", text)
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	var out bytes.Buffer
+	terminal := &progressLine{out: &out, tty: true}
+	terminal.draw("1/2 tests")
+	terminal.draw("2/2 tests")
+	if !terminal.clear() {
+		t.Error("Expected clear to report the line that was shown")
+	}
+	if terminal.clear() {
+		t.Error("Expected nothing to clear the second time")
+	}
+	if want := "\r\033[K1/2 tests\r\033[K2/2 tests\r\033[K"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	log := &progressLine{out: &out}
+	log.draw("1/2 tests")
+	if log.clear() {
+		t.Error("Expected no line to clear without a terminal")
+	}
+	if want := "1/2 tests\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
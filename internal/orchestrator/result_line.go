@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+// resultLinePrefix starts the last line 3pio prints, so tools can find it with grep or tail -1
+const resultLinePrefix = "3pio-result:"
+
+// The failed tests block on stderr starts and ends with these lines
+const (
+	failedTestsStart = "--- FAILED TESTS ---"
+	failedTestsEnd   = "--- END ---"
+)
+
+// formatResultLine formats the machine-readable result line. Counts are test cases, the
+// same as run.json's, and the report path comes last since it may contain spaces.
+func formatResultLine(counts report.RunSummaryCounts, exitCode int, reportPath string) string {
+	return fmt.Sprintf("%s passed=%d failed=%d skipped=%d total=%d exit=%d report=%s",
+		resultLinePrefix, counts.Passed, counts.Failed, counts.Skipped, counts.Total, exitCode, reportPath)
+}
+
+// printResultLine prints the result line for the current run
+func (o *Orchestrator) printResultLine(exitCode int) {
+	fmt.Println(formatResultLine(o.reportManager.RunCounts(), exitCode, filepath.Join(o.runDir, "test-run.md")))
+}
+
+// formatFailedTests formats the failed tests block: one test per line between the start
+// and end lines, in the order given
+func formatFailedTests(names []string) string {
+	return failedTestsStart + "\n" + strings.Join(names, "\n") + "\n" + failedTestsEnd + "\n"
+}
+
+// printFailedTests prints the run's failed tests, sorted, to stderr, so tools can read
+// them without parsing the reports. Nothing is printed when no test failed.
+func (o *Orchestrator) printFailedTests() {
+	names := o.reportManager.FailedTestNames()
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprint(os.Stderr, formatFailedTests(names))
+}
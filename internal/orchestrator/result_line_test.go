@@ -0,0 +1,24 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+func TestFormatResultLine(t *testing.T) {
+	counts := report.RunSummaryCounts{Total: 13, Passed: 10, Failed: 2, Skipped: 1, Groups: 4}
+	line := formatResultLine(counts, 1, ".3pio/runs/20250101T100000-grumpy-yoda/test-run.md")
+	want := "3pio-result: passed=10 failed=2 skipped=1 total=13 exit=1 report=.3pio/runs/20250101T100000-grumpy-yoda/test-run.md"
+	if line != want {
+		t.Errorf("formatResultLine() = %q, want %q", line, want)
+	}
+}
+
+func TestFormatFailedTests(t *testing.T) {
+	block := formatFailedTests([]string{"src/app.test.js > renders", "src/math.test.js > math > adds"})
+	want := "--- FAILED TESTS ---\nsrc/app.test.js > renders\nsrc/math.test.js > math > adds\n--- END ---\n"
+	if block != want {
+		t.Errorf("formatFailedTests() = %q, want %q", block, want)
+	}
+}
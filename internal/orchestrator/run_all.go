@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+// CommandSeparator separates the test commands of a combined run on the command line
+// (3pio go test ./... --and npm test)
+const CommandSeparator = "--and"
+
+// SplitCommands splits a command line at each CommandSeparator. A single command is
+// returned as is; empty commands are dropped.
+func SplitCommands(args []string) [][]string {
+	var commands [][]string
+	start := 0
+	for i := 0; i <= len(args); i++ {
+		if i < len(args) && args[i] != CommandSeparator {
+			continue
+		}
+		if i > start {
+			commands = append(commands, args[start:i])
+		}
+		start = i + 1
+	}
+	return commands
+}
+
+// commandRun is how one command of a combined run ended
+type commandRun struct {
+	name     string
+	runner   string // Detected runner, empty when detection failed
+	runDir   string
+	report   *report.Manager // nil when the command never ran
+	exitCode int
+	err      error
+}
+
+// RunCommands runs several test commands one after another, each detected and reported
+// as a run of its own, as if it had been run alone. A combined run then reports every
+// command's groups under a top-level group named after the command, and becomes the
+// latest run. The exit code is the highest of the commands'. --fail-fast and an
+// interrupt skip the commands that haven't run yet.
+func RunCommands(config Config, commands [][]string) (int, error) {
+	combined, err := New(config)
+	if err != nil {
+		return 1, err
+	}
+	defer func() { _ = combined.Close() }()
+
+	var runs []commandRun
+	exitCode := 0
+	for i, command := range commands {
+		commandConfig := config
+		commandConfig.Command = command
+		// Only the combined run writes to --junit and gets the run name as is
+		commandConfig.JUnitPath = ""
+		if config.RunName != "" {
+			commandConfig.RunName = fmt.Sprintf("%s-%d", config.RunName, i+1)
+		}
+
+		run := commandRun{name: strings.Join(command, " ")}
+		orch, err := New(commandConfig)
+		if err != nil {
+			return 1, err
+		}
+		orch.streamAppend = i > 0
+		run.err = orch.Run()
+		run.runner = orch.detectedRunner
+		run.runDir = orch.runDir
+		run.report = orch.reportManager
+		run.exitCode = orch.GetExitCode()
+		if run.err != nil && run.exitCode == 0 {
+			run.exitCode = 1
+		}
+		if run.err != nil {
+			fmt.Fprintf(os.Stderr, "Test execution failed: %v\n", run.err)
+		}
+		runs = append(runs, run)
+		exitCode = max(exitCode, run.exitCode)
+
+		if remaining := len(commands) - i - 1; remaining > 0 && (orch.interrupted || (config.FailFast && run.exitCode != 0)) {
+			combined.logger.Info("Skipping the last %d commands after %s exited with code %d", remaining, run.name, run.exitCode)
+			break
+		}
+	}
+
+	if err := combined.reportCommands(commands, runs, exitCode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return max(exitCode, 1), err
+	}
+	return exitCode, nil
+}
+
+// reportCommands writes the combined run of the commands that ran and prints its results
+func (o *Orchestrator) reportCommands(commands [][]string, runs []commandRun, exitCode int) error {
+	o.newRunDir()
+
+	var runners []string
+	for _, run := range runs {
+		if run.runner != "" && !slices.Contains(runners, run.runner) {
+			runners = append(runners, run.runner)
+		}
+	}
+	o.detectedRunner = strings.Join(runners, ", ")
+
+	manager, err := o.newReportManager(o.runDir, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	o.reportManager = manager
+	args := make([]string, len(commands))
+	for i, command := range commands {
+		args[i] = strings.Join(command, " ")
+	}
+	if err := manager.Initialize(strings.Join(args, " "+CommandSeparator+" ")); err != nil {
+		return fmt.Errorf("failed to initialize report: %w", err)
+	}
+
+	for _, run := range runs {
+		var errorDetails string
+		if run.report == nil && run.err != nil {
+			errorDetails = run.err.Error()
+		}
+		manager.AddCommand(run.name, run.report, run.exitCode, errorDetails)
+	}
+	if skipped := len(commands) - len(runs); skipped > 0 {
+		reason := fmt.Sprintf("%d of %d commands were not run after %s", skipped, len(commands), runs[len(runs)-1].name)
+		if previous := manager.AbortReason(); previous != "" {
+			reason = previous + "; " + reason
+		}
+		manager.SetAbortReason(reason)
+	}
+	if err := manager.Finalize(exitCode); err != nil {
+		return err
+	}
+	if err := updateLatestLink(filepath.Dir(o.runDir), o.runID); err != nil {
+		o.logger.Debug("Failed to update latest run link: %v", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Commands:    %d of %d run\n", len(runs), len(commands))
+	for _, run := range runs {
+		status := o.paint("PASS", ansiGreen)
+		if run.exitCode != 0 {
+			status = o.paint("FAIL", ansiRed)
+		}
+		where := filepath.Join(run.runDir, "test-run.md")
+		if run.report == nil {
+			where = "not run"
+		}
+		fmt.Printf("  %s %s (exit %d, %s)\n", status, run.name, run.exitCode, where)
+	}
+	counts := manager.RunCounts()
+	parts := []string{o.passedCount(counts.Passed)}
+	if counts.Failed > 0 {
+		parts = append(parts, o.paint(fmt.Sprintf("%d failed", counts.Failed), ansiRed))
+	}
+	if counts.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", counts.Skipped))
+	}
+	parts = append(parts, fmt.Sprintf("%d total", counts.Total))
+	fmt.Printf("Results:     %s\n", strings.Join(parts, ", "))
+	fmt.Printf("Report:      %s\n", filepath.Join(o.runDir, "test-run.md"))
+	o.printResultLine(exitCode)
+	return nil
+}
@@ -0,0 +1,23 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected [][]string
+	}{
+		{[]string{"go", "test", "./..."}, [][]string{{"go", "test", "./..."}}},
+		{[]string{"go", "test", "./...", "--and", "npm", "test"}, [][]string{{"go", "test", "./..."}, {"npm", "test"}}},
+		{[]string{"--and", "npm", "test", "--and", "--and", "pytest", "--and"}, [][]string{{"npm", "test"}, {"pytest"}}},
+		{[]string{"--and"}, nil},
+	}
+	for _, tt := range tests {
+		if got := SplitCommands(tt.args); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("SplitCommands(%q) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultKeepRuns is the number of most recent runs kept when no limit is configured
+const DefaultKeepRuns = 50
+
+// runDirNameRegex matches run directories created by generateRunID (timestamp prefix)
+var runDirNameRegex = regexp.MustCompile(`^\d{8}T\d{6}-`)
+
+// pruneOldRuns removes the oldest run directories in runsDir so that at most keep remain.
+// Directories that don't match the run naming scheme and the current run are never removed.
+func pruneOldRuns(runsDir, currentRunID string, keep int, lg Logger) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(runsDir)
+	if err != nil {
+		return err
+	}
+
+	var runs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !runDirNameRegex.MatchString(entry.Name()) {
+			continue
+		}
+		runs = append(runs, entry.Name())
+	}
+
+	if len(runs) <= keep {
+		return nil
+	}
+
+	// Timestamp prefixes sort lexically in chronological order, newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(runs)))
+
+	for _, name := range runs[keep:] {
+		if name == currentRunID {
+			continue
+		}
+		path := filepath.Join(runsDir, name)
+		if err := os.RemoveAll(path); err != nil {
+			lg.Debug("Failed to remove old run %s: %v", path, err)
+			continue
+		}
+		lg.Debug("Removed old run directory: %s", path)
+	}
+
+	return nil
+}
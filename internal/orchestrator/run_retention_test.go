@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestPruneOldRuns(t *testing.T) {
+	runsDir := t.TempDir()
+
+	dirs := []string{
+		"20250101T100000-grumpy-yoda",
+		"20250102T100000-wonky-picard",
+		"20250103T100000-batty-spock",
+		"20250104T100000-dopey-data",
+		"notes",        // Doesn't match the naming scheme
+		"old-run-copy", // Doesn't match the naming scheme
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(runsDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	if err := pruneOldRuns(runsDir, "20250104T100000-dopey-data", 2, logger.NewTestLogger()); err != nil {
+		t.Fatalf("pruneOldRuns failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(runsDir)
+	if err != nil {
+		t.Fatalf("Failed to read runs dir: %v", err)
+	}
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	sort.Strings(remaining)
+
+	expected := []string{
+		"20250103T100000-batty-spock",
+		"20250104T100000-dopey-data",
+		"notes",
+		"old-run-copy",
+	}
+	if len(remaining) != len(expected) {
+		t.Fatalf("Expected %v to remain, got %v", expected, remaining)
+	}
+	for i := range expected {
+		if remaining[i] != expected[i] {
+			t.Errorf("Expected %v to remain, got %v", expected, remaining)
+			break
+		}
+	}
+}
+
+func TestPruneOldRuns_NeverRemovesCurrentRun(t *testing.T) {
+	runsDir := t.TempDir()
+
+	// The current run sorts oldest, e.g. after a clock change
+	current := "20240101T000000-current-run"
+	for _, dir := range []string{current, "20250101T000000-newer-run", "20250102T000000-newest-run"} {
+		if err := os.MkdirAll(filepath.Join(runsDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	if err := pruneOldRuns(runsDir, current, 1, logger.NewTestLogger()); err != nil {
+		t.Fatalf("pruneOldRuns failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(runsDir, current)); err != nil {
+		t.Errorf("Current run directory should not be removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(runsDir, "20250101T000000-newer-run")); !os.IsNotExist(err) {
+		t.Errorf("Expected older run beyond the limit to be removed")
+	}
+}
+
+func TestPruneOldRuns_DisabledKeepsAll(t *testing.T) {
+	runsDir := t.TempDir()
+	for _, dir := range []string{"20250101T000000-a-b", "20250102T000000-c-d"} {
+		if err := os.MkdirAll(filepath.Join(runsDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	if err := pruneOldRuns(runsDir, "", -1, logger.NewTestLogger()); err != nil {
+		t.Fatalf("pruneOldRuns failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(runsDir)
+	if len(entries) != 2 {
+		t.Errorf("Expected all runs to be kept, got %d", len(entries))
+	}
+}
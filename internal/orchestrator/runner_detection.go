@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/zk/3pio/internal/runner"
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// RunnerDetection describes how 3pio would run a test command (--list-runners)
+type RunnerDetection struct {
+	Runner      string   // Runner name as recorded in reports, e.g. "jest" or "go test"
+	Definition  string   // Go type of the matched runner definition
+	AdapterFile string   // Embedded adapter injected into the command, "" for native runners
+	Command     []string // Command 3pio would execute
+	AlsoMatched []string // Other registered runners that match the command
+}
+
+// DetectRunner detects the test runner for the command the way Run does and builds the
+// command it would execute, without running anything. The adapter path in the command
+// is where the adapter would be extracted, with a placeholder for the run ID.
+func (o *Orchestrator) DetectRunner() (RunnerDetection, error) {
+	runnerDef, err := o.detectRunnerDefinition()
+	if err != nil {
+		return RunnerDetection{}, err
+	}
+	if err := o.selectTests(runnerDef); err != nil {
+		return RunnerDetection{}, err
+	}
+	o.enableGinkgo(runnerDef)
+
+	detection := RunnerDetection{
+		Runner:      runnerName(runnerDef),
+		Definition:  fmt.Sprintf("%T", runnerDef),
+		AdapterFile: runnerDef.GetAdapterFileName(),
+	}
+	adapterPath := ""
+	if detection.AdapterFile != "" {
+		adapterPath = filepath.Join(o.outputDir, "runs", "<run-id>", "adapters", detection.AdapterFile)
+		if abs, err := filepath.Abs(adapterPath); err == nil {
+			adapterPath = abs
+		}
+	}
+	detection.Command = runnerDef.BuildCommand(o.command, adapterPath)
+
+	for _, name := range o.runnerManager.MatchingRunners(o.command) {
+		if def, ok := o.runnerManager.GetDefinition(name); !ok || def != runnerDef {
+			detection.AlsoMatched = append(detection.AlsoMatched, name)
+		}
+	}
+	return detection, nil
+}
+
+// detectRunnerDefinition returns the runner selected with --runner, or else the one
+// detected from the command
+func (o *Orchestrator) detectRunnerDefinition() (runner.Definition, error) {
+	if o.forcedRunner != "" {
+		runnerDef, err := o.runnerManager.Select(o.forcedRunner)
+		if err != nil {
+			return nil, err
+		}
+		o.logger.Debug("Using runner %s from --runner instead of detection", o.forcedRunner)
+		return runnerDef, nil
+	}
+	runnerDef, err := o.runnerManager.Detect(o.command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect test runner: %w", err)
+	}
+	return runnerDef, nil
+}
+
+// runnerName returns the runner name recorded in reports for a runner definition
+func runnerName(runnerDef runner.Definition) string {
+	switch runnerDef.GetAdapterFileName() {
+	case "jest.js":
+		return "jest"
+	case "vitest.js":
+		return "vitest"
+	case "pytest_adapter.py":
+		return "pytest"
+	case "cypress.js":
+		return "cypress"
+	case "mocha.js":
+		return "mocha"
+	case "":
+		// Native runner - determine which one based on the underlying definition
+		nativeRunner, ok := runnerDef.(runner.NativeRunner)
+		if !ok {
+			return "unknown native"
+		}
+		nativeDef := nativeRunner.GetNativeDefinition()
+		switch nativeDef.(type) {
+		case *definitions.GoTestDefinition:
+			return "go test"
+		case *definitions.CargoTestDefinition:
+			return "cargo test"
+		case *definitions.NextestDefinition:
+			return "cargo nextest"
+		case *definitions.MavenDefinition:
+			return "maven"
+		case *definitions.GradleDefinition:
+			return "gradle"
+		case *definitions.DotnetTestDefinition:
+			return "dotnet test"
+		case *definitions.RSpecDefinition:
+			return "rspec"
+		case *definitions.PlaywrightDefinition:
+			return "playwright"
+		case *definitions.TAPDefinition:
+			return "tap"
+		default:
+			return fmt.Sprintf("unknown native (%T)", nativeDef)
+		}
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestDetectRunner(t *testing.T) {
+	tests := []struct {
+		command     []string
+		runner      string
+		adapterFile string
+		wantArg     string
+	}{
+		{[]string{"go", "test", "./..."}, "go test", "", "-json"},
+		{[]string{"cargo", "nextest", "run"}, "cargo nextest", "", "libtest-json"},
+		{[]string{"npx", "jest", "src"}, "jest", "jest.js", "<run-id>"},
+		{[]string{"pytest", "-x"}, "pytest", "pytest_adapter.py", "pytest_adapter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.command, " "), func(t *testing.T) {
+			orch, err := New(Config{Command: tt.command, Logger: logger.NewTestLogger()})
+			if err != nil {
+				t.Fatalf("Failed to create orchestrator: %v", err)
+			}
+			defer func() { _ = orch.Close() }()
+
+			detection, err := orch.DetectRunner()
+			if err != nil {
+				t.Fatalf("DetectRunner failed: %v", err)
+			}
+			if detection.Runner != tt.runner || detection.AdapterFile != tt.adapterFile {
+				t.Errorf("Expected %s with adapter %q, got %s with %q", tt.runner, tt.adapterFile, detection.Runner, detection.AdapterFile)
+			}
+			if command := strings.Join(detection.Command, " "); !strings.Contains(command, tt.wantArg) {
+				t.Errorf("Expected %q in the command, got %s", tt.wantArg, command)
+			}
+		})
+	}
+}
+
+func TestDetectRunner_NoRunner(t *testing.T) {
+	orch, err := New(Config{Command: []string{"echo", "test"}, Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	if _, err := orch.DetectRunner(); err == nil || !strings.Contains(err.Error(), "no test runner detected") {
+		t.Errorf("Expected a no test runner error, got %v", err)
+	}
+}
+
+func TestDetectRunner_ForcedRunner(t *testing.T) {
+	orch, err := New(Config{Command: []string{"npx", "jest", "src"}, Runner: "vitest", Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	detection, err := orch.DetectRunner()
+	if err != nil {
+		t.Fatalf("DetectRunner failed: %v", err)
+	}
+	if detection.Runner != "vitest" || detection.AdapterFile != "vitest.js" {
+		t.Errorf("Expected the forced vitest runner, got %s with %q", detection.Runner, detection.AdapterFile)
+	}
+}
+
+func TestDetectRunner_TestSelection(t *testing.T) {
+	orch, err := New(Config{
+		Command:        []string{"go", "test", "./..."},
+		TestNameFilter: "TestCart",
+		Logger:         logger.NewTestLogger(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+
+	detection, err := orch.DetectRunner()
+	if err != nil {
+		t.Fatalf("DetectRunner failed: %v", err)
+	}
+	if command := strings.Join(detection.Command, " "); command != "go test -json -run=TestCart ./..." {
+		t.Errorf("Expected the name filter as -run, got %s", command)
+	}
+
+	// Runners without a selector reject the flags instead of ignoring them
+	orch, err = New(Config{Command: []string{"mvn", "test"}, TestFiles: []string{"CartTest.java"}, Logger: logger.NewTestLogger()})
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Close() }()
+	if _, err := orch.DetectRunner(); err == nil || !strings.Contains(err.Error(), "not supported for maven") {
+		t.Errorf("Expected an unsupported selection error, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runnerVersionTimeout bounds a runner's --version command. The lookup runs alongside the
+// tests, so a slow one only means the report goes without a version.
+const runnerVersionTimeout = 10 * time.Second
+
+// minVitestMajor is the oldest Vitest major version the Vitest adapter supports
+const minVitestMajor = 3
+
+// nodeRunnerPackages maps JavaScript runners to the npm package whose version they report
+var nodeRunnerPackages = map[string]string{
+	"jest":       "jest",
+	"vitest":     "vitest",
+	"mocha":      "mocha",
+	"cypress":    "cypress",
+	"playwright": "@playwright/test",
+}
+
+// versionRegex matches the first version number in a --version output, e.g. 1.23.1 in
+// "go version go1.23.1 linux/amd64"
+var versionRegex = regexp.MustCompile(`\d+(?:\.\d+)+(?:-[0-9A-Za-z.]+)?`)
+
+// recordRunnerVersion looks up the detected runner's version for the report. JavaScript
+// runners' versions are read from node_modules right away; other runners' --version
+// commands run in the background and the version is recorded whenever they finish.
+func (o *Orchestrator) recordRunnerVersion(name string) {
+	if pkg, ok := nodeRunnerPackages[name]; ok {
+		dir, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		version := nodePackageVersion(dir, pkg)
+		o.setRunnerVersion(version)
+		if name == "vitest" {
+			o.warnOldVitest(version)
+		}
+		return
+	}
+
+	versionCommand := runnerVersionCommand(name, o.command)
+	if versionCommand == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), runnerVersionTimeout)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, versionCommand[0], versionCommand[1:]...).Output()
+		if err != nil {
+			o.logger.Debug("Failed to get the %s version with %q: %v", name, versionCommand, err)
+			return
+		}
+		o.setRunnerVersion(parseRunnerVersion(string(output)))
+	}()
+}
+
+// setRunnerVersion records the runner version on the current report and on the report
+// managers created after it
+func (o *Orchestrator) setRunnerVersion(version string) {
+	if version == "" {
+		return
+	}
+	o.logger.Debug("Runner version: %s", version)
+	o.reportMu.Lock()
+	defer o.reportMu.Unlock()
+	o.runnerVersion = version
+	if o.reportManager != nil {
+		o.reportManager.SetRunnerVersion(version)
+	}
+}
+
+// warnOldVitest warns when the installed Vitest is older than the adapter supports,
+// since the run then tends to report nothing rather than fail
+func (o *Orchestrator) warnOldVitest(version string) {
+	major, _, _ := strings.Cut(version, ".")
+	if n, err := strconv.Atoi(major); err != nil || n >= minVitestMajor {
+		return
+	}
+	o.logger.Info("Vitest %s is older than the supported %d.0", version, minVitestMajor)
+	fmt.Fprintf(os.Stderr, "Warning: Vitest %s detected; 3pio requires Vitest %d.0 or later and results may be missing\n", version, minVitestMajor)
+}
+
+// runnerVersionCommand returns the command printing a runner's version, or nil when
+// there is none. Runners started through a wrapper such as "uv run" or "bundle exec" keep
+// the wrapper, so the version is the one the tests run with.
+func runnerVersionCommand(name string, command []string) []string {
+	switch name {
+	case "go test":
+		return []string{"go", "version"}
+	case "cargo test":
+		return []string{"cargo", "--version"}
+	case "cargo nextest":
+		return []string{"cargo", "nextest", "--version"}
+	case "dotnet test":
+		return []string{"dotnet", "--version"}
+	case "pytest", "rspec":
+		i := slices.IndexFunc(command, func(arg string) bool { return filepath.Base(arg) == name })
+		if i < 0 {
+			return nil
+		}
+		return append(slices.Clone(command[:i+1]), "--version")
+	case "maven", "gradle":
+		if len(command) == 0 {
+			return nil
+		}
+		return []string{command[0], "--version"}
+	}
+	return nil
+}
+
+// parseRunnerVersion returns the first version number in a --version output
+func parseRunnerVersion(output string) string {
+	return versionRegex.FindString(output)
+}
+
+// nodePackageVersion returns the version of an npm package installed in node_modules
+// of dir or the closest directory above it, or "" when it isn't installed
+func nodePackageVersion(dir, pkg string) string {
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "node_modules", filepath.FromSlash(pkg), "package.json"))
+		if err == nil {
+			var manifest struct {
+				Version string `json:"version"`
+			}
+			if json.Unmarshal(data, &manifest) == nil {
+				return manifest.Version
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
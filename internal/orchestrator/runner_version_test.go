@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunnerVersionCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    []string
+	}{
+		{"go test", []string{"go", "test", "./..."}, []string{"go", "version"}},
+		{"cargo test", []string{"cargo", "test"}, []string{"cargo", "--version"}},
+		{"cargo nextest", []string{"cargo", "nextest", "run"}, []string{"cargo", "nextest", "--version"}},
+		{"pytest", []string{"pytest", "-x", "tests"}, []string{"pytest", "--version"}},
+		{"pytest", []string{"uv", "run", "python", "-m", "pytest", "-x"}, []string{"uv", "run", "python", "-m", "pytest", "--version"}},
+		{"pytest", []string{"tox", "-e", "py312"}, nil},
+		{"rspec", []string{"bundle", "exec", "rspec", "spec"}, []string{"bundle", "exec", "rspec", "--version"}},
+		{"gradle", []string{"./gradlew", "test"}, []string{"./gradlew", "--version"}},
+		{"tap", []string{"prove", "-v"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.command, " "), func(t *testing.T) {
+			if got := runnerVersionCommand(tt.name, tt.command); !slices.Equal(got, tt.want) {
+				t.Errorf("runnerVersionCommand(%q, %q) = %q, want %q", tt.name, tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRunnerVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"go version go1.23.1 linux/amd64\n", "1.23.1"},
+		{"cargo 1.80.0 (376290515 2024-07-16)\n", "1.80.0"},
+		{"cargo-nextest 0.9.72 (da8b7a7e4 2024-07-17)\n", "0.9.72"},
+		{"pytest 8.3.2\n", "8.3.2"},
+		{"RSpec 3.13\n  - rspec-core 3.13.0\n", "3.13"},
+		{"\n------------------------------------------------------------\nGradle 8.5\n", "8.5"},
+		{"9.0.100-rc.1.24452.12\n", "9.0.100-rc.1.24452.12"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseRunnerVersion(tt.output); got != tt.want {
+			t.Errorf("parseRunnerVersion(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestNodePackageVersion(t *testing.T) {
+	root := t.TempDir()
+	pkgDir := filepath.Join(root, "node_modules", "@playwright", "test")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "package.json"), []byte(`{"name":"@playwright/test","version":"1.47.2"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workspace := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A workspace package finds the version hoisted to the repository root
+	if got := nodePackageVersion(workspace, "@playwright/test"); got != "1.47.2" {
+		t.Errorf("Expected the hoisted version 1.47.2, got %q", got)
+	}
+	if got := nodePackageVersion(workspace, "vitest"); got != "" {
+		t.Errorf("Expected no version for a package that isn't installed, got %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// verboseLinesPerSecond caps how many non-failing test cases --verbose prints each second.
+// Failures are always printed.
+const verboseLinesPerSecond = 20
+
+// testStream prints test case results as they arrive (--verbose). Past the per-second cap,
+// non-failing results are counted instead and summarized in a single line.
+type testStream struct {
+	out         io.Writer
+	now         func() time.Time
+	windowStart time.Time
+	printed     int            // Lines printed in the current one-second window
+	suppressed  map[string]int // Results not printed since the last summary, by status
+}
+
+func newTestStream(out io.Writer) *testStream {
+	return &testStream{
+		out:        out,
+		now:        time.Now,
+		suppressed: make(map[string]int),
+	}
+}
+
+// add prints a test case result line, unless the cap for this second was reached
+func (s *testStream) add(status, line string) {
+	if now := s.now(); now.Sub(s.windowStart) >= time.Second {
+		s.flush()
+		s.windowStart = now
+		s.printed = 0
+	}
+
+	if status != "FAIL" && s.printed >= verboseLinesPerSecond {
+		s.suppressed[status]++
+		return
+	}
+	s.printed++
+	_, _ = fmt.Fprintln(s.out, line)
+}
+
+// flush prints a summary of the results that were not printed, if any
+func (s *testStream) flush() {
+	var parts []string
+	for _, status := range []string{"PASS", "SKIP", "XFAIL", "XPASS", "PENDING"} {
+		if count := s.suppressed[status]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, streamStatusWords[status]))
+		}
+	}
+	if len(parts) > 0 {
+		_, _ = fmt.Fprintf(s.out, "  ... %s\n", strings.Join(parts, ", "))
+	}
+	s.suppressed = make(map[string]int)
+}
+
+// streamStatusWords describes statuses in the summary of results that were not printed
+var streamStatusWords = map[string]string{
+	"PASS":    "more passed",
+	"SKIP":    "more skipped",
+	"XFAIL":   "more xfailed",
+	"XPASS":   "more xpassed",
+	"PENDING": "more pending",
+}
+
+// formatTestCaseLine formats a test case result as "<icon> <file> > <suite> > <test> (<duration>)"
+func (o *Orchestrator) formatTestCaseLine(payload ipc.TestCasePayload) string {
+	var icon string
+	switch payload.Status {
+	case "PASS":
+		icon = o.paint("✓", ansiGreen)
+	case "FAIL":
+		icon = o.paint("✕", ansiRed)
+	case "SKIP":
+		icon = "○"
+	case "XFAIL":
+		icon = "⊗"
+	case "XPASS":
+		icon = "⊕"
+	default:
+		icon = "?"
+	}
+
+	names := make([]string, 0, len(payload.ParentNames)+1)
+	for _, name := range payload.ParentNames {
+		names = append(names, relativeToCwd(name))
+	}
+	names = append(names, payload.TestName)
+
+	line := icon + " " + strings.Join(names, " > ")
+	if payload.Duration > 0 {
+		line += " (" + formatTestDuration(payload.Duration) + ")"
+	}
+	if retry, ok := payload.Metadata["retry"].(float64); ok && retry > 0 {
+		line += fmt.Sprintf(" [retry %d]", int(retry))
+	}
+	return line
+}
+
+// formatTestDuration formats a duration in milliseconds, e.g. "12ms" or "1.50s"
+func formatTestDuration(ms float64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", int(ms))
+	}
+	return fmt.Sprintf("%.2fs", ms/1000)
+}
+
+// relativeToCwd shortens absolute paths below the working directory
+func relativeToCwd(name string) string {
+	if !filepath.IsAbs(name) {
+		return name
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return name
+	}
+	if rel, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return name
+}
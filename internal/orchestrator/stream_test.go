@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestTestStreamThrottle(t *testing.T) {
+	var out bytes.Buffer
+	clock := time.Unix(1000, 0)
+	stream := newTestStream(&out)
+	stream.now = func() time.Time { return clock }
+
+	for i := 0; i < verboseLinesPerSecond+5; i++ {
+		stream.add("PASS", "pass")
+	}
+	stream.add("SKIP", "skip")
+	stream.add("FAIL", "fail")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != verboseLinesPerSecond+1 {
+		t.Fatalf("Expected %d lines within the first second, got %d:\n%s", verboseLinesPerSecond+1, len(lines), out.String())
+	}
+	if lines[len(lines)-1] != "fail" {
+		t.Errorf("Expected failures to be printed past the cap, got %q", lines[len(lines)-1])
+	}
+
+	// The next second starts with a summary of what was held back
+	out.Reset()
+	clock = clock.Add(time.Second)
+	stream.add("PASS", "next")
+	if want := "  ... 5 more passed, 1 more skipped\nnext\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	stream.flush()
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing to flush, got %q", out.String())
+	}
+}
+
+func TestFormatTestCaseLine(t *testing.T) {
+	o := &Orchestrator{}
+
+	line := o.formatTestCaseLine(ipc.TestCasePayload{
+		TestName:    "adds items",
+		ParentNames: []string{"cart.test.js", "Cart"},
+		Status:      "PASS",
+		Duration:    12,
+	})
+	if want := "✓ cart.test.js > Cart > adds items (12ms)"; line != want {
+		t.Errorf("formatTestCaseLine() = %q, want %q", line, want)
+	}
+
+	line = o.formatTestCaseLine(ipc.TestCasePayload{
+		TestName: "checkout",
+		Status:   "FAIL",
+		Duration: 1500,
+		Metadata: map[string]interface{}{"retry": float64(1)},
+	})
+	if want := "✕ checkout (1.50s) [retry 1]"; line != want {
+		t.Errorf("formatTestCaseLine() = %q, want %q", line, want)
+	}
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/zk/3pio/internal/runner"
+)
+
+// selectTests turns --test-name-filter and --test-file into the runner's own selector
+// arguments in the command, so they don't depend on where raw arguments land once the
+// runner's BuildCommand has added its reporter flags and separators
+func (o *Orchestrator) selectTests(runnerDef runner.Definition) error {
+	if o.selection.IsEmpty() {
+		return nil
+	}
+	selector, ok := runnerDef.(runner.TestSelector)
+	if !ok {
+		return fmt.Errorf("--test-name-filter and --test-file are not supported for %s", runnerName(runnerDef))
+	}
+	command, err := selector.SelectTests(o.command, o.selection)
+	if err != nil {
+		return fmt.Errorf("failed to select tests: %w", err)
+	}
+	o.logger.Debug("Test selection changed the command from %q to %q", o.command, command)
+	o.command = command
+	return nil
+}
+
+// verifyCommand logs the command about to run and checks that the arguments of the given
+// command survived BuildCommand in place. A selection from --test-name-filter or
+// --test-file that didn't survive is an error, since the run would silently test
+// something else; for other arguments it's only logged.
+func (o *Orchestrator) verifyCommand(built []string) error {
+	o.logger.Debug("Final command: %q", built)
+	err := runner.VerifyCommand(o.command, built)
+	if err == nil {
+		return nil
+	}
+	if !o.selection.IsEmpty() {
+		return fmt.Errorf("test selection did not survive building the command: %w", err)
+	}
+	o.logger.Info("The built command may not pass the arguments as given: %v", err)
+	return nil
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// recoverVitestResults stands in for the 3pio Vitest reporter when it sent no test
+// events, which happens when it fails to load in a Vitest version it doesn't support.
+// The results are read from the report Vitest's own json reporter wrote alongside it
+// and appended to the IPC log, so they are reported (and replayed) like any other
+// events. Must be called after the command exits and before the IPC manager is
+// cleaned up, which reads the appended events.
+func (o *Orchestrator) recoverVitestResults() {
+	if o.vitestJSONReport == "" {
+		return
+	}
+	ipcPath := o.ipcManager.Address()
+	if hasTestEvents(ipcPath) {
+		return
+	}
+	if _, err := os.Stat(o.vitestJSONReport); err != nil {
+		o.logger.Debug("Vitest reporter sent no test events and there is no JSON report to fall back on: %v", err)
+		return
+	}
+
+	events, err := definitions.VitestJSONReportEvents(o.vitestJSONReport)
+	if err != nil {
+		o.logger.Error("Vitest reporter sent no test events and the JSON report fallback failed: %v", err)
+		return
+	}
+	o.logger.Info("Vitest reporter sent no test events, using %d events from %s", len(events), o.vitestJSONReport)
+	if !o.quiet {
+		fmt.Println("The 3pio Vitest reporter reported no tests, using Vitest's JSON report instead.")
+	}
+
+	for _, event := range events {
+		if err := ipc.AppendEvent(ipcPath, event); err != nil {
+			o.logger.Error("Failed to append Vitest JSON report event: %v", err)
+			return
+		}
+	}
+}
+
+// hasTestEvents reports whether the IPC log at ipcPath has any test case or group events
+func hasTestEvents(ipcPath string) bool {
+	file, err := os.Open(ipcPath)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), ipc.DefaultMaxEventSize)
+	for scanner.Scan() {
+		var event struct {
+			EventType string `json:"eventType"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue
+		}
+		if event.EventType == string(ipc.EventTypeTestCase) || strings.HasPrefix(event.EventType, "testGroup") {
+			return true
+		}
+	}
+	return false
+}
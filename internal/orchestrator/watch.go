@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+// watchSession tracks the runs of a test command left in watch mode (--watch). The
+// adapter marks each run with runStart and runComplete events; the first run reports
+// into the run directory and each re-run into a rerun-<n> directory inside it.
+type watchSession struct {
+	dir       string                                       // Run directory of the session
+	runs      int                                          // Runs started so far
+	active    bool                                         // A run is in progress, false while waiting for changes
+	newReport func(runDir string) (*report.Manager, error) // Creates and initializes a re-run's report manager
+}
+
+// activeReport returns the report manager of the run in progress, or nil while watch
+// mode is waiting for changes and the last run's report is already final
+func (o *Orchestrator) activeReport() *report.Manager {
+	o.reportMu.Lock()
+	defer o.reportMu.Unlock()
+	if o.watch != nil && !o.watch.active {
+		return nil
+	}
+	return o.reportManager
+}
+
+// startWatchRun switches reporting to a new directory when a re-run starts in watch mode
+// and points runs/latest at it
+func (o *Orchestrator) startWatchRun() {
+	o.watch.runs++
+	if o.watch.runs == 1 {
+		// The first run reports into the run directory set up before the command started
+		return
+	}
+
+	name := fmt.Sprintf("rerun-%d", o.watch.runs)
+	runDir := filepath.Join(o.watch.dir, name)
+	manager, err := o.watch.newReport(runDir)
+	if err != nil {
+		o.logger.Error("Failed to create report for watch run %d: %v", o.watch.runs, err)
+		return
+	}
+
+	o.resetRunState()
+	o.reportMu.Lock()
+	o.reportManager = manager
+	o.runDir = runDir
+	o.watch.active = true
+	o.reportMu.Unlock()
+
+	runID := filepath.Join(filepath.Base(o.watch.dir), name)
+	if err := updateLatestLink(filepath.Dir(o.watch.dir), runID); err != nil {
+		o.logger.Debug("Failed to update latest run link: %v", err)
+	}
+
+	if !o.quiet {
+		fmt.Println()
+		fmt.Printf("Re-running tests (run %d), report: %s\n", o.watch.runs, filepath.Join(runDir, "test-run.md"))
+		fmt.Println()
+	}
+}
+
+// finishWatchRun finalizes and summarizes a run in watch mode once the adapter reports
+// it complete, while the command keeps watching for changes
+func (o *Orchestrator) finishWatchRun() {
+	o.reportMu.Lock()
+	active := o.watch.active
+	o.watch.active = false
+	o.reportMu.Unlock()
+	if !active {
+		return
+	}
+
+	if o.totalGroups == 0 {
+		o.computeStatsFromReportManager()
+		o.displayFinalResults()
+	}
+
+	outcome := o.runOutcome(false)
+	outcome.exitCode = 0
+	if o.failedGroups > 0 || o.failedTests > 0 {
+		outcome.exitCode = 1
+	}
+	outcome.exitMeaning = o.interpretExitCode(outcome.exitCode)
+	o.failOnSkip(&outcome)
+	o.checkPassRate(&outcome)
+	o.reportManager.SetExitReason(outcome.exitReason())
+	if err := o.reportManager.Finalize(outcome.exitCode); err != nil {
+		o.logger.Error("Failed to finalize report for watch run %d: %v", o.watch.runs, err)
+	}
+
+	if o.stream != nil {
+		o.stream.flush()
+	}
+
+	if !o.quiet {
+		fmt.Println()
+	}
+	o.printSkipFailure(outcome)
+	o.printPassRate(outcome)
+	o.printResults()
+	o.printFailedTests()
+	o.printResultLine(outcome.exitCode)
+	if !o.quiet {
+		fmt.Println()
+		fmt.Println("Watching for changes, press Ctrl+C to stop.")
+	}
+}
+
+// resetRunState clears the console counts and group tracking before a re-run
+func (o *Orchestrator) resetRunState() {
+	o.startTime = time.Now()
+	o.passedGroups = 0
+	o.failedGroups = 0
+	o.skippedGroups = 0
+	o.xfailedGroups = 0
+	o.xpassedGroups = 0
+	o.totalGroups = 0
+	o.passedTests = 0
+	o.failedTests = 0
+	o.skippedTests = 0
+	o.xfailedTests = 0
+	o.xpassedTests = 0
+	o.totalTests = 0
+	o.lastCollected = 0
+	o.buildFailures = nil
+	o.testOutcomes = make(map[string]*testOutcome)
+	o.displayedGroups = make(map[string]bool)
+	o.groupStartTimes = make(map[string]time.Time)
+	o.groupFailedTests = make(map[string][]string)
+	o.omittedFailures = make(map[string]bool)
+	o.completedGroups = make(map[string]bool)
+	o.noTestGroups = make(map[string]bool)
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/report"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(format string, args ...interface{}) {}
+func (discardLogger) Error(format string, args ...interface{}) {}
+func (discardLogger) Info(format string, args ...interface{})  {}
+
+func readRunSummary(t *testing.T, runDir string) report.RunSummary {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Failed to read run.json: %v", err)
+	}
+	var summary report.RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Invalid run.json: %v", err)
+	}
+	return summary
+}
+
+func TestWatchSession_ReportsEachRun(t *testing.T) {
+	runsDir := t.TempDir()
+	runID := "20250101T100000-grumpy-yoda"
+	runDir := filepath.Join(runsDir, runID)
+
+	newReport := func(dir string) (*report.Manager, error) {
+		manager, err := report.NewManager(dir, nil, discardLogger{}, "jest", "npx jest --watch")
+		if err != nil {
+			return nil, err
+		}
+		return manager, manager.Initialize("npx jest --watch")
+	}
+	first, err := newReport(runDir)
+	if err != nil {
+		t.Fatalf("Failed to create report manager: %v", err)
+	}
+
+	o := &Orchestrator{
+		runID:         runID,
+		runDir:        runDir,
+		logger:        discardLogger{},
+		quiet:         true,
+		startTime:     time.Now(),
+		reportManager: first,
+		watch:         &watchSession{dir: runDir, active: true, newReport: newReport},
+		testOutcomes:  make(map[string]*testOutcome),
+		noTestGroups:  make(map[string]bool),
+	}
+
+	// The first run reports into the run directory itself
+	o.startWatchRun()
+	o.totalGroups, o.failedGroups, o.totalTests, o.failedTests = 1, 1, 1, 1
+	o.finishWatchRun()
+
+	if o.activeReport() != nil {
+		t.Error("Expected no active report while waiting for changes")
+	}
+	if summary := readRunSummary(t, runDir); summary.ExitCode != 1 || summary.ExitReason != ExitReasonTestsFailed {
+		t.Errorf("Expected the first run to exit 1 (tests_failed), got %d (%q)", summary.ExitCode, summary.ExitReason)
+	}
+
+	// A re-run gets its own directory, fresh counts and the latest link
+	o.startWatchRun()
+	rerunDir := filepath.Join(runDir, "rerun-2")
+	if o.runDir != rerunDir || o.activeReport() == nil || o.activeReport() == first {
+		t.Fatalf("Expected re-run to report into %s, got %s", rerunDir, o.runDir)
+	}
+	if o.failedGroups != 0 || o.totalTests != 0 {
+		t.Errorf("Expected counts to reset, got %d failed groups and %d tests", o.failedGroups, o.totalTests)
+	}
+	if target, err := os.Readlink(filepath.Join(runsDir, latestLinkName)); err == nil && target != filepath.Join(runID, "rerun-2") {
+		t.Errorf("Expected latest to point at the re-run, got %s", target)
+	}
+
+	o.totalGroups, o.passedGroups, o.totalTests, o.passedTests = 1, 1, 1, 1
+	o.finishWatchRun()
+	if summary := readRunSummary(t, rerunDir); summary.ExitCode != 0 || summary.Status != "COMPLETE" {
+		t.Errorf("Expected the re-run to complete with exit 0, got %s with %d", summary.Status, summary.ExitCode)
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiEscapeRegex matches a complete ANSI escape sequence: CSI sequences such as colors
+// ("\x1b[31m") and cursor movement ("\x1b[2K"), OSC sequences such as window titles and
+// hyperlinks, ended by BEL or ST ("\x1b\\"), and short escapes such as "\x1b(B"
+var ansiEscapeRegex = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[ -/]*[0-~])`)
+
+// ansiPartialRegex matches the start of an escape sequence that a chunk ends in the middle of
+var ansiPartialRegex = regexp.MustCompile(`^\x1b(?:\[[0-?]*[ -/]*|\][^\x07\x1b]*|[ -/]*)$`)
+
+// stripANSI removes ANSI escape sequences from text
+func stripANSI(text string) string {
+	if !strings.Contains(text, "\x1b") {
+		return text
+	}
+	return ansiEscapeRegex.ReplaceAllString(text, "")
+}
+
+// stripANSIChunk removes ANSI escape sequences from the next chunk of a stream. A sequence
+// cut off at the end of the chunk is held in pending until the next chunk completes it.
+func stripANSIChunk(pending *string, chunk string) string {
+	text := *pending + chunk
+	*pending = ""
+	if i := strings.LastIndexByte(text, '\x1b'); i >= 0 && ansiPartialRegex.MatchString(text[i:]) {
+		*pending = text[i:]
+		text = text[:i]
+	}
+	return stripANSI(text)
+}
+
+// SetKeepANSI keeps ANSI escape sequences in group stdout and stderr (--keep-ansi). By
+// default they are stripped, since reports show the output as plain text.
+func (gm *GroupManager) SetKeepANSI(keep bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.keepANSI = keep
+}
+
+// SetKeepANSI keeps ANSI escape sequences in the output shown in group reports.
+// output.log always has the raw output.
+func (m *Manager) SetKeepANSI(keep bool) {
+	m.groupManager.SetKeepANSI(keep)
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain text", "no escapes here", "no escapes here"},
+		{"color", "\x1b[31mred\x1b[0m text", "red text"},
+		{"256 color and bold", "\x1b[1;38;5;10mok\x1b[0m", "ok"},
+		{"reset without params", "\x1b[mdone", "done"},
+		{"cursor movement and erase", "\x1b[2K\x1b[1Gprogress 50%\x1b[1A", "progress 50%"},
+		{"private mode", "\x1b[?25lhidden cursor\x1b[?25h", "hidden cursor"},
+		{"hyperlink", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"window title ended by BEL", "\x1b]0;title\x07after", "after"},
+		{"charset selection", "\x1b(Bascii", "ascii"},
+		{"keeps other control characters", "line\r\n\ttab", "line\r\n\ttab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripANSI(tt.input); got != tt.expected {
+				t.Errorf("stripANSI(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripANSIChunk(t *testing.T) {
+	// A sequence split across chunks is held back until it is complete
+	var pending string
+	chunks := []string{"\x1b[3", "2mgreen\x1b", "[0m \x1b]8;;http://x", "\x07end"}
+	var got string
+	for _, chunk := range chunks {
+		got += stripANSIChunk(&pending, chunk)
+	}
+	if got != "green end" || pending != "" {
+		t.Errorf("Expected %q with nothing pending, got %q pending %q", "green end", got, pending)
+	}
+}
+
+func TestGroupManager_StripsANSI(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", nil)
+	if err := gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("prints colors", []string{"color.test.js"}, "PASS")); err != nil {
+		t.Fatalf("Failed to process test case: %v", err)
+	}
+	group, _ := gm.GetGroup(GenerateGroupID(gm.normalizeToAbsolutePath("color.test.js"), nil))
+	if group == nil {
+		t.Fatal("Expected color.test.js group")
+	}
+
+	_ = gm.ProcessStdoutChunk("color.test.js", nil, "\x1b[32mPASS\x1b[")
+	_ = gm.ProcessStdoutChunk("color.test.js", nil, "0m done\n")
+	_ = gm.ProcessStderrChunk("color.test.js", nil, "\x1b[33mwarning\x1b[0m\n")
+	if group.Stdout != "PASS done\n" || group.Stderr != "warning\n" {
+		t.Errorf("Expected stripped output, got stdout %q and stderr %q", group.Stdout, group.Stderr)
+	}
+
+	// --keep-ansi keeps the sequences
+	gm.SetKeepANSI(true)
+	_ = gm.ProcessStdoutChunk("color.test.js", nil, "\x1b[31mred\x1b[0m")
+	if want := "PASS done\n\x1b[31mred\x1b[0m"; group.Stdout != want {
+		t.Errorf("Expected %q, got %q", want, group.Stdout)
+	}
+}
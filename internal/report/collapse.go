@@ -0,0 +1,45 @@
+package report
+
+import "strings"
+
+// SetCollapseDepth collapses the group tree in group reports below the given level
+// (--collapse-depth). Root groups are level 1. The report of a group at that level lists
+// the test cases of all its subgroups instead of a subgroup table. 0 shows every level.
+func (gm *GroupManager) SetCollapseDepth(depth int) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.collapseDepth = depth
+}
+
+// SetCollapseDepth collapses subgroups below the given level into their ancestor's
+// report. The subgroups still get report files of their own.
+func (m *Manager) SetCollapseDepth(depth int) {
+	m.groupManager.SetCollapseDepth(depth)
+}
+
+// collapses reports whether the report of group lists its subgroups' test cases itself
+func (gm *GroupManager) collapses(group *TestGroup) bool {
+	return gm.collapseDepth > 0 && group.Depth+1 == gm.collapseDepth && len(group.Subgroups) > 0
+}
+
+// collapseSubgroups returns the test cases of group and of all its subgroups, depth first
+// in the order of the subgroup table, and the subgroups. Test cases of subgroups are
+// named by their path below group ("describe > test").
+func collapseSubgroups(group *TestGroup, order GroupOrder) ([]TestCase, []*TestGroup) {
+	testCases := append([]TestCase{}, group.TestCases...)
+	var subgroups []*TestGroup
+	var walk func(g *TestGroup, path []string)
+	walk = func(g *TestGroup, path []string) {
+		for _, sg := range sortedSubgroups(g, order) {
+			sgPath := append(append([]string{}, path...), sg.Name)
+			subgroups = append(subgroups, sg)
+			for _, tc := range sg.TestCases {
+				tc.Name = strings.Join(append(sgPath, tc.Name), " > ")
+				testCases = append(testCases, tc)
+			}
+			walk(sg, sgPath)
+		}
+	}
+	walk(group, nil)
+	return testCases, subgroups
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestGroupManager_CollapseDepth(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	for _, event := range []ipc.GroupTestCaseEvent{
+		ipc.NewGroupTestCaseEvent("loads", []string{"cart.test.js", "Cart"}, "PASS"),
+		ipc.NewGroupTestCaseEvent("adds one", []string{"cart.test.js", "Cart", "add", "single"}, "PASS"),
+		ipc.NewGroupTestCaseEvent("adds many", []string{"cart.test.js", "Cart", "add", "bulk"}, "FAIL"),
+	} {
+		if err := gm.ProcessTestCase(event); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+	if err := gm.ProcessGroupError(ipc.NewGroupErrorEvent("bulk", []string{"cart.test.js", "Cart", "add"}, "SETUP_FAILURE", 0, "beforeAll timed out")); err != nil {
+		t.Fatalf("ProcessGroupError failed: %v", err)
+	}
+	file := gm.GetRootGroups()[0]
+	var cart *TestGroup
+	for _, sg := range file.Subgroups {
+		cart = sg
+	}
+
+	// Without collapsing, Cart links its subgroup
+	content := gm.formatGroupReport(cart)
+	if !strings.Contains(content, "## Subgroups\n") || strings.Contains(content, "adds one") {
+		t.Errorf("Expected a subgroup table without the nested tests:\n%s", content)
+	}
+
+	// Level 2 (Cart) lists the tests below it by path and drops the subgroup table
+	gm.SetCollapseDepth(2)
+	content = gm.formatGroupReport(cart)
+	for _, want := range []string{
+		"- Group tests: 3\n",
+		"- Group tests failed: 1\n",
+		"- Subgroups: 3, collapsed into this report (--collapse-depth)\n",
+		"- ✓ loads\n",
+		"- ✕ add > bulk > adds many\n",
+		"- ✓ add > single > adds one\n",
+		"- Group: add > bulk\n- Type: SETUP_FAILURE\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected collapsed report to contain %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "## Subgroups\n") {
+		t.Errorf("Expected no subgroup table in the collapsed report:\n%s", content)
+	}
+
+	// Other levels keep their layout, and deeper groups their own reports
+	if content := gm.formatGroupReport(file); !strings.Contains(content, "## Subgroups\n") {
+		t.Errorf("Expected the file report to keep its subgroup table:\n%s", content)
+	}
+	add := cart.Subgroups[GenerateGroupID("add", cart.GetFullPath())]
+	if add == nil {
+		t.Fatal("Expected the add group")
+	}
+	if content := gm.formatGroupReport(add); !strings.Contains(content, "## Subgroups\n") {
+		t.Errorf("Expected groups below the collapsed level to keep their subgroup table:\n%s", content)
+	}
+}
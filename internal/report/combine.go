@@ -0,0 +1,267 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// CommandGroupMetadataKey marks the discovered event of a command's group in a combined
+// run's ipc.jsonl, so the command is not taken for a path when the run is replayed
+const CommandGroupMetadataKey = "command"
+
+// AddCommand adds the finished run of one command of a combined run (3pio cmd1 --and cmd2)
+// under a top-level group named after the command. run may be nil when the command never
+// ran, e.g. because no runner was detected; errorDetails then says why. The command's
+// warnings and abort reason are carried over, prefixed with its name, and the first exit
+// reason of the commands is kept. Its modified command and output.log are appended to
+// this run's, and its IPC events to this run's ipc.jsonl under the command's group, so
+// the combined run can be regenerated and merged like any other. The combined run starts
+// when its first command did. Call it after Initialize and before Finalize.
+func (m *Manager) AddCommand(name string, run *Manager, exitCode int, errorDetails string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var runGroups *GroupManager
+	if run != nil {
+		runGroups = run.groupManager
+		if run.startTime.Before(m.startTime) {
+			m.startTime = run.startTime
+			if m.state != nil {
+				m.state.Timestamp = run.startTime
+			}
+		}
+		if m.outputFile != nil {
+			if err := appendRunOutput(m.outputFile, "3pio command: "+name, run.runDir); err != nil {
+				m.logger.Error("Failed to copy output.log of %s: %v", run.runDir, err)
+			}
+		}
+		if run.state != nil && m.state != nil {
+			for _, warning := range run.state.Warnings {
+				m.state.Warnings = append(m.state.Warnings, fmt.Sprintf("%s: %s", name, warning))
+			}
+			if m.state.AbortReason == "" && run.state.AbortReason != "" {
+				m.state.AbortReason = fmt.Sprintf("%s: %s", name, run.state.AbortReason)
+			}
+			if m.state.ExitReason == "" {
+				m.state.ExitReason = run.state.ExitReason
+			}
+		}
+		if run.interrupted {
+			m.interrupted = true
+		}
+		if errorDetails == "" && run.state != nil && run.state.Status == "ERROR" {
+			errorDetails = run.state.ErrorDetails
+		}
+		if run.modifiedCommand != "" {
+			if m.modifiedCommand != "" {
+				m.modifiedCommand += " --and "
+			}
+			m.modifiedCommand += run.modifiedCommand
+		}
+	}
+
+	root := m.groupManager.Nest(name, runGroups)
+	if run != nil {
+		// The command's group spans the command, not just the groups it reported
+		root.StartTime = run.startTime
+		root.EndTime = run.runEnd()
+		root.Duration = root.EndTime.Sub(root.StartTime)
+	}
+	settleCommandGroup(root, exitCode, errorDetails)
+	var runDir string
+	if run != nil {
+		runDir = run.runDir
+	}
+	if err := m.writeCommandEvents(root, runDir); err != nil {
+		m.logger.Error("Failed to write the IPC events of %s: %v", name, err)
+	}
+	m.logger.Debug("Added command %s with %d test cases as group %s", name, root.Stats.TotalTestsRecursive, root.ID)
+}
+
+// writeCommandEvents appends the events of a command to this run's ipc.jsonl: those of
+// runDir's ipc.jsonl with the command's group added to their parents, between a start
+// and a result (or error) event for the group. runDir is empty when the command never ran.
+func (m *Manager) writeCommandEvents(root *TestGroup, runDir string) error {
+	path := filepath.Join(m.runDir, "ipc.jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	encoder := json.NewEncoder(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		if err := encoder.Encode(ipc.NewSchemaEvent()); err != nil {
+			return err
+		}
+	}
+
+	nest := func(parents []string) []string {
+		return append([]string{root.Name}, parents...)
+	}
+	discovered := ipc.NewGroupDiscoveredEvent(root.Name, nil)
+	discovered.Payload.Metadata = map[string]interface{}{CommandGroupMetadataKey: true}
+	if err := encoder.Encode(discovered); err != nil {
+		return err
+	}
+	if err := encoder.Encode(ipc.NewGroupStartEvent(root.Name, nil)); err != nil {
+		return err
+	}
+
+	if runDir != "" {
+		runIPC := filepath.Join(runDir, "ipc.jsonl")
+		if _, err := os.Stat(runIPC); err == nil {
+			err := ipc.ReplayFile(runIPC, m.logger, func(event ipc.Event) error {
+				switch e := event.(type) {
+				case ipc.GroupDiscoveredEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupStartEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupResultEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupErrorEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupTestCaseEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupStdoutChunkEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.GroupStderrChunkEvent:
+					e.Payload.ParentNames = nest(e.Payload.ParentNames)
+					event = e
+				case ipc.RunCompleteEvent:
+					// Only the combined run completes
+					return nil
+				}
+				return encoder.Encode(event)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	duration := float64(root.Duration.Milliseconds())
+	if root.Status == TestStatusError && root.ErrorInfo != nil {
+		return encoder.Encode(ipc.NewGroupErrorEvent(root.Name, nil, root.ErrorInfo.Type, duration, root.ErrorInfo.Message))
+	}
+	return encoder.Encode(ipc.NewGroupResultEvent(root.Name, nil, string(root.Status), duration))
+}
+
+// settleCommandGroup sets the status of a command's top-level group once its groups are
+// in. A command that failed without a failing test, such as a build error, fails its group
+// like a setup failure, so it is not mistaken for a passing command.
+func settleCommandGroup(root *TestGroup, exitCode int, errorDetails string) {
+	updateStatsKeepingSetupFailures(root)
+	if errorDetails == "" && exitCode != 0 && !root.HasFailures() {
+		errorDetails = fmt.Sprintf("Command exited with code %d", exitCode)
+	}
+	switch {
+	case errorDetails != "" && !root.HasFailures():
+		root.Status = TestStatusError
+		root.ErrorInfo = &TestError{Message: errorDetails, Type: "COMMAND_ERROR"}
+		root.Stats.SetupFailed = true
+	case errorDetails != "":
+		root.Status = TestStatusFail
+		root.ErrorInfo = &TestError{Message: errorDetails, Type: "COMMAND_ERROR"}
+	case len(root.Subgroups) == 0:
+		root.Status = TestStatusNoTests
+	}
+	if root.EndTime.IsZero() {
+		root.EndTime = time.Now()
+	}
+}
+
+// Nest adds the groups of other, a run of another command, to gm under a new root group
+// named name and returns that group. The groups get the IDs and report directories of
+// their new place in the hierarchy. A name already used by a root group gets a number
+// appended. other may be nil, which adds an empty group.
+func (gm *GroupManager) Nest(name string, other *GroupManager) *TestGroup {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	unique := name
+	for n := 2; gm.groups[GenerateGroupID(unique, nil)] != nil; n++ {
+		unique = fmt.Sprintf("%s (%d)", name, n)
+	}
+	now := time.Now()
+	root := &TestGroup{
+		ID:        GenerateGroupID(unique, nil),
+		Name:      unique,
+		Status:    TestStatusPending,
+		Created:   now,
+		Updated:   now,
+		Subgroups: make(map[string]*TestGroup),
+		TestCases: make([]TestCase, 0),
+	}
+	gm.groups[root.ID] = root
+	gm.addCommandName(unique)
+	gm.claimReportDir(root)
+	gm.rootGroups = append(gm.rootGroups, root)
+
+	if other == nil {
+		root.StartTime = now
+		return root
+	}
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	for _, group := range other.rootGroups {
+		rebaseGroup(group, root)
+		root.Subgroups[group.ID] = group
+		gm.adoptGroup(group)
+
+		if root.StartTime.IsZero() || (!group.StartTime.IsZero() && group.StartTime.Before(root.StartTime)) {
+			root.StartTime = group.StartTime
+		}
+		if group.Created.Before(root.Created) {
+			root.Created = group.Created
+		}
+		if group.EndTime.After(root.EndTime) {
+			root.EndTime = group.EndTime
+		}
+	}
+	if !root.StartTime.IsZero() && !root.EndTime.IsZero() {
+		root.Duration = root.EndTime.Sub(root.StartTime)
+	}
+	return root
+}
+
+// addCommandName records the name of a command's group, which normalizeToAbsolutePath
+// leaves as it is. Caller must hold gm.mu.
+func (gm *GroupManager) addCommandName(name string) {
+	if gm.commandNames == nil {
+		gm.commandNames = make(map[string]bool)
+	}
+	gm.commandNames[name] = true
+}
+
+// rebaseGroup moves group and its subgroups under parent, recomputing the IDs of the
+// groups and their test cases from their new paths
+func rebaseGroup(group, parent *TestGroup) {
+	group.ParentNames = append([]string{}, parent.GetFullPath()...)
+	group.ParentID = parent.ID
+	group.Depth = len(group.ParentNames)
+	group.ID = GenerateGroupID(group.Name, group.ParentNames)
+
+	fullPath := group.GetFullPath()
+	for i := range group.TestCases {
+		group.TestCases[i].ID = GenerateTestCaseID(group.TestCases[i].Name, fullPath)
+		group.TestCases[i].GroupID = group.ID
+	}
+
+	subgroups := make(map[string]*TestGroup, len(group.Subgroups))
+	for _, sg := range group.Subgroups {
+		rebaseGroup(sg, group)
+		subgroups[sg.ID] = sg
+	}
+	group.Subgroups = subgroups
+}
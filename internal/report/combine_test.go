@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// newCommandRun creates the report and ipc.jsonl of one command with the given test
+// cases, as if it had run alone
+func newCommandRun(t *testing.T, runDir, command string, cases []ipc.GroupTestCaseEvent) *Manager {
+	t.Helper()
+	manager, err := NewManager(runDir, runner.NewJestOutputParser(), &mockLogger{}, "jest", command)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize(command); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	ipcFile, err := os.Create(filepath.Join(runDir, "ipc.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to create ipc.jsonl: %v", err)
+	}
+	defer func() { _ = ipcFile.Close() }()
+	for _, event := range cases {
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+		if err := json.NewEncoder(ipcFile).Encode(event); err != nil {
+			t.Fatalf("Failed to write IPC event: %v", err)
+		}
+	}
+	return manager
+}
+
+func TestManager_AddCommand(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	web := newCommandRun(t, filepath.Join(runsDir, "web"), "npm test", []ipc.GroupTestCaseEvent{
+		ipc.NewGroupTestCaseEvent("renders", []string{"app.test.js", "App"}, "PASS"),
+	})
+	api := newCommandRun(t, filepath.Join(runsDir, "api"), "go test ./...", []ipc.GroupTestCaseEvent{
+		ipc.NewGroupTestCaseEvent("TestGet", []string{"example.com/api"}, "FAIL"),
+		ipc.NewGroupTestCaseEvent("TestPut", []string{"example.com/api"}, "PASS"),
+	})
+	if err := web.Finalize(0); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if err := api.Finalize(1); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	outDir := filepath.Join(runsDir, "combined")
+	combined, err := NewManager(outDir, nil, &mockLogger{}, "jest, go", "")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := combined.Initialize("npm test --and go test ./... --and frobnicate"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	combined.AddCommand("npm test", web, 0, "")
+	combined.AddCommand("go test ./...", api, 1, "")
+	combined.AddCommand("frobnicate", nil, 1, "no test runner detected")
+	combined.AddCommand("npm test", nil, 0, "")
+
+	roots := combined.groupManager.GetRootGroups()
+	var names []string
+	for _, root := range roots {
+		names = append(names, root.Name)
+	}
+	if want := []string{"npm test", "go test ./...", "frobnicate", "npm test (2)"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected a top-level group per command %q, got %q", want, names)
+	}
+	if roots[0].Status != TestStatusPass || roots[1].Status != TestStatusFail {
+		t.Errorf("Expected npm test to pass and go test to fail, got %s and %s", roots[0].Status, roots[1].Status)
+	}
+	if roots[2].Status != TestStatusError || roots[2].ErrorInfo == nil || roots[2].ErrorInfo.Message != "no test runner detected" {
+		t.Errorf("Expected the command that never ran to be an error, got %s %+v", roots[2].Status, roots[2].ErrorInfo)
+	}
+	if roots[3].Status != TestStatusNoTests {
+		t.Errorf("Expected an empty command to have no tests, got %s", roots[3].Status)
+	}
+
+	// The combined run and each command's group span the commands, not the combining
+	if !combined.startTime.Equal(web.startTime) {
+		t.Errorf("Expected the combined run to start with the first command at %v, got %v", web.startTime, combined.startTime)
+	}
+	if !roots[1].StartTime.Equal(api.startTime) || !roots[1].EndTime.Equal(api.endTime) {
+		t.Errorf("Expected the go test group to span its run %v-%v, got %v-%v", api.startTime, api.endTime, roots[1].StartTime, roots[1].EndTime)
+	}
+
+	// Groups and test cases get the IDs of their place under the command's group
+	appPath := web.groupManager.normalizeToAbsolutePath("app.test.js")
+	parents := []string{"npm test", appPath}
+	app, ok := combined.groupManager.GetGroup(GenerateGroupID("App", parents))
+	if !ok {
+		t.Fatalf("Expected the App group under %q", parents)
+	}
+	if !reflect.DeepEqual(app.ParentNames, parents) || app.Depth != 2 {
+		t.Errorf("Expected App at depth 2 under %q, got depth %d under %q", parents, app.Depth, app.ParentNames)
+	}
+	wantCaseID := GenerateTestCaseID("renders", append(parents, "App"))
+	if len(app.TestCases) != 1 || app.TestCases[0].ID != wantCaseID || app.TestCases[0].GroupID != app.ID {
+		t.Errorf("Expected the test case to be rebased, got %+v", app.TestCases)
+	}
+
+	if err := combined.Finalize(1); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if counts := combined.RunCounts(); counts.Total != 3 || counts.Failed != 1 {
+		t.Errorf("Expected the test cases of all commands, got %+v", counts)
+	}
+	content, err := os.ReadFile(filepath.Join(outDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	for _, expected := range []string{"| go test ./... |", "| frobnicate |"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected %q in test-run.md:\n%s", expected, content)
+		}
+	}
+	output, err := os.ReadFile(filepath.Join(outDir, "output.log"))
+	if err != nil {
+		t.Fatalf("Failed to read output.log: %v", err)
+	}
+	if !strings.Contains(string(output), "# 3pio command: go test ./...") {
+		t.Errorf("Expected each command's output under a header in output.log:\n%s", output)
+	}
+
+	// ipc.jsonl replays into the same groups, with the commands' names kept as they are
+	summary, err := Regenerate(outDir, nil, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Regenerate failed: %v", err)
+	}
+	if summary.Counts.Total != 3 || summary.Counts.Failed != 1 || summary.ExitCode != 1 {
+		t.Errorf("Expected the replayed run to keep its results, got %+v", summary)
+	}
+	regenerated, err := os.ReadFile(filepath.Join(outDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	for _, expected := range []string{"| FAIL | go test ./... |", "| ERROR | frobnicate |", "| PASS | npm test |", "| NO_TESTS | npm test (2) |"} {
+		if !strings.Contains(string(regenerated), expected) {
+			t.Errorf("Expected %q in the regenerated test-run.md:\n%s", expected, regenerated)
+		}
+	}
+}
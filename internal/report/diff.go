@@ -0,0 +1,280 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner"
+)
+
+// RunDiff lists how the test results of a run changed since an earlier run of the same suite
+type RunDiff struct {
+	OldRunID     string     `json:"oldRunId"`
+	NewRunID     string     `json:"newRunId"`
+	NewlyFailing []DiffTest `json:"newlyFailing"`    // Failing now, but not failing (or absent) before
+	Fixed        []DiffTest `json:"fixed"`           // Failing before, passing now
+	Disappeared  []DiffTest `json:"disappeared"`     // Reported before, not reported now
+	Moved        []DiffTest `json:"moved,omitempty"` // Matched across a code move by stable key (--match-moved)
+}
+
+// DiffTest identifies a test case in a RunDiff by name and parent hierarchy
+type DiffTest struct {
+	Name           string     `json:"name"`
+	ParentNames    []string   `json:"parentNames"`
+	OldParentNames []string   `json:"oldParentNames,omitempty"` // Set when the test moved since the old run
+	StableKey      string     `json:"stableKey,omitempty"`
+	OldStatus      TestStatus `json:"oldStatus,omitempty"` // Empty for tests the old run didn't report
+	NewStatus      TestStatus `json:"newStatus,omitempty"` // Empty for tests that disappeared
+	ErrorMessage   string     `json:"errorMessage,omitempty"`
+}
+
+// Diff compares the tests of two runs. Each run's ipc.jsonl is replayed (run.json only
+// lists failures, so it can't tell which tests disappeared) and test cases are matched by
+// their full hierarchical path. With matchMoved, tests left unmatched are then matched by
+// stable key, so tests in moved or renamed files are still compared. Neither run's reports
+// are modified.
+func Diff(oldRunDir, newRunDir string, matchMoved bool, runners *runner.Manager, lg Logger) (RunDiff, error) {
+	if lg == nil {
+		lg = &noopLogger{}
+	}
+
+	// Runs are replayed into a scratch directory so their own reports stay untouched
+	scratchDir, err := os.MkdirTemp("", "3pio-diff-")
+	if err != nil {
+		return RunDiff{}, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(scratchDir) }()
+
+	var tests [2]map[string]DiffTest
+	for i, runDir := range []string{oldRunDir, newRunDir} {
+		run, result, err := replayRun(runDir, filepath.Join(scratchDir, fmt.Sprint(i)), runners, lg)
+		if err != nil {
+			return RunDiff{}, fmt.Errorf("failed to replay %s: %w", runDir, err)
+		}
+		// Finalizing settles incomplete groups and stops the run's pending writes
+		if err := run.Finalize(result.exitCode, result.errorDetails); err != nil {
+			return RunDiff{}, fmt.Errorf("failed to replay %s: %w", runDir, err)
+		}
+		tests[i] = collectTestResults(run.groupManager.GetRootGroups())
+	}
+
+	if matchMoved {
+		MatchMovedTests(tests[0], tests[1])
+	}
+	diff := CompareTestResults(tests[0], tests[1])
+	diff.OldRunID = runID(oldRunDir)
+	diff.NewRunID = runID(newRunDir)
+	lg.Debug("Diffed %s against %s: %d newly failing, %d fixed, %d disappeared, %d moved",
+		newRunDir, oldRunDir, len(diff.NewlyFailing), len(diff.Fixed), len(diff.Disappeared), len(diff.Moved))
+	return diff, nil
+}
+
+// runID returns the ID run.json records for the run in runDir, or the directory name
+func runID(runDir string) string {
+	if meta, err := readRunMetadata(runDir); err == nil && meta.summary != nil && meta.summary.RunID != "" {
+		return meta.summary.RunID
+	}
+	return filepath.Base(filepath.Clean(runDir))
+}
+
+// collectTestResults returns every test case under groups, keyed by testPathKey
+func collectTestResults(groups []*TestGroup) map[string]DiffTest {
+	tests := make(map[string]DiffTest)
+	var collect func(group *TestGroup)
+	collect = func(group *TestGroup) {
+		// Copy since GetFullPath may share its backing array with ParentNames
+		parentNames := append([]string{}, group.GetFullPath()...)
+		for _, tc := range group.TestCases {
+			test := DiffTest{
+				Name:        tc.Name,
+				ParentNames: parentNames,
+				StableKey:   GenerateStableTestKey(tc.Name, parentNames),
+				NewStatus:   tc.Status,
+			}
+			if tc.Error != nil {
+				test.ErrorMessage = tc.Error.Message
+			}
+			tests[testPathKey(parentNames, tc.Name)] = test
+		}
+		for _, sg := range group.Subgroups {
+			collect(sg)
+		}
+	}
+	for _, group := range groups {
+		collect(group)
+	}
+	return tests
+}
+
+// testPathKey joins a test's parent names and name into the key runs are compared on
+func testPathKey(parentNames []string, name string) string {
+	return strings.Join(append(append([]string{}, parentNames...), name), "\x00")
+}
+
+// MatchMovedTests pairs tests that only the old run reported with tests that only the
+// new run reported when they share a stable key, and files each paired old test under the
+// new test's key. A key shared by more than one unmatched test on either side is ambiguous
+// and left alone. Returns the number of tests paired.
+func MatchMovedTests(oldTests, newTests map[string]DiffTest) int {
+	unmatched := func(tests, other map[string]DiffTest) map[string][]string {
+		byStableKey := make(map[string][]string)
+		for key, test := range tests {
+			if _, ok := other[key]; !ok && test.StableKey != "" {
+				byStableKey[test.StableKey] = append(byStableKey[test.StableKey], key)
+			}
+		}
+		return byStableKey
+	}
+	oldKeys := unmatched(oldTests, newTests)
+	newKeys := unmatched(newTests, oldTests)
+
+	paired := 0
+	for stableKey, oldPaths := range oldKeys {
+		newPaths := newKeys[stableKey]
+		if len(oldPaths) != 1 || len(newPaths) != 1 {
+			continue
+		}
+		oldTests[newPaths[0]] = oldTests[oldPaths[0]]
+		delete(oldTests, oldPaths[0])
+		paired++
+	}
+	return paired
+}
+
+// CompareTestResults compares two runs' test cases, keyed by full hierarchical path with
+// their status in NewStatus, and lists the tests that started failing, were fixed or
+// disappeared. A test that is new and failing counts as newly failing. An old test filed
+// under another path than its own was paired by MatchMovedTests and is listed as moved.
+// Each list is sorted by path.
+func CompareTestResults(oldTests, newTests map[string]DiffTest) RunDiff {
+	diff := RunDiff{
+		NewlyFailing: []DiffTest{},
+		Fixed:        []DiffTest{},
+		Disappeared:  []DiffTest{},
+	}
+
+	for key, test := range newTests {
+		old, existed := oldTests[key]
+		if existed {
+			test.OldStatus = old.NewStatus
+			if testPathKey(old.ParentNames, old.Name) != key {
+				test.OldParentNames = old.ParentNames
+				diff.Moved = append(diff.Moved, test)
+			}
+		}
+		switch {
+		case test.NewStatus == TestStatusFail && test.OldStatus != TestStatusFail:
+			diff.NewlyFailing = append(diff.NewlyFailing, test)
+		case test.NewStatus == TestStatusPass && test.OldStatus == TestStatusFail:
+			test.ErrorMessage = old.ErrorMessage
+			diff.Fixed = append(diff.Fixed, test)
+		}
+	}
+	for key, old := range oldTests {
+		if _, exists := newTests[key]; !exists {
+			diff.Disappeared = append(diff.Disappeared, DiffTest{
+				Name:        old.Name,
+				ParentNames: old.ParentNames,
+				StableKey:   old.StableKey,
+				OldStatus:   old.NewStatus,
+			})
+		}
+	}
+
+	for _, tests := range [][]DiffTest{diff.NewlyFailing, diff.Fixed, diff.Disappeared, diff.Moved} {
+		sort.Slice(tests, func(i, j int) bool {
+			return testPathKey(tests[i].ParentNames, tests[i].Name) < testPathKey(tests[j].ParentNames, tests[j].Name)
+		})
+	}
+	return diff
+}
+
+// Markdown renders the diff as the human-readable diff.md
+func (d RunDiff) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# 3pio run diff\n\n")
+	fmt.Fprintf(&sb, "- Old run: %s\n", d.OldRunID)
+	fmt.Fprintf(&sb, "- New run: %s\n", d.NewRunID)
+	fmt.Fprintf(&sb, "- Newly failing: %d\n", len(d.NewlyFailing))
+	fmt.Fprintf(&sb, "- Fixed: %d\n", len(d.Fixed))
+	fmt.Fprintf(&sb, "- Disappeared: %d\n", len(d.Disappeared))
+	if len(d.Moved) > 0 {
+		fmt.Fprintf(&sb, "- Moved: %d\n", len(d.Moved))
+	}
+
+	writeDiffSection(&sb, "Newly failing", d.NewlyFailing, func(t DiffTest) string {
+		if t.OldStatus == "" {
+			return "new test"
+		}
+		return fmt.Sprintf("was %s", t.OldStatus)
+	})
+	writeDiffSection(&sb, "Fixed", d.Fixed, nil)
+	writeDiffSection(&sb, "Disappeared", d.Disappeared, func(t DiffTest) string {
+		return fmt.Sprintf("was %s", t.OldStatus)
+	})
+	writeDiffSection(&sb, "Moved", d.Moved, func(t DiffTest) string {
+		return fmt.Sprintf("from %s", displayTestPath(t.OldParentNames, t.Name))
+	})
+	return sb.String()
+}
+
+// writeDiffSection writes one list of a diff, skipping empty lists. note, if not nil,
+// returns what to say about a test besides its path and error.
+func writeDiffSection(sb *strings.Builder, title string, tests []DiffTest, note func(DiffTest) string) {
+	if len(tests) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n## %s\n\n", title)
+	for _, t := range tests {
+		fmt.Fprintf(sb, "- %s", displayTestPath(t.ParentNames, t.Name))
+		if note != nil {
+			fmt.Fprintf(sb, " (%s)", note(t))
+		}
+		sb.WriteString("\n")
+		if t.ErrorMessage != "" {
+			firstLine, _, _ := strings.Cut(strings.TrimSpace(t.ErrorMessage), "\n")
+			fmt.Fprintf(sb, "  - %s\n", firstLine)
+		}
+	}
+}
+
+// displayTestPath formats a test's path for diff.md, showing file paths under the
+// working directory relative to it
+func displayTestPath(parentNames []string, name string) string {
+	parts := make([]string, 0, len(parentNames)+1)
+	for _, parent := range parentNames {
+		parts = append(parts, displayGroupName(parent))
+	}
+	return BuildHierarchicalPathFromSlice(append(parts, name))
+}
+
+// displayGroupName shows a group named by a file path under the working directory
+// relative to it, and any other group name as-is
+func displayGroupName(name string) string {
+	cwd, _ := os.Getwd()
+	if cwd != "" && filepath.IsAbs(name) {
+		if rel, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return name
+}
+
+// WriteDiff writes the diff to dir as diff.md and diff.json
+func WriteDiff(dir string, diff RunDiff) error {
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diff: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "diff.json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write diff.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "diff.md"), []byte(diff.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write diff.md: %w", err)
+	}
+	return nil
+}
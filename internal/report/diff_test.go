@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff_Runs(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	oldRun := filepath.Join(runsDir, "20250924T120000-old")
+	newRun := filepath.Join(runsDir, "20250924T130000-new")
+
+	writeReplayableRun(t, oldRun, []string{
+		`{"eventType":"testCase","payload":{"testName":"adds items","parentNames":["cart.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testCase","payload":{"testName":"removes items","parentNames":["cart.test.js"],"status":"FAIL","error":{"message":"expected 0 items"}}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"cart.test.js","parentNames":[],"status":"FAIL"}}`,
+		`{"eventType":"testCase","payload":{"testName":"rounds","parentNames":["util.test.js","prices"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"util.test.js","parentNames":[],"status":"PASS"}}`,
+	})
+	writeReplayableRun(t, newRun, []string{
+		`{"eventType":"testCase","payload":{"testName":"adds items","parentNames":["cart.test.js"],"status":"FAIL","error":{"message":"expected 2 items\nat cart.test.js:4"}}}`,
+		`{"eventType":"testCase","payload":{"testName":"removes items","parentNames":["cart.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testCase","payload":{"testName":"empties","parentNames":["cart.test.js"],"status":"FAIL"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"cart.test.js","parentNames":[],"status":"FAIL"}}`,
+		// Same name, different describe block: not the same test
+		`{"eventType":"testCase","payload":{"testName":"rounds","parentNames":["util.test.js","totals"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"util.test.js","parentNames":[],"status":"PASS"}}`,
+	})
+
+	diff, err := Diff(oldRun, newRun, false, nil, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	names := func(tests []DiffTest) []string {
+		var out []string
+		for _, test := range tests {
+			out = append(out, test.Name)
+		}
+		return out
+	}
+	if got := names(diff.NewlyFailing); strings.Join(got, ",") != "adds items,empties" {
+		t.Errorf("Expected adds items and empties newly failing, got %v", got)
+	}
+	if got := names(diff.Fixed); strings.Join(got, ",") != "removes items" {
+		t.Errorf("Expected removes items fixed, got %v", got)
+	}
+	if len(diff.Disappeared) != 1 || diff.Disappeared[0].Name != "rounds" ||
+		diff.Disappeared[0].ParentNames[len(diff.Disappeared[0].ParentNames)-1] != "prices" {
+		t.Errorf("Expected prices > rounds to have disappeared, got %+v", diff.Disappeared)
+	}
+	if diff.NewlyFailing[0].OldStatus != TestStatusPass || diff.NewlyFailing[1].OldStatus != "" {
+		t.Errorf("Expected old statuses PASS and none, got %+v", diff.NewlyFailing)
+	}
+	if diff.OldRunID != "20250924T120000-old" || diff.NewRunID != "20250924T130000-new" {
+		t.Errorf("Expected run IDs from the run directories, got %q and %q", diff.OldRunID, diff.NewRunID)
+	}
+
+	// Neither run's reports are touched
+	if _, err := os.Stat(filepath.Join(newRun, "run.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected Diff to leave the new run without run.json, got %v", err)
+	}
+
+	if err := WriteDiff(newRun, diff); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	markdown, err := os.ReadFile(filepath.Join(newRun, "diff.md"))
+	if err != nil {
+		t.Fatalf("Failed to read diff.md: %v", err)
+	}
+	for _, want := range []string{
+		"- Newly failing: 2\n",
+		"- cart.test.js → adds items (was PASS)\n  - expected 2 items\n",
+		"- cart.test.js → empties (new test)\n",
+		"## Fixed\n\n- cart.test.js → removes items\n  - expected 0 items\n",
+		"(was PASS)",
+	} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("Expected diff.md to contain %q:\n%s", want, markdown)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(newRun, "diff.json"))
+	if err != nil {
+		t.Fatalf("Failed to read diff.json: %v", err)
+	}
+	var decoded RunDiff
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to parse diff.json: %v", err)
+	}
+	if len(decoded.NewlyFailing) != 2 || len(decoded.Fixed) != 1 || len(decoded.Disappeared) != 1 {
+		t.Errorf("Expected diff.json to match the diff, got %+v", decoded)
+	}
+}
+
+func TestCompareTestResults_Unchanged(t *testing.T) {
+	tests := map[string]DiffTest{
+		testPathKey([]string{"a.test.js"}, "passes"): {Name: "passes", ParentNames: []string{"a.test.js"}, NewStatus: TestStatusPass},
+		testPathKey([]string{"a.test.js"}, "fails"):  {Name: "fails", ParentNames: []string{"a.test.js"}, NewStatus: TestStatusFail},
+		testPathKey([]string{"a.test.js"}, "skips"):  {Name: "skips", ParentNames: []string{"a.test.js"}, NewStatus: TestStatusSkip},
+	}
+
+	diff := CompareTestResults(tests, tests)
+	if len(diff.NewlyFailing) != 0 || len(diff.Fixed) != 0 || len(diff.Disappeared) != 0 {
+		t.Errorf("Expected no changes between identical runs, got %+v", diff)
+	}
+}
+
+func TestCompareTestResults_MatchMoved(t *testing.T) {
+	add := func(tests map[string]DiffTest, status TestStatus, name string, parents ...string) {
+		tests[testPathKey(parents, name)] = DiffTest{
+			Name:        name,
+			ParentNames: parents,
+			StableKey:   GenerateStableTestKey(name, parents),
+			NewStatus:   status,
+		}
+	}
+	oldTests := make(map[string]DiffTest)
+	add(oldTests, TestStatusFail, "adds items", "/app/src/cart.test.js", "Cart")
+	add(oldTests, TestStatusPass, "rounds", "/app/src/util.test.js", "prices")
+	// The same test in two files: ambiguous, so never matched
+	add(oldTests, TestStatusPass, "renders", "/app/src/a.test.js")
+	add(oldTests, TestStatusPass, "renders", "/app/src/b.test.js")
+	newTests := make(map[string]DiffTest)
+	add(newTests, TestStatusPass, "adds items", "/app/src/shop/cart.test.js", "Cart")
+	add(newTests, TestStatusPass, "rounds", "/app/src/util.test.js", "prices")
+	add(newTests, TestStatusPass, "renders", "/app/src/ui/a.test.js")
+
+	// Matched by path alone, the moved test disappears and comes back as new
+	diff := CompareTestResults(oldTests, newTests)
+	if len(diff.Fixed) != 0 || len(diff.Disappeared) != 3 || len(diff.Moved) != 0 {
+		t.Errorf("Expected no fixes and 3 disappeared tests without matching moves, got %+v", diff)
+	}
+
+	if paired := MatchMovedTests(oldTests, newTests); paired != 1 {
+		t.Errorf("Expected 1 test paired, got %d", paired)
+	}
+	diff = CompareTestResults(oldTests, newTests)
+	if len(diff.Fixed) != 1 || diff.Fixed[0].Name != "adds items" || len(diff.Disappeared) != 2 {
+		t.Errorf("Expected the moved test to be fixed and only the ambiguous tests to disappear, got %+v", diff)
+	}
+	if len(diff.Moved) != 1 || diff.Moved[0].OldParentNames[0] != "/app/src/cart.test.js" ||
+		diff.Moved[0].ParentNames[0] != "/app/src/shop/cart.test.js" {
+		t.Errorf("Expected adds items moved from src to src/shop, got %+v", diff.Moved)
+	}
+	if !strings.Contains(diff.Markdown(), "## Moved\n\n- /app/src/shop/cart.test.js → Cart → adds items (from /app/src/cart.test.js → Cart → adds items)\n") {
+		t.Errorf("Expected a Moved section in diff.md:\n%s", diff.Markdown())
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// durationPercentiles summarizes the distribution of test case durations in a run
+type durationPercentiles struct {
+	count int
+	p50   time.Duration
+	p95   time.Duration
+	p99   time.Duration
+}
+
+// collectTestCaseDurations appends the durations of the test cases in group and its
+// subgroups that ran (passed or failed, expectedly or not) to durations
+func collectTestCaseDurations(group *TestGroup, durations []time.Duration) []time.Duration {
+	for _, test := range group.TestCases {
+		switch test.Status {
+		case TestStatusPass, TestStatusFail, TestStatusXPass, TestStatusXFail:
+			durations = append(durations, test.Duration)
+		}
+	}
+	for _, subgroup := range group.Subgroups {
+		durations = collectTestCaseDurations(subgroup, durations)
+	}
+	return durations
+}
+
+// computeDurationPercentiles returns the p50, p95 and p99 of durations using the
+// nearest-rank method, so each percentile is a duration some test actually took.
+// durations is sorted in place. ok is false when there are no durations.
+func computeDurationPercentiles(durations []time.Duration) (stats durationPercentiles, ok bool) {
+	if len(durations) == 0 {
+		return stats, false
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durationPercentiles{
+		count: len(durations),
+		p50:   nearestRank(durations, 50),
+		p95:   nearestRank(durations, 95),
+		p99:   nearestRank(durations, 99),
+	}, true
+}
+
+// nearestRank returns the p-th percentile of sorted, which must not be empty
+func nearestRank(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeDurationPercentiles(t *testing.T) {
+	if _, ok := computeDurationPercentiles(nil); ok {
+		t.Error("Expected no percentiles without durations")
+	}
+
+	one, ok := computeDurationPercentiles([]time.Duration{3 * time.Second})
+	if !ok || one.count != 1 || one.p50 != 3*time.Second || one.p99 != 3*time.Second {
+		t.Errorf("Expected every percentile of one test to be its duration, got %+v", one)
+	}
+
+	// 100 tests taking 1ms to 100ms, given in reverse order
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	stats, ok := computeDurationPercentiles(durations)
+	if !ok {
+		t.Fatal("Expected percentiles")
+	}
+	want := durationPercentiles{count: 100, p50: 50 * time.Millisecond, p95: 95 * time.Millisecond, p99: 99 * time.Millisecond}
+	if stats != want {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+
+	// Percentiles are durations that tests actually took
+	stats, _ = computeDurationPercentiles([]time.Duration{time.Second, 10 * time.Second})
+	if stats.p50 != time.Second || stats.p95 != 10*time.Second {
+		t.Errorf("Expected p50 1s and p95 10s, got %+v", stats)
+	}
+}
+
+func TestCollectTestCaseDurations(t *testing.T) {
+	group := &TestGroup{
+		TestCases: []TestCase{
+			{Name: "passes", Status: TestStatusPass, Duration: time.Second},
+			{Name: "skipped", Status: TestStatusSkip},
+		},
+		Subgroups: map[string]*TestGroup{
+			"sub": {TestCases: []TestCase{{Name: "fails", Status: TestStatusFail, Duration: 2 * time.Second}}},
+		},
+	}
+
+	durations := collectTestCaseDurations(group, nil)
+	if len(durations) != 2 {
+		t.Errorf("Expected the durations of the two tests that ran, got %v", durations)
+	}
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// failureKindOrder is the order failure kinds are listed in the summary
+var failureKindOrder = []ipc.FailureKind{
+	ipc.FailureKindAssertion,
+	ipc.FailureKindException,
+	ipc.FailureKindTimeout,
+	ipc.FailureKindDataRace,
+	ipc.FailureKindSkipAsFail,
+	ipc.FailureKindUnknown,
+}
+
+// countFailureKinds adds the kind of each failed test case in group and its subgroups to
+// counts. Failures without an error count as unknown.
+func countFailureKinds(group *TestGroup, counts map[ipc.FailureKind]int) {
+	for _, tc := range group.TestCases {
+		if tc.Status != TestStatusFail {
+			continue
+		}
+		kind := ipc.FailureKindUnknown
+		if tc.Error != nil && tc.Error.Kind != "" {
+			kind = tc.Error.Kind
+		}
+		counts[kind]++
+	}
+	for _, sg := range group.Subgroups {
+		countFailureKinds(sg, counts)
+	}
+}
+
+// formatFailureKinds lists the non-zero counts, e.g. "2 assertion, 1 timeout"
+func formatFailureKinds(counts map[ipc.FailureKind]int) string {
+	var parts []string
+	for _, kind := range failureKindOrder {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,57 @@
+package report
+
+// SetMaxFailures caps how many failed test cases keep their error details (--max-failures).
+// Failures past the cap are still recorded and counted, with their failure kind and
+// location, but without the message, stack trace or output. Zero means no cap.
+func (gm *GroupManager) SetMaxFailures(limit int) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.maxFailures = limit
+}
+
+// limitFailureDetail drops the error details of a failed test case once the cap on
+// detailed failures is reached. A test case that already has its details keeps them
+// across retries. Caller must hold gm.mu.
+func (gm *GroupManager) limitFailureDetail(testCase *TestCase) {
+	if gm.maxFailures <= 0 {
+		return
+	}
+	if testCase.Status != TestStatusFail {
+		delete(gm.detailedFailures, testCase.ID)
+		delete(gm.omittedFailures, testCase.ID)
+		return
+	}
+	if gm.detailedFailures[testCase.ID] {
+		return
+	}
+	if len(gm.detailedFailures) < gm.maxFailures {
+		gm.detailedFailures[testCase.ID] = true
+		return
+	}
+
+	gm.omittedFailures[testCase.ID] = true
+	if testCase.Error != nil {
+		testCase.Error = &TestError{
+			Location:      testCase.Error.Location,
+			Type:          testCase.Error.Type,
+			Kind:          testCase.Error.Kind,
+			DetailOmitted: true,
+		}
+	}
+	testCase.Stdout = ""
+	testCase.Stderr = ""
+}
+
+// OmittedFailureDetails returns the cap on detailed failures and how many failed test
+// cases went past it without their error details
+func (gm *GroupManager) OmittedFailureDetails() (limit, omitted int) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.maxFailures, len(gm.omittedFailures)
+}
+
+// SetMaxFailures caps how many failed test cases keep their error details in the
+// reports; test-run.md says how many more failed without them
+func (m *Manager) SetMaxFailures(limit int) {
+	m.groupManager.SetMaxFailures(limit)
+}
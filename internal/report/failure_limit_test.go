@@ -0,0 +1,109 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func failedTestEvent(name string) ipc.GroupTestCaseEvent {
+	return ipc.GroupTestCaseEvent{
+		EventType: "testCase",
+		Payload: ipc.TestCasePayload{
+			TestName:    name,
+			ParentNames: []string{"broken.test.js"},
+			Status:      "FAIL",
+			Stdout:      "debug output",
+			Error: &ipc.TestError{
+				Message:   "expected 1 to be 2",
+				Stack:     "at broken.test.js:3",
+				Location:  "broken.test.js:3",
+				ErrorType: "AssertionError",
+			},
+		},
+	}
+}
+
+func TestGroupManager_MaxFailures(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", nil)
+	gm.SetMaxFailures(2)
+
+	for i := 1; i <= 4; i++ {
+		if err := gm.ProcessTestCase(failedTestEvent(fmt.Sprintf("fails %d", i))); err != nil {
+			t.Fatalf("Failed to process test case: %v", err)
+		}
+	}
+	// A retry of a test that kept its details keeps them
+	_ = gm.ProcessTestCase(failedTestEvent("fails 1"))
+
+	group, _ := gm.GetGroup(GenerateGroupID(gm.normalizeToAbsolutePath("broken.test.js"), nil))
+	if group == nil {
+		t.Fatal("Expected broken.test.js group")
+	}
+	if len(group.TestCases) != 4 {
+		t.Fatalf("Expected all 4 failures recorded, got %d", len(group.TestCases))
+	}
+	for _, tc := range group.TestCases {
+		omitted := tc.Name == "fails 3" || tc.Name == "fails 4"
+		if tc.Error == nil || tc.Error.DetailOmitted != omitted {
+			t.Fatalf("%s: expected DetailOmitted=%v, got %+v", tc.Name, omitted, tc.Error)
+		}
+		if omitted {
+			if tc.Error.Message != "" || tc.Error.Stack != "" || tc.Stdout != "" {
+				t.Errorf("%s: expected details dropped, got %+v with stdout %q", tc.Name, tc.Error, tc.Stdout)
+			}
+			if tc.Error.Kind != ipc.FailureKindAssertion || tc.Error.Location != "broken.test.js:3" {
+				t.Errorf("%s: expected failure kind and location kept, got %+v", tc.Name, tc.Error)
+			}
+		} else if tc.Error.Message != "expected 1 to be 2" {
+			t.Errorf("%s: expected message kept, got %q", tc.Name, tc.Error.Message)
+		}
+	}
+	if limit, omitted := gm.OmittedFailureDetails(); limit != 2 || omitted != 2 {
+		t.Errorf("Expected 2 failures omitted past 2, got %d past %d", omitted, limit)
+	}
+
+	// A test that passes on retry is no longer an omitted failure
+	_ = gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("fails 4", []string{"broken.test.js"}, "PASS"))
+	if _, omitted := gm.OmittedFailureDetails(); omitted != 1 {
+		t.Errorf("Expected 1 failure omitted after a retry passed, got %d", omitted)
+	}
+
+	content := gm.formatGroupReport(group)
+	if !strings.Contains(content, "Error details omitted (--max-failures)") {
+		t.Errorf("Expected the report to note omitted details:\n%s", content)
+	}
+}
+
+func TestManager_MaxFailuresSummary(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetMaxFailures(1)
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		_ = manager.HandleEvent(failedTestEvent(fmt.Sprintf("fails %d", i)))
+	}
+	_ = manager.Finalize(1)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, want := range []string{
+		"- Test cases failed: 3",
+		"- Failure details: kept for the first 1 failures, omitted for 2 more (--max-failures)",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in test-run.md:\n%s", want, content)
+		}
+	}
+}
@@ -4,14 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"runtime"
 	"strings"
 )
 
 // GenerateGroupID generates a unique ID for a test group based on its full path
 // The ID is a truncated SHA256 hash of the hierarchical path
 func GenerateGroupID(groupName string, parentNames []string) string {
-	fullPath := append(parentNames, groupName)
-	pathString := strings.Join(fullPath, ":")
+	pathString := idPathString(append(append([]string{}, parentNames...), groupName))
 
 	hash := sha256.Sum256([]byte(pathString))
 	// Use first 16 bytes (32 hex chars) for ID
@@ -20,8 +20,7 @@ func GenerateGroupID(groupName string, parentNames []string) string {
 
 // GenerateTestCaseID generates a unique ID for a test case
 func GenerateTestCaseID(testName string, parentNames []string) string {
-	fullPath := append(parentNames, testName)
-	pathString := strings.Join(fullPath, ":")
+	pathString := idPathString(append(append([]string{}, parentNames...), testName))
 
 	hash := sha256.Sum256([]byte(pathString))
 	// Use first 16 bytes (32 hex chars) for ID
@@ -34,11 +33,37 @@ func GenerateGroupIDFromPath(path []string) string {
 		return ""
 	}
 
-	pathString := strings.Join(path, ":")
+	pathString := idPathString(path)
 	hash := sha256.Sum256([]byte(pathString))
 	return hex.EncodeToString(hash[:16])
 }
 
+// idPathString joins a hierarchy into the string its ID is hashed from. On Windows, file
+// paths are first put in one spelling, so C:\foo and c:/foo get the same ID.
+func idPathString(path []string) string {
+	if runtime.GOOS != "windows" {
+		return strings.Join(path, ":")
+	}
+	parts := make([]string, len(path))
+	for i, part := range path {
+		parts[i] = canonicalWindowsPath(part)
+	}
+	return strings.Join(parts, ":")
+}
+
+// canonicalWindowsPath spells a Windows drive path with a lowercase drive letter and
+// backslash separators. Anything else, including UNC paths, is returned unchanged.
+func canonicalWindowsPath(name string) string {
+	if len(name) < 3 || name[1] != ':' || (name[2] != '\\' && name[2] != '/') {
+		return name
+	}
+	drive := name[0] | 0x20 // ASCII lowercase
+	if drive < 'a' || drive > 'z' {
+		return name
+	}
+	return string(drive) + strings.ReplaceAll(name[1:], "/", `\`)
+}
+
 // ParseHierarchy parses a hierarchical path and returns parent names and the item name
 func ParseHierarchy(fullPath []string) (parentNames []string, itemName string) {
 	if len(fullPath) == 0 {
@@ -123,18 +148,40 @@ func ExtractFileFromPath(path []string) string {
 
 	// The first element is typically the file path
 	first := path[0]
-
-	// Check if it looks like a file path
-	if strings.Contains(first, "/") || strings.Contains(first, "\\") ||
-		strings.HasSuffix(first, ".js") || strings.HasSuffix(first, ".ts") ||
-		strings.HasSuffix(first, ".jsx") || strings.HasSuffix(first, ".tsx") ||
-		strings.HasSuffix(first, ".py") || strings.HasSuffix(first, ".go") {
+	if looksLikeFilePath(first) {
 		return first
 	}
 
 	return ""
 }
 
+// looksLikeFilePath reports whether a group name is a file or package path rather than
+// a suite name
+func looksLikeFilePath(name string) bool {
+	return strings.Contains(name, "/") || strings.Contains(name, "\\") ||
+		strings.HasSuffix(name, ".js") || strings.HasSuffix(name, ".ts") ||
+		strings.HasSuffix(name, ".jsx") || strings.HasSuffix(name, ".tsx") ||
+		strings.HasSuffix(name, ".py") || strings.HasSuffix(name, ".go")
+}
+
+// GenerateStableTestKey generates a key for a test case that survives code moves. Unlike
+// GenerateTestCaseID, it leaves out parents that are file or package paths and normalizes
+// whitespace in the rest, so a test keeps its key when its file is moved or renamed.
+// Tests with the same name and suites in different files share a key.
+func GenerateStableTestKey(testName string, parentNames []string) string {
+	parts := make([]string, 0, len(parentNames)+1)
+	for _, parent := range parentNames {
+		if !looksLikeFilePath(parent) {
+			parts = append(parts, NormalizeGroupName(parent))
+		}
+	}
+	parts = append(parts, NormalizeGroupName(testName))
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, ":")))
+	// Use first 16 bytes (32 hex chars) for ID
+	return hex.EncodeToString(hash[:16])
+}
+
 // GetParentGroupID generates the ID of the parent group
 func GetParentGroupID(parentNames []string) string {
 	if len(parentNames) == 0 {
@@ -286,6 +286,30 @@ func TestExtractFileFromPath(t *testing.T) {
 	}
 }
 
+func TestGenerateStableTestKey(t *testing.T) {
+	key := GenerateStableTestKey("adds items", []string{"/home/ci/app/src/cart.test.js", "Cart", "when empty"})
+
+	// Moving the file, or running from another checkout, keeps the key
+	for _, parents := range [][]string{
+		{"/home/dev/app/lib/shop/cart.test.js", "Cart", "when empty"},
+		{"src/cart.spec.ts", "Cart", "when  empty "},
+	} {
+		if got := GenerateStableTestKey("adds items", parents); got != key {
+			t.Errorf("Expected %q to keep the key %s, got %s", parents, key, got)
+		}
+	}
+	// Renaming a suite or the test changes it
+	if GenerateStableTestKey("adds items", []string{"src/cart.test.js", "Basket", "when empty"}) == key {
+		t.Error("Expected a renamed suite to change the key")
+	}
+	if GenerateStableTestKey("adds an item", []string{"src/cart.test.js", "Cart", "when empty"}) == key {
+		t.Error("Expected a renamed test to change the key")
+	}
+	if len(key) != 32 {
+		t.Errorf("Expected a 32 character key, got %s", key)
+	}
+}
+
 func TestCompareGroupPaths(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -457,3 +481,23 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+
+func TestCanonicalWindowsPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{`C:\foo\a.test.js`, `c:\foo\a.test.js`},
+		{`c:/foo/a.test.js`, `c:\foo\a.test.js`},
+		{`D:/`, `d:\`},
+		{`\\server\share\a.test.js`, `\\server\share\a.test.js`},
+		{"Calculator", "Calculator"},
+		{"1:/not a drive", "1:/not a drive"},
+	}
+
+	for _, tt := range tests {
+		if got := canonicalWindowsPath(tt.name); got != tt.expected {
+			t.Errorf("canonicalWindowsPath(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
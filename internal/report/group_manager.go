@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,21 +30,63 @@ type GroupManager struct {
 	ipcPath    string
 	logger     Logger
 
+	// Test cases slower than this are flagged as slow (0 disables)
+	slowThreshold time.Duration
+
+	// Bytes of stdout and stderr kept per group (0 keeps everything)
+	maxGroupOutput int
+
+	// Keep ANSI escape sequences in stdout and stderr instead of stripping them
+	keepANSI bool
+
+	// Order of the subgroup table in group reports
+	groupOrder GroupOrder
+
+	// How much of each test case group reports show
+	reportDetail ReportDetail
+
+	// Level whose group reports list the test cases of their subgroups (0 collapses nothing)
+	collapseDepth int
+
+	// Hides the project root and home directory in group reports (nil keeps them)
+	redactor *pathRedactor
+
+	// Failed test cases keeping their error details past this many lose them (0 keeps all)
+	maxFailures      int
+	detailedFailures map[string]bool // Test case IDs whose error details are kept
+	omittedFailures  map[string]bool // Test case IDs whose error details were dropped
+
+	// Report directories taken, keyed by parent group ID and directory name (see claimReportDir)
+	reportDirs map[string]string
+
+	// Top-level groups of a combined run's commands, whose names are never paths (see Nest)
+	commandNames map[string]bool
+
 	// Debouncing for report generation
-	pendingUpdates map[string]time.Time // Group ID -> last update time
-	updateTimer    *time.Timer
-	updateMutex    sync.Mutex
+	pendingUpdates      map[string]time.Time // Group ID -> last update time
+	updatesPendingSince time.Time            // When the oldest pending update was scheduled
+	updateTimer         *time.Timer
+	updateMutex         sync.Mutex
+	updateDebounce      time.Duration // Quiet time before pending reports are written
+	updateMaxWait       time.Duration // Longest pending reports are postponed (0 means no limit)
+	updateFlushes       int           // Number of times pending reports were written
 }
 
 // NewGroupManager creates a new GroupManager instance
 func NewGroupManager(runDir string, ipcPath string, logger Logger) *GroupManager {
 	return &GroupManager{
-		groups:         make(map[string]*TestGroup),
-		rootGroups:     make([]*TestGroup, 0),
-		runDir:         runDir,
-		ipcPath:        ipcPath,
-		logger:         logger,
-		pendingUpdates: make(map[string]time.Time),
+		groups:           make(map[string]*TestGroup),
+		rootGroups:       make([]*TestGroup, 0),
+		runDir:           runDir,
+		ipcPath:          ipcPath,
+		logger:           logger,
+		groupOrder:       GroupOrderName,
+		reportDetail:     ReportDetailStandard,
+		reportDirs:       make(map[string]string),
+		detailedFailures: make(map[string]bool),
+		omittedFailures:  make(map[string]bool),
+		pendingUpdates:   make(map[string]time.Time),
+		updateDebounce:   100 * time.Millisecond,
 	}
 }
 
@@ -67,10 +111,20 @@ func (gm *GroupManager) logError(format string, args ...interface{}) {
 	}
 }
 
-// normalizeToAbsolutePath converts any path to an absolute path for consistent storage
+// normalizeToAbsolutePath converts any path to an absolute path for consistent storage.
+// On Windows the result also gets a lowercase drive letter and backslash separators,
+// since the same file may be reported as C:\foo and c:/foo.
 func (gm *GroupManager) normalizeToAbsolutePath(name string) string {
+	if gm.commandNames[name] {
+		return name
+	}
 	// If it's not a file path (e.g., test names, suite names), return as-is
-	if !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "./") && !strings.Contains(name, "/") {
+	isWindowsPath := runtime.GOOS == "windows" && (filepath.IsAbs(name) || strings.HasPrefix(name, `.\`))
+	if !isWindowsPath && !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "./") && !strings.Contains(name, "/") {
+		return name
+	}
+	// Scoped package names such as Jest project names (@app/web) aren't paths either
+	if strings.HasPrefix(name, "@") {
 		return name
 	}
 
@@ -83,20 +137,20 @@ func (gm *GroupManager) normalizeToAbsolutePath(name string) string {
 
 	// Always attempt to resolve symlinks for absolute paths
 	// This is crucial for macOS where /tmp is a symlink to /private/tmp
-	resolved, err := filepath.EvalSymlinks(absPath)
-	if err == nil {
-		return resolved
-	}
-
 	// If symlink resolution fails, it might be because:
 	// 1. The file doesn't exist yet (which is ok for test group names)
 	// 2. There's no symlink to resolve
-	// In either case, return the absolute path
+	// In either case, keep the absolute path
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	if runtime.GOOS == "windows" {
+		return canonicalWindowsPath(absPath)
+	}
 	return absPath
 }
 
 // makeRelativePath converts absolute paths to relative for display purposes only
-// nolint:unused // relative path helper retained for future path normalization
 func (gm *GroupManager) makeRelativePath(name string) string {
 	// Only convert if it looks like an absolute file path
 	if !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "./") {
@@ -125,6 +179,9 @@ func (gm *GroupManager) ProcessGroupDiscovered(event ipc.GroupDiscoveredEvent) e
 	defer gm.mu.Unlock()
 
 	payload := event.Payload
+	if command, _ := payload.Metadata[CommandGroupMetadataKey].(bool); command && len(payload.ParentNames) == 0 {
+		gm.addCommandName(payload.GroupName)
+	}
 
 	// Normalize paths to absolute for consistent storage
 	groupName := gm.normalizeToAbsolutePath(payload.GroupName)
@@ -156,6 +213,7 @@ func (gm *GroupManager) ProcessGroupDiscovered(event ipc.GroupDiscoveredEvent) e
 
 	// Store in groups map
 	gm.groups[groupID] = group
+	gm.claimReportDir(group)
 
 	// Handle parent relationship
 	if len(payload.ParentNames) == 0 {
@@ -289,6 +347,10 @@ func (gm *GroupManager) ProcessGroupResult(event ipc.GroupResultEvent) error {
 		}
 	}
 
+	if coverage, ok := payload.Metadata["coverage"].(string); ok && coverage != "" {
+		group.Coverage = coverage
+	}
+
 	// Propagate completion to ancestors
 	gm.propagateCompletion(group)
 
@@ -343,6 +405,7 @@ func (gm *GroupManager) ProcessGroupError(event ipc.GroupErrorEvent) error {
 	if payload.Error != nil {
 		group.ErrorInfo = &TestError{
 			Message: payload.Error.Message,
+			Stack:   payload.Error.Stack,
 			Type:    payload.ErrorType,
 		}
 	}
@@ -366,6 +429,37 @@ func (gm *GroupManager) ProcessGroupError(event ipc.GroupErrorEvent) error {
 	return nil
 }
 
+// ProcessCollectionError records a test module that failed to collect, such as a pytest
+// import error, as a group with status ERROR and the traceback as its error
+func (gm *GroupManager) ProcessCollectionError(event ipc.CollectionErrorEvent) error {
+	// Collection errors are reported per pytest node ID, normally just the file path
+	parts := strings.Split(event.Payload.FilePath, "::")
+	groupName := parts[len(parts)-1]
+	if groupName == "" {
+		groupName = "__collection__"
+	}
+
+	errorEvent := ipc.NewGroupErrorEvent(groupName, parts[:len(parts)-1], "COLLECTION_ERROR", 0, collectionErrorMessage(event.Payload.Error))
+	errorEvent.Payload.Error.Phase = "collection"
+	errorEvent.Payload.Error.Stack = event.Payload.Error
+	return gm.ProcessGroupError(errorEvent)
+}
+
+// collectionErrorMessage picks a one-line summary from a pytest collection error: the
+// last "E " line, which holds the exception, or else the first line
+func collectionErrorMessage(errorText string) string {
+	lines := strings.Split(strings.TrimSpace(errorText), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); strings.HasPrefix(line, "E ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "E "))
+		}
+	}
+	if lines[0] == "" {
+		return "Collection failed"
+	}
+	return lines[0]
+}
+
 // ProcessTestCase handles a test case event
 func (gm *GroupManager) ProcessTestCase(event ipc.GroupTestCaseEvent) error {
 	gm.mu.Lock()
@@ -412,31 +506,24 @@ func (gm *GroupManager) ProcessTestCase(event ipc.GroupTestCaseEvent) error {
 	}
 
 	// Set status
-	switch payload.Status {
-	case "PASS":
-		testCase.Status = TestStatusPass
-	case "FAIL":
-		testCase.Status = TestStatusFail
-	case "SKIP":
-		testCase.Status = TestStatusSkip
-	case "XFAIL":
-		testCase.Status = TestStatusXFail
-	case "XPASS":
-		testCase.Status = TestStatusXPass
-	default:
-		testCase.Status = TestStatusPending
-	}
+	testCase.Status = testCaseStatus(payload.Status)
 
 	// Set xfail reason if present
 	if payload.XFailReason != "" {
 		testCase.XFailReason = payload.XFailReason
 	}
+	if payload.SkipReason != "" && testCase.Status == TestStatusSkip {
+		testCase.SkipReason = payload.SkipReason
+	}
 
 	// Set duration
 	if payload.Duration > 0 {
 		testCase.Duration = time.Duration(payload.Duration) * time.Millisecond
 	}
 	testCase.EndTime = time.Now()
+	if gm.slowThreshold > 0 && testCase.Duration > gm.slowThreshold {
+		testCase.Slow = true
+	}
 
 	// Set error if present
 	if payload.Error != nil {
@@ -447,17 +534,38 @@ func (gm *GroupManager) ProcessTestCase(event ipc.GroupTestCaseEvent) error {
 			Actual:   payload.Error.Actual,
 			Location: payload.Error.Location,
 			Type:     payload.Error.ErrorType,
+			Kind:     ipc.ClassifyFailure(payload.Error),
+		}
+	}
+
+	// Set benchmark measurements if present
+	if payload.Benchmark != nil {
+		testCase.Benchmark = &Benchmark{
+			Iterations:  payload.Benchmark.Iterations,
+			NsPerOp:     payload.Benchmark.NsPerOp,
+			BytesPerOp:  payload.Benchmark.BytesPerOp,
+			AllocsPerOp: payload.Benchmark.AllocsPerOp,
+			MemStats:    payload.Benchmark.MemStats,
 		}
 	}
 
 	// Set output if present
 	testCase.Stdout = payload.Stdout
 	testCase.Stderr = payload.Stderr
+	gm.limitFailureDetail(&testCase)
 
 	// Check if test case already exists (deduplication)
 	testExists := false
 	for i, existingTest := range parentGroup.TestCases {
 		if existingTest.ID == testCase.ID {
+			// A retry keeps the earlier attempts; a repeated report just replaces the test case
+			if isRepeatedRun(payload.Metadata) {
+				testCase.Attempts = append(existingTest.runs(), repeatedRunAttempt(testCase, payload.Metadata))
+				testCase.Repeated = true
+				testCase.Duration += existingTest.Duration
+			} else if isRetryAttempt(existingTest, testCase, payload.Metadata) {
+				testCase.Attempts = append(existingTest.runs(), testCase.attempt())
+			}
 			// Update existing test case instead of adding duplicate
 			parentGroup.TestCases[i] = testCase
 			testExists = true
@@ -484,6 +592,59 @@ func (gm *GroupManager) ProcessTestCase(event ipc.GroupTestCaseEvent) error {
 	return nil
 }
 
+// testCaseStatus maps a test case event's status to a TestStatus
+func testCaseStatus(status string) TestStatus {
+	switch status {
+	case "PASS":
+		return TestStatusPass
+	case "FAIL":
+		return TestStatusFail
+	case "SKIP":
+		return TestStatusSkip
+	case "XFAIL":
+		return TestStatusXFail
+	case "XPASS":
+		return TestStatusXPass
+	default:
+		return TestStatusPending
+	}
+}
+
+// isRepeatedRun returns true for another run of a test the runner was asked to run
+// several times (go test -count=N), as opposed to a retry. The runner reports the
+// combined status with the number of earlier runs in the "repeat" metadata.
+func isRepeatedRun(metadata map[string]interface{}) bool {
+	repeat, ok := metadata["repeat"].(float64)
+	return ok && repeat > 0
+}
+
+// repeatedRunAttempt returns the attempt for a repeated run of testCase. Its status is
+// the run's own ("runStatus" metadata) rather than the combined one the test case has.
+func repeatedRunAttempt(testCase TestCase, metadata map[string]interface{}) Attempt {
+	attempt := testCase.attempt()
+	if runStatus, ok := metadata["runStatus"].(string); ok {
+		attempt.Status = testCaseStatus(runStatus)
+	}
+	if attempt.Status != TestStatusFail {
+		attempt.Error = nil
+	}
+	return attempt
+}
+
+// isRetryAttempt returns true if a test case reported again is another attempt at the test
+// rather than a repeated report of the same result. Runners that number their retries
+// say so in the "retry" metadata; otherwise a changed outcome marks a retry.
+func isRetryAttempt(existing, next TestCase, metadata map[string]interface{}) bool {
+	if retry, ok := metadata["retry"].(float64); ok && retry > 0 {
+		return true
+	}
+	switch existing.Status {
+	case TestStatusPending, TestStatusRunning:
+		return false
+	}
+	return existing.Status != next.Status
+}
+
 // ProcessStdoutChunk handles stdout output for a group
 func (gm *GroupManager) ProcessStdoutChunk(groupName string, parentNames []string, chunk string) error {
 	gm.mu.Lock()
@@ -503,7 +664,10 @@ func (gm *GroupManager) ProcessStdoutChunk(groupName string, parentNames []strin
 		return nil
 	}
 
-	group.Stdout += chunk
+	if !gm.keepANSI {
+		chunk = stripANSIChunk(&group.stdoutEscape, chunk)
+	}
+	group.Stdout = gm.appendOutput(group.Stdout, &group.stdoutTruncated, chunk)
 	group.Updated = time.Now()
 
 	// Schedule debounced report update
@@ -531,7 +695,10 @@ func (gm *GroupManager) ProcessStderrChunk(groupName string, parentNames []strin
 		return nil
 	}
 
-	group.Stderr += chunk
+	if !gm.keepANSI {
+		chunk = stripANSIChunk(&group.stderrEscape, chunk)
+	}
+	group.Stderr = gm.appendOutput(group.Stderr, &group.stderrTruncated, chunk)
 	group.Updated = time.Now()
 
 	// Schedule debounced report update
@@ -540,6 +707,22 @@ func (gm *GroupManager) ProcessStderrChunk(groupName string, parentNames []strin
 	return nil
 }
 
+// claimReportDir gives a new group a report directory that none of its siblings use.
+// Distinct names can sanitize to the same directory name ("a.b" and "a_b", or "A" and
+// "a" on case-insensitive filesystems), so a group whose directory is taken gets a
+// short hash of its ID appended. Caller must hold gm.mu.
+func (gm *GroupManager) claimReportDir(group *TestGroup) {
+	group.pathSuffix = ""
+	dir := groupPathComponent(group.Name, testExecDir(gm.runDir))
+	key := GetParentGroupID(group.ParentNames) + "/" + strings.ToLower(dir)
+	if owner, taken := gm.reportDirs[key]; taken && owner != group.ID {
+		group.pathSuffix = "_" + shortHash(group.ID)
+		key += strings.ToLower(group.pathSuffix)
+		gm.logDebug("Report directory %s of %s is taken, using %s", dir, BuildHierarchicalPath(group), dir+group.pathSuffix)
+	}
+	gm.reportDirs[key] = group.ID
+}
+
 // ensureParentHierarchy ensures all parent groups exist for a given group
 func (gm *GroupManager) ensureParentHierarchy(group *TestGroup) error {
 	if len(group.ParentNames) == 0 {
@@ -572,6 +755,7 @@ func (gm *GroupManager) ensureParentHierarchy(group *TestGroup) error {
 			}
 
 			gm.groups[parentID] = parent
+			gm.claimReportDir(parent)
 
 			// Add to root groups if this is a root
 			if len(grandparentNames) == 0 {
@@ -630,6 +814,7 @@ func (gm *GroupManager) ensureGroupHierarchy(path []string) error {
 			}
 
 			gm.groups[groupID] = group
+			gm.claimReportDir(group)
 
 			// Handle parent relationship
 			if len(parentNames) == 0 {
@@ -697,15 +882,19 @@ func (gm *GroupManager) scheduleReportUpdate(groupID string) {
 	gm.updateMutex.Lock()
 	defer gm.updateMutex.Unlock()
 
-	gm.pendingUpdates[groupID] = time.Now()
+	now := time.Now()
+	if len(gm.pendingUpdates) == 0 {
+		gm.updatesPendingSince = now
+	}
+	gm.pendingUpdates[groupID] = now
 
 	// Cancel existing timer
 	if gm.updateTimer != nil {
 		gm.updateTimer.Stop()
 	}
 
-	// Schedule new update after 100ms of inactivity
-	gm.updateTimer = time.AfterFunc(100*time.Millisecond, func() {
+	// Schedule new update after the debounce time of inactivity
+	gm.updateTimer = time.AfterFunc(gm.reportUpdateDelay(now), func() {
 		gm.flushPendingUpdates()
 	})
 }
@@ -718,6 +907,9 @@ func (gm *GroupManager) flushPendingUpdates() {
 		updates[k] = v
 	}
 	gm.pendingUpdates = make(map[string]time.Time)
+	if len(updates) > 0 {
+		gm.updateFlushes++
+	}
 	gm.updateMutex.Unlock()
 
 	gm.mu.RLock()
@@ -744,7 +936,7 @@ func (gm *GroupManager) generateGroupReport(group *TestGroup) error {
 	}
 
 	// Generate report content
-	content := gm.formatGroupReport(group)
+	content := gm.redactor.redact(gm.formatGroupReport(group))
 
 	// Write report file
 	if err := os.WriteFile(reportPath, []byte(content), 0644); err != nil {
@@ -761,6 +953,13 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 	// Use the ParentNames field which already contains the hierarchy
 	parentPath := group.ParentNames
 
+	// Past --collapse-depth, the subgroups' test cases are listed here instead of the subgroups
+	groupTestCases := group.TestCases
+	var collapsed []*TestGroup
+	if gm.collapses(group) {
+		groupTestCases, collapsed = collapseSubgroups(group, gm.groupOrder)
+	}
+
 	// Metadata (YAML frontmatter) - MUST come first
 	content += "---\n"
 	content += fmt.Sprintf("group_name: %s\n", group.Name)
@@ -779,6 +978,10 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 		seconds := group.Duration.Seconds()
 		content += fmt.Sprintf("duration: %.2fs\n", seconds)
 	}
+	// Time spent queued before starting, when long enough to show
+	if wait := group.WaitDuration(); wait >= minReportedWait {
+		content += fmt.Sprintf("wait: %.2fs\n", wait.Seconds())
+	}
 
 	content += fmt.Sprintf("created: %s\n", group.Created.Format(time.RFC3339))
 	content += fmt.Sprintf("updated: %s\n", group.Updated.Format(time.RFC3339))
@@ -794,15 +997,83 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 		content += fmt.Sprintf("# Test Report: %s\n\n", fullPath)
 	}
 
+	// Group-level error (setup failure, panic outside a test, ...) comes before the results
+	if group.ErrorInfo != nil {
+		content += "## Error\n\n"
+		if group.ErrorInfo.Type != "" {
+			content += fmt.Sprintf("- Type: %s\n\n", group.ErrorInfo.Type)
+		}
+		content += "```\n"
+		content += group.ErrorInfo.Message
+		if group.ErrorInfo.Stack != "" {
+			content += "\n" + group.ErrorInfo.Stack
+		}
+		content += "\n```\n\n"
+	}
+	for _, sg := range collapsed {
+		if sg.ErrorInfo == nil {
+			continue
+		}
+		if group.ErrorInfo == nil {
+			content += "## Error\n\n"
+		}
+		content += fmt.Sprintf("- Group: %s\n", strings.Join(sg.GetFullPath()[len(parentPath)+1:], " > "))
+		if sg.ErrorInfo.Type != "" {
+			content += fmt.Sprintf("- Type: %s\n", sg.ErrorInfo.Type)
+		}
+		content += "\n```\n"
+		content += sg.ErrorInfo.Message
+		if sg.ErrorInfo.Stack != "" {
+			content += "\n" + sg.ErrorInfo.Stack
+		}
+		content += "\n```\n\n"
+	}
+
 	// Summary section - show direct tests OR subgroups, not both aggregated counts
 	content += "## Summary\n\n"
+	if group.Coverage != "" {
+		content += fmt.Sprintf("- Coverage: %s\n", group.Coverage)
+	}
 
 	// Only show direct test statistics if there are direct test cases
-	if len(group.TestCases) > 0 {
+	if len(collapsed) > 0 {
+		// Collapsed subgroups' tests count as the group's own
+		counts := make(map[TestStatus]int)
+		flaky := 0
+		for _, tc := range groupTestCases {
+			counts[tc.Status]++
+			if tc.Flaky() {
+				flaky++
+			}
+		}
+		content += fmt.Sprintf("- Group tests: %d\n", len(groupTestCases))
+		if counts[TestStatusPass] > 0 {
+			content += fmt.Sprintf("- Group tests passed: %d\n", counts[TestStatusPass])
+		}
+		if flaky > 0 {
+			content += fmt.Sprintf("- Group tests flaky: %d\n", flaky)
+		}
+		if counts[TestStatusFail] > 0 {
+			content += fmt.Sprintf("- Group tests failed: %d\n", counts[TestStatusFail])
+		}
+		if counts[TestStatusSkip] > 0 {
+			content += fmt.Sprintf("- Group tests skipped: %d\n", counts[TestStatusSkip])
+		}
+		if counts[TestStatusXFail] > 0 {
+			content += fmt.Sprintf("- Group tests xfailed: %d\n", counts[TestStatusXFail])
+		}
+		if counts[TestStatusXPass] > 0 {
+			content += fmt.Sprintf("- Group tests xpassed: %d\n", counts[TestStatusXPass])
+		}
+		content += fmt.Sprintf("- Subgroups: %d, collapsed into this report (--collapse-depth)\n", len(collapsed))
+	} else if len(group.TestCases) > 0 {
 		content += fmt.Sprintf("- Group tests: %d\n", group.Stats.TotalTests)
 		if group.Stats.PassedTests > 0 {
 			content += fmt.Sprintf("- Group tests passed: %d\n", group.Stats.PassedTests)
 		}
+		if group.Stats.FlakyTests > 0 {
+			content += fmt.Sprintf("- Group tests flaky: %d\n", group.Stats.FlakyTests)
+		}
 		if group.Stats.FailedTests > 0 {
 			content += fmt.Sprintf("- Group tests failed: %d\n", group.Stats.FailedTests)
 		}
@@ -872,10 +1143,16 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 	}
 	content += "\n"
 
-	// Test case results section - only show if there are test cases
-	if len(group.TestCases) > 0 {
+	// Test case results section - only show if there are test cases to list
+	var testCases []TestCase
+	for _, tc := range groupTestCases {
+		if showTestCase(tc, gm.reportDetail) {
+			testCases = append(testCases, tc)
+		}
+	}
+	if len(testCases) > 0 {
 		content += "## Test case results\n\n"
-		for _, tc := range group.TestCases {
+		for _, tc := range testCases {
 			var icon string
 			switch tc.Status {
 			case TestStatusFail:
@@ -891,6 +1168,9 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 			}
 
 			content += fmt.Sprintf("- %s %s", icon, tc.Name)
+			if tc.SkipReason != "" {
+				content += fmt.Sprintf(" (skipped: %s)", tc.SkipReason)
+			}
 			if tc.Duration > 0 {
 				content += fmt.Sprintf(" (%.2fs)", tc.Duration.Seconds())
 			}
@@ -901,26 +1181,59 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 				content += fmt.Sprintf("  > *Expected failure: %s*\n", tc.XFailReason)
 			}
 
+			// Retried tests say which attempt settled the result; repeated ones list their runs
+			if tc.Repeated {
+				content += fmt.Sprintf("  > *%s*\n", tc.RunsSummary())
+			} else if len(tc.Attempts) > 1 {
+				retries := len(tc.Attempts) - 1
+				if tc.Flaky() {
+					content += fmt.Sprintf("  > *Flaky: passed on retry %d/%d*\n", retries, len(tc.Attempts))
+				} else if tc.Status == TestStatusFail {
+					content += fmt.Sprintf("  > *Failed after %d attempts*\n", len(tc.Attempts))
+				}
+			}
+
 			// Error details indented under the test
 			if tc.Error != nil && tc.Status == TestStatusFail {
-				content += "```\n"
-				content += tc.Error.Message
-				if tc.Error.Stack != "" {
-					content += "\n" + tc.Error.Stack
+				if tc.Error.Kind != "" {
+					content += fmt.Sprintf("  > *Failure kind: %s*\n", tc.Error.Kind)
+				}
+				if tc.Error.Location != "" {
+					content += fmt.Sprintf("  > *Location: %s*\n", tc.Error.Location)
+				}
+				if tc.Error.DetailOmitted {
+					content += "  > *Error details omitted (--max-failures)*\n"
+				} else {
+					content += "```\n"
+					content += tc.Error.Message
+					if tc.Error.Stack != "" {
+						content += "\n" + tc.Error.Stack
+					}
+					content += "\n```\n"
 				}
-				content += "\n```\n"
+			}
+			switch {
+			case gm.reportDetail == ReportDetailFull:
+				content += formatTestOutput("Output", tc.Stdout)
+				content += formatTestOutput("Stderr", tc.Stderr)
+			case gm.reportDetail == ReportDetailStandard && (tc.Error == nil || tc.Status != TestStatusFail):
+				// Output of failed tests is already part of their error
+				content += formatTestOutput("Output", tc.Stdout)
 			}
 		}
 		content += "\n"
 	}
 
+	// Benchmark results section - only show if there are benchmark test cases
+	content += formatBenchmarkTable(groupTestCases)
+
 	// Subgroups
-	if len(group.Subgroups) > 0 {
+	if len(group.Subgroups) > 0 && len(collapsed) == 0 {
 		content += "## Subgroups\n\n"
-		content += "| Status | Name | Tests | Duration | Report |\n"
-		content += "|--------|------|-------|----------|--------|\n"
+		content += "| Status | Name | Tests | Wait | Duration | Report |\n"
+		content += "|--------|------|-------|------|----------|--------|\n"
 
-		for _, subgroup := range group.Subgroups {
+		for _, subgroup := range sortedSubgroups(group, gm.groupOrder) {
 			relPath := GetRelativeReportPath(subgroup, gm.runDir)
 			// Normalize to forward slashes so markdown links are portable (Windows/Linux/macOS)
 			relPath = NormalizeFilePath(relPath)
@@ -949,7 +1262,11 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 				testsStr = "0 tests"
 			}
 
-			// Duration column
+			// Wait column (queued before starting) and Duration column (running)
+			waitStr := "-"
+			if wait := subgroup.WaitDuration(); wait >= minReportedWait {
+				waitStr = fmt.Sprintf("%.1fs", wait.Seconds())
+			}
 			durationStr := "-"
 			if subgroup.Duration > 0 {
 				durationStr = fmt.Sprintf("%.1fs", subgroup.Duration.Seconds())
@@ -958,8 +1275,8 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 			// Report link column
 			reportStr := fmt.Sprintf("./%s", relPath)
 
-			content += fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
-				statusStr, nameStr, testsStr, durationStr, reportStr)
+			content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+				statusStr, nameStr, testsStr, waitStr, durationStr, reportStr)
 		}
 		content += "\n"
 	}
@@ -990,6 +1307,94 @@ func (gm *GroupManager) formatGroupReport(group *TestGroup) string {
 	return content
 }
 
+// formatTestOutput renders a test case's output as a labeled block under the test, or
+// nothing when it is empty
+func formatTestOutput(label, output string) string {
+	if output == "" {
+		return ""
+	}
+	return fmt.Sprintf("  > *%s:*\n```\n%s\n```\n", label, strings.TrimRight(output, "\n"))
+}
+
+// formatBenchmarkTable renders a table of benchmark measurements for the given test cases.
+// Returns an empty string when none of the test cases are benchmarks.
+func formatBenchmarkTable(testCases []TestCase) string {
+	var rows []string
+	for _, tc := range testCases {
+		if tc.Benchmark == nil {
+			continue
+		}
+		bytesStr, allocsStr := "-", "-"
+		if tc.Benchmark.MemStats {
+			bytesStr = fmt.Sprintf("%d", tc.Benchmark.BytesPerOp)
+			allocsStr = fmt.Sprintf("%d", tc.Benchmark.AllocsPerOp)
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %d | %s | %s | %s |\n",
+			tc.Name, tc.Benchmark.Iterations, strconv.FormatFloat(tc.Benchmark.NsPerOp, 'f', -1, 64), bytesStr, allocsStr))
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	content := "## Benchmark results\n\n"
+	content += "| Name | Iterations | ns/op | B/op | allocs/op |\n"
+	content += "|------|------------|-------|------|-----------|\n"
+	content += strings.Join(rows, "")
+	content += "\n"
+	return content
+}
+
+// FinalizeIncompleteGroups settles groups that never received a result (e.g. a crashed
+// worker process). Their status is derived from their children where possible, otherwise
+// FAIL if any test failed or ERROR if the group simply never finished.
+func (gm *GroupManager) FinalizeIncompleteGroups() {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	for _, group := range gm.rootGroups {
+		gm.finalizeIncompleteGroup(group)
+	}
+}
+
+// finalizeIncompleteGroup finalizes a group after its subgroups. Caller must hold gm.mu.
+func (gm *GroupManager) finalizeIncompleteGroup(group *TestGroup) {
+	for _, sg := range group.Subgroups {
+		gm.finalizeIncompleteGroup(sg)
+	}
+
+	if group.IsComplete() || group.Status == TestStatusNoTests {
+		return
+	}
+
+	// Children may all be complete now that subgroups were settled
+	group.UpdateStats()
+	if group.IsComplete() {
+		gm.pendingUpdates[group.ID] = time.Now()
+		return
+	}
+
+	if group.HasFailures() {
+		group.Status = TestStatusFail
+	} else {
+		group.Status = TestStatusError
+		if group.ErrorInfo == nil {
+			group.ErrorInfo = &TestError{
+				Message: "No result was reported for this group before the test run ended",
+				Type:    "INCOMPLETE",
+			}
+		}
+	}
+	if group.EndTime.IsZero() {
+		group.EndTime = time.Now()
+		if !group.StartTime.IsZero() {
+			group.Duration = group.EndTime.Sub(group.StartTime)
+		}
+	}
+	gm.pendingUpdates[group.ID] = time.Now()
+
+	gm.logInfo("Group %s had no result at end of run, marked %s", group.Name, group.Status)
+}
+
 // GetRootGroups returns all root-level groups
 func (gm *GroupManager) GetRootGroups() []*TestGroup {
 	gm.mu.RLock()
@@ -1036,7 +1441,7 @@ func (gm *GroupManager) GenerateFinalReport() error {
 
 	// Generate root summary
 	summaryPath := filepath.Join(gm.runDir, "test-run.md")
-	summaryContent := gm.generateSummaryReport()
+	summaryContent := gm.redactor.redact(gm.generateSummaryReport())
 
 	if err := os.WriteFile(summaryPath, []byte(summaryContent), 0644); err != nil {
 		return fmt.Errorf("failed to write summary report: %w", err)
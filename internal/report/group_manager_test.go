@@ -264,6 +264,299 @@ func TestGroupManager_ProcessTestCase(t *testing.T) {
 	}
 }
 
+func TestGroupManager_ProcessTestCaseRetries(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+
+	send := func(name, status string, retry int) {
+		t.Helper()
+		payload := ipc.TestCasePayload{
+			TestName:    name,
+			ParentNames: []string{"login.spec.ts"},
+			Status:      status,
+			Duration:    100,
+		}
+		if status == "FAIL" {
+			payload.Error = &ipc.TestError{Message: "timed out"}
+		}
+		if retry > 0 {
+			payload.Metadata = map[string]interface{}{"retry": float64(retry)}
+		}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: string(ipc.EventTypeTestCase), Payload: payload}); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+
+	// Flaky: failed twice, passed on the second retry
+	send("flaky", "FAIL", 0)
+	send("flaky", "FAIL", 1)
+	send("flaky", "PASS", 2)
+	// Failed on every attempt, without retry numbers
+	send("broken", "FAIL", 0)
+	send("broken", "FAIL", 0)
+	// Reported twice with the same result
+	send("stable", "PASS", 0)
+	send("stable", "PASS", 0)
+
+	group, _ := gm.GetGroup(GenerateGroupIDFromPath([]string{"login.spec.ts"}))
+	if len(group.TestCases) != 3 {
+		t.Fatalf("Test cases = %d, want 3", len(group.TestCases))
+	}
+
+	flaky := group.TestCases[0]
+	if flaky.Status != TestStatusPass || flaky.Error != nil || !flaky.Flaky() {
+		t.Errorf("flaky = %v (error %v, flaky %v), want a flaky PASS", flaky.Status, flaky.Error, flaky.Flaky())
+	}
+	if len(flaky.Attempts) != 3 || flaky.Attempts[0].Status != TestStatusFail || flaky.Attempts[0].Error == nil || flaky.Attempts[2].Status != TestStatusPass {
+		t.Errorf("flaky attempts = %+v, want FAIL, FAIL, PASS", flaky.Attempts)
+	}
+
+	// A failure repeated without a retry number is a repeated report, not a retry
+	if broken := group.TestCases[1]; broken.Status != TestStatusFail || len(broken.Attempts) != 0 {
+		t.Errorf("broken = %v with %d attempts, want FAIL with none", broken.Status, len(broken.Attempts))
+	}
+	if stable := group.TestCases[2]; len(stable.Attempts) != 0 || stable.Flaky() {
+		t.Errorf("stable has %d attempts, want none", len(stable.Attempts))
+	}
+
+	if group.Stats.PassedTests != 2 || group.Stats.FlakyTests != 1 || group.Stats.FailedTests != 1 {
+		t.Errorf("Stats = %+v, want 2 passed, 1 flaky, 1 failed", group.Stats)
+	}
+
+	content := gm.formatGroupReport(group)
+	for _, want := range []string{"- Group tests flaky: 1", "  > *Flaky: passed on retry 2/3*"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Report missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGroupManager_SkipReason(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	for _, payload := range []ipc.TestCasePayload{
+		{TestName: "test_vacuum", Status: "SKIP", SkipReason: "needs postgres"},
+		{TestName: "test_fast", Status: "SKIP"},
+	} {
+		payload.ParentNames = []string{"test_db.py"}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: "testCase", Payload: payload}); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+
+	content := gm.formatGroupReport(gm.GetRootGroups()[0])
+	for _, want := range []string{"- ○ test_vacuum (skipped: needs postgres)\n", "- ○ test_fast\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected report to contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGroupManager_Coverage(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	err := gm.ProcessGroupResult(ipc.GroupResultEvent{
+		EventType: string(ipc.EventTypeGroupResult),
+		Payload: ipc.GroupResultPayload{
+			GroupName: "example.com/shop",
+			Status:    "PASS",
+			Metadata:  map[string]interface{}{"coverage": "87.5% of statements"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessGroupResult failed: %v", err)
+	}
+
+	group := gm.GetRootGroups()[0]
+	if group.Coverage != "87.5% of statements" {
+		t.Errorf("Expected the coverage to be stored, got %q", group.Coverage)
+	}
+	if content := gm.formatGroupReport(group); !strings.Contains(content, "## Summary\n\n- Coverage: 87.5% of statements\n") {
+		t.Errorf("Expected the coverage in the report summary:\n%s", content)
+	}
+}
+
+func TestGroupManager_WaitDuration(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	names := []string{"queued.test.js", "immediate.test.js", "brief.test.js"}
+	for _, name := range names {
+		if err := gm.ProcessGroupDiscovered(ipc.NewGroupDiscoveredEvent(name, []string{"suite"})); err != nil {
+			t.Fatalf("ProcessGroupDiscovered failed: %v", err)
+		}
+	}
+
+	// queued.test.js waited 2s for a worker after being discovered, brief.test.js 20ms
+	queued := gm.GetRootGroups()[0].FindSubgroup("queued.test.js")
+	queued.Created = queued.Created.Add(-2 * time.Second)
+	brief := gm.GetRootGroups()[0].FindSubgroup("brief.test.js")
+	brief.Created = brief.Created.Add(-20 * time.Millisecond)
+	for _, name := range names {
+		if err := gm.ProcessGroupStart(ipc.NewGroupStartEvent(name, []string{"suite"})); err != nil {
+			t.Fatalf("ProcessGroupStart failed: %v", err)
+		}
+		if err := gm.ProcessGroupResult(ipc.NewGroupResultEvent(name, []string{"suite"}, "PASS", 1500)); err != nil {
+			t.Fatalf("ProcessGroupResult failed: %v", err)
+		}
+	}
+
+	if wait := queued.WaitDuration(); wait < 2*time.Second || wait > 3*time.Second {
+		t.Errorf("Expected a wait of about 2s, got %v", wait)
+	}
+	if content := gm.formatGroupReport(queued); !strings.Contains(content, "duration: 1.50s\nwait: 2.00s\n") {
+		t.Errorf("Expected the wait in the frontmatter:\n%s", content)
+	}
+	// A wait too short for the subgroup table is left out of the frontmatter too
+	if content := gm.formatGroupReport(brief); strings.Contains(content, "wait:") {
+		t.Errorf("Expected no wait in the frontmatter of a group that barely waited:\n%s", content)
+	}
+
+	content := gm.formatGroupReport(gm.GetRootGroups()[0])
+	for _, row := range []string{
+		"| Status | Name | Tests | Wait | Duration | Report |",
+		"| PASS | queued.test.js | 0 tests | 2.0s | 1.5s |",
+		"| PASS | immediate.test.js | 0 tests | - | 1.5s |",
+		"| PASS | brief.test.js | 0 tests | - | 1.5s |",
+	} {
+		if !strings.Contains(content, row) {
+			t.Errorf("Expected %q in the subgroups table:\n%s", row, content)
+		}
+	}
+}
+
+func TestGroupManager_ScopedPackageGroupName(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	payload := ipc.TestCasePayload{TestName: "renders", ParentNames: []string{"@app/web", "/repo/packages/web/app.test.js"}, Status: "PASS"}
+	if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: "testCase", Payload: payload}); err != nil {
+		t.Fatalf("ProcessTestCase failed: %v", err)
+	}
+
+	// A Jest project named after its package is not taken for a path
+	roots := gm.GetRootGroups()
+	if len(roots) != 1 || roots[0].Name != "@app/web" {
+		t.Fatalf("Expected a root group named @app/web, got %+v", roots)
+	}
+	for _, sg := range roots[0].Subgroups {
+		if sg.Name != "/repo/packages/web/app.test.js" || len(sg.TestCases) != 1 {
+			t.Errorf("Expected the test file nested under the project, got %+v", sg)
+		}
+	}
+	if len(roots[0].Subgroups) != 1 {
+		t.Errorf("Expected one subgroup, got %d", len(roots[0].Subgroups))
+	}
+}
+
+func TestGroupManager_TestCaseOutput(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	for _, payload := range []ipc.TestCasePayload{
+		{TestName: "TestInsert", Status: "PASS", Stdout: "    db_test.go:12: inserted 3 rows\n"},
+		{TestName: "TestDelete", Status: "FAIL", Stdout: "    db_test.go:30: deleting\n", Error: &ipc.TestError{Message: "db_test.go:31: row still there"}},
+	} {
+		payload.ParentNames = []string{"example.com/db"}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: "testCase", Payload: payload}); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+
+	content := gm.formatGroupReport(gm.GetRootGroups()[0])
+	if want := "- ✓ TestInsert\n  > *Output:*\n```\n    db_test.go:12: inserted 3 rows\n```\n"; !strings.Contains(content, want) {
+		t.Errorf("Report missing the passing test's output %q:\n%s", want, content)
+	}
+	// A failed test's output is part of its error
+	if strings.Contains(content, "deleting") {
+		t.Errorf("Expected the failed test's error instead of its output:\n%s", content)
+	}
+}
+
+func TestGroupManager_ReportDetail(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	for _, payload := range []ipc.TestCasePayload{
+		{TestName: "test_insert", Status: "PASS", Stdout: "inserted 3 rows\n", Stderr: "slow query\n"},
+		{TestName: "test_delete", Status: "FAIL", Stdout: "deleting\n", Error: &ipc.TestError{Message: "row still there"}},
+		{TestName: "test_vacuum", Status: "SKIP"},
+	} {
+		payload.ParentNames = []string{"test_db.py"}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: "testCase", Payload: payload}); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+	group := gm.GetRootGroups()[0]
+
+	gm.SetReportDetail(ReportDetailMinimal)
+	content := gm.formatGroupReport(group)
+	if strings.Contains(content, "test_insert") || strings.Contains(content, "inserted 3 rows") {
+		t.Errorf("Expected minimal report to leave out the passing test:\n%s", content)
+	}
+	for _, want := range []string{"- ✕ test_delete\n", "row still there", "- ○ test_vacuum\n", "- Group tests passed: 1\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected minimal report to contain %q:\n%s", want, content)
+		}
+	}
+
+	gm.SetReportDetail(ReportDetailFull)
+	content = gm.formatGroupReport(group)
+	for _, want := range []string{
+		"- ✓ test_insert\n  > *Output:*\n```\ninserted 3 rows\n```\n  > *Stderr:*\n```\nslow query\n```\n",
+		"row still there\n```\n  > *Output:*\n```\ndeleting\n```\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected full report to contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGroupManager_ProcessTestCaseRepeatedRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+
+	// go test -count=3 reports the combined status, with each run's own in the metadata
+	send := func(status, runStatus string, repeat int) {
+		t.Helper()
+		payload := ipc.TestCasePayload{
+			TestName:    "TestFlip",
+			ParentNames: []string{"flip_test.go"},
+			Status:      status,
+			Duration:    100,
+		}
+		if status == "FAIL" {
+			payload.Error = &ipc.TestError{Message: "second run fails"}
+		}
+		if repeat > 0 {
+			payload.Metadata = map[string]interface{}{"repeat": float64(repeat), "runStatus": runStatus}
+		}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{EventType: string(ipc.EventTypeTestCase), Payload: payload}); err != nil {
+			t.Fatalf("ProcessTestCase failed: %v", err)
+		}
+	}
+	send("PASS", "PASS", 0)
+	send("FAIL", "FAIL", 1)
+	send("FAIL", "PASS", 2)
+
+	group, _ := gm.GetGroup(GenerateGroupIDFromPath([]string{"flip_test.go"}))
+	if len(group.TestCases) != 1 {
+		t.Fatalf("Test cases = %d, want 1", len(group.TestCases))
+	}
+	tc := group.TestCases[0]
+	if tc.Status != TestStatusFail || !tc.Repeated || tc.Flaky() {
+		t.Errorf("TestFlip = %v (repeated %v, flaky %v), want a repeated FAIL", tc.Status, tc.Repeated, tc.Flaky())
+	}
+	if len(tc.Attempts) != 3 || tc.Attempts[1].Error == nil || tc.Attempts[2].Status != TestStatusPass || tc.Attempts[2].Error != nil {
+		t.Errorf("TestFlip attempts = %+v, want PASS, FAIL with error, PASS", tc.Attempts)
+	}
+	if tc.Duration != 300*time.Millisecond {
+		t.Errorf("Duration = %v, want the 300ms total of the runs", tc.Duration)
+	}
+
+	content := gm.formatGroupReport(group)
+	if !strings.Contains(content, "  > *3 runs: 2 passed, 1 failed*") {
+		t.Errorf("Report missing the runs summary:\n%s", content)
+	}
+	if strings.Contains(content, "Failed after") {
+		t.Errorf("Expected repeated runs not to be described as retries:\n%s", content)
+	}
+}
+
 func TestGroupManager_HierarchyBuilding(t *testing.T) {
 	tmpDir := t.TempDir()
 	log, _ := logger.NewFileLogger()
@@ -662,3 +955,119 @@ func TestFormatGroupReport_OnlyShowCountsGreaterThanZero(t *testing.T) {
 		t.Error("Should not show failed tests line when count is 0")
 	}
 }
+
+func TestFormatGroupReport_BenchmarkTable(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+
+	group := &TestGroup{
+		ID:          "bench-group",
+		Name:        "example.com/bench",
+		ParentNames: []string{},
+		Status:      TestStatusPass,
+		Created:     time.Now(),
+		Updated:     time.Now(),
+		TestCases: []TestCase{
+			{Name: "TestPlain", Status: TestStatusPass},
+			{Name: "BenchmarkFoo", Status: TestStatusPass, Benchmark: &Benchmark{
+				Iterations: 1000000, NsPerOp: 1053, BytesPerOp: 48, AllocsPerOp: 1, MemStats: true,
+			}},
+			{Name: "BenchmarkBar", Status: TestStatusPass, Benchmark: &Benchmark{
+				Iterations: 1000, NsPerOp: 0.466,
+			}},
+		},
+		Stats:     TestGroupStats{TotalTests: 3, PassedTests: 3},
+		Subgroups: make(map[string]*TestGroup),
+	}
+
+	content := gm.formatGroupReport(group)
+
+	if !strings.Contains(content, "## Benchmark results") {
+		t.Fatalf("Expected benchmark table in report:\n%s", content)
+	}
+	if !strings.Contains(content, "| BenchmarkFoo | 1000000 | 1053 | 48 | 1 |") {
+		t.Errorf("Expected row with memory stats for BenchmarkFoo:\n%s", content)
+	}
+	if !strings.Contains(content, "| BenchmarkBar | 1000 | 0.466 | - | - |") {
+		t.Errorf("Expected placeholders when -benchmem was not used:\n%s", content)
+	}
+	if strings.Contains(content, "| TestPlain |") {
+		t.Errorf("Non-benchmark tests should not appear in the benchmark table")
+	}
+
+	group.TestCases = group.TestCases[:1]
+	if strings.Contains(gm.formatGroupReport(group), "## Benchmark results") {
+		t.Errorf("Did not expect a benchmark table without benchmark test cases")
+	}
+}
+
+func TestGroupManager_FinalizeIncompleteGroups(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+
+	// crashed.py: a worker crashed after reporting a failure, no group result followed
+	_ = gm.ProcessGroupStart(ipc.NewGroupStartEvent("crashed.py", nil))
+	_ = gm.ProcessGroupStart(ipc.NewGroupStartEvent("TestSuite", []string{"crashed.py"}))
+	_ = gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("test_one", []string{"crashed.py", "TestSuite"}, "FAIL"))
+	_ = gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("test_two", []string{"crashed.py", "TestSuite"}, "RUNNING"))
+
+	// hung.py: started but never reported anything
+	_ = gm.ProcessGroupStart(ipc.NewGroupStartEvent("hung.py", nil))
+
+	// done.py: completed normally
+	_ = gm.ProcessGroupStart(ipc.NewGroupStartEvent("done.py", nil))
+	_ = gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("test_ok", []string{"done.py"}, "PASS"))
+	_ = gm.ProcessGroupResult(ipc.NewGroupResultEvent("done.py", nil, "PASS", 10))
+
+	gm.FinalizeIncompleteGroups()
+
+	statuses := make(map[string]TestStatus)
+	var hung *TestGroup
+	for _, group := range gm.GetRootGroups() {
+		statuses[filepath.Base(group.Name)] = group.Status
+		if filepath.Base(group.Name) == "hung.py" {
+			hung = group
+		}
+	}
+
+	if statuses["crashed.py"] != TestStatusFail {
+		t.Errorf("Expected crashed.py to be FAIL, got %s", statuses["crashed.py"])
+	}
+	if statuses["hung.py"] != TestStatusError {
+		t.Errorf("Expected hung.py to be ERROR, got %s", statuses["hung.py"])
+	}
+	if hung == nil || hung.ErrorInfo == nil || hung.ErrorInfo.Type != "INCOMPLETE" {
+		t.Errorf("Expected hung.py to carry an INCOMPLETE error")
+	}
+	if statuses["done.py"] != TestStatusPass {
+		t.Errorf("Expected done.py to stay PASS, got %s", statuses["done.py"])
+	}
+}
+
+func TestGroupManager_GroupErrorInReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+
+	event := ipc.NewGroupErrorEvent("shop", nil, "PANIC", 4, "panic: setup exploded")
+	event.Payload.Error.Stack = "goroutine 1 [running]:\nexample.com/shop.TestMain(...)"
+	if err := gm.ProcessGroupError(event); err != nil {
+		t.Fatalf("ProcessGroupError failed: %v", err)
+	}
+
+	group, _ := gm.GetGroup(GenerateGroupID("shop", nil))
+	content := gm.formatGroupReport(group)
+
+	expected := "## Error\n\n- Type: PANIC\n\n```\npanic: setup exploded\ngoroutine 1 [running]:\nexample.com/shop.TestMain(...)\n```\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected error section %q in report:\n%s", expected, content)
+	}
+	if strings.Index(content, "## Error") > strings.Index(content, "## Summary") {
+		t.Error("Expected the error section before the summary")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package report
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// TestGroupManager_WindowsPathSpellings verifies that one file reported with a different
+// drive letter case and separators collapses into a single group
+func TestGroupManager_WindowsPathSpellings(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", nil)
+
+	for _, name := range []string{`C:\foo\cart.test.js`, `c:/foo/cart.test.js`} {
+		if err := gm.ProcessGroupDiscovered(ipc.GroupDiscoveredEvent{
+			Payload: ipc.GroupDiscoveredPayload{GroupName: name},
+		}); err != nil {
+			t.Fatalf("ProcessGroupDiscovered(%s) failed: %v", name, err)
+		}
+		if err := gm.ProcessTestCase(ipc.GroupTestCaseEvent{
+			Payload: ipc.TestCasePayload{TestName: "adds items", ParentNames: []string{name}, Status: "PASS"},
+		}); err != nil {
+			t.Fatalf("ProcessTestCase(%s) failed: %v", name, err)
+		}
+	}
+
+	roots := gm.GetRootGroups()
+	if len(roots) != 1 {
+		t.Fatalf("Expected one group for both spellings, got %d", len(roots))
+	}
+	if roots[0].Name != `c:\foo\cart.test.js` {
+		t.Errorf("Expected the group name c:\\foo\\cart.test.js, got %s", roots[0].Name)
+	}
+	if GenerateGroupID(`C:\foo\cart.test.js`, nil) != GenerateGroupID(`c:/foo/cart.test.js`, nil) {
+		t.Error("Expected both spellings to get the same group ID")
+	}
+}
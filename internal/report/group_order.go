@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GroupOrder picks how groups are listed in test-run.md and in each group report's
+// subgroup table (--sort)
+type GroupOrder string
+
+const (
+	GroupOrderName      GroupOrder = "name"      // Alphabetical by group name (default)
+	GroupOrderStatus    GroupOrder = "status"    // Failures first, then by name
+	GroupOrderDuration  GroupOrder = "duration"  // Slowest first, then by name
+	GroupOrderDiscovery GroupOrder = "discovery" // The order the runner reported the groups in
+)
+
+// ParseGroupOrder validates a --sort value. An empty value means the default order.
+func ParseGroupOrder(value string) (GroupOrder, error) {
+	switch order := GroupOrder(value); order {
+	case "":
+		return GroupOrderName, nil
+	case GroupOrderName, GroupOrderStatus, GroupOrderDuration, GroupOrderDiscovery:
+		return order, nil
+	default:
+		return "", fmt.Errorf("sort order must be name, status, duration or discovery, got %q", value)
+	}
+}
+
+// statusRank returns the position of a status in statusPrecedence, which lists
+// failures first. Unknown statuses sort last.
+func statusRank(status TestStatus) int {
+	for i, s := range statusPrecedence {
+		if s == status {
+			return i
+		}
+	}
+	return len(statusPrecedence)
+}
+
+// sortGroups returns groups in the given order without modifying the slice passed in.
+// For GroupOrderDiscovery groups are ordered by creation time, which keeps the order
+// they were reported in. Ties are broken by name so reports are the same on every run.
+func sortGroups(groups []*TestGroup, order GroupOrder) []*TestGroup {
+	sorted := make([]*TestGroup, len(groups))
+	copy(sorted, groups)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch order {
+		case GroupOrderStatus:
+			if ra, rb := statusRank(a.Status), statusRank(b.Status); ra != rb {
+				return ra < rb
+			}
+		case GroupOrderDuration:
+			if a.Duration != b.Duration {
+				return a.Duration > b.Duration
+			}
+		case GroupOrderDiscovery:
+			if !a.Created.Equal(b.Created) {
+				return a.Created.Before(b.Created)
+			}
+			return false
+		}
+		return a.Name < b.Name
+	})
+	return sorted
+}
+
+// sortedSubgroups returns a group's subgroups in the given order
+func sortedSubgroups(group *TestGroup, order GroupOrder) []*TestGroup {
+	subgroups := make([]*TestGroup, 0, len(group.Subgroups))
+	for _, sg := range group.Subgroups {
+		subgroups = append(subgroups, sg)
+	}
+	if order == GroupOrderDiscovery {
+		// Map iteration has no order of its own; fall back to names for equal creation times
+		sort.Slice(subgroups, func(i, j int) bool { return subgroups[i].Name < subgroups[j].Name })
+	}
+	return sortGroups(subgroups, order)
+}
+
+// SetGroupOrder sets the order of the subgroup table in group reports
+func (gm *GroupManager) SetGroupOrder(order GroupOrder) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.groupOrder = order
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortGroups(t *testing.T) {
+	start := time.Now()
+	groups := []*TestGroup{
+		{Name: "b.test.js", Status: TestStatusPass, Duration: 2 * time.Second, Created: start},
+		{Name: "c.test.js", Status: TestStatusFail, Duration: time.Second, Created: start.Add(time.Millisecond)},
+		{Name: "a.test.js", Status: TestStatusSkip, Duration: 3 * time.Second, Created: start.Add(2 * time.Millisecond)},
+		{Name: "d.test.js", Status: TestStatusFail, Duration: time.Second, Created: start.Add(3 * time.Millisecond)},
+	}
+
+	testCases := []struct {
+		order GroupOrder
+		want  []string
+	}{
+		{GroupOrderName, []string{"a.test.js", "b.test.js", "c.test.js", "d.test.js"}},
+		{GroupOrderStatus, []string{"c.test.js", "d.test.js", "b.test.js", "a.test.js"}},
+		{GroupOrderDuration, []string{"a.test.js", "b.test.js", "c.test.js", "d.test.js"}},
+		{GroupOrderDiscovery, []string{"b.test.js", "c.test.js", "a.test.js", "d.test.js"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.order), func(t *testing.T) {
+			sorted := sortGroups(groups, tc.order)
+			for i, group := range sorted {
+				if group.Name != tc.want[i] {
+					t.Fatalf("Expected order %v, got %s at position %d", tc.want, group.Name, i)
+				}
+			}
+		})
+	}
+
+	if groups[0].Name != "b.test.js" {
+		t.Errorf("sortGroups modified its input")
+	}
+}
+
+func TestParseGroupOrder(t *testing.T) {
+	if order, err := ParseGroupOrder(""); err != nil || order != GroupOrderName {
+		t.Errorf("Expected empty value to mean name, got %q (%v)", order, err)
+	}
+	if order, err := ParseGroupOrder("duration"); err != nil || order != GroupOrderDuration {
+		t.Errorf("Expected duration, got %q (%v)", order, err)
+	}
+	if _, err := ParseGroupOrder("size"); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+}
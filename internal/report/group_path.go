@@ -19,6 +19,10 @@ const (
 
 	// MaxDepth is the maximum nesting depth to prevent excessive directory nesting
 	MaxDepth = 20
+
+	// MaxReportPathLength is the longest a group's report directory may get below the
+	// run's reports directory before it is shortened with hashes
+	MaxReportPathLength = 200
 )
 
 var (
@@ -96,137 +100,91 @@ func GenerateGroupPath(group *TestGroup, runDir string) string {
 	}
 
 	// Build the full hierarchy path
-	hierarchy := append(group.ParentNames, group.Name)
+	hierarchy := append(append([]string{}, group.ParentNames...), group.Name)
+	return generatePath(hierarchy, runDir, group.pathSuffix)
+}
 
+// GenerateGroupPathFromHierarchy generates a filesystem path from a hierarchy slice
+func GenerateGroupPathFromHierarchy(hierarchy []string, runDir string) string {
+	if len(hierarchy) == 0 {
+		return filepath.Join(runDir, "reports")
+	}
+	return generatePath(hierarchy, runDir, "")
+}
+
+// generatePath builds the report directory of a group from its hierarchy, appending
+// suffix to the group's own directory name. Directories more than MaxReportPathLength
+// below reports (or past the Windows path limit) are shortened with hashes.
+func generatePath(hierarchy []string, runDir string, suffix string) string {
 	// Limit depth to prevent excessive nesting
 	if len(hierarchy) > MaxDepth {
 		// Collapse intermediate levels
 		hierarchy = collapseHierarchy(hierarchy)
 	}
 
-	// Sanitize each component
-	components := make([]string, 0, len(hierarchy)+2)
-	components = append(components, runDir, "reports")
-
-	// Derive the test execution directory from runDir
-	// runDir is something like "/tmp/3pio-open-source/jest/.3pio/runs/[id]"
-	// We need to get "/tmp/3pio-open-source/jest"
-	var testExecDir string
-	if absRunDir, err := filepath.Abs(runDir); err == nil {
-		// Go up from runDir to find the project root (parent of .3pio)
-		testExecDir = filepath.Dir(filepath.Dir(absRunDir)) // Go up twice: [id] -> runs -> .3pio
-		testExecDir = filepath.Dir(testExecDir)             // Go up once more: .3pio -> project root
-
-		// Resolve symlinks for consistent comparison
-		if resolved, err := filepath.EvalSymlinks(testExecDir); err == nil {
-			testExecDir = resolved
-		}
-	}
-
+	execDir := testExecDir(runDir)
+	components := make([]string, 0, len(hierarchy))
 	for _, part := range hierarchy {
-		// For absolute paths, make them relative to the test execution directory
-		if strings.HasPrefix(part, "/") && testExecDir != "" {
-			// Resolve symlinks in the file path for consistent comparison
-			resolvedPart := part
-			if resolved, err := filepath.EvalSymlinks(part); err == nil {
-				resolvedPart = resolved
-			}
-
-			// Try to make the path relative to the test execution directory
-			if relPath, err := filepath.Rel(testExecDir, resolvedPart); err == nil {
-				// Only use relative path if it doesn't go outside the project (no ../..)
-				if !strings.HasPrefix(relPath, "..") {
-					part = relPath
-				}
-			}
-		}
-
-		// Always sanitize the entire group name as a single unit
-		// This ensures Go package names like "github.com/zk/3pio" become "github_com_zk_3pio"
-		// and file paths like "./src/test.js" become "_src_test_js"
-		sanitized := SanitizeGroupName(part)
-		if sanitized != "" {
-			components = append(components, sanitized)
-		}
+		components = append(components, groupPathComponent(part, execDir))
 	}
+	components[len(components)-1] += suffix
 
-	// Build the path
-	path := filepath.Join(components...)
+	reportsDir := filepath.Join(runDir, "reports")
+	path := filepath.Join(append([]string{reportsDir}, components...)...)
 
-	// Check Windows path length limit
-	if runtime.GOOS == "windows" && len(path) > MaxWindowsPathLength {
-		path = shortenPath(path, runDir, hierarchy)
+	// Check the path length limits
+	if len(path)-len(reportsDir) > MaxReportPathLength ||
+		(runtime.GOOS == "windows" && len(path) > MaxWindowsPathLength) {
+		path = shortenPath(reportsDir, components)
 	}
 
 	return path
 }
 
-// GenerateGroupPathFromHierarchy generates a filesystem path from a hierarchy slice
-func GenerateGroupPathFromHierarchy(hierarchy []string, runDir string) string {
-	if len(hierarchy) == 0 {
-		return filepath.Join(runDir, "reports")
+// testExecDir derives the directory tests ran in from runDir, to make file paths in group
+// names relative to it. runDir is something like "/tmp/3pio-open-source/jest/.3pio/runs/[id]"
+// and the result "/tmp/3pio-open-source/jest".
+func testExecDir(runDir string) string {
+	absRunDir, err := filepath.Abs(runDir)
+	if err != nil {
+		return ""
 	}
 
-	// Limit depth to prevent excessive nesting
-	if len(hierarchy) > MaxDepth {
-		hierarchy = collapseHierarchy(hierarchy)
-	}
+	// Go up from runDir to find the project root (parent of .3pio)
+	execDir := filepath.Dir(filepath.Dir(absRunDir)) // Go up twice: [id] -> runs -> .3pio
+	execDir = filepath.Dir(execDir)                  // Go up once more: .3pio -> project root
 
-	// Sanitize each component
-	components := make([]string, 0, len(hierarchy)+2)
-	components = append(components, runDir, "reports")
-
-	// Derive the test execution directory from runDir
-	// runDir is something like "/tmp/3pio-open-source/jest/.3pio/runs/[id]"
-	// We need to get "/tmp/3pio-open-source/jest"
-	var testExecDir string
-	if absRunDir, err := filepath.Abs(runDir); err == nil {
-		// Go up from runDir to find the project root (parent of .3pio)
-		testExecDir = filepath.Dir(filepath.Dir(absRunDir)) // Go up twice: [id] -> runs -> .3pio
-		testExecDir = filepath.Dir(testExecDir)             // Go up once more: .3pio -> project root
-
-		// Resolve symlinks for consistent comparison
-		if resolved, err := filepath.EvalSymlinks(testExecDir); err == nil {
-			testExecDir = resolved
-		}
+	// Resolve symlinks for consistent comparison
+	if resolved, err := filepath.EvalSymlinks(execDir); err == nil {
+		execDir = resolved
 	}
+	return execDir
+}
 
-	for _, part := range hierarchy {
-		// For absolute paths, make them relative to the test execution directory
-		if strings.HasPrefix(part, "/") && testExecDir != "" {
-			// Resolve symlinks in the file path for consistent comparison
-			resolvedPart := part
-			if resolved, err := filepath.EvalSymlinks(part); err == nil {
-				resolvedPart = resolved
-			}
-
-			// Try to make the path relative to the test execution directory
-			if relPath, err := filepath.Rel(testExecDir, resolvedPart); err == nil {
-				// Only use relative path if it doesn't go outside the project (no ../..)
-				if !strings.HasPrefix(relPath, "..") {
-					part = relPath
-				}
-			}
+// groupPathComponent returns the directory name for one level of a group hierarchy.
+// Absolute paths are made relative to execDir, the directory tests ran in.
+func groupPathComponent(part string, execDir string) string {
+	// For absolute paths, make them relative to the test execution directory
+	if strings.HasPrefix(part, "/") && execDir != "" {
+		// Resolve symlinks in the file path for consistent comparison
+		resolvedPart := part
+		if resolved, err := filepath.EvalSymlinks(part); err == nil {
+			resolvedPart = resolved
 		}
 
-		// Always sanitize the entire group name as a single unit
-		// This ensures Go package names like "github.com/zk/3pio" become "github_com_zk_3pio"
-		// and file paths like "./src/test.js" become "_src_test_js"
-		sanitized := SanitizeGroupName(part)
-		if sanitized != "" {
-			components = append(components, sanitized)
+		// Try to make the path relative to the test execution directory
+		if relPath, err := filepath.Rel(execDir, resolvedPart); err == nil {
+			// Only use relative path if it doesn't go outside the project (no ../..)
+			if !strings.HasPrefix(relPath, "..") {
+				part = relPath
+			}
 		}
 	}
 
-	// Build the path
-	path := filepath.Join(components...)
-
-	// Check Windows path length limit
-	if runtime.GOOS == "windows" && len(path) > MaxWindowsPathLength {
-		path = shortenPath(path, runDir, hierarchy)
-	}
-
-	return path
+	// Always sanitize the entire group name as a single unit
+	// This ensures Go package names like "github.com/zk/3pio" become "github_com_zk_3pio"
+	// and file paths like "./src/test.js" become "_src_test_js"
+	return SanitizeGroupName(part)
 }
 
 // collapseHierarchy reduces hierarchy depth by combining intermediate levels
@@ -261,42 +219,50 @@ func collapseHierarchy(hierarchy []string) []string {
 	return result
 }
 
-// shortenPath shortens a path that exceeds Windows path length limit
-func shortenPath(longPath string, runDir string, hierarchy []string) string {
-	// Strategy: Use hash-based short names for intermediate directories
-
-	if len(hierarchy) == 0 {
-		return runDir
+// shortenPath shortens a report directory that exceeds the path length limits.
+// The intermediate directories are replaced with a hash of them, and the group's own
+// directory name is kept, truncated with a hash suffix if it is still too long.
+func shortenPath(reportsDir string, components []string) string {
+	if len(components) == 0 {
+		return reportsDir
 	}
 
-	// Always keep the last component readable
-	lastComponent := SanitizeGroupName(hierarchy[len(hierarchy)-1])
-
-	// Calculate available space
-	baseLen := len(runDir) + len(lastComponent) + 10 // Extra space for separators
-	availableSpace := MaxWindowsPathLength - baseLen
-
-	if availableSpace <= 0 {
-		// Even the last component is too long, hash everything
-		hash := sha256.Sum256([]byte(strings.Join(hierarchy, ":")))
-		hashStr := hex.EncodeToString(hash[:8])
-		return filepath.Join(runDir, hashStr)
+	limit := MaxReportPathLength
+	if runtime.GOOS == "windows" && MaxWindowsPathLength-len(reportsDir) < limit {
+		limit = MaxWindowsPathLength - len(reportsDir)
 	}
 
 	// Build shortened path
-	components := []string{runDir}
+	shortened := []string{reportsDir}
+	available := limit - 1 // Separator before the last component
 
-	if len(hierarchy) > 1 {
+	if len(components) > 1 {
 		// Hash all intermediate components
-		intermediate := hierarchy[:len(hierarchy)-1]
-		hash := sha256.Sum256([]byte(strings.Join(intermediate, ":")))
+		intermediate := components[:len(components)-1]
+		hash := sha256.Sum256([]byte(strings.Join(intermediate, "/")))
 		hashStr := hex.EncodeToString(hash[:8])
-		components = append(components, hashStr)
+		shortened = append(shortened, hashStr)
+		available -= len(hashStr) + 1
+	}
+
+	// Always keep the last component readable, as far as it fits
+	lastComponent := components[len(components)-1]
+	if len(lastComponent) > available {
+		if available < 17 {
+			// No room for a readable name, hash everything
+			hash := sha256.Sum256([]byte(strings.Join(components, "/")))
+			return filepath.Join(reportsDir, hex.EncodeToString(hash[:8]))
+		}
+		lastComponent = lastComponent[:available-9] + "_" + shortHash(lastComponent)
 	}
 
-	components = append(components, lastComponent)
+	return filepath.Join(append(shortened, lastComponent)...)
+}
 
-	return filepath.Join(components...)
+// shortHash returns 8 hex characters of the SHA-256 of s
+func shortHash(s string) string {
+	hash := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(hash[:4])
 }
 
 // GetReportFilePath returns the path to the report file for a group
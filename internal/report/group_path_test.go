@@ -5,6 +5,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
 )
 
 func TestSanitizeGroupName(t *testing.T) {
@@ -430,3 +432,99 @@ func TestGetRelativeReportPath(t *testing.T) {
 		t.Errorf("Should end with index.md: %s", relPath)
 	}
 }
+
+func TestGenerateGroupPath_LengthLimit(t *testing.T) {
+	runDir := t.TempDir()
+	reportsDir := filepath.Join(runDir, "reports")
+
+	longName := strings.Repeat("describe block with a long name ", 10)
+	tests := []struct {
+		name  string
+		group *TestGroup
+	}{
+		{"Long nested names", &TestGroup{Name: "leaf", ParentNames: []string{"suite.test.js", longName, longName}}},
+		{"Name at component limit", &TestGroup{Name: strings.Repeat("x", 400), ParentNames: []string{"suite.test.js"}}},
+		{"Special characters", &TestGroup{Name: strings.Repeat(`<>:"|?*\/. `, 40), ParentNames: []string{"suite.test.js"}}},
+	}
+
+	seen := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := GenerateGroupPath(tt.group, runDir)
+			rel, err := filepath.Rel(reportsDir, path)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				t.Fatalf("Expected %s under %s", path, reportsDir)
+			}
+			if len(rel) > MaxReportPathLength {
+				t.Errorf("Report directory %s is %d characters, over %d", rel, len(rel), MaxReportPathLength)
+			}
+			if !IsValidFilePath(path) {
+				t.Errorf("Generated invalid path: %s", path)
+			}
+			if other, ok := seen[path]; ok {
+				t.Errorf("%s and %s share the report directory %s", tt.name, other, path)
+			}
+			seen[path] = tt.name
+
+			// The shortened path is stable
+			if again := GenerateGroupPath(tt.group, runDir); again != path {
+				t.Errorf("Expected the same path twice, got %s and %s", path, again)
+			}
+		})
+	}
+
+	// Shortening keeps the group's own name readable
+	path := GenerateGroupPath(tests[0].group, runDir)
+	if filepath.Base(path) != "leaf" {
+		t.Errorf("Expected the shortened path to end with the group name, got %s", path)
+	}
+
+	// Short paths are left alone
+	short := &TestGroup{Name: "adds", ParentNames: []string{"math.test.js"}}
+	if want := filepath.Join(reportsDir, "math_test_js", "adds"); GenerateGroupPath(short, runDir) != want {
+		t.Errorf("Expected %s, got %s", want, GenerateGroupPath(short, runDir))
+	}
+}
+
+func TestGroupManager_ReportDirCollisions(t *testing.T) {
+	runDir := t.TempDir()
+	gm := NewGroupManager(runDir, "", nil)
+
+	// Each of these sanitizes to the same directory name as another
+	names := []string{"math.test", "math_test", "math-test", "Math_Test", "math test"}
+	for _, name := range names {
+		if err := gm.ProcessGroupDiscovered(ipc.GroupDiscoveredEvent{
+			Payload: ipc.GroupDiscoveredPayload{GroupName: name, ParentNames: []string{"suite.test.js"}},
+		}); err != nil {
+			t.Fatalf("ProcessGroupDiscovered failed: %v", err)
+		}
+	}
+	// A duplicate base name under another parent needs no suffix
+	if err := gm.ProcessGroupDiscovered(ipc.GroupDiscoveredEvent{
+		Payload: ipc.GroupDiscoveredPayload{GroupName: "math.test", ParentNames: []string{"other.test.js"}},
+	}); err != nil {
+		t.Fatalf("ProcessGroupDiscovered failed: %v", err)
+	}
+
+	paths := make(map[string]string)
+	for _, name := range names {
+		group, ok := gm.GetGroup(GenerateGroupID(name, []string{"suite.test.js"}))
+		if !ok {
+			t.Fatalf("Group %q not found", name)
+		}
+		path := strings.ToLower(GetReportFilePath(group, runDir))
+		if other, taken := paths[path]; taken {
+			t.Errorf("Groups %q and %q share the report %s", name, other, path)
+		}
+		paths[path] = name
+	}
+
+	first, _ := gm.GetGroup(GenerateGroupID("math.test", []string{"suite.test.js"}))
+	if want := filepath.Join(runDir, "reports", "suite_test_js", "math_test"); GenerateGroupPath(first, runDir) != want {
+		t.Errorf("Expected the first group to keep %s, got %s", want, GenerateGroupPath(first, runDir))
+	}
+	other, _ := gm.GetGroup(GenerateGroupID("math.test", []string{"other.test.js"}))
+	if want := filepath.Join(runDir, "reports", "other_test_js", "math_test"); GenerateGroupPath(other, runDir) != want {
+		t.Errorf("Expected %s for the same name under another parent, got %s", want, GenerateGroupPath(other, runDir))
+	}
+}
@@ -1,7 +1,11 @@
 package report
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/zk/3pio/internal/ipc"
 )
 
 // TestStatus represents the status of a test or group
@@ -28,7 +32,8 @@ type TestGroup struct {
 	ParentNames []string // Full hierarchy from root (excludes this group's name)
 	Depth       int      // Depth in hierarchy (0 for root)
 
-	// Status and timing
+	// Status and timing. Created is when the group was discovered, so a group queued
+	// behind others (see WaitDuration) starts later; Duration is the time it ran.
 	Status    TestStatus
 	Duration  time.Duration
 	StartTime time.Time
@@ -46,9 +51,23 @@ type TestGroup struct {
 	// Error information for group-level failures
 	ErrorInfo *TestError
 
+	// Statement coverage the runner reported for the group (go test -cover), e.g. "87.5% of statements"
+	Coverage string
+
 	// Output
 	Stdout string // Accumulated stdout for this group
 	Stderr string // Accumulated stderr for this group
+
+	// Set once Stdout or Stderr exceed the output limit (see SetMaxGroupOutput)
+	stdoutTruncated *truncatedOutput
+	stderrTruncated *truncatedOutput
+
+	// Start of an ANSI escape sequence cut off at the end of the last chunk (see SetKeepANSI)
+	stdoutEscape string
+	stderrEscape string
+
+	// Appended to the group's report directory name when a sibling's would be the same
+	pathSuffix string
 }
 
 // TestGroupStats holds aggregated statistics for a test group
@@ -59,6 +78,7 @@ type TestGroupStats struct {
 	SkippedTests int
 	XFailedTests int  // Tests that failed as expected
 	XPassedTests int  // Tests that passed unexpectedly
+	FlakyTests   int  // Passed tests that failed on an earlier attempt (also counted as passed)
 	SetupFailed  bool // Indicates this group failed during setup/initialization
 
 	// Recursive counts (includes subgroups)
@@ -68,6 +88,7 @@ type TestGroupStats struct {
 	SkippedTestsRecursive int
 	XFailedTestsRecursive int
 	XPassedTestsRecursive int
+	FlakyTestsRecursive   int
 }
 
 // TestCase represents an individual test
@@ -83,23 +104,103 @@ type TestCase struct {
 	StartTime   time.Time
 	EndTime     time.Time
 	XFailReason string // Reason for expected failure (xfail marker)
+	SkipReason  string // Why a skipped test was skipped (skipif marker, t.Skip message)
+	Slow        bool   // Duration exceeded the configured slow threshold
 
 	// Error information
 	Error *TestError
 
+	// Every run of a retried test in order, the last being the one above.
+	// Empty when the test ran once.
+	Attempts []Attempt
+	// Attempts are runs the runner repeated on purpose (go test -count=N) rather than
+	// retries. The status above is then FAIL if any run failed, and Duration is the
+	// total of all runs.
+	Repeated bool
+
+	// Benchmark measurements, only set for benchmark test cases
+	Benchmark *Benchmark
+
 	// Output
 	Stdout string // stdout captured during this test
 	Stderr string // stderr captured during this test
 }
 
+// Attempt is one run of a test case that the runner retried
+type Attempt struct {
+	Status   TestStatus
+	Duration time.Duration
+	Error    *TestError
+}
+
+// Flaky returns true if the test passed after failing on an earlier attempt
+func (tc *TestCase) Flaky() bool {
+	if tc.Status != TestStatusPass {
+		return false
+	}
+	for _, attempt := range tc.Attempts {
+		if attempt.Status == TestStatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// RunsSummary describes the runs of a repeated test, e.g. "3 runs: 2 passed, 1 failed"
+func (tc *TestCase) RunsSummary() string {
+	counts := make(map[TestStatus]int)
+	for _, attempt := range tc.Attempts {
+		counts[attempt.Status]++
+	}
+	var parts []string
+	for _, status := range []TestStatus{TestStatusPass, TestStatusFail, TestStatusSkip} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], runStatusWords[status]))
+		}
+	}
+	return fmt.Sprintf("%d runs: %s", len(tc.Attempts), strings.Join(parts, ", "))
+}
+
+// runStatusWords names the statuses counted by RunsSummary
+var runStatusWords = map[TestStatus]string{
+	TestStatusPass: "passed",
+	TestStatusFail: "failed",
+	TestStatusSkip: "skipped",
+}
+
+// runs returns every run of the test case so far, the latest last
+func (tc *TestCase) runs() []Attempt {
+	if len(tc.Attempts) == 0 {
+		return []Attempt{tc.attempt()}
+	}
+	return tc.Attempts
+}
+
+// attempt returns the outcome of the test case's latest run
+func (tc *TestCase) attempt() Attempt {
+	return Attempt{Status: tc.Status, Duration: tc.Duration, Error: tc.Error}
+}
+
+// Benchmark contains the measurements reported for a benchmark run
+type Benchmark struct {
+	Iterations  int64   // Number of iterations (b.N)
+	NsPerOp     float64 // Nanoseconds per operation
+	BytesPerOp  int64   // Bytes allocated per operation (-benchmem)
+	AllocsPerOp int64   // Allocations per operation (-benchmem)
+	MemStats    bool    // True when BytesPerOp and AllocsPerOp were reported
+}
+
 // TestError represents error information for a failed test or group
 type TestError struct {
-	Message  string // Error message
-	Stack    string // Stack trace
-	Expected string // Expected value (for assertions)
-	Actual   string // Actual value (for assertions)
-	Location string // File:line where error occurred
-	Type     string // Type of error (e.g., "AssertionError", "SETUP_FAILURE")
+	Message  string          // Error message
+	Stack    string          // Stack trace
+	Expected string          // Expected value (for assertions)
+	Actual   string          // Actual value (for assertions)
+	Location string          // File:line where error occurred
+	Type     string          // Type of error (e.g., "AssertionError", "SETUP_FAILURE")
+	Kind     ipc.FailureKind // Why a test case failed: assertion, exception, timeout, ... (empty for group errors)
+
+	DetailOmitted bool // Message, stack and output were dropped past --max-failures
 }
 
 // IsComplete returns true if the group has finished executing
@@ -112,6 +213,20 @@ func (g *TestGroup) IsComplete() bool {
 		g.Status == TestStatusXPass
 }
 
+// minReportedWait is the shortest wait group reports show, in their frontmatter and
+// subgroup tables alike. Shorter waits are scheduling noise rather than queueing.
+const minReportedWait = 50 * time.Millisecond
+
+// WaitDuration returns how long the group waited between being discovered and starting,
+// e.g. a test file queued for a free Jest worker. It is 0 for a group that hasn't
+// started, and for runners that discover a group only when it starts.
+func (g *TestGroup) WaitDuration() time.Duration {
+	if g.Created.IsZero() || g.StartTime.IsZero() || !g.StartTime.After(g.Created) {
+		return 0
+	}
+	return g.StartTime.Sub(g.Created)
+}
+
 // HasFailures returns true if the group or any of its children have failures
 func (g *TestGroup) HasFailures() bool {
 	if g.Status == TestStatusFail || g.Status == TestStatusError {
@@ -147,6 +262,10 @@ func (g *TestGroup) UpdateStats() {
 		case TestStatusPass:
 			g.Stats.PassedTests++
 			g.Stats.PassedTestsRecursive++
+			if tc.Flaky() {
+				g.Stats.FlakyTests++
+				g.Stats.FlakyTestsRecursive++
+			}
 		case TestStatusFail:
 			g.Stats.FailedTests++
 			g.Stats.FailedTestsRecursive++
@@ -171,6 +290,7 @@ func (g *TestGroup) UpdateStats() {
 		g.Stats.SkippedTestsRecursive += sg.Stats.SkippedTestsRecursive
 		g.Stats.XFailedTestsRecursive += sg.Stats.XFailedTestsRecursive
 		g.Stats.XPassedTestsRecursive += sg.Stats.XPassedTestsRecursive
+		g.Stats.FlakyTestsRecursive += sg.Stats.FlakyTestsRecursive
 	}
 
 	// Update group status based on children
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JUnit XML structures. The schema follows the de facto format understood by
+// Jenkins, GitLab and CircleCI rather than any single formal XSD.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Error     []junitError    `xml:"error,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+type junitError struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// writeJUnitReport writes the JUnit XML report to the configured path
+func (m *Manager) writeJUnitReport() error {
+	if m.junitPath == "" || m.groupManager == nil {
+		return nil
+	}
+
+	suites := buildJUnitReport(filepath.Base(m.runDir), m.groupManager.GetRootGroups(), m.runEnd().Sub(m.startTime))
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	if dir := filepath.Dir(m.junitPath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create JUnit report directory: %w", err)
+		}
+	}
+
+	content := append([]byte(xml.Header), data...)
+	content = append(content, '\n')
+	return os.WriteFile(m.junitPath, content, 0644)
+}
+
+// buildJUnitReport converts root groups into JUnit test suites.
+// Each root group becomes a <testsuite>; nested groups are flattened into it
+// with their hierarchy recorded in the testcase classname.
+func buildJUnitReport(name string, rootGroups []*TestGroup, totalDuration time.Duration) junitTestSuites {
+	suites := junitTestSuites{
+		Name:   name,
+		Time:   formatJUnitSeconds(totalDuration),
+		Suites: make([]junitTestSuite, 0, len(rootGroups)),
+	}
+
+	for _, group := range rootGroups {
+		suite := junitTestSuite{
+			Name: group.Name,
+			Time: formatJUnitSeconds(group.Duration),
+		}
+		if !group.StartTime.IsZero() {
+			suite.Timestamp = group.StartTime.UTC().Format("2006-01-02T15:04:05")
+		}
+
+		collectJUnitGroup(&suite, group)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return suites
+}
+
+// collectJUnitGroup adds a group's test cases, errors and output to a suite, recursing into subgroups
+func collectJUnitGroup(suite *junitTestSuite, group *TestGroup) {
+	if group.Stats.SetupFailed {
+		suite.Errors++
+		junitErr := junitError{Message: fmt.Sprintf("%s failed during setup", group.Name)}
+		if group.ErrorInfo != nil {
+			junitErr.Type = group.ErrorInfo.Type
+			junitErr.Body = group.ErrorInfo.Message
+		}
+		suite.Error = append(suite.Error, junitErr)
+	}
+
+	className := strings.Join(group.GetFullPath(), " > ")
+	for _, tc := range group.TestCases {
+		testCase := junitTestCase{
+			Name:      tc.Name,
+			ClassName: className,
+			Time:      formatJUnitSeconds(tc.Duration),
+		}
+
+		switch tc.Status {
+		case TestStatusFail:
+			suite.Failures++
+			failure := &junitFailure{}
+			if tc.Error != nil {
+				failure.Message = firstLine(tc.Error.Message)
+				failure.Type = tc.Error.Type
+				failure.Body = tc.Error.Message
+				if tc.Error.Stack != "" {
+					failure.Body += "\n" + tc.Error.Stack
+				}
+			}
+			testCase.Failure = failure
+		case TestStatusSkip:
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{Message: tc.SkipReason}
+		case TestStatusXFail:
+			// Expected failures did not break the build, so report them as skipped
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{Message: "expected failure"}
+			if tc.XFailReason != "" {
+				testCase.Skipped.Message = "expected failure: " + tc.XFailReason
+			}
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if group.Stdout != "" {
+		suite.SystemOut += group.Stdout
+	}
+	if group.Stderr != "" {
+		suite.SystemErr += group.Stderr
+	}
+
+	// Visit subgroups in name order so the XML is stable between runs
+	subgroups := make([]*TestGroup, 0, len(group.Subgroups))
+	for _, sg := range group.Subgroups {
+		subgroups = append(subgroups, sg)
+	}
+	sort.Slice(subgroups, func(i, j int) bool {
+		return subgroups[i].Name < subgroups[j].Name
+	})
+	for _, sg := range subgroups {
+		collectJUnitGroup(suite, sg)
+	}
+}
+
+// formatJUnitSeconds formats a duration as fractional seconds for JUnit time attributes
+func formatJUnitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// firstLine returns the first line of a message for use in short attributes
+func firstLine(message string) string {
+	if idx := strings.IndexByte(message, '\n'); idx >= 0 {
+		return message[:idx]
+	}
+	return message
+}
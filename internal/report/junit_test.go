@@ -0,0 +1,145 @@
+package report
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestManager_WritesJUnitReport(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := &mockLogger{}
+	parser := runner.NewJestOutputParser()
+
+	manager, err := NewManager(tempDir, parser, logger, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	events := []ipc.Event{
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "adds numbers",
+				ParentNames: []string{"math.test.js", "Calculator"},
+				Status:      "PASS",
+				Duration:    12,
+			},
+		},
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "divides by zero",
+				ParentNames: []string{"math.test.js", "Calculator"},
+				Status:      "FAIL",
+				Error: &ipc.TestError{
+					Message:   "expected Infinity\nreceived NaN",
+					ErrorType: "AssertionError",
+				},
+			},
+		},
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "todo",
+				ParentNames: []string{"math.test.js"},
+				Status:      "SKIP",
+			},
+		},
+		ipc.NewGroupErrorEvent("broken.test.js", nil, "SETUP_FAILURE", 0, "cannot find module"),
+	}
+	for _, event := range events {
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+
+	if err := manager.Finalize(1); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.xml"))
+	if err != nil {
+		t.Fatalf("Expected test-run.xml to be written: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(content, &suites); err != nil {
+		t.Fatalf("test-run.xml is not valid XML: %v\n%s", err, content)
+	}
+
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || suites.Errors != 1 {
+		t.Errorf("Unexpected totals: tests=%d failures=%d skipped=%d errors=%d",
+			suites.Tests, suites.Failures, suites.Skipped, suites.Errors)
+	}
+	if len(suites.Suites) != 2 {
+		t.Fatalf("Expected 2 test suites, got %d", len(suites.Suites))
+	}
+
+	var mathSuite, brokenSuite *junitTestSuite
+	for i := range suites.Suites {
+		switch filepath.Base(suites.Suites[i].Name) {
+		case "math.test.js":
+			mathSuite = &suites.Suites[i]
+		case "broken.test.js":
+			brokenSuite = &suites.Suites[i]
+		}
+	}
+	if mathSuite == nil || brokenSuite == nil {
+		t.Fatalf("Missing expected suites in:\n%s", content)
+	}
+
+	var failed *junitTestCase
+	for i := range mathSuite.TestCases {
+		if mathSuite.TestCases[i].Name == "divides by zero" {
+			failed = &mathSuite.TestCases[i]
+		}
+	}
+	if failed == nil || failed.Failure == nil {
+		t.Fatalf("Expected failure node for 'divides by zero':\n%s", content)
+	}
+	if failed.Failure.Message != "expected Infinity" {
+		t.Errorf("Expected failure message to be first line of error, got %q", failed.Failure.Message)
+	}
+	if !strings.HasSuffix(failed.ClassName, "Calculator") {
+		t.Errorf("Expected classname to include parent hierarchy, got %q", failed.ClassName)
+	}
+
+	if len(brokenSuite.Error) != 1 || brokenSuite.Error[0].Type != "SETUP_FAILURE" {
+		t.Errorf("Expected suite-level setup error for broken.test.js, got %+v", brokenSuite.Error)
+	}
+}
+
+func TestManager_JUnitPathOverride(t *testing.T) {
+	tempDir := t.TempDir()
+	customPath := filepath.Join(tempDir, "ci", "junit.xml")
+
+	manager, err := NewManager(tempDir, runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetJUnitPath(customPath)
+
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := manager.Finalize(0); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	if _, err := os.Stat(customPath); err != nil {
+		t.Errorf("Expected JUnit report at %s: %v", customPath, err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "test-run.xml")); !os.IsNotExist(err) {
+		t.Errorf("Did not expect default test-run.xml when a custom path is set")
+	}
+}
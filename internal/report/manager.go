@@ -18,8 +18,16 @@ type Manager struct {
 	state           *ipc.TestRunState
 	outputParser    runner.OutputParser
 	logger          Logger
-	detectedRunner  string // e.g., "vitest", "jest", "go test", "pytest"
-	modifiedCommand string // The actual command executed with adapter
+	detectedRunner  string            // e.g., "vitest", "jest", "go test", "pytest"
+	runnerVersion   string            // Version of the test runner, "" when unknown
+	modifiedCommand string            // The actual command executed with adapter
+	junitPath       string            // Where the JUnit XML report is written on finalize
+	slowThreshold   time.Duration     // Test cases slower than this are listed in test-run.md (0 disables)
+	onlyFailures    bool              // List only failing groups in test-run.md's group table
+	groupOrder      GroupOrder        // Order of test-run.md's group table
+	interrupted     bool              // The run was stopped by a signal (see SetInterrupted)
+	runnerDef       runner.Definition // Builds the rerun command for failed tests (nil omits it)
+	redactor        *pathRedactor     // Hides the project root and home directory in test-run.md (nil keeps them)
 
 	// Group manager for hierarchical test organization
 	groupManager *GroupManager
@@ -31,22 +39,30 @@ type Manager struct {
 	fileBuffers map[string][]string
 	debouncers  map[string]*time.Timer
 	outputFile  *os.File
+	noOutputLog bool // output.log was removed for --no-output-log (see DisableOutputLog)
+
+	// output.log is gzipped into output.log.gz on finalize (see CompressOutputLog)
+	compressOutput   bool
+	outputCompressed bool // output.log.gz has replaced output.log
 
 	// Separate stdout/stderr buffers for structured reports
 	stdoutBuffers map[string][]string
 	stderrBuffers map[string][]string
 
-	// Debouncing for main report writes
+	// Throttling for main report writes. writeTimer is non-nil while a write is scheduled.
 	writeTimer   *time.Timer
 	writeMutex   sync.Mutex
 	pendingWrite bool
 
-	mu           sync.RWMutex
-	debounceTime time.Duration
-	maxWaitTime  time.Duration
+	mu            sync.RWMutex
+	debounceTime  time.Duration
+	writeInterval time.Duration // Minimum time between test-run.md rewrites
+	lastWrite     time.Time     // When test-run.md was last written (guarded by mu)
+	reportWrites  int           // Number of test-run.md writes (guarded by mu)
 
 	// Track test run start time for wall-clock duration
 	startTime time.Time
+	endTime   time.Time // Fixed end of the run once finalized or when regenerating a past run (zero means now)
 }
 
 // NewManager creates a new report manager
@@ -69,6 +85,12 @@ func NewManager(runDir string, parser runner.OutputParser, lg Logger, detectedRu
 		return nil, fmt.Errorf("failed to create output.log: %w", err)
 	}
 
+	return newManager(runDir, parser, lg, detectedRunner, modifiedCommand, outputFile), nil
+}
+
+// newManager creates a manager for an existing run directory. outputFile may be nil
+// when output.log must be left alone.
+func newManager(runDir string, parser runner.OutputParser, lg Logger, detectedRunner string, modifiedCommand string, outputFile *os.File) *Manager {
 	// Initialize GroupManager for hierarchical test organization
 	groupManager := NewGroupManager(runDir, "", lg)
 
@@ -78,7 +100,9 @@ func NewManager(runDir string, parser runner.OutputParser, lg Logger, detectedRu
 		logger:          lg,
 		detectedRunner:  detectedRunner,
 		modifiedCommand: modifiedCommand,
+		junitPath:       filepath.Join(runDir, "test-run.xml"),
 		groupManager:    groupManager,
+		groupOrder:      GroupOrderName,
 		fileHandles:     make(map[string]*os.File),
 		fileBuffers:     make(map[string][]string),
 		debouncers:      make(map[string]*time.Timer),
@@ -87,9 +111,9 @@ func NewManager(runDir string, parser runner.OutputParser, lg Logger, detectedRu
 		stderrBuffers:   make(map[string][]string),
 		pendingWrite:    false,
 		debounceTime:    200 * time.Millisecond,
-		maxWaitTime:     500 * time.Millisecond,
+		writeInterval:   time.Second,
 		startTime:       time.Now(),
-	}, nil
+	}
 }
 
 // UpdateModifiedCommand updates the modified command after adapter extraction
@@ -99,6 +123,159 @@ func (m *Manager) UpdateModifiedCommand(command string) {
 	m.modifiedCommand = command
 }
 
+// SetRunnerVersion records the test runner's version, shown as runner_version in
+// test-run.md's frontmatter and runnerVersion in run.json
+func (m *Manager) SetRunnerVersion(version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runnerVersion = version
+}
+
+// SetJUnitPath overrides where the JUnit XML report is written
+func (m *Manager) SetJUnitPath(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.junitPath = path
+}
+
+// SetSlowThreshold flags test cases slower than threshold and lists them in test-run.md
+func (m *Manager) SetSlowThreshold(threshold time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slowThreshold = threshold
+	m.groupManager.SetSlowThreshold(threshold)
+}
+
+// SetMaxGroupOutput caps the stdout and stderr kept in each group report, in bytes.
+// output.log always has the full output.
+func (m *Manager) SetMaxGroupOutput(limit int) {
+	m.groupManager.SetMaxGroupOutput(limit)
+}
+
+// SetReportDetail sets how much of each test case group reports show: minimal leaves
+// out passing tests and full adds every test's stdout and stderr
+func (m *Manager) SetReportDetail(detail ReportDetail) {
+	m.groupManager.SetReportDetail(detail)
+}
+
+// DisableOutputLog removes output.log for runs that don't keep the command's output.
+// test-run.md then says the output was not saved instead of pointing at the file.
+func (m *Manager) DisableOutputLog() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.noOutputLog = true
+	if m.outputFile == nil {
+		return nil
+	}
+	path := m.outputFile.Name()
+	_ = m.outputFile.Close()
+	m.outputFile = nil
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove output.log: %w", err)
+	}
+	return nil
+}
+
+// SetOnlyFailures limits test-run.md's group table to groups with failed tests or setup
+// failures. Summary counts and per-group report files are unaffected.
+func (m *Manager) SetOnlyFailures(onlyFailures bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onlyFailures = onlyFailures
+}
+
+// SetGroupOrder sets the order of test-run.md's group table and of the subgroup table
+// in each group report
+func (m *Manager) SetGroupOrder(order GroupOrder) {
+	m.mu.Lock()
+	m.groupOrder = order
+	m.mu.Unlock()
+	m.groupManager.SetGroupOrder(order)
+}
+
+// SetAbortReason records that the run was stopped before the test command finished.
+// The reason is shown in test-run.md and run.json.
+func (m *Manager) SetAbortReason(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil {
+		m.state.AbortReason = reason
+	}
+}
+
+// SetInterrupted records that the run was stopped by a signal such as Ctrl-C. Finalize
+// then reports the run as INTERRUPTED and leaves unfinished groups RUNNING.
+func (m *Manager) SetInterrupted(signal string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interrupted = true
+	if m.state != nil {
+		m.state.AbortReason = fmt.Sprintf("Run was interrupted (%s) before the test command finished; results are partial", signal)
+	}
+}
+
+// SetExitReason records why the run exited non-zero (e.g. "tests_failed"). It is shown
+// as exit_reason in test-run.md's frontmatter and exitReason in run.json.
+func (m *Manager) SetExitReason(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil {
+		m.state.ExitReason = reason
+	}
+}
+
+// SetExitSignal records the signal that killed the test command (e.g. "SIGKILL" from the
+// OOM killer). It is shown as signal in test-run.md's frontmatter and in run.json.
+func (m *Manager) SetExitSignal(signal string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil {
+		m.state.Signal = signal
+	}
+}
+
+// SetRunnerExitCode records the test command's own exit code when 3pio exits with
+// another, e.g. under --fail-under or --fail-on-skip. Both are shown as runner_exit_code
+// and 3pio_exit_code in test-run.md's frontmatter, and run.json has runnerExitCode.
+func (m *Manager) SetRunnerExitCode(runnerExitCode, exitCode int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil && runnerExitCode != exitCode {
+		m.state.RunnerExitCode = &runnerExitCode
+		m.state.ExitCode = exitCode
+	}
+}
+
+// SetCrashedAttempts records the attempts of the test command that crashed before
+// reporting any results and were run again. They are listed in test-run.md and run.json.
+func (m *Manager) SetCrashedAttempts(attempts []ipc.CrashedAttempt) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil {
+		m.state.CrashedAttempts = attempts
+	}
+}
+
+// AddWarning records a problem that may make the run's results unreliable, such as a
+// contaminated IPC file. Warnings are listed in test-run.md and run.json.
+func (m *Manager) AddWarning(warning string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != nil {
+		m.state.Warnings = append(m.state.Warnings, warning)
+	}
+}
+
+// AbortReason returns why the run was stopped early, or "" if it ran to completion
+func (m *Manager) AbortReason() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.state == nil {
+		return ""
+	}
+	return m.state.AbortReason
+}
+
 // Initialize sets up the initial test run state
 func (m *Manager) Initialize(args string) error {
 	m.mu.Lock()
@@ -209,6 +386,12 @@ func (m *Manager) HandleEvent(event ipc.Event) error {
 
 // handleCollectionError handles collection error events (pytest specific)
 func (m *Manager) handleCollectionError(event ipc.CollectionErrorEvent) error {
+	if m.groupManager != nil {
+		if err := m.groupManager.ProcessCollectionError(event); err != nil {
+			return err
+		}
+	}
+
 	filePath := event.Payload.FilePath
 
 	// Find the test file and set execution error
@@ -249,20 +432,26 @@ func (m *Manager) SetExecutionError(filePath string, errorMsg string) error {
 
 // Legacy file registration methods removed - using group-based model
 
-// scheduleWrite schedules a debounced state write
+// scheduleWrite schedules a throttled state write. test-run.md is regenerated in full, so
+// for runs with thousands of groups rewrites are limited to one per writeInterval; the
+// per-group reports are written separately and stay current. A scheduled write is not
+// postponed by later events, so a steady stream of events cannot starve it.
+// Caller must hold m.mu.
 func (m *Manager) scheduleWrite() error {
 	m.writeMutex.Lock()
 	defer m.writeMutex.Unlock()
 
 	m.pendingWrite = true
-
-	// Cancel existing timer
 	if m.writeTimer != nil {
-		m.writeTimer.Stop()
+		return nil
 	}
 
-	// Schedule write after debounceTime of inactivity
-	m.writeTimer = time.AfterFunc(m.debounceTime, func() {
+	// Wait debounceTime to batch bursts of events, and longer if the last write was recent
+	delay := m.debounceTime
+	if wait := m.writeInterval - time.Since(m.lastWrite); wait > delay {
+		delay = wait
+	}
+	m.writeTimer = time.AfterFunc(delay, func() {
 		m.flushWrite()
 	})
 
@@ -272,6 +461,7 @@ func (m *Manager) scheduleWrite() error {
 // flushWrite executes pending write to disk
 func (m *Manager) flushWrite() {
 	m.writeMutex.Lock()
+	m.writeTimer = nil
 	if !m.pendingWrite {
 		m.writeMutex.Unlock()
 		return
@@ -279,23 +469,49 @@ func (m *Manager) flushWrite() {
 	m.pendingWrite = false
 	m.writeMutex.Unlock()
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Finalize has already written the final state
+	if m.state == nil || m.finished() {
+		return
+	}
 	if err := m.writeState(); err != nil {
 		m.logger.Error("Failed to write state: %v", err)
 	}
 }
 
-// writeState writes the current state to test-run.md
+// finished returns true once Finalize has set the final status. Caller must hold m.mu.
+func (m *Manager) finished() bool {
+	switch m.state.Status {
+	case "COMPLETE", "ERROR", "INTERRUPTED":
+		return true
+	}
+	return false
+}
+
+// writeState writes the current state to test-run.md. Caller must hold m.mu.
 func (m *Manager) writeState() error {
 	m.state.UpdatedAt = time.Now()
+	m.lastWrite = m.state.UpdatedAt
+	m.reportWrites++
 
 	// Generate markdown report
-	report := m.generateMarkdownReport()
+	report := m.redactor.redact(m.generateMarkdownReport())
 
 	// Write to file
 	reportPath := filepath.Join(m.runDir, "test-run.md")
 	return os.WriteFile(reportPath, []byte(report), 0644)
 }
 
+// runEnd returns the time the run's wall-clock duration is measured up to
+func (m *Manager) runEnd() time.Time {
+	if !m.endTime.IsZero() {
+		return m.endTime
+	}
+	return time.Now()
+}
+
 // writeOutputLogHeader writes the header for output.log
 func (m *Manager) writeOutputLogHeader(args string) error {
 	header := fmt.Sprintf(`# 3pio Test Output Log
@@ -306,6 +522,9 @@ func (m *Manager) writeOutputLogHeader(args string) error {
 
 `, time.Now().Format(time.RFC3339), args)
 
+	if m.outputFile == nil {
+		return nil
+	}
 	_, err := m.outputFile.WriteString(header)
 	return err
 }
@@ -327,6 +546,8 @@ func (m *Manager) generateMarkdownReport() string {
 		statusText = "COMPLETED"
 	case "ERROR":
 		statusText = "ERRORED"
+	case "INTERRUPTED":
+		statusText = "INTERRUPTED"
 	default:
 		statusText = "PENDING"
 	}
@@ -336,16 +557,35 @@ func (m *Manager) generateMarkdownReport() string {
 	fmt.Fprintf(sb, "run_id: %s\n", runID)
 	fmt.Fprintf(sb, "run_path: %s\n", m.runDir)
 	fmt.Fprintf(sb, "detected_runner: %s\n", m.detectedRunner)
+	if m.runnerVersion != "" {
+		fmt.Fprintf(sb, "runner_version: %s\n", m.runnerVersion)
+	}
 	fmt.Fprintf(sb, "modified_command: `%s`\n", m.modifiedCommand)
 	fmt.Fprintf(sb, "created: %s\n", m.state.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z"))
 	fmt.Fprintf(sb, "updated: %s\n", m.state.UpdatedAt.UTC().Format("2006-01-02T15:04:05.000Z"))
 	fmt.Fprintf(sb, "status: %s\n", statusText)
+	if m.state.ExitReason != "" {
+		fmt.Fprintf(sb, "exit_reason: %s\n", m.state.ExitReason)
+	}
+	if m.state.Signal != "" {
+		fmt.Fprintf(sb, "signal: %s\n", m.state.Signal)
+	}
+	if m.state.RunnerExitCode != nil {
+		fmt.Fprintf(sb, "runner_exit_code: %d\n", *m.state.RunnerExitCode)
+		fmt.Fprintf(sb, "3pio_exit_code: %d\n", m.state.ExitCode)
+	}
 	sb.WriteString("---\n\n")
 
 	// Header
 	sb.WriteString("# 3pio Test Run\n\n")
 	fmt.Fprintf(sb, "- Test command: `%s`\n", m.state.Arguments)
-	sb.WriteString("- Run stdout/stderr: `./output.log`\n\n")
+	if m.noOutputLog {
+		sb.WriteString("- Run stdout/stderr: not saved (--no-output-log)\n\n")
+	} else if m.outputCompressed {
+		sb.WriteString("- Run stdout/stderr: `./output.log.gz`\n\n")
+	} else {
+		sb.WriteString("- Run stdout/stderr: `./output.log`\n\n")
+	}
 
 	// Error details if status is ERRORED
 	if statusText == "ERRORED" && m.state.ErrorDetails != "" {
@@ -355,6 +595,28 @@ func (m *Manager) generateMarkdownReport() string {
 		sb.WriteString("\n```\n\n")
 	}
 
+	if m.state.AbortReason != "" {
+		sb.WriteString("## Aborted\n\n")
+		sb.WriteString(m.state.AbortReason)
+		sb.WriteString("\n\n")
+	}
+
+	if len(m.state.Warnings) > 0 {
+		sb.WriteString("## Warnings\n\n")
+		for _, warning := range m.state.Warnings {
+			fmt.Fprintf(sb, "- %s\n", warning)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(m.state.CrashedAttempts) > 0 {
+		writeCrashedAttempts(sb, m.state.CrashedAttempts)
+	}
+
+	if statusText == "INTERRUPTED" {
+		m.writeRunningGroups(sb)
+	}
+
 	// Always use group-based reporting
 	if m.groupManager != nil {
 		// Generate hierarchical summary and results using group data
@@ -379,21 +641,33 @@ func (m *Manager) generateGroupBasedReport(sb *strings.Builder, statusText strin
 		totalTestCases := 0
 		completedTestCases := 0
 		passedTestCases := 0
+		flakyTestCases := 0
 		failedTestCases := 0
 		skippedTestCases := 0
 		runningTestCases := 0
+		noTestGroups := 0
+		failureKinds := make(map[ipc.FailureKind]int)
+		var testCaseTime time.Duration
+		var testCaseDurations []time.Duration
 
 		// Calculate wall-clock duration from start time
-		totalDuration := time.Since(m.startTime).Seconds()
+		totalDuration := m.runEnd().Sub(m.startTime).Seconds()
 
 		for _, group := range rootGroups {
 			// Count all test cases in the group and its subgroups
 			totalTestCases += countTotalTestCases(group)
 			completedTestCases += countCompletedTestCases(group)
 			passedTestCases += countPassedTestCases(group)
+			flakyTestCases += countFlakyTestCases(group)
 			failedTestCases += countFailedTestCases(group)
 			skippedTestCases += countSkippedTestCases(group)
 			runningTestCases += countRunningTestCases(group)
+			testCaseTime += sumTestCaseDurations(group)
+			testCaseDurations = collectTestCaseDurations(group, testCaseDurations)
+			if group.Status == TestStatusNoTests {
+				noTestGroups++
+			}
+			countFailureKinds(group, failureKinds)
 		}
 
 		fmt.Fprintf(sb, "- Total test cases: %d\n", totalTestCases)
@@ -402,23 +676,69 @@ func (m *Manager) generateGroupBasedReport(sb *strings.Builder, statusText strin
 			fmt.Fprintf(sb, "- Test cases running: %d\n", runningTestCases)
 		}
 		fmt.Fprintf(sb, "- Test cases passed: %d\n", passedTestCases)
+		if flakyTestCases > 0 {
+			fmt.Fprintf(sb, "- Test cases flaky: %d (passed on retry)\n", flakyTestCases)
+		}
 		fmt.Fprintf(sb, "- Test cases failed: %d\n", failedTestCases)
+		if failedTestCases > 0 {
+			fmt.Fprintf(sb, "- Failure kinds: %s\n", formatFailureKinds(failureKinds))
+			if limit, omitted := m.groupManager.OmittedFailureDetails(); omitted > 0 {
+				fmt.Fprintf(sb, "- Failure details: kept for the first %d failures, omitted for %d more (--max-failures)\n", limit, omitted)
+			}
+		}
 		fmt.Fprintf(sb, "- Test cases skipped: %d\n", skippedTestCases)
-		fmt.Fprintf(sb, "- Total duration: %.2fs\n\n", totalDuration)
+		// Groups without tests aren't test cases, so they stay out of the counts above
+		if noTestGroups > 0 {
+			fmt.Fprintf(sb, "- Groups with no tests: %d\n", noTestGroups)
+		}
+		fmt.Fprintf(sb, "- Total duration: %.2fs\n", totalDuration)
+		// Summed test case time shows how much the runner's parallelism saved
+		if testCaseTime > 0 {
+			fmt.Fprintf(sb, "- Total CPU time: %.2fs (sum of test case durations)\n", testCaseTime.Seconds())
+			if totalDuration > 0 {
+				fmt.Fprintf(sb, "- Parallelism factor: %.2fx\n", testCaseTime.Seconds()/totalDuration)
+			}
+			// A single test's percentiles would all be its own duration
+			if stats, ok := computeDurationPercentiles(testCaseDurations); ok && stats.count > 1 {
+				fmt.Fprintf(sb, "- Test case durations: p50 %.2fs, p95 %.2fs, p99 %.2fs (%d tests)\n",
+					stats.p50.Seconds(), stats.p95.Seconds(), stats.p99.Seconds(), stats.count)
+			}
+		}
+		sb.WriteString("\n")
 	}
 
 	// Test group results section with table format
-	if len(m.groupManager.GetRootGroups()) > 0 {
+	rootGroups := sortGroups(m.groupManager.GetRootGroups(), m.groupOrder)
+	tableGroups := rootGroups
+	if m.onlyFailures {
+		tableGroups = nil
+		for _, group := range rootGroups {
+			if groupFailed(group) {
+				tableGroups = append(tableGroups, group)
+			}
+		}
+	}
+	if len(rootGroups) > 0 {
 		sb.WriteString("## Test group results\n\n")
+	}
+	if omitted := len(rootGroups) - len(tableGroups); omitted > 0 {
+		fmt.Fprintf(sb, "%d groups without failures omitted (--only-failures).\n\n", omitted)
+	}
+	if len(tableGroups) > 0 {
 		sb.WriteString("| Status | Name | Tests | Duration | Report |\n")
 		sb.WriteString("|--------|------|-------|----------|--------|\n")
 
-		for _, group := range m.groupManager.GetRootGroups() {
+		for _, group := range tableGroups {
 			statusStr := strings.ToUpper(string(group.Status))
 			if statusStr == "" {
 				statusStr = "PENDING"
 			}
-			filename := filepath.Base(group.Name)
+			// File paths are shown by their base name. Other names are shown whole, such as
+			// scoped Jest projects (@app/web) and the commands of a combined run (go test ./...)
+			filename := group.Name
+			if filepath.IsAbs(group.Name) {
+				filename = filepath.Base(group.Name)
+			}
 
 			// Tests column - show breakdown of test results including running tests
 			var testsStr string
@@ -476,7 +796,7 @@ func (m *Manager) generateGroupBasedReport(sb *strings.Builder, statusText strin
 			var durationStr string
 			if statusStr == "RUNNING" && !group.StartTime.IsZero() {
 				// Show elapsed time for running groups
-				elapsed := time.Since(group.StartTime).Seconds()
+				elapsed := m.runEnd().Sub(group.StartTime).Seconds()
 				durationStr = fmt.Sprintf("%.2fs", elapsed)
 			} else if group.Duration > 0 {
 				// Show final duration for completed groups
@@ -499,6 +819,45 @@ func (m *Manager) generateGroupBasedReport(sb *strings.Builder, statusText strin
 			fmt.Fprintf(sb, "| %s | %s | %s | %s | %s |\n", statusStr, filename, testsStr, durationStr, reportFile)
 		}
 	}
+
+	// Packages and files that ran no tests at all, which may be missing tests
+	var noTestGroups []string
+	for _, group := range rootGroups {
+		if group.Status == TestStatusNoTests {
+			noTestGroups = append(noTestGroups, displayGroupName(group.Name))
+		}
+	}
+	if len(noTestGroups) > 0 {
+		sb.WriteString("\n## Groups with no tests\n\n")
+		for _, name := range noTestGroups {
+			fmt.Fprintf(sb, "- %s\n", name)
+		}
+	}
+
+	// Slowest tests across all groups
+	if m.slowThreshold > 0 {
+		if section := formatSlowTestsSection(m.groupManager.collectSlowTests(), m.slowThreshold); section != "" {
+			sb.WriteString("\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Command rerunning just the failed tests, once the run is over
+	if m.runnerDef != nil && statusText == "COMPLETED" {
+		if failures := m.groupManager.collectFailedTests(); len(failures) > 0 {
+			if section := formatReproSection(m.runnerDef.ReproCommand(failures)); section != "" {
+				if !strings.HasSuffix(sb.String(), "\n\n") {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(section)
+			}
+		}
+	}
+}
+
+// groupFailed returns true if the group failed, has failed tests or failed during setup
+func groupFailed(group *TestGroup) bool {
+	return group.HasFailures() || group.Stats.SetupFailed || group.ErrorInfo != nil
 }
 
 // Helper functions to count test cases recursively
@@ -536,6 +895,20 @@ func countPassedTestCases(group *TestGroup) int {
 	return count
 }
 
+// countFlakyTestCases counts passed tests that failed on an earlier attempt
+func countFlakyTestCases(group *TestGroup) int {
+	count := 0
+	for i := range group.TestCases {
+		if group.TestCases[i].Flaky() {
+			count++
+		}
+	}
+	for _, subgroup := range group.Subgroups {
+		count += countFlakyTestCases(subgroup)
+	}
+	return count
+}
+
 func countFailedTestCases(group *TestGroup) int {
 	count := 0
 	for _, test := range group.TestCases {
@@ -575,6 +948,64 @@ func countRunningTestCases(group *TestGroup) int {
 	return count
 }
 
+// sumTestCaseDurations adds up the durations of the test cases in group and its subgroups
+func sumTestCaseDurations(group *TestGroup) time.Duration {
+	var total time.Duration
+	for _, test := range group.TestCases {
+		total += test.Duration
+	}
+	for _, subgroup := range group.Subgroups {
+		total += sumTestCaseDurations(subgroup)
+	}
+	return total
+}
+
+// writeCrashedAttempts lists the attempts re-run with --retry-on-crash, so a crash that a
+// retry papered over still shows in the report
+func writeCrashedAttempts(sb *strings.Builder, attempts []ipc.CrashedAttempt) {
+	sb.WriteString("## Crashed Attempts\n\n")
+	fmt.Fprintf(sb, "The test command crashed before reporting any results and was run again (--retry-on-crash). Results below are from attempt %d.\n\n", attempts[len(attempts)-1].Attempt+1)
+	for _, attempt := range attempts {
+		how := fmt.Sprintf("exited with code %d", attempt.ExitCode)
+		if attempt.Signal != "" {
+			how = "killed by " + attempt.Signal
+		}
+		fmt.Fprintf(sb, "- Attempt %d: %s", attempt.Attempt, how)
+		if attempt.OutputLog != "" {
+			fmt.Fprintf(sb, " (output: `./%s`)", attempt.OutputLog)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
+// writeRunningGroups lists the root groups that were still running when the run was
+// interrupted. Caller must hold m.mu.
+func (m *Manager) writeRunningGroups(sb *strings.Builder) {
+	if m.groupManager == nil {
+		return
+	}
+	var running []*TestGroup
+	for _, group := range m.groupManager.GetRootGroups() {
+		if group.Status == TestStatusRunning {
+			running = append(running, group)
+		}
+	}
+	if len(running) == 0 {
+		return
+	}
+
+	sb.WriteString("Groups still running when the run was interrupted:\n\n")
+	for _, group := range running {
+		if count := countRunningTestCases(group); count > 0 {
+			fmt.Fprintf(sb, "- `%s` (%d tests unfinished)\n", group.Name, count)
+		} else {
+			fmt.Fprintf(sb, "- `%s`\n", group.Name)
+		}
+	}
+	sb.WriteString("\n")
+}
+
 // generateGroupReportSection is kept for potential future use but currently not called
 // It generates a hierarchical report section for a group
 /* func (m *Manager) generateGroupReportSection(sb *strings.Builder, group *TestGroup, indent int) {
@@ -647,6 +1078,14 @@ func (m *Manager) Finalize(exitCode int, errorDetails ...string) error {
 	// Always close resources, even if already finalized
 	// This ensures cleanup happens even on repeated calls
 
+	// Groups still running at the end of the run will never get a result
+	// (e.g. a crashed pytest-xdist worker), so settle them before the final write.
+	// After an interrupt they stay RUNNING so the report shows what was cut off.
+	finalizing := m.state != nil && !m.finished()
+	if finalizing && m.groupManager != nil && !m.interrupted {
+		m.groupManager.FinalizeIncompleteGroups()
+	}
+
 	// Flush all pending group reports
 	if m.groupManager != nil {
 		m.groupManager.Flush()
@@ -661,23 +1100,54 @@ func (m *Manager) Finalize(exitCode int, errorDetails ...string) error {
 	// Close our owned FileLogger if we created one
 
 	// Update final status if we have state and it's not already finalized
-	if m.state != nil && m.state.Status != "COMPLETE" && m.state.Status != "ERROR" {
+	if finalizing {
 		// Cancel any pending timer to prevent race condition
+		m.writeMutex.Lock()
 		if m.writeTimer != nil {
 			m.writeTimer.Stop()
 			m.writeTimer = nil
 		}
+		m.pendingWrite = false
+		m.writeMutex.Unlock()
+
+		// The run ends here, however long its Manager is kept around
+		if m.endTime.IsZero() {
+			m.endTime = time.Now()
+		}
+
+		// output.log is complete now that the command and its readers are done
+		if m.compressOutput && !m.noOutputLog {
+			if err := compressFile(filepath.Join(m.runDir, "output.log")); err != nil {
+				m.logger.Error("Failed to compress output.log: %v", err)
+			} else {
+				m.outputCompressed = true
+			}
+		}
 
 		// Only set ERROR status for actual command errors, not test failures
 		if len(errorDetails) > 0 && errorDetails[0] != "" {
 			m.state.Status = "ERROR"
 			m.state.ErrorDetails = errorDetails[0]
+		} else if m.interrupted {
+			m.state.Status = "INTERRUPTED"
 		} else {
 			m.state.Status = "COMPLETE"
 		}
 
 		// Write final state immediately (bypass debouncing)
-		return m.writeState()
+		if err := m.writeState(); err != nil {
+			return err
+		}
+
+		// JUnit XML is written alongside the markdown report for CI consumption
+		if err := m.writeJUnitReport(); err != nil {
+			m.logger.Error("Failed to write JUnit report: %v", err)
+		}
+
+		// run.json gives tools a stable summary without parsing test-run.md
+		if err := m.writeRunSummary(exitCode); err != nil {
+			m.logger.Error("Failed to write run summary: %v", err)
+		}
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/runner"
@@ -552,3 +553,442 @@ func TestManager_RecursiveTestCountsInTable(t *testing.T) {
 		t.Errorf("Expected summary to show 1 failed test case")
 	}
 }
+
+func TestManager_OnlyFailuresTable(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetOnlyFailures(true)
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	for _, tc := range []struct{ file, status string }{
+		{"green.test.js", "PASS"},
+		{"also_green.test.js", "PASS"},
+		{"red.test.js", "FAIL"},
+	} {
+		_ = manager.HandleEvent(ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload:   ipc.TestCasePayload{TestName: "works", ParentNames: []string{tc.file}, Status: tc.status},
+		})
+	}
+	_ = manager.HandleEvent(ipc.GroupErrorEvent{
+		EventType: "testGroupError",
+		Payload: ipc.GroupErrorPayload{
+			GroupName: "broken.test.js",
+			ErrorType: "SETUP_FAILURE",
+			Error:     &ipc.GroupError{Message: "Cannot find module"},
+		},
+	})
+	_ = manager.Finalize(1)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	report := string(content)
+
+	for _, want := range []string{
+		"- Total test cases: 3",
+		"- Test cases passed: 2",
+		"2 groups without failures omitted (--only-failures).",
+		"| red.test.js |",
+		"| broken.test.js |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "green.test.js") {
+		t.Errorf("Passing groups should be left out of the table:\n%s", report)
+	}
+
+	// Per-group reports are still written for passing groups
+	matches, _ := filepath.Glob(filepath.Join(tempDir, "reports", "green_test_js*", "index.md"))
+	if len(matches) == 0 {
+		t.Error("Expected the report file for green.test.js to be written")
+	}
+}
+
+func TestManager_GroupsWithNoTests(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "go test", "go test ./...")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("go test ./..."); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	_ = manager.HandleEvent(ipc.GroupTestCaseEvent{
+		EventType: "testCase",
+		Payload:   ipc.TestCasePayload{TestName: "TestAdd", ParentNames: []string{"example.com/calc"}, Status: "PASS"},
+	})
+	_ = manager.HandleEvent(ipc.GroupResultEvent{
+		EventType: "testGroupResult",
+		Payload:   ipc.GroupResultPayload{GroupName: "example.com/calc", Status: "PASS"},
+	})
+	for _, pkg := range []string{"example.com/cmd", "example.com/internal/util"} {
+		_ = manager.HandleEvent(ipc.GroupDiscoveredEvent{
+			EventType: "testGroupDiscovered",
+			Payload:   ipc.GroupDiscoveredPayload{GroupName: pkg},
+		})
+		_ = manager.HandleEvent(ipc.GroupResultEvent{
+			EventType: "testGroupResult",
+			Payload:   ipc.GroupResultPayload{GroupName: pkg, Status: "NO_TESTS"},
+		})
+	}
+	_ = manager.Finalize(0)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	report := string(content)
+
+	for _, want := range []string{
+		"- Total test cases: 1\n",
+		"- Test cases skipped: 0\n- Groups with no tests: 2\n",
+		"## Groups with no tests\n\n",
+		"- example.com/internal/util\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "- example.com/calc\n") {
+		t.Errorf("Groups with tests should not be listed as having none:\n%s", report)
+	}
+	if counts := manager.RunCounts(); counts.NoTests != 2 || counts.Total != 1 {
+		t.Errorf("Expected 2 groups with no tests and 1 test case in run.json counts, got %+v", counts)
+	}
+}
+
+func TestManager_FailureKinds(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	for name, testErr := range map[string]*ipc.TestError{
+		"adds":     {Message: "expect(received).toBe(expected)", FailureKind: ipc.FailureKindAssertion},
+		"loads":    {Message: "Timeout of 2000ms exceeded.", Location: "math.test.js:12"},
+		"parses":   {Message: "Cannot read properties of undefined", ErrorType: "TypeError"},
+		"computes": {Message: "expected 2 to equal 3", ErrorType: "AssertionError"},
+		"caches":   {Message: "WARNING: DATA RACE", ErrorType: "DATA RACE", FailureKind: ipc.FailureKindDataRace},
+	} {
+		_ = manager.HandleEvent(ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload:   ipc.TestCasePayload{TestName: name, ParentNames: []string{"math.test.js"}, Status: "FAIL", Error: testErr},
+		})
+	}
+	_ = manager.HandleEvent(ipc.GroupResultEvent{
+		EventType: "testGroupResult",
+		Payload:   ipc.GroupResultPayload{GroupName: "math.test.js", Status: "FAIL"},
+	})
+	_ = manager.Finalize(1)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if want := "- Failure kinds: 2 assertion, 1 exception, 1 timeout, 1 data_race\n"; !strings.Contains(string(content), want) {
+		t.Errorf("Report missing %q:\n%s", want, content)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(tempDir, "reports", "math_test_js*", "index.md"))
+	if len(matches) == 0 {
+		t.Fatal("Expected the report file for math.test.js to be written")
+	}
+	groupReport, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("Failed to read group report: %v", err)
+	}
+	for _, want := range []string{"> *Failure kind: timeout*\n  > *Location: math.test.js:12*\n", "> *Failure kind: exception*"} {
+		if !strings.Contains(string(groupReport), want) {
+			t.Errorf("Group report missing %q:\n%s", want, groupReport)
+		}
+	}
+
+	summary, err := os.ReadFile(filepath.Join(tempDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Failed to read run.json: %v", err)
+	}
+	if !strings.Contains(string(summary), `"failureKind": "assertion"`) {
+		t.Errorf("Expected failed tests in run.json to carry their failure kind:\n%s", summary)
+	}
+}
+
+func TestManager_CollectionErrorGroup(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "pytest", "pytest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("pytest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	var event ipc.CollectionErrorEvent
+	event.EventType = ipc.EventTypeCollectionError
+	event.Payload.FilePath = "test_broken.py"
+	event.Payload.Phase = "collection"
+	event.Payload.Error = "ImportError while importing test module 'test_broken.py'.\n" +
+		"Traceback:\n  test_broken.py:1: in <module>\n    import missing_dep\n" +
+		"E   ModuleNotFoundError: No module named 'missing_dep'"
+	if err := manager.HandleEvent(event); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	_ = manager.Finalize(2)
+
+	group, ok := manager.groupManager.GetGroup(GenerateGroupID("test_broken.py", nil))
+	if !ok {
+		t.Fatal("Expected a group for the file that failed to collect")
+	}
+	if group.Status != TestStatusError {
+		t.Errorf("Expected status ERROR, got %s", group.Status)
+	}
+	if group.ErrorInfo == nil || group.ErrorInfo.Message != "ModuleNotFoundError: No module named 'missing_dep'" {
+		t.Errorf("Expected the exception as the error message, got %+v", group.ErrorInfo)
+	}
+	if group.ErrorInfo != nil && !strings.Contains(group.ErrorInfo.Stack, "import missing_dep") {
+		t.Errorf("Expected the traceback in the error, got %q", group.ErrorInfo.Stack)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(content), "| ERROR | test_broken.py | setup failed |") {
+		t.Errorf("Expected an ERROR row for test_broken.py:\n%s", content)
+	}
+}
+
+func TestManager_FinalizeInterrupted(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// slow.test.js started but had not reported anything when the run was interrupted
+	for _, file := range []string{"done.test.js", "slow.test.js"} {
+		_ = manager.HandleEvent(ipc.GroupStartEvent{
+			EventType: "testGroupStart",
+			Payload:   ipc.GroupStartPayload{GroupName: file},
+		})
+	}
+	_ = manager.HandleEvent(ipc.GroupTestCaseEvent{
+		EventType: "testCase",
+		Payload:   ipc.TestCasePayload{TestName: "works", ParentNames: []string{"done.test.js"}, Status: "PASS"},
+	})
+	_ = manager.HandleEvent(ipc.GroupResultEvent{
+		EventType: "testGroupResult",
+		Payload:   ipc.GroupResultPayload{GroupName: "done.test.js", Status: "PASS"},
+	})
+
+	manager.SetInterrupted("interrupt")
+	if err := manager.Finalize(130); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	report := string(content)
+	for _, want := range []string{
+		"status: INTERRUPTED",
+		"Run was interrupted (interrupt) before the test command finished",
+		"Groups still running when the run was interrupted:\n\n- `slow.test.js`\n",
+		"| RUNNING | slow.test.js |",
+		"| PASS | done.test.js |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+
+	summary, err := os.ReadFile(filepath.Join(tempDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Failed to read run.json: %v", err)
+	}
+	if !strings.Contains(string(summary), `"status": "INTERRUPTED"`) {
+		t.Errorf("Expected run.json status INTERRUPTED, got:\n%s", summary)
+	}
+}
+
+func TestManager_ReportCPUTime(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), nil, &mockLogger{}, "go test", "go test ./...")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer func() { _ = manager.Finalize(0) }()
+	if err := manager.Initialize("go test ./..."); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// 5s of tests in two packages and a subtest group, run in 2s of wall-clock time
+	for _, tc := range []struct {
+		name       string
+		parents    []string
+		durationMs float64
+	}{
+		{"TestA", []string{"pkg/a"}, 1500},
+		{"TestB", []string{"pkg/b"}, 2000},
+		{"sub", []string{"pkg/b", "TestC"}, 1500},
+	} {
+		event := ipc.NewGroupTestCaseEvent(tc.name, tc.parents, "PASS")
+		event.Payload.Duration = tc.durationMs
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+	manager.endTime = time.Now()
+	manager.startTime = manager.endTime.Add(-2 * time.Second)
+
+	report := manager.generateMarkdownReport()
+	for _, want := range []string{
+		"- Total duration: 2.00s\n",
+		"- Total CPU time: 5.00s (sum of test case durations)\n",
+		"- Parallelism factor: 2.50x\n",
+		"- Test case durations: p50 1.50s, p95 2.00s, p99 2.00s (3 tests)\n\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestManager_DisableOutputLog(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "go test", "go test ./...")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.DisableOutputLog(); err != nil {
+		t.Fatalf("DisableOutputLog failed: %v", err)
+	}
+	if err := manager.Initialize("go test ./..."); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := manager.Finalize(0); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "output.log")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output.log, got stat error %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(content), "- Run stdout/stderr: not saved (--no-output-log)") {
+		t.Errorf("Expected the report to say output was not saved:\n%s", content)
+	}
+}
+
+// sendTestCases reports count passing test cases spread over groups of 100, sleeping
+// pause after every 10 of them to simulate a long-running suite
+func sendTestCases(tb testing.TB, manager *Manager, count int, pause time.Duration) {
+	tb.Helper()
+	for i := 0; i < count; i++ {
+		event := ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    fmt.Sprintf("test %d", i),
+				ParentNames: []string{fmt.Sprintf("suite_%d.test.js", i/100)},
+				Status:      "PASS",
+			},
+		}
+		if err := manager.HandleEvent(event); err != nil {
+			tb.Fatalf("HandleEvent failed: %v", err)
+		}
+		if i%10 == 9 {
+			time.Sleep(pause)
+		}
+	}
+}
+
+func TestManager_ThrottlesReportWrites(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.debounceTime = 5 * time.Millisecond
+	manager.writeInterval = 100 * time.Millisecond
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// Events keep arriving faster than the debounce time for about 300ms
+	sendTestCases(t, manager, 1500, 2*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+
+	manager.mu.RLock()
+	writes := manager.reportWrites
+	manager.mu.RUnlock()
+
+	// One initial write, then one per interval while events arrive and a final trailing write
+	if writes < 2 {
+		t.Errorf("Expected the report to be rewritten while events were still arriving, got %d writes", writes)
+	}
+	if writes > 7 {
+		t.Errorf("Expected rewrites to be limited to one per 100ms, got %d writes", writes)
+	}
+
+	if err := manager.Finalize(0); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(manager.runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	if !strings.Contains(string(content), "- Total test cases: 1500") {
+		t.Errorf("Expected the final report to include every test case:\n%s", content)
+	}
+}
+
+// BenchmarkManager_ReportWrites counts test-run.md rewrites for a 5000-test run with
+// and without the rewrite interval. Timings are scaled down from the defaults.
+func BenchmarkManager_ReportWrites(b *testing.B) {
+	for _, bm := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"unthrottled", 0},
+		{"throttled", 25 * time.Millisecond},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			writes := 0
+			for i := 0; i < b.N; i++ {
+				manager, err := NewManager(b.TempDir(), runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest")
+				if err != nil {
+					b.Fatalf("Failed to create manager: %v", err)
+				}
+				manager.debounceTime = time.Millisecond
+				manager.writeInterval = bm.interval
+				if err := manager.Initialize("npx jest"); err != nil {
+					b.Fatalf("Initialize failed: %v", err)
+				}
+
+				sendTestCases(b, manager, 5000, 2*time.Millisecond)
+				if err := manager.Finalize(0); err != nil {
+					b.Fatalf("Finalize failed: %v", err)
+				}
+				writes += manager.reportWrites
+			}
+			b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// statusPrecedence orders group statuses for merging: when runs disagree about a group,
+// the status listed first wins, so a failure in any run is kept
+var statusPrecedence = []TestStatus{
+	TestStatusFail,
+	TestStatusError,
+	TestStatusPass,
+	TestStatusXPass,
+	TestStatusXFail,
+	TestStatusSkip,
+	TestStatusNoTests,
+	TestStatusRunning,
+	TestStatusPending,
+}
+
+// mergeStatus returns whichever of two statuses comes first in statusPrecedence
+func mergeStatus(a, b TestStatus) TestStatus {
+	for _, status := range statusPrecedence {
+		if a == status || b == status {
+			return status
+		}
+	}
+	return a
+}
+
+// Merge combines runs of the same suite, such as the shards of "jest --shard", into a new
+// run directory outDir. Each run's ipc.jsonl is replayed and the resulting groups are merged
+// by group ID (see GroupManager.Merge). The merged run takes its metadata from the first
+// run, spans all of them and gets the first non-zero exit code. output.log concatenates
+// the runs' output.
+func Merge(outDir string, runDirs []string, runners *runner.Manager, lg Logger) (RunSummary, error) {
+	if lg == nil {
+		lg = &noopLogger{}
+	}
+	if len(runDirs) == 0 {
+		return RunSummary{}, fmt.Errorf("no runs to merge")
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return RunSummary{}, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	outputFile, err := os.Create(filepath.Join(outDir, "output.log"))
+	if err != nil {
+		return RunSummary{}, fmt.Errorf("failed to create output.log: %w", err)
+	}
+
+	// Runs are replayed into a scratch directory so their own reports stay untouched
+	scratchDir, err := os.MkdirTemp("", "3pio-merge-")
+	if err != nil {
+		_ = outputFile.Close()
+		return RunSummary{}, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(scratchDir) }()
+
+	var m *Manager
+	var merged replayResult
+	for i, runDir := range runDirs {
+		run, result, err := replayRun(runDir, filepath.Join(scratchDir, fmt.Sprint(i)), runners, lg)
+		if err != nil {
+			_ = outputFile.Close()
+			return RunSummary{}, fmt.Errorf("failed to replay %s: %w", runDir, err)
+		}
+		// Finalizing settles incomplete groups and stops the run's pending writes
+		if err := run.Finalize(result.exitCode, result.errorDetails); err != nil {
+			_ = outputFile.Close()
+			return RunSummary{}, fmt.Errorf("failed to replay %s: %w", runDir, err)
+		}
+
+		if m == nil {
+			m = newManager(outDir, run.outputParser, lg, run.detectedRunner, run.modifiedCommand, outputFile)
+			m.runnerDef = run.runnerDef
+			m.startTime = run.startTime
+			m.endTime = run.endTime
+			m.state = &ipc.TestRunState{
+				Timestamp: run.state.Timestamp,
+				Status:    "RUNNING",
+				UpdatedAt: run.state.UpdatedAt,
+				Arguments: run.state.Arguments,
+				TestFiles: make([]ipc.TestFile, 0),
+			}
+		}
+		if run.startTime.Before(m.startTime) {
+			m.startTime = run.startTime
+		}
+		if run.endTime.After(m.endTime) {
+			m.endTime = run.endTime
+		}
+
+		if merged.exitCode == 0 && result.exitCode != 0 {
+			merged.exitCode = result.exitCode
+			m.state.ExitReason = run.state.ExitReason
+			m.state.Signal = run.state.Signal
+		}
+		if merged.errorDetails == "" && result.errorDetails != "" {
+			merged.errorDetails = fmt.Sprintf("%s: %s", runDir, result.errorDetails)
+		}
+		if run.interrupted && !m.interrupted {
+			m.interrupted = true
+			m.state.AbortReason = fmt.Sprintf("%s: %s", runDir, run.state.AbortReason)
+		}
+
+		if err := appendRunOutput(outputFile, "3pio merged run: "+runDir, runDir); err != nil {
+			lg.Error("Failed to copy output.log of %s: %v", runDir, err)
+		}
+		m.groupManager.Merge(run.groupManager)
+		lg.Debug("Merged %s into %s", runDir, outDir)
+	}
+
+	if err := m.Finalize(merged.exitCode, merged.errorDetails); err != nil {
+		return RunSummary{}, err
+	}
+	return m.buildRunSummary(merged.exitCode, m.endTime), nil
+}
+
+// appendRunOutput appends a run's output.log, or output.log.gz, to out under a "# header" line
+func appendRunOutput(out io.Writer, header, runDir string) error {
+	if _, err := fmt.Fprintf(out, "# %s\n\n", header); err != nil {
+		return err
+	}
+	in, err := openRunOutput(runDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// Merge adds the groups of other, a separate run of the same suite, to gm. Groups are
+// matched by ID. A test case reported by both runs keeps the failing result if either
+// failed, a group's status follows statusPrecedence, and stats are recalculated.
+func (gm *GroupManager) Merge(other *GroupManager) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	for _, group := range other.rootGroups {
+		existing, exists := gm.groups[group.ID]
+		if !exists {
+			gm.rootGroups = append(gm.rootGroups, group)
+			gm.adoptGroup(group)
+			continue
+		}
+		gm.mergeGroup(existing, group)
+		updateStatsKeepingSetupFailures(existing)
+	}
+}
+
+// adoptGroup registers a group from another run and its subgroups. Caller must hold gm.mu.
+func (gm *GroupManager) adoptGroup(group *TestGroup) {
+	gm.groups[group.ID] = group
+	gm.claimReportDir(group)
+	for _, sg := range group.Subgroups {
+		gm.adoptGroup(sg)
+	}
+}
+
+// mergeGroup merges src into dst, the same group in another run. Caller must hold gm.mu.
+func (gm *GroupManager) mergeGroup(dst, src *TestGroup) {
+	for _, tc := range src.TestCases {
+		found := false
+		for i := range dst.TestCases {
+			if dst.TestCases[i].ID == tc.ID {
+				if tc.Status == TestStatusFail {
+					dst.TestCases[i] = tc
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.TestCases = append(dst.TestCases, tc)
+		}
+	}
+
+	for id, sg := range src.Subgroups {
+		if existing, ok := dst.Subgroups[id]; ok {
+			gm.mergeGroup(existing, sg)
+			continue
+		}
+		if dst.Subgroups == nil {
+			dst.Subgroups = make(map[string]*TestGroup)
+		}
+		dst.Subgroups[id] = sg
+		gm.adoptGroup(sg)
+	}
+
+	dst.Status = mergeStatus(dst.Status, src.Status)
+	if dst.ErrorInfo == nil {
+		dst.ErrorInfo = src.ErrorInfo
+	}
+	dst.Stats.SetupFailed = dst.Stats.SetupFailed || src.Stats.SetupFailed
+	dst.Duration += src.Duration
+	if dst.StartTime.IsZero() || (!src.StartTime.IsZero() && src.StartTime.Before(dst.StartTime)) {
+		dst.StartTime = src.StartTime
+	}
+	if src.EndTime.After(dst.EndTime) {
+		dst.EndTime = src.EndTime
+	}
+	dst.Stdout = joinOutput(dst.Stdout, src.Stdout)
+	dst.Stderr = joinOutput(dst.Stderr, src.Stderr)
+}
+
+// joinOutput concatenates the output two runs captured for the same group
+func joinOutput(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	if a[len(a)-1] != '\n' {
+		a += "\n"
+	}
+	return a + b
+}
+
+// updateStatsKeepingSetupFailures recalculates a group's stats with UpdateStats, keeping
+// the setup failure flags that UpdateStats resets
+func updateStatsKeepingSetupFailures(group *TestGroup) {
+	setupFailed := make(map[*TestGroup]bool)
+	var collect func(g *TestGroup)
+	collect = func(g *TestGroup) {
+		if g.Stats.SetupFailed {
+			setupFailed[g] = true
+		}
+		for _, sg := range g.Subgroups {
+			collect(sg)
+		}
+	}
+	collect(group)
+
+	group.UpdateStats()
+	for g := range setupFailed {
+		g.Stats.SetupFailed = true
+	}
+}
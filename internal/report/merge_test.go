@@ -0,0 +1,110 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/runner"
+)
+
+// writeReplayableRun creates a run directory with test-run.md, output.log and the given
+// ipc.jsonl lines, like a run whose console crashed before run.json was written
+func writeReplayableRun(t *testing.T, runDir string, ipcLines []string) {
+	t.Helper()
+	manager, err := NewManager(runDir, runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest --shard")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest --shard"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.mu.Lock()
+	_ = manager.outputFile.Close()
+	manager.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(runDir, "output.log"), []byte("output of "+filepath.Base(runDir)+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write output.log: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "ipc.jsonl"), []byte(strings.Join(ipcLines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ipc.jsonl: %v", err)
+	}
+}
+
+func TestMerge_Shards(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	shard1 := filepath.Join(runsDir, "20250924T120000-shard-1")
+	shard2 := filepath.Join(runsDir, "20250924T120000-shard-2")
+
+	writeReplayableRun(t, shard1, []string{
+		`{"eventType":"testCase","payload":{"testName":"adds items","parentNames":["cart.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"cart.test.js","parentNames":[],"status":"PASS"}}`,
+		`{"eventType":"testCase","payload":{"testName":"formats prices","parentNames":["util.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"util.test.js","parentNames":[],"status":"PASS"}}`,
+	})
+	// The same test failing in another shard wins over its pass
+	writeReplayableRun(t, shard2, []string{
+		`{"eventType":"testCase","payload":{"testName":"adds items","parentNames":["cart.test.js"],"status":"FAIL","error":{"message":"expected 2 items"}}}`,
+		`{"eventType":"testCase","payload":{"testName":"removes items","parentNames":["cart.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"cart.test.js","parentNames":[],"status":"FAIL"}}`,
+		`{"eventType":"testCase","payload":{"testName":"sums","parentNames":["math.test.js"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"math.test.js","parentNames":[],"status":"PASS"}}`,
+	})
+
+	outDir := filepath.Join(runsDir, "20250924T130000-merged")
+	summary, err := Merge(outDir, []string{shard1, shard2}, nil, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	wantCounts := RunSummaryCounts{Total: 4, Passed: 3, Failed: 1, Groups: 3}
+	if summary.Counts != wantCounts {
+		t.Errorf("Expected counts %+v, got %+v", wantCounts, summary.Counts)
+	}
+	if summary.ExitCode != 1 {
+		t.Errorf("Expected exit code 1 from the failing shard, got %d", summary.ExitCode)
+	}
+	if len(summary.FailedTests) != 1 || summary.FailedTests[0].ErrorMessage != "expected 2 items" {
+		t.Errorf("Expected the failing result of adds items, got %+v", summary.FailedTests)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read merged test-run.md: %v", err)
+	}
+	if !strings.Contains(string(content), "- Test cases failed: 1\n") {
+		t.Errorf("Expected merged totals in test-run.md:\n%s", content)
+	}
+
+	output, err := os.ReadFile(filepath.Join(outDir, "output.log"))
+	if err != nil {
+		t.Fatalf("Failed to read merged output.log: %v", err)
+	}
+	for _, expected := range []string{"output of 20250924T120000-shard-1", "output of 20250924T120000-shard-2"} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("Expected %q in merged output.log:\n%s", expected, output)
+		}
+	}
+
+	// The shards' own reports are left alone
+	if _, err := os.Stat(filepath.Join(shard1, "run.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected shard run.json to stay missing, got %v", err)
+	}
+}
+
+func TestMergeStatus(t *testing.T) {
+	tests := []struct {
+		a, b TestStatus
+		want TestStatus
+	}{
+		{TestStatusPass, TestStatusFail, TestStatusFail},
+		{TestStatusSkip, TestStatusPass, TestStatusPass},
+		{TestStatusRunning, TestStatusSkip, TestStatusSkip},
+		{TestStatusError, TestStatusPass, TestStatusError},
+	}
+	for _, tt := range tests {
+		if got := mergeStatus(tt.a, tt.b); got != tt.want {
+			t.Errorf("mergeStatus(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CompressOutputLog gzips output.log into output.log.gz when the run is finalized
+// (--compress-output). output.log stays plain while the command runs, since native
+// runners tail it for their results.
+func (m *Manager) CompressOutputLog() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compressOutput = true
+}
+
+// compressFile replaces path with a gzipped copy at path+".gz". A missing file is left
+// alone. On error the original is kept and a partial copy removed.
+func compressFile(path string) (err error) {
+	in, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	gzPath := path + ".gz"
+	out, err := os.Create(gzPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(gzPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	_ = in.Close()
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// openRunOutput opens the output.log of a run, reading through output.log.gz for runs
+// made with --compress-output. It returns os.ErrNotExist when the run has neither.
+func openRunOutput(runDir string) (io.ReadCloser, error) {
+	path := filepath.Join(runDir, "output.log")
+	if f, err := os.Open(path); !os.IsNotExist(err) {
+		return f, err
+	}
+	f, err := os.Open(path + ".gz")
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to read %s.gz: %w", path, err)
+	}
+	return &gzipFile{Reader: gz, file: f}, nil
+}
+
+// gzipFile closes the file under a gzip reader along with the reader
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	err := g.Reader.Close()
+	if closeErr := g.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressFile(t *testing.T) {
+	runDir := t.TempDir()
+	path := filepath.Join(runDir, "output.log")
+	if err := os.WriteFile(path, []byte("=== RUN   TestAdd\n--- PASS: TestAdd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressFile(path); err != nil {
+		t.Fatalf("compressFile failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected output.log to be replaced, got %v", err)
+	}
+
+	// Readers of a run's output see the same content through the gzip file
+	in, err := openRunOutput(runDir)
+	if err != nil {
+		t.Fatalf("openRunOutput failed: %v", err)
+	}
+	data, err := io.ReadAll(in)
+	_ = in.Close()
+	if err != nil || string(data) != "=== RUN   TestAdd\n--- PASS: TestAdd\n" {
+		t.Errorf("Expected the original output, got %q (%v)", data, err)
+	}
+
+	// Runs without output (--no-output-log) have nothing to compress or read
+	empty := t.TempDir()
+	if err := compressFile(filepath.Join(empty, "output.log")); err != nil {
+		t.Errorf("Expected a missing file to be left alone, got %v", err)
+	}
+	if _, err := openRunOutput(empty); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error without output, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncatedOutput tracks a group's stdout or stderr once it has grown past the
+// --max-group-output limit. Only the start and the end of the stream are kept.
+type truncatedOutput struct {
+	head      string // First half of the limit, fixed once truncation starts
+	tail      string // Most recent output, at most the other half of the limit
+	truncated int    // Bytes dropped between head and tail
+}
+
+// SetMaxGroupOutput caps the stdout and stderr kept for each group, in bytes. Past the
+// cap, the first and last half are kept around a truncation marker. Zero means no cap.
+func (gm *GroupManager) SetMaxGroupOutput(limit int) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.maxGroupOutput = limit
+}
+
+// appendOutput adds chunk to a group's output, truncating the middle of the output
+// when it exceeds the limit. Caller must hold gm.mu.
+func (gm *GroupManager) appendOutput(current string, state **truncatedOutput, chunk string) string {
+	limit := gm.maxGroupOutput
+	if limit <= 0 {
+		return current + chunk
+	}
+
+	t := *state
+	if t == nil {
+		if len(current)+len(chunk) <= limit {
+			return current + chunk
+		}
+		full := current + chunk
+		headEnd := runeStartBefore(full, limit/2)
+		tailStart := runeStartAfter(full, len(full)-(limit-limit/2))
+		t = &truncatedOutput{
+			head:      full[:headEnd],
+			tail:      full[tailStart:],
+			truncated: tailStart - headEnd,
+		}
+		*state = t
+	} else {
+		tail := t.tail + chunk
+		if keep := limit - limit/2; len(tail) > keep {
+			tailStart := runeStartAfter(tail, len(tail)-keep)
+			t.truncated += tailStart
+			tail = tail[tailStart:]
+		}
+		t.tail = tail
+	}
+
+	return t.head + fmt.Sprintf("\n[... truncated %d bytes ...]\n", t.truncated) + t.tail
+}
+
+// runeStartBefore returns the largest index <= i that starts a UTF-8 sequence
+func runeStartBefore(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// runeStartAfter returns the smallest index >= i that starts a UTF-8 sequence
+func runeStartAfter(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
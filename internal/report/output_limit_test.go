@@ -0,0 +1,59 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestGroupManager_MaxGroupOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+	gm.SetMaxGroupOutput(20)
+
+	if err := gm.ProcessTestCase(ipc.NewGroupTestCaseEvent("logs a lot", []string{"chatty.test.js"}, "PASS")); err != nil {
+		t.Fatalf("Failed to process test case: %v", err)
+	}
+	group, _ := gm.GetGroup(GenerateGroupID(gm.normalizeToAbsolutePath("chatty.test.js"), nil))
+	if group == nil {
+		t.Fatal("Expected chatty.test.js group")
+	}
+
+	// Output within the limit is kept as is
+	_ = gm.ProcessStdoutChunk("chatty.test.js", nil, "0123456789")
+	if group.Stdout != "0123456789" {
+		t.Errorf("Expected untruncated output, got %q", group.Stdout)
+	}
+
+	// Past the limit the first and last 10 bytes are kept
+	_ = gm.ProcessStdoutChunk("chatty.test.js", nil, "abcdefghijklmnop")
+	if want := "0123456789\n[... truncated 6 bytes ...]\nghijklmnop"; group.Stdout != want {
+		t.Errorf("Expected %q, got %q", want, group.Stdout)
+	}
+
+	// Later chunks move the tail and add to the truncated count
+	_ = gm.ProcessStdoutChunk("chatty.test.js", nil, "XYZ")
+	if want := "0123456789\n[... truncated 9 bytes ...]\njklmnopXYZ"; group.Stdout != want {
+		t.Errorf("Expected %q, got %q", want, group.Stdout)
+	}
+
+	// stderr is capped separately
+	_ = gm.ProcessStderrChunk("chatty.test.js", nil, "short")
+	if group.Stderr != "short" {
+		t.Errorf("Expected untruncated stderr, got %q", group.Stderr)
+	}
+}
+
+func TestGroupManager_MaxGroupOutputKeepsRunes(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", nil)
+	gm.SetMaxGroupOutput(5)
+
+	var state *truncatedOutput
+	got := gm.appendOutput("", &state, "ééééé")
+	if want := "é\n[... truncated 6 bytes ...]\né"; got != want {
+		t.Errorf("Expected cuts on rune boundaries %q, got %q", want, got)
+	}
+}
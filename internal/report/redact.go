@@ -0,0 +1,84 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// pathRedactor hides where a run happened in rendered reports (--redact-paths): the
+// project root is replaced with <root> and the home directory with ~. Group IDs and
+// other stored paths stay absolute; only report text goes through it.
+type pathRedactor struct {
+	replacements []pathReplacement // Root variants first, so paths in the root never become ~/...
+}
+
+// pathReplacement matches a directory up to the character ending it, which with keeps
+type pathReplacement struct {
+	prefix *regexp.Regexp
+	with   string
+}
+
+// newPathRedactor returns a redactor for the current directory and the user's home
+// directory. Symlinked directories are matched both as given and resolved, since test
+// runners report either.
+func newPathRedactor() *pathRedactor {
+	r := &pathRedactor{}
+	if root, err := os.Getwd(); err == nil {
+		r.add(root, "<root>")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		r.add(home, "~")
+	}
+	return r
+}
+
+// add replaces dir, and its resolved form when it differs, with placeholder. Only whole
+// path components match: /home/me/app does not match inside /home/me/app2.
+func (r *pathRedactor) add(dir, placeholder string) {
+	dirs := []string{filepath.Clean(dir)}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil && resolved != dirs[0] {
+		dirs = append(dirs, resolved)
+	}
+	for _, d := range dirs {
+		if d == "" || d == string(filepath.Separator) || d == "." {
+			continue
+		}
+		pattern := regexp.QuoteMeta(d) + `($|[/\\\s"'` + "`" + `:)\]])`
+		r.replacements = append(r.replacements, pathReplacement{prefix: regexp.MustCompile(pattern), with: placeholder + "${1}"})
+	}
+}
+
+// redact returns text with the project root and home directory replaced
+func (r *pathRedactor) redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rep := range r.replacements {
+		text = rep.prefix.ReplaceAllString(text, rep.with)
+	}
+	return text
+}
+
+// SetRedactPaths makes group reports show the project root as <root> and the home
+// directory as ~ instead of their absolute paths
+func (gm *GroupManager) SetRedactPaths(redact bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.redactor = nil
+	if redact {
+		gm.redactor = newPathRedactor()
+	}
+}
+
+// SetRedactPaths makes test-run.md and group reports show the project root as <root>
+// and the home directory as ~. run.json, the JUnit report and the logs keep full paths.
+func (m *Manager) SetRedactPaths(redact bool) {
+	m.mu.Lock()
+	m.redactor = nil
+	if redact {
+		m.redactor = newPathRedactor()
+	}
+	m.mu.Unlock()
+	m.groupManager.SetRedactPaths(redact)
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestPathRedactor_Redact(t *testing.T) {
+	r := &pathRedactor{}
+	r.add("/home/me/app", "<root>")
+	r.add("/home/me", "~")
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"root file", "/home/me/app/src/a.test.js", "<root>/src/a.test.js"},
+		{"root itself", "cd /home/me/app", "cd <root>"},
+		{"root with location", "at /home/me/app:12", "at <root>:12"},
+		{"home outside root", "/home/me/.cache/x.js", "~/.cache/x.js"},
+		{"sibling of root", "/home/me/app2/a.js", "~/app2/a.js"},
+		{"longer name", "/home/meg/a.js", "/home/meg/a.js"},
+		{"quoted and bracketed", "'/home/me/app' [/home/me/app]", "'<root>' [<root>]"},
+		{"several", "/home/me/app/a.js and /home/me/b.js", "<root>/a.js and ~/b.js"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.redact(tt.text); got != tt.want {
+				t.Errorf("redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	var none *pathRedactor
+	if got := none.redact("/home/me/app"); got != "/home/me/app" {
+		t.Errorf("nil redactor changed text to %q", got)
+	}
+}
+
+func TestManager_RedactPaths(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	manager, err := NewManager(tempDir, nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetRedactPaths(true)
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	file := filepath.Join(cwd, "red.test.js")
+	_ = manager.HandleEvent(ipc.GroupTestCaseEvent{
+		EventType: "testCase",
+		Payload: ipc.TestCasePayload{
+			TestName:    "works",
+			ParentNames: []string{file},
+			Status:      "FAIL",
+			Error:       &ipc.TestError{Message: "expected true at " + file + ":3"},
+		},
+	})
+	_ = manager.Finalize(1)
+
+	var reports []string
+	_ = filepath.WalkDir(tempDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".md" {
+			reports = append(reports, path)
+		}
+		return nil
+	})
+	if len(reports) < 2 {
+		t.Fatalf("Expected test-run.md and a group report, got %v", reports)
+	}
+	for _, path := range reports {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		report := string(content)
+		if strings.Contains(report, cwd+string(filepath.Separator)) {
+			t.Errorf("%s still contains the project root %s:\n%s", path, cwd, report)
+		}
+		if filepath.Base(path) == "index.md" && !strings.Contains(report, "<root>/red.test.js") {
+			t.Errorf("%s does not show the redacted group path:\n%s", path, report)
+		}
+	}
+}
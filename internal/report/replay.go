@@ -0,0 +1,179 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// runMetadata is what a run directory records about the run besides its IPC events
+type runMetadata struct {
+	detectedRunner  string
+	modifiedCommand string
+	runnerVersion   string
+	arguments       string
+	created         time.Time
+	summary         *RunSummary // nil when the run was never finalized
+}
+
+// readRunMetadata reads the frontmatter and test command from a run's test-run.md,
+// and run.json if the run got as far as writing it
+func readRunMetadata(runDir string) (runMetadata, error) {
+	var meta runMetadata
+
+	file, err := os.Open(filepath.Join(runDir, "test-run.md"))
+	if err != nil {
+		return meta, fmt.Errorf("failed to read run metadata: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			switch key {
+			case "detected_runner":
+				meta.detectedRunner = value
+			case "runner_version":
+				meta.runnerVersion = value
+			case "modified_command":
+				meta.modifiedCommand = strings.Trim(value, "`")
+			case "created":
+				if created, err := time.Parse("2006-01-02T15:04:05.000Z", value); err == nil {
+					meta.created = created
+				}
+			case "- Test command":
+				meta.arguments = strings.Trim(value, "`")
+			}
+		}
+		// Nothing needed comes after the header
+		if strings.HasPrefix(line, "## ") {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return meta, fmt.Errorf("failed to read run metadata: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err == nil {
+		var summary RunSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return meta, fmt.Errorf("failed to parse run.json: %w", err)
+		}
+		meta.summary = &summary
+	} else if !os.IsNotExist(err) {
+		return meta, fmt.Errorf("failed to read run.json: %w", err)
+	}
+
+	return meta, nil
+}
+
+// Regenerate rebuilds test-run.md, the group reports, test-run.xml and run.json of an
+// existing run by replaying its ipc.jsonl through a fresh Manager, without running any
+// tests. Run metadata comes from the existing test-run.md, and the exit code, status and
+// end time from run.json when the run was finalized. Otherwise the run is finalized as
+// if it had ended when ipc.jsonl was last written. output.log is left untouched.
+// runners finds the rerun command builder for the original command and may be nil.
+func Regenerate(runDir string, runners *runner.Manager, lg Logger) (RunSummary, error) {
+	m, result, err := replayRun(runDir, runDir, runners, lg)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	if err := m.Finalize(result.exitCode, result.errorDetails); err != nil {
+		return RunSummary{}, err
+	}
+	return m.buildRunSummary(result.exitCode, m.endTime), nil
+}
+
+// replayResult is how a replayed run ended, to pass to Finalize
+type replayResult struct {
+	exitCode     int
+	errorDetails string
+}
+
+// replayRun replays the ipc.jsonl of runDir through a fresh Manager whose reports go to
+// reportDir. The Manager is left unfinalized.
+func replayRun(runDir, reportDir string, runners *runner.Manager, lg Logger) (*Manager, replayResult, error) {
+	var result replayResult
+	if lg == nil {
+		lg = &noopLogger{}
+	}
+
+	ipcPath := filepath.Join(runDir, "ipc.jsonl")
+	ipcInfo, err := os.Stat(ipcPath)
+	if err != nil {
+		return nil, result, fmt.Errorf("no IPC log to replay: %w", err)
+	}
+
+	meta, err := readRunMetadata(runDir)
+	if err != nil {
+		return nil, result, err
+	}
+
+	var parser runner.OutputParser
+	if runners != nil {
+		parser = runners.GetParser(meta.detectedRunner)
+	}
+	m := newManager(reportDir, parser, lg, meta.detectedRunner, meta.modifiedCommand, nil)
+	m.runnerVersion = meta.runnerVersion
+	if runners != nil {
+		if def, err := runners.Detect(strings.Fields(meta.arguments)); err == nil {
+			m.SetRunnerDefinition(def)
+		}
+	}
+
+	m.startTime = meta.created
+	m.endTime = ipcInfo.ModTime()
+	m.state = &ipc.TestRunState{
+		Timestamp: meta.created,
+		Status:    "RUNNING",
+		UpdatedAt: meta.created,
+		Arguments: meta.arguments,
+		TestFiles: make([]ipc.TestFile, 0),
+	}
+
+	if meta.summary != nil {
+		m.startTime = meta.summary.StartTime
+		m.endTime = meta.summary.EndTime
+		m.state.AbortReason = meta.summary.AbortReason
+		m.state.ExitReason = meta.summary.ExitReason
+		m.state.Signal = meta.summary.Signal
+		if meta.summary.RunnerExitCode != nil {
+			m.state.RunnerExitCode = meta.summary.RunnerExitCode
+			m.state.ExitCode = meta.summary.ExitCode
+		}
+		m.state.CrashedAttempts = meta.summary.CrashedAttempts
+		m.state.Warnings = meta.summary.Warnings
+		result.exitCode = meta.summary.ExitCode
+		switch meta.summary.Status {
+		case "ERROR":
+			result.errorDetails = meta.summary.ErrorDetails
+		case "INTERRUPTED":
+			m.interrupted = true
+		}
+	}
+
+	lg.Debug("Replaying %s", ipcPath)
+	if err := ipc.ReplayFile(ipcPath, lg, m.HandleEvent); err != nil {
+		return nil, result, fmt.Errorf("failed to replay IPC log: %w", err)
+	}
+
+	// Without run.json the exit code is lost, so derive it from the results
+	if meta.summary == nil {
+		summary := m.buildRunSummary(0, m.endTime)
+		if summary.Counts.Failed > 0 || len(summary.FailedGroups) > 0 {
+			result.exitCode = 1
+		}
+	}
+
+	return m, result, nil
+}
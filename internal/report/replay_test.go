@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestRegenerate_UnfinishedRun(t *testing.T) {
+	runDir := filepath.Join(t.TempDir(), "runs", "20250924T120000-test-run")
+
+	// A run whose console crashed: test-run.md only has the initial state and there is no run.json
+	manager, err := NewManager(runDir, runner.NewPytestOutputParser(), &mockLogger{}, "pytest", "pytest -p adapter")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("pytest tests"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.mu.Lock()
+	_ = manager.outputFile.Close()
+	manager.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(runDir, "output.log"), []byte("captured output\n"), 0644); err != nil {
+		t.Fatalf("Failed to write output.log: %v", err)
+	}
+
+	ipcLines := []string{
+		`{"eventType":"testGroupStart","payload":{"groupName":"tests/test_cart.py","parentNames":[]}}`,
+		`{"eventType":"testCase","payload":{"testName":"test_total","parentNames":["tests/test_cart.py"],"status":"FAIL","error":{"message":"assert 3 == 4"}}}`,
+		`not json`,
+		`{"eventType":"testCase","payload":{"testName":"test_empty","parentNames":["tests/test_cart.py"],"status":"PASS"}}`,
+		`{"eventType":"testGroupResult","payload":{"groupName":"tests/test_cart.py","parentNames":[],"status":"FAIL"}}`,
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "ipc.jsonl"), []byte(strings.Join(ipcLines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ipc.jsonl: %v", err)
+	}
+
+	summary, err := Regenerate(runDir, nil, &mockLogger{})
+	if err != nil {
+		t.Fatalf("Regenerate failed: %v", err)
+	}
+	if summary.Counts.Passed != 1 || summary.Counts.Failed != 1 {
+		t.Errorf("Expected 1 passed and 1 failed, got %+v", summary.Counts)
+	}
+	if summary.ExitCode != 1 {
+		t.Errorf("Expected exit code 1 derived from the failure, got %d", summary.ExitCode)
+	}
+
+	content, err := os.ReadFile(filepath.Join(runDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	for _, expected := range []string{
+		"detected_runner: pytest\n",
+		"modified_command: `pytest -p adapter`\n",
+		"status: COMPLETED\n",
+		"- Test command: `pytest tests`\n",
+		"- Test cases failed: 1\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected %q in regenerated report:\n%s", expected, content)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+	var written RunSummary
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Failed to parse run.json: %v", err)
+	}
+	if written.Status != "COMPLETE" || written.ExitCode != 1 {
+		t.Errorf("Expected COMPLETE with exit code 1 in run.json, got %s with %d", written.Status, written.ExitCode)
+	}
+
+	// output.log belongs to the original run and must survive
+	output, err := os.ReadFile(filepath.Join(runDir, "output.log"))
+	if err != nil || string(output) != "captured output\n" {
+		t.Errorf("Expected output.log to be left untouched, got %q (%v)", output, err)
+	}
+}
+
+func TestRegenerate_KeepsFinalizedRunMetadata(t *testing.T) {
+	runDir := t.TempDir()
+
+	manager, err := NewManager(runDir, runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.SetAbortReason("Stopped after the first failing group (--fail-fast)")
+	manager.SetExitReason("tests_failed")
+	manager.SetExitSignal("SIGKILL")
+	manager.SetCrashedAttempts([]ipc.CrashedAttempt{{Attempt: 1, ExitCode: 139, Signal: "SIGSEGV"}})
+	if err := manager.HandleEvent(ipc.GroupTestCaseEvent{
+		EventType: "testCase",
+		Payload:   ipc.TestCasePayload{TestName: "adds", ParentNames: []string{"math.test.js"}, Status: "PASS"},
+	}); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := manager.Finalize(3); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	// The test case above was never written to the IPC log, so the replay has no results
+	if err := os.WriteFile(filepath.Join(runDir, "ipc.jsonl"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write ipc.jsonl: %v", err)
+	}
+
+	summary, err := Regenerate(runDir, nil, nil)
+	if err != nil {
+		t.Fatalf("Regenerate failed: %v", err)
+	}
+	if summary.ExitCode != 3 {
+		t.Errorf("Expected the exit code from run.json, got %d", summary.ExitCode)
+	}
+	if summary.AbortReason == "" {
+		t.Error("Expected the abort reason from run.json to be kept")
+	}
+	if summary.ExitReason != "tests_failed" {
+		t.Errorf("Expected the exit reason from run.json to be kept, got %q", summary.ExitReason)
+	}
+	if summary.Signal != "SIGKILL" {
+		t.Errorf("Expected the signal from run.json to be kept, got %q", summary.Signal)
+	}
+	if len(summary.CrashedAttempts) != 1 || summary.CrashedAttempts[0].Signal != "SIGSEGV" {
+		t.Errorf("Expected the crashed attempts from run.json to be kept, got %+v", summary.CrashedAttempts)
+	}
+	if content, _ := os.ReadFile(filepath.Join(runDir, "test-run.md")); !strings.Contains(string(content), "\nexit_reason: tests_failed\nsignal: SIGKILL\n") {
+		t.Errorf("Expected exit_reason and signal in the frontmatter, got:\n%s", content)
+	}
+	if summary.Counts.Total != 0 {
+		t.Errorf("Expected results to come only from the IPC log, got %+v", summary.Counts)
+	}
+}
+
+func TestRegenerate_MissingIPCLog(t *testing.T) {
+	if _, err := Regenerate(t.TempDir(), nil, nil); err == nil || !strings.Contains(err.Error(), "no IPC log") {
+		t.Errorf("Expected a missing IPC log error, got %v", err)
+	}
+}
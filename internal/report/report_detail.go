@@ -0,0 +1,37 @@
+package report
+
+import "fmt"
+
+// ReportDetail picks how much of each test case group reports show (--report-detail)
+type ReportDetail string
+
+const (
+	ReportDetailMinimal  ReportDetail = "minimal"  // Only tests that didn't pass, with their errors
+	ReportDetailStandard ReportDetail = "standard" // Every test, with errors and the output of passing tests (default)
+	ReportDetailFull     ReportDetail = "full"     // Every test, with its stdout and stderr
+)
+
+// ParseReportDetail validates a --report-detail value. An empty value means standard.
+func ParseReportDetail(value string) (ReportDetail, error) {
+	switch detail := ReportDetail(value); detail {
+	case "":
+		return ReportDetailStandard, nil
+	case ReportDetailMinimal, ReportDetailStandard, ReportDetailFull:
+		return detail, nil
+	default:
+		return "", fmt.Errorf("report detail must be minimal, standard or full, got %q", value)
+	}
+}
+
+// SetReportDetail sets how much of each test case group reports show
+func (gm *GroupManager) SetReportDetail(detail ReportDetail) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.reportDetail = detail
+}
+
+// showTestCase reports whether a group report at the given detail lists tc. Minimal
+// reports leave out passing tests, except flaky ones.
+func showTestCase(tc TestCase, detail ReportDetail) bool {
+	return detail != ReportDetailMinimal || tc.Status != TestStatusPass || tc.Flaky()
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner"
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// SetRunnerDefinition sets the runner used to build the "Rerun failures" command in test-run.md
+func (m *Manager) SetRunnerDefinition(def runner.Definition) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runnerDef = def
+}
+
+// collectFailedTests returns the failed test cases across all groups in hierarchy order,
+// with file paths made relative for use on a command line. The "./" prefix is dropped so
+// Go import paths, which are stored as absolute paths, turn back into import paths.
+func (gm *GroupManager) collectFailedTests() []definitions.FailedTest {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	var failures []definitions.FailedTest
+	var visit func(group *TestGroup)
+	visit = func(group *TestGroup) {
+		var parentNames []string
+		for _, name := range group.GetFullPath() {
+			parentNames = append(parentNames, strings.TrimPrefix(gm.makeRelativePath(name), "./"))
+		}
+		for _, tc := range group.TestCases {
+			if tc.Status == TestStatusFail {
+				failures = append(failures, definitions.FailedTest{Name: tc.Name, ParentNames: parentNames})
+			}
+		}
+
+		// Visit subgroups in name order so the command is stable between runs
+		subgroups := make([]*TestGroup, 0, len(group.Subgroups))
+		for _, sg := range group.Subgroups {
+			subgroups = append(subgroups, sg)
+		}
+		sort.Slice(subgroups, func(i, j int) bool {
+			return subgroups[i].Name < subgroups[j].Name
+		})
+		for _, sg := range subgroups {
+			visit(sg)
+		}
+	}
+	for _, group := range gm.rootGroups {
+		visit(group)
+	}
+	return failures
+}
+
+// FailedTestNames returns the failed test cases of the run, sorted, each as its relative
+// parent path and name joined with " > ", e.g. "src/math.test.js > add > adds numbers"
+func (m *Manager) FailedTestNames() []string {
+	failures := m.groupManager.collectFailedTests()
+	names := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		name := failure.Name
+		if len(failure.ParentNames) > 0 {
+			name = strings.Join(failure.ParentNames, " > ") + " > " + name
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatReproSection renders the "Rerun failures" section for test-run.md.
+// Returns an empty string when the runner cannot build a command.
+func formatReproSection(command string) string {
+	if command == "" {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("## Rerun failures\n\n")
+	sb.WriteString("```sh\n")
+	sb.WriteString(command)
+	sb.WriteString("\n```\n\n")
+	return sb.String()
+}
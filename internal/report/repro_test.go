@@ -0,0 +1,90 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestManager_RerunFailuresSection(t *testing.T) {
+	tempDir := t.TempDir()
+
+	manager, err := NewManager(tempDir, runner.NewPytestOutputParser(), &mockLogger{}, "pytest", "pytest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetRunnerDefinition(runner.NewPytestDefinition())
+	if err := manager.Initialize("pytest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	events := []ipc.Event{
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "test_total",
+				ParentNames: []string{"tests/test_cart.py", "TestCart"},
+				Status:      "FAIL",
+				Error:       &ipc.TestError{Message: "assert 3 == 4"},
+			},
+		},
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "test_empty",
+				ParentNames: []string{"tests/test_cart.py", "TestCart"},
+				Status:      "PASS",
+			},
+		},
+	}
+	for _, event := range events {
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+
+	if err := manager.Finalize(1); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "test-run.md"))
+	if err != nil {
+		t.Fatalf("Failed to read test-run.md: %v", err)
+	}
+	expected := "## Rerun failures\n\n```sh\npytest tests/test_cart.py::TestCart::test_total\n```\n"
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("Expected rerun section %q in report:\n%s", expected, content)
+	}
+}
+
+func TestManager_FailedTestNames(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), nil, &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	for _, payload := range []ipc.TestCasePayload{
+		{TestName: "subtracts", ParentNames: []string{"src/math.test.js", "math"}, Status: "FAIL"},
+		{TestName: "adds", ParentNames: []string{"src/math.test.js", "math"}, Status: "FAIL"},
+		{TestName: "multiplies", ParentNames: []string{"src/math.test.js", "math"}, Status: "PASS"},
+		{TestName: "renders", ParentNames: []string{"src/app.test.js"}, Status: "FAIL"},
+	} {
+		_ = manager.HandleEvent(ipc.GroupTestCaseEvent{EventType: "testCase", Payload: payload})
+	}
+
+	want := []string{
+		"src/app.test.js > renders",
+		"src/math.test.js > math > adds",
+		"src/math.test.js > math > subtracts",
+	}
+	got := manager.FailedTestNames()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("FailedTestNames() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// RunSummarySchemaVersion is the version of the run.json schema.
+// Bump it whenever a field is removed or its meaning changes; adding fields is backwards compatible.
+const RunSummarySchemaVersion = 1
+
+// RunSummary is the machine-readable summary written to run.json on finalize
+type RunSummary struct {
+	SchemaVersion   int                  `json:"schemaVersion"`
+	RunID           string               `json:"runId"`
+	DetectedRunner  string               `json:"detectedRunner"`
+	RunnerVersion   string               `json:"runnerVersion,omitempty"`
+	ModifiedCommand string               `json:"modifiedCommand"`
+	Status          string               `json:"status"`
+	ExitCode        int                  `json:"exitCode"`
+	RunnerExitCode  *int                 `json:"runnerExitCode,omitempty"`
+	ErrorDetails    string               `json:"errorDetails,omitempty"`
+	AbortReason     string               `json:"abortReason,omitempty"`
+	ExitReason      string               `json:"exitReason,omitempty"`
+	Signal          string               `json:"signal,omitempty"`
+	CrashedAttempts []ipc.CrashedAttempt `json:"crashedAttempts,omitempty"`
+	Warnings        []string             `json:"warnings,omitempty"`
+	StartTime       time.Time            `json:"startTime"`
+	EndTime         time.Time            `json:"endTime"`
+	DurationMs      int64                `json:"durationMs"`
+	Counts          RunSummaryCounts     `json:"counts"`
+	FailedTests     []FailedTest         `json:"failedTests"`
+	FailedGroups    []FailedGroup        `json:"failedGroups"`
+}
+
+// RunSummaryCounts holds recursive test case counts across all root groups
+type RunSummaryCounts struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Flaky   int `json:"flaky,omitempty"` // Passed on a retry, also counted in Passed
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Groups  int `json:"groups"`
+	NoTests int `json:"noTests,omitempty"` // Root groups that had no tests, e.g. Go packages with [no test files]
+}
+
+// FailedTest identifies a failed test case by name and parent hierarchy
+type FailedTest struct {
+	ID           string          `json:"id"`        // Storage ID, from the full hierarchical path
+	StableKey    string          `json:"stableKey"` // Ignores file paths, so it survives code moves
+	Name         string          `json:"name"`
+	ParentNames  []string        `json:"parentNames"`
+	ErrorMessage string          `json:"errorMessage,omitempty"`
+	FailureKind  ipc.FailureKind `json:"failureKind,omitempty"`
+	Report       string          `json:"report"`
+}
+
+// FailedGroup identifies a group that failed before its tests could run (e.g. setup failures)
+type FailedGroup struct {
+	Name         string   `json:"name"`
+	ParentNames  []string `json:"parentNames"`
+	ErrorMessage string   `json:"errorMessage,omitempty"`
+	Report       string   `json:"report"`
+}
+
+// writeRunSummary writes run.json to the run directory
+func (m *Manager) writeRunSummary(exitCode int) error {
+	summary := m.buildRunSummary(exitCode, m.runEnd())
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run summary: %w", err)
+	}
+
+	summaryPath := filepath.Join(m.runDir, "run.json")
+	return os.WriteFile(summaryPath, append(data, '\n'), 0644)
+}
+
+// RunCounts returns the run's test case counts, the same ones written to run.json
+func (m *Manager) RunCounts() RunSummaryCounts {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.buildRunSummary(0, m.runEnd()).Counts
+}
+
+// buildRunSummary collects run metadata, counts and failures from the group manager
+func (m *Manager) buildRunSummary(exitCode int, endTime time.Time) RunSummary {
+	summary := RunSummary{
+		SchemaVersion:   RunSummarySchemaVersion,
+		RunID:           filepath.Base(m.runDir),
+		DetectedRunner:  m.detectedRunner,
+		RunnerVersion:   m.runnerVersion,
+		ModifiedCommand: m.modifiedCommand,
+		ExitCode:        exitCode,
+		StartTime:       m.startTime.UTC(),
+		EndTime:         endTime.UTC(),
+		DurationMs:      endTime.Sub(m.startTime).Milliseconds(),
+		FailedTests:     []FailedTest{},
+		FailedGroups:    []FailedGroup{},
+	}
+
+	if m.state != nil {
+		summary.Status = m.state.Status
+		summary.ErrorDetails = m.state.ErrorDetails
+		summary.AbortReason = m.state.AbortReason
+		summary.ExitReason = m.state.ExitReason
+		summary.Signal = m.state.Signal
+		summary.RunnerExitCode = m.state.RunnerExitCode
+		summary.CrashedAttempts = m.state.CrashedAttempts
+		summary.Warnings = m.state.Warnings
+	}
+
+	if m.groupManager == nil {
+		return summary
+	}
+
+	for _, group := range m.groupManager.GetRootGroups() {
+		summary.Counts.Total += countTotalTestCases(group)
+		summary.Counts.Passed += countPassedTestCases(group)
+		summary.Counts.Flaky += countFlakyTestCases(group)
+		summary.Counts.Failed += countFailedTestCases(group)
+		summary.Counts.Skipped += countSkippedTestCases(group)
+		if group.Status == TestStatusNoTests {
+			summary.Counts.NoTests++
+		}
+		m.collectFailures(&summary, group)
+	}
+
+	return summary
+}
+
+// collectFailures appends failed test cases and setup-failed groups, recursing into subgroups
+func (m *Manager) collectFailures(summary *RunSummary, group *TestGroup) {
+	summary.Counts.Groups++
+
+	reportPath := GetRelativeReportPath(group, m.runDir)
+	// Copy since GetFullPath may share its backing array with ParentNames
+	parentNames := append([]string{}, group.GetFullPath()...)
+
+	if group.Stats.SetupFailed {
+		failed := FailedGroup{
+			Name:        group.Name,
+			ParentNames: group.ParentNames,
+			Report:      reportPath,
+		}
+		if group.ErrorInfo != nil {
+			failed.ErrorMessage = group.ErrorInfo.Message
+		}
+		if failed.ParentNames == nil {
+			failed.ParentNames = []string{}
+		}
+		summary.FailedGroups = append(summary.FailedGroups, failed)
+	}
+
+	for _, tc := range group.TestCases {
+		if tc.Status != TestStatusFail {
+			continue
+		}
+		failed := FailedTest{
+			ID:          tc.ID,
+			StableKey:   GenerateStableTestKey(tc.Name, parentNames),
+			Name:        tc.Name,
+			ParentNames: parentNames,
+			Report:      reportPath,
+		}
+		if tc.Error != nil {
+			failed.ErrorMessage = tc.Error.Message
+			failed.FailureKind = tc.Error.Kind
+		}
+		summary.FailedTests = append(summary.FailedTests, failed)
+	}
+
+	// Visit subgroups in name order so the output is stable between runs
+	subgroups := make([]*TestGroup, 0, len(group.Subgroups))
+	for _, sg := range group.Subgroups {
+		subgroups = append(subgroups, sg)
+	}
+	sort.Slice(subgroups, func(i, j int) bool {
+		return subgroups[i].Name < subgroups[j].Name
+	})
+	for _, sg := range subgroups {
+		m.collectFailures(summary, sg)
+	}
+}
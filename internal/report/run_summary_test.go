@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+func TestManager_WritesRunSummary(t *testing.T) {
+	tempDir := t.TempDir()
+
+	manager, err := NewManager(tempDir, runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest --reporters custom")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.SetRunnerVersion("29.7.0")
+
+	events := []ipc.Event{
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "adds numbers",
+				ParentNames: []string{"math.test.js", "Calculator"},
+				Status:      "PASS",
+			},
+		},
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "divides by zero",
+				ParentNames: []string{"math.test.js", "Calculator"},
+				Status:      "FAIL",
+				Error:       &ipc.TestError{Message: "expected Infinity"},
+			},
+		},
+		ipc.GroupTestCaseEvent{
+			EventType: "testCase",
+			Payload: ipc.TestCasePayload{
+				TestName:    "todo",
+				ParentNames: []string{"math.test.js"},
+				Status:      "SKIP",
+			},
+		},
+		ipc.NewGroupErrorEvent("broken.test.js", nil, "SETUP_FAILURE", 0, "cannot find module"),
+	}
+	for _, event := range events {
+		if err := manager.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+
+	if err := manager.Finalize(1); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "run.json"))
+	if err != nil {
+		t.Fatalf("Expected run.json to be written: %v", err)
+	}
+
+	var summary RunSummary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatalf("run.json is not valid JSON: %v\n%s", err, content)
+	}
+
+	if summary.SchemaVersion != RunSummarySchemaVersion {
+		t.Errorf("Expected schemaVersion %d, got %d", RunSummarySchemaVersion, summary.SchemaVersion)
+	}
+	if summary.RunID != filepath.Base(tempDir) {
+		t.Errorf("Expected runId %q, got %q", filepath.Base(tempDir), summary.RunID)
+	}
+	if summary.DetectedRunner != "jest" || summary.ModifiedCommand != "npx jest --reporters custom" {
+		t.Errorf("Unexpected runner metadata: %q / %q", summary.DetectedRunner, summary.ModifiedCommand)
+	}
+	if summary.RunnerVersion != "29.7.0" {
+		t.Errorf("Expected runnerVersion 29.7.0, got %q", summary.RunnerVersion)
+	}
+	if report, _ := os.ReadFile(filepath.Join(tempDir, "test-run.md")); !strings.Contains(string(report), "\ndetected_runner: jest\nrunner_version: 29.7.0\n") {
+		t.Errorf("Expected runner_version in the frontmatter, got:\n%s", report)
+	}
+	if summary.ExitCode != 1 || summary.Status != "COMPLETE" {
+		t.Errorf("Expected exit code 1 and COMPLETE status, got %d / %s", summary.ExitCode, summary.Status)
+	}
+	if summary.EndTime.Before(summary.StartTime) {
+		t.Errorf("Expected end time after start time, got %v -> %v", summary.StartTime, summary.EndTime)
+	}
+
+	wantCounts := RunSummaryCounts{Total: 3, Passed: 1, Failed: 1, Skipped: 1, Groups: 3}
+	if summary.Counts != wantCounts {
+		t.Errorf("Expected counts %+v, got %+v", wantCounts, summary.Counts)
+	}
+	if counts := manager.RunCounts(); counts != wantCounts {
+		t.Errorf("Expected RunCounts() %+v, got %+v", wantCounts, counts)
+	}
+
+	if len(summary.FailedTests) != 1 {
+		t.Fatalf("Expected 1 failed test, got %d", len(summary.FailedTests))
+	}
+	failed := summary.FailedTests[0]
+	if failed.Name != "divides by zero" || failed.ErrorMessage != "expected Infinity" {
+		t.Errorf("Unexpected failed test: %+v", failed)
+	}
+	wantParents := []string{"math.test.js", "Calculator"}
+	if !reflect.DeepEqual(failed.ParentNames, wantParents) {
+		t.Errorf("Expected parent names %v, got %v", wantParents, failed.ParentNames)
+	}
+	if failed.ID != GenerateTestCaseID(failed.Name, wantParents) || failed.StableKey != GenerateStableTestKey(failed.Name, []string{"Calculator"}) {
+		t.Errorf("Expected the storage ID and the stable key, got %q and %q", failed.ID, failed.StableKey)
+	}
+
+	if len(summary.FailedGroups) != 1 || summary.FailedGroups[0].ErrorMessage != "cannot find module" {
+		t.Errorf("Expected setup failure for broken.test.js, got %+v", summary.FailedGroups)
+	}
+}
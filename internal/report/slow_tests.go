@@ -0,0 +1,97 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxSlowTestsListed is the maximum number of slow tests listed in test-run.md
+const MaxSlowTestsListed = 10
+
+// slowTest is a test case that exceeded the slow threshold, with its display path
+type slowTest struct {
+	path     []string // Group hierarchy followed by the test name
+	duration time.Duration
+	report   string // Group report path relative to the run directory
+}
+
+// SetSlowThreshold sets the duration above which test cases are flagged as slow.
+// A zero threshold disables slow test detection.
+func (gm *GroupManager) SetSlowThreshold(threshold time.Duration) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.slowThreshold = threshold
+}
+
+// collectSlowTests returns slow test cases across all groups, slowest first
+func (gm *GroupManager) collectSlowTests() []slowTest {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	var slow []slowTest
+	var visit func(group *TestGroup)
+	visit = func(group *TestGroup) {
+		for _, tc := range group.TestCases {
+			if !tc.Slow {
+				continue
+			}
+			path := make([]string, 0, len(group.ParentNames)+2)
+			for _, name := range group.GetFullPath() {
+				path = append(path, gm.makeRelativePath(name))
+			}
+			slow = append(slow, slowTest{
+				path:     append(path, tc.Name),
+				duration: tc.Duration,
+				report:   GetRelativeReportPath(group, gm.runDir),
+			})
+		}
+		for _, sg := range group.Subgroups {
+			visit(sg)
+		}
+	}
+	for _, group := range gm.rootGroups {
+		visit(group)
+	}
+
+	// Break ties by path so the listing is stable between runs
+	sort.Slice(slow, func(i, j int) bool {
+		if slow[i].duration != slow[j].duration {
+			return slow[i].duration > slow[j].duration
+		}
+		return strings.Join(slow[i].path, "\x00") < strings.Join(slow[j].path, "\x00")
+	})
+	return slow
+}
+
+// formatSlowTestsSection renders the "Slowest tests" section for test-run.md.
+// Returns an empty string when no test exceeded the threshold.
+func formatSlowTestsSection(slow []slowTest, threshold time.Duration) string {
+	if len(slow) == 0 {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("## Slowest tests\n\n")
+	fmt.Fprintf(sb, "%d test(s) took longer than %s.\n\n", len(slow), threshold)
+	sb.WriteString("| Duration | Test | Report |\n")
+	sb.WriteString("|----------|------|--------|\n")
+	for i, st := range slow {
+		if i == MaxSlowTestsListed {
+			break
+		}
+		fmt.Fprintf(sb, "| %.2fs | %s | ./%s |\n", st.duration.Seconds(), escapeTableCell(BuildHierarchicalPathFromSlice(st.path)), st.report)
+	}
+	if len(slow) > MaxSlowTestsListed {
+		fmt.Fprintf(sb, "\n%d more not shown.\n", len(slow)-MaxSlowTestsListed)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// escapeTableCell escapes pipes in text written into a markdown table cell, such as
+// table-driven test names, so they don't split the row
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
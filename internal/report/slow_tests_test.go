@@ -0,0 +1,87 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestGroupManager_SlowTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	log, _ := logger.NewFileLogger()
+	t.Cleanup(func() { _ = log.Close() })
+	gm := NewGroupManager(tmpDir, "", log)
+	gm.SetSlowThreshold(100 * time.Millisecond)
+
+	addTest := func(name string, parents []string, durationMs float64) {
+		event := ipc.NewGroupTestCaseEvent(name, parents, "PASS")
+		event.Payload.Duration = durationMs
+		if err := gm.ProcessTestCase(event); err != nil {
+			t.Fatalf("Failed to process test case %s: %v", name, err)
+		}
+	}
+
+	addTest("fast", []string{"math.test.js"}, 5)
+	addTest("exactly at threshold", []string{"math.test.js"}, 100)
+	addTest("slow add", []string{"math.test.js", "Calculator"}, 250)
+	addTest("slowest", []string{"io.test.js"}, 900)
+
+	slow := gm.collectSlowTests()
+	if len(slow) != 2 {
+		t.Fatalf("Expected 2 slow tests, got %d", len(slow))
+	}
+	if got := slow[0].path[len(slow[0].path)-1]; got != "slowest" {
+		t.Errorf("Expected slowest test first, got %s", got)
+	}
+	if got := slow[1].path; len(got) != 3 || got[1] != "Calculator" || got[2] != "slow add" {
+		t.Errorf("Expected full hierarchical path for slow add, got %v", got)
+	}
+
+	section := formatSlowTestsSection(slow, 100*time.Millisecond)
+	if !strings.Contains(section, "## Slowest tests") {
+		t.Errorf("Expected Slowest tests heading, got:\n%s", section)
+	}
+	if !strings.Contains(section, "| 0.90s | io.test.js → slowest |") {
+		t.Errorf("Expected row for slowest test, got:\n%s", section)
+	}
+}
+
+func TestFormatSlowTestsSection_Limit(t *testing.T) {
+	if section := formatSlowTestsSection(nil, time.Second); section != "" {
+		t.Errorf("Expected no section without slow tests, got:\n%s", section)
+	}
+
+	var slow []slowTest
+	for i := 0; i < MaxSlowTestsListed+3; i++ {
+		slow = append(slow, slowTest{
+			path:     []string{"suite.py", fmt.Sprintf("test_%d", i)},
+			duration: 2 * time.Second,
+			report:   "reports/suite_py/index.md",
+		})
+	}
+
+	section := formatSlowTestsSection(slow, time.Second)
+	if rows := strings.Count(section, "| 2.00s |"); rows != MaxSlowTestsListed {
+		t.Errorf("Expected %d rows, got %d", MaxSlowTestsListed, rows)
+	}
+	if !strings.Contains(section, "3 more not shown.") {
+		t.Errorf("Expected overflow note, got:\n%s", section)
+	}
+}
+
+func TestFormatSlowTestsSectionEscapesPipes(t *testing.T) {
+	slow := []slowTest{{
+		path:     []string{"pkg", "TestParse/a|b"},
+		duration: 2 * time.Second,
+		report:   "reports/pkg/index.md",
+	}}
+
+	section := formatSlowTestsSection(slow, time.Second)
+	if !strings.Contains(section, `| 2.00s | pkg → TestParse/a\|b | ./reports/pkg/index.md |`) {
+		t.Errorf("Expected the pipe in the test name to be escaped, got:\n%s", section)
+	}
+}
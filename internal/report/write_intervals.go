@@ -0,0 +1,50 @@
+package report
+
+import "time"
+
+// SetReportIntervals tunes how often reports are rewritten while a run is going. debounce
+// is how long writes wait for a burst of events to end, and maxWait the longest a report
+// can lag behind the events: test-run.md is rewritten at most once per maxWait, and a
+// group report is written at the latest maxWait after its first pending update. Zero
+// keeps the default (200ms and 1s for test-run.md, 100ms and no limit for group reports).
+func (m *Manager) SetReportIntervals(debounce, maxWait time.Duration) {
+	m.mu.Lock()
+	if debounce > 0 {
+		m.debounceTime = debounce
+	}
+	if maxWait > 0 {
+		m.writeInterval = maxWait
+	}
+	m.mu.Unlock()
+	m.groupManager.SetReportIntervals(debounce, maxWait)
+}
+
+// SetReportIntervals sets how long group report writes wait for updates to stop
+// (debounce) and how long updates can keep postponing them (maxWait). Zero keeps the
+// default of 100ms and no limit.
+func (gm *GroupManager) SetReportIntervals(debounce, maxWait time.Duration) {
+	gm.updateMutex.Lock()
+	defer gm.updateMutex.Unlock()
+	if debounce > 0 {
+		gm.updateDebounce = debounce
+	}
+	if maxWait > 0 {
+		gm.updateMaxWait = maxWait
+	}
+}
+
+// reportUpdateDelay returns how long to wait before writing pending group reports: the
+// debounce time, cut short so the first pending update is written within maxWait.
+// Caller must hold gm.updateMutex.
+func (gm *GroupManager) reportUpdateDelay(now time.Time) time.Duration {
+	delay := gm.updateDebounce
+	if gm.updateMaxWait > 0 {
+		if remaining := gm.updateMaxWait - now.Sub(gm.updatesPendingSince); remaining < delay {
+			delay = remaining
+		}
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
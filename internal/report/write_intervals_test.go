@@ -0,0 +1,86 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+	"github.com/zk/3pio/internal/runner"
+)
+
+// groupReportFlushes returns how many times gm has written its pending group reports
+func groupReportFlushes(gm *GroupManager) int {
+	gm.updateMutex.Lock()
+	defer gm.updateMutex.Unlock()
+	return gm.updateFlushes
+}
+
+func TestGroupManager_DebouncesReportWrites(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	gm.SetReportIntervals(50*time.Millisecond, 0)
+	if err := gm.ProcessGroupDiscovered(ipc.GroupDiscoveredEvent{
+		EventType: string(ipc.EventTypeGroupDiscovered),
+		Payload:   ipc.GroupDiscoveredPayload{GroupName: "math.test.js"},
+	}); err != nil {
+		t.Fatalf("ProcessGroupDiscovered failed: %v", err)
+	}
+
+	// A burst of output well within the debounce window
+	for i := 0; i < 50; i++ {
+		_ = gm.ProcessStdoutChunk("math.test.js", nil, "line\n")
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if flushes := groupReportFlushes(gm); flushes != 1 {
+		t.Errorf("Expected a burst of updates to produce a single write, got %d", flushes)
+	}
+}
+
+func TestGroupManager_MaxWaitBoundsDebounce(t *testing.T) {
+	gm := NewGroupManager(t.TempDir(), "", &mockLogger{})
+	gm.SetReportIntervals(50*time.Millisecond, 40*time.Millisecond)
+	if err := gm.ProcessGroupDiscovered(ipc.GroupDiscoveredEvent{
+		EventType: string(ipc.EventTypeGroupDiscovered),
+		Payload:   ipc.GroupDiscoveredPayload{GroupName: "math.test.js"},
+	}); err != nil {
+		t.Fatalf("ProcessGroupDiscovered failed: %v", err)
+	}
+
+	// Updates keep arriving faster than the debounce time for about 200ms, so without
+	// a maximum wait the report would only be written once they stop
+	for i := 0; i < 40; i++ {
+		_ = gm.ProcessStdoutChunk("math.test.js", nil, "line\n")
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if flushes := groupReportFlushes(gm); flushes < 2 {
+		t.Errorf("Expected the report to be written while updates kept arriving, got %d writes", flushes)
+	}
+}
+
+func TestManager_SetReportIntervals(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), runner.NewJestOutputParser(), &mockLogger{}, "jest", "npx jest")
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetReportIntervals(50*time.Millisecond, 10*time.Millisecond)
+	if err := manager.Initialize("npx jest"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	manager.mu.RLock()
+	initialWrites := manager.reportWrites
+	manager.mu.RUnlock()
+
+	sendTestCases(t, manager, 100, 0)
+	time.Sleep(150 * time.Millisecond)
+
+	manager.mu.RLock()
+	writes := manager.reportWrites - initialWrites
+	manager.mu.RUnlock()
+	if writes != 1 {
+		t.Errorf("Expected events within the debounce window to produce a single write, got %d", writes)
+	}
+	if err := manager.Finalize(0); err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+}
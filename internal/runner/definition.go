@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/zk/3pio/internal/runner/definitions"
 )
 
 // Definition interface for test runner implementations
@@ -22,8 +24,12 @@ type Definition interface {
 	// GetAdapterFileName returns the adapter file name
 	GetAdapterFileName() string
 
-	// InterpretExitCode maps exit codes to success/failure
+	// InterpretExitCode maps exit codes to success/failure, or ExitCodeNoTests
 	InterpretExitCode(code int) string
+
+	// ReproCommand returns a shell command that reruns only the given failed tests,
+	// or "" if the runner cannot select them
+	ReproCommand(failures []definitions.FailedTest) string
 }
 
 // NativeRunner interface for runners that process output directly without adapters
@@ -35,6 +41,10 @@ type NativeRunner interface {
 	GetNativeDefinition() interface{}
 }
 
+// ExitCodeNoTests is what InterpretExitCode returns for an exit code that means the
+// runner found no tests to run, as opposed to "success" and "failure"
+const ExitCodeNoTests = "no_tests"
+
 // BaseDefinition provides common functionality for test runners
 type BaseDefinition struct {
 	name        string
@@ -109,9 +119,10 @@ func NewJestDefinition() *JestDefinition {
 	}
 }
 
-// Matches checks if the command is for Jest
+// Matches checks if the command is for Jest. A workspace command (pnpm --filter,
+// npm --workspace) is checked against the package.json of the packages it runs in.
 func (j *JestDefinition) Matches(command []string) bool {
-	return containsTestRunner(command, "jest") || j.isJestInPackageJSON()
+	return containsTestRunner(command, "jest") || inPackageJSON(command, isJestInPackageJSON)
 }
 
 // GetTestFiles gets test files for Jest
@@ -121,8 +132,16 @@ func (j *JestDefinition) GetTestFiles(args []string) ([]string, error) {
 	return []string{}, nil // Dynamic discovery
 }
 
-// BuildCommand builds Jest command with adapter
+// BuildCommand builds Jest command with adapter. Workspace flags before the script, as in
+// "pnpm --filter web test", stay where they are.
 func (j *JestDefinition) BuildCommand(args []string, adapterPath string) []string {
+	return withWorkspaceFlags(args, func(args []string) []string {
+		return j.buildCommand(args, adapterPath)
+	})
+}
+
+// buildCommand builds a Jest command without workspace flags
+func (j *JestDefinition) buildCommand(args []string, adapterPath string) []string {
 	result := make([]string, 0, len(args)+5)
 
 	foundJest := false
@@ -252,9 +271,9 @@ func (j *JestDefinition) BuildCommand(args []string, adapterPath string) []strin
 	return result
 }
 
-// isJestInPackageJSON checks if Jest is configured in package.json
-func (j *JestDefinition) isJestInPackageJSON() bool {
-	data, err := os.ReadFile("package.json")
+// isJestInPackageJSON checks if Jest is configured in a package.json
+func isJestInPackageJSON(manifestPath string) bool {
+	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		return false
 	}
@@ -288,6 +307,7 @@ func (j *JestDefinition) isJestInPackageJSON() bool {
 // VitestDefinition implements Definition for Vitest
 type VitestDefinition struct {
 	BaseDefinition
+	jsonReportPath string // Where Vitest's json reporter writes, "" to not add it
 }
 
 // NewVitestDefinition creates a new Vitest definition
@@ -300,9 +320,10 @@ func NewVitestDefinition() *VitestDefinition {
 	}
 }
 
-// Matches checks if the command is for Vitest
+// Matches checks if the command is for Vitest. A workspace command (pnpm --filter,
+// npm --workspace) is checked against the package.json of the packages it runs in.
 func (v *VitestDefinition) Matches(command []string) bool {
-	return containsTestRunner(command, "vitest") || v.isVitestInPackageJSON()
+	return containsTestRunner(command, "vitest") || inPackageJSON(command, isVitestInPackageJSON)
 }
 
 // GetTestFiles gets test files for Vitest
@@ -324,8 +345,16 @@ func (v *VitestDefinition) GetTestFiles(args []string) ([]string, error) {
 	return []string{}, nil
 }
 
-// BuildCommand builds Vitest command with adapter
+// BuildCommand builds Vitest command with adapter. Workspace flags before the script, as
+// in "pnpm --filter web test", stay where they are.
 func (v *VitestDefinition) BuildCommand(args []string, adapterPath string) []string {
+	return withWorkspaceFlags(args, func(args []string) []string {
+		return v.buildCommand(args, adapterPath)
+	})
+}
+
+// buildCommand builds a Vitest command without workspace flags
+func (v *VitestDefinition) buildCommand(args []string, adapterPath string) []string {
 	foundVitest := false
 	isPackageManagerCommand := false
 	isDirectVitestCall := false
@@ -342,12 +371,7 @@ func (v *VitestDefinition) BuildCommand(args []string, adapterPath string) []str
 
 	// Check for vitest executable in the command (not in config file names)
 	for _, arg := range args {
-		// Check if this is actually the vitest command or its CLI entry point
-		if arg == "vitest" ||
-			strings.HasSuffix(arg, "/vitest") ||
-			strings.HasSuffix(arg, ".bin/vitest") ||
-			strings.Contains(arg, "vitest@") ||
-			strings.Contains(arg, "vitest/dist/cli") { // Handle node execution of vitest CLI
+		if isVitestCommand(arg) {
 			foundVitest = true
 			break
 		}
@@ -404,7 +428,7 @@ func (v *VitestDefinition) BuildCommand(args []string, adapterPath string) []str
 			result = append(result, "--")
 		}
 
-		result = append(result, "--reporter", adapterPath, "--reporter", "default")
+		result = append(result, v.reporterArgs(args, adapterPath)...)
 
 		return result
 	}
@@ -416,14 +440,10 @@ func (v *VitestDefinition) BuildCommand(args []string, adapterPath string) []str
 
 	for i, arg := range args {
 		// Only check for vitest command or its CLI entry point
-		if !reporterAdded && (arg == "vitest" ||
-			strings.HasSuffix(arg, "/vitest") ||
-			strings.HasSuffix(arg, ".bin/vitest") ||
-			strings.Contains(arg, "vitest@") ||
-			strings.Contains(arg, "vitest/dist/cli")) {
+		if !reporterAdded && isVitestCommand(arg) {
 			result = append(result, arg)
 			// Add reporter flags immediately after vitest command
-			result = append(result, "--reporter", adapterPath, "--reporter", "default")
+			result = append(result, v.reporterArgs(args, adapterPath)...)
 			reporterAdded = true
 
 			// Check if next argument is a vitest subcommand
@@ -458,15 +478,48 @@ func (v *VitestDefinition) BuildCommand(args []string, adapterPath string) []str
 
 	// Fallback: if vitest wasn't found and reporter not added, add at the end
 	if !foundVitest && !reporterAdded {
-		result = append(result, "--reporter", adapterPath, "--reporter", "default")
+		result = append(result, v.reporterArgs(args, adapterPath)...)
 	}
 
 	return result
 }
 
-// isVitestInPackageJSON checks if Vitest is configured in package.json
-func (v *VitestDefinition) isVitestInPackageJSON() bool {
-	data, err := os.ReadFile("package.json")
+// isVitestCommand reports whether arg is the vitest command or its CLI entry point,
+// rather than e.g. a config file name
+func isVitestCommand(arg string) bool {
+	return arg == "vitest" ||
+		strings.HasSuffix(arg, "/vitest") ||
+		strings.HasSuffix(arg, ".bin/vitest") ||
+		strings.Contains(arg, "vitest@") ||
+		strings.Contains(arg, "vitest/dist/cli") // Handle node execution of vitest CLI
+}
+
+// SetJSONReportPath makes BuildCommand add Vitest's json reporter, writing to path, so
+// results can be recovered from it if the 3pio reporter fails to load
+func (v *VitestDefinition) SetJSONReportPath(path string) {
+	v.jsonReportPath = path
+}
+
+// reporterArgs returns the --reporter flags to add to a Vitest command: the 3pio reporter,
+// the default console reporter and, if SetJSONReportPath was called, the json reporter.
+// The json reporter is left out when the command sets --outputFile itself, since the
+// two settings would conflict.
+func (v *VitestDefinition) reporterArgs(args []string, adapterPath string) []string {
+	reporters := []string{"--reporter", adapterPath, "--reporter", "default"}
+	if v.jsonReportPath == "" {
+		return reporters
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--outputFile") {
+			return reporters
+		}
+	}
+	return append(reporters, "--reporter", "json", "--outputFile.json="+v.jsonReportPath)
+}
+
+// isVitestInPackageJSON checks if Vitest is configured in a package.json
+func isVitestInPackageJSON(manifestPath string) bool {
+	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		return false
 	}
@@ -497,6 +550,9 @@ func (v *VitestDefinition) isVitestInPackageJSON() bool {
 	return false
 }
 
+// pytestNoTestsCollected is pytest's exit code when no tests were collected
+const pytestNoTestsCollected = 5
+
 // PytestDefinition implements Definition for pytest
 type PytestDefinition struct {
 	BaseDefinition
@@ -512,8 +568,19 @@ func NewPytestDefinition() *PytestDefinition {
 	}
 }
 
-// Matches checks if the command is for pytest
+// InterpretExitCode recognizes pytest's exit code 5, no tests collected
+func (p *PytestDefinition) InterpretExitCode(code int) string {
+	if code == pytestNoTestsCollected {
+		return ExitCodeNoTests
+	}
+	return p.BaseDefinition.InterpretExitCode(code)
+}
+
+// Matches checks if the command is for pytest. Pytest run by tox is left to ToxDefinition.
 func (p *PytestDefinition) Matches(command []string) bool {
+	if isToxCommand(command) {
+		return false
+	}
 	return containsTestRunner(command, "pytest") || containsTestRunner(command, "py.test")
 }
 
@@ -541,14 +608,7 @@ func (p *PytestDefinition) BuildCommand(args []string, adapterPath string) []str
 	result := make([]string, 0, len(args)+2)
 
 	// Set Python path to include adapter directory
-	adapterDir := filepath.Dir(adapterPath)
-	pythonPath := os.Getenv("PYTHONPATH")
-	if pythonPath != "" {
-		pythonPath = fmt.Sprintf("%s%c%s", adapterDir, os.PathListSeparator, pythonPath)
-	} else {
-		pythonPath = adapterDir
-	}
-	_ = os.Setenv("PYTHONPATH", pythonPath)
+	addToPythonPath(filepath.Dir(adapterPath))
 
 	foundPytest := false
 	for _, arg := range args {
@@ -570,6 +630,17 @@ func (p *PytestDefinition) BuildCommand(args []string, adapterPath string) []str
 	return result
 }
 
+// addToPythonPath puts dir in front of PYTHONPATH so Python finds the pytest adapter
+func addToPythonPath(dir string) {
+	pythonPath := os.Getenv("PYTHONPATH")
+	if pythonPath != "" {
+		pythonPath = fmt.Sprintf("%s%c%s", dir, os.PathListSeparator, pythonPath)
+	} else {
+		pythonPath = dir
+	}
+	_ = os.Setenv("PYTHONPATH", pythonPath)
+}
+
 // CypressDefinition implements Definition for Cypress
 type CypressDefinition struct {
 	BaseDefinition
@@ -97,3 +97,15 @@ func TestPytestDefinition_Matches_FalsePositives(t *testing.T) {
 		})
 	}
 }
+
+func TestPytestDefinition_InterpretExitCode(t *testing.T) {
+	pytest := NewPytestDefinition()
+	for code, want := range map[int]string{0: "success", 1: "failure", 2: "failure", 5: ExitCodeNoTests} {
+		if got := pytest.InterpretExitCode(code); got != want {
+			t.Errorf("PytestDefinition.InterpretExitCode(%d) = %q, want %q", code, got, want)
+		}
+	}
+	if got := NewJestDefinition().InterpretExitCode(5); got != "failure" {
+		t.Errorf("JestDefinition.InterpretExitCode(5) = %q, want failure", got)
+	}
+}
@@ -2,15 +2,19 @@ package definitions
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os/exec"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/logger"
 )
 
@@ -29,6 +33,7 @@ type CargoTestDefinition struct {
 
 	// Workspace and crate tracking
 	workspaceName    string                     // Name of workspace if detected
+	workspaceStart   time.Time                  // When the workspace group started (zero until then)
 	currentCrate     string                     // Currently executing crate
 	crateTestCounts  map[string]int             // Expected test count per crate from suite events
 	crateTestsSeen   map[string]int             // Number of tests seen so far per crate
@@ -256,23 +261,90 @@ func (c *CargoTestDefinition) ProcessOutput(combinedOutput io.Reader, ipcPath st
 	c.finalizePendingGroups()
 
 	// Send runComplete event to signal processing is done
-	runCompleteEvent := map[string]interface{}{
-		"eventType": "runComplete",
-		"payload":   map[string]interface{}{},
-	}
-	if err := c.ipcWriter.WriteEvent(runCompleteEvent); err != nil {
+	if err := c.ipcWriter.WriteEvent(ipc.NewRunCompleteEvent()); err != nil {
 		c.logger.Debug("Failed to send runComplete event: %v", err)
 	}
 
 	return nil
 }
 
-// loadCargoMetadata loads crate metadata from cargo metadata command
+// cargoMetadataTimeout bounds how long "cargo metadata" may take before crate descriptions are skipped
+const cargoMetadataTimeout = 10 * time.Second
+
+// cargoMetadata is the subset of "cargo metadata --format-version 1" output we use
+type cargoMetadata struct {
+	Packages []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"packages"`
+	WorkspaceMembers []string `json:"workspace_members"`
+	WorkspaceRoot    string   `json:"workspace_root"`
+}
+
+// loadCargoMetadata runs "cargo metadata" to get crate descriptions and detect workspaces.
+// Failures are logged and leave crates without descriptions.
 func (c *CargoTestDefinition) loadCargoMetadata() {
-	// TODO: In a full implementation, we would run `cargo metadata --format-version 1`
-	// and parse the JSON output to get crate descriptions. For now, we'll use
-	// a simplified approach that could be extended later.
-	c.logger.Debug("Loading cargo metadata (placeholder for now)")
+	ctx, cancel := context.WithTimeout(context.Background(), cargoMetadataTimeout)
+	defer cancel()
+
+	// --no-deps limits packages to the workspace and avoids resolving the dependency graph
+	output, err := exec.CommandContext(ctx, "cargo", "metadata", "--format-version", "1", "--no-deps").Output()
+	if err != nil {
+		c.logger.Debug("Failed to run cargo metadata, continuing without crate descriptions: %v", err)
+		return
+	}
+
+	if err := c.parseCargoMetadata(output); err != nil {
+		c.logger.Debug("Failed to parse cargo metadata: %v", err)
+	}
+}
+
+// parseCargoMetadata fills crateMetadata from "cargo metadata" output. When the workspace has
+// more than one member, its root directory name becomes the workspace group.
+func (c *CargoTestDefinition) parseCargoMetadata(data []byte) error {
+	var metadata cargoMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return err
+	}
+
+	members := make(map[string]bool, len(metadata.WorkspaceMembers))
+	for _, id := range metadata.WorkspaceMembers {
+		members[id] = true
+	}
+
+	for _, pkg := range metadata.Packages {
+		if len(members) > 0 && !members[pkg.ID] {
+			continue
+		}
+		// Test binaries use the crate name with underscores, so key by that
+		c.crateMetadata[strings.ReplaceAll(pkg.Name, "-", "_")] = &CrateMetadata{
+			Name:        pkg.Name,
+			Description: strings.TrimSpace(pkg.Description),
+			Version:     pkg.Version,
+		}
+	}
+
+	if len(metadata.WorkspaceMembers) > 1 && metadata.WorkspaceRoot != "" {
+		c.workspaceName = filepath.Base(metadata.WorkspaceRoot)
+	}
+	c.logger.Debug("Loaded cargo metadata for %d crates (workspace: %q)", len(c.crateMetadata), c.workspaceName)
+	return nil
+}
+
+// crateDisplayName returns the group name for a crate: hyphenated, with its description
+// when known, or "Doc-tests <crate>" for doc-test crates
+func (c *CargoTestDefinition) crateDisplayName(crateName string) string {
+	if strings.HasPrefix(crateName, "doc:") {
+		return "Doc-tests " + strings.ReplaceAll(strings.TrimPrefix(crateName, "doc:"), "_", "-")
+	}
+
+	displayName := strings.ReplaceAll(crateName, "_", "-")
+	if metadata, ok := c.crateMetadata[strings.ReplaceAll(crateName, "-", "_")]; ok && metadata.Description != "" {
+		displayName = fmt.Sprintf("%s (%s)", displayName, metadata.Description)
+	}
+	return displayName
 }
 
 // processLineData processes a single line of cargo test output
@@ -387,35 +459,26 @@ func (c *CargoTestDefinition) processSuiteEvent(event *CargoTestEvent) error {
 			// since no test events will be generated
 			totalTests := event.Passed + event.Failed + event.Ignored
 			if totalTests == 0 {
-				// Check if this is a doc-test
-				var displayCrateName string
-				if strings.HasPrefix(crateName, "doc:") {
-					// Doc-test: display as "Doc-tests <crate>"
-					actualCrateName := strings.TrimPrefix(crateName, "doc:")
-					// Convert underscores to hyphens for consistency
-					actualCrateName = strings.ReplaceAll(actualCrateName, "_", "-")
-					displayCrateName = "Doc-tests " + actualCrateName
-				} else {
-					// Regular test: convert underscores to hyphens for display
-					displayCrateName = strings.ReplaceAll(crateName, "_", "-")
-				}
+				// Same name and parents as crates with tests
+				displayCrateName := c.crateDisplayName(crateName)
+				parentNames := c.crateParents()
 
 				// Send group discovered
 				if !c.discoveredGroups[crateName] {
-					c.sendGroupDiscovered(displayCrateName, nil)
+					c.sendGroupDiscovered(displayCrateName, parentNames)
 					c.discoveredGroups[crateName] = true
 				}
 
 				// Send group start
 				if !c.groupStarts[crateName] {
-					c.sendGroupStart(displayCrateName, nil)
+					c.sendGroupStart(displayCrateName, parentNames)
 					c.groupStarts[crateName] = true
 				}
 
 				// Send group result with 0 tests and duration from exec_time
 				durationMs := event.ExecTime * 1000
 				// Groups with 0 tests should have NO_TESTS status
-				c.sendGroupResult(displayCrateName, nil, "NO_TESTS", durationMs, 0, 0, 0)
+				c.sendGroupResult(displayCrateName, parentNames, "NO_TESTS", durationMs, 0, 0, 0)
 
 				// Mark this group as finalized
 				if group, ok := c.crateGroups[crateName]; ok {
@@ -483,57 +546,21 @@ func (c *CargoTestDefinition) processTestEvent(event *CargoTestEvent) error {
 		return nil
 	}
 
-	// Check if we're in a workspace
-	if c.workspaceName == "" {
-		// Try to detect workspace from cargo metadata (simplified for now)
-		// In a real implementation, we'd run `cargo metadata` to get workspace info
-		// For now, assume single crate
-		c.workspaceName = ""
-	}
-
-	// Build parent hierarchy
-	var parentNames []string
-	if c.workspaceName != "" {
-		parentNames = append(parentNames, c.workspaceName)
-	}
-
-	// Get crate metadata if available
-	var crateDesc string
-	if metadata, ok := c.crateMetadata[crateName]; ok {
-		crateDesc = metadata.Description
-	}
+	// Build parent hierarchy (workspace detected from cargo metadata)
+	parentNames := c.crateParents()
 
-	// Convert underscores to hyphens for display
-	var displayCrateName string
-	var enhancedCrateName string
-
-	// Check if this is a doc-test crate
-	if strings.HasPrefix(crateName, "doc:") {
-		// Doc-test: display as "Doc-tests <crate>"
-		actualCrateName := strings.TrimPrefix(crateName, "doc:")
-		// Convert underscores to hyphens for consistency
-		actualCrateName = strings.ReplaceAll(actualCrateName, "_", "-")
-		displayCrateName = "Doc-tests " + actualCrateName
-		enhancedCrateName = displayCrateName
-	} else {
-		// Regular crate: convert underscores to hyphens
-		displayCrateName = strings.ReplaceAll(crateName, "_", "-")
-		// Build enhanced crate name with description
-		enhancedCrateName = displayCrateName
-		if crateDesc != "" {
-			enhancedCrateName = fmt.Sprintf("%s (%s)", displayCrateName, crateDesc)
-		}
-	}
+	// Crate group name, including its description from cargo metadata when available
+	displayCrateName := c.crateDisplayName(crateName)
 
 	// Ensure crate group exists
 	if !c.discoveredGroups[crateName] {
-		c.sendGroupDiscovered(enhancedCrateName, parentNames)
+		c.sendGroupDiscovered(displayCrateName, parentNames)
 		c.discoveredGroups[crateName] = true
 	}
 
 	// Send group start for crate if not started
 	if !c.groupStarts[crateName] {
-		c.sendGroupStart(enhancedCrateName, parentNames)
+		c.sendGroupStart(displayCrateName, parentNames)
 		c.groupStarts[crateName] = true
 		c.crateGroups[crateName] = &CrateGroupInfo{
 			Name:      crateName,
@@ -623,9 +650,22 @@ func (c *CargoTestDefinition) processTestEvent(event *CargoTestEvent) error {
 			status = "PASS"
 		}
 
+		// Doc-tests report why they failed on stdout; other tests' panics are on stderr
+		var testError *ipc.TestError
+		if status == "FAIL" {
+			if strings.HasPrefix(crateName, "doc:") {
+				testError = docTestError(event.Name, event.Stdout)
+			} else if event.Stderr != "" {
+				testError = &ipc.TestError{
+					Message:     event.Stderr,
+					FailureKind: rustFailureKind(event.Stderr),
+				}
+			}
+		}
+
 		// Send test case event (convert duration from seconds to milliseconds)
 		durationMs := event.ExecTime * 1000
-		c.sendTestCase(testName, testParents, status, durationMs, event.Stdout, event.Stderr)
+		c.sendTestCase(testName, testParents, status, durationMs, event.Stdout, event.Stderr, testError)
 
 		// Create test info
 		testInfo := CargoTestInfo{
@@ -717,10 +757,7 @@ func (c *CargoTestDefinition) finalizePendingGroups() {
 			}
 
 			// Build parent hierarchy based on the group key
-			var parentNames []string
-			if c.workspaceName != "" {
-				parentNames = append(parentNames, c.workspaceName)
-			}
+			parentNames := c.crateParents()
 
 			// Parse the group key to determine if it's a module or crate
 			if strings.Contains(groupKey, "::") {
@@ -730,16 +767,7 @@ func (c *CargoTestDefinition) finalizePendingGroups() {
 				modulePath := parts[1:]
 
 				// Parent hierarchy starts with crate (use display name)
-				var displayCrateName string
-				if strings.HasPrefix(crateName, "doc:") {
-					// Doc-test: display as "Doc-tests <crate>"
-					actualCrateName := strings.TrimPrefix(crateName, "doc:")
-					actualCrateName = strings.ReplaceAll(actualCrateName, "_", "-")
-					displayCrateName = "Doc-tests " + actualCrateName
-				} else {
-					displayCrateName = strings.ReplaceAll(crateName, "_", "-")
-				}
-				parentNames = append(parentNames, displayCrateName)
+				parentNames = append(parentNames, c.crateDisplayName(crateName))
 
 				// Add parent modules (all but the last one)
 				for i := 0; i < len(modulePath)-1; i++ {
@@ -751,18 +779,7 @@ func (c *CargoTestDefinition) finalizePendingGroups() {
 				c.sendGroupResult(groupName, parentNames, finalStatus, totalDuration, passed, failed, skipped)
 			} else {
 				// This is a crate group
-				var displayName string
-				if strings.HasPrefix(groupKey, "doc:") {
-					// Doc-test group: display as "Doc-tests <crate>"
-					actualCrateName := strings.TrimPrefix(groupKey, "doc:")
-					// Convert underscores to hyphens in the crate name
-					actualCrateName = strings.ReplaceAll(actualCrateName, "_", "-")
-					displayName = "Doc-tests " + actualCrateName
-				} else {
-					// Regular crate group - convert underscores to hyphens for display
-					displayName = strings.ReplaceAll(group.Name, "_", "-")
-				}
-				c.sendGroupResult(displayName, parentNames, finalStatus, totalDuration, passed, failed, skipped)
+				c.sendGroupResult(c.crateDisplayName(groupKey), parentNames, finalStatus, totalDuration, passed, failed, skipped)
 			}
 
 			// Mark this group as finalized
@@ -770,101 +787,113 @@ func (c *CargoTestDefinition) finalizePendingGroups() {
 			group.Finalized = true
 		}
 	}
+
+	c.finalizeWorkspace()
+}
+
+// crateParents returns the parent names of a crate group: the workspace group, if any.
+// It starts the workspace group the first time a crate needs it.
+func (c *CargoTestDefinition) crateParents() []string {
+	if c.workspaceName == "" {
+		return nil
+	}
+	if c.workspaceStart.IsZero() {
+		c.sendGroupDiscovered(c.workspaceName, nil)
+		c.sendGroupStart(c.workspaceName, nil)
+		c.workspaceStart = time.Now()
+	}
+	return []string{c.workspaceName}
+}
+
+// finalizeWorkspace sends the result of the workspace group, rolled up from its crates.
+// It fails when a crate failed and has no tests when none of its crates had any.
+func (c *CargoTestDefinition) finalizeWorkspace() {
+	if c.workspaceStart.IsZero() {
+		return
+	}
+	passed, failed, skipped := 0, 0, 0
+	anyFailed := false
+	for key, group := range c.crateGroups {
+		if strings.Contains(key, "::") {
+			continue
+		}
+		if group.Status == "FAIL" {
+			anyFailed = true
+		}
+		for _, test := range group.Tests {
+			switch test.Status {
+			case "PASS":
+				passed++
+			case "FAIL":
+				failed++
+			case "SKIP":
+				skipped++
+			}
+		}
+	}
+
+	status := "PASS"
+	switch {
+	case failed > 0 || anyFailed:
+		status = "FAIL"
+	case passed+skipped == 0:
+		status = "NO_TESTS"
+	case passed == 0:
+		status = "SKIP"
+	}
+	duration := float64(time.Since(c.workspaceStart).Milliseconds())
+	c.sendGroupResult(c.workspaceName, nil, status, duration, passed, failed, skipped)
 }
 
 // IPC event sending methods
 
 func (c *CargoTestDefinition) sendCollectionStart(testCount int) {
-	event := map[string]interface{}{
-		"eventType": "collectionStart",
-		"payload": map[string]interface{}{
-			"collected": testCount,
-		},
-	}
-	c.sendIPCEvent(event)
+	c.sendIPCEvent(ipc.NewCollectionStartEvent(testCount))
 }
 
 func (c *CargoTestDefinition) sendCollectionFinish(testCount int) {
-	event := map[string]interface{}{
-		"eventType": "collectionFinish",
-		"payload": map[string]interface{}{
-			"collected": testCount,
-		},
-	}
-	c.sendIPCEvent(event)
+	c.sendIPCEvent(ipc.NewCollectionFinishEvent(testCount))
 }
 
 func (c *CargoTestDefinition) sendGroupDiscovered(groupName string, parentNames []string) {
-	event := map[string]interface{}{
-		"eventType": "testGroupDiscovered",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-		},
-	}
-	c.sendIPCEvent(event)
+	c.sendIPCEvent(ipc.NewGroupDiscoveredEvent(groupName, parentNames))
 }
 
 func (c *CargoTestDefinition) sendGroupStart(groupName string, parentNames []string) {
-	event := map[string]interface{}{
-		"eventType": "testGroupStart",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-		},
-	}
-	c.sendIPCEvent(event)
+	c.sendIPCEvent(ipc.NewGroupStartEvent(groupName, parentNames))
 }
 
-func (c *CargoTestDefinition) sendTestCase(testName string, parentNames []string, status string, duration float64, stdout, stderr string) {
-	payload := map[string]interface{}{
-		"testName":    testName,
-		"parentNames": parentNames,
-		"status":      status,
-		"duration":    duration,
-	}
-
-	// Only include stdout/stderr if non-empty
-	if stdout != "" {
-		payload["stdout"] = stdout
-	}
-	if stderr != "" {
-		payload["stderr"] = stderr
-	}
-
-	// Include error message for failed tests
-	if status == "FAIL" && stderr != "" {
-		payload["error"] = map[string]interface{}{
-			"message": stderr,
-		}
-	}
+func (c *CargoTestDefinition) sendTestCase(testName string, parentNames []string, status string, duration float64, stdout, stderr string, testError *ipc.TestError) {
+	event := ipc.NewGroupTestCaseEvent(testName, parentNames, status)
+	event.Payload.Duration = duration
+	event.Payload.Stdout = stdout
+	event.Payload.Stderr = stderr
+	event.Payload.Error = testError
+	c.sendIPCEvent(event)
+}
 
-	event := map[string]interface{}{
-		"eventType": "testCase",
-		"payload":   payload,
+// rustFailureKind classifies a failed Rust test by its output. assert! panics with
+// "assertion failed: ..." and assert_eq! with "assertion `left == right` failed" (or
+// "assertion failed: `(left == right)`" before Rust 1.73); any other panic, or an Err
+// returned by the test, is an exception.
+func rustFailureKind(output string) ipc.FailureKind {
+	if strings.Contains(output, "assertion failed") || strings.Contains(output, "assertion `") {
+		return ipc.FailureKindAssertion
 	}
-	c.sendIPCEvent(event)
+	return ipc.FailureKindException
 }
 
 func (c *CargoTestDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, passed, failed, skipped int) {
-	event := map[string]interface{}{
-		"eventType": "testGroupResult",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-			"status":      status,
-			"duration":    duration,
-			"totals": map[string]interface{}{
-				"passed":  passed,
-				"failed":  failed,
-				"skipped": skipped,
-			},
-		},
+	event := ipc.NewGroupResultEvent(groupName, parentNames, status, duration)
+	event.Payload.Totals = ipc.GroupTotals{
+		Passed:  passed,
+		Failed:  failed,
+		Skipped: skipped,
 	}
 	c.sendIPCEvent(event)
 }
 
-func (c *CargoTestDefinition) sendIPCEvent(event map[string]interface{}) {
+func (c *CargoTestDefinition) sendIPCEvent(event ipc.Event) {
 	if c.ipcWriter == nil {
 		c.logger.Debug("IPC writer not initialized, skipping event: %v", event)
 		return
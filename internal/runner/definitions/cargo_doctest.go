@@ -0,0 +1,130 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// docTestCompileErrorType is the errorType reported for doc examples that didn't compile
+const docTestCompileErrorType = "COMPILE_ERROR"
+
+// docTestNameRegex matches a doc-test's name: the source file, the documented item and
+// the line of the example's opening fence, e.g. "src/lib.rs - add (line 3)"
+var docTestNameRegex = regexp.MustCompile(`^(.+?) - .+ \(line (\d+)\)$`)
+
+// rustPanicLocationRegex matches where a panic happened, e.g. "panicked at src/lib.rs:5:1:"
+var rustPanicLocationRegex = regexp.MustCompile(`panicked at ([^\s:]+:\d+)`)
+
+// rustcLocationRegex matches where rustc found an error, e.g. " --> src/lib.rs:15:12"
+var rustcLocationRegex = regexp.MustCompile(`(?m)^\s*--> ([^\s:]+:\d+)`)
+
+// docTestError builds the error of a failed doc-test from the output rustdoc reports for
+// it. An example that panicked is reported as "Test executable failed" followed by its
+// stderr, which is split into the panic message and the backtrace; one that didn't compile
+// is reported with the compiler's errors. The location is where the panic or the first
+// compiler error happened, or else the example's opening fence. The example itself is
+// added to the message when its source file can be read.
+func docTestError(name, output string) *ipc.TestError {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil
+	}
+
+	var file string
+	var fenceLine int
+	if match := docTestNameRegex.FindStringSubmatch(name); match != nil {
+		file = match[1]
+		fenceLine, _ = strconv.Atoi(match[2])
+	}
+
+	testError := &ipc.TestError{}
+	var message string
+	if _, stderr, ok := strings.Cut(output, "stderr:\n"); ok && strings.HasPrefix(output, "Test executable failed") {
+		var stack string
+		message, stack, _ = strings.Cut(strings.TrimSpace(stderr), "stack backtrace:")
+		message = strings.TrimSpace(message)
+		if stack = strings.TrimRight(strings.TrimLeft(stack, "\n"), " \n"); stack != "" {
+			testError.Stack = stack
+		}
+		testError.FailureKind = rustFailureKind(message)
+		if match := rustPanicLocationRegex.FindStringSubmatch(message); match != nil {
+			testError.Location = match[1]
+		}
+	} else {
+		// The summary lines after the compiler's errors say nothing about this example
+		var lines []string
+		for _, line := range strings.Split(output, "\n") {
+			if line == "Couldn't compile the test." || strings.HasPrefix(line, "For more information about") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		message = strings.TrimSpace(strings.Join(lines, "\n"))
+		testError.ErrorType = docTestCompileErrorType
+		testError.FailureKind = ipc.FailureKindException
+		if match := rustcLocationRegex.FindStringSubmatch(message); match != nil {
+			testError.Location = match[1]
+		}
+	}
+	if testError.Location == "" && file != "" {
+		testError.Location = file + ":" + strconv.Itoa(fenceLine)
+	}
+
+	if snippet := docTestSnippet(file, fenceLine); snippet != "" {
+		message += "\n\nDoc example (" + file + ":" + strconv.Itoa(fenceLine) + "):\n" + snippet
+	}
+	testError.Message = message
+	return testError
+}
+
+// docTestSnippet returns the code of the doc example whose opening fence is at line
+// fenceLine of file, without the doc comment markers, or "" when it can't be read.
+// Markdown files, such as a README included as crate docs, hold the example as is.
+func docTestSnippet(file string, fenceLine int) string {
+	if file == "" || fenceLine < 1 {
+		return ""
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if fenceLine > len(lines) {
+		return ""
+	}
+	markdown := strings.EqualFold(filepath.Ext(file), ".md")
+
+	docLine := func(line string) (string, bool) {
+		if markdown {
+			return line, true
+		}
+		trimmed := strings.TrimSpace(line)
+		for _, marker := range []string{"///", "//!"} {
+			if text, ok := strings.CutPrefix(trimmed, marker); ok {
+				return strings.TrimPrefix(text, " "), true
+			}
+		}
+		return "", false
+	}
+
+	if fence, ok := docLine(lines[fenceLine-1]); !ok || !strings.HasPrefix(strings.TrimSpace(fence), "```") {
+		return ""
+	}
+	var snippet []string
+	for _, line := range lines[fenceLine:] {
+		text, ok := docLine(line)
+		if !ok {
+			return ""
+		}
+		if strings.HasPrefix(strings.TrimSpace(text), "```") {
+			return strings.Join(snippet, "\n")
+		}
+		snippet = append(snippet, text)
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// docTestSource is a lib.rs with a doc example at line 3
+const docTestSource = `/// Adds two numbers.
+///
+/// ` + "```" + `
+/// let sum = shop::add(2, 2);
+/// assert_eq!(sum, 5);
+/// ` + "```" + `
+pub fn add(a: i32, b: i32) -> i32 {
+    a + b
+}
+`
+
+func TestDocTestError_Panic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.rs")
+	if err := os.WriteFile(file, []byte(docTestSource), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	output := "Test executable failed (exit status: 101).\n\nstderr:\n\nthread 'main' panicked at src/lib.rs:5:1:\n" +
+		"assertion `left == right` failed\n  left: 4\n right: 5\nstack backtrace:\n   0: __rustc::rust_begin_unwind\n   1: rust_out::main\n\n"
+	testError := docTestError(file+" - add (line 3)", output)
+
+	message := testError.Message
+	wantMessage := "thread 'main' panicked at src/lib.rs:5:1:\nassertion `left == right` failed\n  left: 4\n right: 5\n\n" +
+		"Doc example (" + file + ":3):\nlet sum = shop::add(2, 2);\nassert_eq!(sum, 5);"
+	if message != wantMessage {
+		t.Errorf("Unexpected message:\n%s\nwant:\n%s", message, wantMessage)
+	}
+	if testError.Stack != "   0: __rustc::rust_begin_unwind\n   1: rust_out::main" {
+		t.Errorf("Expected the backtrace as the stack, got %q", testError.Stack)
+	}
+	if testError.Location != "src/lib.rs:5" || testError.FailureKind != ipc.FailureKindAssertion {
+		t.Errorf("Expected an assertion at src/lib.rs:5, got %v at %v", testError.FailureKind, testError.Location)
+	}
+}
+
+func TestDocTestError_CompileError(t *testing.T) {
+	output := "error[E0425]: cannot find value `y` in this scope\n --> src/lib.rs:15:12\n  |\n6 | assert_eq!(y, 2);\n  |            ^ not found in this scope\n\n" +
+		"error: aborting due to 1 previous error\n\nFor more information about this error, try `rustc --explain E0425`.\nCouldn't compile the test."
+	testError := docTestError("src/missing.rs - sub (line 12)", output)
+
+	message := testError.Message
+	if !strings.HasPrefix(message, "error[E0425]: cannot find value `y` in this scope") || !strings.HasSuffix(message, "error: aborting due to 1 previous error") {
+		t.Errorf("Expected the compiler's errors as the message, got %q", message)
+	}
+	if testError.ErrorType != docTestCompileErrorType || testError.FailureKind != ipc.FailureKindException {
+		t.Errorf("Expected a compile error, got %v (%v)", testError.ErrorType, testError.FailureKind)
+	}
+	if testError.Location != "src/lib.rs:15" {
+		t.Errorf("Expected the compiler error's location, got %v", testError.Location)
+	}
+
+	// Without a location in the output, the example's fence is the location
+	if testError := docTestError("src/lib.rs - sub (line 12)", "Couldn't compile the test."); testError.Location != "src/lib.rs:12" {
+		t.Errorf("Expected the example's fence as the location, got %v", testError.Location)
+	}
+}
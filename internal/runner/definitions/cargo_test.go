@@ -1,10 +1,14 @@
 package definitions
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/logger"
 )
 
@@ -213,3 +217,140 @@ func TestCargoTestDefinition_SetEnvironment(t *testing.T) {
 		t.Errorf("SetEnvironment should return RUSTC_BOOTSTRAP=1, got %s", env[0])
 	}
 }
+
+func TestCargoTestDefinition_ParseCargoMetadata(t *testing.T) {
+	logger, _ := logger.NewFileLogger()
+	defer func() { _ = logger.Close() }()
+
+	metadata := `{
+  "packages": [
+    {"id": "path+file:///work/shop/core#shop-core@0.3.1", "name": "shop-core", "version": "0.3.1", "description": "Core domain types\n"},
+    {"id": "path+file:///work/shop/api#0.1.0", "name": "api", "version": "0.1.0", "description": null}
+  ],
+  "workspace_members": ["path+file:///work/shop/core#shop-core@0.3.1", "path+file:///work/shop/api#0.1.0"],
+  "workspace_root": "/work/shop",
+  "version": 1
+}`
+
+	def := NewCargoTestDefinition(logger)
+	if err := def.parseCargoMetadata([]byte(metadata)); err != nil {
+		t.Fatalf("parseCargoMetadata failed: %v", err)
+	}
+
+	if def.workspaceName != "shop" {
+		t.Errorf("Expected workspace name 'shop', got %q", def.workspaceName)
+	}
+	if got := def.crateDisplayName("shop_core"); got != "shop-core (Core domain types)" {
+		t.Errorf("Expected crate description in display name, got %q", got)
+	}
+	if got := def.crateDisplayName("api"); got != "api" {
+		t.Errorf("Expected plain name for crate without description, got %q", got)
+	}
+	if got := def.crateDisplayName("doc:shop_core"); got != "Doc-tests shop-core" {
+		t.Errorf("Expected doc-test display name, got %q", got)
+	}
+
+	// A single-crate project has no workspace group
+	single := NewCargoTestDefinition(logger)
+	if err := single.parseCargoMetadata([]byte(`{"packages": [{"id": "a", "name": "a", "version": "1.0.0"}], "workspace_members": ["a"], "workspace_root": "/work/a"}`)); err != nil {
+		t.Fatalf("parseCargoMetadata failed: %v", err)
+	}
+	if single.workspaceName != "" {
+		t.Errorf("Expected no workspace for a single crate, got %q", single.workspaceName)
+	}
+
+	if err := single.parseCargoMetadata([]byte("error: could not find Cargo.toml")); err == nil {
+		t.Error("Expected error for invalid metadata")
+	}
+}
+
+func TestCargoTestDefinition_WorkspaceGroup(t *testing.T) {
+	logger, _ := logger.NewFileLogger()
+	defer func() { _ = logger.Close() }()
+
+	def := NewCargoTestDefinition(logger)
+	def.workspaceName = "shop"
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+
+	count := 0
+	for _, line := range []string{
+		"     Running unittests src/lib.rs (target/debug/deps/shop_core-0123abcd)",
+		`{"type":"suite","event":"started","test_count":2}`,
+		`{"type":"test","event":"started","name":"cart::adds"}`,
+		`{"type":"test","name":"cart::adds","event":"ok","exec_time":0.001}`,
+		`{"type":"test","event":"started","name":"cart::removes"}`,
+		`{"type":"test","name":"cart::removes","event":"failed","stdout":"assertion failed"}`,
+		`{"type":"suite","event":"failed","passed":1,"failed":1,"ignored":0}`,
+		"     Running unittests src/lib.rs (target/debug/deps/api-4567ef01)",
+		`{"type":"suite","event":"started","test_count":0}`,
+		`{"type":"suite","event":"ok","passed":0,"failed":0,"ignored":0,"exec_time":0.0}`,
+	} {
+		def.processLineData(line, &count)
+	}
+	def.finalizePendingGroups()
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	var workspaceEvents []string
+	var workspaceResult, emptyCrate map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC event %q: %v", line, err)
+		}
+		switch event.Payload["groupName"] {
+		case "shop":
+			workspaceEvents = append(workspaceEvents, event.EventType)
+			if event.EventType == "testGroupResult" {
+				workspaceResult = event.Payload
+			}
+		case "api":
+			if event.EventType == "testGroupResult" {
+				emptyCrate = event.Payload
+			}
+		}
+	}
+
+	if want := []string{"testGroupDiscovered", "testGroupStart", "testGroupResult"}; !reflect.DeepEqual(workspaceEvents, want) {
+		t.Fatalf("Expected workspace events %q, got %q", want, workspaceEvents)
+	}
+	if workspaceResult["status"] != "FAIL" {
+		t.Errorf("Expected the workspace to fail with its crate, got %v", workspaceResult["status"])
+	}
+	totals, _ := workspaceResult["totals"].(map[string]interface{})
+	if totals["passed"] != 1.0 || totals["failed"] != 1.0 {
+		t.Errorf("Expected the workspace totals rolled up from its crates, got %v", totals)
+	}
+	if emptyCrate == nil || !reflect.DeepEqual(emptyCrate["parentNames"], []interface{}{"shop"}) {
+		t.Errorf("Expected the empty crate under the workspace group, got %v", emptyCrate)
+	}
+}
+
+func TestRustFailureKind(t *testing.T) {
+	tests := []struct {
+		output string
+		kind   ipc.FailureKind
+	}{
+		{"thread 'tests::adds' panicked at src/lib.rs:10:5:\nassertion `left == right` failed\n  left: 1\n right: 2", ipc.FailureKindAssertion},
+		{"thread 'tests::adds' panicked at 'assertion failed: `(left == right)`', src/lib.rs:10:5", ipc.FailureKindAssertion},
+		{"thread 'tests::parses' panicked at src/lib.rs:20:9:\ncalled `Option::unwrap()` on a `None` value", ipc.FailureKindException},
+		{"Error: ParseIntError { kind: InvalidDigit }", ipc.FailureKindException},
+	}
+
+	for _, tt := range tests {
+		if kind := rustFailureKind(tt.output); kind != tt.kind {
+			t.Errorf("rustFailureKind(%q) = %s, want %s", tt.output, kind, tt.kind)
+		}
+	}
+}
@@ -45,6 +45,11 @@ func (c *CargoTestWrapper) InterpretExitCode(code int) string {
 	return "failure"
 }
 
+// ReproCommand returns a command that reruns only the given failed tests
+func (c *CargoTestWrapper) ReproCommand(failures []FailedTest) string {
+	return c.CargoTestDefinition.ReproCommand(failures)
+}
+
 // IsNative returns true as cargo test processes output directly
 func (c *CargoTestWrapper) IsNative() bool {
 	return true
@@ -0,0 +1,449 @@
+package definitions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// trxPollInterval is how often the results directory is scanned for finished TRX files
+const trxPollInterval = 250 * time.Millisecond
+
+// DotnetTestDefinition implements support for "dotnet test" (VSTest with xUnit, NUnit or MSTest).
+// The command is given a TRX logger writing into a results directory owned by 3pio, and each
+// TRX file (one per test project) is converted into IPC events once it has been written.
+//
+// Assemblies become root groups, namespaces and classes become nested groups, and test
+// methods become test cases.
+type DotnetTestDefinition struct {
+	logger    *logger.FileLogger
+	mu        sync.Mutex
+	ipcWriter *IPCWriter
+
+	resultsDir     string // Directory the TRX logger writes to
+	tempResultsDir bool   // Whether resultsDir was created by us and should be removed
+
+	processed  map[string]bool // TRX files already converted
+	discovered map[string]bool // Group key -> discovered and started
+}
+
+// trxTestRun is the root <TestRun> element of a TRX file
+type trxTestRun struct {
+	Results         []trxUnitTestResult `xml:"Results>UnitTestResult"`
+	TestDefinitions []trxUnitTest       `xml:"TestDefinitions>UnitTest"`
+}
+
+// trxUnitTestResult is the outcome of a single test execution
+type trxUnitTestResult struct {
+	TestID   string `xml:"testId,attr"`
+	TestName string `xml:"testName,attr"`
+	Duration string `xml:"duration,attr"`
+	Outcome  string `xml:"outcome,attr"`
+	Output   struct {
+		StdOut    string `xml:"StdOut"`
+		StdErr    string `xml:"StdErr"`
+		ErrorInfo *struct {
+			Message    string `xml:"Message"`
+			StackTrace string `xml:"StackTrace"`
+		} `xml:"ErrorInfo"`
+	} `xml:"Output"`
+}
+
+// trxUnitTest describes a test: the assembly it lives in and its declaring class
+type trxUnitTest struct {
+	ID         string `xml:"id,attr"`
+	Name       string `xml:"name,attr"`
+	Storage    string `xml:"storage,attr"`
+	TestMethod struct {
+		CodeBase  string `xml:"codeBase,attr"`
+		ClassName string `xml:"className,attr"`
+		Name      string `xml:"name,attr"`
+	} `xml:"TestMethod"`
+}
+
+// trxGroupResult accumulates test counts for a group within one TRX file
+type trxGroupResult struct {
+	hierarchy []string
+	passed    int
+	failed    int
+	skipped   int
+	duration  float64 // Seconds
+}
+
+// NewDotnetTestDefinition creates a new dotnet test runner definition
+func NewDotnetTestDefinition(logger *logger.FileLogger) *DotnetTestDefinition {
+	return &DotnetTestDefinition{
+		logger:     logger,
+		processed:  make(map[string]bool),
+		discovered: make(map[string]bool),
+	}
+}
+
+// Name returns the name of this test runner
+func (d *DotnetTestDefinition) Name() string {
+	return "dotnet"
+}
+
+// Detect checks if the command is for dotnet test
+func (d *DotnetTestDefinition) Detect(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+
+	switch commandBaseName(args[0]) {
+	case "dotnet", "dotnet.exe":
+		return args[1] == "test"
+	default:
+		return false
+	}
+}
+
+// ModifyCommand adds a TRX logger and a results directory to the dotnet test command.
+// The flags go before any "--" separator, after which arguments are RunSettings.
+func (d *DotnetTestDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	resultsDir := d.ensureResultsDir(ipcPath)
+
+	// LogFilePrefix rather than LogFileName, since a fixed file name is overwritten
+	// by each project when testing a solution
+	injected := []string{"--results-directory", resultsDir, "--logger", "trx;LogFilePrefix=3pio"}
+
+	result := make([]string, 0, len(cmd)+len(injected))
+	separator := len(cmd)
+	for i, arg := range cmd {
+		if arg == "--" {
+			separator = i
+			break
+		}
+	}
+	result = append(result, cmd[:separator]...)
+	result = append(result, injected...)
+	result = append(result, cmd[separator:]...)
+	return result
+}
+
+// ensureResultsDir picks the TRX results directory once, so repeated command builds agree.
+// It lives in the run directory when the IPC path is known, otherwise in a temporary directory.
+func (d *DotnetTestDefinition) ensureResultsDir(ipcPath string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.resultsDir != "" {
+		return d.resultsDir
+	}
+
+	if ipcPath != "" {
+		d.resultsDir = filepath.Join(filepath.Dir(ipcPath), "trx")
+		return d.resultsDir
+	}
+
+	dir, err := os.MkdirTemp("", "3pio-trx-")
+	if err != nil {
+		d.logger.Error("Failed to create TRX results directory: %v", err)
+		dir = filepath.Join(os.TempDir(), "3pio-trx")
+	}
+	d.resultsDir = dir
+	d.tempResultsDir = true
+	return d.resultsDir
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (d *DotnetTestDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as dotnet test results come from TRX files
+func (d *DotnetTestDefinition) RequiresAdapter() bool {
+	return false
+}
+
+// ProcessOutput drains the dotnet test console output and converts TRX files into IPC events.
+// It returns once the output reaches EOF (the process has exited) and a final scan is done.
+func (d *DotnetTestDefinition) ProcessOutput(output io.Reader, ipcPath string) error {
+	var err error
+	d.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC writer: %w", err)
+	}
+	defer func() {
+		if err := d.ipcWriter.Close(); err != nil {
+			d.logger.Debug("Failed to close IPC writer: %v", err)
+		}
+	}()
+
+	resultsDir := d.ensureResultsDir(ipcPath)
+	if d.tempResultsDir {
+		defer func() {
+			if err := os.RemoveAll(resultsDir); err != nil {
+				d.logger.Debug("Failed to remove TRX results directory: %v", err)
+			}
+		}()
+	}
+
+	outputDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, output)
+		outputDone <- err
+	}()
+
+	ticker := time.NewTicker(trxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.scanResults(resultsDir, false)
+		case readErr := <-outputDone:
+			d.scanResults(resultsDir, true)
+			if readErr != nil {
+				return fmt.Errorf("error reading output: %w", readErr)
+			}
+			return nil
+		}
+	}
+}
+
+// scanResults converts TRX files that have not been processed yet. A file that fails to
+// parse is assumed to still be in progress and retried, unless this is the final scan.
+func (d *DotnetTestDefinition) scanResults(resultsDir string, final bool) {
+	files, err := filepath.Glob(filepath.Join(resultsDir, "*.trx"))
+	if err != nil {
+		d.logger.Debug("Failed to list TRX files: %v", err)
+		return
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		if d.processed[path] {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			d.logger.Debug("Failed to read TRX file %s: %v", path, err)
+			continue
+		}
+		var run trxTestRun
+		if err := xml.Unmarshal(data, &run); err != nil {
+			if final {
+				d.logger.Error("Failed to parse TRX file %s: %v", path, err)
+				d.processed[path] = true
+			} else {
+				d.logger.Debug("TRX file %s not ready yet: %v", path, err)
+			}
+			continue
+		}
+
+		d.logger.Debug("Processing TRX file: %s (%d results)", path, len(run.Results))
+		d.processed[path] = true
+		d.processTestRun(run)
+	}
+}
+
+// processTestRun sends test case events for every result, then group results deepest first
+func (d *DotnetTestDefinition) processTestRun(run trxTestRun) {
+	definitions := make(map[string]trxUnitTest, len(run.TestDefinitions))
+	for _, def := range run.TestDefinitions {
+		definitions[def.ID] = def
+	}
+
+	groups := make(map[string]*trxGroupResult)
+	for _, result := range run.Results {
+		hierarchy, testName := trxTestHierarchy(result, definitions[result.TestID])
+		d.ensureGroups(hierarchy)
+
+		status := trxOutcomeStatus(result.Outcome)
+		duration := parseTRXDuration(result.Duration)
+
+		// Every enclosing group counts this test
+		for i := range hierarchy {
+			key := strings.Join(hierarchy[:i+1], "\x00")
+			group, exists := groups[key]
+			if !exists {
+				group = &trxGroupResult{hierarchy: hierarchy[:i+1]}
+				groups[key] = group
+			}
+			switch status {
+			case "PASS":
+				group.passed++
+			case "FAIL":
+				group.failed++
+			case "SKIP":
+				group.skipped++
+			}
+			group.duration += duration
+		}
+
+		d.sendTestCase(testName, hierarchy, status, duration, result)
+	}
+
+	// A TRX file covers a whole test project, so all of its groups are complete
+	ordered := make([]*trxGroupResult, 0, len(groups))
+	for _, group := range groups {
+		ordered = append(ordered, group)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if len(ordered[i].hierarchy) != len(ordered[j].hierarchy) {
+			return len(ordered[i].hierarchy) > len(ordered[j].hierarchy)
+		}
+		return strings.Join(ordered[i].hierarchy, "\x00") < strings.Join(ordered[j].hierarchy, "\x00")
+	})
+	for _, group := range ordered {
+		status := "PASS"
+		if group.failed > 0 {
+			status = "FAIL"
+		} else if group.skipped > 0 && group.passed == 0 {
+			status = "SKIP"
+		}
+		totals := map[string]interface{}{
+			"total":   group.passed + group.failed + group.skipped,
+			"passed":  group.passed,
+			"failed":  group.failed,
+			"skipped": group.skipped,
+		}
+		name := group.hierarchy[len(group.hierarchy)-1]
+		d.sendGroupResult(name, group.hierarchy[:len(group.hierarchy)-1], status, group.duration, totals)
+	}
+}
+
+// ensureGroups sends discovery and start events for each level of the hierarchy once
+func (d *DotnetTestDefinition) ensureGroups(hierarchy []string) {
+	for i := range hierarchy {
+		key := strings.Join(hierarchy[:i+1], "\x00")
+		if d.discovered[key] {
+			continue
+		}
+		d.discovered[key] = true
+		d.sendGroupEvent("testGroupDiscovered", hierarchy[i], hierarchy[:i])
+		d.sendGroupEvent("testGroupStart", hierarchy[i], hierarchy[:i])
+	}
+}
+
+// trxTestHierarchy returns [assembly, namespace parts..., class] and the test name for a result.
+// The test name keeps any parameters, e.g. "Adds(a: 1, b: 2)" for a theory.
+func trxTestHierarchy(result trxUnitTestResult, def trxUnitTest) ([]string, string) {
+	assembly := def.TestMethod.CodeBase
+	if assembly == "" {
+		assembly = def.Storage
+	}
+	assembly = strings.TrimSuffix(commandBaseName(assembly), filepath.Ext(assembly))
+	if assembly == "" {
+		assembly = "tests"
+	}
+
+	className := def.TestMethod.ClassName
+	testName := result.TestName
+	if className == "" {
+		// Without a definition, split the method off the fully-qualified name,
+		// ignoring dots inside parameter lists
+		qualified := testName
+		if idx := strings.Index(qualified, "("); idx != -1 {
+			qualified = qualified[:idx]
+		}
+		if idx := strings.LastIndex(qualified, "."); idx != -1 {
+			className = qualified[:idx]
+		}
+	}
+	testName = strings.TrimPrefix(testName, className+".")
+
+	hierarchy := []string{assembly}
+	if className != "" {
+		hierarchy = append(hierarchy, strings.Split(className, ".")...)
+	}
+	return hierarchy, testName
+}
+
+// trxOutcomeStatus maps a TRX outcome to a 3pio test status
+func trxOutcomeStatus(outcome string) string {
+	switch outcome {
+	case "Passed", "PassedButRunAborted", "Warning":
+		return "PASS"
+	case "NotExecuted", "Inconclusive", "NotRunnable", "Pending", "Disconnected":
+		return "SKIP"
+	default:
+		// Failed, Error, Timeout, Aborted
+		return "FAIL"
+	}
+}
+
+// parseTRXDuration parses a TRX duration ("hh:mm:ss.fffffff") into seconds
+func parseTRXDuration(value string) float64 {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err1 := strconv.ParseFloat(parts[0], 64)
+	minutes, err2 := strconv.ParseFloat(parts[1], 64)
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0
+	}
+	return hours*3600 + minutes*60 + seconds
+}
+
+// IPC event sending methods
+
+func (d *DotnetTestDefinition) sendGroupEvent(eventType, groupName string, parentNames []string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+		},
+	}
+	if err := d.ipcWriter.WriteEvent(event); err != nil {
+		d.logger.Error("Failed to send %s: %v", eventType, err)
+	}
+}
+
+func (d *DotnetTestDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
+	event := map[string]interface{}{
+		"eventType": "testGroupResult",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"status":      status,
+			"duration":    duration * 1000, // Convert seconds to milliseconds
+			"totals":      totals,
+		},
+	}
+	if err := d.ipcWriter.WriteEvent(event); err != nil {
+		d.logger.Error("Failed to send testGroupResult: %v", err)
+	}
+}
+
+func (d *DotnetTestDefinition) sendTestCase(testName string, parentNames []string, status string, duration float64, result trxUnitTestResult) {
+	payload := map[string]interface{}{
+		"testName":    testName,
+		"parentNames": parentNames,
+		"status":      status,
+		"duration":    int64(duration * 1000), // Convert to milliseconds
+	}
+	if status == "FAIL" && result.Output.ErrorInfo != nil {
+		payload["error"] = map[string]interface{}{
+			"message": strings.TrimSpace(result.Output.ErrorInfo.Message),
+			"stack":   strings.TrimSpace(result.Output.ErrorInfo.StackTrace),
+		}
+	}
+	if result.Output.StdOut != "" {
+		payload["stdout"] = result.Output.StdOut
+	}
+	if result.Output.StdErr != "" {
+		payload["stderr"] = result.Output.StdErr
+	}
+
+	event := map[string]interface{}{
+		"eventType": "testCase",
+		"payload":   payload,
+	}
+	if err := d.ipcWriter.WriteEvent(event); err != nil {
+		d.logger.Debug("Failed to write test case event: %v", err)
+	}
+}
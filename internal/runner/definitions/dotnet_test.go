@@ -0,0 +1,186 @@
+package definitions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+const sampleTRX = `<?xml version="1.0" encoding="utf-8"?>
+<TestRun id="1" name="run" xmlns="http://microsoft.com/schemas/VisualStudio/TeamTest/2010">
+  <Results>
+    <UnitTestResult testId="a" testName="Acme.Math.CalculatorTests.Adds" duration="00:00:00.0123000" outcome="Passed" />
+    <UnitTestResult testId="b" testName="Acme.Math.CalculatorTests.Divides(x: 1.5)" duration="00:00:01.5000000" outcome="Failed">
+      <Output>
+        <StdOut>dividing</StdOut>
+        <ErrorInfo>
+          <Message>Assert.Equal() Failure
+Expected: 1
+Actual:   2</Message>
+          <StackTrace>   at Acme.Math.CalculatorTests.Divides(Double x) in /src/CalculatorTests.cs:line 21</StackTrace>
+        </ErrorInfo>
+      </Output>
+    </UnitTestResult>
+    <UnitTestResult testId="c" testName="Acme.Math.CalculatorTests.Subtracts" duration="00:00:00" outcome="NotExecuted" />
+  </Results>
+  <TestDefinitions>
+    <UnitTest name="Acme.Math.CalculatorTests.Adds" storage="/src/bin/Debug/net8.0/acme.tests.dll" id="a">
+      <TestMethod codeBase="/src/bin/Debug/net8.0/Acme.Tests.dll" className="Acme.Math.CalculatorTests" name="Adds" />
+    </UnitTest>
+    <UnitTest name="Acme.Math.CalculatorTests.Divides(x: 1.5)" storage="/src/bin/Debug/net8.0/acme.tests.dll" id="b">
+      <TestMethod codeBase="/src/bin/Debug/net8.0/Acme.Tests.dll" className="Acme.Math.CalculatorTests" name="Divides" />
+    </UnitTest>
+    <UnitTest name="Acme.Math.CalculatorTests.Subtracts" storage="/src/bin/Debug/net8.0/acme.tests.dll" id="c">
+      <TestMethod codeBase="/src/bin/Debug/net8.0/Acme.Tests.dll" className="Acme.Math.CalculatorTests" name="Subtracts" />
+    </UnitTest>
+  </TestDefinitions>
+</TestRun>
+`
+
+func TestDotnetTestDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewDotnetTestDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"dotnet test", []string{"dotnet", "test"}, true},
+		{"dotnet test project", []string{"dotnet", "test", "Acme.Tests.csproj"}, true},
+		{"full path", []string{"/usr/share/dotnet/dotnet", "test"}, true},
+		{"dotnet build", []string{"dotnet", "build"}, false},
+		{"no subcommand", []string{"dotnet"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDotnetTestDefinition_ModifyCommand(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewDotnetTestDefinition(lg)
+
+	ipcPath := filepath.Join("run", "ipc.jsonl")
+	resultsDir := filepath.Join("run", "trx")
+
+	got := def.ModifyCommand([]string{"dotnet", "test", "--", "RunConfiguration.MaxCpuCount=1"}, ipcPath, "")
+	expected := []string{"dotnet", "test", "--results-directory", resultsDir, "--logger", "trx;LogFilePrefix=3pio", "--", "RunConfiguration.MaxCpuCount=1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// Later builds without an IPC path must reuse the same results directory
+	got = def.ModifyCommand([]string{"dotnet", "test"}, "", "")
+	if got[3] != resultsDir {
+		t.Errorf("Expected results directory %s to be reused, got %v", resultsDir, got)
+	}
+}
+
+func TestTRXTestHierarchy(t *testing.T) {
+	def := trxUnitTest{}
+	def.TestMethod.CodeBase = `C:\src\bin\Acme.Tests.dll`
+	def.TestMethod.ClassName = "Acme.Math.CalculatorTests"
+
+	hierarchy, name := trxTestHierarchy(trxUnitTestResult{TestName: "Acme.Math.CalculatorTests.Adds(a: 1.5)"}, def)
+	if !reflect.DeepEqual(hierarchy, []string{"Acme.Tests", "Acme", "Math", "CalculatorTests"}) {
+		t.Errorf("Unexpected hierarchy: %v", hierarchy)
+	}
+	if name != "Adds(a: 1.5)" {
+		t.Errorf("Expected test name with parameters, got %q", name)
+	}
+
+	// Without a definition the class is taken from the fully-qualified test name
+	hierarchy, name = trxTestHierarchy(trxUnitTestResult{TestName: "Acme.CalculatorTests.Adds(a: 1.5)"}, trxUnitTest{})
+	if !reflect.DeepEqual(hierarchy, []string{"tests", "Acme", "CalculatorTests"}) || name != "Adds(a: 1.5)" {
+		t.Errorf("Unexpected fallback hierarchy %v and name %q", hierarchy, name)
+	}
+}
+
+func TestParseTRXDuration(t *testing.T) {
+	if got := parseTRXDuration("01:02:03.5000000"); got != 3723.5 {
+		t.Errorf("Expected 3723.5, got %v", got)
+	}
+	if got := parseTRXDuration("bogus"); got != 0 {
+		t.Errorf("Expected 0 for invalid duration, got %v", got)
+	}
+}
+
+func TestDotnetTestDefinition_ProcessOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewDotnetTestDefinition(lg)
+
+	ipcPath := filepath.Join(tempDir, "ipc.jsonl")
+	command := def.ModifyCommand([]string{"dotnet", "test"}, ipcPath, "")
+	resultsDir := command[3]
+	if err := os.MkdirAll(resultsDir, 0755); err != nil {
+		t.Fatalf("Failed to create results dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(resultsDir, "3pio_net8.0.trx"), []byte(sampleTRX), 0644); err != nil {
+		t.Fatalf("Failed to write TRX file: %v", err)
+	}
+
+	if err := def.ProcessOutput(strings.NewReader("Passed!  - Failed: 1\n"), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	testStatuses := make(map[string]string)
+	groupResults := make(map[string]string)
+	var failure map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			name := event.Payload["testName"].(string)
+			testStatuses[name] = event.Payload["status"].(string)
+			if name == "Divides(x: 1.5)" {
+				failure, _ = event.Payload["error"].(map[string]interface{})
+			}
+		case "testGroupResult":
+			groupResults[event.Payload["groupName"].(string)] = event.Payload["status"].(string)
+		}
+	}
+
+	expectedStatuses := map[string]string{
+		"Adds":            "PASS",
+		"Divides(x: 1.5)": "FAIL",
+		"Subtracts":       "SKIP",
+	}
+	if !reflect.DeepEqual(testStatuses, expectedStatuses) {
+		t.Errorf("Expected statuses %v, got %v", expectedStatuses, testStatuses)
+	}
+	if failure == nil || !strings.HasPrefix(failure["message"].(string), "Assert.Equal() Failure") ||
+		!strings.Contains(failure["stack"].(string), "CalculatorTests.cs:line 21") {
+		t.Errorf("Expected failure message and stack trace, got %v", failure)
+	}
+	for _, group := range []string{"Acme.Tests", "Acme", "Math", "CalculatorTests"} {
+		if groupResults[group] != "FAIL" {
+			t.Errorf("Expected group %s to fail, got %q", group, groupResults[group])
+		}
+	}
+}
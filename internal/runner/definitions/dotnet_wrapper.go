@@ -0,0 +1,54 @@
+package definitions
+
+// DotnetTestWrapper wraps DotnetTestDefinition to implement the Definition interface from runner package
+type DotnetTestWrapper struct {
+	*DotnetTestDefinition
+}
+
+// NewDotnetTestWrapper creates a new wrapper for dotnet test
+func NewDotnetTestWrapper(impl *DotnetTestDefinition) *DotnetTestWrapper {
+	return &DotnetTestWrapper{DotnetTestDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *DotnetTestWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *DotnetTestWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.DotnetTestDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; dotnet test needs no adapter
+func (w *DotnetTestWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as dotnet test doesn't use an adapter
+func (w *DotnetTestWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *DotnetTestWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *DotnetTestWrapper) ReproCommand(failures []FailedTest) string {
+	return w.DotnetTestDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as dotnet test results are processed without an adapter
+func (w *DotnetTestWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying dotnet test definition
+func (w *DotnetTestWrapper) GetNativeDefinition() interface{} {
+	return w.DotnetTestDefinition
+}
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/logger"
 )
 
@@ -31,13 +36,49 @@ type GoTestDefinition struct {
 	packageGroups     map[string]*PackageGroupInfo // Track package-level group info
 	packageResultSent map[string]bool              // Track if result has been sent for package
 	packageErrors     map[string][]string          // Buffer package-level error output
+	packagePanics     map[string][]string          // Package-level output from a "panic:" line onwards
+	packageCoverage   map[string]string            // Coverage summary from -cover, e.g. "87.5% of statements"
+	buildOutput       map[string][]string          // Compiler output per package ("" when unattributed)
+	nonJSONPackage    string                       // Package named by the last "# package" header in non-JSON output
+	failedBuilds      map[string]bool              // Packages whose output said "[build failed]"
 
 	// Group tracking for universal abstractions
 	discoveredGroups map[string]bool           // Track discovered groups to avoid duplicates
 	groupStarts      map[string]bool           // Track started groups
 	subgroupStats    map[string]*SubgroupStats // Track test counts and timing for subgroups
+
+	// Benchmarks never get a pass event, so results are reported from their output lines
+	benchmarksReported map[string]bool // Track benchmarks (package/test) whose result was sent
+
+	// go test -count=N runs each test N times, reported as one test case with N runs
+	testRuns map[string]*repeatedTest // Runs of each test (package/test) so far
+
+	// Optional test-to-file mapping from go list (see EnableFileMapping)
+	fileMapping bool                         // Whether EnableFileMapping was called
+	testFiles   map[string]map[string]string // Package to top-level test to file name, nil until go list finishes
+	testFileFor map[string]string            // File chosen for each package/top-level test, fixed at first lookup
+	fileGroups  map[string][]string          // File groups started per package, finalized with the package
+
+	// Ginkgo suites reported as their spec hierarchy, read from Ginkgo's JSON report
+	ginkgoForced    bool              // --ginkgo turns Ginkgo mode on without detection
+	ginkgo          bool              // Ginkgo mode is on for the current command
+	ginkgoReport    string            // Report path the suites write, relative to their package
+	ginkgoOwnReport bool              // The report flag was added by 3pio, so reports are removed once read
+	ginkgoSuites    map[string]string // Package to the top-level test that ran its Ginkgo suite
+
+	verbose bool // The command runs with -v, so passing tests' output is reported too
 }
 
+// benchmarkLineRegex matches a benchmark result line, e.g.
+// "BenchmarkFoo-8   1000000   1053 ns/op   48 B/op   1 allocs/op"
+var benchmarkLineRegex = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+([\d.]+) ns/op`)
+
+// benchmarkMemRegex matches the -benchmem columns of a benchmark result line
+var benchmarkMemRegex = regexp.MustCompile(`(\d+) B/op\s+(\d+) allocs/op`)
+
+// benchmarkProcsSuffixRegex matches the GOMAXPROCS suffix go appends to benchmark names
+var benchmarkProcsSuffixRegex = regexp.MustCompile(`-\d+$`)
+
 // PackageInfo removed - no longer using go list for package metadata
 
 // TestState tracks the state of a running test
@@ -58,12 +99,14 @@ type TestInfo struct {
 
 // GoTestEvent represents a single event from go test -json output
 type GoTestEvent struct {
-	Time    time.Time `json:"Time"`
-	Action  string    `json:"Action"`
-	Package string    `json:"Package"`
-	Test    string    `json:"Test,omitempty"`
-	Output  string    `json:"Output,omitempty"`
-	Elapsed float64   `json:"Elapsed,omitempty"`
+	Time        time.Time `json:"Time"`
+	Action      string    `json:"Action"`
+	Package     string    `json:"Package"`
+	Test        string    `json:"Test,omitempty"`
+	Output      string    `json:"Output,omitempty"`
+	Elapsed     float64   `json:"Elapsed,omitempty"`
+	ImportPath  string    `json:"ImportPath,omitempty"`  // Set on build-output and build-fail events
+	FailedBuild string    `json:"FailedBuild,omitempty"` // Set on a package's fail event when its build failed
 }
 
 // PackageGroupInfo tracks information for a package group
@@ -82,12 +125,38 @@ type SubgroupStats struct {
 	StartTime    time.Time
 	Duration     float64 // in seconds
 	Status       string
+
+	// Wall-clock span of the group's tests, so overlapping tests are not counted twice
+	FirstRun   time.Time // Earliest run event of a test in the group
+	LastResult time.Time // Latest pass/fail/skip event of a test in the group
+}
+
+// extendSpan widens the group's wall-clock span to cover a test that ran from start to end
+func (s *SubgroupStats) extendSpan(start, end time.Time) {
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	if s.FirstRun.IsZero() || start.Before(s.FirstRun) {
+		s.FirstRun = start
+	}
+	if end.After(s.LastResult) {
+		s.LastResult = end
+	}
+}
+
+// wallClock returns the seconds from the group's first run event to its last result,
+// or fallback when the events carried no usable times
+func (s *SubgroupStats) wallClock(fallback float64) float64 {
+	if s.FirstRun.IsZero() || !s.LastResult.After(s.FirstRun) {
+		return fallback
+	}
+	return s.LastResult.Sub(s.FirstRun).Seconds()
 }
 
 // IPCWriter handles writing IPC events
 type IPCWriter struct {
 	path string
-	file *os.File
+	out  io.WriteCloser // IPC file, or a connection when path uses ipc.SocketScheme
 	mu   sync.Mutex
 }
 
@@ -106,9 +175,21 @@ func NewGoTestDefinition(logger *logger.FileLogger) *GoTestDefinition {
 		packageGroups:     make(map[string]*PackageGroupInfo),
 		packageResultSent: make(map[string]bool),
 		packageErrors:     make(map[string][]string),
+		packagePanics:     make(map[string][]string),
+		packageCoverage:   make(map[string]string),
+		buildOutput:       make(map[string][]string),
+		failedBuilds:      make(map[string]bool),
 		discoveredGroups:  make(map[string]bool),
 		groupStarts:       make(map[string]bool),
 		subgroupStats:     make(map[string]*SubgroupStats),
+
+		benchmarksReported: make(map[string]bool),
+		testRuns:           make(map[string]*repeatedTest),
+
+		testFileFor: make(map[string]string),
+		fileGroups:  make(map[string][]string),
+
+		ginkgoSuites: make(map[string]string),
 	}
 }
 
@@ -136,11 +217,17 @@ func (g *GoTestDefinition) Detect(args []string) bool {
 	return false
 }
 
-// ModifyCommand ensures the -json flag is present in the go test command
+// ModifyCommand ensures the -json flag is present in the go test command, and in Ginkgo
+// mode that the suites write a JSON report
 func (g *GoTestDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
-	result := make([]string, 0, len(cmd)+1)
+	result := make([]string, 0, len(cmd)+2)
 	hasJSON := false
 
+	g.mu.Lock()
+	g.verbose = isGoTestVerbose(cmd)
+	g.mu.Unlock()
+	ginkgoFlags := g.setGinkgoMode(cmd)
+
 	// Check if -json flag already exists
 	for _, arg := range cmd {
 		if arg == "-json" {
@@ -158,7 +245,8 @@ func (g *GoTestDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []
 		}
 	}
 
-	return result
+	// Flags after the packages still reach the test binaries
+	return append(result, ginkgoFlags...)
 }
 
 // GetTestFiles extracts test files from command arguments or uses go list
@@ -225,11 +313,12 @@ func (g *GoTestDefinition) ProcessOutput(stdout io.Reader, ipcPath string) error
 		// Try to parse as JSON
 		var event GoTestEvent
 		if err := json.Unmarshal(line, &event); err != nil {
-			// Non-JSON line (likely build error)
+			// Non-JSON line (likely build error), kept in case a package fails to build
 			g.logger.Debug("Non-JSON output: %s", string(line))
-			// Non-JSON output no longer sent as events - handled by group events
+			g.recordNonJSONLine(string(line))
 			continue
 		}
+		g.endNonJSONBlock()
 
 		// Process the event
 		if err := g.processEvent(&event); err != nil {
@@ -303,8 +392,15 @@ func (g *GoTestDefinition) processEvent(event *GoTestEvent) error {
 		g.handleOutput(event)
 
 	case "bench":
-		// Benchmark result (not supported yet)
-		g.logger.Debug("Benchmark event (not supported): %+v", event)
+		// Benchmark output (older Go versions report result lines with this action)
+		g.handleBenchmarkOutput(event)
+
+	case "build-output":
+		// Compiler output (Go 1.24+), reported with the package if its build fails
+		g.recordBuildOutput(event)
+
+	case "build-fail":
+		g.logger.Debug("Build failed: %s", event.ImportPath)
 	}
 
 	return nil
@@ -404,7 +500,20 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	g.recordTestResult(event)
+}
+
+// recordTestResult sends a test result and updates group and package tracking.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) recordTestResult(event *GoTestEvent) {
 	key := fmt.Sprintf("%s/%s", event.Package, event.Test)
+
+	// Benchmark results were already sent from their output line
+	if g.benchmarksReported[key] {
+		delete(g.testStates, key)
+		return
+	}
+
 	state, ok := g.testStates[key]
 	if !ok {
 		// Create state if it doesn't exist
@@ -426,6 +535,18 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 
 	// Parse the test hierarchy (handle subtests with "/" separator)
 	suiteChain, finalTestName := g.parseTestHierarchy(event.Test)
+	topLevel := len(suiteChain) == 0
+
+	// A Ginkgo suite is reported as its specs instead
+	if topLevel && g.ginkgo && g.recordGinkgoSuite(event, state) {
+		return
+	}
+
+	// With go list file mapping, the test file is a group between the package and the test
+	if filePath != "" {
+		suiteChain = append([]string{filePath}, suiteChain...)
+		g.trackFileGroup(event.Package, filePath)
+	}
 
 	// Ensure all parent groups are discovered and started
 	g.ensureGroupsDiscovered(event.Package, suiteChain)
@@ -439,9 +560,27 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 	// Build complete hierarchy for this test case using package
 	parentNames := g.buildHierarchyFromPackage(event.Package, suiteChain)
 
-	// Send test case event with group hierarchy
-	outputStr := strings.Join(state.Output, "\n")
-	g.sendTestCaseWithGroups(finalTestName, parentNames, status, event.Elapsed, outputStr)
+	// Send test case event with group hierarchy. With -count=N, a test that failed on an
+	// earlier run is reported as failed.
+	var testError *ipc.TestError
+	if status == "FAIL" {
+		testError = goTestError(state.Output)
+	}
+	status, testError, runMetadata := g.recordRun(key, status, testError)
+	var stdout, skipReason string
+	if g.verbose {
+		stdout = goTestStdout(state.Output)
+	}
+	if status == "SKIP" {
+		skipReason = goTestSkipReason(state.Output)
+	}
+	g.sendTestCaseWithGroups(finalTestName, parentNames, status, event.Elapsed, testError, runMetadata, stdout, skipReason)
+
+	// When the test ran, for the wall-clock duration of its groups
+	startTime := state.StartTime
+	if startTime.IsZero() && !event.Time.IsZero() {
+		startTime = event.Time.Add(-time.Duration(event.Elapsed * float64(time.Second)))
+	}
 
 	// Track subgroup statistics for parent groups
 	if len(suiteChain) > 0 {
@@ -457,6 +596,7 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 			}
 
 			stats := g.subgroupStats[groupKey]
+			stats.extendSpan(startTime, event.Time)
 			stats.TotalTests++
 			switch status {
 			case "PASS":
@@ -476,12 +616,19 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 			}
 		}
 
+		// Top-level tests count towards their file group's duration when event times are missing
+		if topLevel {
+			g.subgroupStats[event.Package+"/"+filePath].Duration += event.Elapsed
+		}
+
 		// Check if this subtest itself is a parent group (has further subtests)
 		// If it is, send a group result for it
-		groupKey := event.Package + "/" + event.Test
+		groupKey := strings.Join(append(append([]string{event.Package}, suiteChain...), finalTestName), "/")
 		if stats, exists := g.subgroupStats[groupKey]; exists {
-			// This subtest has its own subtests, send group result for it
-			stats.Duration = event.Elapsed
+			// This subtest has its own subtests, send group result for it. Its duration runs
+			// from its own run event to the last result, which may be a parallel subtest's.
+			stats.extendSpan(startTime, event.Time)
+			stats.Duration = stats.wallClock(event.Elapsed)
 
 			// Determine final status
 			if stats.Status == "" {
@@ -493,13 +640,16 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 					stats.Status = "SKIP"
 				}
 			}
+			if status == "FAIL" {
+				stats.Status = "FAIL" // An earlier run failed (-count=N)
+			}
 
 			// Send group result for this subgroup
-			totals := map[string]interface{}{
-				"total":   stats.TotalTests,
-				"passed":  stats.PassedTests,
-				"failed":  stats.FailedTests,
-				"skipped": stats.SkippedTests,
+			totals := ipc.GroupTotals{
+				Total:   stats.TotalTests,
+				Passed:  stats.PassedTests,
+				Failed:  stats.FailedTests,
+				Skipped: stats.SkippedTests,
 			}
 
 			// Build parent names for this subgroup
@@ -513,7 +663,8 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 		groupKey := event.Package + "/" + event.Test
 		if stats, exists := g.subgroupStats[groupKey]; exists {
 			// This test has subtests, send group result for it
-			stats.Duration = event.Elapsed
+			stats.extendSpan(startTime, event.Time)
+			stats.Duration = stats.wallClock(event.Elapsed)
 
 			// Determine final status
 			if stats.Status == "" {
@@ -525,13 +676,16 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 					stats.Status = "SKIP"
 				}
 			}
+			if status == "FAIL" {
+				stats.Status = "FAIL" // An earlier run failed (-count=N)
+			}
 
 			// Send group result for this subgroup
-			totals := map[string]interface{}{
-				"total":   stats.TotalTests,
-				"passed":  stats.PassedTests,
-				"failed":  stats.FailedTests,
-				"skipped": stats.SkippedTests,
+			totals := ipc.GroupTotals{
+				Total:   stats.TotalTests,
+				Passed:  stats.PassedTests,
+				Failed:  stats.FailedTests,
+				Skipped: stats.SkippedTests,
 			}
 
 			// The parent names for this group are just [package]
@@ -546,7 +700,7 @@ func (g *GoTestDefinition) handleTestResult(event *GoTestEvent) {
 	delete(g.testStates, key)
 
 	// Track test in package group (only top-level tests, not subtests)
-	if len(suiteChain) == 0 {
+	if topLevel && !g.recordRepeatedPackageTest(event.Package, finalTestName, status, event.Elapsed) {
 		// This is a top-level test (no parent hierarchy)
 		if pkgGroup, ok := g.packageGroups[event.Package]; ok {
 			pkgGroup.Tests = append(pkgGroup.Tests, TestInfo{
@@ -605,54 +759,54 @@ func (g *GoTestDefinition) handlePackageResult(event *GoTestEvent) {
 			g.packageStarted[event.Package] = true
 		}
 
+		// Tests whose goroutine panicked never get a result event of their own
+		packagePanic := g.failPanickedTests(event)
+
+		// Benchmark parent groups and file groups never receive a result event of their own
+		g.finalizeBenchmarkGroups(event.Package)
+		g.finalizeFileGroups(event.Package)
+
 		// Send the package group result
 		status := strings.ToUpper(event.Action)
 
 		// Calculate totals from tracked tests
-		totals := map[string]interface{}{
-			"total":   0,
-			"passed":  0,
-			"failed":  0,
-			"skipped": 0,
-		}
-
-		// If we have a package group with tests, use those totals
-		if pkgGroup, ok := g.packageGroups[event.Package]; ok && len(pkgGroup.Tests) > 0 {
-			totals["total"] = len(pkgGroup.Tests)
-			for _, test := range pkgGroup.Tests {
-				switch test.Status {
-				case "PASS":
-					totals["passed"] = totals["passed"].(int) + 1
-				case "FAIL":
-					totals["failed"] = totals["failed"].(int) + 1
-				case "SKIP":
-					totals["skipped"] = totals["skipped"].(int) + 1
-				}
-			}
+		var totals ipc.GroupTotals
+		if pkgGroup, ok := g.packageGroups[event.Package]; ok {
+			totals = testTotals(pkgGroup.Tests)
 		}
 
 		// Check if this is a package with no test files
 		if pkgGroup, ok := g.packageGroups[event.Package]; ok {
-			if pkgGroup.NoTestFiles && status == "SKIP" {
+			// With -cover, go test reports packages without test files as passed
+			if pkgGroup.NoTestFiles && (status == "SKIP" || status == "PASS") {
 				// Mark this specially so orchestrator can display ???
 				status = "NOTESTS"
 			}
 		}
 
-		// Detect setup failures and send testGroupError event
-		if event.Action == "fail" && totals["total"].(int) == 0 {
+		// Detect package-scope panics and setup failures and send testGroupError event
+		if len(packagePanic) > 0 {
+			message, stack, _ := splitGoPanic(packagePanic)
+			g.sendGroupError(event.Package, []string{}, goPanicErrorType, event.Elapsed, message, stack)
+			if totals.Total == 0 {
+				totals.SetupFailed = true
+			}
+		} else if g.buildFailed(event) {
+			g.sendGroupError(event.Package, []string{}, goBuildFailureErrorType, event.Elapsed, g.buildFailureMessage(event.Package), "")
+			totals.SetupFailed = true
+		} else if event.Action == "fail" && totals.Total == 0 {
 			// This is a setup failure - construct error message
 			errorMessage := g.constructErrorMessage(event.Package)
 
 			// Send testGroupError event
-			g.sendGroupError(event.Package, []string{}, "SETUP_FAILURE", event.Elapsed, errorMessage)
+			g.sendGroupError(event.Package, []string{}, "SETUP_FAILURE", event.Elapsed, errorMessage, "")
 
 			// Mark setupFailed in testGroupResult totals
-			totals["setupFailed"] = true
+			totals.SetupFailed = true
 		}
 
 		// Send GroupResult for the package
-		g.sendGroupResult(event.Package, []string{}, status, event.Elapsed, totals)
+		g.writeGroupResult(event.Package, []string{}, status, event.Elapsed, totals, g.packageResultMetadata(event.Package))
 
 		// Clear the started flag since we've sent the result
 		delete(g.packageStarted, event.Package)
@@ -661,7 +815,7 @@ func (g *GoTestDefinition) handlePackageResult(event *GoTestEvent) {
 		g.cleanupPackageErrors(event.Package)
 
 		g.logger.Debug("Sent package result for %s: status=%s, duration=%.2fs, tests=%d",
-			event.Package, status, event.Elapsed, totals["total"])
+			event.Package, status, event.Elapsed, totals.Total)
 	}
 }
 
@@ -670,6 +824,16 @@ func (g *GoTestDefinition) handleOutput(event *GoTestEvent) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	// Benchmark result lines are reported as test cases
+	if g.recordBenchmarkLine(event) {
+		return
+	}
+
+	// Coverage summaries are reported with the package result too
+	if event.Test == "" {
+		g.recordCoverageLine(event)
+	}
+
 	// If output is for a specific test, buffer it
 	if event.Test != "" {
 		key := fmt.Sprintf("%s/%s", event.Package, event.Test)
@@ -679,6 +843,14 @@ func (g *GoTestDefinition) handleOutput(event *GoTestEvent) {
 	} else {
 		// Package-level output processing
 
+		// A panic outside any test (e.g. in TestMain) is reported with the package
+		if g.recordPackagePanicOutput(event) {
+			return
+		}
+
+		g.recordBuildFailedLine(event)
+		g.recordPackageOutput(event)
+
 		// Filter and capture relevant error lines
 		output := strings.TrimSpace(event.Output)
 		if g.isErrorOutput(output) {
@@ -691,16 +863,7 @@ func (g *GoTestDefinition) handleOutput(event *GoTestEvent) {
 
 		// Check for "no test files" indicator
 		if strings.Contains(event.Output, "[no test files]") {
-			// Ensure package group exists
-			if _, exists := g.packageGroups[event.Package]; !exists {
-				g.packageGroups[event.Package] = &PackageGroupInfo{
-					StartTime:   event.Time,
-					Tests:       []TestInfo{},
-					NoTestFiles: true,
-				}
-			} else {
-				g.packageGroups[event.Package].NoTestFiles = true
-			}
+			g.markNoTestFiles(event)
 		}
 
 		// Package-level output - now handled by group events
@@ -712,17 +875,217 @@ func (g *GoTestDefinition) handleOutput(event *GoTestEvent) {
 	}
 }
 
-// getFilePathForTest maps a test to its source file - simplified to always use package-level mapping
+// markNoTestFiles records that a package has no test files. Caller must hold g.mu.
+func (g *GoTestDefinition) markNoTestFiles(event *GoTestEvent) {
+	// Ensure package group exists
+	if _, exists := g.packageGroups[event.Package]; !exists {
+		g.packageGroups[event.Package] = &PackageGroupInfo{
+			StartTime:   event.Time,
+			Tests:       []TestInfo{},
+			NoTestFiles: true,
+		}
+	} else {
+		g.packageGroups[event.Package].NoTestFiles = true
+	}
+}
+
+// handleBenchmarkOutput processes "bench" events
+func (g *GoTestDefinition) handleBenchmarkOutput(event *GoTestEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.recordBenchmarkLine(event) {
+		g.logger.Debug("Benchmark output without result: %s", strings.TrimSpace(event.Output))
+	}
+}
+
+// recordBenchmarkLine sends a test case for a benchmark result line.
+// Returns false if the output is not a benchmark result. Caller must hold g.mu.
+func (g *GoTestDefinition) recordBenchmarkLine(event *GoTestEvent) bool {
+	name, result, ok := parseBenchmarkLine(event.Output)
+	if !ok {
+		return false
+	}
+
+	// Prefer the test name from the event; the output line carries a GOMAXPROCS suffix
+	testName := event.Test
+	if testName == "" {
+		testName = name
+	}
+
+	key := fmt.Sprintf("%s/%s", event.Package, testName)
+	if g.benchmarksReported[key] {
+		// Same result line seen again (e.g. as both output and bench events)
+		return true
+	}
+	g.benchmarksReported[key] = true
+
+	// Benchmarks may be the first thing a package runs
+	if !g.packageStarted[event.Package] {
+		g.packageStarted[event.Package] = true
+		g.ensureGroupsDiscovered(event.Package, []string{})
+		g.ensureGroupStarted([]string{event.Package})
+		if _, exists := g.packageGroups[event.Package]; !exists {
+			g.packageGroups[event.Package] = &PackageGroupInfo{
+				StartTime: event.Time,
+				Tests:     []TestInfo{},
+			}
+		}
+	}
+
+	// Sub-benchmarks nest like subtests
+	suiteChain, finalTestName := g.parseTestHierarchy(testName)
+	g.ensureGroupsDiscovered(event.Package, suiteChain)
+	for i := 0; i <= len(suiteChain); i++ {
+		hierarchy := append([]string{event.Package}, suiteChain[:i]...)
+		g.ensureGroupStarted(hierarchy)
+	}
+	parentNames := g.buildHierarchyFromPackage(event.Package, suiteChain)
+
+	var elapsed float64
+	if state, ok := g.testStates[key]; ok && !state.StartTime.IsZero() && !event.Time.IsZero() {
+		elapsed = event.Time.Sub(state.StartTime).Seconds()
+	}
+	delete(g.testStates, key)
+
+	g.sendBenchmarkCase(finalTestName, parentNames, elapsed, result)
+
+	// Track stats for parent benchmark groups so they can be finalized with the package
+	for i := range suiteChain {
+		groupKey := strings.Join(append([]string{event.Package}, suiteChain[:i+1]...), "/")
+		stats, exists := g.subgroupStats[groupKey]
+		if !exists {
+			stats = &SubgroupStats{StartTime: time.Now()}
+			g.subgroupStats[groupKey] = stats
+		}
+		stats.TotalTests++
+		stats.PassedTests++
+		stats.Duration += elapsed
+	}
+
+	if len(suiteChain) == 0 {
+		if pkgGroup, ok := g.packageGroups[event.Package]; ok {
+			pkgGroup.Tests = append(pkgGroup.Tests, TestInfo{
+				Name:     finalTestName,
+				Status:   "PASS",
+				Duration: elapsed,
+			})
+		}
+	}
+
+	g.packageTestsDone[event.Package]++
+	if g.packageStatuses[event.Package] != "FAIL" {
+		g.packageStatuses[event.Package] = "PASS"
+	}
+
+	return true
+}
+
+// finalizeBenchmarkGroups sends group results for benchmarks with sub-benchmarks.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) finalizeBenchmarkGroups(packageName string) {
+	prefix := packageName + "/"
+	var benchmarks []string
+	for groupKey := range g.subgroupStats {
+		if !strings.HasPrefix(groupKey, prefix) {
+			continue
+		}
+		testName := strings.TrimPrefix(groupKey, prefix)
+		if strings.HasPrefix(testName, "Benchmark") {
+			benchmarks = append(benchmarks, testName)
+		}
+	}
+
+	// Deepest groups first so parents are finalized after their children
+	sort.Slice(benchmarks, func(i, j int) bool {
+		return strings.Count(benchmarks[i], "/") > strings.Count(benchmarks[j], "/")
+	})
+
+	for _, testName := range benchmarks {
+		groupKey := prefix + testName
+		stats := g.subgroupStats[groupKey]
+		suiteChain, finalTestName := g.parseTestHierarchy(testName)
+		parentNames := g.buildHierarchyFromPackage(packageName, suiteChain)
+
+		status := "PASS"
+		if stats.FailedTests > 0 {
+			status = "FAIL"
+		}
+		totals := ipc.GroupTotals{
+			Total:   stats.TotalTests,
+			Passed:  stats.PassedTests,
+			Failed:  stats.FailedTests,
+			Skipped: stats.SkippedTests,
+		}
+		g.sendGroupResult(finalTestName, parentNames, status, stats.Duration, totals)
+
+		if len(suiteChain) == 0 {
+			if pkgGroup, ok := g.packageGroups[packageName]; ok {
+				pkgGroup.Tests = append(pkgGroup.Tests, TestInfo{
+					Name:     finalTestName,
+					Status:   status,
+					Duration: stats.Duration,
+				})
+			}
+		}
+
+		delete(g.subgroupStats, groupKey)
+		delete(g.testStates, groupKey)
+	}
+}
+
+// parseBenchmarkLine parses a benchmark result line into its name and measurements
+func parseBenchmarkLine(output string) (string, *ipc.BenchmarkResult, bool) {
+	line := strings.TrimSpace(output)
+	matches := benchmarkLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", nil, false
+	}
+
+	iterations, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return "", nil, false
+	}
+	nsPerOp, err := strconv.ParseFloat(matches[3], 64)
+	if err != nil {
+		return "", nil, false
+	}
+
+	result := &ipc.BenchmarkResult{
+		Iterations: iterations,
+		NsPerOp:    nsPerOp,
+	}
+	if mem := benchmarkMemRegex.FindStringSubmatch(line); mem != nil {
+		result.BytesPerOp, _ = strconv.ParseInt(mem[1], 10, 64)
+		result.AllocsPerOp, _ = strconv.ParseInt(mem[2], 10, 64)
+		result.MemStats = true
+	}
+
+	name := benchmarkProcsSuffixRegex.ReplaceAllString(matches[1], "")
+	return name, result, true
+}
+
+// getFilePathForTest maps a test to the file declaring its top-level test function.
+// It returns "" unless file mapping is enabled and go list had finished when the test was
+// first seen; the answer is then kept so all events for a test agree. Caller must hold g.mu.
 func (g *GoTestDefinition) getFilePathForTest(packageName, testName string) string {
-	// Go tests operate at package level, not file level
-	// Always use package-based mapping since we can't reliably determine which file contains a test
-	return g.getFilePathForPackage(packageName)
+	if !g.fileMapping || testName == "" {
+		return ""
+	}
+
+	topLevel, _, _ := strings.Cut(testName, "/")
+	key := packageName + "/" + topLevel
+	if file, ok := g.testFileFor[key]; ok {
+		return file
+	}
+
+	file := g.testFiles[packageName][topLevel] // testFiles is nil until go list finishes
+	g.testFileFor[key] = file
+	return file
 }
 
-// getFilePathForPackage simplified - no longer maps to files without go list
+// getFilePathForPackage returns "" since a package spans several files
 func (g *GoTestDefinition) getFilePathForPackage(packageName string) string {
-	// Without go list, we can't map packages to files
-	// Return empty string to indicate no file mapping available
 	return ""
 }
 
@@ -805,6 +1168,11 @@ func (g *GoTestDefinition) extractPackagePatterns(args []string) []string {
 			continue
 		}
 
+		// Everything after -args goes to the test binary
+		if arg == "-args" {
+			break
+		}
+
 		// Skip flags
 		if strings.HasPrefix(arg, "-") {
 			// Check if flag takes a value
@@ -812,7 +1180,9 @@ func (g *GoTestDefinition) extractPackagePatterns(args []string) []string {
 				arg == "-cpu" || arg == "-parallel" || arg == "-timeout" ||
 				arg == "-benchtime" || arg == "-blockprofile" || arg == "-coverprofile" ||
 				arg == "-cpuprofile" || arg == "-memprofile" || arg == "-mutexprofile" ||
-				arg == "-outputdir" || arg == "-trace" {
+				arg == "-outputdir" || arg == "-trace" || arg == "-tags" || arg == "-skip" ||
+				arg == "-p" || arg == "-coverpkg" || arg == "-covermode" || arg == "-shuffle" ||
+				arg == "-fuzz" || arg == "-fuzztime" || arg == "-exec" || arg == "-vet" || arg == "-o" {
 				skipNext = true
 			}
 			continue
@@ -830,78 +1200,83 @@ func (g *GoTestDefinition) extractPackagePatterns(args []string) []string {
 	return patterns
 }
 
-// runGoList removed - no longer using go list
-
-// parseGoListOutput removed - no longer using go list
+// go list is only used for the opt-in file mapping, see gotest_files.go
 
 // IPC event sending methods
 
 func (g *GoTestDefinition) sendGroupDiscovered(groupName string, parentNames []string) {
-	event := map[string]interface{}{
-		"eventType": "testGroupDiscovered",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-		},
-	}
-	if err := g.ipcWriter.WriteEvent(event); err != nil {
+	if err := g.ipcWriter.WriteEvent(ipc.NewGroupDiscoveredEvent(groupName, parentNames)); err != nil {
 		g.logger.Error("Failed to send testGroupDiscovered: %v", err)
 	}
 }
 
 func (g *GoTestDefinition) sendGroupStart(groupName string, parentNames []string) {
-	event := map[string]interface{}{
-		"eventType": "testGroupStart",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-		},
-	}
-	if err := g.ipcWriter.WriteEvent(event); err != nil {
+	if err := g.ipcWriter.WriteEvent(ipc.NewGroupStartEvent(groupName, parentNames)); err != nil {
 		g.logger.Error("Failed to send testGroupStart: %v", err)
 	}
 }
 
-func (g *GoTestDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
-	event := map[string]interface{}{
-		"eventType": "testGroupResult",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-			"status":      status,
-			"duration":    duration * 1000, // Convert seconds to milliseconds
-			"totals":      totals,
-		},
-	}
+func (g *GoTestDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals ipc.GroupTotals) {
+	g.writeGroupResult(groupName, parentNames, status, duration, totals, nil)
+}
+
+// writeGroupResult sends a group result event, with metadata such as a package's
+// coverage summary when it isn't nil
+func (g *GoTestDefinition) writeGroupResult(groupName string, parentNames []string, status string, duration float64, totals ipc.GroupTotals, metadata map[string]interface{}) {
+	event := ipc.NewGroupResultEvent(groupName, parentNames, status, duration*1000) // Convert seconds to milliseconds
+	event.Payload.Totals = totals
+	event.Payload.Metadata = metadata
 	if err := g.ipcWriter.WriteEvent(event); err != nil {
 		g.logger.Error("Failed to send testGroupResult: %v", err)
 	}
 }
 
 // sendTestCaseWithGroups sends a test case event with group hierarchy
-func (g *GoTestDefinition) sendTestCaseWithGroups(testName string, parentNames []string, status string, duration float64, output string) {
-	event := map[string]interface{}{
-		"eventType": "testCase",
-		"payload": map[string]interface{}{
-			"testName":    testName,
-			"parentNames": parentNames,
-			"status":      status,
-			"duration":    int64(duration * 1000), // Convert to milliseconds
-		},
-	}
-
-	// Add error details for failed tests
-	if status == "FAIL" && output != "" {
-		event["payload"].(map[string]interface{})["error"] = map[string]interface{}{
-			"message": output,
-		}
-	}
+func (g *GoTestDefinition) sendTestCaseWithGroups(testName string, parentNames []string, status string, duration float64, testError *ipc.TestError, metadata map[string]interface{}, stdout, skipReason string) {
+	event := ipc.NewGroupTestCaseEvent(testName, parentNames, status)
+	event.Payload.Duration = wholeMilliseconds(duration)
+	event.Payload.Error = testError
+	event.Payload.Metadata = metadata
+	event.Payload.Stdout = stdout
+	event.Payload.SkipReason = skipReason
 
 	if err := g.ipcWriter.WriteEvent(event); err != nil {
 		g.logger.Debug("Failed to write test case event: %v", err)
 	}
 }
 
+// sendBenchmarkCase sends a passing test case event carrying benchmark measurements
+func (g *GoTestDefinition) sendBenchmarkCase(testName string, parentNames []string, duration float64, result *ipc.BenchmarkResult) {
+	event := ipc.NewGroupTestCaseEvent(testName, parentNames, "PASS")
+	event.Payload.Duration = wholeMilliseconds(duration)
+	event.Payload.Benchmark = result
+
+	if err := g.ipcWriter.WriteEvent(event); err != nil {
+		g.logger.Debug("Failed to write benchmark case event: %v", err)
+	}
+}
+
+// wholeMilliseconds converts a go test elapsed time in seconds to whole milliseconds
+func wholeMilliseconds(seconds float64) float64 {
+	return float64(int64(seconds * 1000))
+}
+
+// testTotals counts the tests of a group by status
+func testTotals(tests []TestInfo) ipc.GroupTotals {
+	totals := ipc.GroupTotals{Total: len(tests)}
+	for _, test := range tests {
+		switch test.Status {
+		case "PASS":
+			totals.Passed++
+		case "FAIL":
+			totals.Failed++
+		case "SKIP":
+			totals.Skipped++
+		}
+	}
+	return totals
+}
+
 // finalizePendingGroups sends group results for any groups that haven't been finalized
 func (g *GoTestDefinition) finalizePendingGroups() {
 	g.mu.Lock()
@@ -915,41 +1290,24 @@ func (g *GoTestDefinition) finalizePendingGroups() {
 			// (packageStarted is set when we send the group start, and should be cleared when we send result)
 			g.logger.Debug("Checking if package %s needs finalization", pkgName)
 
-			// Calculate totals from tracked tests
-			totals := map[string]interface{}{
-				"total":   0,
-				"passed":  0,
-				"failed":  0,
-				"skipped": 0,
-			}
-
-			status := "FAIL" // Default to FAIL if incomplete
-
-			if len(pkgGroup.Tests) > 0 {
-				totals["total"] = len(pkgGroup.Tests)
-				for _, test := range pkgGroup.Tests {
-					switch test.Status {
-					case "PASS":
-						totals["passed"] = totals["passed"].(int) + 1
-					case "FAIL":
-						totals["failed"] = totals["failed"].(int) + 1
-					case "SKIP":
-						totals["skipped"] = totals["skipped"].(int) + 1
-					}
-				}
+			// Tests still running when the command stopped fail, and so do their groups
+			g.failUnfinishedTests(pkgName)
+			g.finalizeBenchmarkGroups(pkgName)
+			g.finalizeFileGroups(pkgName)
 
-				// Determine status based on test results
-				if totals["failed"].(int) > 0 {
-					status = "FAIL"
-				} else if totals["passed"].(int) > 0 {
-					status = "PASS"
-				} else if totals["skipped"].(int) > 0 {
-					status = "SKIP"
-				}
+			// Calculate totals from tracked tests
+			totals := testTotals(pkgGroup.Tests)
+
+			// Determine status based on test results, defaulting to FAIL if incomplete
+			status := "FAIL"
+			if totals.Failed == 0 && totals.Passed > 0 {
+				status = "PASS"
+			} else if totals.Failed == 0 && totals.Skipped > 0 {
+				status = "SKIP"
 			}
 
 			g.logger.Debug("Finalizing incomplete package %s with status %s (passed=%d, failed=%d, skipped=%d)",
-				pkgName, status, totals["passed"], totals["failed"], totals["skipped"])
+				pkgName, status, totals.Passed, totals.Failed, totals.Skipped)
 			g.sendGroupResult(pkgName, []string{}, status, 0, totals)
 
 			// Clear the started flag so we don't finalize again
@@ -958,6 +1316,45 @@ func (g *GoTestDefinition) finalizePendingGroups() {
 	}
 }
 
+// unfinishedTestMessage ends the output of a test that never got a result
+const unfinishedTestMessage = "test did not finish: the test command stopped before its result"
+
+// failUnfinishedTests sends failing results for tests of a package that were still
+// running when the test command stopped, e.g. killed by --timeout. Subtests come first,
+// so their groups are settled before their parents. Caller must hold g.mu.
+func (g *GoTestDefinition) failUnfinishedTests(pkgName string) {
+	var names []string
+	for _, state := range g.testStates {
+		if state.Package == pkgName {
+			names = append(names, state.Name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if depthI, depthJ := strings.Count(names[i], "/"), strings.Count(names[j], "/"); depthI != depthJ {
+			return depthI > depthJ
+		}
+		return names[i] < names[j]
+	})
+
+	now := time.Now()
+	for _, name := range names {
+		state := g.testStates[pkgName+"/"+name]
+		state.Output = append(state.Output, unfinishedTestMessage)
+		var elapsed float64
+		if !state.StartTime.IsZero() {
+			elapsed = now.Sub(state.StartTime).Seconds()
+		}
+		g.logger.Debug("Failing test %s in package %s, which did not finish", name, pkgName)
+		g.recordTestResult(&GoTestEvent{
+			Time:    now,
+			Action:  "fail",
+			Package: pkgName,
+			Test:    name,
+			Elapsed: elapsed,
+		})
+	}
+}
+
 // isErrorOutput determines if a line of output should be captured as an error
 func (g *GoTestDefinition) isErrorOutput(output string) bool {
 	// Skip empty lines and standard go test output
@@ -1032,21 +1429,10 @@ func (g *GoTestDefinition) cleanErrorMessage(message string) string {
 	return strings.Join(cleanLines, "\n")
 }
 
-// sendGroupError sends a testGroupError event
-func (g *GoTestDefinition) sendGroupError(groupName string, parentNames []string, errorType string, duration float64, message string) {
-	event := map[string]interface{}{
-		"eventType": "testGroupError",
-		"payload": map[string]interface{}{
-			"groupName":   groupName,
-			"parentNames": parentNames,
-			"errorType":   errorType,
-			"duration":    duration * 1000, // Convert seconds to milliseconds
-			"error": map[string]interface{}{
-				"message": message,
-				"phase":   "setup",
-			},
-		},
-	}
+// sendGroupError sends a testGroupError event, with an optional stack trace
+func (g *GoTestDefinition) sendGroupError(groupName string, parentNames []string, errorType string, duration float64, message, stack string) {
+	event := ipc.NewGroupErrorEvent(groupName, parentNames, errorType, duration*1000, message) // Convert seconds to milliseconds
+	event.Payload.Error.Stack = stack
 	if err := g.ipcWriter.WriteEvent(event); err != nil {
 		g.logger.Error("Failed to send testGroupError: %v", err)
 	}
@@ -1055,24 +1441,42 @@ func (g *GoTestDefinition) sendGroupError(groupName string, parentNames []string
 // cleanupPackageErrors removes buffered errors to prevent memory leaks
 func (g *GoTestDefinition) cleanupPackageErrors(packageName string) {
 	delete(g.packageErrors, packageName)
+	delete(g.buildOutput, packageName)
+	delete(g.failedBuilds, packageName)
 }
 
 // sendTestFileResult, sendTestFileResultWithDuration, sendStdoutChunk removed - using group events instead
 
-// NewIPCWriter creates a new IPC writer
+// NewIPCWriter creates a new IPC writer for an IPC file or socket path. Its first line
+// is the schema header declaring the version of the events that follow.
 func NewIPCWriter(path string) (*IPCWriter, error) {
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, err
+	var out io.WriteCloser
+	if socketPath, ok := ipc.SocketPath(path); ok {
+		conn, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return nil, err
+		}
+		out = conn
+	} else {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		out = file
 	}
 
-	return &IPCWriter{
+	w := &IPCWriter{
 		path: path,
-		file: file,
-	}, nil
+		out:  out,
+	}
+	if err := w.WriteEvent(ipc.NewSchemaEvent()); err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	return w, nil
 }
 
-// WriteEvent writes an IPC event to the file
+// WriteEvent writes an IPC event to the file or socket
 func (w *IPCWriter) WriteEvent(event interface{}) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -1082,7 +1486,7 @@ func (w *IPCWriter) WriteEvent(event interface{}) error {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	_, err = w.file.Write(append(data, '\n'))
+	_, err = w.out.Write(append(data, '\n'))
 	if err != nil {
 		return fmt.Errorf("failed to write event: %w", err)
 	}
@@ -1092,5 +1496,5 @@ func (w *IPCWriter) WriteEvent(event interface{}) error {
 
 // Close closes the IPC writer
 func (w *IPCWriter) Close() error {
-	return w.file.Close()
+	return w.out.Close()
 }
@@ -0,0 +1,172 @@
+package definitions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBenchmarkLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantName  string
+		wantOK    bool
+		wantIters int64
+		wantNs    float64
+		wantMem   bool
+		wantBytes int64
+		wantAlloc int64
+	}{
+		{
+			name:      "without benchmem",
+			line:      "BenchmarkFoo-8   \t 1000000\t      1053 ns/op\n",
+			wantName:  "BenchmarkFoo",
+			wantOK:    true,
+			wantIters: 1000000,
+			wantNs:    1053,
+		},
+		{
+			name:      "with benchmem",
+			line:      "BenchmarkFoo-8   \t 1000000\t      1053 ns/op\t      48 B/op\t       1 allocs/op\n",
+			wantName:  "BenchmarkFoo",
+			wantOK:    true,
+			wantIters: 1000000,
+			wantNs:    1053,
+			wantMem:   true,
+			wantBytes: 48,
+			wantAlloc: 1,
+		},
+		{
+			name:      "sub-benchmark with fractional ns/op",
+			line:      "BenchmarkBar/small         \t    1000\t         0.4660 ns/op\n",
+			wantName:  "BenchmarkBar/small",
+			wantOK:    true,
+			wantIters: 1000,
+			wantNs:    0.466,
+		},
+		{
+			name:   "benchmark name header",
+			line:   "BenchmarkFoo\n",
+			wantOK: false,
+		},
+		{
+			name:   "log output",
+			line:   "    bench_test.go:12: setup\n",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, result, ok := parseBenchmarkLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if name != tt.wantName {
+				t.Errorf("Expected name %q, got %q", tt.wantName, name)
+			}
+			if result.Iterations != tt.wantIters || result.NsPerOp != tt.wantNs {
+				t.Errorf("Expected %d iterations at %v ns/op, got %d at %v", tt.wantIters, tt.wantNs, result.Iterations, result.NsPerOp)
+			}
+			if result.MemStats != tt.wantMem || result.BytesPerOp != tt.wantBytes || result.AllocsPerOp != tt.wantAlloc {
+				t.Errorf("Unexpected memory stats: %+v", result)
+			}
+		})
+	}
+}
+
+func TestGoTestDefinition_Benchmarks(t *testing.T) {
+	g := NewGoTestDefinition(createTestLogger(t))
+
+	tmpDir := t.TempDir()
+	ipcPath := filepath.Join(tmpDir, "test.jsonl")
+	ipcWriter, err := NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	g.ipcWriter = ipcWriter
+	t.Cleanup(func() { _ = ipcWriter.Close() })
+
+	capture := NewTestIPCCapture(ipcPath)
+
+	// Event sequence as produced by go test -json -bench . -benchmem
+	pkg := "example.com/bench"
+	events := []*GoTestEvent{
+		{Action: "start", Package: pkg},
+		{Action: "run", Package: pkg, Test: "BenchmarkFoo"},
+		{Action: "output", Package: pkg, Test: "BenchmarkFoo", Output: "BenchmarkFoo\n"},
+		{Action: "output", Package: pkg, Test: "BenchmarkFoo", Output: "BenchmarkFoo-8 \t    1000\t      1053 ns/op\t      48 B/op\t       1 allocs/op\n"},
+		{Action: "run", Package: pkg, Test: "BenchmarkBar"},
+		{Action: "run", Package: pkg, Test: "BenchmarkBar/small"},
+		{Action: "output", Package: pkg, Test: "BenchmarkBar/small", Output: "BenchmarkBar/small-8 \t    1000\t      0.466 ns/op\t       0 B/op\t       0 allocs/op\n"},
+		{Action: "run", Package: pkg, Test: "BenchmarkBar/big"},
+		{Action: "output", Package: pkg, Test: "BenchmarkBar/big", Output: "BenchmarkBar/big-8 \t    1000\t      12.57 ns/op\t       0 B/op\t       0 allocs/op\n"},
+		{Action: "bench", Package: pkg, Test: "BenchmarkBar/big", Output: "BenchmarkBar/big-8 \t    1000\t      12.57 ns/op\t       0 B/op\t       0 allocs/op\n"},
+		{Action: "pass", Package: pkg, Elapsed: 0.5},
+	}
+	for _, event := range events {
+		if err := g.processEvent(event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = ipcWriter.Close()
+
+	testCases := capture.GetEventsByType("testCase")
+	if len(testCases) != 3 {
+		t.Fatalf("Expected 3 benchmark test cases, got %d", len(testCases))
+	}
+
+	byName := make(map[string]map[string]interface{})
+	for _, event := range testCases {
+		payload := event["payload"].(map[string]interface{})
+		byName[payload["testName"].(string)] = payload
+	}
+
+	foo, ok := byName["BenchmarkFoo"]
+	if !ok {
+		t.Fatalf("Missing test case for BenchmarkFoo")
+	}
+	bench, ok := foo["benchmark"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected benchmark payload for BenchmarkFoo, got %v", foo)
+	}
+	if bench["nsPerOp"] != 1053.0 || bench["bytesPerOp"] != 48.0 || bench["allocsPerOp"] != 1.0 {
+		t.Errorf("Unexpected benchmark payload: %v", bench)
+	}
+
+	small, ok := byName["small"]
+	if !ok {
+		t.Fatalf("Expected sub-benchmark 'small' to be nested under BenchmarkBar")
+	}
+	parents := convertToStringSlice(small["parentNames"])
+	if len(parents) != 2 || parents[0] != pkg || parents[1] != "BenchmarkBar" {
+		t.Errorf("Expected parent names [%s BenchmarkBar], got %v", pkg, parents)
+	}
+
+	var barResult, pkgResult map[string]interface{}
+	for _, event := range capture.GetEventsByType("testGroupResult") {
+		payload := event["payload"].(map[string]interface{})
+		switch payload["groupName"] {
+		case "BenchmarkBar":
+			barResult = payload
+		case pkg:
+			pkgResult = payload
+		}
+	}
+	if barResult == nil || barResult["status"] != "PASS" {
+		t.Errorf("Expected PASS group result for BenchmarkBar, got %v", barResult)
+	}
+	if pkgResult == nil {
+		t.Fatalf("Missing package group result")
+	}
+	totals := pkgResult["totals"].(map[string]interface{})
+	if totals["total"] != 2.0 || totals["passed"] != 2.0 {
+		t.Errorf("Expected package totals of 2 passed benchmarks, got %v", totals)
+	}
+	if _, setupFailed := totals["setupFailed"]; setupFailed {
+		t.Errorf("Benchmark-only package should not be treated as a setup failure")
+	}
+}
@@ -0,0 +1,81 @@
+package definitions
+
+import (
+	"strings"
+)
+
+// goBuildFailureErrorType is the errorType reported for packages whose tests failed to compile
+const goBuildFailureErrorType = "BUILD_FAILURE"
+
+// buildPackage strips the test variant from a build import path, e.g.
+// "example.com/pkg [example.com/pkg.test]" becomes "example.com/pkg"
+func buildPackage(importPath string) string {
+	pkg, _, _ := strings.Cut(importPath, " ")
+	return pkg
+}
+
+// recordBuildOutput buffers compiler output reported with "build-output" events (Go 1.24+)
+func (g *GoTestDefinition) recordBuildOutput(event *GoTestEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	pkg := buildPackage(event.ImportPath)
+	g.buildOutput[pkg] = append(g.buildOutput[pkg], event.Output)
+}
+
+// recordNonJSONLine buffers output that go test printed without -json framing, such as
+// compiler errors from older Go versions. A "# package" header starts that package's
+// output; lines before any header are kept for build failures without output of their own.
+func (g *GoTestDefinition) recordNonJSONLine(line string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if header, ok := strings.CutPrefix(line, "# "); ok {
+		g.nonJSONPackage = buildPackage(header)
+	}
+	g.buildOutput[g.nonJSONPackage] = append(g.buildOutput[g.nonJSONPackage], line+"\n")
+}
+
+// endNonJSONBlock ends the current run of non-JSON lines
+func (g *GoTestDefinition) endNonJSONBlock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nonJSONPackage = ""
+}
+
+// recordBuildFailedLine notes a package's "FAIL pkg [build failed]" line, which is the only
+// sign of a failed build before Go 1.24. Caller must hold g.mu.
+func (g *GoTestDefinition) recordBuildFailedLine(event *GoTestEvent) {
+	if strings.HasSuffix(strings.TrimSpace(event.Output), "[build failed]") {
+		g.failedBuilds[event.Package] = true
+	}
+}
+
+// buildFailed returns true if the package result reports a failed build. Caller must hold g.mu.
+func (g *GoTestDefinition) buildFailed(event *GoTestEvent) bool {
+	if event.Action != "fail" {
+		return false
+	}
+	return event.FailedBuild != "" || g.failedBuilds[event.Package]
+}
+
+// buildFailureMessage returns the compiler output captured for a package, without the
+// "# package" headers. Caller must hold g.mu.
+func (g *GoTestDefinition) buildFailureMessage(packageName string) string {
+	output := g.buildOutput[packageName]
+	if len(output) == 0 {
+		output = g.buildOutput[""]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.Join(output, ""), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "Build failed"
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package definitions
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoTestDefinition_BuildFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{
+			// Go 1.24+ reports compiler output as build-output events
+			name: "build-output events",
+			output: `{"ImportPath":"example.com/shop [example.com/shop.test]","Action":"build-output","Output":"# example.com/shop [example.com/shop.test]\n"}
+{"ImportPath":"example.com/shop [example.com/shop.test]","Action":"build-output","Output":"shop/cart_test.go:5:30: undefined: checkout\n"}
+{"ImportPath":"example.com/shop [example.com/shop.test]","Action":"build-fail"}
+{"Action":"start","Package":"example.com/shop"}
+{"Action":"output","Package":"example.com/shop","Output":"FAIL\texample.com/shop [build failed]\n"}
+{"Action":"fail","Package":"example.com/shop","FailedBuild":"example.com/shop [example.com/shop.test]"}
+{"Action":"start","Package":"example.com/util"}
+{"Action":"run","Package":"example.com/util","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/util","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/util"}
+`,
+		},
+		{
+			// Older versions print compiler output without JSON framing
+			name: "non-JSON compiler output",
+			output: `# example.com/shop [example.com/shop.test]
+shop/cart_test.go:5:30: undefined: checkout
+{"Action":"start","Package":"example.com/shop"}
+{"Action":"output","Package":"example.com/shop","Output":"FAIL\texample.com/shop [build failed]\n"}
+{"Action":"fail","Package":"example.com/shop"}
+{"Action":"start","Package":"example.com/util"}
+{"Action":"run","Package":"example.com/util","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/util","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/util"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGoTestDefinition(createTestLogger(t))
+			ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+			if err := g.ProcessOutput(strings.NewReader(tt.output), ipcPath); err != nil {
+				t.Fatalf("ProcessOutput failed: %v", err)
+			}
+			capture := NewTestIPCCapture(ipcPath)
+
+			errs := capture.GetEventsByType("testGroupError")
+			if len(errs) != 1 {
+				t.Fatalf("Expected 1 group error, got %d: %v", len(errs), errs)
+			}
+			payload := errs[0]["payload"].(map[string]interface{})
+			if payload["groupName"] != "example.com/shop" || payload["errorType"] != "BUILD_FAILURE" {
+				t.Errorf("Expected a BUILD_FAILURE for example.com/shop, got %v", payload)
+			}
+			message := payload["error"].(map[string]interface{})["message"]
+			if message != "shop/cart_test.go:5:30: undefined: checkout" {
+				t.Errorf("Expected the compiler output as the message, got %q", message)
+			}
+
+			// The package that built still reports its results
+			for _, result := range capture.GetEventsByType("testGroupResult") {
+				result := result["payload"].(map[string]interface{})
+				if result["groupName"] == "example.com/util" && result["status"] != "PASS" {
+					t.Errorf("Expected example.com/util to pass, got %v", result["status"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package definitions
+
+import (
+	"regexp"
+	"strings"
+)
+
+// goCoverageRegex matches the coverage summary go test -cover prints for a package, e.g.
+// "coverage: 87.5% of statements", also at the end of its "ok" line and after the
+// package name for packages without test files
+var goCoverageRegex = regexp.MustCompile(`(?:^|\t)coverage: (.+)$`)
+
+// recordCoverageLine keeps a package's coverage summary for its group result. The line
+// stays part of the package's output. Caller must hold g.mu.
+func (g *GoTestDefinition) recordCoverageLine(event *GoTestEvent) {
+	line := strings.TrimRight(event.Output, "\r\n")
+	match := goCoverageRegex.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	g.packageCoverage[event.Package] = match[1]
+	// Packages without test files get the summary after their name instead of
+	// "[no test files]", e.g. "\texample.com/pkg\t\tcoverage: 0.0% of statements"
+	if strings.HasPrefix(line, "\t"+event.Package+"\t") {
+		g.markNoTestFiles(event)
+	}
+}
+
+// packageResultMetadata returns the metadata of a package's group result: its coverage
+// summary when the command ran with -cover, nil otherwise. Caller must hold g.mu.
+func (g *GoTestDefinition) packageResultMetadata(packageName string) map[string]interface{} {
+	coverage, ok := g.packageCoverage[packageName]
+	if !ok {
+		return nil
+	}
+	delete(g.packageCoverage, packageName)
+	return map[string]interface{}{"coverage": coverage}
+}
@@ -0,0 +1,60 @@
+package definitions
+
+import (
+	"testing"
+)
+
+func TestGoTestDefinition_Coverage(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	events := []*GoTestEvent{
+		{Action: "start", Package: "example.com/shop"},
+		{Action: "run", Package: "example.com/shop", Test: "TestAdd"},
+		{Action: "pass", Package: "example.com/shop", Test: "TestAdd"},
+		{Action: "output", Package: "example.com/shop", Output: "PASS\n"},
+		{Action: "output", Package: "example.com/shop", Output: "coverage: 87.5% of statements\n"},
+		{Action: "output", Package: "example.com/shop", Output: "ok  \texample.com/shop\t0.002s\tcoverage: 87.5% of statements\n"},
+		{Action: "pass", Package: "example.com/shop", Elapsed: 0.002},
+		// Packages without test files are reported as passed with only a coverage line
+		{Action: "start", Package: "example.com/shop/util"},
+		{Action: "output", Package: "example.com/shop/util", Output: "\texample.com/shop/util\t\tcoverage: 0.0% of statements\n"},
+		{Action: "pass", Package: "example.com/shop/util", Elapsed: 0.01},
+		// Without -cover there is no coverage metadata
+		{Action: "start", Package: "example.com/shop/api"},
+		{Action: "run", Package: "example.com/shop/api", Test: "TestGet"},
+		{Action: "pass", Package: "example.com/shop/api", Test: "TestGet"},
+		{Action: "pass", Package: "example.com/shop/api", Elapsed: 0.01},
+	}
+	processGoEvents(t, g, events)
+	done()
+
+	results := map[string]map[string]interface{}{}
+	for _, event := range capture.GetEventsByType("testGroupResult") {
+		payload := event["payload"].(map[string]interface{})
+		if parents, _ := payload["parentNames"].([]interface{}); len(parents) == 0 {
+			results[payload["groupName"].(string)] = payload
+		}
+	}
+
+	tests := []struct {
+		pkg, status, coverage string
+	}{
+		{"example.com/shop", "PASS", "87.5% of statements"},
+		{"example.com/shop/util", "NOTESTS", "0.0% of statements"},
+		{"example.com/shop/api", "PASS", ""},
+	}
+	for _, tt := range tests {
+		payload, ok := results[tt.pkg]
+		if !ok {
+			t.Fatalf("Expected a group result for %s, got %v", tt.pkg, results)
+		}
+		if payload["status"] != tt.status {
+			t.Errorf("%s: expected status %s, got %v", tt.pkg, tt.status, payload["status"])
+		}
+		metadata, _ := payload["metadata"].(map[string]interface{})
+		if coverage, _ := metadata["coverage"].(string); coverage != tt.coverage {
+			t.Errorf("%s: expected coverage %q, got %q", tt.pkg, tt.coverage, coverage)
+		}
+	}
+
+}
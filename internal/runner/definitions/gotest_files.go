@@ -0,0 +1,165 @@
+package definitions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// goListTimeout bounds the background go list lookup used for file mapping
+const goListTimeout = 30 * time.Second
+
+// goTestFuncRegex matches a top-level test, benchmark, example or fuzz function declaration
+var goTestFuncRegex = regexp.MustCompile(`^func ((?:Test|Benchmark|Example|Fuzz)\w*)\(`)
+
+// goListPackage is the subset of "go list -json" output used for file mapping
+type goListPackage struct {
+	ImportPath   string
+	Dir          string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// EnableFileMapping starts a background go list lookup so test results can be grouped by
+// the file declaring each top-level test, below their package. This costs roughly
+// 200-500ms of go list work, which is why it is opt-in. Tests never wait for the lookup:
+// tests first seen before it finishes stay directly under their package.
+func (g *GoTestDefinition) EnableFileMapping(command []string) {
+	patterns := g.extractPackagePatterns(command)
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	g.mu.Lock()
+	g.fileMapping = true
+	g.mu.Unlock()
+
+	go func() {
+		start := time.Now()
+		files, err := loadGoTestFiles(patterns)
+		if err != nil {
+			g.logger.Debug("go list file mapping failed, keeping package-level groups: %v", err)
+			files = map[string]map[string]string{}
+		}
+		g.logger.Debug("go list mapped tests in %d packages in %v", len(files), time.Since(start))
+
+		g.mu.Lock()
+		g.testFiles = files
+		g.mu.Unlock()
+	}()
+}
+
+// loadGoTestFiles runs go list for the patterns and maps each package's top-level test
+// functions to the name of the _test.go file declaring them
+func loadGoTestFiles(patterns []string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), goListTimeout)
+	defer cancel()
+
+	args := append([]string{"list", "-json", "-e"}, patterns...)
+	output, err := exec.CommandContext(ctx, "go", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseGoListTestFiles(output, readTestFunctions)
+}
+
+// parseGoListTestFiles reads the concatenated JSON objects printed by "go list -json" and
+// uses readFuncs to list the test functions in each test file
+func parseGoListTestFiles(output []byte, readFuncs func(path string) ([]string, error)) (map[string]map[string]string, error) {
+	files := make(map[string]map[string]string)
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg goListPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		for _, name := range append(pkg.TestGoFiles, pkg.XTestGoFiles...) {
+			funcs, err := readFuncs(filepath.Join(pkg.Dir, name))
+			if err != nil {
+				continue
+			}
+			for _, fn := range funcs {
+				if files[pkg.ImportPath] == nil {
+					files[pkg.ImportPath] = make(map[string]string)
+				}
+				files[pkg.ImportPath][fn] = name
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// readTestFunctions lists the top-level test functions declared in a Go file
+func readTestFunctions(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var funcs []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if match := goTestFuncRegex.FindStringSubmatch(scanner.Text()); match != nil {
+			funcs = append(funcs, match[1])
+		}
+	}
+	return funcs, scanner.Err()
+}
+
+// trackFileGroup records a file group of a package so it is finalized with the package.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) trackFileGroup(packageName, file string) {
+	for _, existing := range g.fileGroups[packageName] {
+		if existing == file {
+			return
+		}
+	}
+	g.fileGroups[packageName] = append(g.fileGroups[packageName], file)
+}
+
+// finalizeFileGroups sends results for a package's file groups, which have no result
+// event of their own. Caller must hold g.mu.
+func (g *GoTestDefinition) finalizeFileGroups(packageName string) {
+	for _, file := range g.fileGroups[packageName] {
+		groupKey := packageName + "/" + file
+		stats, ok := g.subgroupStats[groupKey]
+		if !ok {
+			continue
+		}
+
+		status := "PASS"
+		if stats.FailedTests > 0 {
+			status = "FAIL"
+		} else if stats.PassedTests == 0 && stats.SkippedTests > 0 {
+			status = "SKIP"
+		}
+		totals := ipc.GroupTotals{
+			Total:   stats.TotalTests,
+			Passed:  stats.PassedTests,
+			Failed:  stats.FailedTests,
+			Skipped: stats.SkippedTests,
+		}
+		// Parallel tests overlap, so the file takes as long as its first to last test
+		g.sendGroupResult(file, []string{packageName}, status, stats.wallClock(stats.Duration), totals)
+		delete(g.subgroupStats, groupKey)
+	}
+	delete(g.fileGroups, packageName)
+}
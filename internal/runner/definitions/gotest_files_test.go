@@ -0,0 +1,137 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoListTestFiles(t *testing.T) {
+	output := []byte(`{
+	"ImportPath": "example.com/shop/cart",
+	"Dir": "/src/cart",
+	"GoFiles": ["cart.go"],
+	"TestGoFiles": ["cart_test.go"],
+	"XTestGoFiles": ["example_test.go"]
+}
+{
+	"ImportPath": "example.com/shop/util",
+	"Dir": "/src/util",
+	"GoFiles": ["util.go"]
+}
+`)
+	funcs := map[string][]string{
+		filepath.Join("/src/cart", "cart_test.go"):    {"TestAdd", "TestRemove"},
+		filepath.Join("/src/cart", "example_test.go"): {"ExampleCart"},
+	}
+	readFuncs := func(path string) ([]string, error) {
+		return funcs[path], nil
+	}
+
+	files, err := parseGoListTestFiles(output, readFuncs)
+	if err != nil {
+		t.Fatalf("parseGoListTestFiles failed: %v", err)
+	}
+
+	expected := map[string]map[string]string{
+		"example.com/shop/cart": {
+			"TestAdd":     "cart_test.go",
+			"TestRemove":  "cart_test.go",
+			"ExampleCart": "example_test.go",
+		},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
+
+func TestReadTestFunctions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cart_test.go")
+	source := `package cart
+
+func TestAdd(t *testing.T) {}
+
+func (s *suite) TestMethod(t *testing.T) {}
+
+func BenchmarkAdd(b *testing.B) {}
+
+func helper() {}
+
+func FuzzParse(f *testing.F) {}
+`
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	funcs, err := readTestFunctions(path)
+	if err != nil {
+		t.Fatalf("readTestFunctions failed: %v", err)
+	}
+	expected := []string{"TestAdd", "BenchmarkAdd", "FuzzParse"}
+	if !reflect.DeepEqual(funcs, expected) {
+		t.Errorf("Expected %v, got %v", expected, funcs)
+	}
+}
+
+func TestGoTestDefinition_FileMapping(t *testing.T) {
+	g := NewGoTestDefinition(createTestLogger(t))
+
+	ipcPath := filepath.Join(t.TempDir(), "test.jsonl")
+	ipcWriter, err := NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	g.ipcWriter = ipcWriter
+	t.Cleanup(func() { _ = ipcWriter.Close() })
+	capture := NewTestIPCCapture(ipcPath)
+
+	// TestEarly is seen before go list finishes, so it stays under the package
+	g.fileMapping = true
+	if err := g.processEvent(&GoTestEvent{Action: "run", Package: "example.com/cart", Test: "TestEarly"}); err != nil {
+		t.Fatalf("Failed to process event: %v", err)
+	}
+	g.testFiles = map[string]map[string]string{
+		"example.com/cart": {"TestAdd": "cart_test.go", "TestEarly": "cart_test.go"},
+	}
+
+	events := []*GoTestEvent{
+		{Action: "pass", Package: "example.com/cart", Test: "TestEarly", Elapsed: 0.1},
+		{Action: "run", Package: "example.com/cart", Test: "TestAdd"},
+		{Action: "run", Package: "example.com/cart", Test: "TestAdd/empty"},
+		{Action: "fail", Package: "example.com/cart", Test: "TestAdd/empty", Elapsed: 0.2},
+		{Action: "fail", Package: "example.com/cart", Test: "TestAdd", Elapsed: 0.3},
+		{Action: "fail", Package: "example.com/cart", Elapsed: 1.0},
+	}
+	for _, event := range events {
+		if err := g.processEvent(event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = ipcWriter.Close()
+
+	parents := make(map[string][]string)
+	for _, event := range capture.GetEventsByType("testCase") {
+		payload := event["payload"].(map[string]interface{})
+		parents[payload["testName"].(string)] = convertToStringSlice(payload["parentNames"])
+	}
+	expectedParents := map[string][]string{
+		"TestEarly": {"example.com/cart"},
+		"TestAdd":   {"example.com/cart", "cart_test.go"},
+		"empty":     {"example.com/cart", "cart_test.go", "TestAdd"},
+	}
+	if !reflect.DeepEqual(parents, expectedParents) {
+		t.Errorf("Expected test parents %v, got %v", expectedParents, parents)
+	}
+
+	// The file group result comes before the package result
+	var results []string
+	for _, event := range capture.GetEventsByType("testGroupResult") {
+		payload := event["payload"].(map[string]interface{})
+		results = append(results, payload["groupName"].(string)+"="+payload["status"].(string))
+	}
+	expectedResults := []string{"TestAdd=FAIL", "cart_test.go=FAIL", "example.com/cart=FAIL"}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Expected group results %v, got %v", expectedResults, results)
+	}
+}
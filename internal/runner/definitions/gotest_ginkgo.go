@@ -0,0 +1,474 @@
+package definitions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// GinkgoReportFile is the JSON report 3pio asks Ginkgo suites to write. Ginkgo resolves
+// it against the directory the suite runs in, so each package writes its own.
+const GinkgoReportFile = ".3pio-ginkgo-report.json"
+
+// ginkgoImport is the import path that marks a test file as part of a Ginkgo suite
+const ginkgoImport = "github.com/onsi/ginkgo/v2"
+
+// ginkgoSuitePrefix starts the line a Ginkgo suite prints when it starts, followed by
+// the suite description and " - " and the suite's directory
+const ginkgoSuitePrefix = "Running Suite: "
+
+// ginkgoReportSlack is how much older than the run event of its test a report may be. go
+// test times events when it reads the output, which can be after the suite started.
+const ginkgoReportSlack = 10 * time.Second
+
+// ginkgoSuiteNodes are the leaf node types Ginkgo runs once per suite rather than per spec
+var ginkgoSuiteNodes = []string{
+	"BeforeSuite", "AfterSuite", "SynchronizedBeforeSuite", "SynchronizedAfterSuite",
+	"ReportBeforeSuite", "ReportAfterSuite", "DeferCleanup (Suite)",
+}
+
+// ginkgoReport is the subset of a suite in Ginkgo's JSON report used to report its specs
+type ginkgoReport struct {
+	SuitePath        string
+	SuiteDescription string
+	SpecReports      []ginkgoSpecReport
+}
+
+// ginkgoSpecReport is one spec, or a suite node such as BeforeSuite, in a Ginkgo report
+type ginkgoSpecReport struct {
+	ContainerHierarchyTexts    []string // Describe/Context texts, outermost first
+	LeafNodeType               string   // "It" for specs
+	LeafNodeText               string
+	State                      string // passed, skipped, pending, failed, aborted, panicked, interrupted or timedout
+	StartTime                  time.Time
+	EndTime                    time.Time
+	RunTime                    time.Duration // Nanoseconds in the JSON
+	CapturedGinkgoWriterOutput string
+	CapturedStdOutErr          string
+	Failure                    *ginkgoFailure // Also set for skipped specs, holding the Skip message
+}
+
+// ginkgoFailure is why a spec failed or was skipped
+type ginkgoFailure struct {
+	Message        string
+	Location       ginkgoLocation
+	ForwardedPanic string
+}
+
+// ginkgoLocation is a source location in a Ginkgo report
+type ginkgoLocation struct {
+	FileName       string
+	LineNumber     int
+	FullStackTrace string
+}
+
+// EnableGinkgo reports Ginkgo suites as their Describe/Context/It hierarchy (--ginkgo),
+// even when the command also tests packages without Ginkgo. Those packages fail on the
+// Ginkgo report flag this adds, which is why mixed runs are not detected on their own.
+func (g *GoTestDefinition) EnableGinkgo() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ginkgoForced = true
+}
+
+// setGinkgoMode decides from the command whether Ginkgo suites are reported as nested
+// groups, and returns the report flag to add to it, if any
+func (g *GoTestDefinition) setGinkgoMode(cmd []string) []string {
+	g.mu.RLock()
+	forced := g.ginkgoForced
+	g.mu.RUnlock()
+	enabled := forced || g.usesGinkgo(cmd)
+	reportPath := ginkgoReportFlag(cmd)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ginkgo = enabled
+	g.ginkgoReport = reportPath
+	g.ginkgoOwnReport = false
+	if !enabled || reportPath != "" {
+		return nil
+	}
+	g.ginkgoReport = GinkgoReportFile
+	g.ginkgoOwnReport = true
+	return []string{"-ginkgo.json-report=" + GinkgoReportFile}
+}
+
+// usesGinkgo reports whether every package a go test command tests is a Ginkgo suite:
+// the command passes Ginkgo flags already, or the module requires Ginkgo v2 and each
+// package with test files imports it
+func (g *GoTestDefinition) usesGinkgo(cmd []string) bool {
+	for _, arg := range cmd {
+		if strings.HasPrefix(arg, "-ginkgo.") || strings.HasPrefix(arg, "--ginkgo.") {
+			return true
+		}
+	}
+
+	root, modulePath, requires := readGoModule()
+	if !requires {
+		return false
+	}
+	patterns := g.extractPackagePatterns(cmd)
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	suites := 0
+	for _, pattern := range patterns {
+		dir, recursive := strings.CutSuffix(pattern, "/...")
+		if pattern == "..." {
+			dir, recursive = ".", true
+		}
+		if rel, ok := strings.CutPrefix(dir, modulePath); ok && modulePath != "" && (rel == "" || rel[0] == '/') {
+			dir = filepath.Join(root, filepath.FromSlash(rel))
+		} else if !strings.HasPrefix(dir, ".") {
+			return false // A package outside the module, which can't be checked
+		}
+
+		found, ok := ginkgoPackages(dir, recursive)
+		if !ok {
+			return false
+		}
+		suites += found
+	}
+	return suites > 0
+}
+
+// readGoModule finds the go.mod for the current directory and returns the module's
+// directory and path, and whether it requires Ginkgo v2
+func readGoModule() (root, modulePath string, requiresGinkgo bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", false
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			scanner := bufio.NewScanner(strings.NewReader(string(data)))
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if path, ok := strings.CutPrefix(line, "module "); ok {
+					modulePath = strings.Trim(strings.TrimSpace(path), `"`)
+				}
+				if strings.Contains(line, ginkgoImport+" ") {
+					requiresGinkgo = true
+				}
+			}
+			return dir, modulePath, requiresGinkgo
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// ginkgoPackages counts the packages with test files in dir, and below it when recursive,
+// skipping the directories go ignores. ok is false when one of them doesn't import Ginkgo.
+func ginkgoPackages(dir string, recursive bool) (count int, ok bool) {
+	ok = true
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+		if path != dir {
+			name := entry.Name()
+			if !recursive || name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+		}
+
+		testFiles, _ := filepath.Glob(filepath.Join(path, "*_test.go"))
+		if len(testFiles) == 0 {
+			return nil
+		}
+		for _, file := range testFiles {
+			if importsGinkgo(file) {
+				count++
+				return nil
+			}
+		}
+		ok = false
+		return filepath.SkipAll
+	})
+	return count, ok && err == nil
+}
+
+// importsGinkgo reports whether a Go file imports Ginkgo v2
+func importsGinkgo(path string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil && importPath == ginkgoImport {
+			return true
+		}
+	}
+	return false
+}
+
+// ginkgoReportFlag returns the JSON report path a command already passes to Ginkgo, or ""
+func ginkgoReportFlag(cmd []string) string {
+	for i, arg := range cmd {
+		name := strings.TrimLeft(arg, "-")
+		if value, ok := strings.CutPrefix(name, "ginkgo.json-report="); ok {
+			return value
+		}
+		if name == "ginkgo.json-report" && i+1 < len(cmd) {
+			return cmd[i+1]
+		}
+	}
+	return ""
+}
+
+// ginkgoSuiteDir returns the directory named by the line a Ginkgo suite prints when it
+// starts ("Running Suite: Calc Suite - /src/calc"), or "" when the output has none
+func ginkgoSuiteDir(output []string) string {
+	for _, line := range output {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), ginkgoSuitePrefix); ok {
+			if i := strings.LastIndex(rest, " - "); i >= 0 {
+				return rest[i+len(" - "):]
+			}
+		}
+	}
+	return ""
+}
+
+// readGinkgoReport reads the report a suite in dir wrote, ignoring one left by a run
+// before since. The report is removed once read unless the command asked for it.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) readGinkgoReport(dir string, since time.Time) (*ginkgoReport, error) {
+	path := g.ginkgoReport
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() && info.ModTime().Before(since.Add(-ginkgoReportSlack)) {
+		return nil, fmt.Errorf("%s was written before the suite started", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if g.ginkgoOwnReport {
+		if err := os.Remove(path); err != nil {
+			g.logger.Debug("Failed to remove Ginkgo report %s: %v", path, err)
+		}
+	}
+
+	var reports []ginkgoReport
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("%s has no suites", path)
+	}
+	return &reports[0], nil
+}
+
+// recordGinkgoSuite reports the specs of a top-level test that ran a Ginkgo suite in place
+// of the test, as test cases under groups for their containers. It returns false when the
+// test is not a Ginkgo suite or its report can't be read, and when the suite failed without
+// a failing spec, so the test itself is reported too. Caller must hold g.mu.
+func (g *GoTestDefinition) recordGinkgoSuite(event *GoTestEvent, state *TestState) bool {
+	dir := ginkgoSuiteDir(state.Output)
+	if dir == "" {
+		return false
+	}
+	report, err := g.readGinkgoReport(dir, state.StartTime)
+	if err != nil {
+		g.logger.Debug("Reporting %s in %s as a Go test, no Ginkgo report: %v", event.Test, event.Package, err)
+		return false
+	}
+	g.ginkgoSuites[event.Package] = event.Test
+	g.logger.Debug("Reporting Ginkgo suite %q in %s with %d specs", report.SuiteDescription, event.Package, len(report.SpecReports))
+
+	failed := g.sendGinkgoSpecs(event.Package, report.SpecReports)
+	if event.Action == "fail" && failed == 0 {
+		return false
+	}
+
+	delete(g.testStates, event.Package+"/"+event.Test)
+	g.packageTestsDone[event.Package]++
+	if event.Action == "fail" {
+		g.packageStatuses[event.Package] = "FAIL"
+	} else if g.packageStatuses[event.Package] != "FAIL" {
+		g.packageStatuses[event.Package] = "PASS"
+	}
+	return true
+}
+
+// sendGinkgoSpecs sends a test case for each spec, and for suite nodes that failed, under
+// its package and containers, then the results of the container groups. Specs count
+// towards their package like top-level tests. It returns the number of failed test cases.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) sendGinkgoSpecs(packageName string, specs []ginkgoSpecReport) int {
+	containerStats := make(map[string]*SubgroupStats)
+	var containers [][]string // Container paths below the package, in the order first seen
+	failed := 0
+
+	for _, spec := range specs {
+		status := ginkgoStatus(spec.State)
+		name := spec.LeafNodeText
+		if spec.LeafNodeType != "It" {
+			if status != "FAIL" {
+				continue // Setup and reporting nodes are only worth a test case when they fail
+			}
+			name = strings.TrimSpace(spec.LeafNodeType + " " + spec.LeafNodeText)
+		}
+
+		chain := spec.ContainerHierarchyTexts
+		g.ensureGroupsDiscovered(packageName, chain)
+		for i := 0; i <= len(chain); i++ {
+			g.ensureGroupStarted(g.buildHierarchyFromPackage(packageName, chain[:i]))
+		}
+
+		var testError *ipc.TestError
+		var stdout, skipReason string
+		switch status {
+		case "FAIL":
+			testError = spec.testError()
+			failed++
+		case "SKIP":
+			skipReason = spec.skipReason()
+		}
+		if g.verbose || status == "FAIL" {
+			stdout = strings.Trim(spec.CapturedGinkgoWriterOutput+spec.CapturedStdOutErr, "\n")
+		}
+		duration := spec.RunTime.Seconds()
+		g.sendTestCaseWithGroups(name, g.buildHierarchyFromPackage(packageName, chain), status, duration, testError, nil, stdout, skipReason)
+
+		for i := 1; i <= len(chain); i++ {
+			key := strings.Join(g.buildHierarchyFromPackage(packageName, chain[:i]), "/")
+			stats, ok := containerStats[key]
+			if !ok {
+				stats = &SubgroupStats{}
+				containerStats[key] = stats
+				containers = append(containers, chain[:i])
+			}
+			stats.extendSpan(spec.StartTime, spec.EndTime)
+			stats.Duration += duration
+			stats.TotalTests++
+			switch status {
+			case "PASS":
+				stats.PassedTests++
+			case "FAIL":
+				stats.FailedTests++
+			case "SKIP":
+				stats.SkippedTests++
+			}
+		}
+
+		if pkgGroup, ok := g.packageGroups[packageName]; ok {
+			pkgGroup.Tests = append(pkgGroup.Tests, TestInfo{
+				Name:     strings.Join(append(append([]string{}, chain...), name), " "),
+				Status:   status,
+				Duration: duration,
+			})
+		}
+	}
+
+	// A container is first seen after its parent, so going backwards sends children first
+	for i := len(containers) - 1; i >= 0; i-- {
+		chain := containers[i]
+		stats := containerStats[strings.Join(g.buildHierarchyFromPackage(packageName, chain), "/")]
+		status := "PASS"
+		if stats.FailedTests > 0 {
+			status = "FAIL"
+		} else if stats.PassedTests == 0 && stats.SkippedTests > 0 {
+			status = "SKIP"
+		}
+		totals := ipc.GroupTotals{
+			Total:   stats.TotalTests,
+			Passed:  stats.PassedTests,
+			Failed:  stats.FailedTests,
+			Skipped: stats.SkippedTests,
+		}
+		parentNames := g.buildHierarchyFromPackage(packageName, chain[:len(chain)-1])
+		g.sendGroupResult(chain[len(chain)-1], parentNames, status, stats.wallClock(stats.Duration), totals)
+	}
+	return failed
+}
+
+// ginkgoStatus maps a Ginkgo spec state to a test case status
+func ginkgoStatus(state string) string {
+	switch state {
+	case "passed":
+		return "PASS"
+	case "skipped", "pending":
+		return "SKIP"
+	default:
+		return "FAIL"
+	}
+}
+
+// testError returns the error of a failed spec
+func (s ginkgoSpecReport) testError() *ipc.TestError {
+	testError := &ipc.TestError{Message: fmt.Sprintf("Spec %s", s.State)}
+	if s.Failure == nil {
+		return testError
+	}
+	if s.Failure.Message != "" {
+		testError.Message = s.Failure.Message
+	}
+	if s.Failure.ForwardedPanic != "" {
+		testError.Message += "\n" + s.Failure.ForwardedPanic
+	}
+	testError.Stack = s.Failure.Location.FullStackTrace
+	if s.Failure.Location.FileName != "" {
+		testError.Location = fmt.Sprintf("%s:%d", s.Failure.Location.FileName, s.Failure.Location.LineNumber)
+	}
+	switch s.State {
+	case "failed":
+		testError.FailureKind = ipc.FailureKindAssertion
+	case "panicked":
+		testError.ErrorType = goPanicErrorType
+		testError.FailureKind = ipc.FailureKindException
+	case "timedout":
+		testError.FailureKind = ipc.FailureKindTimeout
+	}
+	return testError
+}
+
+// skipReason returns the message a spec passed to Skip, or "pending" for a pending spec
+func (s ginkgoSpecReport) skipReason() string {
+	if s.State == "pending" {
+		return "pending"
+	}
+	if s.Failure != nil {
+		return s.Failure.Message
+	}
+	return ""
+}
+
+// ginkgoReproCommand returns a "go test" command rerunning the failed specs of the Ginkgo
+// suite run by suiteTest, focused on their full text. paths are the failures' container
+// texts followed by the spec text.
+func ginkgoReproCommand(pkg, suiteTest string, paths [][]string) string {
+	args := []string{"go", "test", "-run", ExactNamePattern([]string{suiteTest}), pkg}
+	var texts []string
+	for _, path := range paths {
+		if len(path) == 1 && hasAnyPrefix(path[0], ginkgoSuiteNodes) {
+			return ShellJoin(args) // A suite node failed, so the whole suite reruns
+		}
+		texts = append(texts, strings.Join(path, " "))
+	}
+	// Ginkgo matches the focus against the suite description, a space and the spec's text
+	focus := " " + strings.TrimPrefix(ExactNamePattern(uniqueSorted(texts)), "^")
+	args = append(args, "-ginkgo.focus="+focus)
+	return ShellJoin(args)
+}
@@ -0,0 +1,234 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// ginkgoCalcReport is a Ginkgo JSON report trimmed to the fields 3pio reads
+const ginkgoCalcReport = `[{
+  "SuitePath": "/src/calc",
+  "SuiteDescription": "Calc Suite",
+  "SpecReports": [
+    {"ContainerHierarchyTexts": null, "LeafNodeType": "BeforeSuite", "LeafNodeText": "", "State": "passed", "RunTime": 1000},
+    {"ContainerHierarchyTexts": ["Calculator", "when adding"], "LeafNodeType": "It", "LeafNodeText": "adds two numbers", "State": "passed", "RunTime": 2000000},
+    {"ContainerHierarchyTexts": ["Calculator", "when adding"], "LeafNodeType": "It", "LeafNodeText": "fails on purpose", "State": "failed", "RunTime": 3000000,
+     "Failure": {"Message": "Expected\n    <int>: 2\nto equal\n    <int>: 3", "Location": {"FileName": "/src/calc/calc_test.go", "LineNumber": 17, "FullStackTrace": "calc_test.init.func1.1.2()"}}},
+    {"ContainerHierarchyTexts": ["Calculator", "division"], "LeafNodeType": "It", "LeafNodeText": "divides", "State": "passed", "RunTime": 1000000},
+    {"ContainerHierarchyTexts": ["Calculator", "division"], "LeafNodeType": "It", "LeafNodeText": "pending spec", "State": "pending", "RunTime": 0},
+    {"ContainerHierarchyTexts": ["Calculator", "division"], "LeafNodeType": "It", "LeafNodeText": "skips", "State": "skipped", "RunTime": 1000000,
+     "Failure": {"Message": "not today", "Location": {"FileName": "/src/calc/calc_test.go", "LineNumber": 23}}}
+  ]
+}]`
+
+func TestGoTestDefinition_GinkgoSuite(t *testing.T) {
+	def := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+
+	// The command passes a Ginkgo flag, so Ginkgo mode turns on and asks for the report
+	command := def.ModifyCommand([]string{"go", "test", "./calc", "-ginkgo.v"}, "", "")
+	expected := []string{"go", "test", "-json", "./calc", "-ginkgo.v", "-ginkgo.json-report=" + GinkgoReportFile}
+	if !reflect.DeepEqual(command, expected) {
+		t.Fatalf("ModifyCommand() = %q, want %q", command, expected)
+	}
+
+	suiteDir := t.TempDir()
+	reportPath := filepath.Join(suiteDir, GinkgoReportFile)
+	if err := os.WriteFile(reportPath, []byte(ginkgoCalcReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const pkg = "example.com/calc"
+	now := time.Now()
+	events := []GoTestEvent{
+		{Action: "start", Package: pkg, Time: now},
+		{Action: "run", Package: pkg, Test: "TestCalc", Time: now},
+		{Action: "output", Package: pkg, Test: "TestCalc", Output: "=== RUN   TestCalc\n"},
+		{Action: "output", Package: pkg, Test: "TestCalc", Output: "Running Suite: Calc Suite - " + suiteDir + "\n"},
+		{Action: "fail", Package: pkg, Test: "TestCalc", Elapsed: 0.01, Time: now},
+		{Action: "run", Package: pkg, Test: "TestPlain", Time: now},
+		{Action: "pass", Package: pkg, Test: "TestPlain", Elapsed: 0.01, Time: now},
+		{Action: "fail", Package: pkg, Elapsed: 0.05, Time: now},
+	}
+	for _, event := range events {
+		if err := def.processEvent(&event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	testCases := make(map[string]ipc.TestCasePayload)
+	groupResults := make(map[string]ipc.GroupResultPayload)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		event, err := ipc.ParseEvent([]byte(line))
+		if err != nil {
+			t.Fatalf("Invalid IPC event %s: %v", line, err)
+		}
+		switch e := event.(type) {
+		case ipc.GroupTestCaseEvent:
+			testCases[e.Payload.TestName] = e.Payload
+		case ipc.GroupResultEvent:
+			groupResults[e.Payload.GroupName] = e.Payload
+		}
+	}
+
+	if _, ok := testCases["TestCalc"]; ok {
+		t.Error("Expected the test running the suite to be replaced by its specs")
+	}
+	if _, ok := testCases["BeforeSuite"]; ok {
+		t.Error("Expected a passing BeforeSuite not to be reported")
+	}
+	failing, ok := testCases["fails on purpose"]
+	if !ok {
+		t.Fatalf("Expected a test case per spec, got %v", testCases)
+	}
+	if want := []string{pkg, "Calculator", "when adding"}; !reflect.DeepEqual(failing.ParentNames, want) {
+		t.Errorf("Expected parent names %q, got %q", want, failing.ParentNames)
+	}
+	if failing.Status != "FAIL" || failing.Error == nil || failing.Error.Location != "/src/calc/calc_test.go:17" || failing.Error.FailureKind != ipc.FailureKindAssertion {
+		t.Errorf("Expected an assertion failure at calc_test.go:17, got %s %+v", failing.Status, failing.Error)
+	}
+	if failing.Duration != 3 {
+		t.Errorf("Expected the spec's run time of 3ms, got %v", failing.Duration)
+	}
+	if pending := testCases["pending spec"]; pending.Status != "SKIP" || pending.SkipReason != "pending" {
+		t.Errorf("Expected the pending spec to be skipped as pending, got %s %q", pending.Status, pending.SkipReason)
+	}
+	if skipped := testCases["skips"]; skipped.Status != "SKIP" || skipped.SkipReason != "not today" {
+		t.Errorf("Expected the Skip message as the skip reason, got %s %q", skipped.Status, skipped.SkipReason)
+	}
+
+	adding := groupResults["when adding"]
+	if adding.Status != "FAIL" || adding.Totals.Total != 2 || adding.Totals.Failed != 1 {
+		t.Errorf("Expected the when adding container to fail with 2 specs, got %s %+v", adding.Status, adding.Totals)
+	}
+	if want := []string{pkg, "Calculator"}; !reflect.DeepEqual(adding.ParentNames, want) {
+		t.Errorf("Expected the container under %q, got %q", want, adding.ParentNames)
+	}
+	if calculator := groupResults["Calculator"]; calculator.Totals.Total != 5 || calculator.Totals.Skipped != 2 {
+		t.Errorf("Expected the Calculator container to count its 5 specs, got %+v", calculator.Totals)
+	}
+	if totals := groupResults[pkg].Totals; totals.Total != 6 || totals.Passed != 3 || totals.Failed != 1 {
+		t.Errorf("Expected the package to count 5 specs and TestPlain, got %+v", totals)
+	}
+
+	if _, err := os.Stat(reportPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the report 3pio asked for to be removed once read, got %v", err)
+	}
+	repro := def.ReproCommand([]FailedTest{{Name: "fails on purpose", ParentNames: failing.ParentNames}})
+	if want := "go test -run '^TestCalc$' example.com/calc '-ginkgo.focus= Calculator when adding fails on purpose$'"; repro != want {
+		t.Errorf("ReproCommand() = %s, want %s", repro, want)
+	}
+}
+
+func TestGoTestDefinition_GinkgoSuiteWithoutReport(t *testing.T) {
+	def := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	def.EnableGinkgo()
+	def.ModifyCommand([]string{"go", "test", "./calc"}, "", "")
+
+	// The suite ran, but wrote no report: the test is reported as a plain Go test
+	const pkg = "example.com/calc"
+	events := []GoTestEvent{
+		{Action: "start", Package: pkg},
+		{Action: "run", Package: pkg, Test: "TestCalc"},
+		{Action: "output", Package: pkg, Test: "TestCalc", Output: "Running Suite: Calc Suite - " + t.TempDir() + "\n"},
+		{Action: "pass", Package: pkg, Test: "TestCalc", Elapsed: 0.01},
+		{Action: "pass", Package: pkg, Elapsed: 0.05},
+	}
+	for _, event := range events {
+		if err := def.processEvent(&event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	if !strings.Contains(string(data), `"testName":"TestCalc"`) {
+		t.Errorf("Expected TestCalc reported as a Go test, got:\n%s", data)
+	}
+}
+
+func TestUsesGinkgo(t *testing.T) {
+	dir := t.TempDir()
+	ginkgoTest := "package a_test\n\nimport (\n\t\"testing\"\n\n\t. \"github.com/onsi/ginkgo/v2\"\n)\n\nfunc TestA(t *testing.T) { RunSpecs(t, \"A\") }\n"
+	files := map[string]string{
+		"go.mod":                 "module example.com/shop\n\ngo 1.23\n\nrequire github.com/onsi/ginkgo/v2 v2.20.2\n",
+		"a/a_suite_test.go":      ginkgoTest,
+		"a/a_test.go":            "package a_test\n",
+		"b/b_test.go":            "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+		"a/testdata/x/x_test.go": "package x\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	def := &GoTestDefinition{}
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"go", "test", "./a"}, true},
+		{[]string{"go", "test", "./a/..."}, true},
+		{[]string{"go", "test", "example.com/shop/a"}, true},
+		{[]string{"go", "test", "./..."}, false}, // b has tests without Ginkgo
+		{[]string{"go", "test", "./b"}, false},
+		{[]string{"go", "test", "github.com/other/pkg"}, false},
+		{[]string{"go", "test", "./...", "-ginkgo.v"}, true},
+	}
+	for _, tt := range tests {
+		if got := def.usesGinkgo(tt.args); got != tt.expected {
+			t.Errorf("usesGinkgo(%q) = %v, want %v", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestGinkgoReportFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"go", "test", "./...", "-ginkgo.json-report=out.json"}, "out.json"},
+		{[]string{"go", "test", "./...", "--ginkgo.json-report", "/tmp/r.json"}, "/tmp/r.json"},
+		{[]string{"go", "test", "./...", "-ginkgo.v"}, ""},
+	}
+	for _, tt := range tests {
+		if got := ginkgoReportFlag(tt.args); got != tt.expected {
+			t.Errorf("ginkgoReportFlag(%q) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+}
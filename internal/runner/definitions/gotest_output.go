@@ -0,0 +1,96 @@
+package definitions
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// isGoTestSummaryLine returns true for the status lines go test prints for a package
+// ("PASS", "FAIL", "ok  \tpkg\t0.01s", "FAIL\tpkg\t0.01s", "?   \tpkg\t[no test files]"),
+// which the package result already reports
+func isGoTestSummaryLine(output string) bool {
+	line := strings.TrimRight(output, "\r\n")
+	if line == "PASS" || line == "FAIL" {
+		return true
+	}
+	return strings.HasPrefix(line, "ok  \t") || strings.HasPrefix(line, "FAIL\t") || strings.HasPrefix(line, "?   \t")
+}
+
+// recordPackageOutput sends package-level output printed outside any test, such as
+// logging from init or TestMain, to the package group's stdout. Output is attributed by
+// the event's Package alone, never by which package printed last, since go test -p
+// interleaves the events of packages running at the same time. Caller must hold g.mu.
+func (g *GoTestDefinition) recordPackageOutput(event *GoTestEvent) {
+	if event.Output == "" || isGoTestSummaryLine(event.Output) {
+		return
+	}
+	// Output after the package result (or before its start) has no group to go to
+	if !g.packageStarted[event.Package] {
+		return
+	}
+	g.sendGroupStdout(event.Package, []string{}, event.Output)
+}
+
+func (g *GoTestDefinition) sendGroupStdout(groupName string, parentNames []string, chunk string) {
+	if err := g.ipcWriter.WriteEvent(ipc.NewGroupStdoutEvent(groupName, parentNames, chunk)); err != nil {
+		g.logger.Debug("Failed to write group stdout event: %v", err)
+	}
+}
+
+// goTestFramingPrefixes start the lines go test -v prints around a test's own output
+var goTestFramingPrefixes = []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- PASS", "--- FAIL", "--- SKIP", "--- BENCH"}
+
+// isGoTestVerbose reports whether a go test command runs with -v, the only case where
+// the output logged by passing tests (t.Log) is meant to be seen
+func isGoTestVerbose(cmd []string) bool {
+	for _, arg := range cmd {
+		switch arg {
+		case "-args":
+			return false // Everything after -args goes to the test binary as is
+		case "-v", "-v=true", "-test.v", "-test.v=true":
+			return true
+		}
+	}
+	return false
+}
+
+// goTestStdout returns the output a test logged, without the lines go test prints
+// around it, or "" when it logged nothing
+func goTestStdout(output []string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.Join(output, ""), "\n") {
+		if hasAnyPrefix(strings.TrimSpace(line), goTestFramingPrefixes) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// goTestLogLineRegex matches a line logged by t.Log, t.Skip and friends, which go test
+// prefixes with the file and line of the call ("    db_test.go:12: message")
+var goTestLogLineRegex = regexp.MustCompile(`^\s*[\w.-]+\.go:\d+: (.*)$`)
+
+// goTestSkipReason returns the message a skipped test passed to t.Skip, which is the last
+// line it logged, or "" when it logged nothing
+func goTestSkipReason(output []string) string {
+	lines := strings.Split(strings.Join(output, ""), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if m := goTestLogLineRegex.FindStringSubmatch(lines[i]); m != nil {
+			return strings.TrimSpace(m[1])
+		}
+	}
+	return ""
+}
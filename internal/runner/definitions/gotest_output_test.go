@@ -0,0 +1,220 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestGoTestDefinition_PackageOutput(t *testing.T) {
+	def := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+
+	events := []GoTestEvent{
+		{Action: "start", Package: "example.com/pkg"},
+		{Action: "output", Package: "example.com/pkg", Output: "2025/09/24 12:00:00 connecting to test database\n"},
+		{Action: "run", Package: "example.com/pkg", Test: "TestQuery"},
+		{Action: "output", Package: "example.com/pkg", Test: "TestQuery", Output: "=== RUN   TestQuery\n"},
+		{Action: "pass", Package: "example.com/pkg", Test: "TestQuery", Elapsed: 0.01},
+		{Action: "output", Package: "example.com/pkg", Output: "PASS\n"},
+		{Action: "output", Package: "example.com/pkg", Output: "coverage: 80.0% of statements\n"},
+		{Action: "output", Package: "example.com/pkg", Output: "ok  \texample.com/pkg\t0.012s\n"},
+		{Action: "pass", Package: "example.com/pkg", Elapsed: 0.012},
+	}
+	for _, event := range events {
+		if err := def.processEvent(&event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	var chunks []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		event, err := ipc.ParseEvent([]byte(line))
+		if err != nil {
+			t.Fatalf("Invalid IPC event %s: %v", line, err)
+		}
+		if stdout, ok := event.(ipc.GroupStdoutChunkEvent); ok {
+			if stdout.Payload.GroupName != "example.com/pkg" || len(stdout.Payload.ParentNames) != 0 {
+				t.Errorf("Expected output for the package group, got %s %v", stdout.Payload.GroupName, stdout.Payload.ParentNames)
+			}
+			chunks = append(chunks, stdout.Payload.Chunk)
+		}
+	}
+
+	want := []string{"2025/09/24 12:00:00 connecting to test database\n", "coverage: 80.0% of statements\n"}
+	if len(chunks) != len(want) || chunks[0] != want[0] || chunks[1] != want[1] {
+		t.Errorf("Expected package output %q, got %q", want, chunks)
+	}
+}
+
+func TestGoTestDefinition_InterleavedPackageOutput(t *testing.T) {
+	def := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+
+	// go test -p runs packages concurrently and their events interleave line by line
+	const a, b = "example.com/shop", "example.com/users"
+	events := []GoTestEvent{
+		{Action: "start", Package: a},
+		{Action: "start", Package: b},
+		{Action: "output", Package: a, Output: "shop: seeding catalog\n"},
+		{Action: "output", Package: b, Output: "users: starting fake auth server\n"},
+		{Action: "run", Package: a, Test: "TestCheckout"},
+		{Action: "output", Package: a, Test: "TestCheckout", Output: "=== RUN   TestCheckout\n"},
+		{Action: "output", Package: b, Output: "users: auth server listening on :9000\n"},
+		{Action: "run", Package: b, Test: "TestLogin"},
+		{Action: "output", Package: a, Output: "shop: catalog ready\n"},
+		{Action: "output", Package: b, Test: "TestLogin", Output: "=== RUN   TestLogin\n"},
+		{Action: "pass", Package: b, Test: "TestLogin", Elapsed: 0.01},
+		{Action: "output", Package: b, Output: "PASS\n"},
+		{Action: "pass", Package: a, Test: "TestCheckout", Elapsed: 0.02},
+		{Action: "output", Package: b, Output: "ok  \texample.com/users\t0.011s\n"},
+		{Action: "pass", Package: b, Elapsed: 0.011},
+		{Action: "output", Package: a, Output: "shop: dropping catalog\n"},
+		{Action: "output", Package: a, Output: "ok  \texample.com/shop\t0.021s\n"},
+		{Action: "pass", Package: a, Elapsed: 0.021},
+	}
+	for _, event := range events {
+		if err := def.processEvent(&event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	output := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		event, err := ipc.ParseEvent([]byte(line))
+		if err != nil {
+			t.Fatalf("Invalid IPC event %s: %v", line, err)
+		}
+		if stdout, ok := event.(ipc.GroupStdoutChunkEvent); ok {
+			output[stdout.Payload.GroupName] += stdout.Payload.Chunk
+		}
+	}
+
+	want := map[string]string{
+		a: "shop: seeding catalog\nshop: catalog ready\nshop: dropping catalog\n",
+		b: "users: starting fake auth server\nusers: auth server listening on :9000\n",
+	}
+	if len(output) != len(want) {
+		t.Errorf("Expected output for %d package groups, got %v", len(want), output)
+	}
+	for pkg, expected := range want {
+		if output[pkg] != expected {
+			t.Errorf("Expected %s output %q, got %q", pkg, expected, output[pkg])
+		}
+	}
+}
+
+func TestIsGoTestSummaryLine(t *testing.T) {
+	for output, want := range map[string]bool{
+		"PASS\n":                          true,
+		"FAIL\n":                          true,
+		"ok  \texample.com/pkg\t0.012s\n": true,
+		"FAIL\texample.com/pkg\t0.012s\n": true,
+		"?   \texample.com/cmd\t[no test files]\n": true,
+		"PASSWORD set from environment\n":          false,
+		"coverage: 80.0% of statements\n":          false,
+	} {
+		if got := isGoTestSummaryLine(output); got != want {
+			t.Errorf("isGoTestSummaryLine(%q) = %v, want %v", output, got, want)
+		}
+	}
+}
+
+func TestGoTestDefinition_TestCaseStdout(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		command []string
+		want    string
+	}{
+		{"verbose", []string{"go", "test", "-v", "./..."}, "    db_test.go:12: inserted 3 rows\n    db_test.go:13: done"},
+		{"not verbose", []string{"go", "test", "./..."}, ""},
+		{"-v for the test binary", []string{"go", "test", "./...", "-args", "-v"}, ""},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			def := NewGoTestDefinition(createTestLogger(t))
+			def.ModifyCommand(tc.command, "", "")
+			ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+			var err error
+			def.ipcWriter, err = NewIPCWriter(ipcPath)
+			if err != nil {
+				t.Fatalf("Failed to create IPC writer: %v", err)
+			}
+
+			events := []GoTestEvent{
+				{Action: "start", Package: "example.com/db"},
+				{Action: "run", Package: "example.com/db", Test: "TestInsert"},
+				{Action: "output", Package: "example.com/db", Test: "TestInsert", Output: "=== RUN   TestInsert\n"},
+				{Action: "output", Package: "example.com/db", Test: "TestInsert", Output: "    db_test.go:12: inserted 3 rows\n"},
+				{Action: "output", Package: "example.com/db", Test: "TestInsert", Output: "    db_test.go:13: done\n"},
+				{Action: "output", Package: "example.com/db", Test: "TestInsert", Output: "--- PASS: TestInsert (0.00s)\n"},
+				{Action: "pass", Package: "example.com/db", Test: "TestInsert", Elapsed: 0.001},
+				{Action: "pass", Package: "example.com/db", Elapsed: 0.01},
+			}
+			for _, event := range events {
+				if err := def.processEvent(&event); err != nil {
+					t.Fatalf("Failed to process event: %v", err)
+				}
+			}
+			_ = def.ipcWriter.Close()
+
+			data, err := os.ReadFile(ipcPath)
+			if err != nil {
+				t.Fatalf("Failed to read IPC file: %v", err)
+			}
+			found := false
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				event, err := ipc.ParseEvent([]byte(line))
+				if err != nil {
+					t.Fatalf("Invalid IPC event %s: %v", line, err)
+				}
+				if testCase, ok := event.(ipc.GroupTestCaseEvent); ok {
+					found = true
+					if testCase.Payload.Stdout != tc.want {
+						t.Errorf("Expected stdout %q, got %q", tc.want, testCase.Payload.Stdout)
+					}
+				}
+			}
+			if !found {
+				t.Fatal("Expected a test case event")
+			}
+		})
+	}
+}
+
+func TestGoTestSkipReason(t *testing.T) {
+	for _, tc := range []struct {
+		output []string
+		want   string
+	}{
+		{[]string{"=== RUN   TestVacuum\n", "    db_test.go:40: needs postgres\n", "--- SKIP: TestVacuum (0.00s)\n"}, "needs postgres"},
+		{[]string{"=== RUN   TestVacuum\n", "    db_test.go:38: connecting\n", "    db_test.go:40: no server\n", "--- SKIP: TestVacuum (0.00s)\n"}, "no server"},
+		{[]string{"=== RUN   TestVacuum\n", "--- SKIP: TestVacuum (0.00s)\n"}, ""},
+	} {
+		if got := goTestSkipReason(tc.output); got != tc.want {
+			t.Errorf("goTestSkipReason(%q) = %q, want %q", tc.output, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package definitions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// goPanicErrorType is the errorType reported for tests and packages that panicked
+const goPanicErrorType = "PANIC"
+
+// splitGoPanic splits test output containing a panic into a message and the goroutine
+// dump. The message keeps any output logged before the panic, without blank lines.
+// ok is false when the output contains no panic.
+func splitGoPanic(output []string) (message, stack string, ok bool) {
+	lines := strings.Split(strings.Join(output, ""), "\n")
+
+	panicIdx := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic: ") {
+			panicIdx = i
+			break
+		}
+	}
+	if panicIdx == -1 {
+		return "", "", false
+	}
+
+	dumpIdx := len(lines)
+	for i := panicIdx + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "goroutine ") && strings.HasSuffix(lines[i], ":") {
+			dumpIdx = i
+			break
+		}
+	}
+
+	var messageLines []string
+	for _, line := range lines[:dumpIdx] {
+		if strings.TrimSpace(line) != "" {
+			messageLines = append(messageLines, line)
+		}
+	}
+	return strings.Join(messageLines, "\n"), strings.TrimSpace(strings.Join(lines[dumpIdx:], "\n")), true
+}
+
+// goTestError builds the error payload for a failed test from its buffered output.
+// Data races found by -race lead the message, since they are usually why the test
+// failed. Panics are split into the panic message and the goroutine dump. Go has no
+// assertions, so a failure without either is one reported with t.Error or t.Fatal.
+func goTestError(output []string) *ipc.TestError {
+	if message, ok := goRaceMessage(output); ok {
+		return &ipc.TestError{
+			Message:     message,
+			ErrorType:   goRaceErrorType,
+			FailureKind: ipc.FailureKindDataRace,
+		}
+	}
+	if message, stack, ok := splitGoPanic(output); ok {
+		kind := ipc.FailureKindException
+		if strings.Contains(message, "panic: test timed out") {
+			kind = ipc.FailureKindTimeout
+		}
+		return &ipc.TestError{
+			Message:     message,
+			Stack:       stack,
+			ErrorType:   goPanicErrorType,
+			FailureKind: kind,
+		}
+	}
+
+	message := strings.Join(output, "\n")
+	if message == "" {
+		return nil
+	}
+	return &ipc.TestError{
+		Message:     message,
+		FailureKind: ipc.FailureKindAssertion,
+	}
+}
+
+// recordPackagePanicOutput buffers package-level output from a "panic:" line onwards.
+// It returns false for output before any panic. Caller must hold g.mu.
+func (g *GoTestDefinition) recordPackagePanicOutput(event *GoTestEvent) bool {
+	chunks, panicking := g.packagePanics[event.Package]
+	if !panicking && !strings.HasPrefix(event.Output, "panic: ") {
+		return false
+	}
+
+	// The package's own FAIL line is not part of the panic
+	trimmed := strings.TrimSpace(event.Output)
+	if strings.HasPrefix(trimmed, "FAIL\t") || strings.HasPrefix(trimmed, "exit status ") {
+		return true
+	}
+	g.packagePanics[event.Package] = append(chunks, event.Output)
+	return true
+}
+
+// failPanickedTests sends failing results for tests of a package that were still running
+// when it exited because of a panic. go test reports no result for a test whose goroutine
+// panicked, and a package-level panic is attached to the running tests its goroutine dump
+// mentions. It returns the package-level panic output that no test claimed, if any.
+// Caller must hold g.mu.
+func (g *GoTestDefinition) failPanickedTests(event *GoTestEvent) []string {
+	packagePanic := g.packagePanics[event.Package]
+	delete(g.packagePanics, event.Package)
+	_, packageStack, _ := splitGoPanic(packagePanic)
+
+	var running []*TestState
+	for _, state := range g.testStates {
+		if state.Package == event.Package {
+			running = append(running, state)
+		}
+	}
+	if len(running) == 0 {
+		return packagePanic
+	}
+
+	crashed := make(map[string]bool)
+	claimed := false
+	for _, state := range running {
+		if _, _, ok := splitGoPanic(state.Output); ok {
+			crashed[state.Name] = true
+			continue
+		}
+		topLevel := strings.SplitN(state.Name, "/", 2)[0]
+		if packageStack != "" && (strings.Contains(packageStack, "."+topLevel+"(") || strings.Contains(packageStack, "."+topLevel+".func")) {
+			state.Output = append(state.Output, packagePanic...)
+			crashed[state.Name] = true
+			claimed = true
+		}
+	}
+
+	// A crashed subtest also ends its running parent tests
+	for _, state := range running {
+		for name := range crashed {
+			if strings.HasPrefix(name, state.Name+"/") {
+				crashed[state.Name] = true
+			}
+		}
+	}
+
+	// Deepest tests first, so subtest groups are settled before their parents
+	names := make([]string, 0, len(crashed))
+	for name := range crashed {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if depthI, depthJ := strings.Count(names[i], "/"), strings.Count(names[j], "/"); depthI != depthJ {
+			return depthI > depthJ
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		state := g.testStates[event.Package+"/"+name]
+		var elapsed float64
+		if !state.StartTime.IsZero() && !event.Time.IsZero() {
+			elapsed = event.Time.Sub(state.StartTime).Seconds()
+		}
+		g.logger.Debug("Failing test %s in package %s, which panicked without a result", name, event.Package)
+		g.recordTestResult(&GoTestEvent{
+			Time:    event.Time,
+			Action:  "fail",
+			Package: event.Package,
+			Test:    name,
+			Elapsed: elapsed,
+		})
+	}
+
+	if claimed {
+		return nil
+	}
+	return packagePanic
+}
@@ -0,0 +1,206 @@
+package definitions
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// outputEvents turns lines of go test output into output events for one test (or the package)
+func outputEvents(pkg, test string, lines ...string) []*GoTestEvent {
+	events := make([]*GoTestEvent, len(lines))
+	for i, line := range lines {
+		events[i] = &GoTestEvent{Action: "output", Package: pkg, Test: test, Output: line + "\n"}
+	}
+	return events
+}
+
+func newPanicTestDefinition(t *testing.T) (*GoTestDefinition, *TestIPCCapture, func()) {
+	t.Helper()
+	g := NewGoTestDefinition(createTestLogger(t))
+
+	ipcPath := filepath.Join(t.TempDir(), "test.jsonl")
+	ipcWriter, err := NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	g.ipcWriter = ipcWriter
+	t.Cleanup(func() { _ = ipcWriter.Close() })
+	return g, NewTestIPCCapture(ipcPath), func() { _ = ipcWriter.Close() }
+}
+
+func processGoEvents(t *testing.T, g *GoTestDefinition, events []*GoTestEvent) {
+	t.Helper()
+	for _, event := range events {
+		if err := g.processEvent(event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+}
+
+func TestSplitGoPanic(t *testing.T) {
+	output := []string{
+		"=== RUN   TestBoom\n",
+		"    a_test.go:11: about to panic\n",
+		"panic: assignment to entry in nil map [recovered]\n",
+		"\n",
+		"goroutine 7 [running]:\n",
+		"example.com/shop.TestBoom(0xc000)\n",
+		"\t/src/shop/a_test.go:13 +0x53\n",
+	}
+
+	message, stack, ok := splitGoPanic(output)
+	if !ok {
+		t.Fatal("Expected a panic to be found")
+	}
+	if message != "=== RUN   TestBoom\n    a_test.go:11: about to panic\npanic: assignment to entry in nil map [recovered]" {
+		t.Errorf("Unexpected message %q", message)
+	}
+	if stack != "goroutine 7 [running]:\nexample.com/shop.TestBoom(0xc000)\n\t/src/shop/a_test.go:13 +0x53" {
+		t.Errorf("Unexpected stack %q", stack)
+	}
+
+	if _, _, ok := splitGoPanic([]string{"--- FAIL: TestX\n", "    x_test.go:5: want 1, got 2\n"}); ok {
+		t.Error("Expected no panic in plain failure output")
+	}
+}
+
+func TestGoTestError_FailureKind(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		kind   ipc.FailureKind
+	}{
+		{"t.Errorf", []string{"    x_test.go:5: want 1, got 2\n"}, ipc.FailureKindAssertion},
+		{"panic", []string{"panic: runtime error: index out of range\n", "\n", "goroutine 7 [running]:\n"}, ipc.FailureKindException},
+		{"timeout", []string{"panic: test timed out after 1s\n", "\trunning tests:\n", "\n", "goroutine 1 [running]:\n"}, ipc.FailureKindTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := goTestError(tt.output).FailureKind; kind != tt.kind {
+				t.Errorf("Expected failure kind %s, got %v", tt.kind, kind)
+			}
+		})
+	}
+}
+
+func TestGoTestDefinition_PanicInTest(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	events := []*GoTestEvent{{Action: "run", Package: "example.com/shop", Test: "TestBoom"}}
+	events = append(events, outputEvents("example.com/shop", "TestBoom",
+		"=== RUN   TestBoom",
+		"--- FAIL: TestBoom (0.00s)",
+		"panic: assignment to entry in nil map [recovered]",
+		"",
+		"goroutine 7 [running]:",
+		"example.com/shop.TestBoom(0xc000)",
+		"\t/src/shop/a_test.go:13 +0x53",
+	)...)
+	events = append(events,
+		&GoTestEvent{Action: "fail", Package: "example.com/shop", Test: "TestBoom"},
+		&GoTestEvent{Action: "fail", Package: "example.com/shop", Elapsed: 0.01},
+	)
+	processGoEvents(t, g, events)
+	done()
+
+	cases := capture.GetEventsByType("testCase")
+	if len(cases) != 1 {
+		t.Fatalf("Expected 1 test case, got %d", len(cases))
+	}
+	testError := cases[0]["payload"].(map[string]interface{})["error"].(map[string]interface{})
+	if testError["errorType"] != "PANIC" {
+		t.Errorf("Expected PANIC error type, got %v", testError["errorType"])
+	}
+	if !strings.HasSuffix(testError["message"].(string), "panic: assignment to entry in nil map [recovered]") {
+		t.Errorf("Expected the panic in the message, got %q", testError["message"])
+	}
+	if !strings.HasPrefix(testError["stack"].(string), "goroutine 7 [running]:") {
+		t.Errorf("Expected the goroutine dump as the stack, got %q", testError["stack"])
+	}
+	if errs := capture.GetEventsByType("testGroupError"); len(errs) != 0 {
+		t.Errorf("Expected no group error for a panic inside a test, got %v", errs)
+	}
+}
+
+func TestGoTestDefinition_PanicInGoroutineWithoutResult(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	// go test reports no result for a test whose spawned goroutine panicked
+	events := []*GoTestEvent{
+		{Action: "run", Package: "example.com/shop", Test: "TestOK"},
+		{Action: "pass", Package: "example.com/shop", Test: "TestOK"},
+		{Action: "run", Package: "example.com/shop", Test: "TestAsync"},
+		{Action: "run", Package: "example.com/shop", Test: "TestAsync/worker"},
+	}
+	events = append(events, outputEvents("example.com/shop", "TestAsync/worker",
+		"panic: from goroutine",
+		"",
+		"goroutine 9 [running]:",
+		"example.com/shop.TestAsync.func1.1()",
+	)...)
+	events = append(events, &GoTestEvent{Action: "fail", Package: "example.com/shop", Elapsed: 0.01})
+	processGoEvents(t, g, events)
+	done()
+
+	statuses := make(map[string]string)
+	for _, event := range capture.GetEventsByType("testCase") {
+		payload := event["payload"].(map[string]interface{})
+		statuses[payload["testName"].(string)] = payload["status"].(string)
+		if payload["testName"] == "worker" {
+			testError := payload["error"].(map[string]interface{})
+			if testError["errorType"] != "PANIC" || testError["message"] != "panic: from goroutine" {
+				t.Errorf("Unexpected error for the panicking subtest: %v", testError)
+			}
+		}
+	}
+	expected := map[string]string{"TestOK": "PASS", "TestAsync": "FAIL", "worker": "FAIL"}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %q (all: %v)", name, status, statuses[name], statuses)
+		}
+	}
+
+	results := capture.GetEventsByType("testGroupResult")
+	last := results[len(results)-1]["payload"].(map[string]interface{})
+	totals := last["totals"].(map[string]interface{})
+	if last["groupName"] != "example.com/shop" || totals["failed"] != float64(1) || totals["passed"] != float64(1) {
+		t.Errorf("Expected the package to count the panicked test, got %v", last)
+	}
+}
+
+func TestGoTestDefinition_PanicInTestMain(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	events := []*GoTestEvent{{Action: "start", Package: "example.com/shop"}}
+	events = append(events, outputEvents("example.com/shop", "",
+		"panic: setup exploded",
+		"",
+		"goroutine 1 [running]:",
+		"example.com/shop.TestMain(...)",
+		"\t/src/shop/main_test.go:6",
+		"FAIL\texample.com/shop\t0.003s",
+	)...)
+	events = append(events, &GoTestEvent{Action: "fail", Package: "example.com/shop", Elapsed: 0.004})
+	processGoEvents(t, g, events)
+	done()
+
+	errs := capture.GetEventsByType("testGroupError")
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 group error, got %d", len(errs))
+	}
+	payload := errs[0]["payload"].(map[string]interface{})
+	if payload["errorType"] != "PANIC" {
+		t.Errorf("Expected PANIC error type, got %v", payload["errorType"])
+	}
+	groupError := payload["error"].(map[string]interface{})
+	if groupError["message"] != "panic: setup exploded" {
+		t.Errorf("Unexpected message %q", groupError["message"])
+	}
+	if groupError["stack"] != "goroutine 1 [running]:\nexample.com/shop.TestMain(...)\n\t/src/shop/main_test.go:6" {
+		t.Errorf("Unexpected stack %q", groupError["stack"])
+	}
+}
@@ -0,0 +1,49 @@
+package definitions
+
+import "strings"
+
+// goRaceErrorType is the errorType reported for tests failed by the race detector
+const goRaceErrorType = "DATA RACE"
+
+// goRaceFrame is the line of "=" signs before and after each race detector report
+const goRaceFrame = "=================="
+
+// splitGoRaces separates the race detector's reports from the rest of a test's output.
+// Each report starts with "WARNING: DATA RACE" and is framed by goRaceFrame lines, which
+// are dropped. ok is false when the output contains no race report.
+func splitGoRaces(output []string) (races []string, rest []string, ok bool) {
+	for i := 0; i < len(output); i++ {
+		if strings.TrimSpace(output[i]) != goRaceFrame || i+1 == len(output) || !strings.HasPrefix(output[i+1], "WARNING: DATA RACE") {
+			rest = append(rest, output[i])
+			continue
+		}
+		end := i + 1
+		for end < len(output) && strings.TrimSpace(output[end]) != goRaceFrame {
+			end++
+		}
+		races = append(races, strings.TrimSpace(strings.Join(output[i+1:end], "")))
+		i = end
+	}
+	return races, rest, len(races) > 0
+}
+
+// goRaceMessage builds a failed test's error message from the race reports in its output,
+// followed by the rest of the output without blank lines, such as the testing package's
+// "race detected during execution of test". ok is false when there is no race report.
+func goRaceMessage(output []string) (string, bool) {
+	races, rest, ok := splitGoRaces(output)
+	if !ok {
+		return "", false
+	}
+	message := strings.Join(races, "\n\n")
+	var restLines []string
+	for _, line := range strings.Split(strings.Join(rest, ""), "\n") {
+		if strings.TrimSpace(line) != "" {
+			restLines = append(restLines, line)
+		}
+	}
+	if len(restLines) > 0 {
+		message += "\n\n" + strings.Join(restLines, "\n")
+	}
+	return message, true
+}
@@ -0,0 +1,76 @@
+package definitions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// raceOutput is the output go test -race prints for a test with a data race
+var raceOutput = []string{
+	"=== RUN   TestCounter",
+	"==================",
+	"WARNING: DATA RACE",
+	"Read at 0x00c0000182d8 by goroutine 10:",
+	"  example.com/shop.TestCounter.func1()",
+	"      /src/shop/counter_test.go:15 +0x7b",
+	"",
+	"Previous write at 0x00c0000182d8 by goroutine 9:",
+	"  example.com/shop.TestCounter.func1()",
+	"      /src/shop/counter_test.go:15 +0x8d",
+	"==================",
+	"    counter_test.go:18: 2",
+	"    testing.go:1865: race detected during execution of test",
+	"--- FAIL: TestCounter (0.00s)",
+}
+
+func TestSplitGoRaces(t *testing.T) {
+	var output []string
+	for _, line := range raceOutput {
+		output = append(output, line+"\n")
+	}
+
+	races, rest, ok := splitGoRaces(output)
+	if !ok || len(races) != 1 {
+		t.Fatalf("Expected 1 race report, got %d (ok=%v)", len(races), ok)
+	}
+	if !strings.HasPrefix(races[0], "WARNING: DATA RACE\nRead at 0x00c0000182d8 by goroutine 10:") ||
+		!strings.HasSuffix(races[0], "/src/shop/counter_test.go:15 +0x8d") {
+		t.Errorf("Unexpected race report %q", races[0])
+	}
+	if len(rest) != 4 || rest[0] != "=== RUN   TestCounter\n" {
+		t.Errorf("Expected the output around the report to be kept, got %q", rest)
+	}
+
+	// A separator line alone is ordinary test output
+	if _, _, ok := splitGoRaces([]string{"==================\n", "    x_test.go:5: want 1, got 2\n"}); ok {
+		t.Error("Expected no race report in plain failure output")
+	}
+}
+
+func TestGoTestDefinition_DataRace(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	events := []*GoTestEvent{{Action: "run", Package: "example.com/shop", Test: "TestCounter"}}
+	events = append(events, outputEvents("example.com/shop", "TestCounter", raceOutput...)...)
+	events = append(events,
+		&GoTestEvent{Action: "fail", Package: "example.com/shop", Test: "TestCounter"},
+		&GoTestEvent{Action: "fail", Package: "example.com/shop", Elapsed: 0.01},
+	)
+	processGoEvents(t, g, events)
+	done()
+
+	cases := capture.GetEventsByType("testCase")
+	if len(cases) != 1 {
+		t.Fatalf("Expected 1 test case, got %d", len(cases))
+	}
+	testError := cases[0]["payload"].(map[string]interface{})["error"].(map[string]interface{})
+	if testError["errorType"] != goRaceErrorType || testError["failureKind"] != string(ipc.FailureKindDataRace) {
+		t.Errorf("Expected a %s error of kind %s, got %v", goRaceErrorType, ipc.FailureKindDataRace, testError)
+	}
+	message := testError["message"].(string)
+	if !strings.HasPrefix(message, "WARNING: DATA RACE\n") || !strings.HasSuffix(message, "testing.go:1865: race detected during execution of test\n--- FAIL: TestCounter (0.00s)") {
+		t.Errorf("Expected the race report to lead the message, got %q", message)
+	}
+}
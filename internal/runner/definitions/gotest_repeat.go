@@ -0,0 +1,54 @@
+package definitions
+
+import "github.com/zk/3pio/internal/ipc"
+
+// repeatedTest tracks the runs of a test that go test -count=N runs more than once
+type repeatedTest struct {
+	runs   int
+	failed bool           // Some run failed
+	error  *ipc.TestError // Error of the latest failing run
+}
+
+// recordRun counts a run of the test at key (package/test) and returns the status and
+// error to report for it. A test that failed on any run stays failed, keeping the error
+// of its latest failing run. Repeated runs carry "repeat" metadata, the number of runs
+// before this one, and "runStatus", this run's own status, so the report can list every
+// run. metadata is nil on the first run. Caller must hold g.mu.
+func (g *GoTestDefinition) recordRun(key, status string, testError *ipc.TestError) (string, *ipc.TestError, map[string]interface{}) {
+	test, ok := g.testRuns[key]
+	if !ok {
+		test = &repeatedTest{}
+		g.testRuns[key] = test
+	}
+	test.runs++
+	if status == "FAIL" {
+		test.failed = true
+		test.error = testError
+	}
+	if test.runs == 1 {
+		return status, testError, nil
+	}
+
+	metadata := map[string]interface{}{"repeat": test.runs - 1, "runStatus": status}
+	if test.failed {
+		return "FAIL", test.error, metadata
+	}
+	return status, testError, metadata
+}
+
+// recordRepeatedPackageTest updates a top-level test already listed in its package group
+// for another run, and reports whether it was there. Caller must hold g.mu.
+func (g *GoTestDefinition) recordRepeatedPackageTest(packageName, testName, status string, duration float64) bool {
+	pkgGroup, ok := g.packageGroups[packageName]
+	if !ok {
+		return false
+	}
+	for i := range pkgGroup.Tests {
+		if pkgGroup.Tests[i].Name == testName {
+			pkgGroup.Tests[i].Status = status
+			pkgGroup.Tests[i].Duration += duration
+			return true
+		}
+	}
+	return false
+}
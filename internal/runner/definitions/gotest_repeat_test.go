@@ -0,0 +1,94 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestGoTestDefinition_RepeatedRuns(t *testing.T) {
+	def := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "events.jsonl")
+	var err error
+	def.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+
+	// go test -count=3: TestFlip fails only on its second run
+	const pkg = "example.com/flip"
+	events := []GoTestEvent{{Action: "start", Package: pkg}}
+	for _, flipResult := range []string{"pass", "fail", "pass"} {
+		events = append(events,
+			GoTestEvent{Action: "run", Package: pkg, Test: "TestFlip"},
+			GoTestEvent{Action: "output", Package: pkg, Test: "TestFlip", Output: "=== RUN   TestFlip\n"},
+		)
+		if flipResult == "fail" {
+			events = append(events, GoTestEvent{Action: "output", Package: pkg, Test: "TestFlip", Output: "    flip_test.go:9: second run fails\n"})
+		}
+		events = append(events,
+			GoTestEvent{Action: flipResult, Package: pkg, Test: "TestFlip", Elapsed: 0.01},
+			GoTestEvent{Action: "run", Package: pkg, Test: "TestStable"},
+			GoTestEvent{Action: "pass", Package: pkg, Test: "TestStable", Elapsed: 0.01},
+		)
+	}
+	events = append(events, GoTestEvent{Action: "fail", Package: pkg, Elapsed: 0.05})
+
+	for _, event := range events {
+		if err := def.processEvent(&event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = def.ipcWriter.Close()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+	var flipRuns []ipc.TestCasePayload
+	var packageResult *ipc.GroupResultPayload
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		event, err := ipc.ParseEvent([]byte(line))
+		if err != nil {
+			t.Fatalf("Invalid IPC event %s: %v", line, err)
+		}
+		switch e := event.(type) {
+		case ipc.GroupTestCaseEvent:
+			if e.Payload.TestName == "TestFlip" {
+				flipRuns = append(flipRuns, e.Payload)
+			}
+		case ipc.GroupResultEvent:
+			if e.Payload.GroupName == pkg {
+				packageResult = &e.Payload
+			}
+		}
+	}
+
+	if len(flipRuns) != 3 {
+		t.Fatalf("Expected a test case event per run, got %d", len(flipRuns))
+	}
+	if flipRuns[0].Metadata != nil {
+		t.Errorf("Expected no repeat metadata on the first run, got %v", flipRuns[0].Metadata)
+	}
+	// The last run passed, but the test failed on an earlier one
+	last := flipRuns[2]
+	if last.Status != "FAIL" {
+		t.Errorf("Expected the test to stay failed after a passing run, got %s", last.Status)
+	}
+	if last.Metadata["repeat"] != float64(2) || last.Metadata["runStatus"] != "PASS" {
+		t.Errorf("Expected repeat 2 with runStatus PASS, got %v", last.Metadata)
+	}
+	if last.Error == nil || !strings.Contains(last.Error.Message, "second run fails") {
+		t.Errorf("Expected the failing run's error to be kept, got %+v", last.Error)
+	}
+
+	if packageResult == nil {
+		t.Fatal("Expected a package group result")
+	}
+	if totals := packageResult.Totals; totals.Total != 2 || totals.Passed != 1 || totals.Failed != 1 {
+		t.Errorf("Expected each test counted once in the package totals, got %+v", totals)
+	}
+}
@@ -3,13 +3,16 @@ package definitions
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/zk/3pio/internal/ipc"
 	"github.com/zk/3pio/internal/logger"
 )
 
@@ -909,6 +912,65 @@ func TestGoTestDefinition_DeeplyNestedSubgroups(t *testing.T) {
 	}
 }
 
+// Test that group durations are wall-clock spans rather than sums of overlapping tests
+func TestGoTestDefinition_NestedSubgroupWallClockDuration(t *testing.T) {
+	g := NewGoTestDefinition(createTestLogger(t))
+	ipcPath := filepath.Join(t.TempDir(), "test.jsonl")
+	ipcWriter, err := NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	g.ipcWriter = ipcWriter
+	t.Cleanup(func() { _ = ipcWriter.Close() })
+	capture := NewTestIPCCapture(ipcPath)
+
+	g.fileMapping = true
+	g.testFiles = map[string]map[string]string{
+		"example.com/cart": {"TestCart": "cart_test.go", "TestTotal": "cart_test.go"},
+	}
+
+	// TestCart/Add runs two parallel subtests, which finish after their parent reports
+	// its own (short) elapsed time. TestTotal runs alongside TestCart.
+	at := func(ms int) time.Time { return time.Unix(1700000000, 0).Add(time.Duration(ms) * time.Millisecond) }
+	events := []*GoTestEvent{
+		{Action: "run", Package: "example.com/cart", Test: "TestCart", Time: at(0)},
+		{Action: "run", Package: "example.com/cart", Test: "TestTotal", Time: at(0)},
+		{Action: "run", Package: "example.com/cart", Test: "TestCart/Add", Time: at(100)},
+		{Action: "run", Package: "example.com/cart", Test: "TestCart/Add/one", Time: at(100)},
+		{Action: "run", Package: "example.com/cart", Test: "TestCart/Add/two", Time: at(100)},
+		{Action: "pass", Package: "example.com/cart", Test: "TestCart/Add/one", Time: at(1100), Elapsed: 1.0},
+		{Action: "pass", Package: "example.com/cart", Test: "TestCart/Add/two", Time: at(2100), Elapsed: 2.0},
+		{Action: "pass", Package: "example.com/cart", Test: "TestCart/Add", Time: at(2100), Elapsed: 0.01},
+		{Action: "pass", Package: "example.com/cart", Test: "TestCart", Time: at(2200), Elapsed: 0.05},
+		{Action: "pass", Package: "example.com/cart", Test: "TestTotal", Time: at(1500), Elapsed: 1.5},
+		{Action: "pass", Package: "example.com/cart", Time: at(2300), Elapsed: 2.3},
+	}
+	for _, event := range events {
+		if err := g.processEvent(event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+	_ = ipcWriter.Close()
+
+	durations := make(map[string]float64)
+	for _, event := range capture.GetEventsByType("testGroupResult") {
+		payload := event["payload"].(map[string]interface{})
+		durations[payload["groupName"].(string)], _ = payload["duration"].(float64)
+	}
+
+	// Group durations are reported in milliseconds
+	expected := map[string]float64{
+		"Add":          2000, // First subtest run to last subtest result
+		"TestCart":     2200, // Its own run to its own result, covering the subtests
+		"cart_test.go": 2200, // TestCart and TestTotal overlap, so not 2200+1500
+	}
+	for group, want := range expected {
+		if got := durations[group]; got < want-1 || got > want+1 {
+			t.Errorf("Expected %s duration %.0fms, got %.0fms", group, want, got)
+		}
+	}
+}
+
 // Test package-level test counting
 func TestGoTestDefinition_PackageLevelTestCount(t *testing.T) {
 	g := NewGoTestDefinition(createTestLogger(t))
@@ -1091,12 +1153,21 @@ func TestIPCWriter(t *testing.T) {
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
-	if len(lines) != len(events) {
-		t.Errorf("Expected %d lines, got %d", len(events), len(lines))
+	if len(lines) != len(events)+1 {
+		t.Fatalf("Expected a schema header and %d lines, got %d lines", len(events), len(lines))
+	}
+
+	// The header declares the schema version of the events that follow
+	header, err := ipc.ParseEvent([]byte(lines[0]))
+	if err != nil {
+		t.Fatalf("Failed to parse the header: %v", err)
+	}
+	if schema, ok := header.(ipc.SchemaEvent); !ok || schema.Payload.Version != ipc.SchemaVersion {
+		t.Errorf("Expected a schema header of version %d, got %+v", ipc.SchemaVersion, header)
 	}
 
 	// Parse and verify each line
-	for i, line := range lines {
+	for i, line := range lines[1:] {
 		var parsed map[string]interface{}
 		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
 			t.Fatalf("Failed to parse line %d: %v", i, err)
@@ -1108,6 +1179,88 @@ func TestIPCWriter(t *testing.T) {
 	}
 }
 
+// Test that concurrent writers never interleave partial lines, both when sharing a
+// writer and when each worker appends through its own writer (like xdist workers)
+func TestIPCWriter_ConcurrentWriters(t *testing.T) {
+	tmpDir := t.TempDir()
+	ipcPath := filepath.Join(tmpDir, "test.jsonl")
+
+	shared, err := NewIPCWriter(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to create IPC writer: %v", err)
+	}
+	t.Cleanup(func() { _ = shared.Close() })
+
+	const workers = 8
+	const eventsPerWorker = 200
+	// Large payloads make torn writes more likely if appends weren't atomic
+	padding := strings.Repeat("x", 2048)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			writer := shared
+			if worker%2 == 1 {
+				own, err := NewIPCWriter(ipcPath)
+				if err != nil {
+					t.Errorf("Failed to create IPC writer: %v", err)
+					return
+				}
+				defer func() { _ = own.Close() }()
+				writer = own
+			}
+
+			for i := 0; i < eventsPerWorker; i++ {
+				event := map[string]interface{}{
+					"eventType": "testCase",
+					"payload": map[string]interface{}{
+						"testName": fmt.Sprintf("gw%d-test%d", worker, i),
+						"status":   "PASS",
+						"stdout":   padding,
+					},
+				}
+				if err := writer.WriteEvent(event); err != nil {
+					t.Errorf("Failed to write event: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	// Every writer starts with a schema header: the shared one and one per odd worker
+	headers := 1 + workers/2
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != workers*eventsPerWorker+headers {
+		t.Fatalf("Expected %d lines, got %d", workers*eventsPerWorker+headers, len(lines))
+	}
+
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
+			t.Fatalf("Line %d is not valid JSON (interleaved write?): %v", i, err)
+		}
+		if parsed["eventType"] == string(ipc.EventTypeSchema) {
+			headers--
+			continue
+		}
+		payload := parsed["payload"].(map[string]interface{})
+		seen[payload["testName"].(string)] = true
+	}
+	if len(seen) != workers*eventsPerWorker || headers != 0 {
+		t.Errorf("Expected %d distinct events and a header per writer, got %d events and %d missing headers", workers*eventsPerWorker, len(seen), headers)
+	}
+}
+
 // Test that go list functionality has been removed for performance
 func TestGoTestDefinition_BuildTestToFileMapRemoved(t *testing.T) {
 	// This test verifies that go list has been removed to improve startup performance
@@ -1156,3 +1309,44 @@ func BenchmarkGoTestDefinition_ProcessEvent(b *testing.B) {
 		_ = g.processEvent(event)
 	}
 }
+
+func TestGoTestDefinition_UnfinishedTests(t *testing.T) {
+	g, capture, done := newPanicTestDefinition(t)
+
+	// The command is killed (e.g. --timeout) while a test and its subtest are running
+	processGoEvents(t, g, []*GoTestEvent{
+		{Action: "run", Package: "example.com/shop", Test: "TestOK"},
+		{Action: "pass", Package: "example.com/shop", Test: "TestOK"},
+		{Action: "run", Package: "example.com/shop", Test: "TestSlow"},
+		{Action: "run", Package: "example.com/shop", Test: "TestSlow/waits"},
+	})
+	g.finalizePendingGroups()
+	done()
+
+	statuses := make(map[string]string)
+	for _, event := range capture.GetEventsByType("testCase") {
+		payload := event["payload"].(map[string]interface{})
+		statuses[payload["testName"].(string)] = payload["status"].(string)
+		if payload["testName"] == "waits" {
+			testError, _ := payload["error"].(map[string]interface{})
+			if testError == nil || !strings.Contains(testError["message"].(string), "did not finish") {
+				t.Errorf("Expected the unfinished test's error to say so, got %v", payload["error"])
+			}
+		}
+	}
+	expected := map[string]string{"TestOK": "PASS", "TestSlow": "FAIL", "waits": "FAIL"}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %q (all: %v)", name, status, statuses[name], statuses)
+		}
+	}
+
+	groups := make(map[string]string)
+	for _, event := range capture.GetEventsByType("testGroupResult") {
+		payload := event["payload"].(map[string]interface{})
+		groups[payload["groupName"].(string)] = payload["status"].(string)
+	}
+	if groups["TestSlow"] != "FAIL" || groups["example.com/shop"] != "FAIL" {
+		t.Errorf("Expected the unfinished test's groups to fail, got %v", groups)
+	}
+}
@@ -63,12 +63,17 @@ func (g *GoTestWrapper) InterpretExitCode(code int) string {
 	return "failure"
 }
 
+// ReproCommand returns a command that reruns only the given failed tests
+func (g *GoTestWrapper) ReproCommand(failures []FailedTest) string {
+	return g.GoTestDefinition.ReproCommand(failures)
+}
+
 // IsNative returns true if this is a native runner (no adapter needed)
 func (g *GoTestWrapper) IsNative() bool {
 	return true
 }
 
-// GetNativeDefinition returns the underlying native definition
-func (g *GoTestWrapper) GetNativeDefinition() NativeDefinition {
+// GetNativeDefinition returns the underlying Go test definition, satisfying runner.NativeRunner
+func (g *GoTestWrapper) GetNativeDefinition() interface{} {
 	return g.GoTestDefinition
 }
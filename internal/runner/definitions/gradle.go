@@ -0,0 +1,80 @@
+package definitions
+
+import (
+	"strings"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// gradleReportGlobs match Gradle test result directories (one per test task) for single and multi-project builds
+var gradleReportGlobs = []string{
+	"build/test-results/*",
+	"*/build/test-results/*",
+	"*/*/build/test-results/*",
+}
+
+// gradleTestTasks are the tasks that run tests, optionally qualified with a project path (":app:test")
+var gradleTestTasks = map[string]bool{
+	"test":  true,
+	"check": true,
+	"build": true,
+}
+
+// GradleDefinition implements support for Gradle builds that run tests.
+// Results are read from the JUnit XML reports Gradle writes under build/test-results.
+type GradleDefinition struct {
+	*JUnitReportProcessor
+	logger *logger.FileLogger
+}
+
+// NewGradleDefinition creates a new Gradle test runner definition
+func NewGradleDefinition(logger *logger.FileLogger) *GradleDefinition {
+	return &GradleDefinition{
+		JUnitReportProcessor: NewJUnitReportProcessor(logger, gradleReportGlobs),
+		logger:               logger,
+	}
+}
+
+// Name returns the name of this test runner
+func (g *GradleDefinition) Name() string {
+	return "gradle"
+}
+
+// Detect checks if the command is a Gradle build that runs tests (e.g. "gradle test", "./gradlew :app:test")
+func (g *GradleDefinition) Detect(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+
+	switch commandBaseName(args[0]) {
+	case "gradle", "gradlew", "gradle.bat", "gradlew.bat":
+	default:
+		return false
+	}
+
+	for _, arg := range args[1:] {
+		task := arg
+		if idx := strings.LastIndex(arg, ":"); idx != -1 {
+			task = arg[idx+1:]
+		}
+		if gradleTestTasks[task] {
+			return true
+		}
+	}
+	return false
+}
+
+// ModifyCommand returns the command unchanged since Gradle writes XML reports by default
+func (g *GradleDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	return append([]string{}, cmd...)
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (g *GradleDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as Gradle results come from report files
+func (g *GradleDefinition) RequiresAdapter() bool {
+	return false
+}
@@ -0,0 +1,36 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestGradleDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewGradleDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"gradle test", []string{"gradle", "test"}, true},
+		{"gradle wrapper", []string{"./gradlew", "test"}, true},
+		{"project task", []string{"./gradlew", ":app:test", "--tests", "*CalculatorTest"}, true},
+		{"check", []string{"gradle", "clean", "check"}, true},
+		{"windows wrapper", []string{"gradlew.bat", "test"}, true},
+		{"assemble only", []string{"./gradlew", "assemble"}, false},
+		{"no task", []string{"./gradlew"}, false},
+		{"maven", []string{"mvn", "test"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package definitions
+
+// GradleWrapper wraps GradleDefinition to implement the Definition interface from runner package
+type GradleWrapper struct {
+	*GradleDefinition
+}
+
+// NewGradleWrapper creates a new wrapper for Gradle builds
+func NewGradleWrapper(impl *GradleDefinition) *GradleWrapper {
+	return &GradleWrapper{GradleDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *GradleWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *GradleWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.GradleDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; Gradle needs no adapter
+func (w *GradleWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as Gradle doesn't use an adapter
+func (w *GradleWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *GradleWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *GradleWrapper) ReproCommand(failures []FailedTest) string {
+	return w.GradleDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as Gradle results are processed without an adapter
+func (w *GradleWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying Gradle definition
+func (w *GradleWrapper) GetNativeDefinition() interface{} {
+	return w.GradleDefinition
+}
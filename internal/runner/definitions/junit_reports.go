@@ -0,0 +1,442 @@
+package definitions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// junitReportPollInterval is how often report directories are scanned for new XML files
+const junitReportPollInterval = 250 * time.Millisecond
+
+// JUnitReportProcessor converts JUnit XML report files written by a test runner
+// (Maven Surefire, Gradle) into IPC events. The runner's console output is not parsed;
+// report directories are polled while the runner is executing instead.
+//
+// Test classes are mapped onto the group hierarchy as package -> class -> method.
+type JUnitReportProcessor struct {
+	logger      *logger.FileLogger
+	ipcWriter   *IPCWriter
+	reportGlobs []string // Glob patterns matching report directories
+
+	startTime  time.Time
+	processed  map[string]bool // Report files already converted (each file is written once)
+	discovered map[string]bool // Group key -> discovered and started
+	packages   map[string]*junitPackageInfo
+}
+
+// junitPackageInfo accumulates results for a package group until the run ends
+type junitPackageInfo struct {
+	name     string
+	passed   int
+	failed   int
+	skipped  int
+	duration float64 // Seconds
+}
+
+// junitXMLTestSuite is a <testsuite> element in a JUnit XML report
+type junitXMLTestSuite struct {
+	Name      string             `xml:"name,attr"`
+	Time      string             `xml:"time,attr"`
+	TestCases []junitXMLTestCase `xml:"testcase"`
+	SystemOut string             `xml:"system-out"`
+	SystemErr string             `xml:"system-err"`
+}
+
+// junitXMLTestCase is a <testcase> element in a JUnit XML report
+type junitXMLTestCase struct {
+	Name      string           `xml:"name,attr"`
+	ClassName string           `xml:"classname,attr"`
+	Time      string           `xml:"time,attr"`
+	Failure   *junitXMLProblem `xml:"failure"`
+	Error     *junitXMLProblem `xml:"error"`
+	Skipped   *junitXMLProblem `xml:"skipped"`
+	SystemOut string           `xml:"system-out"`
+	SystemErr string           `xml:"system-err"`
+}
+
+// junitXMLProblem is a <failure>, <error> or <skipped> element
+type junitXMLProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// NewJUnitReportProcessor creates a processor that watches directories matching reportGlobs
+func NewJUnitReportProcessor(logger *logger.FileLogger, reportGlobs []string) *JUnitReportProcessor {
+	return &JUnitReportProcessor{
+		logger:      logger,
+		reportGlobs: reportGlobs,
+		processed:   make(map[string]bool),
+		discovered:  make(map[string]bool),
+		packages:    make(map[string]*junitPackageInfo),
+	}
+}
+
+// ProcessOutput drains the runner's console output and converts JUnit XML reports into IPC events.
+// It returns once the output reaches EOF (the runner has exited) and a final scan is done.
+func (p *JUnitReportProcessor) ProcessOutput(output io.Reader, ipcPath string) error {
+	var err error
+	p.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC writer: %w", err)
+	}
+	defer func() {
+		if err := p.ipcWriter.Close(); err != nil {
+			p.logger.Debug("Failed to close IPC writer: %v", err)
+		}
+	}()
+
+	// Reports left over from earlier builds are older than this run.
+	// Truncate to whole seconds since some filesystems store coarse modification times.
+	p.startTime = time.Now().Truncate(time.Second)
+
+	outputDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, output)
+		outputDone <- err
+	}()
+
+	ticker := time.NewTicker(junitReportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.scanReports(false)
+		case readErr := <-outputDone:
+			// The runner has exited, so every report is complete
+			p.scanReports(true)
+			p.finalizePackages()
+			if readErr != nil {
+				return fmt.Errorf("error reading output: %w", readErr)
+			}
+			return nil
+		}
+	}
+}
+
+// scanReports processes new or modified report files. Files that fail to parse are
+// retried on the next scan since the runner may still be writing them, unless final is set.
+func (p *JUnitReportProcessor) scanReports(final bool) {
+	for _, path := range p.findReportFiles() {
+		if p.processed[path] {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(p.startTime) {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			p.logger.Debug("Failed to read JUnit report %s: %v", path, err)
+			continue
+		}
+		suites, err := parseJUnitXMLReport(data)
+		if err != nil {
+			if final {
+				p.logger.Error("Failed to parse JUnit report %s: %v", path, err)
+				p.processed[path] = true
+			} else {
+				p.logger.Debug("JUnit report %s not ready yet: %v", path, err)
+			}
+			continue
+		}
+
+		p.logger.Debug("Processing JUnit report: %s (%d suites)", path, len(suites))
+		p.processed[path] = true
+		for _, suite := range suites {
+			p.processSuite(suite)
+		}
+	}
+}
+
+// findReportFiles returns the XML report files in all matching report directories, sorted by path
+func (p *JUnitReportProcessor) findReportFiles() []string {
+	var files []string
+	for _, pattern := range p.reportGlobs {
+		matches, err := filepath.Glob(filepath.Join(pattern, "*.xml"))
+		if err != nil {
+			p.logger.Debug("Invalid report glob %s: %v", pattern, err)
+			continue
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// processSuite sends events for every test case in a suite, then a result for each class group
+func (p *JUnitReportProcessor) processSuite(suite junitXMLTestSuite) {
+	type classResult struct {
+		hierarchy []string
+		passed    int
+		failed    int
+		skipped   int
+	}
+	var classOrder []string
+	classes := make(map[string]*classResult)
+
+	for _, tc := range suite.TestCases {
+		className := tc.ClassName
+		if className == "" {
+			className = suite.Name
+		}
+		hierarchy := javaClassHierarchy(className)
+		p.ensureGroups(hierarchy)
+
+		key := strings.Join(hierarchy, "\x00")
+		class, exists := classes[key]
+		if !exists {
+			class = &classResult{hierarchy: hierarchy}
+			classes[key] = class
+			classOrder = append(classOrder, key)
+		}
+		pkg := p.packageInfo(hierarchy[0])
+
+		status := "PASS"
+		problem := tc.Failure
+		if problem == nil {
+			problem = tc.Error
+		}
+		switch {
+		case problem != nil:
+			status = "FAIL"
+			class.failed++
+			pkg.failed++
+		case tc.Skipped != nil:
+			status = "SKIP"
+			class.skipped++
+			pkg.skipped++
+		default:
+			class.passed++
+			pkg.passed++
+		}
+
+		p.sendTestCase(tc.Name, hierarchy, status, parseJUnitSeconds(tc.Time), problem, tc.SystemOut, tc.SystemErr)
+	}
+
+	suiteDuration := parseJUnitSeconds(suite.Time)
+	countedRoots := make(map[string]bool)
+	for _, key := range classOrder {
+		class := classes[key]
+		groupName := class.hierarchy[len(class.hierarchy)-1]
+		parentNames := class.hierarchy[:len(class.hierarchy)-1]
+
+		if root := class.hierarchy[0]; !countedRoots[root] {
+			countedRoots[root] = true
+			p.packageInfo(root).duration += suiteDuration
+		}
+
+		if suite.SystemOut != "" {
+			p.sendOutputChunk("groupStdout", groupName, parentNames, suite.SystemOut)
+		}
+		if suite.SystemErr != "" {
+			p.sendOutputChunk("groupStderr", groupName, parentNames, suite.SystemErr)
+		}
+
+		// Packages without a name use the class as the root group, which is finalized at the end
+		if len(parentNames) == 0 {
+			continue
+		}
+
+		status := "PASS"
+		if class.failed > 0 {
+			status = "FAIL"
+		} else if class.skipped > 0 && class.passed == 0 {
+			status = "SKIP"
+		}
+		totals := map[string]interface{}{
+			"total":   class.passed + class.failed + class.skipped,
+			"passed":  class.passed,
+			"failed":  class.failed,
+			"skipped": class.skipped,
+		}
+		p.sendGroupResult(groupName, parentNames, status, suiteDuration, totals)
+	}
+}
+
+// finalizePackages sends a result for every package group once all reports are processed
+func (p *JUnitReportProcessor) finalizePackages() {
+	names := make([]string, 0, len(p.packages))
+	for name := range p.packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pkg := p.packages[name]
+		status := "PASS"
+		if pkg.failed > 0 {
+			status = "FAIL"
+		} else if pkg.skipped > 0 && pkg.passed == 0 {
+			status = "SKIP"
+		}
+		totals := map[string]interface{}{
+			"total":   pkg.passed + pkg.failed + pkg.skipped,
+			"passed":  pkg.passed,
+			"failed":  pkg.failed,
+			"skipped": pkg.skipped,
+		}
+		p.sendGroupResult(name, []string{}, status, pkg.duration, totals)
+	}
+}
+
+// packageInfo returns the accumulated results for a root group, creating them if needed
+func (p *JUnitReportProcessor) packageInfo(name string) *junitPackageInfo {
+	pkg, exists := p.packages[name]
+	if !exists {
+		pkg = &junitPackageInfo{name: name}
+		p.packages[name] = pkg
+	}
+	return pkg
+}
+
+// ensureGroups sends discovery and start events for each level of the hierarchy once
+func (p *JUnitReportProcessor) ensureGroups(hierarchy []string) {
+	for i := range hierarchy {
+		key := strings.Join(hierarchy[:i+1], "\x00")
+		if p.discovered[key] {
+			continue
+		}
+		p.discovered[key] = true
+		p.sendGroupEvent("testGroupDiscovered", hierarchy[i], hierarchy[:i])
+		p.sendGroupEvent("testGroupStart", hierarchy[i], hierarchy[:i])
+	}
+}
+
+// javaClassHierarchy splits a fully qualified class name into [package, class].
+// Classes in the default package have no package group.
+func javaClassHierarchy(className string) []string {
+	idx := strings.LastIndex(className, ".")
+	if idx <= 0 || idx == len(className)-1 {
+		return []string{className}
+	}
+	return []string{className[:idx], className[idx+1:]}
+}
+
+// parseJUnitXMLReport parses a report whose root is either <testsuites> or a single <testsuite>
+func parseJUnitXMLReport(data []byte) ([]junitXMLTestSuite, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	switch root.XMLName.Local {
+	case "testsuites":
+		var suites struct {
+			Suites []junitXMLTestSuite `xml:"testsuite"`
+		}
+		if err := xml.Unmarshal(data, &suites); err != nil {
+			return nil, err
+		}
+		return suites.Suites, nil
+	case "testsuite":
+		var suite junitXMLTestSuite
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			return nil, err
+		}
+		return []junitXMLTestSuite{suite}, nil
+	default:
+		return nil, fmt.Errorf("unexpected root element <%s>", root.XMLName.Local)
+	}
+}
+
+// parseJUnitSeconds parses a JUnit time attribute, which some locales write with thousands separators
+func parseJUnitSeconds(value string) float64 {
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
+
+// IPC event sending methods
+
+func (p *JUnitReportProcessor) sendGroupEvent(eventType, groupName string, parentNames []string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Error("Failed to send %s: %v", eventType, err)
+	}
+}
+
+func (p *JUnitReportProcessor) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
+	event := map[string]interface{}{
+		"eventType": "testGroupResult",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"status":      status,
+			"duration":    duration * 1000, // Convert seconds to milliseconds
+			"totals":      totals,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Error("Failed to send testGroupResult: %v", err)
+	}
+}
+
+func (p *JUnitReportProcessor) sendTestCase(testName string, parentNames []string, status string, duration float64, problem *junitXMLProblem, stdout, stderr string) {
+	payload := map[string]interface{}{
+		"testName":    testName,
+		"parentNames": parentNames,
+		"status":      status,
+		"duration":    int64(duration * 1000), // Convert to milliseconds
+	}
+	if problem != nil {
+		message := problem.Message
+		if message == "" {
+			message = problem.Type
+		}
+		payload["error"] = map[string]interface{}{
+			"message":   message,
+			"stack":     strings.TrimSpace(problem.Body),
+			"errorType": problem.Type,
+		}
+	}
+	if stdout != "" {
+		payload["stdout"] = stdout
+	}
+	if stderr != "" {
+		payload["stderr"] = stderr
+	}
+
+	event := map[string]interface{}{
+		"eventType": "testCase",
+		"payload":   payload,
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Debug("Failed to write test case event: %v", err)
+	}
+}
+
+func (p *JUnitReportProcessor) sendOutputChunk(eventType, groupName string, parentNames []string, chunk string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"chunk":       chunk,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Debug("Failed to send %s: %v", eventType, err)
+	}
+}
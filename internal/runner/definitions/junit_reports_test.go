@@ -0,0 +1,171 @@
+package definitions
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+const surefireReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="com.example.CalculatorTest" time="1,234.5" tests="3" failures="1" errors="0" skipped="1">
+  <testcase name="addsNumbers" classname="com.example.CalculatorTest" time="0.012"/>
+  <testcase name="dividesByZero" classname="com.example.CalculatorTest" time="0.003">
+    <failure message="expected: &lt;1&gt; but was: &lt;2&gt;" type="org.opentest4j.AssertionFailedError">org.opentest4j.AssertionFailedError: expected: &lt;1&gt; but was: &lt;2&gt;
+	at com.example.CalculatorTest.dividesByZero(CalculatorTest.java:21)</failure>
+    <system-out>dividing</system-out>
+  </testcase>
+  <testcase name="subtractsNumbers" classname="com.example.CalculatorTest" time="0">
+    <skipped message="not implemented"/>
+  </testcase>
+</testsuite>
+`
+
+func TestParseJUnitXMLReport(t *testing.T) {
+	suites, err := parseJUnitXMLReport([]byte(surefireReport))
+	if err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(suites) != 1 || len(suites[0].TestCases) != 3 {
+		t.Fatalf("Expected 1 suite with 3 test cases, got %+v", suites)
+	}
+	if suites[0].TestCases[1].Failure == nil {
+		t.Errorf("Expected failure on dividesByZero")
+	}
+	if got := parseJUnitSeconds(suites[0].Time); got != 1234.5 {
+		t.Errorf("Expected suite time 1234.5, got %v", got)
+	}
+
+	wrapped := `<testsuites><testsuite name="a"><testcase name="x"/></testsuite><testsuite name="b"/></testsuites>`
+	suites, err = parseJUnitXMLReport([]byte(wrapped))
+	if err != nil {
+		t.Fatalf("Failed to parse testsuites report: %v", err)
+	}
+	if len(suites) != 2 {
+		t.Errorf("Expected 2 suites, got %d", len(suites))
+	}
+
+	if _, err := parseJUnitXMLReport([]byte(`<testsuite name="partial"><testcase`)); err == nil {
+		t.Errorf("Expected error for a partially written report")
+	}
+}
+
+func TestJavaClassHierarchy(t *testing.T) {
+	tests := []struct {
+		className string
+		expected  []string
+	}{
+		{"com.example.CalculatorTest", []string{"com.example", "CalculatorTest"}},
+		{"CalculatorTest", []string{"CalculatorTest"}},
+		{"com.example.Outer$Inner", []string{"com.example", "Outer$Inner"}},
+	}
+
+	for _, tt := range tests {
+		got := javaClassHierarchy(tt.className)
+		if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("javaClassHierarchy(%q) = %v, expected %v", tt.className, got, tt.expected)
+		}
+	}
+}
+
+func TestJUnitReportProcessor_ProcessOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	oldCwd, _ := os.Getwd()
+	_ = os.Chdir(tempDir)
+	defer func() { _ = os.Chdir(oldCwd) }()
+
+	reportDir := filepath.Join("target", "surefire-reports")
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		t.Fatalf("Failed to create report dir: %v", err)
+	}
+
+	// A report left over from an earlier build must be ignored
+	stalePath := filepath.Join(reportDir, "TEST-com.example.StaleTest.xml")
+	stale := `<testsuite name="com.example.StaleTest"><testcase name="old" classname="com.example.StaleTest"/></testsuite>`
+	if err := os.WriteFile(stalePath, []byte(stale), 0644); err != nil {
+		t.Fatalf("Failed to write stale report: %v", err)
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(stalePath, hourAgo, hourAgo)
+
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	processor := NewJUnitReportProcessor(lg, mavenReportGlobs)
+
+	ipcPath := filepath.Join(tempDir, "ipc.jsonl")
+	output, outputWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- processor.ProcessOutput(output, ipcPath)
+	}()
+
+	// The write blocks until the processor is reading, so the report is newer than its start time
+	_, _ = outputWriter.Write([]byte("[INFO] Running com.example.CalculatorTest\n"))
+	if err := os.WriteFile(filepath.Join(reportDir, "TEST-com.example.CalculatorTest.xml"), []byte(surefireReport), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	_ = outputWriter.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessOutput returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	testStatuses := make(map[string]string)
+	groupResults := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string `json:"eventType"`
+			Payload   struct {
+				TestName    string   `json:"testName"`
+				GroupName   string   `json:"groupName"`
+				ParentNames []string `json:"parentNames"`
+				Status      string   `json:"status"`
+				Error       *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			if strings.Join(event.Payload.ParentNames, "/") != "com.example/CalculatorTest" {
+				t.Errorf("Unexpected parent names for %s: %v", event.Payload.TestName, event.Payload.ParentNames)
+			}
+			testStatuses[event.Payload.TestName] = event.Payload.Status
+			if event.Payload.TestName == "dividesByZero" && (event.Payload.Error == nil || event.Payload.Error.Message != "expected: <1> but was: <2>") {
+				t.Errorf("Expected failure message on dividesByZero, got %+v", event.Payload.Error)
+			}
+		case "testGroupResult":
+			groupResults[event.Payload.GroupName] = event.Payload.Status
+		}
+	}
+
+	expectedStatuses := map[string]string{
+		"addsNumbers":      "PASS",
+		"dividesByZero":    "FAIL",
+		"subtractsNumbers": "SKIP",
+	}
+	for name, status := range expectedStatuses {
+		if testStatuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %q", name, status, testStatuses[name])
+		}
+	}
+	if _, exists := testStatuses["old"]; exists {
+		t.Errorf("Stale report should not be processed")
+	}
+	if groupResults["CalculatorTest"] != "FAIL" || groupResults["com.example"] != "FAIL" {
+		t.Errorf("Expected class and package groups to fail, got %v", groupResults)
+	}
+}
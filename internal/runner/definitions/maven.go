@@ -0,0 +1,90 @@
+package definitions
+
+import (
+	"strings"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// mavenReportGlobs match Surefire and Failsafe report directories for single and multi-module builds
+var mavenReportGlobs = []string{
+	"target/surefire-reports",
+	"target/failsafe-reports",
+	"*/target/surefire-reports",
+	"*/target/failsafe-reports",
+	"*/*/target/surefire-reports",
+	"*/*/target/failsafe-reports",
+}
+
+// mavenTestGoals are the lifecycle phases and goals that run tests
+var mavenTestGoals = map[string]bool{
+	"test":                      true,
+	"verify":                    true,
+	"package":                   true,
+	"install":                   true,
+	"surefire:test":             true,
+	"failsafe:integration-test": true,
+}
+
+// MavenDefinition implements support for Maven builds that run tests through Surefire.
+// Results are read from the JUnit XML reports Surefire writes under target/surefire-reports.
+type MavenDefinition struct {
+	*JUnitReportProcessor
+	logger *logger.FileLogger
+}
+
+// NewMavenDefinition creates a new Maven test runner definition
+func NewMavenDefinition(logger *logger.FileLogger) *MavenDefinition {
+	return &MavenDefinition{
+		JUnitReportProcessor: NewJUnitReportProcessor(logger, mavenReportGlobs),
+		logger:               logger,
+	}
+}
+
+// Name returns the name of this test runner
+func (m *MavenDefinition) Name() string {
+	return "maven"
+}
+
+// Detect checks if the command is a Maven build that runs tests (e.g. "mvn test", "./mvnw clean verify")
+func (m *MavenDefinition) Detect(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+
+	switch commandBaseName(args[0]) {
+	case "mvn", "mvnw", "mvn.cmd", "mvnw.cmd":
+	default:
+		return false
+	}
+
+	for _, arg := range args[1:] {
+		if mavenTestGoals[arg] {
+			return true
+		}
+	}
+	return false
+}
+
+// ModifyCommand returns the command unchanged since Surefire writes XML reports by default
+func (m *MavenDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	return append([]string{}, cmd...)
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (m *MavenDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as Maven results come from report files
+func (m *MavenDefinition) RequiresAdapter() bool {
+	return false
+}
+
+// commandBaseName returns the executable name without its directory, for both / and \ separators
+func commandBaseName(cmd string) string {
+	if idx := strings.LastIndexAny(cmd, `/\`); idx != -1 {
+		return cmd[idx+1:]
+	}
+	return cmd
+}
@@ -0,0 +1,36 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+func TestMavenDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewMavenDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"mvn test", []string{"mvn", "test"}, true},
+		{"mvn clean verify", []string{"mvn", "clean", "verify"}, true},
+		{"maven wrapper", []string{"./mvnw", "-q", "test"}, true},
+		{"windows wrapper", []string{`.\mvnw.cmd`, "test"}, true},
+		{"single test goal", []string{"mvn", "surefire:test", "-Dtest=CalculatorTest"}, true},
+		{"compile only", []string{"mvn", "compile"}, false},
+		{"no goal", []string{"mvn"}, false},
+		{"gradle", []string{"gradle", "test"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package definitions
+
+// MavenWrapper wraps MavenDefinition to implement the Definition interface from runner package
+type MavenWrapper struct {
+	*MavenDefinition
+}
+
+// NewMavenWrapper creates a new wrapper for Maven builds
+func NewMavenWrapper(impl *MavenDefinition) *MavenWrapper {
+	return &MavenWrapper{MavenDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *MavenWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *MavenWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.MavenDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; Maven needs no adapter
+func (w *MavenWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as Maven doesn't use an adapter
+func (w *MavenWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *MavenWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *MavenWrapper) ReproCommand(failures []FailedTest) string {
+	return w.MavenDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as Maven results are processed without an adapter
+func (w *MavenWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying Maven definition
+func (w *MavenWrapper) GetNativeDefinition() interface{} {
+	return w.MavenDefinition
+}
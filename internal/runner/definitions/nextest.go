@@ -37,8 +37,35 @@ type NextestPackageGroupInfo struct {
 // NextestTestInfo tracks individual test information
 type NextestTestInfo struct {
 	Name     string
-	Status   string
+	FullName string // Name as nextest reports it, including crate and modules
+	Status   string // Status of the last attempt
 	Duration float64
+	Retries  int // Attempts before the last one, with nextest --retries
+}
+
+// recordResult records a test's result in the package group and returns how many earlier
+// attempts it replaced. With --retries, nextest reports a result for every attempt of a
+// flaky or failing test; only the last attempt counts.
+func (g *NextestPackageGroupInfo) recordResult(info NextestTestInfo) int {
+	for i := range g.Tests {
+		if g.Tests[i].FullName == info.FullName {
+			info.Retries = g.Tests[i].Retries + 1
+			g.Tests[i] = info
+			return info.Retries
+		}
+	}
+	g.Tests = append(g.Tests, info)
+	return 0
+}
+
+// hasResult returns true if a result was already recorded for the test
+func (g *NextestPackageGroupInfo) hasResult(fullName string) bool {
+	for _, test := range g.Tests {
+		if test.FullName == fullName {
+			return true
+		}
+	}
+	return false
 }
 
 // NextestTestState tracks the state of a running test
@@ -305,7 +332,10 @@ func (n *NextestDefinition) processTestEvent(event *NextestEvent, testCount *int
 			Package:   packageName,
 			StartTime: time.Now(),
 		}
-		(*testCount)++
+		// A retried test starts again but is only counted once
+		if group, ok := n.packageGroups[packageName]; !ok || !group.hasResult(event.Name) {
+			(*testCount)++
+		}
 
 	case "ok", "failed", "ignored":
 		// Ensure groups are created even if we didn't see a "started" event
@@ -357,13 +387,12 @@ func (n *NextestDefinition) processTestEvent(event *NextestEvent, testCount *int
 			status = "PASS"
 		}
 
-		// Send test case event
-		n.sendTestCase(testName, testParents, status, event.ExecTime, event.Stdout, event.Stderr)
-
-		// Track test in package group
+		// Track test in package group; a repeated result is a retry that replaces the earlier one
+		retry := 0
 		if group, ok := n.packageGroups[packageName]; ok {
-			group.Tests = append(group.Tests, NextestTestInfo{
+			retry = group.recordResult(NextestTestInfo{
 				Name:     testName,
+				FullName: event.Name,
 				Status:   status,
 				Duration: event.ExecTime,
 			})
@@ -371,6 +400,17 @@ func (n *NextestDefinition) processTestEvent(event *NextestEvent, testCount *int
 			// Don't update group status here - let finalizePendingGroups determine final status
 		}
 
+		// Send test case event
+		n.sendTestCase(testName, testParents, status, event.ExecTime, event.Stdout, event.Stderr, retry)
+
+		// Route captured output to the crate group so its report shows it, headed like libtest does
+		if event.Stdout != "" {
+			n.sendOutputChunk("groupStdout", packageName, parentNames, nextestOutputChunk(event.Name, "stdout", event.Stdout))
+		}
+		if event.Stderr != "" {
+			n.sendOutputChunk("groupStderr", packageName, parentNames, nextestOutputChunk(event.Name, "stderr", event.Stderr))
+		}
+
 		// Clean up test state
 		delete(n.testStates, event.Name)
 	}
@@ -464,13 +504,16 @@ func (n *NextestDefinition) sendGroupStart(groupName string, parentNames []strin
 	n.sendIPCEvent(event)
 }
 
-func (n *NextestDefinition) sendTestCase(testName string, parentNames []string, status string, duration float64, stdout, stderr string) {
+func (n *NextestDefinition) sendTestCase(testName string, parentNames []string, status string, duration float64, stdout, stderr string, retry int) {
 	payload := map[string]interface{}{
 		"testName":    testName,
 		"parentNames": parentNames,
 		"status":      status,
 		"duration":    duration,
 	}
+	if retry > 0 {
+		payload["metadata"] = map[string]interface{}{"retry": retry}
+	}
 
 	// Only include stdout/stderr if non-empty
 	if stdout != "" {
@@ -480,10 +523,14 @@ func (n *NextestDefinition) sendTestCase(testName string, parentNames []string,
 		payload["stderr"] = stderr
 	}
 
-	// Include error message for failed tests
-	if status == "FAIL" && stderr != "" {
-		payload["error"] = map[string]interface{}{
-			"message": stderr,
+	// Include error message for failed tests. With libtest JSON, nextest reports all captured
+	// output (including the panic message) in stdout.
+	if status == "FAIL" {
+		if message := nextestFailureMessage(stdout, stderr); message != "" {
+			payload["error"] = map[string]interface{}{
+				"message":     message,
+				"failureKind": rustFailureKind(message),
+			}
 		}
 	}
 
@@ -494,6 +541,52 @@ func (n *NextestDefinition) sendTestCase(testName string, parentNames []string,
 	n.sendIPCEvent(event)
 }
 
+func (n *NextestDefinition) sendOutputChunk(eventType, groupName string, parentNames []string, chunk string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"chunk":       chunk,
+		},
+	}
+	n.sendIPCEvent(event)
+}
+
+// nextestFailureMessage picks the error message for a failed test: stderr when present,
+// otherwise the captured stdout from the panic line onwards.
+func nextestFailureMessage(stdout, stderr string) string {
+	if strings.TrimSpace(stderr) != "" {
+		return strings.TrimSpace(stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "panicked at") {
+			lines = lines[i:]
+			break
+		}
+	}
+
+	// Drop libtest's backtrace hint, it adds nothing to the report
+	message := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "note: run with `RUST_BACKTRACE=") {
+			continue
+		}
+		message = append(message, line)
+	}
+	return strings.TrimSpace(strings.Join(message, "\n"))
+}
+
+// nextestOutputChunk formats a test's captured output the way libtest prints it on failure
+func nextestOutputChunk(testName, stream, output string) string {
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	return fmt.Sprintf("---- %s %s ----\n%s", testName, stream, output)
+}
+
 func (n *NextestDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, passed, failed, skipped int) {
 	event := map[string]interface{}{
 		"eventType": "testGroupResult",
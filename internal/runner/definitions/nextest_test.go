@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -401,3 +402,131 @@ func TestNextestDefinition_SuiteCompletionEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestNextestDefinition_FailureOutput(t *testing.T) {
+	logger, _ := logger.NewFileLogger()
+	defer func() { _ = logger.Close() }()
+	def := NewNextestDefinition(logger)
+
+	stdout := "computing...\nthread 'tests::test_fail' panicked at src/lib.rs:10:9:\nassertion `left == right` failed\n  left: 1\n right: 2\nnote: run with `RUST_BACKTRACE=1` environment variable to display a backtrace\n"
+	line, _ := json.Marshal(map[string]interface{}{
+		"type": "test", "event": "failed", "name": "my_crate::tests::test_fail", "exec_time": 0.002, "stdout": stdout,
+	})
+	jsonEvents := `{"type":"suite","event":"started","test_count":1}
+{"type":"test","event":"started","name":"my_crate::tests::test_fail"}
+` + string(line) + `
+{"type":"suite","event":"failed","passed":0,"failed":1,"ignored":0}
+`
+
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	if err := def.ProcessOutput(strings.NewReader(jsonEvents), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput failed: %v", err)
+	}
+
+	ipcData, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	var message, chunk string
+	for _, line := range strings.Split(strings.TrimSpace(string(ipcData)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Failed to parse IPC event: %v", err)
+		}
+		switch event.EventType {
+		case "testCase":
+			if errInfo, ok := event.Payload["error"].(map[string]interface{}); ok {
+				message, _ = errInfo["message"].(string)
+			}
+			if event.Payload["stdout"] != stdout {
+				t.Errorf("Expected captured stdout on the test case, got %v", event.Payload["stdout"])
+			}
+		case "groupStdout":
+			if event.Payload["groupName"] != "my_crate" {
+				t.Errorf("Expected output routed to the crate group, got %v", event.Payload["groupName"])
+			}
+			chunk, _ = event.Payload["chunk"].(string)
+		}
+	}
+
+	expectedMessage := "thread 'tests::test_fail' panicked at src/lib.rs:10:9:\nassertion `left == right` failed\n  left: 1\n right: 2"
+	if message != expectedMessage {
+		t.Errorf("Expected error message %q, got %q", expectedMessage, message)
+	}
+	if chunk != "---- my_crate::tests::test_fail stdout ----\n"+stdout {
+		t.Errorf("Unexpected group stdout chunk %q", chunk)
+	}
+}
+
+func TestNextestDefinition_RetriesCountOnce(t *testing.T) {
+	logger, _ := logger.NewFileLogger()
+	defer func() { _ = logger.Close() }()
+	def := NewNextestDefinition(logger)
+
+	// With --retries 2, a flaky test fails twice before passing
+	jsonEvents := `{"type":"suite","event":"started","test_count":2}
+{"type":"test","event":"started","name":"my_crate::tests::test_flaky"}
+{"type":"test","event":"failed","name":"my_crate::tests::test_flaky","stdout":"first try"}
+{"type":"test","event":"started","name":"my_crate::tests::test_flaky"}
+{"type":"test","event":"failed","name":"my_crate::tests::test_flaky","stdout":"second try"}
+{"type":"test","event":"started","name":"my_crate::tests::test_flaky"}
+{"type":"test","event":"ok","name":"my_crate::tests::test_flaky","exec_time":0.001}
+{"type":"test","event":"started","name":"my_crate::tests::test_add"}
+{"type":"test","event":"ok","name":"my_crate::tests::test_add","exec_time":0.001}
+{"type":"suite","event":"ok","passed":2,"failed":0,"ignored":0}
+`
+
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	if err := def.ProcessOutput(strings.NewReader(jsonEvents), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput failed: %v", err)
+	}
+
+	ipcData, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	var retries []interface{}
+	var groupResult, collectionFinish map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(ipcData)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Failed to parse IPC event: %v", err)
+		}
+		switch event.EventType {
+		case "testCase":
+			if event.Payload["testName"] == "test_flaky" {
+				metadata, _ := event.Payload["metadata"].(map[string]interface{})
+				retries = append(retries, metadata["retry"])
+			}
+		case "testGroupResult":
+			groupResult = event.Payload
+		case "collectionFinish":
+			collectionFinish = event.Payload
+		}
+	}
+
+	if len(retries) != 3 || retries[0] != nil || retries[1] != float64(1) || retries[2] != float64(2) {
+		t.Errorf("Expected attempts numbered nil, 1, 2, got %v", retries)
+	}
+	if groupResult == nil {
+		t.Fatal("Expected a testGroupResult event")
+	}
+	if groupResult["status"] != "PASS" {
+		t.Errorf("Expected the crate to pass on the last attempt, got %v", groupResult["status"])
+	}
+	totals, _ := groupResult["totals"].(map[string]interface{})
+	if totals["passed"] != float64(2) || totals["failed"] != float64(0) {
+		t.Errorf("Expected 2 passed and 0 failed, got %v", totals)
+	}
+	if collectionFinish["collected"] != float64(2) {
+		t.Errorf("Expected 2 tests collected, got %v", collectionFinish["collected"])
+	}
+}
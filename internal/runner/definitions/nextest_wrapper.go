@@ -44,6 +44,11 @@ func (n *NextestWrapper) InterpretExitCode(code int) string {
 	return "failure"
 }
 
+// ReproCommand returns a command that reruns only the given failed tests
+func (n *NextestWrapper) ReproCommand(failures []FailedTest) string {
+	return n.NextestDefinition.ReproCommand(failures)
+}
+
 // IsNative returns true as nextest processes output directly
 func (n *NextestWrapper) IsNative() bool {
 	return true
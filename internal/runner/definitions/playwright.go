@@ -0,0 +1,560 @@
+package definitions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// playwrightANSIRegex matches the color escapes Playwright puts in error messages
+var playwrightANSIRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// playwrightLaunchers are commands that may precede "playwright" in a command line
+var playwrightLaunchers = map[string]bool{
+	"npx":  true,
+	"bunx": true,
+	"pnpm": true,
+	"yarn": true,
+	"bun":  true,
+	"exec": true,
+	"x":    true,
+}
+
+// PlaywrightDefinition implements support for Playwright Test through its built-in JSON reporter.
+// The reporter writes to a file named by environment variables, and the report is converted
+// to IPC events once Playwright exits, since it is only written at the end of the run.
+//
+// Projects become root groups when they have a name, followed by the test file and its
+// describe blocks. Retried tests send one test case per attempt, so the last attempt decides
+// the final status.
+type PlaywrightDefinition struct {
+	logger    *logger.FileLogger
+	mu        sync.Mutex
+	ipcWriter *IPCWriter
+
+	outputPath     string          // Where the JSON reporter writes its report
+	tempOutputPath bool            // Whether outputPath is in a temporary directory we should remove
+	projects       map[string]bool // Project names seen in the report, used for rerun commands
+}
+
+// playwrightReport is the document written by "playwright test --reporter=json"
+type playwrightReport struct {
+	Config struct {
+		RootDir string `json:"rootDir"`
+	} `json:"config"`
+	Suites []playwrightSuite `json:"suites"`
+	Errors []playwrightError `json:"errors"`
+}
+
+// playwrightSuite is a test file (top level) or a describe block
+type playwrightSuite struct {
+	Title  string            `json:"title"`
+	File   string            `json:"file"`
+	Specs  []playwrightSpec  `json:"specs"`
+	Suites []playwrightSuite `json:"suites"`
+}
+
+// playwrightSpec is a test declaration, which runs once per project
+type playwrightSpec struct {
+	Title string           `json:"title"`
+	File  string           `json:"file"`
+	Line  int              `json:"line"`
+	Tests []playwrightTest `json:"tests"`
+}
+
+// playwrightTest is a spec run in one project, with one result per attempt
+type playwrightTest struct {
+	ProjectName    string             `json:"projectName"`
+	ExpectedStatus string             `json:"expectedStatus"`
+	Status         string             `json:"status"` // expected, unexpected, flaky or skipped
+	Results        []playwrightResult `json:"results"`
+}
+
+// playwrightResult is a single attempt of a test
+type playwrightResult struct {
+	Status   string             `json:"status"` // passed, failed, timedOut, skipped or interrupted
+	Duration float64            `json:"duration"`
+	Retry    int                `json:"retry"`
+	Errors   []playwrightError  `json:"errors"`
+	Stdout   []playwrightOutput `json:"stdout"`
+	Stderr   []playwrightOutput `json:"stderr"`
+}
+
+// playwrightError is an error thrown by a test or while loading test files
+type playwrightError struct {
+	Message  string `json:"message"`
+	Stack    string `json:"stack"`
+	Location *struct {
+		File string `json:"file"`
+		Line int    `json:"line"`
+	} `json:"location"`
+}
+
+// playwrightOutput is a chunk of console output captured during an attempt
+type playwrightOutput struct {
+	Text string `json:"text"`
+}
+
+// playwrightGroupNode accumulates results for a group of the hierarchy
+type playwrightGroupNode struct {
+	path     []string // Full hierarchy including this group
+	passed   int
+	failed   int
+	skipped  int
+	duration float64
+}
+
+// NewPlaywrightDefinition creates a new Playwright runner definition
+func NewPlaywrightDefinition(logger *logger.FileLogger) *PlaywrightDefinition {
+	return &PlaywrightDefinition{
+		logger:   logger,
+		projects: make(map[string]bool),
+	}
+}
+
+// Name returns the name of this test runner
+func (p *PlaywrightDefinition) Name() string {
+	return "playwright"
+}
+
+// Detect checks if the command runs "playwright test", directly or through npx, bunx,
+// pnpm exec or yarn
+func (p *PlaywrightDefinition) Detect(args []string) bool {
+	for i, arg := range args {
+		name := strings.TrimSuffix(commandBaseName(arg), ".cmd")
+		if name == "playwright" {
+			return i+1 < len(args) && args[i+1] == "test"
+		}
+		if !playwrightLaunchers[name] && !strings.HasPrefix(arg, "-") {
+			return false
+		}
+	}
+	return false
+}
+
+// ModifyCommand adds the JSON reporter. A reporter chosen on the command line is kept
+// alongside it, otherwise the list reporter is used for the console output.
+func (p *PlaywrightDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	p.ensureOutputPath(ipcPath)
+
+	result := make([]string, 0, len(cmd)+1)
+	hasReporter := false
+	for i := 0; i < len(cmd); i++ {
+		arg := cmd[i]
+		switch {
+		case arg == "--reporter" && i+1 < len(cmd):
+			result = append(result, arg, withJSONReporter(cmd[i+1]))
+			hasReporter = true
+			i++
+		case strings.HasPrefix(arg, "--reporter="):
+			result = append(result, "--reporter="+withJSONReporter(strings.TrimPrefix(arg, "--reporter=")))
+			hasReporter = true
+		default:
+			result = append(result, arg)
+		}
+	}
+	if !hasReporter {
+		result = append(result, "--reporter=list,json")
+	}
+	return result
+}
+
+// withJSONReporter adds the json reporter to a comma-separated reporter list
+func withJSONReporter(reporters string) string {
+	for _, reporter := range strings.Split(reporters, ",") {
+		if reporter == "json" {
+			return reporters
+		}
+	}
+	return reporters + ",json"
+}
+
+// ReporterEnv returns the environment variables pointing the JSON reporter at its output
+// file. PLAYWRIGHT_JSON_OUTPUT_FILE is read by newer versions and PLAYWRIGHT_JSON_OUTPUT_NAME
+// by older ones.
+func (p *PlaywrightDefinition) ReporterEnv(ipcPath string) []string {
+	outputPath := p.ensureOutputPath(ipcPath)
+	if abs, err := filepath.Abs(outputPath); err == nil {
+		outputPath = abs
+	}
+	return []string{
+		"PLAYWRIGHT_JSON_OUTPUT_FILE=" + outputPath,
+		"PLAYWRIGHT_JSON_OUTPUT_NAME=" + outputPath,
+	}
+}
+
+// ensureOutputPath picks the JSON report path once, so repeated command builds agree.
+// It lives in the run directory when the IPC path is known, otherwise in a temporary directory.
+func (p *PlaywrightDefinition) ensureOutputPath(ipcPath string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.outputPath != "" {
+		return p.outputPath
+	}
+
+	if ipcPath != "" {
+		p.outputPath = filepath.Join(filepath.Dir(ipcPath), "playwright.json")
+		return p.outputPath
+	}
+
+	dir, err := os.MkdirTemp("", "3pio-playwright-")
+	if err != nil {
+		p.logger.Error("Failed to create Playwright output directory: %v", err)
+		dir = os.TempDir()
+	}
+	p.outputPath = filepath.Join(dir, "playwright.json")
+	p.tempOutputPath = err == nil
+	return p.outputPath
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (p *PlaywrightDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as Playwright results come from its JSON reporter
+func (p *PlaywrightDefinition) RequiresAdapter() bool {
+	return false
+}
+
+// ProcessOutput drains the Playwright console output, then converts the JSON report into IPC events
+func (p *PlaywrightDefinition) ProcessOutput(output io.Reader, ipcPath string) error {
+	var err error
+	p.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC writer: %w", err)
+	}
+	defer func() {
+		if err := p.ipcWriter.Close(); err != nil {
+			p.logger.Debug("Failed to close IPC writer: %v", err)
+		}
+	}()
+
+	outputPath := p.ensureOutputPath(ipcPath)
+	if p.tempOutputPath {
+		defer func() {
+			if err := os.RemoveAll(filepath.Dir(outputPath)); err != nil {
+				p.logger.Debug("Failed to remove Playwright output directory: %v", err)
+			}
+		}()
+	}
+
+	// The JSON reporter only writes its report once Playwright finishes
+	if _, err := io.Copy(io.Discard, output); err != nil {
+		return fmt.Errorf("error reading output: %w", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		// Playwright failed before reporting (e.g. a config error); output.log has the details
+		p.logger.Debug("No Playwright JSON report at %s: %v", outputPath, err)
+		return nil
+	}
+
+	var report playwrightReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("failed to parse Playwright JSON report: %w", err)
+	}
+
+	p.processReport(report)
+	return nil
+}
+
+// processReport sends events for every attempt of every test, then group results deepest
+// first, then errors raised outside tests
+func (p *PlaywrightDefinition) processReport(report playwrightReport) {
+	type testEntry struct {
+		spec   playwrightSpec
+		test   playwrightTest
+		parent []string
+	}
+
+	var entries []testEntry
+	nodes := make(map[string]*playwrightGroupNode)
+	var ordered []*playwrightGroupNode
+
+	var walk func(suite playwrightSuite, path []string)
+	walk = func(suite playwrightSuite, path []string) {
+		for _, spec := range suite.Specs {
+			for _, test := range spec.Tests {
+				parent := path
+				if test.ProjectName != "" {
+					p.mu.Lock()
+					p.projects[test.ProjectName] = true
+					p.mu.Unlock()
+					parent = append([]string{test.ProjectName}, path...)
+				}
+				entries = append(entries, testEntry{spec: spec, test: test, parent: parent})
+
+				status := playwrightFinalStatus(test)
+				for depth := 1; depth <= len(parent); depth++ {
+					key := strings.Join(parent[:depth], "\x00")
+					node, exists := nodes[key]
+					if !exists {
+						node = &playwrightGroupNode{path: parent[:depth]}
+						nodes[key] = node
+						ordered = append(ordered, node)
+					}
+					switch status {
+					case "PASS", "XFAIL":
+						node.passed++
+					case "FAIL":
+						node.failed++
+					case "SKIP":
+						node.skipped++
+					}
+					for _, result := range test.Results {
+						node.duration += result.Duration
+					}
+				}
+			}
+		}
+		for _, child := range suite.Suites {
+			walk(child, append(append([]string{}, path...), child.Title))
+		}
+	}
+	for _, suite := range report.Suites {
+		walk(suite, []string{p.filePath(report.Config.RootDir, suite.File, suite.Title)})
+	}
+
+	// Discover and start groups in hierarchy order
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(ordered[i].path) < len(ordered[j].path)
+	})
+	for _, node := range ordered {
+		name, parents := node.path[len(node.path)-1], node.path[:len(node.path)-1]
+		p.sendGroupEvent("testGroupDiscovered", name, parents)
+		p.sendGroupEvent("testGroupStart", name, parents)
+	}
+
+	for _, entry := range entries {
+		p.sendAttempts(entry.spec, entry.test, entry.parent, report.Config.RootDir)
+	}
+
+	for i := len(ordered) - 1; i >= 0; i-- {
+		node := ordered[i]
+		status := "PASS"
+		if node.failed > 0 {
+			status = "FAIL"
+		} else if node.skipped > 0 && node.passed == 0 {
+			status = "SKIP"
+		}
+		totals := map[string]interface{}{
+			"total":   node.passed + node.failed + node.skipped,
+			"passed":  node.passed,
+			"failed":  node.failed,
+			"skipped": node.skipped,
+		}
+		p.sendGroupResult(node.path[len(node.path)-1], node.path[:len(node.path)-1], status, node.duration, totals)
+	}
+
+	// Errors outside tests, such as a test file that fails to load
+	for _, reportErr := range report.Errors {
+		groupName := "playwright"
+		if reportErr.Location != nil && reportErr.Location.File != "" {
+			groupName = p.filePath(report.Config.RootDir, reportErr.Location.File, "")
+		}
+		p.sendGroupError(groupName, "SETUP_FAILURE", playwrightErrorInfo([]playwrightError{reportErr}, "", ""))
+	}
+}
+
+// filePath resolves a test file reported relative to the config's rootDir
+func (p *PlaywrightDefinition) filePath(rootDir, file, fallback string) string {
+	if file == "" {
+		return fallback
+	}
+	if rootDir == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(rootDir, file)
+}
+
+// playwrightFinalStatus maps a test's overall outcome to a 3pio test status.
+// Flaky tests failed at least once but passed on a retry.
+func playwrightFinalStatus(test playwrightTest) string {
+	switch test.Status {
+	case "expected", "flaky":
+		if len(test.Results) > 0 {
+			last := test.Results[len(test.Results)-1].Status
+			if last == "skipped" {
+				return "SKIP"
+			}
+			// test.fail() tests are expected to fail
+			if test.ExpectedStatus == "failed" && last != "passed" {
+				return "XFAIL"
+			}
+		}
+		return "PASS"
+	case "unexpected":
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
+
+// playwrightAttemptStatus maps the status of a single attempt to a 3pio test status
+func playwrightAttemptStatus(status string) string {
+	switch status {
+	case "passed":
+		return "PASS"
+	case "skipped":
+		return "SKIP"
+	default:
+		// failed, timedOut and interrupted
+		return "FAIL"
+	}
+}
+
+// playwrightErrorInfo builds the error payload from an attempt's errors, joining their messages
+func playwrightErrorInfo(errs []playwrightError, errorType, fallbackLocation string) map[string]interface{} {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, strings.TrimSpace(playwrightANSIRegex.ReplaceAllString(e.Message, "")))
+	}
+	info := map[string]interface{}{
+		"message": strings.Join(messages, "\n\n"),
+		"stack":   playwrightANSIRegex.ReplaceAllString(errs[0].Stack, ""),
+	}
+	if errorType != "" {
+		info["errorType"] = errorType
+	}
+	if errs[0].Location != nil && errs[0].Location.File != "" {
+		info["location"] = errs[0].Location.File + ":" + strconv.Itoa(errs[0].Location.Line)
+	} else if fallbackLocation != "" {
+		info["location"] = fallbackLocation
+	}
+	return info
+}
+
+// sendAttempts sends a test case event for each attempt of a test. Earlier attempts keep
+// their own status; the last one carries the test's final status.
+func (p *PlaywrightDefinition) sendAttempts(spec playwrightSpec, test playwrightTest, parentNames []string, rootDir string) {
+	location := ""
+	if spec.File != "" {
+		location = p.filePath(rootDir, spec.File, "") + ":" + strconv.Itoa(spec.Line)
+	}
+
+	if len(test.Results) == 0 {
+		// Tests that never ran, e.g. after --max-failures was reached
+		p.sendTestCase(spec.Title, parentNames, playwrightFinalStatus(test), playwrightResult{}, location)
+		return
+	}
+
+	for i, result := range test.Results {
+		status := playwrightAttemptStatus(result.Status)
+		if i == len(test.Results)-1 {
+			status = playwrightFinalStatus(test)
+		} else {
+			p.logger.Debug("Playwright test %q attempt %d: %s", spec.Title, result.Retry+1, result.Status)
+		}
+		p.sendTestCase(spec.Title, parentNames, status, result, location)
+	}
+}
+
+// IPC event sending methods
+
+func (p *PlaywrightDefinition) sendGroupEvent(eventType, groupName string, parentNames []string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Error("Failed to send %s: %v", eventType, err)
+	}
+}
+
+func (p *PlaywrightDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
+	event := map[string]interface{}{
+		"eventType": "testGroupResult",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"status":      status,
+			"duration":    duration, // Playwright reports milliseconds
+			"totals":      totals,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Error("Failed to send testGroupResult: %v", err)
+	}
+}
+
+func (p *PlaywrightDefinition) sendGroupError(groupName, errorType string, errorInfo map[string]interface{}) {
+	if errorInfo == nil {
+		return
+	}
+	errorInfo["phase"] = "collection"
+	event := map[string]interface{}{
+		"eventType": "testGroupError",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": []string{},
+			"errorType":   errorType,
+			"error":       errorInfo,
+		},
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Error("Failed to send testGroupError: %v", err)
+	}
+}
+
+func (p *PlaywrightDefinition) sendTestCase(testName string, parentNames []string, status string, result playwrightResult, location string) {
+	payload := map[string]interface{}{
+		"testName":    testName,
+		"parentNames": parentNames,
+		"status":      status,
+		"duration":    result.Duration,
+	}
+	if result.Retry > 0 {
+		payload["metadata"] = map[string]interface{}{"retry": result.Retry}
+	}
+	if status == "FAIL" {
+		errorType := ""
+		if result.Status == "timedOut" {
+			errorType = "TIMEOUT"
+		}
+		if errorInfo := playwrightErrorInfo(result.Errors, errorType, location); errorInfo != nil {
+			payload["error"] = errorInfo
+		}
+	}
+	if stdout := joinPlaywrightOutput(result.Stdout); stdout != "" {
+		payload["stdout"] = stdout
+	}
+	if stderr := joinPlaywrightOutput(result.Stderr); stderr != "" {
+		payload["stderr"] = stderr
+	}
+
+	event := map[string]interface{}{
+		"eventType": "testCase",
+		"payload":   payload,
+	}
+	if err := p.ipcWriter.WriteEvent(event); err != nil {
+		p.logger.Debug("Failed to write test case event: %v", err)
+	}
+}
+
+// joinPlaywrightOutput concatenates the text chunks of captured output
+func joinPlaywrightOutput(chunks []playwrightOutput) string {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		sb.WriteString(chunk.Text)
+	}
+	return sb.String()
+}
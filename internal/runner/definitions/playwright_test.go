@@ -0,0 +1,209 @@
+package definitions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+const samplePlaywrightJSON = `{
+  "config": {"rootDir": "/work/tests"},
+  "suites": [
+    {
+      "title": "login.spec.ts",
+      "file": "login.spec.ts",
+      "specs": [
+        {"title": "shows the form", "file": "login.spec.ts", "line": 3, "tests": [
+          {"projectName": "chromium", "expectedStatus": "passed", "status": "expected", "results": [
+            {"status": "passed", "duration": 120, "retry": 0, "errors": [], "stdout": [{"text": "ready\n"}]}
+          ]},
+          {"projectName": "firefox", "expectedStatus": "passed", "status": "flaky", "results": [
+            {"status": "failed", "duration": 300, "retry": 0, "errors": [{"message": "Error: first try"}]},
+            {"status": "passed", "duration": 150, "retry": 1, "errors": []}
+          ]}
+        ]}
+      ],
+      "suites": [
+        {
+          "title": "with bad password",
+          "file": "login.spec.ts",
+          "specs": [
+            {"title": "shows an error", "file": "login.spec.ts", "line": 12, "tests": [
+              {"projectName": "chromium", "expectedStatus": "passed", "status": "unexpected", "results": [
+                {"status": "failed", "duration": 200, "retry": 0, "errors": [{"message": "Error: \u001b[2mexpect(\u001b[22mlocator).toBeVisible()", "stack": "at login.spec.ts:14:5", "location": {"file": "/work/tests/login.spec.ts", "line": 14, "column": 5}}]},
+                {"status": "timedOut", "duration": 30000, "retry": 1, "errors": [{"message": "Test timeout of 30000ms exceeded."}]}
+              ]},
+              {"projectName": "firefox", "expectedStatus": "skipped", "status": "skipped", "results": [
+                {"status": "skipped", "duration": 0, "retry": 0, "errors": []}
+              ]}
+            ]}
+          ]
+        }
+      ]
+    }
+  ],
+  "errors": [
+    {"message": "SyntaxError: Unexpected token", "location": {"file": "/work/tests/broken.spec.ts", "line": 1, "column": 1}}
+  ]
+}`
+
+func TestPlaywrightDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewPlaywrightDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"direct", []string{"playwright", "test"}, true},
+		{"npx", []string{"npx", "playwright", "test", "--project", "chromium"}, true},
+		{"npx with flags", []string{"npx", "--no-install", "playwright", "test"}, true},
+		{"pnpm exec", []string{"pnpm", "exec", "playwright", "test"}, true},
+		{"yarn", []string{"yarn", "playwright", "test"}, true},
+		{"node_modules bin", []string{"./node_modules/.bin/playwright", "test"}, true},
+		{"install browsers", []string{"npx", "playwright", "install"}, false},
+		{"file named playwright", []string{"node", "playwright", "test"}, false},
+		{"jest", []string{"npx", "jest"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlaywrightDefinition_ModifyCommand(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewPlaywrightDefinition(lg)
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"npx", "playwright", "test"}, []string{"npx", "playwright", "test", "--reporter=list,json"}},
+		{[]string{"npx", "playwright", "test", "--reporter", "dot"}, []string{"npx", "playwright", "test", "--reporter", "dot,json"}},
+		{[]string{"npx", "playwright", "test", "--reporter=line,json"}, []string{"npx", "playwright", "test", "--reporter=line,json"}},
+	}
+	for _, tt := range tests {
+		if got := def.ModifyCommand(tt.args, filepath.Join("run", "ipc.jsonl"), ""); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ModifyCommand(%v) = %v, expected %v", tt.args, got, tt.expected)
+		}
+	}
+
+	expectedPath, _ := filepath.Abs(filepath.Join("run", "playwright.json"))
+	env := def.ReporterEnv(filepath.Join("run", "ipc.jsonl"))
+	if !reflect.DeepEqual(env, []string{"PLAYWRIGHT_JSON_OUTPUT_FILE=" + expectedPath, "PLAYWRIGHT_JSON_OUTPUT_NAME=" + expectedPath}) {
+		t.Errorf("Unexpected reporter environment: %v", env)
+	}
+}
+
+func TestPlaywrightDefinition_ProcessOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewPlaywrightDefinition(lg)
+
+	ipcPath := filepath.Join(tempDir, "ipc.jsonl")
+	if err := os.WriteFile(filepath.Join(tempDir, "playwright.json"), []byte(samplePlaywrightJSON), 0644); err != nil {
+		t.Fatalf("Failed to write Playwright report: %v", err)
+	}
+	def.ensureOutputPath(ipcPath)
+
+	if err := def.ProcessOutput(strings.NewReader("Running 4 tests using 2 workers\n"), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	loginFile := filepath.Join("/work/tests", "login.spec.ts")
+	var attempts []string
+	groupResults := make(map[string]string)
+	var timeout, groupError map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			parents := convertToStringSlice(event.Payload["parentNames"])
+			attempts = append(attempts, parents[0]+" "+event.Payload["testName"].(string)+"="+event.Payload["status"].(string))
+			if event.Payload["testName"] == "shows an error" && parents[0] == "chromium" {
+				timeout, _ = event.Payload["error"].(map[string]interface{})
+				if !reflect.DeepEqual(parents, []string{"chromium", loginFile, "with bad password"}) {
+					t.Errorf("Unexpected parents %v", parents)
+				}
+			}
+		case "testGroupResult":
+			parents := convertToStringSlice(event.Payload["parentNames"])
+			groupResults[strings.Join(append(parents, event.Payload["groupName"].(string)), " > ")] = event.Payload["status"].(string)
+		case "testGroupError":
+			groupError = event.Payload
+		}
+	}
+
+	// Every attempt is sent in order, and the last one carries the final status
+	expectedAttempts := []string{
+		"chromium shows the form=PASS",
+		"firefox shows the form=FAIL",
+		"firefox shows the form=PASS",
+		"chromium shows an error=FAIL",
+		"chromium shows an error=FAIL",
+		"firefox shows an error=SKIP",
+	}
+	if !reflect.DeepEqual(attempts, expectedAttempts) {
+		t.Errorf("Expected attempts %v, got %v", expectedAttempts, attempts)
+	}
+	if timeout == nil || timeout["errorType"] != "TIMEOUT" || timeout["message"] != "Test timeout of 30000ms exceeded." {
+		t.Errorf("Expected the last attempt's timeout error, got %v", timeout)
+	}
+
+	expectedGroups := map[string]string{
+		"chromium":                "FAIL",
+		"chromium > " + loginFile: "FAIL",
+		"chromium > " + loginFile + " > with bad password": "FAIL",
+		"firefox":                "PASS",
+		"firefox > " + loginFile: "PASS",
+		"firefox > " + loginFile + " > with bad password": "SKIP",
+	}
+	if !reflect.DeepEqual(groupResults, expectedGroups) {
+		t.Errorf("Expected group results %v, got %v", expectedGroups, groupResults)
+	}
+
+	if groupError == nil || groupError["groupName"] != "/work/tests/broken.spec.ts" || groupError["errorType"] != "SETUP_FAILURE" {
+		t.Errorf("Expected a setup failure for the broken file, got %v", groupError)
+	}
+}
+
+func TestPlaywrightErrorInfo_StripsColors(t *testing.T) {
+	var report playwrightReport
+	if err := json.Unmarshal([]byte(samplePlaywrightJSON), &report); err != nil {
+		t.Fatalf("Failed to parse sample: %v", err)
+	}
+	result := report.Suites[0].Suites[0].Specs[0].Tests[0].Results[0]
+
+	info := playwrightErrorInfo(result.Errors, "", "")
+	if info["message"] != "Error: expect(locator).toBeVisible()" {
+		t.Errorf("Expected color codes to be stripped, got %q", info["message"])
+	}
+	if info["location"] != "/work/tests/login.spec.ts:14" {
+		t.Errorf("Expected the error location, got %v", info["location"])
+	}
+}
@@ -0,0 +1,54 @@
+package definitions
+
+// PlaywrightWrapper wraps PlaywrightDefinition to implement the Definition interface from runner package
+type PlaywrightWrapper struct {
+	*PlaywrightDefinition
+}
+
+// NewPlaywrightWrapper creates a new wrapper for Playwright
+func NewPlaywrightWrapper(impl *PlaywrightDefinition) *PlaywrightWrapper {
+	return &PlaywrightWrapper{PlaywrightDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *PlaywrightWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *PlaywrightWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.PlaywrightDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; Playwright needs no adapter
+func (w *PlaywrightWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as Playwright doesn't use an adapter
+func (w *PlaywrightWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *PlaywrightWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *PlaywrightWrapper) ReproCommand(failures []FailedTest) string {
+	return w.PlaywrightDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as Playwright results are processed without an adapter
+func (w *PlaywrightWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying Playwright definition
+func (w *PlaywrightWrapper) GetNativeDefinition() interface{} {
+	return w.PlaywrightDefinition
+}
@@ -0,0 +1,284 @@
+package definitions
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FailedTest identifies a failed test case for building a rerun command.
+// ParentNames is the group hierarchy from the root group down, with file paths made
+// relative to the working directory where possible.
+type FailedTest struct {
+	Name        string
+	ParentNames []string
+}
+
+// shellSafeRegex matches arguments that need no quoting in a POSIX shell.
+// "#" only starts a comment at the beginning of a word.
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+,-][A-Za-z0-9_./:=@%+,#-]*$`)
+
+// ShellQuote quotes an argument for a POSIX shell, leaving simple arguments as-is
+func ShellQuote(arg string) string {
+	if shellSafeRegex.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// ShellJoin quotes each argument and joins them into a copy-pasteable command line
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ExactNamePattern builds a regular expression matching exactly one of the names
+func ExactNamePattern(names []string) string {
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = regexp.QuoteMeta(name)
+	}
+	if len(escaped) == 1 {
+		return "^" + escaped[0] + "$"
+	}
+	return "^(" + strings.Join(escaped, "|") + ")$"
+}
+
+// uniqueSorted returns the distinct values in sorted order
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// ReproCommand returns "go test" commands rerunning the failed top-level tests, one per package.
+// Subtests rerun with their top-level test, since subtest names are rewritten by go test.
+func (g *GoTestDefinition) ReproCommand(failures []FailedTest) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	tests := make(map[string][]string)
+	specs := make(map[string][][]string)
+	for _, failure := range failures {
+		if len(failure.ParentNames) == 0 {
+			continue
+		}
+		pkg := failure.ParentNames[0]
+		path := failure.ParentNames[1:]
+		if _, ok := g.ginkgoSuites[pkg]; ok {
+			specs[pkg] = append(specs[pkg], append(append([]string{}, path...), failure.Name))
+			continue
+		}
+		// Skip the file group added by --go-list
+		if len(path) > 0 && strings.HasSuffix(path[0], "_test.go") {
+			path = path[1:]
+		}
+		topLevel := failure.Name
+		if len(path) > 0 {
+			topLevel = path[0]
+		}
+		tests[pkg] = append(tests[pkg], topLevel)
+	}
+
+	packages := make([]string, 0, len(tests)+len(specs))
+	for pkg := range tests {
+		packages = append(packages, pkg)
+	}
+	for pkg := range specs {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	lines := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		if paths, ok := specs[pkg]; ok {
+			lines = append(lines, ginkgoReproCommand(pkg, g.ginkgoSuites[pkg], paths))
+			continue
+		}
+		pattern := ExactNamePattern(uniqueSorted(tests[pkg]))
+		lines = append(lines, ShellJoin([]string{"go", "test", "-run", pattern, pkg}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ReproCommand returns a "cargo test" command rerunning the failed tests by exact path
+func (c *CargoTestDefinition) ReproCommand(failures []FailedTest) string {
+	var paths []string
+	for _, failure := range failures {
+		parents := failure.ParentNames
+		if c.workspaceName != "" && len(parents) > 0 && parents[0] == c.workspaceName {
+			parents = parents[1:]
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		// The first remaining parent is the crate; the rest are modules
+		paths = append(paths, strings.Join(append(append([]string{}, parents[1:]...), failure.Name), "::"))
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+	return ShellJoin(append([]string{"cargo", "test", "--", "--exact"}, uniqueSorted(paths)...))
+}
+
+// ReproCommand returns a "cargo nextest run" command selecting the failed tests with a filterset
+func (n *NextestDefinition) ReproCommand(failures []FailedTest) string {
+	var filters []string
+	for _, failure := range failures {
+		parents := failure.ParentNames
+		if n.workspaceName != "" && len(parents) > 0 && parents[0] == n.workspaceName {
+			parents = parents[1:]
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		path := strings.Join(append(append([]string{}, parents[1:]...), failure.Name), "::")
+		filters = append(filters, "test(="+path+")")
+	}
+	if len(filters) == 0 {
+		return ""
+	}
+	return ShellJoin([]string{"cargo", "nextest", "run", "-E", strings.Join(uniqueSorted(filters), " | ")})
+}
+
+// javaTestSelectors groups failed test methods by fully-qualified class name
+func javaTestSelectors(failures []FailedTest) ([]string, map[string][]string) {
+	methods := make(map[string][]string)
+	for _, failure := range failures {
+		if len(failure.ParentNames) == 0 {
+			continue
+		}
+		class := strings.Join(failure.ParentNames, ".")
+		methods[class] = append(methods[class], failure.Name)
+	}
+
+	classes := make([]string, 0, len(methods))
+	for class := range methods {
+		classes = append(classes, class)
+		methods[class] = uniqueSorted(methods[class])
+	}
+	sort.Strings(classes)
+	return classes, methods
+}
+
+// ReproCommand returns a "mvn test" command selecting the failed methods with -Dtest
+func (m *MavenDefinition) ReproCommand(failures []FailedTest) string {
+	classes, methods := javaTestSelectors(failures)
+	if len(classes) == 0 {
+		return ""
+	}
+
+	selectors := make([]string, len(classes))
+	for i, class := range classes {
+		selectors[i] = class + "#" + strings.Join(methods[class], "+")
+	}
+	// Modules without a matching test would otherwise fail the build
+	return ShellJoin([]string{"mvn", "test", "-Dtest=" + strings.Join(selectors, ","), "-Dsurefire.failIfNoSpecifiedTests=false"})
+}
+
+// ReproCommand returns a Gradle test command with a --tests filter per failed method
+func (g *GradleDefinition) ReproCommand(failures []FailedTest) string {
+	classes, methods := javaTestSelectors(failures)
+	if len(classes) == 0 {
+		return ""
+	}
+
+	args := []string{"gradle", "test"}
+	if _, err := os.Stat("gradlew"); err == nil {
+		args[0] = "./gradlew"
+	}
+	for _, class := range classes {
+		for _, method := range methods[class] {
+			args = append(args, "--tests", class+"."+method)
+		}
+	}
+	return ShellJoin(args)
+}
+
+// ReproCommand returns a "dotnet test" command filtering on the failed tests' qualified names
+func (d *DotnetTestDefinition) ReproCommand(failures []FailedTest) string {
+	var names []string
+	for _, failure := range failures {
+		// The first parent is the test assembly; the rest is the namespace and class
+		if len(failure.ParentNames) < 2 {
+			continue
+		}
+		method := failure.Name
+		if idx := strings.Index(method, "("); idx != -1 {
+			method = method[:idx]
+		}
+		names = append(names, "FullyQualifiedName="+strings.Join(append(append([]string{}, failure.ParentNames[1:]...), method), "."))
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return ShellJoin([]string{"dotnet", "test", "--filter", strings.Join(uniqueSorted(names), "|")})
+}
+
+// ReproCommand returns an rspec command matching each failed example by full description
+func (r *RSpecDefinition) ReproCommand(failures []FailedTest) string {
+	var files, descriptions []string
+	for _, failure := range failures {
+		if len(failure.ParentNames) == 0 {
+			continue
+		}
+		files = append(files, failure.ParentNames[0])
+		descriptions = append(descriptions, strings.Join(append(append([]string{}, failure.ParentNames[1:]...), failure.Name), " "))
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	args := append([]string{"bundle", "exec", "rspec"}, uniqueSorted(files)...)
+	for _, description := range uniqueSorted(descriptions) {
+		args = append(args, "-e", description)
+	}
+	return ShellJoin(args)
+}
+
+// ReproCommand returns a "playwright test" command limited to the failed tests' files, projects
+// and titles. Titles are matched with --grep, which Playwright applies to the full test title.
+func (p *PlaywrightDefinition) ReproCommand(failures []FailedTest) string {
+	var files, projects, titles []string
+	for _, failure := range failures {
+		parents := failure.ParentNames
+		p.mu.Lock()
+		isProject := len(parents) > 0 && p.projects[parents[0]]
+		p.mu.Unlock()
+		if isProject {
+			projects = append(projects, parents[0])
+			parents = parents[1:]
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		files = append(files, parents[0])
+		titles = append(titles, regexp.QuoteMeta(failure.Name))
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	args := append([]string{"npx", "playwright", "test"}, uniqueSorted(files)...)
+	for _, project := range uniqueSorted(projects) {
+		args = append(args, "--project", project)
+	}
+	args = append(args, "--grep", strings.Join(uniqueSorted(titles), "|"))
+	return ShellJoin(args)
+}
+
+// ReproCommand returns "" since TAP producers have no common way to select tests
+func (t *TAPDefinition) ReproCommand(failures []FailedTest) string {
+	return ""
+}
@@ -0,0 +1,110 @@
+package definitions
+
+import (
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{"./pkg/...", "./pkg/..."},
+		{"-Dtest=a.B#c+d", "-Dtest=a.B#c+d"},
+		{"^TestA$", "'^TestA$'"},
+		{"it's", `'it'\''s'`},
+		{"two words", "'two words'"},
+		{"#comment", "'#comment'"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.arg); got != tt.expected {
+			t.Errorf("ShellQuote(%q) = %s, expected %s", tt.arg, got, tt.expected)
+		}
+	}
+}
+
+func TestReproCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      interface{ ReproCommand([]FailedTest) string }
+		failures []FailedTest
+		expected string
+	}{
+		{
+			name: "go test groups top-level tests by package",
+			def:  &GoTestDefinition{},
+			failures: []FailedTest{
+				{Name: "TestB", ParentNames: []string{"example.com/cart"}},
+				{Name: "empty", ParentNames: []string{"example.com/cart", "cart_test.go", "TestA"}},
+				{Name: "nested", ParentNames: []string{"example.com/cart", "TestA", "sub"}},
+				{Name: "TestParse", ParentNames: []string{"example.com/util"}},
+			},
+			expected: "go test -run '^(TestA|TestB)$' example.com/cart\ngo test -run '^TestParse$' example.com/util",
+		},
+		{
+			name: "cargo test uses exact module paths",
+			def:  &CargoTestDefinition{workspaceName: "shop"},
+			failures: []FailedTest{
+				{Name: "indexing", ParentNames: []string{"shop", "grid (Grid storage)", "grid", "tests"}},
+				{Name: "top_level", ParentNames: []string{"shop", "cli"}},
+			},
+			expected: "cargo test -- --exact grid::tests::indexing top_level",
+		},
+		{
+			name: "nextest uses a filterset",
+			def:  &NextestDefinition{},
+			failures: []FailedTest{
+				{Name: "parses", ParentNames: []string{"core", "config"}},
+			},
+			expected: "cargo nextest run -E 'test(=config::parses)'",
+		},
+		{
+			name: "maven selects methods per class",
+			def:  &MavenDefinition{},
+			failures: []FailedTest{
+				{Name: "adds", ParentNames: []string{"com.example", "CalculatorTest"}},
+				{Name: "divides", ParentNames: []string{"com.example", "CalculatorTest"}},
+			},
+			expected: "mvn test -Dtest=com.example.CalculatorTest#adds+divides -Dsurefire.failIfNoSpecifiedTests=false",
+		},
+		{
+			name: "dotnet filters on qualified names",
+			def:  &DotnetTestDefinition{},
+			failures: []FailedTest{
+				{Name: "Adds(1, 2)", ParentNames: []string{"Shop.Tests", "Shop", "CartTests"}},
+			},
+			expected: "dotnet test --filter FullyQualifiedName=Shop.CartTests.Adds",
+		},
+		{
+			name: "rspec matches full descriptions",
+			def:  &RSpecDefinition{},
+			failures: []FailedTest{
+				{Name: "adds items", ParentNames: []string{"spec/cart_spec.rb", "Cart", "#add"}},
+			},
+			expected: "bundle exec rspec spec/cart_spec.rb -e 'Cart #add adds items'",
+		},
+		{
+			name: "playwright selects files, projects and titles",
+			def:  &PlaywrightDefinition{projects: map[string]bool{"chromium": true}},
+			failures: []FailedTest{
+				{Name: "logs in (admin)", ParentNames: []string{"chromium", "tests/login.spec.ts", "login"}},
+				{Name: "shows cart", ParentNames: []string{"tests/cart.spec.ts"}},
+			},
+			expected: "npx playwright test tests/cart.spec.ts tests/login.spec.ts --project chromium --grep 'logs in \\(admin\\)|shows cart'",
+		},
+		{
+			name:     "tap has no selector",
+			def:      &TAPDefinition{},
+			failures: []FailedTest{{Name: "adds", ParentNames: []string{TAPRootGroupName}}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.def.ReproCommand(tt.failures); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,458 @@
+package definitions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// rspecExampleIDRegex extracts the scoped index path from an example id such as "./spec/calc_spec.rb[1:2:1]"
+var rspecExampleIDRegex = regexp.MustCompile(`\[([\d:]+)\]$`)
+
+// RSpecDefinition implements support for RSpec through its built-in JSON formatter.
+// The JSON document is written to a file next to the IPC file and converted to IPC events
+// once RSpec exits, since the formatter only writes it at the end of the run.
+//
+// Spec files become root groups and example groups (describe/context) become subgroups.
+type RSpecDefinition struct {
+	logger    *logger.FileLogger
+	mu        sync.Mutex
+	ipcWriter *IPCWriter
+
+	outputPath     string // Where the JSON formatter writes its report
+	tempOutputPath bool   // Whether outputPath is in a temporary directory we should remove
+}
+
+// rspecReport is the document written by "rspec --format json"
+type rspecReport struct {
+	Examples []rspecExample `json:"examples"`
+}
+
+// rspecExample is a single example in the RSpec JSON report
+type rspecExample struct {
+	ID              string          `json:"id"`
+	Description     string          `json:"description"`
+	FullDescription string          `json:"full_description"`
+	Status          string          `json:"status"`
+	FilePath        string          `json:"file_path"`
+	LineNumber      int             `json:"line_number"`
+	RunTime         float64         `json:"run_time"`
+	PendingMessage  string          `json:"pending_message"`
+	Exception       *rspecException `json:"exception"`
+}
+
+// rspecException describes why an example failed
+type rspecException struct {
+	Class     string   `json:"class"`
+	Message   string   `json:"message"`
+	Backtrace []string `json:"backtrace"`
+}
+
+// rspecGroupNode is an example group identified by its scoped index path within a file
+type rspecGroupNode struct {
+	key      string   // File path plus index path, e.g. "./spec/calc_spec.rb[1:2]"
+	name     string   // Group description
+	path     []string // Full hierarchy including the file and this group
+	depth    int      // Number of example groups from the file to this group
+	consumed int      // Description segments consumed by this group and its ancestors
+	examples []int    // Indexes of examples nested anywhere below this group
+	passed   int
+	failed   int
+	skipped  int
+	duration float64
+}
+
+// NewRSpecDefinition creates a new RSpec runner definition
+func NewRSpecDefinition(logger *logger.FileLogger) *RSpecDefinition {
+	return &RSpecDefinition{
+		logger: logger,
+	}
+}
+
+// Name returns the name of this test runner
+func (r *RSpecDefinition) Name() string {
+	return "rspec"
+}
+
+// Detect checks if the command runs RSpec ("rspec", "bin/rspec" or "bundle exec rspec")
+func (r *RSpecDefinition) Detect(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	if commandBaseName(args[0]) == "rspec" {
+		return true
+	}
+
+	return len(args) >= 3 && commandBaseName(args[0]) == "bundle" && args[1] == "exec" && commandBaseName(args[2]) == "rspec"
+}
+
+// ModifyCommand adds the JSON formatter writing to a file. When the command has no formatter
+// of its own, the progress formatter is kept for the console output.
+func (r *RSpecDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	outputPath := r.ensureOutputPath(ipcPath)
+
+	hasFormatter := false
+	for _, arg := range cmd {
+		if arg == "--format" || arg == "-f" || strings.HasPrefix(arg, "--format=") || (strings.HasPrefix(arg, "-f") && len(arg) > 2) {
+			hasFormatter = true
+			break
+		}
+	}
+
+	result := make([]string, 0, len(cmd)+6)
+	result = append(result, cmd...)
+	if !hasFormatter {
+		result = append(result, "--format", "progress")
+	}
+	result = append(result, "--format", "json", "--out", outputPath)
+	return result
+}
+
+// ensureOutputPath picks the JSON report path once, so repeated command builds agree.
+// It lives in the run directory when the IPC path is known, otherwise in a temporary directory.
+func (r *RSpecDefinition) ensureOutputPath(ipcPath string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.outputPath != "" {
+		return r.outputPath
+	}
+
+	if ipcPath != "" {
+		r.outputPath = filepath.Join(filepath.Dir(ipcPath), "rspec.json")
+		return r.outputPath
+	}
+
+	dir, err := os.MkdirTemp("", "3pio-rspec-")
+	if err != nil {
+		r.logger.Error("Failed to create RSpec output directory: %v", err)
+		dir = os.TempDir()
+	}
+	r.outputPath = filepath.Join(dir, "rspec.json")
+	r.tempOutputPath = err == nil
+	return r.outputPath
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (r *RSpecDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as RSpec results come from its JSON formatter
+func (r *RSpecDefinition) RequiresAdapter() bool {
+	return false
+}
+
+// ProcessOutput drains the RSpec console output, then converts the JSON report into IPC events
+func (r *RSpecDefinition) ProcessOutput(output io.Reader, ipcPath string) error {
+	var err error
+	r.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC writer: %w", err)
+	}
+	defer func() {
+		if err := r.ipcWriter.Close(); err != nil {
+			r.logger.Debug("Failed to close IPC writer: %v", err)
+		}
+	}()
+
+	outputPath := r.ensureOutputPath(ipcPath)
+	if r.tempOutputPath {
+		defer func() {
+			if err := os.RemoveAll(filepath.Dir(outputPath)); err != nil {
+				r.logger.Debug("Failed to remove RSpec output directory: %v", err)
+			}
+		}()
+	}
+
+	// The JSON formatter only writes its report once RSpec finishes
+	if _, err := io.Copy(io.Discard, output); err != nil {
+		return fmt.Errorf("error reading output: %w", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		// RSpec failed before running any examples (e.g. a load error); output.log has the details
+		r.logger.Debug("No RSpec JSON report at %s: %v", outputPath, err)
+		return nil
+	}
+
+	var report rspecReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("failed to parse RSpec JSON report: %w", err)
+	}
+
+	r.processReport(report)
+	return nil
+}
+
+// processReport sends events for every example, then group results deepest first
+func (r *RSpecDefinition) processReport(report rspecReport) {
+	examplePaths, nodes := buildRSpecGroups(report.Examples)
+
+	// Discover and start groups in hierarchy order
+	ordered := make([]*rspecGroupNode, 0, len(nodes))
+	for _, node := range nodes {
+		ordered = append(ordered, node)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if len(ordered[i].path) != len(ordered[j].path) {
+			return len(ordered[i].path) < len(ordered[j].path)
+		}
+		return ordered[i].key < ordered[j].key
+	})
+	for _, node := range ordered {
+		r.sendGroupEvent("testGroupDiscovered", node.name, node.path[:len(node.path)-1])
+		r.sendGroupEvent("testGroupStart", node.name, node.path[:len(node.path)-1])
+	}
+
+	for i, example := range report.Examples {
+		status := rspecStatus(example.Status)
+		r.sendTestCase(example, examplePaths[i], status)
+	}
+
+	for i := len(ordered) - 1; i >= 0; i-- {
+		node := ordered[i]
+		status := "PASS"
+		if node.failed > 0 {
+			status = "FAIL"
+		} else if node.skipped > 0 && node.passed == 0 {
+			status = "SKIP"
+		}
+		totals := map[string]interface{}{
+			"total":   node.passed + node.failed + node.skipped,
+			"passed":  node.passed,
+			"failed":  node.failed,
+			"skipped": node.skipped,
+		}
+		r.sendGroupResult(node.name, node.path[:len(node.path)-1], status, node.duration, totals)
+	}
+}
+
+// buildRSpecGroups reconstructs the example group tree. The JSON formatter does not report
+// group names, so they are recovered from each example's full description using the scoped
+// id ("[1:2:1]") for the nesting depth.
+// It returns each example's parent hierarchy and the group nodes keyed by scoped id.
+func buildRSpecGroups(examples []rspecExample) ([][]string, map[string]*rspecGroupNode) {
+	nodes := make(map[string]*rspecGroupNode)
+	exampleKeys := make([][]string, len(examples))
+	segments := make([][]string, len(examples))
+
+	for i, example := range examples {
+		groupText := strings.TrimSpace(strings.TrimSuffix(example.FullDescription, example.Description))
+		segments[i] = splitRSpecDescription(groupText)
+
+		var indices []string
+		if match := rspecExampleIDRegex.FindStringSubmatch(example.ID); match != nil {
+			indices = strings.Split(match[1], ":")
+			indices = indices[:len(indices)-1] // The last index is the example itself
+		} else if groupText != "" {
+			indices = []string{"1"}
+		}
+
+		// The file itself is the root group
+		keys := []string{example.FilePath}
+		if _, exists := nodes[example.FilePath]; !exists {
+			nodes[example.FilePath] = &rspecGroupNode{key: example.FilePath, name: example.FilePath, path: []string{example.FilePath}}
+		}
+		for depth := 1; depth <= len(indices); depth++ {
+			key := example.FilePath + "[" + strings.Join(indices[:depth], ":") + "]"
+			if _, exists := nodes[key]; !exists {
+				nodes[key] = &rspecGroupNode{key: key, depth: depth}
+			}
+			keys = append(keys, key)
+		}
+		exampleKeys[i] = keys
+
+		status := rspecStatus(example.Status)
+		for _, key := range keys {
+			node := nodes[key]
+			node.examples = append(node.examples, i)
+			node.duration += example.RunTime
+			switch status {
+			case "PASS":
+				node.passed++
+			case "FAIL":
+				node.failed++
+			case "SKIP":
+				node.skipped++
+			}
+		}
+	}
+
+	// Name groups from the shallowest down, so each group knows what its parent consumed
+	examplePaths := make([][]string, len(examples))
+	for i, keys := range exampleKeys {
+		path := []string{examples[i].FilePath}
+		parent := nodes[keys[0]]
+		for _, key := range keys[1:] {
+			node := nodes[key]
+			if node.path == nil {
+				nameRSpecGroup(node, parent, exampleKeys, segments)
+			}
+			path = append(path, node.name)
+			parent = node
+		}
+		examplePaths[i] = path
+	}
+
+	return examplePaths, nodes
+}
+
+// nameRSpecGroup picks the description segments that belong to a group. Examples directly
+// in the group give its full description; otherwise the segments shared by its child groups
+// are used. A group with a single child group and no examples of its own is ambiguous, and
+// takes one segment since outer describe blocks are usually a single class or method name.
+func nameRSpecGroup(node, parent *rspecGroupNode, exampleKeys [][]string, segments [][]string) {
+	first := segments[node.examples[0]]
+	end := -1
+	common := len(first)
+	children := make(map[string]bool)
+	for _, i := range node.examples {
+		seg := segments[i]
+		if len(exampleKeys[i])-1 == node.depth {
+			end = len(seg)
+			break
+		}
+		children[exampleKeys[i][node.depth+1]] = true
+
+		n := 0
+		for n < common && n < len(seg) && seg[n] == first[n] {
+			n++
+		}
+		common = n
+	}
+
+	if end < 0 {
+		if len(children) > 1 {
+			end = common
+		} else {
+			end = parent.consumed + 1
+		}
+	}
+	// Leave at least one segment for every nested group below this one
+	for _, i := range node.examples {
+		nestedGroups := len(exampleKeys[i]) - 1 - node.depth
+		if limit := len(segments[i]) - nestedGroups; limit < end {
+			end = limit
+		}
+	}
+	if end < parent.consumed {
+		end = parent.consumed
+	}
+
+	node.consumed = end
+	node.name = joinRSpecDescription(first[parent.consumed:end])
+	if node.name == "" {
+		node.name = "example group " + node.key[strings.LastIndex(node.key, "[")+1:len(node.key)-1]
+	}
+	node.path = append(append([]string{}, parent.path...), node.name)
+}
+
+// splitRSpecDescription splits a group description into the pieces RSpec joined together.
+// Nested descriptions are joined with a space, except ones starting with "#", "." or "::".
+func splitRSpecDescription(text string) []string {
+	var segments []string
+	for _, word := range strings.Fields(text) {
+		start := 0
+		for i := 1; i < len(word); i++ {
+			if word[i] == '#' || word[i] == '.' || (word[i] == ':' && i+1 < len(word) && word[i+1] == ':' && word[i-1] != ':') {
+				segments = append(segments, word[start:i])
+				start = i
+			}
+		}
+		segments = append(segments, word[start:])
+	}
+	return segments
+}
+
+// joinRSpecDescription reverses splitRSpecDescription
+func joinRSpecDescription(segments []string) string {
+	var sb strings.Builder
+	for i, seg := range segments {
+		if i > 0 && !strings.HasPrefix(seg, "#") && !strings.HasPrefix(seg, ".") && !strings.HasPrefix(seg, "::") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(seg)
+	}
+	return sb.String()
+}
+
+// rspecStatus maps an RSpec example status to a 3pio test status
+func rspecStatus(status string) string {
+	switch status {
+	case "passed":
+		return "PASS"
+	case "failed":
+		return "FAIL"
+	default:
+		// pending (skip/xit/pending)
+		return "SKIP"
+	}
+}
+
+// IPC event sending methods
+
+func (r *RSpecDefinition) sendGroupEvent(eventType, groupName string, parentNames []string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+		},
+	}
+	if err := r.ipcWriter.WriteEvent(event); err != nil {
+		r.logger.Error("Failed to send %s: %v", eventType, err)
+	}
+}
+
+func (r *RSpecDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
+	event := map[string]interface{}{
+		"eventType": "testGroupResult",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"status":      status,
+			"duration":    duration * 1000, // Convert seconds to milliseconds
+			"totals":      totals,
+		},
+	}
+	if err := r.ipcWriter.WriteEvent(event); err != nil {
+		r.logger.Error("Failed to send testGroupResult: %v", err)
+	}
+}
+
+func (r *RSpecDefinition) sendTestCase(example rspecExample, parentNames []string, status string) {
+	payload := map[string]interface{}{
+		"testName":    example.Description,
+		"parentNames": parentNames,
+		"status":      status,
+		"duration":    int64(example.RunTime * 1000), // Convert to milliseconds
+	}
+	if status == "FAIL" && example.Exception != nil {
+		payload["error"] = map[string]interface{}{
+			"message":   strings.TrimSpace(example.Exception.Message),
+			"stack":     strings.Join(example.Exception.Backtrace, "\n"),
+			"errorType": example.Exception.Class,
+			"location":  example.FilePath + ":" + strconv.Itoa(example.LineNumber),
+		}
+	}
+
+	event := map[string]interface{}{
+		"eventType": "testCase",
+		"payload":   payload,
+	}
+	if err := r.ipcWriter.WriteEvent(event); err != nil {
+		r.logger.Debug("Failed to write test case event: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package definitions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+const sampleRSpecJSON = `{
+  "version": "3.13.0",
+  "examples": [
+    {"id": "./spec/calculator_spec.rb[1:1:1]", "description": "adds two numbers", "full_description": "Calculator#add adds two numbers", "status": "passed", "file_path": "./spec/calculator_spec.rb", "line_number": 5, "run_time": 0.001},
+    {"id": "./spec/calculator_spec.rb[1:1:2]", "description": "handles negatives", "full_description": "Calculator#add handles negatives", "status": "failed", "file_path": "./spec/calculator_spec.rb", "line_number": 9, "run_time": 0.002,
+     "exception": {"class": "RSpec::Expectations::ExpectationNotMetError", "message": "\nexpected: -1\n     got: 1\n", "backtrace": ["./spec/calculator_spec.rb:10:in 'block (3 levels) in <top (required)>'"]}},
+    {"id": "./spec/calculator_spec.rb[1:2:1:1]", "description": "is not implemented", "full_description": "Calculator#divide when dividing by zero is not implemented", "status": "pending", "file_path": "./spec/calculator_spec.rb", "line_number": 15, "run_time": 0, "pending_message": "later"},
+    {"id": "./spec/user_spec.rb[1:1]", "description": "has a name", "full_description": "User has a name", "status": "passed", "file_path": "./spec/user_spec.rb", "line_number": 3, "run_time": 0.004}
+  ],
+  "summary": {"example_count": 4, "failure_count": 1, "pending_count": 1}
+}`
+
+func TestRSpecDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewRSpecDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"rspec", []string{"rspec"}, true},
+		{"rspec with file", []string{"rspec", "spec/user_spec.rb"}, true},
+		{"bundle exec", []string{"bundle", "exec", "rspec", "--tag", "focus"}, true},
+		{"binstub", []string{"bin/rspec"}, true},
+		{"relative binstub", []string{"./bin/rspec"}, true},
+		{"bundle exec rake", []string{"bundle", "exec", "rake"}, false},
+		{"ruby", []string{"ruby", "test.rb"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRSpecDefinition_ModifyCommand(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+
+	ipcPath := filepath.Join("run", "ipc.jsonl")
+	outputPath := filepath.Join("run", "rspec.json")
+
+	def := NewRSpecDefinition(lg)
+	got := def.ModifyCommand([]string{"bundle", "exec", "rspec"}, ipcPath, "")
+	expected := []string{"bundle", "exec", "rspec", "--format", "progress", "--format", "json", "--out", outputPath}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// A formatter chosen by the user is kept for the console instead of progress
+	def = NewRSpecDefinition(lg)
+	got = def.ModifyCommand([]string{"rspec", "-fd"}, ipcPath, "")
+	expected = []string{"rspec", "-fd", "--format", "json", "--out", outputPath}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestBuildRSpecGroups(t *testing.T) {
+	var report rspecReport
+	if err := json.Unmarshal([]byte(sampleRSpecJSON), &report); err != nil {
+		t.Fatalf("Failed to parse sample: %v", err)
+	}
+
+	paths, _ := buildRSpecGroups(report.Examples)
+	expected := [][]string{
+		{"./spec/calculator_spec.rb", "Calculator", "#add"},
+		{"./spec/calculator_spec.rb", "Calculator", "#add"},
+		{"./spec/calculator_spec.rb", "Calculator", "#divide", "when dividing by zero"},
+		{"./spec/user_spec.rb", "User"},
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected paths %v, got %v", expected, paths)
+	}
+}
+
+func TestSplitRSpecDescription(t *testing.T) {
+	segments := splitRSpecDescription("Foo::Bar#baz .qux with args")
+	expected := []string{"Foo", "::Bar", "#baz", ".qux", "with", "args"}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected %v, got %v", expected, segments)
+	}
+	if joined := joinRSpecDescription(segments); joined != "Foo::Bar#baz.qux with args" {
+		t.Errorf("Unexpected join: %q", joined)
+	}
+}
+
+func TestRSpecDefinition_ProcessOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewRSpecDefinition(lg)
+
+	ipcPath := filepath.Join(tempDir, "ipc.jsonl")
+	command := def.ModifyCommand([]string{"rspec"}, ipcPath, "")
+	if err := os.WriteFile(command[len(command)-1], []byte(sampleRSpecJSON), 0644); err != nil {
+		t.Fatalf("Failed to write RSpec report: %v", err)
+	}
+
+	if err := def.ProcessOutput(strings.NewReader("..F*\n"), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	testStatuses := make(map[string]string)
+	groupResults := make(map[string]string)
+	var failure map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			EventType string                 `json:"eventType"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		switch event.EventType {
+		case "testCase":
+			name := event.Payload["testName"].(string)
+			testStatuses[name] = event.Payload["status"].(string)
+			if name == "handles negatives" {
+				failure, _ = event.Payload["error"].(map[string]interface{})
+			}
+		case "testGroupResult":
+			groupResults[event.Payload["groupName"].(string)] = event.Payload["status"].(string)
+		}
+	}
+
+	expectedStatuses := map[string]string{
+		"adds two numbers":   "PASS",
+		"handles negatives":  "FAIL",
+		"is not implemented": "SKIP",
+		"has a name":         "PASS",
+	}
+	if !reflect.DeepEqual(testStatuses, expectedStatuses) {
+		t.Errorf("Expected statuses %v, got %v", expectedStatuses, testStatuses)
+	}
+	if failure == nil || !strings.Contains(failure["message"].(string), "expected: -1") ||
+		failure["location"] != "./spec/calculator_spec.rb:9" {
+		t.Errorf("Expected failure details, got %v", failure)
+	}
+
+	expectedGroups := map[string]string{
+		"./spec/calculator_spec.rb": "FAIL",
+		"Calculator":                "FAIL",
+		"#add":                      "FAIL",
+		"#divide":                   "SKIP",
+		"when dividing by zero":     "SKIP",
+		"./spec/user_spec.rb":       "PASS",
+		"User":                      "PASS",
+	}
+	if !reflect.DeepEqual(groupResults, expectedGroups) {
+		t.Errorf("Expected group results %v, got %v", expectedGroups, groupResults)
+	}
+}
@@ -0,0 +1,54 @@
+package definitions
+
+// RSpecWrapper wraps RSpecDefinition to implement the Definition interface from runner package
+type RSpecWrapper struct {
+	*RSpecDefinition
+}
+
+// NewRSpecWrapper creates a new wrapper for RSpec
+func NewRSpecWrapper(impl *RSpecDefinition) *RSpecWrapper {
+	return &RSpecWrapper{RSpecDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *RSpecWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *RSpecWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.RSpecDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; RSpec needs no adapter
+func (w *RSpecWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as RSpec doesn't use an adapter
+func (w *RSpecWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *RSpecWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *RSpecWrapper) ReproCommand(failures []FailedTest) string {
+	return w.RSpecDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as RSpec results are processed without an adapter
+func (w *RSpecWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying RSpec definition
+func (w *RSpecWrapper) GetNativeDefinition() interface{} {
+	return w.RSpecDefinition
+}
@@ -0,0 +1,89 @@
+package definitions
+
+import (
+	"fmt"
+	"slices"
+)
+
+// TestSelection is the tests picked with --test-name-filter and --test-file, which each
+// runner translates into its own selector arguments
+type TestSelection struct {
+	NameFilter string   // Pattern in the runner's own syntax (a regex for most, a -k expression for pytest)
+	Files      []string // Test files to run
+}
+
+// IsEmpty reports whether the selection leaves the command as it is
+func (s TestSelection) IsEmpty() bool {
+	return s.NameFilter == "" && len(s.Files) == 0
+}
+
+// InsertBeforeSeparator returns a copy of command with args inserted before its first "--"
+// separator, or appended when it has none
+func InsertBeforeSeparator(command []string, args ...string) []string {
+	index := slices.Index(command, "--")
+	if index < 0 {
+		index = len(command)
+	}
+	return slices.Concat(command[:index], args, command[index:])
+}
+
+// SelectTests adds -run for the name filter right after "go test", ahead of any -args.
+// go test selects packages rather than files, so test files are rejected.
+func (g *GoTestDefinition) SelectTests(command []string, selection TestSelection) ([]string, error) {
+	if len(selection.Files) > 0 {
+		return nil, fmt.Errorf("go test selects packages, not files: add the package to the command instead of --test-file")
+	}
+	result := slices.Clone(command)
+	if selection.NameFilter == "" {
+		return result, nil
+	}
+	for i := 1; i < len(command); i++ {
+		if command[i-1] == "go" && command[i] == "test" {
+			return slices.Insert(result, i+1, "-run="+selection.NameFilter), nil
+		}
+	}
+	return append(result, "-run="+selection.NameFilter), nil
+}
+
+// SelectTests adds the name filter as cargo's positional test filter, before the "--"
+// that starts the test binary's arguments. Cargo selects test targets rather than files,
+// so test files are rejected.
+func (c *CargoTestDefinition) SelectTests(command []string, selection TestSelection) ([]string, error) {
+	if len(selection.Files) > 0 {
+		return nil, fmt.Errorf("cargo test selects targets, not files: use --test <name> in the command instead of --test-file")
+	}
+	if selection.NameFilter == "" {
+		return slices.Clone(command), nil
+	}
+	return InsertBeforeSeparator(command, selection.NameFilter), nil
+}
+
+// SelectTests adds the name filter as nextest's positional test filter. Test files are
+// rejected as for cargo test.
+func (n *NextestDefinition) SelectTests(command []string, selection TestSelection) ([]string, error) {
+	if len(selection.Files) > 0 {
+		return nil, fmt.Errorf("cargo nextest selects targets, not files: use -E in the command instead of --test-file")
+	}
+	if selection.NameFilter == "" {
+		return slices.Clone(command), nil
+	}
+	return InsertBeforeSeparator(command, selection.NameFilter), nil
+}
+
+// SelectTests adds --grep for the name filter and the test files as positional arguments
+func (p *PlaywrightDefinition) SelectTests(command []string, selection TestSelection) ([]string, error) {
+	var args []string
+	if selection.NameFilter != "" {
+		args = append(args, "--grep="+selection.NameFilter)
+	}
+	return InsertBeforeSeparator(command, append(args, selection.Files...)...), nil
+}
+
+// SelectTests adds --example for the name filter and the test files as positional arguments
+func (r *RSpecDefinition) SelectTests(command []string, selection TestSelection) ([]string, error) {
+	var args []string
+	if selection.NameFilter != "" {
+		args = append(args, "--example="+selection.NameFilter)
+	}
+	return InsertBeforeSeparator(command, append(args, selection.Files...)...), nil
+}
@@ -0,0 +1,54 @@
+package definitions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectTests_Native(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectFn  func([]string, TestSelection) ([]string, error)
+		command   []string
+		selection TestSelection
+		expected  []string
+	}{
+		{"go test", (&GoTestDefinition{}).SelectTests, []string{"go", "test", "./...", "-args", "-v"},
+			TestSelection{NameFilter: "TestCart/adds"}, []string{"go", "test", "-run=TestCart/adds", "./...", "-args", "-v"}},
+		{"cargo test", (&CargoTestDefinition{}).SelectTests, []string{"cargo", "test", "--", "--nocapture"},
+			TestSelection{NameFilter: "cart::adds"}, []string{"cargo", "test", "cart::adds", "--", "--nocapture"}},
+		{"cargo nextest", (&NextestDefinition{}).SelectTests, []string{"cargo", "nextest", "run"},
+			TestSelection{NameFilter: "cart::adds"}, []string{"cargo", "nextest", "run", "cart::adds"}},
+		{"playwright", (&PlaywrightDefinition{}).SelectTests, []string{"npx", "playwright", "test"},
+			TestSelection{NameFilter: "logs in", Files: []string{"tests/login.spec.ts"}},
+			[]string{"npx", "playwright", "test", "--grep=logs in", "tests/login.spec.ts"}},
+		{"rspec", (&RSpecDefinition{}).SelectTests, []string{"bundle", "exec", "rspec"},
+			TestSelection{NameFilter: "adds items", Files: []string{"spec/cart_spec.rb"}},
+			[]string{"bundle", "exec", "rspec", "--example=adds items", "spec/cart_spec.rb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selectFn(tt.command, tt.selection)
+			if err != nil {
+				t.Fatalf("SelectTests failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectTests_NativeRejectsFiles(t *testing.T) {
+	files := TestSelection{Files: []string{"cart_test.go"}}
+	for name, selectFn := range map[string]func([]string, TestSelection) ([]string, error){
+		"go test":       (&GoTestDefinition{}).SelectTests,
+		"cargo test":    (&CargoTestDefinition{}).SelectTests,
+		"cargo nextest": (&NextestDefinition{}).SelectTests,
+	} {
+		if _, err := selectFn([]string{"test"}, files); err == nil {
+			t.Errorf("Expected %s to reject --test-file", name)
+		}
+	}
+}
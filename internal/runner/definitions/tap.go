@@ -0,0 +1,719 @@
+package definitions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// TAPRootGroupName is the synthetic group holding test points that are not inside a subtest
+const TAPRootGroupName = "TAP tests"
+
+var (
+	// tapTestPointRegex matches "ok 1 - description" and "not ok 2 description"
+	tapTestPointRegex = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s+)?(.*)$`)
+	// tapPlanRegex matches plan lines such as "1..4" or "1..0 # SKIP no database"
+	tapPlanRegex = regexp.MustCompile(`^1\.\.(\d+)\s*(?:#\s*(.*))?$`)
+	// tapDirectiveRegex matches a "# SKIP reason" or "# TODO reason" directive at the end of a description
+	tapDirectiveRegex = regexp.MustCompile(`(?i)(?:^|\s)#\s*(skip|todo)\S*\s*(.*)$`)
+)
+
+// TAPDefinition implements support for tools that emit TAP (Test Anything Protocol), such as
+// "node --test", tape and Perl test scripts. TAP is parsed as it streams from the process.
+//
+// TAP has no notion of files, so groups follow the subtest hierarchy: a test point whose
+// subtest contains test points of its own becomes a group, anything else is a test case.
+// Top-level test points that are not subtests go into a synthetic TAPRootGroupName group.
+type TAPDefinition struct {
+	logger    *logger.FileLogger
+	ipcWriter *IPCWriter
+
+	// Parser state
+	stack          []*tapBlock // Open subtest blocks; stack[0] is the top level
+	pendingSubtest string      // Name from a "# Subtest:" line announcing the next block
+	lastNode       *tapNode    // Test point that following diagnostics belong to
+	lastIndent     int         // Indentation of lastNode
+	inYAML         bool        // Inside a "---" / "..." diagnostic block
+	yamlLines      []string    // Lines of the current diagnostic block
+	pending        *tapNode    // Top-level test point waiting for its diagnostics before being sent
+
+	// Synthetic root group state
+	rootStarted bool
+	rootCounts  tapCounts
+	rootPoints  int // Top-level test points seen, compared against the top-level plan
+}
+
+// tapBlock is an indented block of TAP lines belonging to a subtest
+type tapBlock struct {
+	indent   int
+	name     string // From "# Subtest: name", if present
+	children []*tapNode
+	plan     int    // Planned test count, -1 when there is no plan
+	skipAll  string // Reason from a "1..0 # SKIP reason" plan
+}
+
+// tapNode is a parsed test point, with the test points of its subtest as children
+type tapNode struct {
+	name     string
+	status   string
+	reason   string  // SKIP or TODO directive text
+	duration float64 // Milliseconds, from the "duration_ms" diagnostic
+	diag     map[string]string
+	comments []string // "# ..." lines following a failing test point
+	children []*tapNode
+	plan     int
+}
+
+// tapCounts tallies test case results below a group
+type tapCounts struct {
+	passed  int
+	failed  int
+	skipped int
+	xfailed int
+}
+
+// NewTAPDefinition creates a new TAP runner definition
+func NewTAPDefinition(logger *logger.FileLogger) *TAPDefinition {
+	return &TAPDefinition{
+		logger: logger,
+	}
+}
+
+// Name returns the name of this test runner
+func (t *TAPDefinition) Name() string {
+	return "tap"
+}
+
+// Detect checks if the command produces TAP: "node --test", tape, or a Perl ".t" script
+func (t *TAPDefinition) Detect(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch commandBaseName(args[0]) {
+	case "node", "node.exe":
+		for _, arg := range args[1:] {
+			if arg == "--test" {
+				return true
+			}
+		}
+	case "tape":
+		return true
+	case "npx":
+		return len(args) >= 2 && commandBaseName(args[1]) == "tape"
+	case "perl":
+		for _, arg := range args[1:] {
+			if !strings.HasPrefix(arg, "-") && strings.HasSuffix(arg, ".t") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ModifyCommand selects the TAP reporter for "node --test" unless the command picks a reporter.
+// Other TAP producers are run unchanged.
+func (t *TAPDefinition) ModifyCommand(cmd []string, ipcPath, runID string) []string {
+	if len(cmd) == 0 || (commandBaseName(cmd[0]) != "node" && commandBaseName(cmd[0]) != "node.exe") {
+		return cmd
+	}
+
+	for _, arg := range cmd {
+		if arg == "--test-reporter" || strings.HasPrefix(arg, "--test-reporter=") {
+			return cmd
+		}
+	}
+
+	// Node options must come before the script arguments
+	result := make([]string, 0, len(cmd)+1)
+	result = append(result, cmd[0], "--test-reporter=tap")
+	result = append(result, cmd[1:]...)
+	return result
+}
+
+// GetTestFiles returns empty array for dynamic discovery
+func (t *TAPDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// RequiresAdapter returns false as TAP is parsed from the command output
+func (t *TAPDefinition) RequiresAdapter() bool {
+	return false
+}
+
+// ProcessOutput parses TAP from the command output and converts it to IPC events
+func (t *TAPDefinition) ProcessOutput(output io.Reader, ipcPath string) error {
+	var err error
+	t.ipcWriter, err = NewIPCWriter(ipcPath)
+	if err != nil {
+		return fmt.Errorf("failed to create IPC writer: %w", err)
+	}
+	defer func() {
+		if err := t.ipcWriter.Close(); err != nil {
+			t.logger.Debug("Failed to close IPC writer: %v", err)
+		}
+	}()
+
+	t.reset()
+
+	scanner := bufio.NewScanner(output)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+	for scanner.Scan() {
+		t.processLine(scanner.Text())
+	}
+
+	t.finish()
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading output: %w", err)
+	}
+	return nil
+}
+
+// reset clears the parser state before a run
+func (t *TAPDefinition) reset() {
+	t.stack = []*tapBlock{{plan: -1}}
+	t.pendingSubtest = ""
+	t.lastNode = nil
+	t.inYAML = false
+	t.yamlLines = nil
+	t.pending = nil
+	t.rootStarted = false
+	t.rootCounts = tapCounts{}
+	t.rootPoints = 0
+}
+
+// processLine handles a single line of TAP
+func (t *TAPDefinition) processLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	content := strings.TrimSpace(line)
+	indent := tapIndent(line)
+
+	if t.inYAML {
+		if content == "..." {
+			t.finishYAML()
+		} else {
+			t.yamlLines = append(t.yamlLines, line)
+		}
+		return
+	}
+
+	if content == "---" && t.lastNode != nil && indent > t.lastIndent {
+		t.inYAML = true
+		t.yamlLines = nil
+		return
+	}
+
+	if strings.HasPrefix(content, "# Subtest:") {
+		t.flushPending()
+		t.lastNode = nil
+		name := strings.TrimSpace(strings.TrimPrefix(content, "# Subtest:"))
+		switch {
+		case indent <= t.top().indent:
+			// node:test style: the comment is level with the test point it names
+			t.pendingSubtest = name
+		case t.pendingSubtest != "":
+			// The first subtest inside a block announced one level up
+			t.push(indent, t.pendingSubtest)
+			t.pendingSubtest = name
+		default:
+			// TAP 14 style: the comment is indented with the subtest's own lines
+			t.push(indent, name)
+		}
+		return
+	}
+
+	if match := tapPlanRegex.FindStringSubmatch(content); match != nil {
+		t.flushPending()
+		t.lastNode = nil
+		t.enterIndent(indent)
+		block := t.top()
+		block.plan, _ = strconv.Atoi(match[1])
+		if dm := tapDirectiveRegex.FindStringSubmatch("#" + match[2]); block.plan == 0 && dm != nil && strings.EqualFold(dm[1], "skip") {
+			block.skipAll = strings.TrimSpace(dm[2])
+			if block.skipAll == "" {
+				block.skipAll = "skipped"
+			}
+		}
+		return
+	}
+
+	if match := tapTestPointRegex.FindStringSubmatch(content); match != nil {
+		t.flushPending()
+		t.addTestPoint(indent, match)
+		return
+	}
+
+	if strings.HasPrefix(content, "Bail out!") {
+		t.flushPending()
+		reason := strings.TrimSpace(strings.TrimPrefix(content, "Bail out!"))
+		if reason == "" {
+			reason = "Bail out!"
+		}
+		t.logger.Debug("TAP producer bailed out: %s", reason)
+		node := &tapNode{name: "Bail out!", status: "FAIL", plan: -1, diag: map[string]string{"message": reason}}
+		t.closeBlocks(t.stack[0].indent)
+		t.stack[0].children = append(t.stack[0].children, node)
+		t.sendTopLevel(node)
+		t.lastNode = nil
+		return
+	}
+
+	if strings.HasPrefix(content, "#") {
+		// Diagnostics after a failing test point, e.g. Perl's "#   Failed test 'x'"
+		if t.lastNode != nil && t.lastNode.status == "FAIL" {
+			t.lastNode.comments = append(t.lastNode.comments, strings.TrimSpace(strings.TrimPrefix(content, "#")))
+		}
+		return
+	}
+
+	if content != "" {
+		// "TAP version" or output from the tests themselves
+		t.lastNode = nil
+	}
+}
+
+// addTestPoint records an "ok" / "not ok" line. A block indented below it is its subtest.
+func (t *TAPDefinition) addTestPoint(indent int, match []string) {
+	child := t.closeBlocks(indent)
+	if indent > t.top().indent {
+		// A test point nested without a "# Subtest:" line or preceding plan
+		t.push(indent, t.pendingSubtest)
+	}
+
+	node := &tapNode{status: "PASS", plan: -1}
+	description := match[3]
+	directive := ""
+	if dm := tapDirectiveRegex.FindStringSubmatchIndex(description); dm != nil {
+		directive = strings.ToUpper(description[dm[2]:dm[3]])
+		node.reason = strings.TrimSpace(description[dm[4]:dm[5]])
+		description = description[:dm[0]]
+	}
+	node.name = strings.TrimSpace(description)
+
+	failed := match[1] != ""
+	switch {
+	case directive == "SKIP":
+		node.status = "SKIP"
+	case directive == "TODO" && failed:
+		node.status = "XFAIL"
+	case failed:
+		node.status = "FAIL"
+	}
+
+	if child != nil {
+		node.children = child.children
+		node.plan = child.plan
+		if node.name == "" {
+			node.name = child.name
+		}
+		if child.skipAll != "" && len(child.children) == 0 {
+			node.status = "SKIP"
+			node.reason = child.skipAll
+		}
+	}
+	if node.name == "" {
+		node.name = t.pendingSubtest
+	}
+	if node.name == "" {
+		node.name = "test " + match[2]
+	}
+	t.pendingSubtest = ""
+
+	block := t.top()
+	block.children = append(block.children, node)
+	t.lastNode = node
+	t.lastIndent = indent
+
+	if len(t.stack) == 1 {
+		t.rootPoints++
+		// Sent once its diagnostics have been read
+		t.pending = node
+	}
+}
+
+// enterIndent opens or closes blocks so the top block matches a non-test-point line's indentation
+func (t *TAPDefinition) enterIndent(indent int) {
+	if indent > t.top().indent {
+		t.push(indent, t.pendingSubtest)
+		t.pendingSubtest = ""
+		return
+	}
+	if child := t.closeBlocks(indent); child != nil {
+		t.addUnclosed(child)
+	}
+}
+
+// closeBlocks pops blocks indented deeper than indent and returns the shallowest one.
+// Deeper blocks that never got their own test point are folded into their parent.
+func (t *TAPDefinition) closeBlocks(indent int) *tapBlock {
+	var child *tapBlock
+	for len(t.stack) > 1 && t.top().indent > indent {
+		if child != nil {
+			t.addUnclosed(child)
+		}
+		child = t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+	return child
+}
+
+// addUnclosed adds a subtest that ended without its closing test point to the top block.
+// This happens when the producer crashes mid-subtest, so the subtest is reported as failed.
+func (t *TAPDefinition) addUnclosed(block *tapBlock) {
+	name := block.name
+	if name == "" {
+		name = "subtest"
+	}
+	node := &tapNode{name: name, status: "FAIL", plan: block.plan, children: block.children}
+	node.diag = map[string]string{"message": "subtest ended without a result"}
+	t.top().children = append(t.top().children, node)
+	if len(t.stack) == 1 {
+		t.rootPoints++
+		t.sendTopLevel(node)
+	}
+}
+
+func (t *TAPDefinition) top() *tapBlock {
+	return t.stack[len(t.stack)-1]
+}
+
+func (t *TAPDefinition) push(indent int, name string) {
+	t.stack = append(t.stack, &tapBlock{indent: indent, name: name, plan: -1})
+}
+
+// finishYAML attaches a diagnostic block to the test point before it
+func (t *TAPDefinition) finishYAML() {
+	t.inYAML = false
+	if t.lastNode == nil {
+		return
+	}
+	t.lastNode.diag = parseTAPDiagnostics(t.yamlLines)
+	if ms, err := strconv.ParseFloat(t.lastNode.diag["duration_ms"], 64); err == nil {
+		t.lastNode.duration = ms
+	}
+	t.yamlLines = nil
+}
+
+// flushPending sends the top-level test point whose diagnostics are now complete
+func (t *TAPDefinition) flushPending() {
+	if t.pending == nil {
+		return
+	}
+	node := t.pending
+	t.pending = nil
+	t.sendTopLevel(node)
+}
+
+// finish closes any open subtests and sends the synthetic root group result
+func (t *TAPDefinition) finish() {
+	if t.inYAML {
+		t.finishYAML()
+	}
+	t.flushPending()
+	if child := t.closeBlocks(t.stack[0].indent); child != nil {
+		t.addUnclosed(child)
+	}
+
+	root := t.stack[0]
+	if !t.rootStarted && root.skipAll == "" {
+		return
+	}
+	t.ensureRootGroup()
+
+	counts := t.rootCounts
+	status := "PASS"
+	if counts.failed > 0 {
+		status = "FAIL"
+	} else if root.skipAll != "" || (counts.skipped > 0 && counts.passed == 0 && counts.xfailed == 0) {
+		status = "SKIP"
+	}
+
+	total := counts.passed + counts.failed + counts.skipped + counts.xfailed
+	if root.plan > t.rootPoints {
+		t.logger.Debug("TAP plan expected %d tests but %d ran", root.plan, t.rootPoints)
+		total += root.plan - t.rootPoints
+		status = "FAIL"
+	}
+
+	totals := map[string]interface{}{
+		"total":   total,
+		"passed":  counts.passed,
+		"failed":  counts.failed,
+		"skipped": counts.skipped,
+		"xfailed": counts.xfailed,
+	}
+	t.sendGroupResult(TAPRootGroupName, []string{}, status, 0, totals)
+}
+
+// sendTopLevel sends a completed top-level test point, either as a group with its subtests
+// or as a test case in the synthetic root group
+func (t *TAPDefinition) sendTopLevel(node *tapNode) {
+	if len(node.children) > 0 {
+		t.sendGroup(node, []string{})
+		return
+	}
+
+	t.ensureRootGroup()
+	t.sendTestCase(node, []string{TAPRootGroupName})
+	t.rootCounts.add(node.status)
+}
+
+// ensureRootGroup announces the synthetic root group the first time it is needed
+func (t *TAPDefinition) ensureRootGroup() {
+	if t.rootStarted {
+		return
+	}
+	t.rootStarted = true
+	t.sendGroupEvent("testGroupDiscovered", TAPRootGroupName, []string{})
+	t.sendGroupEvent("testGroupStart", TAPRootGroupName, []string{})
+}
+
+// sendGroup sends the events for a subtest and everything below it, returning its counts
+func (t *TAPDefinition) sendGroup(node *tapNode, parentNames []string) tapCounts {
+	t.sendGroupEvent("testGroupDiscovered", node.name, parentNames)
+	t.sendGroupEvent("testGroupStart", node.name, parentNames)
+
+	path := append(append([]string{}, parentNames...), node.name)
+	var counts tapCounts
+	duration := 0.0
+	for _, child := range node.children {
+		if len(child.children) > 0 {
+			childCounts := t.sendGroup(child, path)
+			counts.passed += childCounts.passed
+			counts.failed += childCounts.failed
+			counts.skipped += childCounts.skipped
+			counts.xfailed += childCounts.xfailed
+		} else {
+			t.sendTestCase(child, path)
+			counts.add(child.status)
+		}
+		duration += child.duration
+	}
+	if node.duration > 0 {
+		duration = node.duration
+	}
+
+	status := node.status
+	if counts.failed > 0 || (node.plan > len(node.children)) {
+		status = "FAIL"
+	} else if status == "XFAIL" {
+		status = "PASS"
+	}
+
+	total := counts.passed + counts.failed + counts.skipped + counts.xfailed
+	if node.plan > len(node.children) {
+		total += node.plan - len(node.children)
+	}
+	totals := map[string]interface{}{
+		"total":   total,
+		"passed":  counts.passed,
+		"failed":  counts.failed,
+		"skipped": counts.skipped,
+		"xfailed": counts.xfailed,
+	}
+	t.sendGroupResult(node.name, parentNames, status, duration, totals)
+	return counts
+}
+
+// add counts a test case status
+func (c *tapCounts) add(status string) {
+	switch status {
+	case "PASS":
+		c.passed++
+	case "FAIL":
+		c.failed++
+	case "SKIP":
+		c.skipped++
+	case "XFAIL":
+		c.xfailed++
+	}
+}
+
+// tapIndent returns the indentation width of a line, counting a tab as four spaces
+func tapIndent(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// parseTAPDiagnostics reads the top-level keys of a YAML diagnostic block. Scalars are
+// unquoted, block scalars ("|", "|-", ">") are joined with newlines, and nested
+// mappings or lists are kept as dedented text.
+func parseTAPDiagnostics(lines []string) map[string]string {
+	diag := make(map[string]string)
+
+	base := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			base = tapIndent(line)
+			break
+		}
+	}
+	if base < 0 {
+		return diag
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if tapIndent(line) != base || strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if value == "" || strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			// Collect the more deeply indented lines that follow
+			var nested []string
+			minIndent := -1
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || tapIndent(lines[i+1]) > base) {
+				i++
+				nested = append(nested, lines[i])
+				if strings.TrimSpace(lines[i]) != "" && (minIndent < 0 || tapIndent(lines[i]) < minIndent) {
+					minIndent = tapIndent(lines[i])
+				}
+			}
+			for j, n := range nested {
+				if len(n) >= minIndent && minIndent > 0 {
+					nested[j] = strings.TrimRight(n[minIndent:], " ")
+				} else {
+					nested[j] = strings.TrimSpace(n)
+				}
+			}
+			diag[key] = strings.Trim(strings.Join(nested, "\n"), "\n")
+			continue
+		}
+
+		diag[key] = unquoteTAPScalar(value)
+	}
+	return diag
+}
+
+// unquoteTAPScalar removes YAML single or double quotes from a scalar
+func unquoteTAPScalar(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// tapError builds the error payload for a failing test point from its diagnostics.
+// node:test reports "error", tape and others report "message"; Perl only prints comments.
+func tapError(node *tapNode) map[string]interface{} {
+	diag := node.diag
+	message := diag["message"]
+	if message == "" {
+		message = diag["error"]
+	}
+	if message == "" && len(node.comments) > 0 {
+		message = strings.Join(node.comments, "\n")
+	}
+	if message == "" {
+		message = node.name
+	}
+
+	errorInfo := map[string]interface{}{
+		"message": message,
+	}
+	if stack := diag["stack"]; stack != "" {
+		errorInfo["stack"] = stack
+	}
+	if expected, ok := diag["expected"]; ok {
+		errorInfo["expected"] = expected
+	}
+	if actual, ok := diag["actual"]; ok {
+		errorInfo["actual"] = actual
+	}
+	if location := diag["location"]; location != "" {
+		errorInfo["location"] = location
+	} else if at := diag["at"]; at != "" {
+		errorInfo["location"] = at
+	}
+	if name := diag["name"]; name != "" {
+		errorInfo["errorType"] = name
+	}
+	return errorInfo
+}
+
+// IPC event sending methods
+
+func (t *TAPDefinition) sendGroupEvent(eventType, groupName string, parentNames []string) {
+	event := map[string]interface{}{
+		"eventType": eventType,
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+		},
+	}
+	if err := t.ipcWriter.WriteEvent(event); err != nil {
+		t.logger.Error("Failed to send %s: %v", eventType, err)
+	}
+}
+
+func (t *TAPDefinition) sendGroupResult(groupName string, parentNames []string, status string, duration float64, totals map[string]interface{}) {
+	event := map[string]interface{}{
+		"eventType": "testGroupResult",
+		"payload": map[string]interface{}{
+			"groupName":   groupName,
+			"parentNames": parentNames,
+			"status":      status,
+			"duration":    duration, // Already in milliseconds
+			"totals":      totals,
+		},
+	}
+	if err := t.ipcWriter.WriteEvent(event); err != nil {
+		t.logger.Error("Failed to send testGroupResult: %v", err)
+	}
+}
+
+func (t *TAPDefinition) sendTestCase(node *tapNode, parentNames []string) {
+	payload := map[string]interface{}{
+		"testName":    node.name,
+		"parentNames": parentNames,
+		"status":      node.status,
+		"duration":    int64(node.duration),
+	}
+	switch node.status {
+	case "FAIL":
+		payload["error"] = tapError(node)
+	case "SKIP":
+		if node.reason != "" {
+			payload["skipReason"] = node.reason
+		}
+	case "XFAIL":
+		payload["xfailReason"] = node.reason
+	}
+
+	event := map[string]interface{}{
+		"eventType": "testCase",
+		"payload":   payload,
+	}
+	if err := t.ipcWriter.WriteEvent(event); err != nil {
+		t.logger.Debug("Failed to write test case event: %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package definitions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/logger"
+)
+
+// sampleNodeTAP is "node --test --test-reporter=tap" output for a suite with nested tests
+const sampleNodeTAP = `TAP version 13
+# Subtest: math
+    # Subtest: adds
+    ok 1 - adds
+      ---
+      duration_ms: 0.52
+      ...
+    # Subtest: divides
+    not ok 2 - divides
+      ---
+      duration_ms: 1.25
+      location: '/app/test/math.test.js:9:3'
+      failureType: 'testCodeFailure'
+      error: |-
+        Expected values to be strictly equal:
+
+        1 !== 2
+
+      code: 'ERR_ASSERTION'
+      name: 'AssertionError'
+      expected: 2
+      actual: 1
+      stack: |-
+        TestContext.<anonymous> (/app/test/math.test.js:10:12)
+        Test.runInAsyncScope (node:async_hooks:206:9)
+      ...
+    # Subtest: rounds
+    ok 3 - rounds # SKIP not implemented
+      ---
+      duration_ms: 0.1
+      ...
+    1..3
+not ok 1 - math
+  ---
+  duration_ms: 3.5
+  type: 'suite'
+  ...
+# Subtest: standalone
+ok 2 - standalone
+  ---
+  duration_ms: 0.3
+  ...
+1..2
+# tests 4
+# pass 2
+`
+
+func TestTAPDefinition_Detect(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewTAPDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"node test runner", []string{"node", "--test"}, true},
+		{"node test runner with files", []string{"node", "--test", "test/"}, true},
+		{"tape", []string{"tape", "test/*.js"}, true},
+		{"npx tape", []string{"npx", "tape", "test/a.js"}, true},
+		{"perl script", []string{"perl", "-Ilib", "t/basic.t"}, true},
+		{"node script", []string{"node", "server.js"}, false},
+		{"perl script without .t", []string{"perl", "build.pl"}, false},
+		{"npx jest", []string{"npx", "jest"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.Detect(tt.args); got != tt.expected {
+				t.Errorf("Detect(%v) = %v, expected %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTAPDefinition_ModifyCommand(t *testing.T) {
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewTAPDefinition(lg)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"node gets the tap reporter", []string{"node", "--test", "test/"}, []string{"node", "--test-reporter=tap", "--test", "test/"}},
+		{"node reporter is kept", []string{"node", "--test", "--test-reporter=spec"}, []string{"node", "--test", "--test-reporter=spec"}},
+		{"tape is unchanged", []string{"npx", "tape", "test/a.js"}, []string{"npx", "tape", "test/a.js"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := def.ModifyCommand(tt.args, "", ""); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseTAPDiagnostics(t *testing.T) {
+	lines := []string{
+		"    operator: equal",
+		"    expected: 'it''s'",
+		"    actual:   \"it\\tis\"",
+		"    at: Test.<anonymous> (/app/test.js:5:5)",
+		"    stack: |-",
+		"      Error: should be equal",
+		"          at Test.assert (/app/node_modules/tape/lib/test.js:1:1)",
+	}
+	expected := map[string]string{
+		"operator": "equal",
+		"expected": "it's",
+		"actual":   "it\tis",
+		"at":       "Test.<anonymous> (/app/test.js:5:5)",
+		"stack":    "Error: should be equal\n    at Test.assert (/app/node_modules/tape/lib/test.js:1:1)",
+	}
+	if got := parseTAPDiagnostics(lines); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// tapEvent is an IPC event read back from the file written by ProcessOutput
+type tapEvent struct {
+	EventType string                 `json:"eventType"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+func runTAP(t *testing.T, output string) []tapEvent {
+	t.Helper()
+	lg, _ := logger.NewFileLogger()
+	defer func() { _ = lg.Close() }()
+	def := NewTAPDefinition(lg)
+
+	ipcPath := filepath.Join(t.TempDir(), "ipc.jsonl")
+	if err := def.ProcessOutput(strings.NewReader(output), ipcPath); err != nil {
+		t.Fatalf("ProcessOutput returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ipcPath)
+	if err != nil {
+		t.Fatalf("Failed to read IPC file: %v", err)
+	}
+
+	var events []tapEvent
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var event tapEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid IPC line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestTAPDefinition_ProcessOutput_Subtests(t *testing.T) {
+	events := runTAP(t, sampleNodeTAP)
+
+	testStatuses := make(map[string]string)
+	testParents := make(map[string]string)
+	groupResults := make(map[string]string)
+	var failure map[string]interface{}
+	var skipReason interface{}
+	for _, event := range events {
+		switch event.EventType {
+		case "testCase":
+			name := event.Payload["testName"].(string)
+			testStatuses[name] = event.Payload["status"].(string)
+			parents, _ := json.Marshal(event.Payload["parentNames"])
+			testParents[name] = string(parents)
+			if name == "divides" {
+				failure, _ = event.Payload["error"].(map[string]interface{})
+			}
+			if name == "rounds" {
+				skipReason = event.Payload["skipReason"]
+			}
+		case "testGroupResult":
+			groupResults[event.Payload["groupName"].(string)] = event.Payload["status"].(string)
+		}
+	}
+
+	expectedStatuses := map[string]string{
+		"adds":       "PASS",
+		"divides":    "FAIL",
+		"rounds":     "SKIP",
+		"standalone": "PASS",
+	}
+	if !reflect.DeepEqual(testStatuses, expectedStatuses) {
+		t.Errorf("Expected statuses %v, got %v", expectedStatuses, testStatuses)
+	}
+	if skipReason != "not implemented" {
+		t.Errorf("Expected the SKIP directive as skip reason, got %v", skipReason)
+	}
+	if testParents["adds"] != `["math"]` || testParents["standalone"] != `["`+TAPRootGroupName+`"]` {
+		t.Errorf("Unexpected test hierarchy: %v", testParents)
+	}
+
+	if failure == nil {
+		t.Fatal("Expected error details for the failing test")
+	}
+	if !strings.Contains(failure["message"].(string), "1 !== 2") {
+		t.Errorf("Expected assertion message, got %q", failure["message"])
+	}
+	if failure["location"] != "/app/test/math.test.js:9:3" || failure["errorType"] != "AssertionError" {
+		t.Errorf("Expected location and error type, got %v", failure)
+	}
+	if failure["expected"] != "2" || failure["actual"] != "1" {
+		t.Errorf("Expected expected/actual values, got %v", failure)
+	}
+	if !strings.HasPrefix(failure["stack"].(string), "TestContext.<anonymous>") {
+		t.Errorf("Expected stack trace, got %q", failure["stack"])
+	}
+
+	expectedGroups := map[string]string{
+		"math":           "FAIL",
+		TAPRootGroupName: "PASS",
+	}
+	if !reflect.DeepEqual(groupResults, expectedGroups) {
+		t.Errorf("Expected group results %v, got %v", expectedGroups, groupResults)
+	}
+}
+
+func TestTAPDefinition_ProcessOutput_FlatWithComments(t *testing.T) {
+	// Perl Test::More output: no subtests, failure details as comments
+	output := `1..3
+ok 1 - loads module
+not ok 2 - parses config
+#   Failed test 'parses config'
+#   at t/basic.t line 12.
+ok 3 # skip no network
+# Looks like you failed 1 test of 3.
+`
+	events := runTAP(t, output)
+
+	var failure map[string]interface{}
+	var rootTotals map[string]interface{}
+	statuses := make(map[string]string)
+	for _, event := range events {
+		switch event.EventType {
+		case "testCase":
+			name := event.Payload["testName"].(string)
+			statuses[name] = event.Payload["status"].(string)
+			if name == "parses config" {
+				failure, _ = event.Payload["error"].(map[string]interface{})
+			}
+		case "testGroupResult":
+			if event.Payload["groupName"] != TAPRootGroupName || event.Payload["status"] != "FAIL" {
+				t.Errorf("Unexpected group result %v", event.Payload)
+			}
+			rootTotals, _ = event.Payload["totals"].(map[string]interface{})
+		}
+	}
+
+	expected := map[string]string{
+		"loads module":  "PASS",
+		"parses config": "FAIL",
+		"test 3":        "SKIP",
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, statuses)
+	}
+	if failure == nil || failure["message"] != "Failed test 'parses config'\nat t/basic.t line 12." {
+		t.Errorf("Expected comment diagnostics as the message, got %v", failure)
+	}
+	if rootTotals == nil || rootTotals["total"] != float64(3) || rootTotals["failed"] != float64(1) {
+		t.Errorf("Unexpected root totals %v", rootTotals)
+	}
+}
+
+func TestTAPDefinition_ProcessOutput_MissingPlannedTests(t *testing.T) {
+	// The producer died after the first of four planned tests
+	events := runTAP(t, "1..4\nok 1 - first\n")
+
+	last := events[len(events)-1]
+	if last.EventType != "testGroupResult" || last.Payload["status"] != "FAIL" {
+		t.Fatalf("Expected failing root group result, got %v", last)
+	}
+	totals := last.Payload["totals"].(map[string]interface{})
+	if totals["total"] != float64(4) || totals["passed"] != float64(1) {
+		t.Errorf("Expected the plan to count missing tests, got %v", totals)
+	}
+}
+
+func TestTAPDefinition_ProcessOutput_TAP14Subtests(t *testing.T) {
+	// TAP 14 subtests indent the "# Subtest" comment with the subtest's lines
+	output := `TAP version 14
+    # Subtest: parser
+    1..2
+    ok 1 - reads numbers
+    not ok 2 - reads strings # TODO escapes
+ok 1 - parser
+1..1
+`
+	events := runTAP(t, output)
+
+	statuses := make(map[string]string)
+	var groups []string
+	for _, event := range events {
+		switch event.EventType {
+		case "testCase":
+			statuses[event.Payload["testName"].(string)] = event.Payload["status"].(string)
+		case "testGroupResult":
+			groups = append(groups, event.Payload["groupName"].(string)+"="+event.Payload["status"].(string))
+		}
+	}
+
+	expected := map[string]string{"reads numbers": "PASS", "reads strings": "XFAIL"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Expected statuses %v, got %v", expected, statuses)
+	}
+	if !reflect.DeepEqual(groups, []string{"parser=PASS"}) {
+		t.Errorf("Expected only the parser group, got %v", groups)
+	}
+}
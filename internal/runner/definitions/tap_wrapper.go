@@ -0,0 +1,54 @@
+package definitions
+
+// TAPWrapper wraps TAPDefinition to implement the Definition interface from runner package
+type TAPWrapper struct {
+	*TAPDefinition
+}
+
+// NewTAPWrapper creates a new wrapper for TAP producers
+func NewTAPWrapper(impl *TAPDefinition) *TAPWrapper {
+	return &TAPWrapper{TAPDefinition: impl}
+}
+
+// Matches checks if this runner can handle the given command
+func (w *TAPWrapper) Matches(command []string) bool {
+	return w.Detect(command)
+}
+
+// GetTestFiles returns list of test files (empty for dynamic discovery)
+func (w *TAPWrapper) GetTestFiles(args []string) ([]string, error) {
+	return w.TAPDefinition.GetTestFiles(args)
+}
+
+// BuildCommand returns the command to run; TAP needs no adapter
+func (w *TAPWrapper) BuildCommand(args []string, adapterPath string) []string {
+	return w.ModifyCommand(args, "", "")
+}
+
+// GetAdapterFileName returns empty as TAP output is parsed directly
+func (w *TAPWrapper) GetAdapterFileName() string {
+	return ""
+}
+
+// InterpretExitCode maps exit codes to success/failure
+func (w *TAPWrapper) InterpretExitCode(code int) string {
+	if code == 0 {
+		return "success"
+	}
+	return "failure"
+}
+
+// ReproCommand returns a command that reruns only the given failed tests
+func (w *TAPWrapper) ReproCommand(failures []FailedTest) string {
+	return w.TAPDefinition.ReproCommand(failures)
+}
+
+// IsNative returns true as TAP output is parsed without an adapter
+func (w *TAPWrapper) IsNative() bool {
+	return true
+}
+
+// GetNativeDefinition returns the underlying TAP definition
+func (w *TAPWrapper) GetNativeDefinition() interface{} {
+	return w.TAPDefinition
+}
@@ -0,0 +1,166 @@
+package definitions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+// VitestJSONReportFile is the name of the file, in the run directory, that Vitest's json
+// reporter writes next to the 3pio reporter. It is only read when the 3pio reporter
+// reported no tests, e.g. because it failed to load in an unsupported Vitest version.
+const VitestJSONReportFile = "vitest-results.json"
+
+// vitestJSONReport is the report Vitest's json reporter writes (Jest's JSON format)
+type vitestJSONReport struct {
+	TestResults []vitestJSONFile `json:"testResults"`
+}
+
+// vitestJSONFile holds the results of one test file
+type vitestJSONFile struct {
+	Name             string                `json:"name"`
+	Status           string                `json:"status"`
+	Message          string                `json:"message"`
+	StartTime        float64               `json:"startTime"`
+	EndTime          float64               `json:"endTime"`
+	AssertionResults []vitestJSONAssertion `json:"assertionResults"`
+}
+
+// vitestJSONAssertion is one test case of a test file
+type vitestJSONAssertion struct {
+	AncestorTitles  []string `json:"ancestorTitles"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Duration        float64  `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+}
+
+// vitestJSONGroup accumulates the results of a file or describe block
+type vitestJSONGroup struct {
+	name        string
+	parentNames []string
+	totals      ipc.GroupTotals
+}
+
+// VitestJSONReportEvents reads a report written by Vitest's json reporter and returns
+// the group and test case events the 3pio reporter would have sent for it
+func VitestJSONReportEvents(reportPath string) ([]ipc.Event, error) {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vitest JSON report: %w", err)
+	}
+	var report vitestJSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse Vitest JSON report: %w", err)
+	}
+
+	var events []ipc.Event
+	for _, file := range report.TestResults {
+		events = append(events, vitestFileEvents(file)...)
+	}
+	return events, nil
+}
+
+// vitestFileEvents converts the results of one test file into IPC events: the file's
+// discovery and start, its test cases, a result for each describe block (innermost
+// first) and the file's result. A file that failed without running any test, such as
+// one that doesn't compile, gets a setup failure instead.
+func vitestFileEvents(file vitestJSONFile) []ipc.Event {
+	duration := 0.0
+	if file.EndTime > file.StartTime {
+		duration = file.EndTime - file.StartTime
+	}
+	events := []ipc.Event{
+		ipc.NewGroupDiscoveredEvent(file.Name, nil),
+		ipc.NewGroupStartEvent(file.Name, nil),
+	}
+
+	if len(file.AssertionResults) == 0 && file.Status == "failed" {
+		message := strings.TrimSpace(file.Message)
+		if message == "" {
+			message = "Test file failed to run"
+		}
+		return append(events, ipc.NewGroupErrorEvent(file.Name, nil, "SETUP_FAILURE", duration, message))
+	}
+
+	fileGroup := &vitestJSONGroup{name: file.Name}
+	groups := map[string]*vitestJSONGroup{}
+	var order []*vitestJSONGroup // Describe blocks in the order they were first seen
+	for _, test := range file.AssertionResults {
+		parentNames := append([]string{file.Name}, test.AncestorTitles...)
+		status := vitestTestStatus(test.Status)
+
+		event := ipc.NewGroupTestCaseEvent(test.Title, parentNames, status)
+		event.Payload.Duration = test.Duration
+		if len(test.FailureMessages) > 0 {
+			message := strings.Join(test.FailureMessages, "\n")
+			firstLine, _, _ := strings.Cut(message, "\n")
+			event.Payload.Error = &ipc.TestError{Message: firstLine, Stack: message}
+		}
+		events = append(events, event)
+
+		// Count the test in the file and every describe block around it
+		countVitestTest(&fileGroup.totals, status)
+		for depth := 1; depth < len(parentNames); depth++ {
+			key := strings.Join(parentNames[:depth+1], "\x00")
+			group, ok := groups[key]
+			if !ok {
+				group = &vitestJSONGroup{name: parentNames[depth], parentNames: parentNames[:depth]}
+				groups[key] = group
+				order = append(order, group)
+			}
+			countVitestTest(&group.totals, status)
+		}
+	}
+
+	// Innermost blocks first so each group completes before its parent
+	for i := len(order) - 1; i >= 0; i-- {
+		group := order[i]
+		result := ipc.NewGroupResultEvent(group.name, group.parentNames, vitestGroupStatus(group.totals), 0)
+		result.Payload.Totals = group.totals
+		events = append(events, result)
+	}
+	result := ipc.NewGroupResultEvent(file.Name, nil, vitestGroupStatus(fileGroup.totals), duration)
+	result.Payload.Totals = fileGroup.totals
+	return append(events, result)
+}
+
+// vitestTestStatus maps a test status in the JSON report to a 3pio status
+func vitestTestStatus(status string) string {
+	switch status {
+	case "passed":
+		return "PASS"
+	case "failed":
+		return "FAIL"
+	default: // skipped, pending, todo, disabled
+		return "SKIP"
+	}
+}
+
+// countVitestTest adds a test with a 3pio status to totals
+func countVitestTest(totals *ipc.GroupTotals, status string) {
+	switch status {
+	case "PASS":
+		totals.Passed++
+	case "FAIL":
+		totals.Failed++
+	default:
+		totals.Skipped++
+	}
+	totals.Total++
+}
+
+// vitestGroupStatus returns the status of a group with the given totals
+func vitestGroupStatus(totals ipc.GroupTotals) string {
+	switch {
+	case totals.Failed > 0:
+		return "FAIL"
+	case totals.Passed == 0 && totals.Skipped > 0:
+		return "SKIP"
+	default:
+		return "PASS"
+	}
+}
@@ -0,0 +1,88 @@
+package definitions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zk/3pio/internal/ipc"
+)
+
+func TestVitestJSONReportEvents(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), VitestJSONReportFile)
+	report := `{"numTotalTests":4,"testResults":[
+{"name":"/src/cart.test.ts","status":"failed","message":"","startTime":1000,"endTime":1300,"assertionResults":[
+ {"ancestorTitles":["cart","totals"],"title":"sums","status":"passed","duration":2,"failureMessages":[]},
+ {"ancestorTitles":["cart"],"title":"empties","status":"failed","duration":4,"failureMessages":["AssertionError: expected 1 to be 0\n    at cart.test.ts:9"]},
+ {"ancestorTitles":[],"title":"later","status":"todo","failureMessages":[]}]},
+{"name":"/src/broken.test.ts","status":"failed","message":"SyntaxError: Unexpected token","startTime":1000,"endTime":1010,"assertionResults":[]}]}`
+	if err := os.WriteFile(reportPath, []byte(report), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+
+	events, err := VitestJSONReportEvents(reportPath)
+	if err != nil {
+		t.Fatalf("VitestJSONReportEvents failed: %v", err)
+	}
+
+	var tests []ipc.TestCasePayload
+	results := make(map[string]ipc.GroupResultPayload)
+	var resultOrder []string
+	var setupFailure *ipc.GroupErrorPayload
+	for _, event := range events {
+		switch e := event.(type) {
+		case ipc.GroupTestCaseEvent:
+			tests = append(tests, e.Payload)
+		case ipc.GroupResultEvent:
+			results[e.Payload.GroupName] = e.Payload
+			resultOrder = append(resultOrder, e.Payload.GroupName)
+		case ipc.GroupErrorEvent:
+			setupFailure = &e.Payload
+		}
+	}
+
+	if len(tests) != 3 {
+		t.Fatalf("Expected 3 test cases, got %+v", tests)
+	}
+	if tests[0].Status != "PASS" || len(tests[0].ParentNames) != 3 || tests[0].ParentNames[2] != "totals" {
+		t.Errorf("Expected sums to pass under cart > totals, got %+v", tests[0])
+	}
+	if tests[1].Status != "FAIL" || tests[1].Error == nil || tests[1].Error.Message != "AssertionError: expected 1 to be 0" {
+		t.Errorf("Expected empties to fail with the first line of its message, got %+v", tests[1])
+	}
+	if tests[2].Status != "SKIP" {
+		t.Errorf("Expected the todo test to be skipped, got %+v", tests[2])
+	}
+
+	// Describe blocks complete innermost first, then the file
+	wantOrder := []string{"totals", "cart", "/src/cart.test.ts"}
+	for i, name := range wantOrder {
+		if i >= len(resultOrder) || resultOrder[i] != name {
+			t.Fatalf("Expected group results in order %v, got %v", wantOrder, resultOrder)
+		}
+	}
+	file := results["/src/cart.test.ts"]
+	if file.Status != "FAIL" || file.Totals.Passed != 1 || file.Totals.Failed != 1 || file.Totals.Skipped != 1 || file.Duration != 300 {
+		t.Errorf("Expected the file to fail with 1 passed, 1 failed, 1 skipped in 300ms, got %+v", file)
+	}
+	if results["totals"].Status != "PASS" {
+		t.Errorf("Expected the totals block to pass, got %+v", results["totals"])
+	}
+
+	if setupFailure == nil || setupFailure.GroupName != "/src/broken.test.ts" || setupFailure.Error.Message != "SyntaxError: Unexpected token" {
+		t.Errorf("Expected broken.test.ts to be a setup failure, got %+v", setupFailure)
+	}
+}
+
+func TestVitestJSONReportEvents_InvalidReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), VitestJSONReportFile)
+	if err := os.WriteFile(reportPath, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	if _, err := VitestJSONReportEvents(reportPath); err == nil {
+		t.Error("Expected an error for an invalid report")
+	}
+	if _, err := VitestJSONReportEvents(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing report")
+	}
+}
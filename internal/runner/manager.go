@@ -2,6 +2,7 @@ package runner
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/zk/3pio/internal/logger"
@@ -35,6 +36,7 @@ func NewManager(fileLogger *logger.FileLogger) *Manager {
 	m.Register("cypress", NewCypressDefinition())
 	m.Register("mocha", NewMochaDefinition())
 	m.Register("pytest", NewPytestDefinition())
+	m.Register("tox", NewToxDefinition())
 
 	// Register Go test runner (native, no adapter)
 	m.Register("go", definitions.NewGoTestWrapper(fileLogger))
@@ -46,6 +48,22 @@ func NewManager(fileLogger *logger.FileLogger) *Manager {
 	nextestImpl := definitions.NewNextestDefinition(fileLogger)
 	m.Register("nextest", definitions.NewNextestWrapper(nextestImpl))
 
+	// Register JVM build tools (native, results read from JUnit XML reports)
+	m.Register("maven", definitions.NewMavenWrapper(definitions.NewMavenDefinition(fileLogger)))
+	m.Register("gradle", definitions.NewGradleWrapper(definitions.NewGradleDefinition(fileLogger)))
+
+	// Register .NET test runner (native, results read from TRX files)
+	m.Register("dotnet", definitions.NewDotnetTestWrapper(definitions.NewDotnetTestDefinition(fileLogger)))
+
+	// Register RSpec (native, results read from the JSON formatter)
+	m.Register("rspec", definitions.NewRSpecWrapper(definitions.NewRSpecDefinition(fileLogger)))
+
+	// Register Playwright (native, results read from the JSON reporter)
+	m.Register("playwright", definitions.NewPlaywrightWrapper(definitions.NewPlaywrightDefinition(fileLogger)))
+
+	// Register TAP producers (native, TAP parsed from the output)
+	m.Register("tap", definitions.NewTAPWrapper(definitions.NewTAPDefinition(fileLogger)))
+
 	return m
 }
 
@@ -79,6 +97,51 @@ func (m *Manager) Detect(command []string) (Definition, error) {
 	return nil, fmt.Errorf("no test runner detected for command: %s", strings.Join(command, " "))
 }
 
+// MatchingRunners returns the sorted names of every registered runner that matches
+// command, checked the same way as Detect. More than one name means detection is ambiguous.
+func (m *Manager) MatchingRunners(command []string) []string {
+	var names []string
+	for name, def := range m.runners {
+		if def.Matches(command) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && len(command) > 0 && isPackageManager(command[0]) {
+		for name, def := range m.runners {
+			if def.Matches([]string{"test"}) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names returns the sorted names of the registered runners
+func (m *Manager) Names() []string {
+	names := make([]string, 0, len(m.runners))
+	for name := range m.runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the runner registered as name, bypassing detection (--runner)
+func (m *Manager) Select(name string) (Definition, error) {
+	def, ok := m.runners[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown runner %q, valid runners are: %s", name, strings.Join(m.Names(), ", "))
+	}
+	return def, nil
+}
+
+// BuiltinNames returns the sorted names of the runners NewManager registers, for
+// validating --runner before a logger exists
+func BuiltinNames() []string {
+	return NewManager(nil).Names()
+}
+
 // GetDefinition returns a specific runner definition by name
 func (m *Manager) GetDefinition(name string) (Definition, bool) {
 	def, ok := m.runners[name]
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/zk/3pio/internal/logger"
@@ -77,7 +78,7 @@ func TestManager_Detect_FalsePositives(t *testing.T) {
 		},
 		{
 			name:        "bundler not bun",
-			command:     []string{"bundle", "exec", "rspec"},
+			command:     []string{"bundle", "exec", "rake", "test"},
 			shouldMatch: false,
 			description: "bundle command shouldn't be detected as bun",
 		},
@@ -102,3 +103,23 @@ func TestManager_Detect_FalsePositives(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_Select(t *testing.T) {
+	m := NewManager(nil)
+
+	def, err := m.Select("vitest")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := def.(*VitestDefinition); !ok {
+		t.Errorf("Expected vitest definition, got %T", def)
+	}
+
+	_, err = m.Select("karma")
+	if err == nil {
+		t.Fatal("Expected an error for an unknown runner")
+	}
+	if !strings.Contains(err.Error(), "jest, maven, mocha") {
+		t.Errorf("Expected the error to list valid runners, got: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pytestCollectedSummary matches the summary line of "pytest --collect-only -q", such as
+// "340 tests collected in 0.52s", "12/340 tests collected (328 deselected) in 0.10s" or
+// "5 tests collected, 2 errors in 0.12s". The first number is the count that will run.
+var pytestCollectedSummary = regexp.MustCompile(`(?m)^(\d+)(?:/\d+)? tests? collected`)
+
+// PreflightCommand builds the "pytest --collect-only -q" command used to count the tests
+// args will run, keeping the user's own arguments so the selection matches
+func (p *PytestDefinition) PreflightCommand(args []string) []string {
+	result := make([]string, 0, len(args)+2)
+
+	foundPytest := false
+	for _, arg := range args {
+		result = append(result, arg)
+		if !foundPytest && (strings.Contains(arg, "pytest") || strings.Contains(arg, "py.test")) {
+			foundPytest = true
+			result = append(result, "--collect-only", "-q")
+		}
+	}
+
+	if !foundPytest {
+		result = append(result, "--collect-only", "-q")
+	}
+
+	return result
+}
+
+// CollectTestCount runs the preflight collection for args with environment env and returns
+// the number of tests collected. Collection errors still return the count pytest reported, along with an
+// error describing them; the error alone means no count could be determined.
+func (p *PytestDefinition) CollectTestCount(dir string, env []string, args []string) (int, error) {
+	command := p.PreflightCommand(args)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, runErr := cmd.CombinedOutput()
+
+	count, ok := parsePytestCollectOutput(string(output))
+	if !ok {
+		if runErr != nil {
+			return 0, fmt.Errorf("pytest --collect-only failed: %w", runErr)
+		}
+		return 0, fmt.Errorf("pytest --collect-only printed no collection summary")
+	}
+	if runErr != nil {
+		return count, fmt.Errorf("pytest --collect-only reported errors: %w", runErr)
+	}
+	return count, nil
+}
+
+// parsePytestCollectOutput reads the test count from "pytest --collect-only -q" output.
+// Without a summary line it counts the listed test IDs instead.
+func parsePytestCollectOutput(output string) (int, bool) {
+	if match := pytestCollectedSummary.FindStringSubmatch(output); match != nil {
+		count, err := strconv.Atoi(match[1])
+		return count, err == nil
+	}
+	if strings.Contains(output, "no tests collected") {
+		return 0, true
+	}
+
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "::") && !strings.ContainsAny(line, " \t") {
+			count++
+		}
+	}
+	return count, count > 0
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPytestPreflightCommand(t *testing.T) {
+	pytest := NewPytestDefinition()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "direct pytest command",
+			args:     []string{"pytest", "tests/", "-k", "api"},
+			expected: []string{"pytest", "--collect-only", "-q", "tests/", "-k", "api"},
+		},
+		{
+			name:     "python -m pytest",
+			args:     []string{"python", "-m", "pytest", "-x"},
+			expected: []string{"python", "-m", "pytest", "--collect-only", "-q", "-x"},
+		},
+		{
+			name:     "pytest not found",
+			args:     []string{"./run-tests.sh"},
+			expected: []string{"./run-tests.sh", "--collect-only", "-q"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pytest.PreflightCommand(tt.args); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("PreflightCommand() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePytestCollectOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		wantCount int
+		wantOK    bool
+	}{
+		{
+			name:      "summary line",
+			output:    "tests/test_a.py::test_one\ntests/test_a.py::test_two\n\n340 tests collected in 0.52s\n",
+			wantCount: 340,
+			wantOK:    true,
+		},
+		{
+			name:      "single test",
+			output:    "tests/test_a.py::test_one\n\n1 test collected in 0.01s\n",
+			wantCount: 1,
+			wantOK:    true,
+		},
+		{
+			name:      "deselected tests",
+			output:    "12/340 tests collected (328 deselected) in 0.10s\n",
+			wantCount: 12,
+			wantOK:    true,
+		},
+		{
+			name:      "collection errors",
+			output:    "ERROR tests/test_broken.py\n5 tests collected, 2 errors in 0.12s\n",
+			wantCount: 5,
+			wantOK:    true,
+		},
+		{
+			name:      "no tests",
+			output:    "no tests collected in 0.01s\n",
+			wantCount: 0,
+			wantOK:    true,
+		},
+		{
+			name:      "test IDs without summary",
+			output:    "tests/test_a.py::test_one\ntests/test_a.py::TestCart::test_add[1-2]\n",
+			wantCount: 2,
+			wantOK:    true,
+		},
+		{
+			name:      "unrelated output",
+			output:    "ERROR: file or directory not found: missing/\n",
+			wantCount: 0,
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, ok := parsePytestCollectOutput(tt.output)
+			if count != tt.wantCount || ok != tt.wantOK {
+				t.Errorf("parsePytestCollectOutput() = (%d, %v), want (%d, %v)", count, ok, tt.wantCount, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// fileTestNames groups failures by their file (the root group) and returns the sorted
+// files and each failure's full name, built by joining the groups below the file and the
+// test name with sep
+func fileTestNames(failures []definitions.FailedTest, sep string) ([]string, []string) {
+	fileSet := make(map[string]bool)
+	nameSet := make(map[string]bool)
+	for _, failure := range failures {
+		if len(failure.ParentNames) == 0 {
+			continue
+		}
+		fileSet[failure.ParentNames[0]] = true
+		nameSet[strings.Join(append(append([]string{}, failure.ParentNames[1:]...), failure.Name), sep)] = true
+	}
+	return sortedKeys(fileSet), sortedKeys(nameSet)
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReproCommand returns a Jest command limited to the failing files and test names.
+// Jest matches -t against the describe titles and test title joined by spaces.
+func (j *JestDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	files, names := fileTestNames(failures, " ")
+	if len(files) == 0 {
+		return ""
+	}
+	args := append([]string{"npx", "jest"}, files...)
+	return definitions.ShellJoin(append(args, "-t", definitions.ExactNamePattern(names)))
+}
+
+// ReproCommand returns a Vitest command limited to the failing files and test names
+func (v *VitestDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	files, names := fileTestNames(failures, " ")
+	if len(files) == 0 {
+		return ""
+	}
+	args := append([]string{"npx", "vitest", "run"}, files...)
+	return definitions.ShellJoin(append(args, "-t", definitions.ExactNamePattern(names)))
+}
+
+// ReproCommand returns a pytest command listing the node id of each failed test
+func (p *PytestDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	nodeIDs := make(map[string]bool)
+	for _, failure := range failures {
+		if len(failure.ParentNames) == 0 {
+			continue
+		}
+		// The file and class groups followed by the test name form the node id
+		nodeIDs[strings.Join(append(append([]string{}, failure.ParentNames...), failure.Name), "::")] = true
+	}
+	if len(nodeIDs) == 0 {
+		return ""
+	}
+	return definitions.ShellJoin(append([]string{"pytest"}, sortedKeys(nodeIDs)...))
+}
+
+// ReproCommand returns a Cypress command limited to the failing spec files
+func (c *CypressDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	files, _ := fileTestNames(failures, " ")
+	if len(files) == 0 {
+		return ""
+	}
+	return definitions.ShellJoin([]string{"npx", "cypress", "run", "--spec", strings.Join(files, ",")})
+}
+
+// ReproCommand returns a Mocha command limited to the failing files and test titles.
+// Mocha matches --grep against the suite titles and test title joined by spaces.
+func (m *MochaDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	files, names := fileTestNames(failures, " ")
+	if len(files) == 0 {
+		return ""
+	}
+	args := append([]string{"npx", "mocha"}, files...)
+	return definitions.ShellJoin(append(args, "--grep", definitions.ExactNamePattern(names)))
+}
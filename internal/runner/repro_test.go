@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+func TestReproCommand(t *testing.T) {
+	failures := []definitions.FailedTest{
+		{Name: "adds (fast)", ParentNames: []string{"src/math.test.js", "Calculator"}},
+		{Name: "parses", ParentNames: []string{"src/parse.test.js"}},
+	}
+
+	tests := []struct {
+		name     string
+		def      Definition
+		expected string
+	}{
+		{"jest", NewJestDefinition(), `npx jest src/math.test.js src/parse.test.js -t '^(Calculator adds \(fast\)|parses)$'`},
+		{"vitest", NewVitestDefinition(), `npx vitest run src/math.test.js src/parse.test.js -t '^(Calculator adds \(fast\)|parses)$'`},
+		{"mocha", NewMochaDefinition(), `npx mocha src/math.test.js src/parse.test.js --grep '^(Calculator adds \(fast\)|parses)$'`},
+		{"cypress", NewCypressDefinition(), "npx cypress run --spec src/math.test.js,src/parse.test.js"},
+		{"pytest", NewPytestDefinition(), `pytest 'src/math.test.js::Calculator::adds (fast)' src/parse.test.js::parses`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.def.ReproCommand(failures); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+			if got := tt.def.ReproCommand(nil); got != "" {
+				t.Errorf("Expected no command without failures, got %q", got)
+			}
+		})
+	}
+}
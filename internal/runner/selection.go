@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// TestSelector is implemented by definitions that can translate --test-name-filter and
+// --test-file into their runner's own selector arguments. SelectTests returns the command
+// with those arguments added where the runner reads them, before BuildCommand runs.
+type TestSelector interface {
+	SelectTests(command []string, selection definitions.TestSelection) ([]string, error)
+}
+
+// selectNodeTests adds selector flags and test files to a Node test command. In a direct
+// invocation the flags go right after the runner (flagsAfterRunner) or before any "--";
+// in a package.json script they are appended, after a "--" when the package manager only
+// forwards arguments that follow one.
+func selectNodeTests(command []string, isRunner func(string) bool, separatorManagers []string, flagsAfterRunner bool, flags, files []string) []string {
+	runnerIndex := slices.IndexFunc(command, isRunner)
+	if runnerIndex >= 0 {
+		if flagsAfterRunner {
+			return append(slices.Insert(slices.Clone(command), runnerIndex+1, flags...), files...)
+		}
+		return definitions.InsertBeforeSeparator(command, append(flags, files...)...)
+	}
+
+	result := slices.Clone(command)
+	if len(command) > 0 && !slices.Contains(command, "--") && slices.Contains(separatorManagers, command[0]) {
+		result = append(result, "--")
+	}
+	return append(append(result, flags...), files...)
+}
+
+// SelectTests adds --testNamePattern right after jest, where BuildCommand's "--" before
+// test files can't end up in front of it, and the test files at the end
+func (j *JestDefinition) SelectTests(command []string, selection definitions.TestSelection) ([]string, error) {
+	var flags []string
+	if selection.NameFilter != "" {
+		flags = append(flags, "--testNamePattern="+selection.NameFilter)
+	}
+	isJest := func(arg string) bool { return strings.Contains(arg, "jest") }
+	// Yarn forwards script arguments without a separator
+	return selectNodeTests(command, isJest, []string{"npm", "pnpm", "bun"}, true, flags, selection.Files), nil
+}
+
+// SelectTests adds --testNamePattern and the test files as filters
+func (v *VitestDefinition) SelectTests(command []string, selection definitions.TestSelection) ([]string, error) {
+	var flags []string
+	if selection.NameFilter != "" {
+		flags = append(flags, "--testNamePattern="+selection.NameFilter)
+	}
+	// pnpm forwards script arguments without a separator
+	return selectNodeTests(command, isVitestCommand, []string{"npm", "yarn", "bun", "deno"}, false, flags, selection.Files), nil
+}
+
+// SelectTests adds --grep and the test files
+func (m *MochaDefinition) SelectTests(command []string, selection definitions.TestSelection) ([]string, error) {
+	var flags []string
+	if selection.NameFilter != "" {
+		flags = append(flags, "--grep="+selection.NameFilter)
+	}
+	isMocha := func(arg string) bool { return arg == "mocha" || strings.HasSuffix(arg, "/mocha") }
+	return selectNodeTests(command, isMocha, []string{"npm", "yarn", "bun"}, false, flags, selection.Files), nil
+}
+
+// SelectTests adds the test files as --spec. Cypress has no option to select tests by name.
+func (c *CypressDefinition) SelectTests(command []string, selection definitions.TestSelection) ([]string, error) {
+	if selection.NameFilter != "" {
+		return nil, fmt.Errorf("cypress has no option to select tests by name: use --test-file instead of --test-name-filter")
+	}
+	var flags []string
+	if len(selection.Files) > 0 {
+		flags = append(flags, "--spec", strings.Join(selection.Files, ","))
+	}
+	isCypress := func(arg string) bool { return arg == "cypress" || strings.HasSuffix(arg, "/cypress") }
+	return selectNodeTests(command, isCypress, []string{"npm", "yarn", "bun"}, false, flags, nil), nil
+}
+
+// SelectTests adds -k with the name filter, which pytest reads as a keyword expression,
+// and the test files
+func (p *PytestDefinition) SelectTests(command []string, selection definitions.TestSelection) ([]string, error) {
+	var args []string
+	if selection.NameFilter != "" {
+		args = append(args, "-k", selection.NameFilter)
+	}
+	return definitions.InsertBeforeSeparator(command, append(args, selection.Files...)...), nil
+}
+
+// VerifyCommand checks that every argument of command survived BuildCommand into built in
+// the same order, and that no flag ended up behind a "--" separator BuildCommand added,
+// where the runner would take it for a positional argument
+func VerifyCommand(command, built []string) error {
+	commandSeparators, builtSeparators := 0, 0
+	j := 0
+	for _, arg := range command {
+		for j < len(built) && built[j] != arg {
+			if built[j] == "--" {
+				builtSeparators++
+			}
+			j++
+		}
+		if j == len(built) {
+			return fmt.Errorf("argument %q is missing or out of order", arg)
+		}
+		if arg == "--" {
+			commandSeparators++
+			builtSeparators++
+		} else if strings.HasPrefix(arg, "-") && builtSeparators != commandSeparators {
+			return fmt.Errorf("flag %q ends up after a \"--\" separator", arg)
+		}
+		j++
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+func TestSelectTests(t *testing.T) {
+	selection := definitions.TestSelection{NameFilter: "Cart adds.items", Files: []string{"src/cart.test.js"}}
+
+	tests := []struct {
+		name     string
+		def      TestSelector
+		command  []string
+		expected []string
+	}{
+		{"jest direct", NewJestDefinition(), []string{"npx", "jest", "--ci"},
+			[]string{"npx", "jest", "--testNamePattern=Cart adds.items", "--ci", "src/cart.test.js"}},
+		{"jest npm script", NewJestDefinition(), []string{"npm", "test"},
+			[]string{"npm", "test", "--", "--testNamePattern=Cart adds.items", "src/cart.test.js"}},
+		{"jest yarn script", NewJestDefinition(), []string{"yarn", "test"},
+			[]string{"yarn", "test", "--testNamePattern=Cart adds.items", "src/cart.test.js"}},
+		{"vitest direct", NewVitestDefinition(), []string{"npx", "vitest", "run"},
+			[]string{"npx", "vitest", "run", "--testNamePattern=Cart adds.items", "src/cart.test.js"}},
+		{"vitest pnpm script", NewVitestDefinition(), []string{"pnpm", "test"},
+			[]string{"pnpm", "test", "--testNamePattern=Cart adds.items", "src/cart.test.js"}},
+		{"mocha direct", NewMochaDefinition(), []string{"npx", "mocha"},
+			[]string{"npx", "mocha", "--grep=Cart adds.items", "src/cart.test.js"}},
+		{"mocha npm script with separator", NewMochaDefinition(), []string{"npm", "test", "--", "--bail"},
+			[]string{"npm", "test", "--", "--bail", "--grep=Cart adds.items", "src/cart.test.js"}},
+		{"pytest", NewPytestDefinition(), []string{"python", "-m", "pytest", "-x"},
+			[]string{"python", "-m", "pytest", "-x", "-k", "Cart adds.items", "src/cart.test.js"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.def.SelectTests(tt.command, selection)
+			if err != nil {
+				t.Fatalf("SelectTests failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("Expected %q, got %q", tt.expected, got)
+			}
+			// The selection must survive the reporter injection in place
+			built := tt.def.(Definition).BuildCommand(got, "/tmp/adapter.js")
+			if err := VerifyCommand(got, built); err != nil {
+				t.Errorf("Selection did not survive BuildCommand: %v\n%q", err, built)
+			}
+		})
+	}
+}
+
+func TestSelectTests_Cypress(t *testing.T) {
+	def := NewCypressDefinition()
+	got, err := def.SelectTests([]string{"npx", "cypress", "run"}, definitions.TestSelection{Files: []string{"a.cy.js", "b.cy.js"}})
+	if err != nil {
+		t.Fatalf("SelectTests failed: %v", err)
+	}
+	if expected := []string{"npx", "cypress", "run", "--spec", "a.cy.js,b.cy.js"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if _, err := def.SelectTests([]string{"npx", "cypress", "run"}, definitions.TestSelection{NameFilter: "logs in"}); err == nil {
+		t.Error("Expected an error selecting Cypress tests by name")
+	}
+}
+
+func TestVerifyCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		built   []string
+		wantErr bool
+	}{
+		{"arguments kept with additions", []string{"npx", "jest", "-t", "adds", "src"},
+			[]string{"npx", "jest", "--reporters", "a.js", "-t", "adds", "--", "src"}, false},
+		{"script separator kept", []string{"npm", "test", "--", "-t", "adds"},
+			[]string{"npm", "test", "--", "-t", "adds", "--reporters", "a.js"}, false},
+		{"argument dropped", []string{"go", "test", "-run=Foo", "./..."},
+			[]string{"go", "test", "-json", "./..."}, true},
+		{"arguments reordered", []string{"pytest", "a.py", "b.py"},
+			[]string{"pytest", "b.py", "a.py"}, true},
+		// Jest's BuildCommand puts "--" before the first file-like argument
+		{"flag behind an added separator", []string{"npx", "jest", "src/a.test.js", "-t", "adds"},
+			[]string{"npx", "jest", "--reporters", "a.js", "--", "src/a.test.js", "-t", "adds"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyCommand(tt.command, tt.built); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+// ToxEnvGroupsVar tells the pytest adapter to report each tox environment's results
+// under a root group named after the environment (tox's TOX_ENV_NAME)
+const ToxEnvGroupsVar = "THREEPIO_TOX_ENV_GROUPS"
+
+// toxPassEnv are the variables tox must pass into its isolated environments for the
+// pytest adapter to load and report there
+var toxPassEnv = []string{"PYTHONPATH", "PYTEST_ADDOPTS", "THREEPIO_IPC_PATH", ToxEnvGroupsVar}
+
+// ToxDefinition implements Definition for tox running pytest in its environments. It
+// uses the pytest adapter, loaded through PYTEST_ADDOPTS rather than the command line
+// since tox.ini decides how pytest is started.
+type ToxDefinition struct {
+	BaseDefinition
+}
+
+// NewToxDefinition creates a new tox definition
+func NewToxDefinition() *ToxDefinition {
+	return &ToxDefinition{
+		BaseDefinition: BaseDefinition{
+			name:        "tox",
+			adapterFile: "pytest_adapter.py",
+		},
+	}
+}
+
+// toxLaunchers are the arguments that can come right before tox when another tool starts
+// it, as in "python -m tox", "uv run tox", "uvx tox" or "pipx run tox"
+var toxLaunchers = []string{"-m", "run", "uvx", "exec"}
+
+// isToxCommand reports whether command runs tox, either directly or through a launcher.
+// An argument named tox elsewhere, such as "pytest -k tox", doesn't count, and neither do
+// the arguments after "--", which tox passes on to the commands it runs.
+func isToxCommand(command []string) bool {
+	for i, arg := range command {
+		if arg == "--" {
+			return false
+		}
+		if !containsTestRunner([]string{arg}, "tox") && !containsTestRunner([]string{arg}, "tox.exe") {
+			continue
+		}
+		if i == 0 || slices.Contains(toxLaunchers, command[i-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks if the command runs tox
+func (t *ToxDefinition) Matches(command []string) bool {
+	return isToxCommand(command)
+}
+
+// GetTestFiles returns no files: tox.ini decides what pytest runs, so tests are
+// discovered as they run
+func (t *ToxDefinition) GetTestFiles(args []string) ([]string, error) {
+	return []string{}, nil
+}
+
+// BuildCommand loads the pytest adapter in every tox environment. The adapter's directory
+// goes on PYTHONPATH and "-p pytest_adapter" into PYTEST_ADDOPTS, and an --override
+// makes tox pass both into its environments, which otherwise only see a few variables.
+// Tox 4 is required for --override.
+func (t *ToxDefinition) BuildCommand(args []string, adapterPath string) []string {
+	addToPythonPath(filepath.Dir(adapterPath))
+
+	addopts := os.Getenv("PYTEST_ADDOPTS")
+	if !strings.Contains(addopts, "-p pytest_adapter") {
+		addopts = strings.TrimSpace("-p pytest_adapter " + addopts)
+	}
+	_ = os.Setenv("PYTEST_ADDOPTS", addopts)
+	_ = os.Setenv(ToxEnvGroupsVar, "1")
+
+	return definitions.InsertBeforeSeparator(args, "--override", "testenv.passenv+="+strings.Join(toxPassEnv, ","))
+}
+
+// ReproCommand returns a tox command that reruns the failed tests in the environments
+// they failed in, passing their pytest node ids as positional arguments ({posargs})
+func (t *ToxDefinition) ReproCommand(failures []definitions.FailedTest) string {
+	envs := make(map[string]bool)
+	nodeIDs := make(map[string]bool)
+	for _, failure := range failures {
+		// The tox environment, then the file and class groups
+		if len(failure.ParentNames) < 2 {
+			continue
+		}
+		envs[failure.ParentNames[0]] = true
+		nodeIDs[strings.Join(append(append([]string{}, failure.ParentNames[1:]...), failure.Name), "::")] = true
+	}
+	if len(nodeIDs) == 0 {
+		return ""
+	}
+	command := []string{"tox", "-e", strings.Join(sortedKeys(envs), ","), "--"}
+	return definitions.ShellJoin(append(command, sortedKeys(nodeIDs)...))
+}
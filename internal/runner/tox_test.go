@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/internal/runner/definitions"
+)
+
+func TestToxDefinition_Matches(t *testing.T) {
+	tox := NewToxDefinition()
+	pytest := NewPytestDefinition()
+	for _, tc := range []struct {
+		command []string
+		isTox   bool
+	}{
+		{[]string{"tox"}, true},
+		{[]string{"tox", "-e", "py311,py312"}, true},
+		{[]string{"tox", "-e", "py39", "--", "pytest", "-x"}, true},
+		{[]string{"python", "-m", "tox"}, true},
+		{[]string{"uvx", "tox", "-p"}, true},
+		{[]string{".venv/bin/tox"}, true},
+		{[]string{"pytest", "-k", "tox"}, false},
+		{[]string{"pytest", "tests/tox"}, false},
+		{[]string{"nox", "--", "tox"}, false},
+	} {
+		if got := tox.Matches(tc.command); got != tc.isTox {
+			t.Errorf("tox.Matches(%v) = %v, want %v", tc.command, got, tc.isTox)
+		}
+		// Pytest started by tox belongs to tox alone, so detection isn't ambiguous
+		if tc.isTox && pytest.Matches(tc.command) {
+			t.Errorf("pytest.Matches(%v) = true, want false for a tox command", tc.command)
+		}
+	}
+}
+
+func TestToxDefinition_BuildCommand(t *testing.T) {
+	t.Setenv("PYTHONPATH", "/project/src")
+	t.Setenv("PYTEST_ADDOPTS", "--strict-markers")
+	t.Setenv(ToxEnvGroupsVar, "")
+	adapterPath := filepath.Join("/run", "adapters", "pytest_adapter.py")
+
+	got := NewToxDefinition().BuildCommand([]string{"tox", "-e", "py312", "--", "-x"}, adapterPath)
+	want := []string{"tox", "-e", "py312", "--override", "testenv.passenv+=PYTHONPATH,PYTEST_ADDOPTS,THREEPIO_IPC_PATH,THREEPIO_TOX_ENV_GROUPS", "--", "-x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildCommand() = %v, want %v", got, want)
+	}
+
+	if pythonPath := os.Getenv("PYTHONPATH"); !strings.HasPrefix(pythonPath, filepath.Dir(adapterPath)+string(os.PathListSeparator)) {
+		t.Errorf("Expected the adapter directory first on PYTHONPATH, got %q", pythonPath)
+	}
+	if addopts := os.Getenv("PYTEST_ADDOPTS"); addopts != "-p pytest_adapter --strict-markers" {
+		t.Errorf("Expected the adapter plugin added to PYTEST_ADDOPTS, got %q", addopts)
+	}
+	if os.Getenv(ToxEnvGroupsVar) != "1" {
+		t.Errorf("Expected %s to be set", ToxEnvGroupsVar)
+	}
+
+	// Building again doesn't load the plugin twice
+	NewToxDefinition().BuildCommand([]string{"tox"}, adapterPath)
+	if addopts := os.Getenv("PYTEST_ADDOPTS"); addopts != "-p pytest_adapter --strict-markers" {
+		t.Errorf("Expected PYTEST_ADDOPTS to be unchanged, got %q", addopts)
+	}
+}
+
+func TestToxDefinition_ReproCommand(t *testing.T) {
+	failures := []definitions.FailedTest{
+		{Name: "test_add", ParentNames: []string{"py312", "tests/test_math.py", "TestCalc"}},
+		{Name: "test_parse", ParentNames: []string{"py311", "tests/test_parse.py"}},
+	}
+	want := "tox -e py311,py312 -- tests/test_math.py::TestCalc::test_add tests/test_parse.py::test_parse"
+	if got := NewToxDefinition().ReproCommand(failures); got != want {
+		t.Errorf("ReproCommand() = %q, want %q", got, want)
+	}
+}
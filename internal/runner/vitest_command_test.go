@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -409,3 +410,29 @@ func TestVitestNpmCommandFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestVitestBuildCommand_JSONReport(t *testing.T) {
+	vitest := NewVitestDefinition()
+	vitest.SetJSONReportPath("/run/vitest-results.json")
+
+	got := vitest.BuildCommand([]string{"npx", "vitest", "run"}, "/tmp/adapter.js")
+	want := []string{"npx", "vitest", "--reporter", "/tmp/adapter.js", "--reporter", "default",
+		"--reporter", "json", "--outputFile.json=/run/vitest-results.json", "run"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	got = vitest.BuildCommand([]string{"npm", "test"}, "/tmp/adapter.js")
+	want = []string{"npm", "test", "--", "--reporter", "/tmp/adapter.js", "--reporter", "default",
+		"--reporter", "json", "--outputFile.json=/run/vitest-results.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// The command's own --outputFile would conflict with ours
+	got = vitest.BuildCommand([]string{"npx", "vitest", "run", "--outputFile=junit.xml"}, "/tmp/adapter.js")
+	want = []string{"npx", "vitest", "--reporter", "/tmp/adapter.js", "--reporter", "default", "run", "--outputFile=junit.xml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,422 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WorkspaceGroupsVar tells the Jest and Vitest adapters to report each workspace
+// package's results under a root group named after the package (its package.json name)
+const WorkspaceGroupsVar = "THREEPIO_WORKSPACE_GROUPS"
+
+// workspaceFlag describes a package manager flag that picks or runs workspace packages
+type workspaceFlag struct {
+	values  int  // Arguments the flag takes after it, unless given as --flag=value
+	selects bool // Whether the flag picks packages, rather than only changing how they run
+}
+
+// workspaceFlags are the workspace flags of each package manager. Yarn picks a package with
+// a "workspace <name>" command rather than a flag, which is treated as one here.
+var workspaceFlags = map[string]map[string]workspaceFlag{
+	"pnpm": {
+		"--filter":                 {values: 1, selects: true},
+		"-F":                       {values: 1, selects: true},
+		"--filter-prod":            {values: 1, selects: true},
+		"-C":                       {values: 1, selects: true},
+		"--dir":                    {values: 1, selects: true},
+		"-r":                       {selects: true},
+		"--recursive":              {selects: true},
+		"--workspace-concurrency":  {values: 1},
+		"-w":                       {},
+		"--workspace-root":         {},
+		"--include-workspace-root": {},
+		"--parallel":               {},
+		"--stream":                 {},
+		"--aggregate-output":       {},
+		"--if-present":             {},
+		"--no-bail":                {},
+	},
+	"npm": {
+		"-w":                       {values: 1, selects: true},
+		"--workspace":              {values: 1, selects: true},
+		"-ws":                      {selects: true},
+		"--workspaces":             {selects: true},
+		"--include-workspace-root": {},
+		"--if-present":             {},
+	},
+	"yarn": {
+		"workspace": {values: 1, selects: true},
+	},
+}
+
+// lookupWorkspaceFlag returns the workspace flag arg is for manager, and the number of
+// arguments after arg that belong to it
+func lookupWorkspaceFlag(manager, arg string) (workspaceFlag, int, bool) {
+	name, _, hasValue := strings.Cut(arg, "=")
+	if !strings.HasPrefix(arg, "-") {
+		name, hasValue = arg, false
+	}
+	flag, ok := workspaceFlags[manager][name]
+	if !ok || (hasValue && flag.values == 0) {
+		return workspaceFlag{}, 0, false
+	}
+	if hasValue {
+		return flag, 0, true
+	}
+	return flag, flag.values, true
+}
+
+// SplitWorkspaceFlags separates the workspace flags right after the package manager, as in
+// "pnpm --filter web test" or "npm -w web test", from the rest of the command. rest keeps
+// the package manager first, so BuildCommand sees the command it would without the flags.
+func SplitWorkspaceFlags(args []string) (flags, rest []string) {
+	if len(args) == 0 {
+		return nil, args
+	}
+	manager := args[0]
+	i := 1
+	for i < len(args) {
+		if manager == "yarn" && i > 1 {
+			break // yarn workspace <name> only comes first
+		}
+		_, values, ok := lookupWorkspaceFlag(manager, args[i])
+		if !ok || i+values >= len(args) {
+			break
+		}
+		i += 1 + values
+	}
+	if i == 1 {
+		return nil, args
+	}
+	return slices.Clone(args[1:i]), append([]string{manager}, args[i:]...)
+}
+
+// WithoutWorkspaceFlags returns a package manager command without its workspace flags,
+// wherever they are before any "--", so a flag such as npm's -w isn't mistaken for the
+// runner's own
+func WithoutWorkspaceFlags(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	result := []string{args[0]}
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--" || (args[0] == "yarn" && i > 1) {
+			return append(result, args[i:]...)
+		}
+		if _, values, ok := lookupWorkspaceFlag(args[0], args[i]); ok {
+			i += values
+			continue
+		}
+		result = append(result, args[i])
+	}
+	return result
+}
+
+// withWorkspaceFlags builds a command with the workspace flags kept right after the
+// package manager, where it reads them, and has the adapter group results by package
+func withWorkspaceFlags(args []string, build func([]string) []string) []string {
+	if IsWorkspaceCommand(args) {
+		_ = os.Setenv(WorkspaceGroupsVar, "1")
+	}
+	flags, rest := SplitWorkspaceFlags(args)
+	if len(flags) == 0 {
+		return build(args)
+	}
+	built := build(rest)
+	if len(built) == 0 || built[0] != rest[0] {
+		return built
+	}
+	return slices.Concat(built[:1], flags, built[1:])
+}
+
+// inPackageJSON reports whether configured finds the runner in the package.json of the
+// workspace packages a command runs in, or else in the current directory's
+func inPackageJSON(command []string, configured func(manifestPath string) bool) bool {
+	if dirs := workspacePackageDirs(command); len(dirs) > 0 {
+		return slices.ContainsFunc(dirs, func(dir string) bool {
+			return configured(filepath.Join(filepath.FromSlash(dir), "package.json"))
+		})
+	}
+	return configured("package.json")
+}
+
+// workspaceSelection is what a command's workspace flags pick: every package, or the
+// packages matching the selectors
+type workspaceSelection struct {
+	all       bool
+	selectors []string
+}
+
+// parseWorkspaceSelection reads the workspace flags of a package manager command, up to
+// any "--". ok is false when the command doesn't run in workspace packages.
+func parseWorkspaceSelection(args []string) (workspaceSelection, bool) {
+	var selection workspaceSelection
+	if len(args) < 2 {
+		return selection, false
+	}
+	manager := args[0]
+	found := false
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--" || (manager == "yarn" && i > 1) {
+			break
+		}
+		flag, values, ok := lookupWorkspaceFlag(manager, args[i])
+		if !ok || !flag.selects {
+			i += values
+			continue
+		}
+		found = true
+		switch {
+		case flag.values == 0:
+			selection.all = true
+		case values == 0:
+			_, value, _ := strings.Cut(args[i], "=")
+			selection.selectors = append(selection.selectors, value)
+		case i+1 < len(args):
+			selection.selectors = append(selection.selectors, args[i+1])
+		}
+		i += values
+	}
+	return selection, found
+}
+
+// IsWorkspaceCommand reports whether a package manager command runs its script in
+// workspace packages, as "pnpm --filter web test", "npm test --workspaces" and
+// "yarn workspace web test" do
+func IsWorkspaceCommand(args []string) bool {
+	_, ok := parseWorkspaceSelection(args)
+	return ok
+}
+
+// workspacePackageDirs returns the directories of the workspace packages a command runs in,
+// or nil when it isn't a workspace command. Packages are those listed by package.json's
+// "workspaces" or pnpm-workspace.yaml, matched against the command's selectors by name or
+// path; a directory picked by path, such as pnpm's -C, counts even when it isn't listed.
+func workspacePackageDirs(args []string) []string {
+	selection, ok := parseWorkspaceSelection(args)
+	if !ok {
+		return nil
+	}
+	packages := listWorkspacePackages(".")
+	if selection.all || len(selection.selectors) == 0 {
+		return packageDirs(packages)
+	}
+
+	var included, excluded []workspacePackage
+	positive := false
+	for _, selector := range selection.selectors {
+		exclude := strings.HasPrefix(selector, "!")
+		selector = strings.TrimPrefix(selector, "!")
+		if strings.Contains(selector, "[") {
+			return packageDirs(packages) // Changed-since selectors need git; assume any package
+		}
+		matched := matchWorkspacePackages(packages, selector)
+		if exclude {
+			excluded = append(excluded, matched...)
+			continue
+		}
+		positive = true
+		included = append(included, matched...)
+	}
+	if !positive {
+		included = packages
+	}
+
+	var dirs []string
+	for _, pkg := range included {
+		if !slices.Contains(excluded, pkg) && !slices.Contains(dirs, pkg.dir) {
+			dirs = append(dirs, pkg.dir)
+		}
+	}
+	return dirs
+}
+
+// workspacePackage is a package of the workspace
+type workspacePackage struct {
+	name string
+	dir  string // Relative to the workspace root, slash-separated
+}
+
+// packageDirs returns the directories of packages
+func packageDirs(packages []workspacePackage) []string {
+	dirs := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		dirs = append(dirs, pkg.dir)
+	}
+	return dirs
+}
+
+// matchWorkspacePackages returns the packages a pnpm-style selector picks: by name, which
+// may be a glob such as @app/*, or by directory when the selector is a path ("./web",
+// "{packages/web}"). The "..." and "^" dependency markers are ignored, so only the named
+// packages themselves are returned.
+func matchWorkspacePackages(packages []workspacePackage, selector string) []workspacePackage {
+	selector = strings.TrimPrefix(strings.TrimSuffix(selector, "..."), "...")
+	selector = strings.TrimPrefix(strings.TrimSuffix(selector, "^"), "^")
+	byPath := strings.HasPrefix(selector, ".") || strings.HasPrefix(selector, "{")
+	selector = strings.TrimSuffix(strings.TrimPrefix(selector, "{"), "}")
+	dirPattern := path.Clean(filepath.ToSlash(selector))
+
+	var matched []workspacePackage
+	for _, pkg := range packages {
+		if nameMatch, _ := path.Match(selector, pkg.name); nameMatch && !byPath {
+			matched = append(matched, pkg)
+		} else if dirMatch, _ := path.Match(dirPattern, pkg.dir); dirMatch {
+			matched = append(matched, pkg)
+		}
+	}
+	if len(matched) == 0 && (byPath || strings.Contains(selector, "/")) && !strings.HasPrefix(selector, "@") {
+		if _, err := os.Stat(filepath.Join(selector, "package.json")); err == nil {
+			matched = append(matched, workspacePackage{dir: dirPattern})
+		}
+	}
+	return matched
+}
+
+// listWorkspacePackages returns the packages of the workspace rooted at root, or nil when
+// it isn't one
+func listWorkspacePackages(root string) []workspacePackage {
+	var include, exclude []string
+	for _, pattern := range workspacePatterns(root) {
+		if rest, ok := strings.CutPrefix(pattern, "!"); ok {
+			exclude = append(exclude, path.Clean(rest))
+		} else {
+			include = append(include, path.Clean(pattern))
+		}
+	}
+
+	var packages []workspacePackage
+	seen := make(map[string]bool)
+	for _, pattern := range include {
+		base := globBase(pattern)
+		_ = filepath.WalkDir(filepath.Join(root, filepath.FromSlash(base)), func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if d.Name() == "node_modules" || (strings.HasPrefix(d.Name(), ".") && d.Name() != ".") {
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return nil
+			}
+			rel = filepath.ToSlash(rel)
+			if seen[rel] || !matchGlob(pattern, rel) || slices.ContainsFunc(exclude, func(ex string) bool { return matchGlob(ex, rel) }) {
+				return nil
+			}
+			name, ok := packageName(filepath.Join(p, "package.json"))
+			if !ok {
+				return nil
+			}
+			seen[rel] = true
+			packages = append(packages, workspacePackage{name: name, dir: rel})
+			return nil
+		})
+	}
+	return packages
+}
+
+// workspacePatterns returns the package globs of the workspace rooted at root, from
+// package.json's "workspaces" (a list, or Yarn's {"packages": [...]}) and pnpm-workspace.yaml
+func workspacePatterns(root string) []string {
+	var patterns []string
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var manifest struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && len(manifest.Workspaces) > 0 {
+			var list []string
+			var object struct {
+				Packages []string `json:"packages"`
+			}
+			if json.Unmarshal(manifest.Workspaces, &list) == nil {
+				patterns = append(patterns, list...)
+			} else if json.Unmarshal(manifest.Workspaces, &object) == nil {
+				patterns = append(patterns, object.Packages...)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		patterns = append(patterns, pnpmWorkspacePatterns(string(data))...)
+	}
+	return patterns
+}
+
+// pnpmWorkspacePatterns returns the entries of the "packages" list in a
+// pnpm-workspace.yaml. Only the block list form pnpm documents is read.
+func pnpmWorkspacePatterns(yaml string) []string {
+	var patterns []string
+	inPackages := false
+	for _, line := range strings.Split(yaml, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if entry, ok := strings.CutPrefix(trimmed, "-"); ok && inPackages {
+			entry, _, _ = strings.Cut(entry, " #")
+			patterns = append(patterns, strings.Trim(strings.TrimSpace(entry), `"'`))
+		}
+	}
+	return patterns
+}
+
+// packageName returns the name in a package.json, or its directory's name when it has
+// none. ok is false when the file can't be read.
+func packageName(manifestPath string) (string, bool) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", false
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(data, &manifest) == nil && manifest.Name != "" {
+		return manifest.Name, true
+	}
+	return filepath.Base(filepath.Dir(manifestPath)), true
+}
+
+// globBase returns the directories of a slash-separated glob before its first wildcard
+func globBase(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[{") {
+			return path.Join(segments[:i]...)
+		}
+	}
+	return pattern
+}
+
+// matchGlob reports whether a slash-separated path matches a glob, where "**" matches any
+// number of directories
+func matchGlob(pattern, name string) bool {
+	var match func(pattern, name []string) bool
+	match = func(pattern, name []string) bool {
+		if len(pattern) == 0 {
+			return len(name) == 0
+		}
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if match(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, _ := path.Match(pattern[0], name[0])
+		return ok && match(pattern[1:], name[1:])
+	}
+	return match(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
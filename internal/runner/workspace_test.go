@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitWorkspaceFlags(t *testing.T) {
+	tests := []struct {
+		args  []string
+		flags []string
+		rest  []string
+	}{
+		{[]string{"pnpm", "--filter", "web", "test"}, []string{"--filter", "web"}, []string{"pnpm", "test"}},
+		{[]string{"pnpm", "-F", "web", "-r", "exec", "jest"}, []string{"-F", "web", "-r"}, []string{"pnpm", "exec", "jest"}},
+		{[]string{"pnpm", "--filter=@app/*", "test"}, []string{"--filter=@app/*"}, []string{"pnpm", "test"}},
+		{[]string{"npm", "-w", "packages/web", "test"}, []string{"-w", "packages/web"}, []string{"npm", "test"}},
+		{[]string{"npm", "--workspaces", "run", "test"}, []string{"--workspaces"}, []string{"npm", "run", "test"}},
+		{[]string{"yarn", "workspace", "web", "test"}, []string{"workspace", "web"}, []string{"yarn", "test"}},
+		// Flags after the script are the script's, and a flag without its value is left alone
+		{[]string{"npm", "test", "-w", "web"}, nil, []string{"npm", "test", "-w", "web"}},
+		{[]string{"pnpm", "--filter"}, nil, []string{"pnpm", "--filter"}},
+		{[]string{"npx", "jest"}, nil, []string{"npx", "jest"}},
+	}
+	for _, tt := range tests {
+		flags, rest := SplitWorkspaceFlags(tt.args)
+		if !reflect.DeepEqual(flags, tt.flags) || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("SplitWorkspaceFlags(%q) = %q, %q, want %q, %q", tt.args, flags, rest, tt.flags, tt.rest)
+		}
+	}
+}
+
+func TestWithoutWorkspaceFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"npm", "test", "-w", "web"}, []string{"npm", "test"}},
+		{[]string{"npm", "-w", "web", "test", "--", "-w"}, []string{"npm", "test", "--", "-w"}},
+		{[]string{"pnpm", "-w", "test"}, []string{"pnpm", "test"}},
+		{[]string{"jest", "-w", "4"}, []string{"jest", "-w", "4"}},
+	}
+	for _, tt := range tests {
+		if got := WithoutWorkspaceFlags(tt.args); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("WithoutWorkspaceFlags(%q) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildCommand_WorkspaceFlags(t *testing.T) {
+	t.Setenv(WorkspaceGroupsVar, "")
+
+	tests := []struct {
+		name     string
+		def      Definition
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "pnpm filter with a jest script",
+			def:      NewJestDefinition(),
+			args:     []string{"pnpm", "--filter", "jest-utils", "test"},
+			expected: []string{"pnpm", "--filter", "jest-utils", "test", "--", "--reporters", "/tmp/adapter.js"},
+		},
+		{
+			name:     "npm workspace exec jest",
+			def:      NewJestDefinition(),
+			args:     []string{"npm", "-w", "web", "exec", "jest", "src/app.test.js"},
+			expected: []string{"npm", "-w", "web", "exec", "jest", "--reporters", "/tmp/adapter.js", "--", "src/app.test.js"},
+		},
+		{
+			name:     "yarn workspace script",
+			def:      NewJestDefinition(),
+			args:     []string{"yarn", "workspace", "web", "test"},
+			expected: []string{"yarn", "workspace", "web", "test", "--reporters", "/tmp/adapter.js"},
+		},
+		{
+			name:     "pnpm filter with a vitest script",
+			def:      NewVitestDefinition(),
+			args:     []string{"pnpm", "--filter", "vitest-app", "test"},
+			expected: []string{"pnpm", "--filter", "vitest-app", "test", "--reporter", "/tmp/adapter.js", "--reporter", "default"},
+		},
+		{
+			name:     "npm workspaces with a vitest script",
+			def:      NewVitestDefinition(),
+			args:     []string{"npm", "--workspaces", "test"},
+			expected: []string{"npm", "--workspaces", "test", "--", "--reporter", "/tmp/adapter.js", "--reporter", "default"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.def.BuildCommand(tt.args, "/tmp/adapter.js")
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("BuildCommand(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+			if os.Getenv(WorkspaceGroupsVar) != "1" {
+				t.Errorf("Expected BuildCommand to set %s for a workspace command", WorkspaceGroupsVar)
+			}
+		})
+	}
+}
+
+// writeFiles writes files, given by slash-separated path relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkspacePackageDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":                           `{"name": "root", "workspaces": ["packages/*"]}`,
+		"pnpm-workspace.yaml":                    "packages:\n  - 'apps/**'\n  - \"!apps/legacy\"\n",
+		"packages/web/package.json":              `{"name": "@app/web", "scripts": {"test": "jest"}}`,
+		"packages/api/package.json":              `{"name": "@app/api", "devDependencies": {"vitest": "^3.0.0"}}`,
+		"apps/admin/package.json":                `{"name": "admin"}`,
+		"apps/legacy/package.json":               `{"name": "legacy"}`,
+		"apps/admin/node_modules/x/package.json": `{"name": "x"}`,
+	})
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"pnpm", "-r", "test"}, []string{"packages/api", "packages/web", "apps/admin"}},
+		{[]string{"pnpm", "--filter", "@app/*", "test"}, []string{"packages/api", "packages/web"}},
+		{[]string{"pnpm", "--filter", "!@app/web", "test"}, []string{"packages/api", "apps/admin"}},
+		{[]string{"pnpm", "--filter", "./packages/web...", "test"}, []string{"packages/web"}},
+		{[]string{"pnpm", "-C", "apps/legacy", "test"}, []string{"apps/legacy"}},
+		{[]string{"npm", "test", "--workspace=packages/api"}, []string{"packages/api"}},
+		{[]string{"yarn", "workspace", "admin", "test"}, []string{"apps/admin"}},
+		{[]string{"npm", "test"}, nil},
+	}
+	for _, tt := range tests {
+		if got := workspacePackageDirs(tt.args); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("workspacePackageDirs(%q) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+
+	// Detection reads the package.json of the packages the command runs in
+	jest, vitest := NewJestDefinition(), NewVitestDefinition()
+	if !jest.Matches([]string{"pnpm", "--filter", "@app/web", "test"}) || vitest.Matches([]string{"pnpm", "--filter", "@app/web", "test"}) {
+		t.Error("Expected a command filtered to @app/web to be detected as Jest only")
+	}
+	if !vitest.Matches([]string{"npm", "-w", "@app/api", "test"}) || jest.Matches([]string{"npm", "-w", "@app/api", "test"}) {
+		t.Error("Expected a command in the @app/api workspace to be detected as Vitest only")
+	}
+}
@@ -0,0 +1,6 @@
+module.exports = {
+  projects: [
+    { displayName: '@app/web', rootDir: '<rootDir>/packages/web' },
+    { displayName: '@app/api', rootDir: '<rootDir>/packages/api' }
+  ]
+};
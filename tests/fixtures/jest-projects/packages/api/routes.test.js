@@ -0,0 +1,9 @@
+describe('routes', () => {
+  test('lists products', () => {
+    expect([1, 2]).toHaveLength(2);
+  });
+
+  test('rejects unknown routes', () => {
+    expect(404).toBe(200);
+  });
+});
@@ -0,0 +1,5 @@
+describe('render', () => {
+  test('renders the title', () => {
+    expect('<h1>Shop</h1>').toContain('Shop');
+  });
+});
@@ -0,0 +1,46 @@
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zk/3pio/tests/testutil"
+)
+
+// TestJestProjectsGroupByProject checks that a Jest config with two projects reports each
+// project as a top-level group with its test files nested under it
+func TestJestProjectsGroupByProject(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	fixtureDir := filepath.Join("..", "fixtures", "jest-projects")
+	if _, err := os.Stat(filepath.Join(fixtureDir, "node_modules", ".bin", "jest")); err != nil {
+		t.Skip("jest is not installed in the jest-projects fixture (run npm install there)")
+	}
+	cleanTestDir(t, fixtureDir)
+
+	result := testutil.RunThreepio(t, fixtureDir, "npx", "jest")
+	if result.ExitCode == 0 {
+		t.Errorf("Expected a non-zero exit code for the failing @app/api test")
+	}
+	runDir := filepath.Join(fixtureDir, ".3pio", "runs", result.RunID)
+
+	testRunContent := readFile(t, filepath.Join(runDir, "test-run.md"))
+	for _, project := range []string{"@app/web", "@app/api"} {
+		if !strings.Contains(testRunContent, project) {
+			t.Errorf("Expected test-run.md to list the %s project group:\n%s", project, testRunContent)
+		}
+	}
+
+	// Each project's report directory holds its own test file
+	reportsDir := filepath.Join(runDir, "reports")
+	assertFileExists(t, filepath.Join(reportsDir, "@app_web", "index.md"))
+	assertFileExists(t, filepath.Join(reportsDir, "@app_api", "index.md"))
+	webReport := readFile(t, filepath.Join(reportsDir, "@app_web", "index.md"))
+	if !strings.Contains(webReport, "render.test.js") || strings.Contains(webReport, "routes.test.js") {
+		t.Errorf("Expected the @app/web report to list only render.test.js:\n%s", webReport)
+	}
+}